@@ -2,12 +2,17 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
 	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,75 +20,483 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/joho/godotenv"
 
+	"watered/internal/about"
+	"watered/internal/activity"
+	"watered/internal/apiversion"
+	"watered/internal/assets"
 	"watered/internal/auth"
+	"watered/internal/backup"
+	"watered/internal/compress"
+	"watered/internal/config"
+	"watered/internal/demo"
+	"watered/internal/features"
 	"watered/internal/handlers"
+	"watered/internal/i18n"
+	"watered/internal/lifecycle"
+	"watered/internal/logger"
 	"watered/internal/monitoring"
+	"watered/internal/mqtt"
+	"watered/internal/notify/discord"
+	"watered/internal/notify/email"
+	"watered/internal/notify/ntfy"
+	"watered/internal/notify/slack"
+	"watered/internal/notify/telegram"
+	"watered/internal/privacy"
+	"watered/internal/push"
+	"watered/internal/ratelimit"
+	"watered/internal/realip"
+	"watered/internal/realtime"
+	"watered/internal/reload"
+	"watered/internal/render"
+	"watered/internal/scheduler"
 	"watered/internal/services"
 	"watered/internal/storage"
+	"watered/internal/tracing"
+	"watered/internal/update"
+	"watered/internal/weather"
 )
 
 func main() {
-	// Load environment variables from .env files
+	recoveryMode := flag.Bool("recovery", false, "print a one-time admin recovery token on startup")
+	migrateDryRun := flag.Bool("migrate-dry-run", false, "report pending data file migrations and exit without applying them")
+	flag.Parse()
+
+	// Log JSON lines; LOG_LEVEL applies once the configuration is loaded
+	slog.SetDefault(logger.New(os.Stdout, slog.LevelInfo))
+
+	// Load environment variables from .env files, then validate them. The
+	// process environment is kept apart so a reload can re-read the files.
+	processEnv := environ()
 	loadEnvFiles()
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("Invalid configuration", "error", err)
+	}
+	// LOG_LEVEL can change when the configuration is reloaded
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(cfg.Server.LogLevel)
+	slog.SetDefault(logger.New(os.Stdout, logLevel))
+	logStartupReport(cfg.Report())
+
+	if *migrateDryRun {
+		runMigrationDryRun(cfg.Storage.DataFile)
+		return
+	}
+
+	// Request traces go to an OTLP collector when one is configured
+	traceExporter := tracing.NewExporter(cfg.Tracing, update.Version)
+	if traceExporter != nil {
+		traceExporter.Start()
+		tracing.SetTracer(tracing.NewTracer(traceExporter, cfg.Tracing.SampleRatio))
+	}
 
-	// Initialize storage
-	store := storage.NewMemoryStorage()
+	// Initialize storage: persist to a JSON file when DATA_FILE is set, or to
+	// an append-only journal when JOURNAL_FILE is set
+	store, err := storage.Open(cfg.Storage)
+	if err != nil {
+		fatal("Failed to open storage", "error", err)
+	}
 	defer store.Close()
 
+	// Demo mode runs in a sandbox: an in-memory store holding sample data,
+	// never the data file, put back to the sample data on a schedule
+	var sandbox *demo.Sandbox
+	if cfg.IsDemoMode() {
+		if sandbox, err = demo.NewSandbox(store); err != nil {
+			fatal("Failed to start demo sandbox", "error", err)
+		}
+		if err := sandbox.Reset(); err != nil {
+			fatal("Failed to seed demo sandbox", "error", err)
+		}
+	}
+
+	// Check stored data for consistency problems before serving requests
+	if report, err := services.NewIntegrityService(store).Check(cfg.Server.IntegrityAutoRepair); err != nil {
+		slog.Warn("Data integrity check failed", "error", err)
+	} else if !report.OK() {
+		slog.Warn("Data integrity issues found, see GET /admin/integrity or run wateredctl fsck", "issues", len(report.Unresolved()))
+	}
+
 	// Initialize services
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, cfg.Auth)
 	plantService := services.NewPlantService(store)
+	plantService.SetClockSkewTolerance(cfg.Server.ClockSkewTolerance)
+	plantService.SetUndoWateringWindow(cfg.Server.UndoWateringWindow)
+	notificationService := services.NewNotificationService(store)
+	searchService := services.NewSearchService(store)
+	userService := services.NewUserService(store)
+	setupService := services.NewSetupService(store, cfg.Auth)
+
+	// Feature flags for subsystems being rolled out, switched by FEATURE_*
+	// and from the admin page
+	flags := features.New(store, cfg.Features)
+	plantService.SetNotificationsEnabled(flags.Check(features.Notifications))
+
+	// Real-time sync: plant and config changes are broadcast to /ws clients
+	realtimeHub := realtime.NewHub()
+	plantService.SetPublisher(realtimeHub)
+
+	// Web Push: enabled when VAPID keys are configured
+	var pushSender services.PushSender
+	vapidKeys, err := push.LoadVAPIDKeys(cfg.Push)
+	if err != nil {
+		fatal("Invalid VAPID configuration", "error", err)
+	}
+	if vapidKeys != nil {
+		pushSender = push.NewSender(vapidKeys)
+	}
+	pushService := services.NewPushService(store, pushSender)
+
+	// Email reminders: enabled when SMTP_HOST is configured
+	var emailSender services.EmailSender
+	if cfg.SMTP.Enabled() {
+		emailSender = email.NewSender(cfg.SMTP)
+	}
+	emailService := services.NewEmailService(store, emailSender)
+
+	// Slack notifications: enabled when SLACK_WEBHOOK_URL is configured
+	var slackSender services.SlackSender
+	if cfg.Slack.Enabled() {
+		slackSender = slack.NewSender(cfg.Slack)
+	}
+	slackService := services.NewSlackService(store, slackSender)
+	if err := slackService.SetTemplates(cfg.Slack.OverdueTemplate, cfg.Slack.WateredTemplate); err != nil {
+		fatal("Invalid Slack message template", "error", err)
+	}
+	if slackService.Enabled() {
+		plantService.AddWateringNotifier(slackService)
+	}
+
+	// Discord notifications: enabled when DISCORD_WEBHOOK_URL is configured
+	var discordSender services.DiscordSender
+	if cfg.Discord.Enabled() {
+		discordSender = discord.NewSender(cfg.Discord)
+	}
+	discordService := services.NewDiscordService(store, discordSender)
+	if discordService.Enabled() {
+		plantService.AddWateringNotifier(discordService)
+	}
+
+	// ntfy notifications: enabled when NTFY_TOPIC is configured
+	var ntfySender services.NtfySender
+	if cfg.Ntfy.Enabled() {
+		ntfySender = ntfy.NewSender(cfg.Ntfy)
+	}
+	ntfyService := services.NewNtfyService(store, ntfySender, cfg.Server.PublicURL)
+	if ntfyService.Enabled() {
+		plantService.AddWateringNotifier(ntfyService)
+	}
+
+	// Telegram bot: enabled when TELEGRAM_BOT_TOKEN is configured
+	var telegramSender services.TelegramSender
+	if cfg.Telegram.Enabled() {
+		telegramSender = telegram.NewSender(cfg.Telegram)
+	}
+	telegramService := services.NewTelegramService(store, plantService, telegramSender, cfg.Telegram)
+
+	// Snooze links in email and push reminders and invite links, signed with
+	// the session secret. Without one, links stop working when the server
+	// restarts.
+	linkKey := []byte(cfg.Auth.SessionSecret)
+	if len(linkKey) == 0 {
+		linkKey = make([]byte, 32)
+		if _, err := rand.Read(linkKey); err != nil {
+			fatal("Failed to generate link signing key", "error", err)
+		}
+	}
+	snoozeService := services.NewSnoozeService(store, plantService, linkKey, cfg.Notifications.SnoozeDuration, cfg.Server.PublicURL)
+	emailService.SetSnoozeService(snoozeService)
+	pushService.SetSnoozeService(snoozeService)
+
+	// Self-update: enabled when a release signing key is configured
+	selfUpdater, err := update.NewUpdater(cfg.Update, update.Version)
+	if err != nil {
+		fatal("Invalid self-update configuration", "error", err)
+	}
+
+	// restartRequests asks the main goroutine to shut down gracefully and
+	// re-exec the installed binary
+	restartRequests := make(chan struct{}, 1)
+	requestRestart := func() {
+		select {
+		case restartRequests <- struct{}{}:
+		default:
+		}
+	}
+
+	// Admin recovery: issue a one-time token on the console only
+	if *recoveryMode || cfg.Server.Recovery {
+		token, err := authService.EnableRecovery(auth.RecoveryTokenTTL)
+		if err != nil {
+			fatal("Failed to enable admin recovery", "error", err)
+		}
+		slog.Warn("ADMIN RECOVERY TOKEN: POST token=<token>&email=<your email> to /auth/recovery", "audit", true, "token", token, "valid_for", auth.RecoveryTokenTTL)
+	}
+
+	// Last-seen times per user, written to storage in batches
+	activityTracker, err := activity.NewTracker(store)
+	if err != nil {
+		fatal("Failed to load user activity", "error", err)
+	}
+
+	// Admin changes are recorded in the audit log
+	auditService := services.NewAuditService(store)
+
+	// SIGHUP and POST /admin/config/reload apply configuration changes
+	// without a restart; components register the settings they pick up below
+	reloader := reload.New(cfg, func() (*config.Config, error) {
+		return config.LoadFrom(readEnv(processEnv))
+	})
+	reloader.SetAuditor(auditService)
+	reloader.Handle([]string{"LOG_LEVEL"}, func(next *config.Config) error {
+		logLevel.Set(next.Server.LogLevel)
+		return nil
+	})
+	reloader.Handle([]string{"ALLOWED_EMAILS", "ADMIN_EMAILS", "VIEWER_EMAILS", "DEMO_USERS"}, func(next *config.Config) error {
+		authService.SetAllowlist(next.Auth)
+		return nil
+	})
+	reloader.Handle([]string{"SNOOZE_DURATION"}, func(next *config.Config) error {
+		snoozeService.SetDuration(next.Notifications.SnoozeDuration)
+		return nil
+	})
 
 	// Initialize handlers
 	authHandlers := handlers.NewAuthHandlers(authService)
 	plantHandlers := handlers.NewPlantHandlers(plantService, authService)
-	adminHandlers := handlers.NewAdminHandler(store)
+	plantHandlers.SetAuditService(auditService)
+	adminHandlers := handlers.NewAdminHandler(store, cfg)
+	adminHandlers.SetAuditService(auditService)
+	adminHandlers.SetEmailService(emailService)
+	adminHandlers.SetSlackService(slackService)
+	adminHandlers.SetDiscordService(discordService)
+	adminHandlers.SetNtfyService(ntfyService)
+	adminHandlers.SetPublisher(realtimeHub)
+	adminHandlers.SetActivityTracker(activityTracker)
+	adminHandlers.SetReloader(reloader)
+	adminHandlers.SetAuthService(authService)
+	notificationHandlers := handlers.NewNotificationHandlers(notificationService, authService)
+	localeHandlers := handlers.NewLocaleHandlers(userService, authService)
+	accountHandlers := handlers.NewAccountHandlers(userService, authService)
+	accountHandlers.SetActivityTracker(activityTracker)
+	searchHandlers := handlers.NewSearchHandlers(searchService, plantService, authService)
+	setupHandlers := handlers.NewSetupHandlers(setupService, authService)
+	pushHandlers := handlers.NewPushHandlers(pushService, authService)
+	snoozeHandlers := handlers.NewSnoozeHandlers(snoozeService, plantService)
+	telegramHandlers := handlers.NewTelegramHandlers(telegramService, authService, cfg.Telegram.WebhookSecret)
+	deviceService := services.NewDeviceService(store, plantService, cfg.Sensors)
+	sensorService := services.NewSensorService(store, plantService, deviceService, cfg.Sensors)
+	sensorHandlers := handlers.NewSensorHandlers(sensorService, plantService)
+	buttonHandlers := handlers.NewButtonHandlers(services.NewButtonService(deviceService, plantService, cfg.Buttons))
+	updateHandlers := handlers.NewUpdateHandlers(nil, requestRestart)
+	if selfUpdater != nil {
+		updateHandlers = handlers.NewUpdateHandlers(selfUpdater, requestRestart)
+	}
+
+	// Per-client rate limiting for the API, warning before refusing requests
+	rateLimit := func(next http.Handler) http.Handler { return next }
+	rateLimiter := ratelimit.NewLimiter(cfg.RateLimit)
+	if rateLimiter != nil {
+		rateLimit = rateLimiter.Middleware(authService.ClientKey)
+	}
+
+	// Token buckets for sign-in and writes, per address and per user. The
+	// address is the one realip trusts, so forged forwarded headers do not
+	// buy a fresh bucket.
+	authLimit := func(next http.Handler) http.Handler { return next }
+	authLimiter := ratelimit.NewBucketLimiter("auth", cfg.RateLimit.Auth)
+	if authLimiter != nil {
+		authLimit = authLimiter.Middleware(authService.ClientIPKey, authService.ClientKey)
+	}
+	writeLimit := func(next http.Handler) http.Handler { return next }
+	writeLimiter := ratelimit.NewBucketLimiter("write", cfg.RateLimit.Write)
+	if writeLimiter != nil {
+		writeLimit = ratelimit.WritesOnly(writeLimiter.Middleware(authService.ClientIPKey, authService.ClientKey))
+	}
+	rateLimitHandlers := handlers.NewRateLimitHandlers(rateLimiter, authLimiter, writeLimiter)
+	if lockout := ratelimit.NewLockout(cfg.RateLimit.Lockout); lockout != nil {
+		lockout.SetAuditor(auditService)
+		authHandlers.SetLockout(lockout)
+		rateLimitHandlers.SetLockout(lockout)
+	}
+
+	if setupService.IsSetupRequired() {
+		slog.Info("First-run setup available at POST /setup, send the token as X-Setup-Token header", "token", setupService.BootstrapToken())
+	}
 
 	// Initialize health monitoring
-	healthMonitor := monitoring.NewHealthMonitor("1.0.0")
+	healthMonitor := monitoring.NewHealthMonitor(update.Version)
+	healthMonitor.SetCacheTTL(cfg.Server.HealthCacheTTL)
+	healthMonitor.SetMetricsInterval(cfg.Server.HealthMetricsInterval)
+	if cfg.Server.HealthHistoryInterval > 0 {
+		healthMonitor.SetHistory(store, cfg.Server.HealthHistoryInterval, cfg.Server.HealthHistoryRetention)
+	}
 	healthMonitor.RegisterChecker(monitoring.NewDatabaseHealthChecker(store))
-	healthMonitor.RegisterChecker(monitoring.NewMemoryHealthChecker(512.0)) // 512MB limit
-	healthMonitor.RegisterChecker(monitoring.NewApplicationHealthChecker(store))
+	memoryChecker := monitoring.NewMemoryHealthChecker(512.0) // 512MB limit
+	memoryChecker.SetMemStatsSource(healthMonitor.ReadMemStats)
+	healthMonitor.RegisterChecker(memoryChecker)
+	healthMonitor.RegisterChecker(monitoring.NewApplicationHealthChecker(store, cfg.IsDemoMode()))
+	capacityMonitor := monitoring.NewCapacityMonitor(store, cfg.Server.CapacityWarnDays, cfg.Storage.DataFile, cfg.Storage.JournalFile)
+	healthMonitor.SetCapacityMonitor(capacityMonitor)
+	jobs := scheduler.New()
+	healthMonitor.RegisterChecker(jobs)
+	var backups *backup.Uploader
+	if bucket := backup.NewS3Bucket(cfg.Backup); bucket != nil {
+		backups = backup.New(store, bucket, cfg.Backup)
+		healthMonitor.RegisterChecker(backups)
+	}
 
 	// Parse templates
 	templates, err := template.ParseGlob(filepath.Join("web", "templates", "*.html"))
 	if err != nil {
-		log.Printf("Warning: Could not parse templates: %v", err)
+		slog.Warn("Could not parse templates", "error", err)
 		templates = template.New("empty")
 	}
+	renderer := render.NewRenderer(templates, render.NewCSPPolicy(cfg.CSP))
+	authHandlers.SetRenderer(renderer)
+
+	// Hash static assets so the service worker can precache the current set
+	cacheManifest, err := assets.BuildManifest(os.DirFS(filepath.Join("web", "static")), "/static", "sw.js")
+	if err != nil {
+		slog.Warn("Could not build cache manifest", "error", err)
+		cacheManifest = &assets.Manifest{Assets: []assets.Asset{}}
+	}
+
+	// Build and license information for /about
+	aboutInfo, err := about.Load(update.Version)
+	if err != nil {
+		fatal("Failed to load build information", "error", err)
+	}
+	aboutHandler := handlers.NewAboutHandler(aboutInfo, renderer)
+	apiDocsHandlers := handlers.NewAPIDocsHandlers(renderer, authService)
+	pwaHandlers := handlers.NewPWAHandlers(renderer)
+	fragmentHandlers := handlers.NewFragmentHandlers(plantHandlers, renderer)
+	errorHandlers := handlers.NewErrorHandlers(renderer)
+	apiKeyHandlers := handlers.NewAPIKeyHandlers(authService)
+	apiKeyHandlers.SetAuditService(auditService)
+	deviceHandlers := handlers.NewDeviceHandlers(deviceService, authService)
+	deviceHandlers.SetAuditService(auditService)
+	sessionHandlers := handlers.NewSessionHandlers(authService)
+	sessionHandlers.SetAuditService(auditService)
+	shareService := services.NewShareService(store, plantService)
+	shareHandlers := handlers.NewShareHandlers(shareService, authService, renderer)
+	shareHandlers.SetAuditService(auditService)
+	inviteService := services.NewInviteService(store, linkKey, cfg.Server.PublicURL)
+	inviteHandlers := handlers.NewInviteHandlers(inviteService, authService, renderer)
+	inviteHandlers.SetAuditService(auditService)
+	authHandlers.SetInviteService(inviteService)
+	badgeHandlers := handlers.NewBadgeHandlers(plantService, shareService, cfg.Server.BadgeRequireToken)
+	feedHandlers := handlers.NewFeedHandlers(plantService, cfg.Server.PublicURL)
+	auditHandlers := handlers.NewAuditHandlers(auditService)
 
 	// Create router
 	r := chi.NewRouter()
 
 	// Add middleware
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	r.Use(middleware.RealIP)
+	// Forwarded client addresses are only believed from TRUSTED_PROXIES, so
+	// rate limits and lockouts keyed on the address cannot be dodged
+	r.Use(realip.Middleware(cfg.Server.TrustedProxies))
 	r.Use(middleware.RequestID)
+	// Request-scoped JSON logger carrying the request ID and user, plus an access log line
+	r.Use(logger.Middleware(func(r *http.Request) string {
+		if user, _ := authService.GetCurrentUser(r); user != nil {
+			return user.Email
+		}
+		return ""
+	}))
+	// A span per request, so slow requests can be broken down in the trace viewer
+	r.Use(tracing.Middleware)
+	// Panics become a 500 error page, or JSON error for API requests
+	r.Use(errorHandlers.Recoverer)
+	// Gzip JSON, HTML and other text for clients that accept it
+	r.Use(compress.Middleware(cfg.Server.CompressionMinSize))
+	// Record when signed-in users were last seen; API key clients are not users
+	r.Use(activityTracker.Middleware(func(r *http.Request) string {
+		if user, _ := authService.GetCurrentUser(r); user != nil {
+			return user.Email
+		}
+		return ""
+	}))
+	// Pages and human-readable API strings in the user's chosen language,
+	// else the browser's
+	r.Use(i18n.Middleware(func(r *http.Request) string {
+		if user, _ := authService.GetCurrentUser(r); user != nil {
+			return userService.Locale(user.Email)
+		}
+		return ""
+	}))
+	// Cookie-authenticated writes must carry the session's CSRF token
+	r.Use(authService.CSRFProtect)
+	// Label every demo response so sample data is never mistaken for real data
+	if sandbox != nil {
+		r.Use(demo.Middleware)
+	}
 
-	// Health check endpoints
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"ok","service":"watered"}`))
-	})
+	// Unknown routes and methods get a styled page, or JSON for API requests
+	r.NotFound(errorHandlers.NotFoundHandler)
+	r.MethodNotAllowed(errorHandlers.MethodNotAllowedHandler)
+
+	// Health check endpoints. Liveness only says the process answers;
+	// readiness also needs storage, migrations and the scheduler, and fails
+	// during startup and shutdown so no traffic is routed here then.
+	r.Get("/health", monitoring.LiveHandler())
+	r.Get("/health/live", monitoring.LiveHandler())
+	readiness := monitoring.NewReadiness()
+	readiness.AddCheck("storage", monitoring.StorageReady(store))
+	readiness.AddCheck("migrations", monitoring.MigrationsReady(store))
+	readiness.AddCheck("scheduler", jobs.Ready)
+	r.Get("/health/ready", readiness.HTTPHandler())
 
 	// Comprehensive health monitoring endpoint
 	r.Get("/health/detailed", healthMonitor.HTTPHandler())
 
+	// Post-deploy smoke test: admins, or CD pipelines holding SMOKE_TEST_TOKEN
+	smokeTester := monitoring.NewSmokeTester(plantService)
+	r.With(authService.AdminOrTokenRequired(cfg.Server.SmokeTestToken)).
+		Post("/health/smoke", smokeTester.HTTPHandler())
+
+	// First-run setup routes
+	r.Route("/setup", func(r chi.Router) {
+		r.Get("/status", setupHandlers.GetSetupStatusHandler)
+		r.Post("/", setupHandlers.CompleteSetupHandler)
+	})
+
 	// Authentication routes
 	r.Route("/auth", func(r chi.Router) {
-		r.Get("/login", authHandlers.LoginHandler)
-		r.Get("/callback", authHandlers.CallbackHandler)
-		r.Post("/logout", authHandlers.LogoutHandler)
-		r.Get("/status", authHandlers.StatusHandler)
-		// Demo routes (only available in demo mode)
-		r.HandleFunc("/demo-login", authHandlers.DemoLoginHandler)
+		// Every page load reads the sign-in state, so only sign-in itself
+		// draws from the auth bucket. The state moved to /api/v1/auth/status.
+		r.With(apiversion.Deprecated(apiversion.LegacyDeprecatedAt, "/auth", "/api/v1/auth")).Get("/status", authHandlers.StatusHandler)
+
+		r.Group(func(r chi.Router) {
+			r.Use(authLimit)
+			r.Get("/login", authHandlers.LoginHandler)
+			r.Get("/callback", authHandlers.CallbackHandler)
+			r.Post("/logout", authHandlers.LogoutHandler)
+			// Demo routes (only available in demo mode)
+			r.HandleFunc("/demo-login", authHandlers.DemoLoginHandler)
+			// Admin recovery (only available when started with -recovery)
+			r.Post("/recovery", authHandlers.RecoveryLoginHandler)
+			// Bearer tokens for native apps, from a session or refresh token
+			r.Post("/token", authHandlers.TokenHandler)
+		})
 	})
 
-	// API routes
-	r.Route("/api", func(r chi.Router) {
+	// API routes, one set per version under /api/{version}. v1 is also
+	// served at the unversioned /api paths it replaced, which are deprecated.
+	apiV1 := func(r chi.Router) {
 		r.Get("/status", handlers.GetStatus)
+		r.Get("/version", aboutHandler.GetVersionHandler)
+		r.Get("/time", plantHandlers.GetTimeHandler)
+		r.Get("/leaderboard", plantHandlers.GetLeaderboardHandler)
+		r.Get("/cache-manifest", cacheManifest.HTTPHandler())
+		r.Get("/openapi.json", apiDocsHandlers.GetOpenAPIHandler)
+		r.Get("/docs", apiDocsHandlers.GetDocsHandler)
+		// Snooze links in reminders carry a signed token instead of a session
+		r.Get("/snooze", snoozeHandlers.SnoozeHandler)
+		// Telegram bot commands, authenticated by the webhook secret
+		r.Post("/telegram/webhook", telegramHandlers.WebhookHandler)
+		// Soil sensors authenticate with their device token
+		r.With(flags.Require(features.Sensors)).Post("/sensors/{deviceID}/readings", sensorHandlers.RecordReadingHandler)
 
 		// Plant API routes
 		r.Route("/plant", func(r chi.Router) {
@@ -91,11 +504,24 @@ func main() {
 			r.Get("/", plantHandlers.GetPlantHandler)
 			r.Get("/status", plantHandlers.GetPlantStatusHandler)
 			r.Get("/timer", plantHandlers.GetPlantTimerHandler)
+			r.Get("/events", plantHandlers.PlantEventsHandler)
+			r.Get("/stats", plantHandlers.GetPlantStatsHandler)
+			r.With(flags.Require(features.Sensors)).Get("/sensors", sensorHandlers.GetPlantSensorsHandler)
+			r.Get("/tasks", plantHandlers.ListCareTasksHandler)
+			r.Get("/tasks/{taskID}/history", plantHandlers.CareTaskHistoryHandler)
+			// Calendar apps authenticate with a share link token
+			r.Get("/calendar.ics", shareHandlers.CalendarHandler)
+			// Buttons authenticate with their device token and water their own plant
+			r.Post("/water/button", buttonHandlers.PressHandler)
 
 			// Protected plant endpoints (require authentication)
 			r.Group(func(r chi.Router) {
 				r.Use(authService.AuthRequired)
+				// Viewers, such as a plant sitter, can't water
+				r.Use(authService.RoleRequired(privacy.RoleMember))
 				r.Post("/water", plantHandlers.WaterPlantHandler)
+				r.Post("/water/undo", plantHandlers.UndoWateringHandler)
+				r.Post("/tasks/{taskID}/complete", plantHandlers.CompleteCareTaskHandler)
 			})
 
 			// Admin-only plant endpoints
@@ -103,26 +529,211 @@ func main() {
 				r.Use(authService.AdminRequired)
 				r.Put("/settings", plantHandlers.UpdatePlantSettingsHandler)
 				r.Post("/reset", plantHandlers.ResetPlantHandler)
+				r.Put("/vacation", plantHandlers.SetVacationHandler)
+				r.Delete("/vacation", plantHandlers.ClearVacationHandler)
+				r.Post("/tasks", plantHandlers.CreateCareTaskHandler)
+				r.Put("/tasks/{taskID}", plantHandlers.UpdateCareTaskHandler)
+				r.Delete("/tasks/{taskID}", plantHandlers.DeleteCareTaskHandler)
+			})
+		})
+
+		// Multi-plant API routes (/api/plant is an alias for plant 1)
+		r.Route("/plants", func(r chi.Router) {
+			r.Get("/", plantHandlers.ListPlantsHandler)
+			r.With(authService.AdminRequired, flags.Require(features.MultiPlant)).Post("/", plantHandlers.CreatePlantHandler)
+
+			r.Route("/{id}", func(r chi.Router) {
+				// Public plant endpoints (read-only)
+				r.Get("/", plantHandlers.GetPlantHandler)
+				r.Get("/status", plantHandlers.GetPlantStatusHandler)
+				r.Get("/timer", plantHandlers.GetPlantTimerHandler)
+				r.With(flags.Require(features.Sensors)).Get("/sensors", sensorHandlers.GetPlantSensorsHandler)
+				r.Get("/tasks", plantHandlers.ListCareTasksHandler)
+				r.Get("/tasks/{taskID}/history", plantHandlers.CareTaskHistoryHandler)
+				r.Get("/calendar.ics", shareHandlers.CalendarHandler)
+
+				// Protected plant endpoints (require authentication)
+				r.Group(func(r chi.Router) {
+					r.Use(authService.AuthRequired)
+					r.Use(authService.RoleRequired(privacy.RoleMember))
+					r.Post("/water", plantHandlers.WaterPlantHandler)
+					r.Post("/water/undo", plantHandlers.UndoWateringHandler)
+					r.Post("/tasks/{taskID}/complete", plantHandlers.CompleteCareTaskHandler)
+				})
+
+				// Admin-only plant endpoints
+				r.Group(func(r chi.Router) {
+					r.Use(authService.AdminRequired)
+					r.Put("/settings", plantHandlers.UpdatePlantSettingsHandler)
+					r.Post("/reset", plantHandlers.ResetPlantHandler)
+					r.Put("/vacation", plantHandlers.SetVacationHandler)
+					r.Delete("/vacation", plantHandlers.ClearVacationHandler)
+					r.Post("/tasks", plantHandlers.CreateCareTaskHandler)
+					r.Put("/tasks/{taskID}", plantHandlers.UpdateCareTaskHandler)
+					r.Delete("/tasks/{taskID}", plantHandlers.DeleteCareTaskHandler)
+					r.Delete("/", plantHandlers.DeletePlantHandler)
+				})
 			})
 		})
+
+		// Search across plants and notification history
+		r.Get("/search", searchHandlers.SearchHandler)
+
+		// Web Push subscription endpoints
+		r.Route("/push", func(r chi.Router) {
+			r.Get("/vapid-public-key", pushHandlers.GetVAPIDPublicKeyHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(authService.AuthRequired)
+				r.Post("/subscriptions", pushHandlers.SubscribeHandler)
+				r.Delete("/subscriptions", pushHandlers.UnsubscribeHandler)
+			})
+		})
+
+		// Current user endpoints
+		r.Route("/me", func(r chi.Router) {
+			r.Use(authService.AuthRequired)
+			r.Get("/notifications", notificationHandlers.GetMyNotificationsHandler)
+			r.Get("/locale", localeHandlers.GetLocaleHandler)
+			r.Put("/locale", localeHandlers.UpdateLocaleHandler)
+			r.Get("/export", accountHandlers.ExportHandler)
+			r.Delete("/", accountHandlers.DeleteHandler)
+		})
+	}
+	r.Route("/api", func(r chi.Router) {
+		// Automation clients authenticate with "Authorization: Bearer <api key>",
+		// native apps with an access token from /auth/token
+		r.Use(authService.APIKeyAuth)
+		r.Use(rateLimit)
+		r.Use(writeLimit)
+
+		apiversion.Mount(r, apiversion.Version{Name: "v1", Routes: func(r chi.Router) {
+			apiV1(r)
+			r.Get("/auth/status", authHandlers.StatusHandler)
+		}})
+		apiversion.MountLegacy(r, "/api", apiversion.Version{Name: "v1", Routes: apiV1})
 	})
 
 	// Admin API routes
 	r.Route("/admin", func(r chi.Router) {
+		r.Use(rateLimit)
+		r.Use(writeLimit)
 		r.Use(authService.AdminRequired)
 
 		// Configuration endpoints
 		r.Get("/config", adminHandlers.GetConfigHandler)
+		r.Get("/environment", adminHandlers.GetEnvironmentHandler)
 		r.Put("/config/timeout", adminHandlers.UpdateTimeoutHandler)
+		r.Put("/config/privacy", adminHandlers.UpdatePrivacyModeHandler)
+		r.Post("/config/reload", adminHandlers.ReloadConfigHandler)
+
+		// Feature flags
+		r.Get("/features", adminHandlers.GetFeaturesHandler)
+		r.Put("/features/{name}", adminHandlers.UpdateFeatureHandler)
+		r.Delete("/features/{name}", adminHandlers.ResetFeatureHandler)
 
 		// User management endpoints
 		r.Get("/users", adminHandlers.GetUsersHandler)
 		r.Post("/users", adminHandlers.AddUserHandler)
+		r.Post("/users/merge", adminHandlers.MergeUsersHandler)
 		r.Delete("/users/{email}", adminHandlers.RemoveUserHandler)
+		r.Put("/users/{email}/role", adminHandlers.SetUserRoleHandler)
+		r.Delete("/users/{email}/sessions", sessionHandlers.RevokeUserSessionsHandler)
+
+		// Users offered on the demo login form
+		r.Get("/demo-users", adminHandlers.GetDemoUsersHandler)
+		r.Post("/demo-users", adminHandlers.AddDemoUserHandler)
+		r.Delete("/demo-users/{email}", adminHandlers.RemoveDemoUserHandler)
+
+		// Signed-in browser sessions
+		r.Get("/sessions", sessionHandlers.ListSessionsHandler)
+		r.Delete("/sessions/{id}", sessionHandlers.RevokeSessionHandler)
+
+		// API keys for automation clients
+		r.Get("/apikeys", apiKeyHandlers.ListAPIKeysHandler)
+		r.Post("/apikeys", apiKeyHandlers.CreateAPIKeyHandler)
+		r.Delete("/apikeys/{id}", apiKeyHandlers.RevokeAPIKeyHandler)
+
+		// Read-only share links to a plant's status
+		r.Get("/shares", shareHandlers.ListSharesHandler)
+		r.Post("/shares", shareHandlers.CreateShareHandler)
+		r.Delete("/shares/{id}", shareHandlers.RevokeShareHandler)
+
+		// Invite links that add whoever signs in through them to the allowlist
+		r.Get("/invites", inviteHandlers.ListInvitesHandler)
+		r.Post("/invites", inviteHandlers.CreateInviteHandler)
+		r.Delete("/invites/{id}", inviteHandlers.RevokeInviteHandler)
+
+		// Registered sensors and buttons
+		r.Get("/devices", deviceHandlers.ListDevicesHandler)
+		r.Post("/devices", deviceHandlers.CreateDeviceHandler)
+		r.Get("/devices/{id}", deviceHandlers.GetDeviceHandler)
+		r.Patch("/devices/{id}", deviceHandlers.UpdateDeviceHandler)
+		r.Post("/devices/{id}/token", deviceHandlers.RotateDeviceTokenHandler)
+		r.Delete("/devices/{id}", deviceHandlers.DeleteDeviceHandler)
 
 		// History and statistics endpoints
 		r.Get("/history", adminHandlers.GetHistoryHandler)
+		r.Get("/history/daily", adminHandlers.GetDailyHistoryHandler)
+		r.Get("/history/by-user", adminHandlers.GetUserHistoryHandler)
 		r.Get("/stats", adminHandlers.GetStatsHandler)
+		r.Get("/reports/weekly", adminHandlers.GetWeeklyReportHandler)
+
+		// Uptime timeline of recorded health checks
+		r.Get("/health/history", healthMonitor.HistoryHandler())
+
+		// Notification history
+		r.Get("/notifications", notificationHandlers.GetNotificationsHandler)
+
+		// Audit log of admin changes
+		r.Get("/audit", auditHandlers.GetAuditLogHandler)
+
+		// Data integrity
+		r.Get("/integrity", adminHandlers.GetIntegrityHandler)
+		r.Post("/integrity/repair", adminHandlers.RepairIntegrityHandler)
+
+		// Backup and restore
+		r.Get("/backup", adminHandlers.BackupHandler)
+		r.Post("/restore", adminHandlers.RestoreHandler)
+
+		// Email
+		r.Post("/email/test", adminHandlers.SendTestEmailHandler)
+
+		// Slack
+		r.Post("/slack/test", adminHandlers.SendTestSlackHandler)
+
+		// Discord
+		r.Post("/discord/test", adminHandlers.SendTestDiscordHandler)
+
+		// ntfy
+		r.Post("/ntfy/test", adminHandlers.SendTestNtfyHandler)
+
+		// Self-update
+		r.Get("/update", updateHandlers.GetUpdateStatusHandler)
+		r.Post("/update", updateHandlers.ApplyUpdateHandler)
+
+		// Rate limiting
+		r.Get("/ratelimit", rateLimitHandlers.GetRateLimitStatsHandler)
+		r.Get("/limits", rateLimitHandlers.GetRateLimitStatsHandler)
+		r.Delete("/limits/{key}", rateLimitHandlers.ResetClientHandler)
+	})
+
+	// Real-time sync across devices
+	r.Get("/ws", realtimeHub.ServeHTTP)
+
+	// Service worker, served from the root so it can receive push events for the whole app
+	r.Get("/sw.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		http.ServeFile(w, r, filepath.Join("web", "static", "sw.js"))
+	})
+
+	// Installing to a home screen, and the page shown offline
+	r.Get("/manifest.webmanifest", pwaHandlers.GetManifestHandler)
+	r.Get("/offline", pwaHandlers.GetOfflineHandler)
+
+	// Dashboard fragments the index page swaps in with htmx
+	r.Route("/fragments", func(r chi.Router) {
+		r.Get("/plant-card", fragmentHandlers.PlantCardHandler)
+		r.Get("/timer", fragmentHandlers.TimerHandler)
 	})
 
 	// Static files
@@ -132,17 +743,35 @@ func main() {
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		// Check authentication and pass user data to template
 		user, _ := authService.GetCurrentUser(r)
+		csrfToken, err := authService.CSRFToken(w, r)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("Failed to get CSRF token", "error", err)
+		}
 		templateData := map[string]interface{}{
-			"User":          user,
-			"Authenticated": user != nil,
+			"User":            user,
+			"Authenticated":   user != nil,
+			auth.CSRFTokenKey: csrfToken,
+			render.I18nKey:    i18n.FromContext(r.Context()),
 		}
 
-		if err := templates.ExecuteTemplate(w, "index.html", templateData); err != nil {
+		if err := renderer.Render(w, "index.html", templateData); err != nil {
 			http.Error(w, "Template error", http.StatusInternalServerError)
-			log.Printf("Template error: %v", err)
+			logger.FromContext(r.Context()).Error("Template error", "error", err)
 		}
 	})
 
+	r.Get("/about", aboutHandler.GetAboutHandler)
+
+	// Read-only plant status for share link holders; the token authenticates
+	r.With(rateLimit).Get("/share/{token}", shareHandlers.GetShareHandler)
+	// Sign-in through an invite link; the token is checked and kept for the
+	// OAuth callback
+	r.With(rateLimit).Get("/invite/{token}", inviteHandlers.AcceptInviteHandler)
+	// Status badge for READMEs and dashboards
+	r.With(rateLimit).Get("/badge.svg", badgeHandlers.GetBadgeHandler)
+	// Activity feed for feed readers
+	r.With(rateLimit).Get("/feed.atom", feedHandlers.GetFeedHandler)
+
 	r.Get("/login", func(w http.ResponseWriter, r *http.Request) {
 		// Redirect if already authenticated
 		if authService.IsAuthenticated(r) {
@@ -150,17 +779,15 @@ func main() {
 			return
 		}
 
-		// Check if demo mode is enabled (when GOOGLE_CLIENT_ID is not set or is demo value)
-		clientID := os.Getenv("GOOGLE_CLIENT_ID")
-		demoMode := clientID == "" || clientID == "demo-client-id"
-
+		// Offer demo login when DEMO_MODE or the demo sandbox enables it
 		templateData := map[string]interface{}{
-			"DemoMode": demoMode,
+			"DemoMode":     authService.IsDemoMode(),
+			render.I18nKey: i18n.FromContext(r.Context()),
 		}
 
-		if err := templates.ExecuteTemplate(w, "login.html", templateData); err != nil {
+		if err := renderer.Render(w, "login.html", templateData); err != nil {
 			http.Error(w, "Template error", http.StatusInternalServerError)
-			log.Printf("Template error: %v", err)
+			logger.FromContext(r.Context()).Error("Template error", "error", err)
 		}
 	})
 
@@ -169,82 +796,390 @@ func main() {
 		r.Use(authService.AdminRequired)
 		r.Get("/admin", func(w http.ResponseWriter, r *http.Request) {
 			user, _ := authService.GetCurrentUser(r)
+			csrfToken, err := authService.CSRFToken(w, r)
+			if err != nil {
+				logger.FromContext(r.Context()).Error("Failed to get CSRF token", "error", err)
+			}
 			templateData := map[string]interface{}{
-				"User":          user,
-				"Authenticated": user != nil,
+				"User":            user,
+				"Authenticated":   user != nil,
+				auth.CSRFTokenKey: csrfToken,
+				render.I18nKey:    i18n.FromContext(r.Context()),
 			}
 
-			if err := templates.ExecuteTemplate(w, "admin.html", templateData); err != nil {
+			if err := renderer.Render(w, "admin.html", templateData); err != nil {
 				http.Error(w, "Template error", http.StatusInternalServerError)
-				log.Printf("Template error: %v", err)
+				logger.FromContext(r.Context()).Error("Template error", "error", err)
 			}
 		})
 	})
 
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// 405 responses list the methods a route takes, now all are registered
+	if err := errorHandlers.SetRoutes(r); err != nil {
+		slog.Warn("Could not index routes for the Allow header", "error", err)
 	}
 
 	// Create server
+	port := cfg.Server.Port
 	srv := &http.Server{
 		Addr:         ":" + port,
 		Handler:      r,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	// Background jobs: reminders, escalation, digests, capacity sampling, weather, backups and updates
+	registered := make(map[string]bool)
+	register := func(job scheduler.Job) {
+		if err := jobs.Register(job); err != nil {
+			fatal("Failed to register background job", "error", err)
+		}
+		registered[job.Name] = true
+	}
+
+	var notifiers []services.PlantNotifier
+	if pushService.Enabled() {
+		notifiers = append(notifiers, pushService)
+	}
+	// With an escalation chain, overdue emails follow the chain instead of going to everyone
+	if emailService.Enabled() && !cfg.Escalation.Enabled() {
+		notifiers = append(notifiers, emailService)
+	}
+	if slackService.Enabled() {
+		notifiers = append(notifiers, slackService)
+	}
+	if discordService.Enabled() {
+		notifiers = append(notifiers, discordService)
+	}
+	if ntfyService.Enabled() {
+		notifiers = append(notifiers, ntfyService)
+	}
+	if telegramService.Enabled() {
+		notifiers = append(notifiers, telegramService)
+	}
+	if len(notifiers) > 0 {
+		reminders := services.NewNotificationScheduler(plantService, cfg.Notifications.CheckInterval, notifiers...)
+		register(scheduler.Job{
+			Name:       "reminders",
+			Schedule:   scheduler.Every(reminders.Interval()),
+			RunAtStart: true,
+			Run:        flags.Gate(features.Notifications, func(ctx context.Context) error { reminders.CheckOnce(ctx); return nil }),
+		})
+	}
+
+	providers := make(map[string]services.EscalationProvider)
+	if pushService.Enabled() {
+		providers[config.EscalationPush] = pushService
+	}
+	if emailService.Enabled() {
+		providers[config.EscalationEmail] = emailService
+	}
+	if len(providers) > 0 {
+		// Remind users again on the same channel once their snooze ends
+		snoozeService.SetProviders(providers)
+		register(scheduler.Job{
+			Name:     "snooze",
+			Schedule: scheduler.Every(cfg.Notifications.CheckInterval),
+			Run:      flags.Gate(features.Notifications, func(ctx context.Context) error { snoozeService.CheckOnce(ctx); return nil }),
+		})
+	}
+
+	if cfg.Escalation.Enabled() {
+		escalation := services.NewEscalationScheduler(plantService, cfg.Escalation, cfg.Notifications.CheckInterval, providers)
+		register(scheduler.Job{
+			Name:       "escalation",
+			Schedule:   scheduler.Every(escalation.Interval()),
+			RunAtStart: true,
+			Run:        flags.Gate(features.Notifications, func(ctx context.Context) error { escalation.CheckOnce(ctx); return nil }),
+		})
+	}
+
+	// Sample storage size so /health/detailed can project disk growth
+	register(scheduler.Job{
+		Name:       "capacity_sample",
+		Schedule:   scheduler.Every(monitoring.CapacitySampleInterval),
+		Jitter:     time.Minute,
+		RunAtStart: true,
+		Run: func(ctx context.Context) error {
+			if metrics := capacityMonitor.Metrics(); metrics.Error != "" {
+				return errors.New(metrics.Error)
+			}
+			return nil
+		},
+	})
+
+	// Keep the system metrics fresh so health checks never read them
+	if cfg.Server.HealthMetricsInterval > 0 {
+		register(scheduler.Job{
+			Name:       "system_metrics",
+			Schedule:   scheduler.Every(cfg.Server.HealthMetricsInterval),
+			RunAtStart: true,
+			Run:        func(ctx context.Context) error { healthMonitor.SampleMetrics(); return nil },
+		})
+	}
+
+	// Record health checks for the admin uptime timeline
+	if cfg.Server.HealthHistoryInterval > 0 {
+		register(scheduler.Job{
+			Name:       "health_history",
+			Schedule:   scheduler.Every(cfg.Server.HealthHistoryInterval),
+			RunAtStart: true,
+			Run:        healthMonitor.RecordHealth,
+		})
+	}
+
+	// Write last-seen times in batches rather than on every request
+	register(scheduler.Job{
+		Name:     "activity_flush",
+		Schedule: scheduler.Every(activity.DefaultFlushInterval),
+		Run:      func(ctx context.Context) error { return activityTracker.Flush() },
+	})
+
+	// Live status events for /api/plant/events
+	plantEvents := services.NewNotificationScheduler(plantService, services.PlantEventCheckInterval, plantService.EventNotifier())
+	register(scheduler.Job{
+		Name:       "plant_events",
+		Schedule:   scheduler.Every(plantEvents.Interval()),
+		RunAtStart: true,
+		Run:        func(ctx context.Context) error { plantEvents.CheckOnce(ctx); return nil },
+	})
+
+	// Stretch or shrink outdoor plants' timeouts for the local weather
+	if weatherClient := weather.NewClient(cfg.Weather); weatherClient != nil {
+		weatherService := services.NewWeatherService(plantService, weatherClient)
+		register(scheduler.Job{
+			Name:       "weather",
+			Schedule:   scheduler.Every(cfg.Weather.RefreshInterval),
+			Jitter:     time.Minute,
+			RunAtStart: true,
+			Run:        weatherService.Refresh,
+		})
+	}
+
+	// Copy everything off the host, keeping the newest BACKUP_RETAIN backups.
+	// Not run at start, so a crash loop cannot rotate out good backups.
+	if backups != nil {
+		register(scheduler.Job{
+			Name:     "backup",
+			Schedule: scheduler.Every(cfg.Backup.Interval),
+			Jitter:   time.Minute,
+			Run:      backups.Run,
+		})
+	}
+
+	if sandbox != nil && cfg.Demo.ResetInterval > 0 {
+		register(scheduler.Job{
+			Name:     "demo_reset",
+			Schedule: scheduler.Every(cfg.Demo.ResetInterval),
+			Run: func(ctx context.Context) error {
+				if err := sandbox.Reset(); err != nil {
+					return err
+				}
+				// Open dashboards reload the restored plants
+				realtimeHub.Publish(services.ConfigUpdatedMessage, map[string]interface{}{"demo_reset": true})
+				return nil
+			},
+		})
+	}
+
+	if emailService.Enabled() && cfg.Notifications.DigestEnabled {
+		digest := services.NewDigestScheduler(plantService, emailService, cfg.Notifications.DigestHour)
+		register(scheduler.Job{
+			Name:     "email_digest",
+			Schedule: digest,
+			Run:      flags.Gate(features.Notifications, func(ctx context.Context) error { digest.SendOnce(); return nil }),
+		})
+	}
+
+	if emailService.Enabled() && cfg.Notifications.WeeklyReportEnabled {
+		weekly := services.NewWeeklyReportScheduler(plantService, emailService, cfg.Notifications.WeeklyReportDay, cfg.Notifications.DigestHour)
+		register(scheduler.Job{
+			Name:     "weekly_report",
+			Schedule: weekly,
+			Run:      flags.Gate(features.Notifications, func(ctx context.Context) error { weekly.SendOnce(); return nil }),
+		})
+	}
+
+	if selfUpdater != nil && cfg.Update.CheckInterval > 0 {
+		// Jitter spreads release checks from many installations
+		register(scheduler.Job{
+			Name:     "self_update",
+			Schedule: scheduler.Every(cfg.Update.CheckInterval),
+			Jitter:   5 * time.Minute,
+			Run: func(ctx context.Context) error {
+				release, err := selfUpdater.Update(ctx)
+				if errors.Is(err, update.ErrUpdateInProgress) {
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("automatic update failed: %w", err)
+				}
+				if release != nil {
+					slog.Info("Installed release, restarting", "audit", true, "version", release.Version)
+					requestRestart()
+				}
+				return nil
+			},
+		})
+	}
+
+	// Reloaded check intervals and email times reschedule the jobs using
+	// them. Switching the digest or weekly report on or off needs a restart.
+	reschedule := func(schedules map[string]scheduler.Schedule) error {
+		var errs []error
+		for name, schedule := range schedules {
+			if registered[name] {
+				errs = append(errs, jobs.Reschedule(name, schedule))
+			}
+		}
+		return errors.Join(errs...)
+	}
+	reloader.Handle([]string{"NOTIFICATION_CHECK_INTERVAL"}, func(next *config.Config) error {
+		every := scheduler.Every(next.Notifications.CheckInterval)
+		return reschedule(map[string]scheduler.Schedule{"reminders": every, "snooze": every, "escalation": every})
+	})
+	reloader.Handle([]string{"EMAIL_DIGEST_HOUR", "EMAIL_WEEKLY_REPORT_DAY"}, func(next *config.Config) error {
+		return reschedule(map[string]scheduler.Schedule{
+			"email_digest":  services.NewDigestScheduler(plantService, emailService, next.Notifications.DigestHour),
+			"weekly_report": services.NewWeeklyReportScheduler(plantService, emailService, next.Notifications.WeeklyReportDay, next.Notifications.DigestHour),
+		})
+	})
+
+	jobs.Start(context.Background())
+
+	// Sensors publishing readings over MQTT, alongside the HTTP ingestion API
+	mqttCtx, stopMQTT := context.WithCancel(context.Background())
+	defer stopMQTT()
+	mqttDone := make(chan struct{})
+	if cfg.MQTT.Enabled() {
+		subscriber := mqtt.NewSubscriber(cfg.MQTT)
+		go func() {
+			defer close(mqttDone)
+			subscriber.Run(mqttCtx, func(msg mqtt.Message) {
+				device, ok := mqtt.DeviceFromTopic(cfg.MQTT.Topic, msg.Topic)
+				if !ok || !flags.Enabled(features.Sensors) {
+					return
+				}
+				if err := sensorService.RecordMessage(device, msg.Payload); err != nil {
+					slog.Warn("Rejected MQTT sensor reading", "device", device, "topic", msg.Topic, "error", err)
+				}
+			})
+		}()
+	} else {
+		close(mqttDone)
+	}
+
+	// Components stop in this order on shutdown: first everything that
+	// records waterings or sends reminders, then the notifications and
+	// live updates they queued, then state that is flushed at exit
+	shutdown := lifecycle.New()
+	// Event streams never end on their own, so end them as the server stops
+	// accepting requests rather than wait out the deadline
+	srv.RegisterOnShutdown(plantService.Events().Close)
+	shutdown.Add("http", srv.Shutdown)
+	shutdown.Add("mqtt", lifecycle.Cancel(stopMQTT, mqttDone))
+	// Let running jobs finish, so a reminder, digest or update is not cut
+	// off halfway
+	shutdown.Add("scheduler", jobs.Stop)
+	shutdown.Add("watering_notifications", plantService.DrainNotifications)
+	shutdown.Add("realtime", realtimeHub.Shutdown)
+	shutdown.Add("activity", lifecycle.Func(activityTracker.Flush))
+	if traceExporter != nil {
+		shutdown.Add("tracing", traceExporter.Shutdown)
 	}
 
 	// Start server in goroutine
 	go func() {
-		log.Printf("Starting server on port %s", port)
+		slog.Info("Starting server", "port", port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
+			fatal("Server failed to start", "error", err)
 		}
 	}()
+	readiness.SetServing(true)
 
-	// Wait for interrupt signal to gracefully shutdown
+	// SIGHUP reloads the configuration, as is usual for daemons
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if _, err := reloader.Reload("SIGHUP"); err != nil {
+				slog.Error("Configuration reload failed, keeping the running configuration", "error", err)
+			}
+		}
+	}()
+
+	// Wait for interrupt signal or an installed update to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
 
-	log.Println("Shutting down server...")
+	restarting := false
+	select {
+	case <-quit:
+	case <-restartRequests:
+		restarting = true
+	}
+
+	slog.Info("Shutting down server")
+	readiness.SetServing(false)
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	if err := shutdown.Shutdown(ctx); err != nil {
+		slog.Warn("Shutdown did not finish cleanly", "error", err)
+	}
+
+	if restarting {
+		// Deferred calls do not run across exec, so release the store first
+		store.Close()
+		slog.Info("Restarting into the updated binary")
+		if err := update.Restart(); err != nil {
+			fatal("Restart failed, start the server manually", "error", err)
+		}
+	}
+
+	slog.Info("Server exited")
+}
+
+// fatal logs msg at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// runMigrationDryRun reports the migrations that would be applied to the data file
+func runMigrationDryRun(dataFile string) {
+	if dataFile == "" {
+		fatal("DATA_FILE is not set; in-memory storage has no migrations")
+	}
+
+	result, err := storage.MigrateFile(dataFile, true)
+	if err != nil {
+		fatal("Migration dry run failed", "error", err)
 	}
 
-	log.Println("Server exited")
+	if len(result.Pending) == 0 {
+		slog.Info("Data file is up to date, no pending migrations", "path", dataFile)
+		return
+	}
+	for _, name := range result.Pending {
+		slog.Info("Would apply migration", "migration", name)
+	}
 }
 
 // loadEnvFiles loads environment variables from .env files in order of precedence
 func loadEnvFiles() {
 	// Check if we're in demo mode - if so, don't load any env files
 	if os.Getenv("WATERED_MODE") == "demo" {
-		log.Printf("Demo mode: Skipping environment file loading")
+		slog.Info("Demo mode: skipping environment file loading")
 		return
 	}
 
-	// Environment files to load in order of precedence (last wins)
-	// Note: .env.example is never loaded automatically - it's just a template
-	envFiles := []string{
-		".env",       // Main environment file
-		".env.local", // Local overrides (highest priority)
-	}
-
-	// Also check for environment-specific files
-	if env := os.Getenv("ENVIRONMENT"); env != "" {
-		envFiles = append(envFiles, ".env."+env)
-	}
-
 	loadedFiles := []string{}
 
-	for _, file := range envFiles {
+	for _, file := range envFiles() {
 		if err := godotenv.Load(file); err == nil {
 			loadedFiles = append(loadedFiles, file)
 		}
@@ -252,62 +1187,68 @@ func loadEnvFiles() {
 	}
 
 	if len(loadedFiles) > 0 {
-		log.Printf("Loaded environment variables from: %v", loadedFiles)
+		slog.Info("Loaded environment files", "files", loadedFiles)
 	}
-
-	// Log current configuration status (without sensitive values)
-	logConfigurationStatus()
 }
 
-// logConfigurationStatus logs the current configuration status
-func logConfigurationStatus() {
-	mode := getEnvOrDefault(os.Getenv("WATERED_MODE"), "production")
-	clientID := os.Getenv("GOOGLE_CLIENT_ID")
-	sessionSecret := os.Getenv("SESSION_SECRET")
-	allowedEmails := os.Getenv("ALLOWED_EMAILS")
-	adminEmails := os.Getenv("ADMIN_EMAILS")
-	environment := os.Getenv("ENVIRONMENT")
-
-	log.Printf("Configuration Status:")
-	log.Printf("  Mode: %s", mode)
-	log.Printf("  Environment: %s", getEnvOrDefault(environment, "development"))
-
-	if mode == "demo" {
-		log.Printf("  OAuth Mode: Demo (Google OAuth disabled)")
-		log.Printf("  Demo Login: Available at /auth/demo-login")
-		log.Printf("  Security: Demo users only")
-	} else if clientID != "" && clientID != "demo-client-id" {
-		log.Printf("  OAuth Mode: Production (Google OAuth enabled)")
-		log.Printf("  Demo Login: Disabled")
-	} else {
-		log.Printf("  OAuth Mode: Demo fallback (Google OAuth credentials missing)")
-		log.Printf("  Demo Login: Available at /auth/demo-login")
-		log.Printf("  Warning: Production mode requires GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET")
+// envFiles returns the environment files to load in order of precedence
+func envFiles() []string {
+	// Environment files to load in order of precedence (last wins)
+	// Note: .env.example is never loaded automatically - it's just a template
+	files := []string{
+		".env",       // Main environment file
+		".env.local", // Local overrides (highest priority)
 	}
 
-	if sessionSecret != "" && sessionSecret != "development-secret-change-in-production" {
-		log.Printf("  Session Secret: Configured")
-	} else {
-		log.Printf("  Session Secret: Using development default")
+	// Also check for environment-specific files
+	if env := os.Getenv("ENVIRONMENT"); env != "" {
+		files = append(files, ".env."+env)
 	}
+	return files
+}
 
-	if allowedEmails != "" {
-		log.Printf("  Allowed Emails: Configured")
-	} else {
-		log.Printf("  Allowed Emails: Using demo defaults")
+// environ returns the process environment as a map
+func environ() map[string]string {
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			env[key] = value
+		}
 	}
+	return env
+}
 
-	if adminEmails != "" {
-		log.Printf("  Admin Emails: Configured")
-	} else {
-		log.Printf("  Admin Emails: Using demo defaults")
+// readEnv returns the environment a reload reads the configuration from:
+// the .env files as they are on disk now, merged like loadEnvFiles does,
+// under the process environment the server started with
+func readEnv(processEnv map[string]string) func(string) string {
+	env := make(map[string]string)
+	if processEnv["WATERED_MODE"] != "demo" {
+		for _, file := range envFiles() {
+			values, err := godotenv.Read(file)
+			if err != nil {
+				continue
+			}
+			// godotenv.Load never overrides a variable, so the first file
+			// setting one wins
+			for key, value := range values {
+				if _, ok := env[key]; !ok {
+					env[key] = value
+				}
+			}
+		}
+	}
+	for key, value := range processEnv {
+		env[key] = value
 	}
+	return func(key string) string { return env[key] }
 }
 
-// getEnvOrDefault returns the environment variable value or default if empty
-func getEnvOrDefault(value, defaultValue string) string {
-	if value == "" {
-		return defaultValue
+// logStartupReport logs what the configuration turns on as one structured
+// line, followed by a warning for each likely misconfiguration
+func logStartupReport(report config.Report) {
+	slog.Info("Startup report", "config", report)
+	for _, warning := range report.Warnings {
+		slog.Warn(warning)
 	}
-	return value
 }
@@ -0,0 +1,187 @@
+// Command wateredctl provides offline maintenance tools for a Watered data
+// store. Stop the server before running it against the same data file.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"watered/internal/config"
+	"watered/internal/push"
+	"watered/internal/services"
+	"watered/internal/storage"
+	"watered/internal/update"
+)
+
+const usage = `Usage: wateredctl <command> [flags]
+
+Commands:
+  fsck          check stored data for consistency problems
+  vapid-keys    generate a VAPID key pair for Web Push notifications
+  update-keys   generate a key pair for signing release binaries
+  sign-release  sign release binaries for self-update
+
+Run "wateredctl <command> -h" for command flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "fsck":
+		os.Exit(runFsck(os.Args[2:], os.Stdout))
+	case "vapid-keys":
+		os.Exit(runVAPIDKeys(os.Args[2:], os.Stdout))
+	case "update-keys":
+		os.Exit(runUpdateKeys(os.Args[2:], os.Stdout))
+	case "sign-release":
+		os.Exit(runSignRelease(os.Args[2:], os.Stdout))
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+// runFsck checks (and optionally repairs) a data store. It returns 0 when the
+// data is consistent, 1 when unresolved issues remain and 2 on error.
+func runFsck(args []string, out io.Writer) int {
+	// Only the storage paths matter here, so settings the server would
+	// reject do not stop an offline check
+	cfg, _ := config.Load()
+
+	fs := flag.NewFlagSet("fsck", flag.ContinueOnError)
+	dataFile := fs.String("data", cfg.Storage.DataFile, "path to the JSON data file")
+	journalFile := fs.String("journal", cfg.Storage.JournalFile, "path to the journal file (used when -data is empty)")
+	repair := fs.Bool("repair", false, "repair issues that have an automatic fix")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *dataFile == "" && *journalFile == "" {
+		fmt.Fprintln(os.Stderr, "no data store given; set -data or -journal (or DATA_FILE / JOURNAL_FILE)")
+		return 2
+	}
+
+	store, err := storage.Open(config.StorageConfig{DataFile: *dataFile, JournalFile: *journalFile})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+	defer store.Close()
+
+	report, err := services.NewIntegrityService(store).Check(*repair)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "integrity check failed: %v\n", err)
+		return 2
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		for _, issue := range report.Issues {
+			status := ""
+			switch {
+			case issue.Repaired:
+				status = " (repaired)"
+			case issue.Repairable:
+				status = " (repairable with -repair)"
+			}
+			fmt.Fprintf(out, "%-7s %-32s %s%s\n", issue.Severity, issue.Code, issue.Message, status)
+		}
+		fmt.Fprintf(out, "%d issues found, %d unresolved\n", len(report.Issues), len(report.Unresolved()))
+	}
+
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
+
+// runVAPIDKeys prints a new VAPID key pair as environment variable assignments
+func runVAPIDKeys(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("vapid-keys", flag.ContinueOnError)
+	subject := fs.String("subject", "mailto:admin@example.com", "contact URI for push service operators")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	keys, err := push.GenerateVAPIDKeys(*subject)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate keys: %v\n", err)
+		return 2
+	}
+
+	fmt.Fprintf(out, "VAPID_PUBLIC_KEY=%s\n", keys.PublicKey)
+	fmt.Fprintf(out, "VAPID_PRIVATE_KEY=%s\n", keys.PrivateKey)
+	fmt.Fprintf(out, "VAPID_SUBJECT=%s\n", keys.Subject)
+	return 0
+}
+
+// runUpdateKeys prints a new release signing key pair. The public key goes in
+// the server's environment; keep the signing key with the release tooling.
+func runUpdateKeys(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("update-keys", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	publicKey, privateKey, err := update.GenerateKeys()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+
+	fmt.Fprintf(out, "UPDATE_PUBLIC_KEY=%s\n", publicKey)
+	fmt.Fprintf(out, "UPDATE_SIGNING_KEY=%s\n", privateKey)
+	return 0
+}
+
+// runSignRelease writes a <binary>.sig file next to each binary given. The
+// signature covers the release version and the file name, which must be the
+// asset name the binary is published under.
+func runSignRelease(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("sign-release", flag.ContinueOnError)
+	key := fs.String("key", os.Getenv("UPDATE_SIGNING_KEY"), "release signing key (default $UPDATE_SIGNING_KEY)")
+	version := fs.String("version", "", "release version the binaries are tagged as, such as v1.2.0")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *key == "" || *version == "" || fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: wateredctl sign-release [-key KEY] -version VERSION <binary>...")
+		return 2
+	}
+
+	for _, path := range fs.Args() {
+		binary, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 2
+		}
+
+		signature, err := update.Sign(*key, *version, filepath.Base(path), binary)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 2
+		}
+
+		if err := os.WriteFile(path+".sig", []byte(signature+"\n"), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 2
+		}
+		fmt.Fprintf(out, "signed %s\n", path)
+	}
+	return 0
+}
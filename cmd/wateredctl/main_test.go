@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"watered/internal/update"
+)
+
+func TestRunFsck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watered.json")
+	data := `{"config":{"timeout_hours":24,"allowed_emails":["user@example.com"],"admin_emails":["admin@example.com"]}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write data file: %v", err)
+	}
+
+	var out bytes.Buffer
+	if code := runFsck([]string{"-data", path}, &out); code != 1 {
+		t.Errorf("Expected exit code 1 with unresolved issues, got %d", code)
+	}
+	if !strings.Contains(out.String(), "config_admin_not_allowed") {
+		t.Errorf("Expected admin issue in output, got %s", out.String())
+	}
+
+	out.Reset()
+	if code := runFsck([]string{"-data", path, "-repair"}, &out); code != 0 {
+		t.Errorf("Expected exit code 0 after repair, got %d: %s", code, out.String())
+	}
+
+	out.Reset()
+	if code := runFsck([]string{"-data", path}, &out); code != 0 {
+		t.Errorf("Expected clean data after repair, got %d: %s", code, out.String())
+	}
+}
+
+func TestRunFsck_NoStore(t *testing.T) {
+	t.Setenv("DATA_FILE", "")
+	t.Setenv("JOURNAL_FILE", "")
+
+	var out bytes.Buffer
+	if code := runFsck(nil, &out); code != 2 {
+		t.Errorf("Expected exit code 2 without a data store, got %d", code)
+	}
+}
+
+func TestRunVAPIDKeys(t *testing.T) {
+	var out bytes.Buffer
+	if code := runVAPIDKeys([]string{"-subject", "mailto:ops@example.com"}, &out); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+
+	for _, name := range []string{"VAPID_PUBLIC_KEY=", "VAPID_PRIVATE_KEY=", "VAPID_SUBJECT=mailto:ops@example.com"} {
+		if !strings.Contains(out.String(), name) {
+			t.Errorf("Expected %s in output, got %s", name, out.String())
+		}
+	}
+}
+
+func TestRunSignRelease(t *testing.T) {
+	var keys bytes.Buffer
+	if code := runUpdateKeys(nil, &keys); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+
+	values := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(keys.String()), "\n") {
+		name, value, _ := strings.Cut(line, "=")
+		values[name] = value
+	}
+	if values["UPDATE_PUBLIC_KEY"] == "" || values["UPDATE_SIGNING_KEY"] == "" {
+		t.Fatalf("Expected both keys in output, got %s", keys.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "watered-linux-arm64")
+	binary := []byte("release binary")
+	if err := os.WriteFile(path, binary, 0755); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+
+	var out bytes.Buffer
+	if code := runSignRelease([]string{"-key", values["UPDATE_SIGNING_KEY"], "-version", "v1.2.0", path}, &out); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+
+	signature, err := os.ReadFile(path + ".sig")
+	if err != nil {
+		t.Fatalf("Expected signature file: %v", err)
+	}
+	publicKey, err := update.ParsePublicKey(values["UPDATE_PUBLIC_KEY"])
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+	if err := update.Verify(publicKey, "v1.2.0", "watered-linux-arm64", binary, string(signature)); err != nil {
+		t.Errorf("Expected signature to verify: %v", err)
+	}
+	if err := update.Verify(publicKey, "v1.3.0", "watered-linux-arm64", binary, string(signature)); err == nil {
+		t.Error("Expected signature not to verify for another version")
+	}
+
+	if code := runSignRelease([]string{"-key", values["UPDATE_SIGNING_KEY"], path}, &out); code != 2 {
+		t.Errorf("Expected exit code 2 without a version, got %d", code)
+	}
+
+	t.Setenv("UPDATE_SIGNING_KEY", "")
+	if code := runSignRelease([]string{path}, &out); code != 2 {
+		t.Errorf("Expected exit code 2 without a key, got %d", code)
+	}
+}
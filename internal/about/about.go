@@ -0,0 +1,117 @@
+// Package about describes the running build and the open-source licenses of
+// the dependencies compiled into it.
+package about
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+//go:generate go run gen.go -o licenses.json
+
+// Commit and BuildTime identify the source a release was built from. Release
+// builds set them with -ldflags "-X watered/internal/about.Commit=... -X
+// watered/internal/about.BuildTime=...". Builds without them fall back to
+// the version control details the Go toolchain records.
+var (
+	Commit    string
+	BuildTime string
+)
+
+//go:embed licenses.json
+var licensesJSON []byte
+
+// License is the license of one module compiled into the binary
+type License struct {
+	Module  string `json:"module"`
+	Version string `json:"version"`
+	License string `json:"license"`
+	Text    string `json:"text"`
+}
+
+// Info describes the running build
+type Info struct {
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	GoVersion string    `json:"go_version"`
+	Platform  string    `json:"platform"`
+	Revision  string    `json:"revision,omitempty"`
+	BuildTime string    `json:"build_time,omitempty"`
+	Modified  bool      `json:"modified,omitempty"`
+	Licenses  []License `json:"licenses"`
+}
+
+// Build identifies the running binary
+type Build struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// ReadBuild returns the running binary's build details
+func ReadBuild(version string) Build {
+	build := Build{
+		Version:   version,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				build.Commit = setting.Value
+			case "vcs.time":
+				build.BuildTime = setting.Value
+			case "vcs.modified":
+				build.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if Commit != "" {
+		build.Commit = Commit
+	}
+	if BuildTime != "" {
+		build.BuildTime = BuildTime
+	}
+	return build
+}
+
+// Build returns the build details the info was loaded with
+func (i *Info) Build() Build {
+	return Build{
+		Version:   i.Version,
+		Commit:    i.Revision,
+		BuildTime: i.BuildTime,
+		Modified:  i.Modified,
+		GoVersion: i.GoVersion,
+		Platform:  i.Platform,
+	}
+}
+
+// Load combines the embedded license list with build details recorded by the
+// Go toolchain
+func Load(version string) (*Info, error) {
+	build := ReadBuild(version)
+	info := &Info{
+		Name:      "Watered",
+		Version:   build.Version,
+		GoVersion: build.GoVersion,
+		Platform:  build.Platform,
+		Revision:  build.Commit,
+		BuildTime: build.BuildTime,
+		Modified:  build.Modified,
+	}
+
+	if err := json.Unmarshal(licensesJSON, &info.Licenses); err != nil {
+		return nil, fmt.Errorf("failed to decode embedded licenses: %w", err)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,60 @@
+package about
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	info, err := Load("v1.2.3")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if info.Version != "v1.2.3" || info.GoVersion != runtime.Version() {
+		t.Errorf("Unexpected build info: %+v", info)
+	}
+
+	licenses := map[string]License{}
+	for _, license := range info.Licenses {
+		if license.Text == "" {
+			t.Errorf("Expected license text for %s", license.Module)
+		}
+		licenses[license.Module] = license
+	}
+
+	for module, expected := range map[string]string{
+		"github.com/go-chi/chi/v5":    "MIT",
+		"github.com/gorilla/sessions": "BSD-3-Clause",
+		"std":                         "BSD-3-Clause",
+	} {
+		if got := licenses[module].License; got != expected {
+			t.Errorf("Expected %s to be %s, got %q", module, expected, got)
+		}
+	}
+
+	if _, bundled := licenses["github.com/stretchr/testify"]; bundled {
+		t.Error("Test-only dependencies should not be listed")
+	}
+}
+
+func TestReadBuild_LinkerFlags(t *testing.T) {
+	defer func(commit, buildTime string) { Commit, BuildTime = commit, buildTime }(Commit, BuildTime)
+	Commit, BuildTime = "0123abc", "2024-06-01T12:00:00Z"
+
+	build := ReadBuild("v1.2.3")
+	if build.Version != "v1.2.3" || build.Commit != "0123abc" || build.BuildTime != "2024-06-01T12:00:00Z" {
+		t.Errorf("Expected the linker flags to be used, got %+v", build)
+	}
+	if build.GoVersion != runtime.Version() || build.Platform != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("Unexpected runtime details: %+v", build)
+	}
+
+	info, err := Load("v1.2.3")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if info.Build() != build {
+		t.Errorf("Expected the info's build to match, got %+v", info.Build())
+	}
+}
@@ -0,0 +1,103 @@
+//go:build ignore
+
+// gen writes licenses.json with the license of every module linked into the
+// server binary, plus the Go standard library. Run it with go generate.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+type license struct {
+	Module  string `json:"module"`
+	Version string `json:"version"`
+	License string `json:"license"`
+	Text    string `json:"text"`
+}
+
+func main() {
+	out := flag.String("o", "licenses.json", "output file")
+	pkg := flag.String("pkg", "watered/cmd/server", "package whose dependencies are listed")
+	flag.Parse()
+
+	cmd := exec.Command("go", "list", "-deps", "-f", "{{with .Module}}{{if not .Main}}{{.Path}} {{.Version}} {{.Dir}}{{end}}{{end}}", *pkg)
+	cmd.Stderr = os.Stderr
+	listing, err := cmd.Output()
+	if err != nil {
+		log.Fatalf("go list failed: %v", err)
+	}
+
+	seen := map[string]bool{}
+	var licenses []license
+	scanner := bufio.NewScanner(bytes.NewReader(listing))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || seen[fields[0]] {
+			continue
+		}
+		seen[fields[0]] = true
+
+		text, err := readLicense(fields[2])
+		if err != nil {
+			log.Fatalf("%s: %v", fields[0], err)
+		}
+		licenses = append(licenses, license{Module: fields[0], Version: fields[1], License: identify(text), Text: text})
+	}
+	sort.Slice(licenses, func(i, j int) bool { return licenses[i].Module < licenses[j].Module })
+
+	goLicense, err := os.ReadFile(filepath.Join(runtime.GOROOT(), "LICENSE"))
+	if err != nil {
+		log.Fatalf("Go standard library: %v", err)
+	}
+	licenses = append(licenses, license{Module: "std", Version: runtime.Version(), License: identify(string(goLicense)), Text: string(goLicense)})
+
+	data, err := json.MarshalIndent(licenses, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readLicense returns the first license file found in a module directory
+func readLicense(dir string) (string, error) {
+	for _, name := range []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENCE", "COPYING"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", os.ErrNotExist
+}
+
+// identify names common licenses by a phrase unique to their text
+func identify(text string) string {
+	switch {
+	case strings.Contains(text, "Apache License") && strings.Contains(text, "Version 2.0"):
+		return "Apache-2.0"
+	case strings.Contains(text, "Mozilla Public License Version 2.0"):
+		return "MPL-2.0"
+	case strings.Contains(text, "Permission is hereby granted, free of charge"):
+		return "MIT"
+	case strings.Contains(text, "Neither the name"):
+		return "BSD-3-Clause"
+	case strings.Contains(text, "Redistribution and use in source and binary forms"):
+		return "BSD-2-Clause"
+	default:
+		return "see text"
+	}
+}
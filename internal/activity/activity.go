@@ -0,0 +1,151 @@
+// Package activity records when each signed-in user was last seen and from
+// which client. Requests only update memory; changes are written to storage
+// in batches by Flush, so tracking costs no storage write per request.
+package activity
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+// DefaultFlushInterval is how often recorded activity should be flushed to
+// storage
+const DefaultFlushInterval = time.Minute
+
+// resolution is how stale a user's last-seen time must be before a request
+// updates it, so a busy session marks the user dirty at most once a minute
+const resolution = time.Minute
+
+// UserFunc returns the email of the signed-in user making a request, or ""
+type UserFunc func(r *http.Request) string
+
+// Tracker keeps every user's latest activity in memory and remembers which
+// records changed since the last flush
+type Tracker struct {
+	storage storage.Storage
+	now     func() time.Time
+
+	mu    sync.Mutex
+	users map[string]*models.UserActivity
+	dirty map[string]bool
+}
+
+// NewTracker creates a tracker seeded with the activity already in storage
+func NewTracker(storage storage.Storage) (*Tracker, error) {
+	stored, err := storage.ListUserActivity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user activity: %w", err)
+	}
+
+	t := &Tracker{
+		storage: storage,
+		now:     time.Now,
+		users:   make(map[string]*models.UserActivity, len(stored)),
+		dirty:   make(map[string]bool),
+	}
+	for _, record := range stored {
+		t.users[record.Email] = record
+	}
+	return t, nil
+}
+
+// Touch records that email made a request from the given client
+func (t *Tracker) Touch(email, userAgent, clientIP string) {
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, exists := t.users[email]
+	if !exists {
+		record = &models.UserActivity{Email: email, FirstSeen: now}
+		t.users[email] = record
+	} else if now.Sub(record.LastSeen) < resolution && record.UserAgent == userAgent && record.ClientIP == clientIP {
+		return
+	}
+
+	record.LastSeen = now
+	record.UserAgent = userAgent
+	record.ClientIP = clientIP
+	t.dirty[email] = true
+}
+
+// Flush writes the records that changed since the last flush in one batch.
+// Records that fail to save are retried on the next flush.
+func (t *Tracker) Flush() error {
+	t.mu.Lock()
+	batch := make([]*models.UserActivity, 0, len(t.dirty))
+	for email := range t.dirty {
+		record := *t.users[email]
+		batch = append(batch, &record)
+	}
+	t.dirty = make(map[string]bool)
+	t.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := t.storage.SaveUserActivity(batch); err != nil {
+		t.mu.Lock()
+		for _, record := range batch {
+			t.dirty[record.Email] = true
+		}
+		t.mu.Unlock()
+		return fmt.Errorf("failed to save activity for %d users: %w", len(batch), err)
+	}
+	return nil
+}
+
+// Forget drops a user's activity from memory and storage, such as when they
+// delete their account
+func (t *Tracker) Forget(email string) error {
+	t.mu.Lock()
+	delete(t.users, email)
+	delete(t.dirty, email)
+	t.mu.Unlock()
+
+	if err := t.storage.DeleteUserActivity(email); err != nil {
+		return fmt.Errorf("failed to delete activity for %s: %w", email, err)
+	}
+	return nil
+}
+
+// List returns every user's latest activity, including changes not yet
+// flushed, ordered by email
+func (t *Tracker) List() []*models.UserActivity {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]*models.UserActivity, 0, len(t.users))
+	for _, record := range t.users {
+		recordCopy := *record
+		result = append(result, &recordCopy)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Email < result[j].Email })
+	return result
+}
+
+// Middleware records activity for every request made by a signed-in user.
+// Behind a proxy it relies on realip.Middleware having rewritten
+// RemoteAddr.
+func (t *Tracker) Middleware(user UserFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if email := user(r); email != "" {
+				host, _, err := net.SplitHostPort(r.RemoteAddr)
+				if err != nil {
+					host = r.RemoteAddr
+				}
+				t.Touch(email, r.UserAgent(), host)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package activity
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStorage counts activity writes and can be made to fail them
+type countingStorage struct {
+	*storage.MemoryStorage
+	saves int
+	err   error
+}
+
+func (s *countingStorage) SaveUserActivity(activity []*models.UserActivity) error {
+	s.saves++
+	if s.err != nil {
+		return s.err
+	}
+	return s.MemoryStorage.SaveUserActivity(activity)
+}
+
+// newTestTracker returns a tracker with a controllable clock
+func newTestTracker(t *testing.T) (*Tracker, *countingStorage, *time.Time) {
+	t.Helper()
+
+	store := &countingStorage{MemoryStorage: storage.NewMemoryStorage()}
+	tracker, err := NewTracker(store)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+	return tracker, store, &now
+}
+
+func TestTracker_BatchesWrites(t *testing.T) {
+	tracker, store, now := newTestTracker(t)
+
+	for i := 0; i < 10; i++ {
+		tracker.Touch("a@example.com", "Firefox", "10.0.0.1")
+		tracker.Touch("b@example.com", "Safari", "10.0.0.2")
+	}
+	assert.Equal(t, 0, store.saves, "Touch must not write to storage")
+
+	require.NoError(t, tracker.Flush())
+	assert.Equal(t, 1, store.saves, "Expected one write for the whole batch")
+	stored, _ := store.ListUserActivity()
+	require.Len(t, stored, 2)
+	assert.Equal(t, "Firefox", stored[0].UserAgent)
+
+	// Nothing changed, so nothing is written
+	require.NoError(t, tracker.Flush())
+	assert.Equal(t, 1, store.saves)
+
+	// Requests within the resolution do not mark the user dirty
+	*now = now.Add(30 * time.Second)
+	tracker.Touch("a@example.com", "Firefox", "10.0.0.1")
+	require.NoError(t, tracker.Flush())
+	assert.Equal(t, 1, store.saves)
+
+	// A new client is recorded straight away
+	tracker.Touch("a@example.com", "Firefox Mobile", "10.0.0.3")
+	require.NoError(t, tracker.Flush())
+	assert.Equal(t, 2, store.saves)
+
+	*now = now.Add(time.Hour)
+	tracker.Touch("b@example.com", "Safari", "10.0.0.2")
+	activity := tracker.List()
+	require.Len(t, activity, 2)
+	assert.Equal(t, "Firefox Mobile", activity[0].UserAgent)
+	assert.Equal(t, *now, activity[1].LastSeen, "Expected List to include unflushed activity")
+	assert.Equal(t, now.Add(-time.Hour-30*time.Second), activity[1].FirstSeen)
+}
+
+func TestTracker_RetriesFailedFlush(t *testing.T) {
+	tracker, store, _ := newTestTracker(t)
+	tracker.Touch("a@example.com", "Firefox", "10.0.0.1")
+
+	store.err = errors.New("disk full")
+	assert.Error(t, tracker.Flush())
+
+	store.err = nil
+	require.NoError(t, tracker.Flush())
+	stored, _ := store.ListUserActivity()
+	assert.Len(t, stored, 1)
+}
+
+func TestTracker_LoadsStoredActivity(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	firstSeen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.SaveUserActivity([]*models.UserActivity{{Email: "a@example.com", FirstSeen: firstSeen, LastSeen: firstSeen}})
+
+	tracker, err := NewTracker(store)
+	require.NoError(t, err)
+	tracker.Touch("a@example.com", "Firefox", "10.0.0.1")
+
+	activity := tracker.List()
+	require.Len(t, activity, 1)
+	assert.Equal(t, firstSeen, activity[0].FirstSeen)
+	assert.True(t, activity[0].LastSeen.After(firstSeen))
+}
+
+func TestTracker_Forget(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	store.SaveUserActivity([]*models.UserActivity{{Email: "a@example.com"}, {Email: "b@example.com"}})
+
+	tracker, err := NewTracker(store)
+	require.NoError(t, err)
+	tracker.Touch("a@example.com", "Firefox", "10.0.0.1")
+	require.NoError(t, tracker.Forget("a@example.com"))
+	require.NoError(t, tracker.Flush())
+
+	activity := tracker.List()
+	require.Len(t, activity, 1)
+	assert.Equal(t, "b@example.com", activity[0].Email)
+	stored, _ := store.ListUserActivity()
+	require.Len(t, stored, 1)
+	assert.Equal(t, "b@example.com", stored[0].Email)
+}
+
+func TestTracker_Middleware(t *testing.T) {
+	tracker, _, _ := newTestTracker(t)
+	handler := tracker.Middleware(func(r *http.Request) string {
+		return r.Header.Get("X-User")
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	anonymous := httptest.NewRequest(http.MethodGet, "/", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), anonymous)
+	assert.Empty(t, tracker.List())
+
+	signedIn := httptest.NewRequest(http.MethodGet, "/", nil)
+	signedIn.Header.Set("X-User", "a@example.com")
+	signedIn.Header.Set("User-Agent", "Firefox")
+	signedIn.RemoteAddr = "192.0.2.1:1234"
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, signedIn)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	activity := tracker.List()
+	require.Len(t, activity, 1)
+	assert.Equal(t, "Firefox", activity[0].UserAgent)
+	assert.Equal(t, "192.0.2.1", activity[0].ClientIP)
+}
@@ -0,0 +1,36 @@
+// Package apidocs embeds the hand-maintained OpenAPI description of the
+// HTTP API. Update openapi.json alongside any route or response change.
+package apidocs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+// Spec returns the OpenAPI 3 document as JSON
+func Spec() []byte {
+	return spec
+}
+
+// Document is the subset of the OpenAPI document inspected by the server
+type Document struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+// Load parses the embedded document
+func Load() (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	return &doc, nil
+}
@@ -0,0 +1,74 @@
+package apidocs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	doc, err := Load()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(doc.OpenAPI, "3."), "unexpected OpenAPI version %q", doc.OpenAPI)
+	assert.Equal(t, "Watered API", doc.Info.Title)
+
+	for path, method := range map[string]string{
+		"/api/v1/plant":             "get",
+		"/api/v1/plants":            "post",
+		"/api/v1/plants/{id}/water": "post",
+		"/api/v1/auth/status":       "get",
+		"/auth/logout":              "post",
+		"/admin/config":             "get",
+		"/admin/users/{email}":      "delete",
+		"/admin/integrity/repair":   "post",
+	} {
+		assert.Contains(t, doc.Paths[path], method, "%s %s is not documented", method, path)
+	}
+
+	// Deprecated aliases are described once, under their v1 paths
+	assert.NotContains(t, doc.Paths, "/api/plant")
+	assert.NotContains(t, doc.Paths, "/auth/status")
+}
+
+func TestSpecReferencesResolve(t *testing.T) {
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(Spec(), &document))
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			if ref, ok := value["$ref"].(string); ok {
+				assert.NotNil(t, resolve(document, ref), "unresolved reference %s", ref)
+			}
+			for _, child := range value {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				walk(child)
+			}
+		}
+	}
+	walk(document)
+}
+
+// resolve follows a local JSON pointer such as #/components/schemas/Plant
+func resolve(document map[string]interface{}, ref string) interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var node interface{} = document
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = object[part]
+	}
+	return node
+}
@@ -0,0 +1,65 @@
+// Package apiversion mounts versions of the HTTP API side by side. Each
+// version lives under /api/{name}, so a v2 with breaking changes can be
+// added next to v1 without moving existing clients. The unversioned /api
+// paths that predate versioning are kept as deprecated aliases of v1.
+package apiversion
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Response headers telling clients a path is deprecated (RFC 9745) and
+// where its replacement lives (RFC 8288)
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderLink        = "Link"
+)
+
+// LegacyDeprecatedAt is when the unversioned /api paths were deprecated in
+// favour of /api/v1
+var LegacyDeprecatedAt = time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC)
+
+// Version is one version of the API
+type Version struct {
+	// Name is the path segment the version is served under, such as v1
+	Name string
+	// Routes registers the version's endpoints relative to its root
+	Routes func(r chi.Router)
+}
+
+// Mount serves each version under r at /{name}
+func Mount(r chi.Router, versions ...Version) {
+	for _, v := range versions {
+		r.Route("/"+v.Name, v.Routes)
+	}
+}
+
+// MountLegacy serves v's routes directly under r, which is mounted at
+// prefix, marking every response deprecated in favour of the same path
+// under prefix/{name}
+func MountLegacy(r chi.Router, prefix string, v Version) {
+	r.Group(func(r chi.Router) {
+		r.Use(Deprecated(LegacyDeprecatedAt, prefix, prefix+"/"+v.Name))
+		v.Routes(r)
+	})
+}
+
+// Deprecated marks responses with a Deprecation header dated at and a
+// successor-version Link to the request path with from replaced by to
+func Deprecated(at time.Time, from, to string) func(http.Handler) http.Handler {
+	deprecation := "@" + strconv.FormatInt(at.Unix(), 10)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(HeaderDeprecation, deprecation)
+			if rest, ok := strings.CutPrefix(r.URL.Path, from); ok {
+				w.Header().Add(HeaderLink, "<"+to+rest+`>; rel="successor-version"`)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
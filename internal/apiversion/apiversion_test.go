@@ -0,0 +1,75 @@
+package apiversion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMount(t *testing.T) {
+	ok := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}
+	}
+	v1 := Version{Name: "v1", Routes: func(r chi.Router) {
+		r.Get("/plants/{id}", ok("v1 plant"))
+	}}
+	v2 := Version{Name: "v2", Routes: func(r chi.Router) {
+		r.Get("/plants/{id}", ok("v2 plant"))
+	}}
+
+	router := chi.NewRouter()
+	router.Route("/api", func(r chi.Router) {
+		Mount(r, v1, v2)
+		MountLegacy(r, "/api", v1)
+	})
+
+	tests := []struct {
+		path        string
+		body        string
+		deprecation string
+		link        string
+	}{
+		{"/api/v1/plants/2", "v1 plant", "", ""},
+		{"/api/v2/plants/2", "v2 plant", "", ""},
+		{"/api/plants/2", "v1 plant", "@1792022400", `</api/v1/plants/2>; rel="successor-version"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.body, rr.Body.String())
+			assert.Equal(t, tt.deprecation, rr.Header().Get(HeaderDeprecation))
+			assert.Equal(t, tt.link, rr.Header().Get(HeaderLink))
+		})
+	}
+
+	// Versions only serve their own routes
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v3/plants/2", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestDeprecated(t *testing.T) {
+	at := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	handler := Deprecated(at, "/auth", "/api/v1/auth")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth/status", nil))
+	assert.Equal(t, "@1767225600", rr.Header().Get(HeaderDeprecation))
+	assert.Equal(t, `</api/v1/auth/status>; rel="successor-version"`, rr.Header().Get(HeaderLink))
+
+	// Paths outside the deprecated prefix get no successor link
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/other", nil))
+	assert.Equal(t, "@1767225600", rr.Header().Get(HeaderDeprecation))
+	assert.Empty(t, rr.Header().Get(HeaderLink))
+}
@@ -0,0 +1,99 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"time"
+)
+
+// Asset is a single precacheable file
+type Asset struct {
+	URL  string `json:"url"`
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// Manifest lists the static assets the service worker should precache. Its
+// Version changes whenever any asset is added, removed or modified.
+type Manifest struct {
+	Version     string    `json:"version"`
+	Assets      []Asset   `json:"assets"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// BuildManifest hashes every file in fsys and lists it under urlPrefix.
+// Files named in exclude (relative to fsys) are skipped.
+func BuildManifest(fsys fs.FS, urlPrefix string, exclude ...string) (*Manifest, error) {
+	skip := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		skip[name] = true
+	}
+
+	manifest := &Manifest{
+		Assets:      []Asset{},
+		GeneratedAt: time.Now(),
+	}
+
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || skip[name] {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("failed to read asset %s: %w", name, err)
+		}
+
+		sum := sha256.Sum256(data)
+		manifest.Assets = append(manifest.Assets, Asset{
+			URL:  path.Join(urlPrefix, name),
+			Hash: "sha256-" + base64.StdEncoding.EncodeToString(sum[:]),
+			Size: int64(len(data)),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(manifest.Assets, func(i, j int) bool {
+		return manifest.Assets[i].URL < manifest.Assets[j].URL
+	})
+
+	version := sha256.New()
+	for _, asset := range manifest.Assets {
+		fmt.Fprintf(version, "%s %s\n", asset.URL, asset.Hash)
+	}
+	manifest.Version = hex.EncodeToString(version.Sum(nil))[:16]
+
+	return manifest, nil
+}
+
+// HTTPHandler serves the manifest, answering 304 when the client already has
+// the current version
+func (m *Manifest) HTTPHandler() http.HandlerFunc {
+	etag := `"` + m.Version + `"`
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", etag)
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m)
+	}
+}
@@ -0,0 +1,83 @@
+package assets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"js/app.js": {Data: []byte("console.log('app')")},
+		"style.css": {Data: []byte("body {}")},
+		"sw.js":     {Data: []byte("self.addEventListener('push', () => {})")},
+		"icon.png":  {Data: []byte{0x89, 0x50, 0x4e, 0x47}},
+	}
+}
+
+func TestBuildManifest(t *testing.T) {
+	manifest, err := BuildManifest(testFS(), "/static", "sw.js")
+	require.NoError(t, err)
+
+	urls := make([]string, len(manifest.Assets))
+	for i, asset := range manifest.Assets {
+		urls[i] = asset.URL
+		assert.True(t, strings.HasPrefix(asset.Hash, "sha256-"), "hash %q", asset.Hash)
+	}
+	assert.Equal(t, []string{"/static/icon.png", "/static/js/app.js", "/static/style.css"}, urls)
+	assert.Equal(t, int64(7), manifest.Assets[2].Size)
+	assert.Len(t, manifest.Version, 16)
+}
+
+func TestBuildManifest_VersionTracksContent(t *testing.T) {
+	fsys := testFS()
+	first, err := BuildManifest(fsys, "/static")
+	require.NoError(t, err)
+
+	again, err := BuildManifest(fsys, "/static")
+	require.NoError(t, err)
+	assert.Equal(t, first.Version, again.Version)
+
+	fsys["style.css"] = &fstest.MapFile{Data: []byte("body { color: green }")}
+	changed, err := BuildManifest(fsys, "/static")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Version, changed.Version)
+
+	delete(fsys, "icon.png")
+	removed, err := BuildManifest(fsys, "/static")
+	require.NoError(t, err)
+	assert.NotEqual(t, changed.Version, removed.Version)
+}
+
+func TestManifestHTTPHandler(t *testing.T) {
+	manifest, err := BuildManifest(testFS(), "/static")
+	require.NoError(t, err)
+	handler := manifest.HTTPHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/cache-manifest", nil)
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"`+manifest.Version+`"`, rr.Header().Get("ETag"))
+	assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+
+	var body Manifest
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, manifest.Version, body.Version)
+	assert.Len(t, body.Assets, 4)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/cache-manifest", nil)
+	req.Header.Set("If-None-Match", rr.Header().Get("ETag"))
+	rr = httptest.NewRecorder()
+	handler(rr, req)
+
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+}
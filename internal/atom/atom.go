@@ -0,0 +1,78 @@
+// Package atom writes Atom (RFC 4287) feeds for feed readers.
+package atom
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// namespace is the Atom XML namespace
+const namespace = "http://www.w3.org/2005/Atom"
+
+// Feed is an Atom feed. Updated defaults to the newest entry's time.
+type Feed struct {
+	XMLName xml.Name  `xml:"feed"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	ID      string    `xml:"id"`
+	Title   string    `xml:"title"`
+	Updated time.Time `xml:"updated"`
+	Author  Person    `xml:"author"`
+	Links   []Link    `xml:"link"`
+	Entries []Entry   `xml:"entry"`
+}
+
+// Person names a feed's author
+type Person struct {
+	Name string `xml:"name"`
+}
+
+// Link points to a related resource, such as the feed itself (rel="self")
+type Link struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// Entry is one item of a feed
+type Entry struct {
+	ID       string    `xml:"id"`
+	Title    string    `xml:"title"`
+	Updated  time.Time `xml:"updated"`
+	Category *Category `xml:"category,omitempty"`
+	Links    []Link    `xml:"link"`
+	Summary  string    `xml:"summary,omitempty"`
+}
+
+// Category tags an entry, such as with the kind of activity it describes
+type Category struct {
+	Term string `xml:"term,attr"`
+}
+
+// Encode writes the feed to w as an XML document
+func (f *Feed) Encode(w io.Writer) error {
+	f.XMLNS = namespace
+	if f.Updated.IsZero() {
+		for _, entry := range f.Entries {
+			if entry.Updated.After(f.Updated) {
+				f.Updated = entry.Updated
+			}
+		}
+	}
+	// Feeds without entries still need an updated time; the Unix epoch
+	// keeps the document the same until something happens
+	if f.Updated.IsZero() {
+		f.Updated = time.Unix(0, 0)
+	}
+	f.Updated = f.Updated.UTC()
+	for i := range f.Entries {
+		f.Entries[i].Updated = f.Entries[i].Updated.UTC()
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(f)
+}
@@ -0,0 +1,61 @@
+package atom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeed_Encode(t *testing.T) {
+	older := time.Date(2024, 6, 11, 8, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	newer := older.Add(24 * time.Hour)
+	feed := &Feed{
+		ID:     "https://plants.example.com/feed.atom",
+		Title:  "Fern & friends",
+		Author: Person{Name: "Watered"},
+		Links:  []Link{{Href: "https://plants.example.com/feed.atom", Rel: "self"}},
+		Entries: []Entry{
+			{ID: "urn:watered:watering-2", Title: "Fern was watered", Updated: newer, Category: &Category{Term: "watered"}},
+			{ID: "urn:watered:watering-1", Title: "Fern was watered", Updated: older},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := feed.Encode(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		`<title>Fern &amp; friends</title>`,
+		`<updated>2024-06-12T06:00:00Z</updated>`,
+		`<link href="https://plants.example.com/feed.atom" rel="self"></link>`,
+		`<category term="watered"></category>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<summary>") {
+		t.Errorf("Expected empty summaries to be left out, got:\n%s", out)
+	}
+
+	var parsed Feed
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil || len(parsed.Entries) != 2 {
+		t.Errorf("Expected the feed to parse back with two entries, got %+v (%v)", parsed, err)
+	}
+}
+
+func TestFeed_EncodeEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&Feed{ID: "urn:x", Title: "Empty"}).Encode(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "<updated>1970-01-01T00:00:00Z</updated>") {
+		t.Errorf("Expected an empty feed to still carry an updated time, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/privacy"
+	"watered/internal/randtoken"
+	"watered/internal/respond"
+)
+
+// APIKeyPrefix starts every issued API key so keys are easy to recognize
+// in configuration files and secret scanners
+const APIKeyPrefix = "wk_"
+
+// maxAPIKeyNameLength caps the label shown in audit logs and as the waterer
+const maxAPIKeyNameLength = 64
+
+// ErrInvalidAPIKeyName is returned when an API key name is empty or too long
+var ErrInvalidAPIKeyName = errors.New("API key name must be 1 to 64 characters")
+
+// apiKeyUserKey is the request context key holding the API key's user
+type apiKeyUserKey struct{}
+
+// CreateAPIKey issues a new API key acting as createdBy. It returns the
+// stored key and the plaintext key, which is not retrievable afterwards.
+func (a *AuthService) CreateAPIKey(name, createdBy string) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return nil, "", ErrInvalidAPIKeyName
+	}
+
+	id, err := randtoken.String(8, hex.EncodeToString)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret, err := randtoken.String(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	plaintext := APIKeyPrefix + id + "_" + secret
+
+	key := &models.APIKey{
+		ID:        id,
+		Name:      name,
+		Hash:      randtoken.Hash(plaintext),
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	if err := a.storage.SaveAPIKey(key); err != nil {
+		return nil, "", fmt.Errorf("failed to store API key: %w", err)
+	}
+
+	slog.Info("API key issued", "audit", true, "key_id", key.ID, "key_name", key.Name, "by", createdBy)
+	return key, plaintext, nil
+}
+
+// ListAPIKeys returns all issued API keys without their hashes
+func (a *AuthService) ListAPIKeys() ([]*models.APIKey, error) {
+	keys, err := a.storage.ListAPIKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		key.Hash = ""
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey deletes an API key so it can no longer authenticate. It
+// returns false when no key with that ID exists.
+func (a *AuthService) RevokeAPIKey(id, revokedBy string) (bool, error) {
+	key, err := a.storage.GetAPIKey(id)
+	if err != nil {
+		return false, err
+	}
+	if key == nil {
+		return false, nil
+	}
+	if err := a.storage.DeleteAPIKey(id); err != nil {
+		return false, err
+	}
+
+	slog.Info("API key revoked", "audit", true, "key_id", key.ID, "key_name", key.Name, "by", revokedBy)
+	return true, nil
+}
+
+// AuthenticateAPIKey returns the user a plaintext API key acts as, or nil
+// when the key is unknown, malformed or its issuer is no longer allowed
+func (a *AuthService) AuthenticateAPIKey(plaintext string) *models.User {
+	rest, ok := strings.CutPrefix(plaintext, APIKeyPrefix)
+	if !ok {
+		return nil
+	}
+	id, _, ok := strings.Cut(rest, "_")
+	if !ok || id == "" {
+		return nil
+	}
+
+	key, err := a.storage.GetAPIKey(id)
+	if err != nil || key == nil {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(randtoken.Hash(plaintext)), []byte(key.Hash)) != 1 {
+		return nil
+	}
+
+	// Removing the issuer from the allowlist also disables their keys
+	if !a.IsUserAllowed(key.CreatedBy) && !a.IsUserAdmin(key.CreatedBy) {
+		return nil
+	}
+
+	// Keys act as members, or as viewers for an issuer who has become one
+	role := privacy.RoleMember
+	if a.UserRole(key.CreatedBy) == privacy.RoleViewer {
+		role = privacy.RoleViewer
+	}
+	return &models.User{
+		Email: key.CreatedBy,
+		Name:  key.Name,
+		Role:  role.String(),
+	}
+}
+
+// APIKeyAuth middleware accepts "Authorization: Bearer <key>" as an
+// alternative to a session cookie, where the key is an API key or an app's
+// access token. Requests without a bearer key pass through unchanged; an
+// invalid key is rejected rather than falling back to the session. Key users
+// never have admin rights; access token users have their own role.
+func (a *AuthService) APIKeyAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		bearer = strings.TrimSpace(bearer)
+
+		if !strings.HasPrefix(bearer, APIKeyPrefix) {
+			user := a.AuthenticateAccessToken(bearer)
+			if user == nil {
+				logger.FromContext(r.Context()).Warn("Rejected access token", "audit", true, "remote_addr", r.RemoteAddr, "method", r.Method, "path", r.URL.Path)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="watered", error="invalid_token"`)
+				respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Invalid or expired access token")
+				return
+			}
+			logger.AddAttrs(r.Context(), "user", user.Email)
+			next.ServeHTTP(w, r.WithContext(withAppUser(r.Context(), user)))
+			return
+		}
+
+		user := a.AuthenticateAPIKey(bearer)
+		if user == nil {
+			logger.FromContext(r.Context()).Warn("Rejected API key", "audit", true, "remote_addr", r.RemoteAddr, "method", r.Method, "path", r.URL.Path)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="watered"`)
+			respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Invalid API key")
+			return
+		}
+
+		logger.AddAttrs(r.Context(), "user", user.Email, "api_key", user.Name)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyUserKey{}, user)))
+	})
+}
+
+// ClientKey identifies the caller for rate limiting: the API key, the
+// signed-in user, or else the client address. Behind a proxy it relies on
+// realip.Middleware having rewritten RemoteAddr from a trusted proxy's
+// headers.
+func (a *AuthService) ClientKey(r *http.Request) string {
+	if user := apiKeyUser(r); user != nil {
+		return "apikey:" + user.Name
+	}
+	if user, err := a.GetCurrentUser(r); err == nil && user != nil {
+		return "user:" + user.Email
+	}
+	return a.ClientIPKey(r)
+}
+
+// ClientIPKey identifies a request by client address alone, so limits keyed
+// on it hold however many accounts or API keys the client uses. The address
+// only comes from forwarded headers sent by a trusted proxy.
+func (a *AuthService) ClientIPKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// apiKeyUser returns the user authenticated by APIKeyAuth, if any
+func apiKeyUser(r *http.Request) *models.User {
+	user, _ := r.Context().Value(apiKeyUserKey{}).(*models.User)
+	return user
+}
+
+// ViaAPIKey reports whether a request authenticated with an API key rather
+// than a session
+func ViaAPIKey(r *http.Request) bool {
+	return apiKeyUser(r) != nil
+}
@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/privacy"
+	"watered/internal/ratelimit"
+	"watered/internal/realip"
+	"watered/internal/storage"
+)
+
+func newAPIKeyTestService(t *testing.T) *AuthService {
+	t.Helper()
+	store := storage.NewMemoryStorage()
+	t.Cleanup(func() { store.Close() })
+	store.UpdateAdminConfig(&models.AdminConfig{
+		AllowedEmails: []string{"user@example.com"},
+		AdminEmails:   []string{"admin@example.com"},
+	})
+	return NewAuthService(store, config.AuthConfig{})
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	authService := newAPIKeyTestService(t)
+
+	key, plaintext, err := authService.CreateAPIKey("  Home Assistant ", "admin@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+	if !strings.HasPrefix(plaintext, APIKeyPrefix+key.ID+"_") {
+		t.Errorf("Expected key to start with prefix and ID, got %q", plaintext)
+	}
+	if key.Name != "Home Assistant" || key.CreatedBy != "admin@example.com" {
+		t.Errorf("Unexpected key metadata: %+v", key)
+	}
+	if key.Hash == "" || strings.Contains(key.Hash, plaintext) {
+		t.Error("Expected only a hash of the key to be stored")
+	}
+
+	keys, _ := authService.ListAPIKeys()
+	if len(keys) != 1 || keys[0].Hash != "" {
+		t.Errorf("Expected one listed key without hash, got %+v", keys)
+	}
+
+	for _, name := range []string{"", "   ", strings.Repeat("x", maxAPIKeyNameLength+1)} {
+		if _, _, err := authService.CreateAPIKey(name, "admin@example.com"); err != ErrInvalidAPIKeyName {
+			t.Errorf("Expected ErrInvalidAPIKeyName for %q, got %v", name, err)
+		}
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	authService := newAPIKeyTestService(t)
+	key, plaintext, err := authService.CreateAPIKey("Home Assistant", "user@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+
+	user := authService.AuthenticateAPIKey(plaintext)
+	if user == nil || user.Email != "user@example.com" || user.Name != "Home Assistant" || user.IsAdmin {
+		t.Fatalf("Expected non-admin user for key, got %+v", user)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"not-a-key",
+		APIKeyPrefix + key.ID,
+		APIKeyPrefix + key.ID + "_wrong",
+		APIKeyPrefix + "unknown_" + strings.SplitN(plaintext, "_", 3)[2],
+	} {
+		if user := authService.AuthenticateAPIKey(invalid); user != nil {
+			t.Errorf("Expected %q to be rejected, got %+v", invalid, user)
+		}
+	}
+
+	// Removing the issuer from the allowlist disables their keys
+	authService.storage.UpdateAdminConfig(&models.AdminConfig{AdminEmails: []string{"admin@example.com"}})
+	if user := authService.AuthenticateAPIKey(plaintext); user != nil {
+		t.Errorf("Expected key of removed user to be rejected, got %+v", user)
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	authService := newAPIKeyTestService(t)
+	key, plaintext, _ := authService.CreateAPIKey("Home Assistant", "admin@example.com")
+
+	revoked, err := authService.RevokeAPIKey(key.ID, "admin@example.com")
+	if err != nil || !revoked {
+		t.Fatalf("Expected key to be revoked, got %v (%v)", revoked, err)
+	}
+	if user := authService.AuthenticateAPIKey(plaintext); user != nil {
+		t.Errorf("Expected revoked key to be rejected, got %+v", user)
+	}
+
+	if revoked, err := authService.RevokeAPIKey(key.ID, "admin@example.com"); err != nil || revoked {
+		t.Errorf("Expected revoking an unknown key to report false, got %v (%v)", revoked, err)
+	}
+}
+
+func TestAPIKeyAuthMiddleware(t *testing.T) {
+	authService := newAPIKeyTestService(t)
+	_, plaintext, _ := authService.CreateAPIKey("Home Assistant", "admin@example.com")
+
+	var current *models.User
+	var role privacy.Role
+	handler := authService.APIKeyAuth(authService.AuthRequired(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current, _ = authService.GetCurrentUser(r)
+		role = authService.CallerRole(r)
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	tests := []struct {
+		name          string
+		authorization string
+		expectedCode  int
+	}{
+		{"valid key", "Bearer " + plaintext, http.StatusOK},
+		{"invalid key", "Bearer wk_nope_nope", http.StatusUnauthorized},
+		{"no key falls back to session", "", http.StatusSeeOther},
+		{"other scheme falls back to session", "Basic dXNlcjpwYXNz", http.StatusSeeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current = nil
+			req := httptest.NewRequest("POST", "/api/plant/water", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, rr.Code)
+			}
+			if tt.expectedCode == http.StatusOK && (current == nil || current.Email != "admin@example.com") {
+				t.Errorf("Expected request to act as the key issuer, got %+v", current)
+			}
+			if tt.expectedCode == http.StatusOK && role != privacy.RoleViewer {
+				t.Errorf("Expected API key clients to be viewers, got %s", role)
+			}
+		})
+	}
+
+	// Keys never carry admin rights, even when issued by an admin
+	adminOnly := authService.APIKeyAuth(authService.AdminRequired(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+	req := httptest.NewRequest("PUT", "/api/plant/settings", nil)
+	req.Header.Set("Authorization", "Bearer "+plaintext)
+	rr := httptest.NewRecorder()
+	adminOnly.ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected API key to be refused admin access, got %d", rr.Code)
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	authService := newAPIKeyTestService(t)
+	_, plaintext, _ := authService.CreateAPIKey("Home Assistant", "admin@example.com")
+
+	var key string
+	handler := authService.APIKeyAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = authService.ClientKey(r)
+	}))
+
+	req := httptest.NewRequest("GET", "/api/plant", nil)
+	req.RemoteAddr = "192.0.2.1:51234"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if key != "ip:192.0.2.1" {
+		t.Errorf("Expected anonymous clients to be keyed by address, got %q", key)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+plaintext)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if key != "apikey:Home Assistant" {
+		t.Errorf("Expected API key clients to be keyed by key name, got %q", key)
+	}
+}
+
+func TestClientIPKey_SpoofedForwardedFor(t *testing.T) {
+	authService := newAPIKeyTestService(t)
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	// The sign-in bucket as the server wires it, behind realip
+	limiter := ratelimit.NewBucketLimiter("auth", config.BucketConfig{Burst: 2, Refill: time.Hour})
+	handler := realip.Middleware(trusted)(limiter.Middleware(authService.ClientIPKey, authService.ClientKey)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	signIn := func(remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest("GET", "/auth/login", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// A direct client claiming a new address each time shares one bucket
+	for i := 1; i <= 2; i++ {
+		if code := signIn("203.0.113.7:1234", fmt.Sprintf("198.51.100.%d", i)); code != http.StatusOK {
+			t.Fatalf("Attempt %d: expected status %d, got %d", i, http.StatusOK, code)
+		}
+	}
+	if code := signIn("203.0.113.7:1234", "198.51.100.3"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected spoofed X-Forwarded-For to draw from the same bucket, got %d", code)
+	}
+
+	// Clients behind a trusted proxy each get their own bucket
+	if code := signIn("10.0.0.2:1234", "198.51.100.1"); code != http.StatusOK {
+		t.Errorf("Expected a client forwarded by a trusted proxy to get its own bucket, got %d", code)
+	}
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"watered/internal/config"
+	"watered/internal/storage"
+)
+
+// newCSRFTestSession signs in a user and returns the session cookies and
+// the session's CSRF token
+func newCSRFTestSession(t *testing.T, authService *AuthService) ([]*http.Cookie, string) {
+	t.Helper()
+
+	authService.allowedEmails["user@example.com"] = true
+	w := httptest.NewRecorder()
+	err := authService.CreateSession(w, httptest.NewRequest("GET", "/", nil), &GoogleUserInfo{
+		Email: "user@example.com",
+		Name:  "User",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	cookies := w.Result().Cookies()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	token, err := authService.CSRFToken(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("Failed to get CSRF token: %v", err)
+	}
+	if token == "" {
+		t.Fatal("Expected a CSRF token for a signed-in session")
+	}
+	return cookies, token
+}
+
+func TestCSRFToken(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	authService := NewAuthService(store, config.AuthConfig{})
+
+	// Anonymous visitors have no session to protect
+	token, err := authService.CSRFToken(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if err != nil || token != "" {
+		t.Errorf("Expected no token for anonymous visitors, got %q (%v)", token, err)
+	}
+
+	cookies, first := newCSRFTestSession(t, authService)
+
+	// The token is stable for the session
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	if again, _ := authService.CSRFToken(httptest.NewRecorder(), req); again != first {
+		t.Errorf("Expected the same token for the session, got %q and %q", first, again)
+	}
+
+	// Signing in again issues a new token
+	if _, second := newCSRFTestSession(t, authService); second == first {
+		t.Error("Expected a new token after signing in again")
+	}
+}
+
+func TestCSRFProtectMiddleware(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	authService := NewAuthService(store, config.AuthConfig{})
+	cookies, token := newCSRFTestSession(t, authService)
+
+	handler := authService.CSRFProtect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name          string
+		method        string
+		signedIn      bool
+		header        string
+		form          string
+		authorization string
+		expectedCode  int
+	}{
+		{"safe method", "GET", true, "", "", "", http.StatusOK},
+		{"anonymous write", "POST", false, "", "", "", http.StatusOK},
+		{"missing token", "POST", true, "", "", "", http.StatusForbidden},
+		{"wrong token", "PUT", true, "wrong", "", "", http.StatusForbidden},
+		{"header token", "DELETE", true, token, "", "", http.StatusOK},
+		{"form token", "POST", true, "", token, "", http.StatusOK},
+		{"bearer client", "POST", true, "", "", "Bearer wk_key", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			if tt.form != "" {
+				req = httptest.NewRequest(tt.method, "/api/plant/water", strings.NewReader(url.Values{CSRFFormField: {tt.form}}.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				req = httptest.NewRequest(tt.method, "/api/plant/water", nil)
+			}
+			if tt.signedIn {
+				for _, cookie := range cookies {
+					req.AddCookie(cookie)
+				}
+			}
+			if tt.header != "" {
+				req.Header.Set(CSRFHeader, tt.header)
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/securecookie"
+)
+
+const (
+	// LoginCookieName is the cookie carrying a sign-in in progress
+	LoginCookieName = "watered-login"
+
+	// loginMaxAge is how many seconds a visitor has to come back from
+	// Google after starting to sign in
+	loginMaxAge = 10 * 60
+)
+
+// pendingLogin is what a visitor's browser holds while they sign in with
+// Google: the OAuth state to check on the callback and the invite link they
+// followed, if any. It lives in a signed cookie rather than a stored session,
+// so visitors who never finish signing in leave nothing on the server.
+type pendingLogin struct {
+	State  string `json:"state,omitempty"`
+	Invite string `json:"invite,omitempty"`
+}
+
+// newLoginCodec creates the codec signing pending login cookies with secret.
+// Cookies older than loginMaxAge are rejected whatever their MaxAge says.
+func newLoginCodec(secret []byte) *securecookie.SecureCookie {
+	codec := securecookie.New(secret, nil).MaxAge(loginMaxAge)
+	codec.SetSerializer(securecookie.JSONEncoder{})
+	return codec
+}
+
+// pendingLogin returns the request's sign-in in progress. Missing, expired
+// and tampered cookies give an empty one.
+func (a *AuthService) pendingLogin(r *http.Request) pendingLogin {
+	var pending pendingLogin
+	if cookie, err := r.Cookie(LoginCookieName); err == nil {
+		if err := a.loginCodec.Decode(LoginCookieName, cookie.Value, &pending); err != nil {
+			return pendingLogin{}
+		}
+	}
+	return pending
+}
+
+// setPendingLogin writes the sign-in in progress to its cookie
+func (a *AuthService) setPendingLogin(w http.ResponseWriter, pending pendingLogin) error {
+	encoded, err := a.loginCodec.Encode(LoginCookieName, pending)
+	if err != nil {
+		return fmt.Errorf("failed to encode login cookie: %w", err)
+	}
+	http.SetCookie(w, a.loginCookie(encoded, loginMaxAge))
+	return nil
+}
+
+// clearPendingLogin removes the sign-in in progress once it is done
+func (a *AuthService) clearPendingLogin(w http.ResponseWriter, r *http.Request) {
+	if _, err := r.Cookie(LoginCookieName); err == nil {
+		http.SetCookie(w, a.loginCookie("", -1))
+	}
+}
+
+// loginCookie returns the pending login cookie with the session cookie's
+// attributes. It is Lax like the session cookie, so it comes back with the
+// redirect from Google.
+func (a *AuthService) loginCookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     LoginCookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   a.store.options.Secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// BeginLogin starts signing in with Google, returning the OAuth state to
+// send along. The state is kept in the login cookie with any invite link the
+// visitor followed.
+func (a *AuthService) BeginLogin(w http.ResponseWriter, r *http.Request) (string, error) {
+	state, err := a.GenerateStateToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate state token: %w", err)
+	}
+	pending := a.pendingLogin(r)
+	pending.State = state
+	if err := a.setPendingLogin(w, pending); err != nil {
+		return "", err
+	}
+	return state, nil
+}
+
+// ValidLoginState reports whether state is the one BeginLogin handed this
+// browser, and not more than loginMaxAge ago
+func (a *AuthService) ValidLoginState(r *http.Request, state string) bool {
+	expected := a.pendingLogin(r).State
+	return expected != "" && subtle.ConstantTimeCompare([]byte(state), []byte(expected)) == 1
+}
+
+// SetPendingInvite remembers the token of the invite link a visitor followed,
+// for the OAuth callback to accept once they have signed in
+func (a *AuthService) SetPendingInvite(w http.ResponseWriter, r *http.Request, token string) error {
+	pending := a.pendingLogin(r)
+	pending.Invite = token
+	return a.setPendingLogin(w, pending)
+}
+
+// PendingInvite returns the token of the invite link the visitor followed
+// before signing in, or "" when there is none
+func (a *AuthService) PendingInvite(r *http.Request) string {
+	return a.pendingLogin(r).Invite
+}
@@ -3,20 +3,28 @@ package auth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 
+	"watered/internal/config"
+	"watered/internal/logger"
 	"watered/internal/models"
+	"watered/internal/privacy"
+	"watered/internal/respond"
 	"watered/internal/storage"
 )
 
@@ -31,48 +39,69 @@ type GoogleUserInfo struct {
 
 // AuthService handles authentication operations
 type AuthService struct {
-	oauth2Config  *oauth2.Config
-	store         *sessions.CookieStore
-	storage       storage.Storage
+	// oauth2Config is swapped whole when the setup wizard saves new
+	// credentials, so sign-ins in flight never see half of a change
+	oauth2Config atomic.Pointer[oauth2.Config]
+	store        *sessionStore
+	loginCodec   *securecookie.SecureCookie
+	storage      storage.Storage
+	demoMode     bool
+
+	// Allowlist from ALLOWED_EMAILS, ADMIN_EMAILS and VIEWER_EMAILS,
+	// replaced when the configuration is reloaded
+	allowMu       sync.RWMutex
 	allowedEmails map[string]bool
 	adminEmails   map[string]bool
+	viewerEmails  map[string]bool
+	demoUsers     []models.DemoUser
+
+	// Console-issued admin recovery token
+	recovery   *recoveryToken
+	recoveryMu sync.Mutex
+
+	// Bearer tokens for native apps
+	tokenKey   []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
 }
 
 // NewAuthService creates a new authentication service
-func NewAuthService(storage storage.Storage) *AuthService {
-	// Get OAuth2 credentials from environment
-	clientID := os.Getenv("GOOGLE_CLIENT_ID")
-	clientSecret := os.Getenv("GOOGLE_CLIENT_SECRET")
-	sessionSecret := os.Getenv("SESSION_SECRET")
+func NewAuthService(storage storage.Storage, cfg config.AuthConfig) *AuthService {
+	clientID := cfg.GoogleClientID
+	clientSecret := cfg.GoogleClientSecret
+	sessionSecret := cfg.SessionSecret
 
+	// Fall back to credentials saved by the setup wizard
+	if clientID == "" || clientSecret == "" {
+		if stored, err := storage.GetAdminConfig(); err == nil && stored != nil &&
+			stored.GoogleClientID != "" && stored.GoogleClientSecret != "" {
+			clientID = stored.GoogleClientID
+			clientSecret = stored.GoogleClientSecret
+			slog.Info("Using Google OAuth2 credentials from stored configuration")
+		}
+	}
+
+	// The startup report warns when neither OAuth nor demo login is set up,
+	// and about the development session secret
 	if clientID == "" || clientSecret == "" {
-		log.Printf("Warning: Google OAuth2 credentials not set. Demo mode enabled.")
-		log.Printf("Visit /auth/demo-login to test authentication without Google OAuth.")
 		clientID = "demo-client-id"
 		clientSecret = "demo-client-secret"
 	}
 
-	// Handle session secret based on mode
-	if os.Getenv("WATERED_MODE") == "demo" {
-		// Use fixed demo session secret for consistent demo experience
+	// Without a session secret, demos use a fixed one for a consistent demo
+	// experience
+	if sessionSecret == "" && cfg.DemoMode {
 		sessionSecret = "demo-session-secret-for-development-only"
-		log.Printf("Demo mode: Using fixed demo session secret")
 	} else if sessionSecret == "" {
 		sessionSecret = "development-secret-change-in-production"
-		log.Printf("Warning: SESSION_SECRET not set. Using development secret.")
-	} else {
-		log.Printf("SESSION_SECRET loaded successfully (length: %d characters)", len(sessionSecret))
 	}
 
-	// Determine redirect URL based on environment
-	redirectURL := os.Getenv("REDIRECT_URL")
+	// Default to localhost for development
+	redirectURL := cfg.RedirectURL
 	if redirectURL == "" {
-		// Default to localhost for development
 		redirectURL = "http://localhost:8080/auth/callback"
 	}
 
-	log.Printf("OAuth redirect URL: %s", redirectURL)
-
 	// Create OAuth2 config
 	oauth2Config := &oauth2.Config{
 		ClientID:     clientID,
@@ -85,34 +114,51 @@ func NewAuthService(storage storage.Storage) *AuthService {
 		Endpoint: google.Endpoint,
 	}
 
-	// Determine if we should use secure cookies (HTTPS)
-	secureCookies := os.Getenv("SECURE_COOKIES") == "true"
-	environment := os.Getenv("ENVIRONMENT")
-
-	// Auto-detect secure cookies for production environments
-	if environment == "production" || environment == "prod" {
-		secureCookies = true
-	}
-
-	log.Printf("Cookie configuration: secure=%v, environment=%s", secureCookies, environment)
-
-	// Create secure cookie store
-	store := sessions.NewCookieStore([]byte(sessionSecret))
-	store.Options = &sessions.Options{
+	// Session values are kept in storage; the cookie only carries a signed ID
+	store := newSessionStore(storage, []byte(sessionSecret), &sessions.Options{
 		Path:     "/",
 		MaxAge:   24 * 60 * 60, // 24 hours
 		HttpOnly: true,
-		Secure:   secureCookies,
+		Secure:   cfg.SecureCookies,
 		SameSite: http.SameSiteLaxMode,
+	})
+
+	accessTTL, refreshTTL := cfg.AccessTokenTTL, cfg.RefreshTokenTTL
+	if accessTTL <= 0 {
+		accessTTL = DefaultAccessTokenTTL
+	}
+	if refreshTTL <= 0 {
+		refreshTTL = DefaultRefreshTokenTTL
 	}
 
-	// Parse allowed emails
-	allowedEmails := make(map[string]bool)
-	adminEmails := make(map[string]bool)
+	allowedEmails, adminEmails, viewerEmails := staticAllowlist(cfg)
+	service := &AuthService{
+		store:         store,
+		loginCodec:    newLoginCodec([]byte(sessionSecret)),
+		storage:       storage,
+		allowedEmails: allowedEmails,
+		adminEmails:   adminEmails,
+		viewerEmails:  viewerEmails,
+		demoUsers:     ConfiguredDemoUsers(cfg),
+		demoMode:      cfg.DemoMode,
+		tokenKey:      accessTokenKey(sessionSecret),
+		accessTTL:     accessTTL,
+		refreshTTL:    refreshTTL,
+	}
+	service.oauth2Config.Store(oauth2Config)
+	return service
+}
 
-	if allowedEmailsStr := os.Getenv("ALLOWED_EMAILS"); allowedEmailsStr != "" {
-		for _, email := range strings.Split(allowedEmailsStr, ",") {
-			allowedEmails[strings.TrimSpace(email)] = true
+// staticAllowlist returns the users, admins and viewers allowed by the
+// configuration, or the demo users when none are configured
+func staticAllowlist(cfg config.AuthConfig) (allowedEmails, adminEmails, viewerEmails map[string]bool) {
+	allowedEmails = make(map[string]bool)
+	adminEmails = make(map[string]bool)
+	viewerEmails = make(map[string]bool)
+
+	if len(cfg.AllowedEmails) > 0 {
+		for _, email := range cfg.AllowedEmails {
+			allowedEmails[email] = true
 		}
 	} else {
 		// Demo allowed emails
@@ -122,9 +168,8 @@ func NewAuthService(storage storage.Storage) *AuthService {
 		allowedEmails["test@example.com"] = true
 	}
 
-	if adminEmailsStr := os.Getenv("ADMIN_EMAILS"); adminEmailsStr != "" {
-		for _, email := range strings.Split(adminEmailsStr, ",") {
-			email = strings.TrimSpace(email)
+	if len(cfg.AdminEmails) > 0 {
+		for _, email := range cfg.AdminEmails {
 			adminEmails[email] = true
 			allowedEmails[email] = true // Admins are also allowed users
 		}
@@ -134,13 +179,66 @@ func NewAuthService(storage storage.Storage) *AuthService {
 		allowedEmails["admin@example.com"] = true
 	}
 
-	return &AuthService{
-		oauth2Config:  oauth2Config,
-		store:         store,
-		storage:       storage,
-		allowedEmails: allowedEmails,
-		adminEmails:   adminEmails,
+	for _, email := range cfg.ViewerEmails {
+		viewerEmails[email] = true
+		allowedEmails[email] = true // Viewers are allowed users too
+	}
+	return allowedEmails, adminEmails, viewerEmails
+}
+
+// ConfiguredAdmins returns the admins set by ADMIN_EMAILS, or the demo admin
+// when it is unset. They stay admins whatever the stored configuration says.
+func ConfiguredAdmins(cfg config.AuthConfig) map[string]bool {
+	_, adminEmails, _ := staticAllowlist(cfg)
+	return adminEmails
+}
+
+// DefaultDemoUsers are offered on the demo login form when DEMO_USERS is
+// unset
+var DefaultDemoUsers = []string{"demo@example.com", "user1@example.com", "user2@example.com", "admin@example.com"}
+
+// ConfiguredDemoUsers returns the demo users set by DEMO_USERS, or
+// DefaultDemoUsers when it is unset
+func ConfiguredDemoUsers(cfg config.AuthConfig) []models.DemoUser {
+	emails := cfg.DemoUsers
+	if len(emails) == 0 {
+		emails = DefaultDemoUsers
+	}
+	users := make([]models.DemoUser, 0, len(emails))
+	for _, email := range emails {
+		users = append(users, models.DemoUser{Email: strings.ToLower(email)})
 	}
+	return users
+}
+
+// SetAllowlist replaces the users, admins and viewers allowed by
+// ALLOWED_EMAILS, ADMIN_EMAILS and VIEWER_EMAILS, and the demo users from
+// DEMO_USERS, such as after the configuration was reloaded. Users added from
+// the admin page stay allowed, and signed-in users keep their session.
+func (a *AuthService) SetAllowlist(cfg config.AuthConfig) {
+	allowedEmails, adminEmails, viewerEmails := staticAllowlist(cfg)
+	demoUsers := ConfiguredDemoUsers(cfg)
+	a.allowMu.Lock()
+	defer a.allowMu.Unlock()
+	a.allowedEmails = allowedEmails
+	a.adminEmails = adminEmails
+	a.viewerEmails = viewerEmails
+	a.demoUsers = demoUsers
+}
+
+// staticAllowed reports whether email is allowed, and whether as an admin,
+// by the configured allowlist
+func (a *AuthService) staticAllowed(email string) (allowed, admin bool) {
+	a.allowMu.RLock()
+	defer a.allowMu.RUnlock()
+	return a.allowedEmails[email], a.adminEmails[email]
+}
+
+// staticViewer reports whether the configured allowlist makes email a viewer
+func (a *AuthService) staticViewer(email string) bool {
+	a.allowMu.RLock()
+	defer a.allowMu.RUnlock()
+	return a.viewerEmails[email]
 }
 
 // GenerateStateToken creates a random state token for OAuth2 CSRF protection
@@ -155,18 +253,19 @@ func (a *AuthService) GenerateStateToken() (string, error) {
 
 // GetLoginURL returns the Google OAuth2 login URL
 func (a *AuthService) GetLoginURL(state string) string {
-	return a.oauth2Config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	return a.oauth2Config.Load().AuthCodeURL(state, oauth2.AccessTypeOffline)
 }
 
 // HandleCallback processes the OAuth2 callback
 func (a *AuthService) HandleCallback(ctx context.Context, code string) (*GoogleUserInfo, error) {
-	token, err := a.oauth2Config.Exchange(ctx, code)
+	oauth2Config := a.oauth2Config.Load()
+	token, err := oauth2Config.Exchange(ctx, code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
 	}
 
 	// Get user info from Google
-	client := a.oauth2Config.Client(ctx, token)
+	client := oauth2Config.Client(ctx, token)
 	resp, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
@@ -184,15 +283,15 @@ func (a *AuthService) HandleCallback(ctx context.Context, code string) (*GoogleU
 // IsUserAllowed checks if a user email is in the whitelist
 func (a *AuthService) IsUserAllowed(email string) bool {
 	// First check static configuration (for fallback/demo mode)
-	if a.allowedEmails[email] {
+	if allowed, _ := a.staticAllowed(email); allowed {
 		return true
 	}
 
 	// Then check dynamic admin configuration from storage
 	config, err := a.storage.GetAdminConfig()
 	if err != nil || config == nil {
-		log.Printf("Warning: Failed to get admin config, using static allowlist: %v", err)
-		return a.allowedEmails[email]
+		slog.Warn("Failed to get admin config, using static allowlist", "error", err)
+		return false
 	}
 
 	// Check if email is in the dynamic allowlist
@@ -208,15 +307,15 @@ func (a *AuthService) IsUserAllowed(email string) bool {
 // IsUserAdmin checks if a user email is in the admin list
 func (a *AuthService) IsUserAdmin(email string) bool {
 	// First check static configuration (for fallback/demo mode)
-	if a.adminEmails[email] {
+	if _, admin := a.staticAllowed(email); admin {
 		return true
 	}
 
 	// Then check dynamic admin configuration from storage
 	config, err := a.storage.GetAdminConfig()
 	if err != nil || config == nil {
-		log.Printf("Warning: Failed to get admin config, using static admin list: %v", err)
-		return a.adminEmails[email]
+		slog.Warn("Failed to get admin config, using static admin list", "error", err)
+		return false
 	}
 
 	// Check if email is in the dynamic admin list
@@ -229,50 +328,125 @@ func (a *AuthService) IsUserAdmin(email string) bool {
 	return false
 }
 
+// IsUserViewer checks if a user email has the read-only viewer role
+func (a *AuthService) IsUserViewer(email string) bool {
+	if a.staticViewer(email) {
+		return true
+	}
+
+	config, err := a.storage.GetAdminConfig()
+	if err != nil || config == nil {
+		return false
+	}
+	for _, viewerEmail := range config.ViewerEmails {
+		if viewerEmail == email {
+			return true
+		}
+	}
+	return false
+}
+
+// UserRole returns the role an allowed user signs in with. Admins listed as
+// viewers too stay admins.
+func (a *AuthService) UserRole(email string) privacy.Role {
+	switch {
+	case a.IsUserAdmin(email):
+		return privacy.RoleAdmin
+	case a.IsUserViewer(email):
+		return privacy.RoleViewer
+	default:
+		return privacy.RoleMember
+	}
+}
+
+// CanWater reports whether email may record waterings: an allowed user who
+// is not a viewer
+func (a *AuthService) CanWater(email string) bool {
+	return a.IsUserAllowed(email) && a.UserRole(email) >= privacy.RoleMember
+}
+
+// RoleOf returns a signed-in user's role. Sessions from before roles were
+// stored have none, and are members unless they are admins.
+func RoleOf(user *models.User) privacy.Role {
+	switch {
+	case user.IsAdmin:
+		return privacy.RoleAdmin
+	case user.Role == privacy.RoleViewer.String():
+		return privacy.RoleViewer
+	default:
+		return privacy.RoleMember
+	}
+}
+
 // CreateSession creates a new user session
 func (a *AuthService) CreateSession(w http.ResponseWriter, r *http.Request, userInfo *GoogleUserInfo) error {
-	session, err := a.store.Get(r, "watered-session")
+	session, err := a.store.Get(r, SessionCookieName)
 	if err != nil {
 		return fmt.Errorf("failed to get session: %w", err)
 	}
+	// Signing in always starts a new session
+	if err := a.store.renew(session); err != nil {
+		return err
+	}
 
 	// Store user info in session
 	session.Values["user_id"] = userInfo.ID
 	session.Values["user_email"] = userInfo.Email
 	session.Values["user_name"] = userInfo.Name
 	session.Values["user_picture"] = userInfo.Picture
-	session.Values["is_admin"] = a.IsUserAdmin(userInfo.Email)
+	role := a.UserRole(userInfo.Email)
+	session.Values["is_admin"] = role == privacy.RoleAdmin
+	session.Values["role"] = role.String()
 	session.Values["authenticated"] = true
-	session.Values["login_time"] = time.Now().Unix()
+
+	// Each login gets a fresh CSRF token
+	csrfToken, err := newCSRFToken()
+	if err != nil {
+		return err
+	}
+	session.Values[csrfSessionKey] = csrfToken
 
 	// Save session
 	if err := session.Save(r, w); err != nil {
 		return fmt.Errorf("failed to save session: %w", err)
 	}
+	a.clearPendingLogin(w, r)
 
 	// Create or update user in storage
 	user := &models.User{
 		Email:    userInfo.Email,
 		Name:     userInfo.Name,
-		IsAdmin:  a.IsUserAdmin(userInfo.Email),
+		IsAdmin:  role == privacy.RoleAdmin,
+		Role:     role.String(),
 		JoinedAt: time.Now(),
 	}
 
 	if existingUser, err := a.storage.GetUser(userInfo.Email); err == nil && existingUser != nil {
 		// Update existing user
 		user.JoinedAt = existingUser.JoinedAt
+		user.Locale = existingUser.Locale
 	}
 
 	if err := a.storage.CreateUser(user); err != nil {
-		log.Printf("Warning: Failed to store user in database: %v", err)
+		slog.Warn("Failed to store user in database", "email", user.Email, "error", err)
 	}
 
 	return nil
 }
 
-// GetCurrentUser returns the current authenticated user
+// GetCurrentUser returns the user authenticated by API key, access token or
+// session cookie
 func (a *AuthService) GetCurrentUser(r *http.Request) (*models.User, error) {
-	session, err := a.store.Get(r, "watered-session")
+	if user := apiKeyUser(r); user != nil {
+		userCopy := *user
+		return &userCopy, nil
+	}
+	if user := appUser(r); user != nil {
+		userCopy := *user
+		return &userCopy, nil
+	}
+
+	session, err := a.store.Get(r, SessionCookieName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
@@ -289,14 +463,30 @@ func (a *AuthService) GetCurrentUser(r *http.Request) (*models.User, error) {
 
 	name, _ := session.Values["user_name"].(string)
 	isAdmin, _ := session.Values["is_admin"].(bool)
+	role, _ := session.Values["role"].(string)
 
 	return &models.User{
 		Email:   email,
 		Name:    name,
 		IsAdmin: isAdmin,
+		Role:    role,
 	}, nil
 }
 
+// CallerRole returns how much of a response the caller may see. API key
+// clients are viewers whoever issued the key.
+func (a *AuthService) CallerRole(r *http.Request) privacy.Role {
+	if apiKeyUser(r) != nil {
+		return privacy.RoleViewer
+	}
+
+	user, err := a.GetCurrentUser(r)
+	if err != nil || user == nil {
+		return privacy.RoleViewer
+	}
+	return RoleOf(user)
+}
+
 // IsAuthenticated checks if the current request is authenticated
 func (a *AuthService) IsAuthenticated(r *http.Request) bool {
 	user, err := a.GetCurrentUser(r)
@@ -305,23 +495,20 @@ func (a *AuthService) IsAuthenticated(r *http.Request) bool {
 
 // GetSession returns the current session
 func (a *AuthService) GetSession(r *http.Request) (*sessions.Session, error) {
-	session, err := a.store.Get(r, "watered-session")
+	session, err := a.store.Get(r, SessionCookieName)
 	if err != nil {
-		log.Printf("GetSession error: %v", err)
-		log.Printf("GetSession: Cookie count: %d", len(r.Cookies()))
-		for _, cookie := range r.Cookies() {
-			if cookie.Name == "watered-session" {
-				log.Printf("GetSession: Found session cookie (length: %d)", len(cookie.Value))
-				break
-			}
+		attrs := []any{"error", err, "cookies", len(r.Cookies())}
+		if cookie, cookieErr := r.Cookie(SessionCookieName); cookieErr == nil {
+			attrs = append(attrs, "session_cookie_length", len(cookie.Value))
 		}
+		logger.FromContext(r.Context()).Warn("Failed to get session", attrs...)
 	}
 	return session, err
 }
 
 // ClearSession logs out the user by clearing their session
 func (a *AuthService) ClearSession(w http.ResponseWriter, r *http.Request) error {
-	session, err := a.store.Get(r, "watered-session")
+	session, err := a.store.Get(r, SessionCookieName)
 	if err != nil {
 		return fmt.Errorf("failed to get session: %w", err)
 	}
@@ -344,34 +531,124 @@ func (a *AuthService) AuthRequired(next http.Handler) http.Handler {
 	})
 }
 
+// RoleRequired returns middleware that requires a signed-in user with at
+// least role, such as privacy.RoleMember to keep viewers from changing
+// anything. A user's role is fixed when they sign in.
+func (a *AuthService) RoleRequired(role privacy.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := a.GetCurrentUser(r)
+			if err != nil || user == nil {
+				respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+				return
+			}
+			if RoleOf(user) < role {
+				respond.Error(w, http.StatusForbidden, respond.CodeForbidden, fmt.Sprintf("The %s role is required", role))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // AdminRequired middleware that requires admin privileges
 func (a *AuthService) AdminRequired(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user, err := a.GetCurrentUser(r)
 		if err != nil || user == nil || !user.IsAdmin {
-			http.Error(w, "Admin access required", http.StatusForbidden)
+			respond.Error(w, http.StatusForbidden, respond.CodeForbidden, "Admin access required")
 			return
 		}
-		next.ServeHTTP(w, r)
+		if a.isRecoverySession(r) {
+			logger.FromContext(r.Context()).Warn("Recovery admin request", "audit", true, "email", user.Email, "method", r.Method, "path", r.URL.Path)
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), actorKey{}, user.Email)))
 	})
 }
 
+// actorKey is the request context key holding who passed AdminRequired
+type actorKey struct{}
+
+// TokenActor is the actor of admin requests admitted by bearer token
+const TokenActor = "token"
+
+// Actor returns who is making an admin request: the admin's email, or
+// TokenActor for requests admitted by AdminOrTokenRequired's token. It is
+// empty outside admin routes.
+func Actor(r *http.Request) string {
+	actor, _ := r.Context().Value(actorKey{}).(string)
+	return actor
+}
+
+// AdminOrTokenRequired returns middleware that admits admin sessions, or
+// requests carrying "Authorization: Bearer <token>" when token is non-empty.
+// It lets automated clients such as CD pipelines call admin-gated endpoints.
+func (a *AuthService) AdminOrTokenRequired(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		adminOnly := a.AdminRequired(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token != "" {
+				if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok &&
+					subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+					next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), actorKey{}, TokenActor)))
+					return
+				}
+			}
+			adminOnly.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SetOAuthCredentials replaces the Google OAuth2 client credentials at
+// runtime. It is safe to call while users are signing in.
+func (a *AuthService) SetOAuthCredentials(clientID, clientSecret string) {
+	next := *a.oauth2Config.Load()
+	next.ClientID = clientID
+	next.ClientSecret = clientSecret
+	a.oauth2Config.Store(&next)
+	slog.Info("Google OAuth2 credentials updated")
+}
+
 // SetAllowedEmails sets the allowed emails (for testing)
 func (a *AuthService) SetAllowedEmails(emails map[string]bool) {
+	a.allowMu.Lock()
+	defer a.allowMu.Unlock()
 	a.allowedEmails = emails
 }
 
-// IsDemoMode checks if we're running in demo mode (no real Google credentials)
+// IsDemoMode reports whether demo login is enabled by DEMO_MODE or the demo
+// sandbox. Missing OAuth credentials never enable it.
 func (a *AuthService) IsDemoMode() bool {
-	// Check explicit demo mode first
-	if os.Getenv("WATERED_MODE") == "demo" {
-		return true
+	return a.demoMode
+}
+
+// DemoUsers returns the users offered on the demo login form: those an admin
+// saved, or else DEMO_USERS. Users who aren't allowed to sign in are left
+// out.
+func (a *AuthService) DemoUsers() ([]models.DemoUser, error) {
+	a.allowMu.RLock()
+	users := a.demoUsers
+	a.allowMu.RUnlock()
+
+	config, err := a.storage.GetAdminConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin config: %w", err)
+	}
+	if config != nil && config.DemoUsers != nil {
+		users = config.DemoUsers
+	}
+
+	offered := make([]models.DemoUser, 0, len(users))
+	for _, user := range users {
+		if a.IsUserAllowed(user.Email) {
+			offered = append(offered, user)
+		}
 	}
-	// Fallback to credential-based detection
-	return a.oauth2Config.ClientID == "demo-client-id"
+	return offered, nil
 }
 
-// CreateDemoSession creates a demo session for testing (bypasses Google OAuth)
+// CreateDemoSession creates a demo session for testing (bypasses Google
+// OAuth). Only allowed users offered on the demo login form may sign in.
 func (a *AuthService) CreateDemoSession(w http.ResponseWriter, r *http.Request, email string, name string, isAdmin bool) error {
 	if !a.IsDemoMode() {
 		return fmt.Errorf("demo sessions only available in demo mode")
@@ -382,6 +659,16 @@ func (a *AuthService) CreateDemoSession(w http.ResponseWriter, r *http.Request,
 		return fmt.Errorf("user not in allowlist")
 	}
 
+	// Only users offered on the demo login form may sign in through it, so
+	// taking someone off the form locks them out of demo login
+	users, err := a.DemoUsers()
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(users, func(user models.DemoUser) bool { return strings.EqualFold(user.Email, email) }) {
+		return fmt.Errorf("user is not a demo user")
+	}
+
 	// Create demo user info
 	userInfo := &GoogleUserInfo{
 		ID:            "demo-" + email,
@@ -3,51 +3,38 @@ package auth
 import (
 	"net/http"
 	"net/http/httptest"
-	"os"
+	"strings"
+	"sync"
 	"testing"
 
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/privacy"
 	"watered/internal/storage"
 )
 
 func TestNewAuthService(t *testing.T) {
-	// Save original env vars
-	originalClientID := os.Getenv("GOOGLE_CLIENT_ID")
-	originalClientSecret := os.Getenv("GOOGLE_CLIENT_SECRET")
-	originalSessionSecret := os.Getenv("SESSION_SECRET")
-	originalAllowedEmails := os.Getenv("ALLOWED_EMAILS")
-	originalAdminEmails := os.Getenv("ADMIN_EMAILS")
-
-	// Clean up after test
-	defer func() {
-		os.Setenv("GOOGLE_CLIENT_ID", originalClientID)
-		os.Setenv("GOOGLE_CLIENT_SECRET", originalClientSecret)
-		os.Setenv("SESSION_SECRET", originalSessionSecret)
-		os.Setenv("ALLOWED_EMAILS", originalAllowedEmails)
-		os.Setenv("ADMIN_EMAILS", originalAdminEmails)
-	}()
-
-	// Test with environment variables
-	os.Setenv("GOOGLE_CLIENT_ID", "test-client-id")
-	os.Setenv("GOOGLE_CLIENT_SECRET", "test-client-secret")
-	os.Setenv("SESSION_SECRET", "test-session-secret")
-	os.Setenv("ALLOWED_EMAILS", "user1@example.com,user2@example.com")
-	os.Setenv("ADMIN_EMAILS", "admin@example.com")
-
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := NewAuthService(store)
+	authService := NewAuthService(store, config.AuthConfig{
+		GoogleClientID:     "test-client-id",
+		GoogleClientSecret: "test-client-secret",
+		SessionSecret:      "test-session-secret",
+		AllowedEmails:      []string{"user1@example.com", "user2@example.com"},
+		AdminEmails:        []string{"admin@example.com"},
+	})
 
 	if authService == nil {
 		t.Fatal("Expected auth service to be created")
 	}
 
-	if authService.oauth2Config == nil {
+	if authService.oauth2Config.Load() == nil {
 		t.Fatal("Expected OAuth2 config to be created")
 	}
 
-	if authService.oauth2Config.ClientID != "test-client-id" {
-		t.Errorf("Expected client ID 'test-client-id', got '%s'", authService.oauth2Config.ClientID)
+	if authService.oauth2Config.Load().ClientID != "test-client-id" {
+		t.Errorf("Expected client ID 'test-client-id', got '%s'", authService.oauth2Config.Load().ClientID)
 	}
 
 	// Test email whitelist
@@ -77,7 +64,7 @@ func TestGenerateStateToken(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := NewAuthService(store)
+	authService := NewAuthService(store, config.AuthConfig{})
 
 	token1, err := authService.GenerateStateToken()
 	if err != nil {
@@ -102,7 +89,7 @@ func TestGetLoginURL(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := NewAuthService(store)
+	authService := NewAuthService(store, config.AuthConfig{})
 
 	state := "test-state"
 	url := authService.GetLoginURL(state)
@@ -125,7 +112,7 @@ func TestCreateAndGetSession(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := NewAuthService(store)
+	authService := NewAuthService(store, config.AuthConfig{})
 
 	// Create test request and response
 	req := httptest.NewRequest("GET", "/", nil)
@@ -189,7 +176,7 @@ func TestClearSession(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := NewAuthService(store)
+	authService := NewAuthService(store, config.AuthConfig{})
 
 	// Create and authenticate user
 	req := httptest.NewRequest("GET", "/", nil)
@@ -243,7 +230,7 @@ func TestAuthRequiredMiddleware(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := NewAuthService(store)
+	authService := NewAuthService(store, config.AuthConfig{})
 
 	// Test handler that should only be called if authenticated
 	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -273,7 +260,7 @@ func TestAdminRequiredMiddleware(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := NewAuthService(store)
+	authService := NewAuthService(store, config.AuthConfig{})
 
 	// Test handler that should only be called if admin
 	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -298,11 +285,11 @@ func TestCreateDemoSession(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := NewAuthService(store)
+	authService := NewAuthService(store, config.AuthConfig{DemoMode: true})
 
 	// Test demo mode detection
 	if !authService.IsDemoMode() {
-		t.Error("Expected service to be in demo mode when no credentials are set")
+		t.Error("Expected service to be in demo mode when DEMO_MODE is set")
 	}
 
 	// Create test request and response
@@ -354,7 +341,7 @@ func TestDemoSessionWithUnauthorizedEmail(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := NewAuthService(store)
+	authService := NewAuthService(store, config.AuthConfig{DemoMode: true})
 
 	req := httptest.NewRequest("GET", "/", nil)
 	w := httptest.NewRecorder()
@@ -383,3 +370,243 @@ func contains(s, substr string) bool {
 				return false
 			}()))
 }
+
+func TestAdminOrTokenRequiredMiddleware(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := NewAuthService(store, config.AuthConfig{})
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name     string
+		token    string
+		header   string
+		expected int
+	}{
+		{"valid token", "secret", "Bearer secret", http.StatusOK},
+		{"wrong token", "secret", "Bearer wrong", http.StatusForbidden},
+		{"missing header", "secret", "", http.StatusForbidden},
+		{"token disabled", "", "Bearer ", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware := authService.AdminOrTokenRequired(tt.token)(testHandler)
+
+			req := httptest.NewRequest("POST", "/health/smoke", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			middleware.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuthService_ExplicitDemoMode(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := NewAuthService(store, config.AuthConfig{
+		GoogleClientID:     "real-client-id",
+		GoogleClientSecret: "real-client-secret",
+		DemoMode:           true,
+	})
+
+	if !authService.IsDemoMode() {
+		t.Error("Expected WATERED_MODE=demo to enable demo mode even with credentials")
+	}
+}
+
+func TestAuthService_DemoModeNotInferred(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	// Missing credentials fall back to the demo client ID, which must not
+	// turn on demo login
+	authService := NewAuthService(store, config.AuthConfig{})
+	if authService.IsDemoMode() {
+		t.Error("Expected demo mode to stay off without DEMO_MODE")
+	}
+
+	err := authService.CreateDemoSession(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), "demo@example.com", "Demo User", false)
+	if err == nil {
+		t.Error("Expected demo sessions to be refused without DEMO_MODE")
+	}
+}
+
+func TestAuthService_SetOAuthCredentials(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := NewAuthService(store, config.AuthConfig{})
+
+	// Saving credentials from the setup wizard while people sign in must
+	// not race with the login path
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			authService.GetLoginURL("state")
+		}()
+	}
+	authService.SetOAuthCredentials("wizard-client-id", "wizard-secret")
+	wg.Wait()
+
+	if url := authService.GetLoginURL("state"); !strings.Contains(url, "client_id=wizard-client-id") {
+		t.Errorf("Expected the login URL to use the new client ID, got %s", url)
+	}
+}
+
+func TestAuthService_DemoUsers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := NewAuthService(store, config.AuthConfig{DemoMode: true})
+	users, err := authService.DemoUsers()
+	if err != nil {
+		t.Fatalf("Failed to list demo users: %v", err)
+	}
+	if len(users) != len(DefaultDemoUsers) || users[0].Email != "demo@example.com" {
+		t.Errorf("Expected the default demo users, got %+v", users)
+	}
+
+	// DEMO_USERS replaces the defaults, leaving out users who can't sign in
+	authService.SetAllowlist(config.AuthConfig{
+		AllowedEmails: []string{"guest@example.com"},
+		AdminEmails:   []string{"host@example.com"},
+		DemoUsers:     []string{"Guest@example.com", "stranger@example.com", "host@example.com"},
+	})
+	users, _ = authService.DemoUsers()
+	if len(users) != 2 || users[0].Email != "guest@example.com" || users[1].Email != "host@example.com" {
+		t.Errorf("Expected the allowed DEMO_USERS, got %+v", users)
+	}
+
+	// Users saved by an admin replace DEMO_USERS, even when there are none
+	store.UpdateAdminConfig(&models.AdminConfig{DemoUsers: []models.DemoUser{{Email: "host@example.com", Name: "Host"}}})
+	users, _ = authService.DemoUsers()
+	if len(users) != 1 || users[0].Name != "Host" {
+		t.Errorf("Expected the saved demo users, got %+v", users)
+	}
+	store.UpdateAdminConfig(&models.AdminConfig{DemoUsers: []models.DemoUser{}})
+	if users, _ = authService.DemoUsers(); len(users) != 0 {
+		t.Errorf("Expected no demo users, got %+v", users)
+	}
+}
+
+func TestAuthService_SetAllowlist(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := NewAuthService(store, config.AuthConfig{
+		AllowedEmails: []string{"user1@example.com"},
+		AdminEmails:   []string{"admin@example.com"},
+	})
+	authService.SetAllowlist(config.AuthConfig{
+		AllowedEmails: []string{"user2@example.com"},
+		AdminEmails:   []string{"owner@example.com"},
+	})
+
+	if authService.IsUserAllowed("user1@example.com") || authService.IsUserAdmin("admin@example.com") {
+		t.Error("Expected users removed from the allowlist to be denied")
+	}
+	if !authService.IsUserAllowed("user2@example.com") || !authService.IsUserAllowed("owner@example.com") {
+		t.Error("Expected users added to the allowlist to be allowed")
+	}
+	if !authService.IsUserAdmin("owner@example.com") {
+		t.Error("Expected owner@example.com to be admin")
+	}
+}
+
+func TestAuthService_ViewerRole(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := NewAuthService(store, config.AuthConfig{
+		AllowedEmails: []string{"user@example.com"},
+		AdminEmails:   []string{"admin@example.com"},
+		ViewerEmails:  []string{"sitter@example.com"},
+	})
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"neighbour@example.com"},
+		ViewerEmails:  []string{"neighbour@example.com"},
+	})
+
+	for email, want := range map[string]privacy.Role{
+		"admin@example.com":     privacy.RoleAdmin,
+		"user@example.com":      privacy.RoleMember,
+		"sitter@example.com":    privacy.RoleViewer,
+		"neighbour@example.com": privacy.RoleViewer,
+	} {
+		if !authService.IsUserAllowed(email) {
+			t.Errorf("Expected %s to be allowed", email)
+		}
+		if got := authService.UserRole(email); got != want {
+			t.Errorf("Expected %s to be %s, got %s", email, want, got)
+		}
+		if authService.CanWater(email) != (want >= privacy.RoleMember) {
+			t.Errorf("Expected CanWater(%s) to be %v", email, want >= privacy.RoleMember)
+		}
+	}
+
+	// The role is kept with the session
+	req := signIn(t, authService, "sitter@example.com")
+	user, err := authService.GetCurrentUser(req)
+	if err != nil || user == nil {
+		t.Fatalf("Expected a signed-in user, got %v", err)
+	}
+	if user.Role != "viewer" || user.IsAdmin {
+		t.Errorf("Expected a viewer session, got %+v", user)
+	}
+	if role := authService.CallerRole(withCookies(req)); role != privacy.RoleViewer {
+		t.Errorf("Expected the viewer role, got %s", role)
+	}
+
+	// Sessions from before roles were stored are members
+	if role := RoleOf(&models.User{Email: "user@example.com"}); role != privacy.RoleMember {
+		t.Errorf("Expected a member without a stored role, got %s", role)
+	}
+}
+
+func TestRoleRequiredMiddleware(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := NewAuthService(store, config.AuthConfig{
+		AllowedEmails: []string{"user@example.com"},
+		ViewerEmails:  []string{"sitter@example.com"},
+	})
+	handler := authService.RoleRequired(privacy.RoleMember)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"anonymous", httptest.NewRequest("POST", "/api/v1/plant/water", nil), http.StatusUnauthorized},
+		{"viewer", signIn(t, authService, "sitter@example.com"), http.StatusForbidden},
+		{"member", signIn(t, authService, "user@example.com"), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, tt.req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
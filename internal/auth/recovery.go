@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"watered/internal/logger"
+)
+
+const (
+	// RecoveryTokenTTL is how long a recovery token printed at startup stays valid
+	RecoveryTokenTTL = time.Hour
+	// RecoverySessionMaxAge limits how long a recovery admin session lasts (seconds)
+	RecoverySessionMaxAge = 15 * 60
+	// recoveryUserEmail identifies recovery sessions when no email is given
+	recoveryUserEmail = "recovery-admin@localhost"
+)
+
+// recoveryToken is a one-time console-issued token granting a temporary admin session
+type recoveryToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// EnableRecovery issues a one-time admin recovery token. It is meant to be
+// printed to the console only, never served over HTTP.
+func (a *AuthService) EnableRecovery(ttl time.Duration) (string, error) {
+	value, err := a.GenerateStateToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate recovery token: %w", err)
+	}
+
+	a.recoveryMu.Lock()
+	a.recovery = &recoveryToken{
+		value:     value,
+		expiresAt: time.Now().Add(ttl),
+	}
+	a.recoveryMu.Unlock()
+
+	slog.Warn("Admin recovery mode enabled", "audit", true, "expires_at", time.Now().Add(ttl).Format(time.RFC3339))
+	return value, nil
+}
+
+// IsRecoveryEnabled reports whether an unused, unexpired recovery token exists
+func (a *AuthService) IsRecoveryEnabled() bool {
+	a.recoveryMu.Lock()
+	defer a.recoveryMu.Unlock()
+	return a.recovery != nil && time.Now().Before(a.recovery.expiresAt)
+}
+
+// RedeemRecoveryToken consumes the recovery token and creates a short-lived
+// admin session. Every attempt is audit logged.
+func (a *AuthService) RedeemRecoveryToken(w http.ResponseWriter, r *http.Request, token, email string) error {
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == "" {
+		email = recoveryUserEmail
+	}
+
+	a.recoveryMu.Lock()
+	valid := a.recovery != nil && time.Now().Before(a.recovery.expiresAt) &&
+		subtle.ConstantTimeCompare([]byte(a.recovery.value), []byte(token)) == 1
+	if valid {
+		a.recovery = nil // One-time use
+	}
+	a.recoveryMu.Unlock()
+
+	if !valid {
+		logger.FromContext(r.Context()).Warn("Failed admin recovery attempt", "audit", true, "remote_addr", r.RemoteAddr, "email", email)
+		return fmt.Errorf("invalid or expired recovery token")
+	}
+
+	session, err := a.store.Get(r, SessionCookieName)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if err := a.store.renew(session); err != nil {
+		return err
+	}
+
+	session.Values["user_id"] = "recovery-" + email
+	session.Values["user_email"] = email
+	session.Values["user_name"] = "Recovery Admin"
+	session.Values["is_admin"] = true
+	session.Values["authenticated"] = true
+	session.Values["recovery"] = true
+	session.Options.MaxAge = RecoverySessionMaxAge
+
+	csrfToken, err := newCSRFToken()
+	if err != nil {
+		return err
+	}
+	session.Values[csrfSessionKey] = csrfToken
+
+	if err := session.Save(r, w); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	logger.FromContext(r.Context()).Warn("Admin recovery session granted", "audit", true, "email", email, "remote_addr", r.RemoteAddr, "duration", time.Duration(RecoverySessionMaxAge)*time.Second)
+	return nil
+}
+
+// isRecoverySession reports whether the request belongs to a recovery admin session
+func (a *AuthService) isRecoverySession(r *http.Request) bool {
+	session, err := a.store.Get(r, SessionCookieName)
+	if err != nil {
+		return false
+	}
+	recovery, _ := session.Values["recovery"].(bool)
+	return recovery
+}
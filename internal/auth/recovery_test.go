@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/storage"
+)
+
+func TestRecoveryTokenFlow(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := NewAuthService(store, config.AuthConfig{})
+
+	if authService.IsRecoveryEnabled() {
+		t.Fatal("Expected recovery to be disabled by default")
+	}
+
+	token, err := authService.EnableRecovery(time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to enable recovery: %v", err)
+	}
+
+	// Wrong token is rejected
+	req := httptest.NewRequest("POST", "/auth/recovery", nil)
+	w := httptest.NewRecorder()
+	if err := authService.RedeemRecoveryToken(w, req, "wrong", ""); err == nil {
+		t.Error("Expected error for wrong recovery token")
+	}
+
+	// Correct token grants an admin session
+	w = httptest.NewRecorder()
+	if err := authService.RedeemRecoveryToken(w, req, token, "owner@example.com"); err != nil {
+		t.Fatalf("Failed to redeem recovery token: %v", err)
+	}
+
+	adminReq := httptest.NewRequest("GET", "/admin/config", nil)
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.MaxAge != RecoverySessionMaxAge {
+			t.Errorf("Expected recovery session max age %d, got %d", RecoverySessionMaxAge, cookie.MaxAge)
+		}
+		adminReq.AddCookie(cookie)
+	}
+
+	handler := authService.AdminRequired(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, adminReq)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected recovery session to have admin access, got %d", rr.Code)
+	}
+
+	// Token is single use
+	if authService.IsRecoveryEnabled() {
+		t.Error("Expected recovery token to be consumed")
+	}
+	if err := authService.RedeemRecoveryToken(httptest.NewRecorder(), req, token, ""); err == nil {
+		t.Error("Expected reused recovery token to be rejected")
+	}
+}
+
+func TestRecoveryTokenExpiry(t *testing.T) {
+	authService := NewAuthService(storage.NewMemoryStorage(), config.AuthConfig{})
+
+	token, err := authService.EnableRecovery(-time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to enable recovery: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/auth/recovery", nil)
+	if err := authService.RedeemRecoveryToken(httptest.NewRecorder(), req, token, ""); err == nil {
+		t.Error("Expected expired recovery token to be rejected")
+	}
+}
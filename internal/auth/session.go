@@ -0,0 +1,336 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+
+	"watered/internal/models"
+	"watered/internal/randtoken"
+	"watered/internal/storage"
+)
+
+const (
+	// SessionCookieName is the cookie carrying the session ID
+	SessionCookieName = "watered-session"
+
+	// sessionLastSeenResolution is how stale a session's last seen time may
+	// get before a request from it is written back
+	sessionLastSeenResolution = time.Minute
+	// sessionPruneInterval is how often expired sessions are removed
+	sessionPruneInterval = time.Hour
+)
+
+// errSessionNotSignedIn is returned when saving a session nobody has
+// signed in to
+var errSessionNotSignedIn = errors.New("only signed-in sessions are stored")
+
+// sessionStore is a gorilla sessions.Store that keeps session values in
+// Storage. The cookie only carries a signed random ID, so sessions can be
+// listed and revoked on the server. Stored sessions are keyed by the hash of
+// that ID.
+type sessionStore struct {
+	storage storage.Storage
+	codecs  []securecookie.Codec
+	options *sessions.Options
+	now     func() time.Time
+
+	pruneMu   sync.Mutex
+	lastPrune time.Time
+}
+
+// newSessionStore creates a session store signing its cookies with secret
+func newSessionStore(storage storage.Storage, secret []byte, options *sessions.Options) *sessionStore {
+	codecs := securecookie.CodecsFromPairs(secret)
+	for _, codec := range codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			// Expiry is enforced by the stored session
+			sc.MaxAge(0)
+		}
+	}
+	return &sessionStore{
+		storage: storage,
+		codecs:  codecs,
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// Get returns the request's session, cached for the rest of the request
+func (s *sessionStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New loads the session named by the request's cookie. Requests without a
+// cookie, or whose session expired or was revoked, get a new empty session.
+func (s *sessionStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	options := *s.options
+	session.Options = &options
+	session.IsNew = true
+
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	var id string
+	if err := securecookie.DecodeMulti(name, cookie.Value, &id, s.codecs...); err != nil {
+		// Cookies signed with another secret, or from before sessions were
+		// kept on the server, start over
+		return session, nil
+	}
+
+	stored, err := s.storage.GetSession(randtoken.Hash(id))
+	if err != nil {
+		return session, fmt.Errorf("failed to load session: %w", err)
+	}
+	now := s.now()
+	if stored == nil || !now.Before(stored.ExpiresAt) {
+		return session, nil
+	}
+
+	session.ID = id
+	session.IsNew = false
+	setSessionValues(session, stored)
+
+	if now.Sub(stored.LastSeen) >= sessionLastSeenResolution {
+		stored.LastSeen = now
+		if err := s.storage.SaveSession(stored); err != nil {
+			slog.Error("Failed to record session last seen", "error", err)
+		}
+	}
+	return session, nil
+}
+
+// Save stores the session's values and sets its cookie. Sessions whose
+// MaxAge is negative are deleted and their cookie cleared. Only signed-in
+// sessions are stored; a sign-in in progress lives in the login cookie.
+func (s *sessionStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			if err := s.storage.DeleteSession(randtoken.Hash(session.ID)); err != nil {
+				return fmt.Errorf("failed to delete session: %w", err)
+			}
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	if authenticated, _ := session.Values["authenticated"].(bool); !authenticated {
+		return errSessionNotSignedIn
+	}
+
+	now := s.now()
+	stored := &models.Session{
+		CreatedAt:  now,
+		UserAgent:  r.UserAgent(),
+		RemoteAddr: r.RemoteAddr,
+	}
+	if session.ID == "" {
+		id, err := randtoken.String(32, base64.RawURLEncoding.EncodeToString)
+		if err != nil {
+			return fmt.Errorf("failed to generate session ID: %w", err)
+		}
+		session.ID = id
+		s.prune(now)
+	} else if existing, err := s.storage.GetSession(randtoken.Hash(session.ID)); err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	} else if existing != nil {
+		stored = existing
+	}
+
+	if err := storeSessionValues(stored, session.Values); err != nil {
+		return err
+	}
+	stored.ID = randtoken.Hash(session.ID)
+	stored.LastSeen = now
+	stored.ExpiresAt = now.Add(time.Duration(session.Options.MaxAge) * time.Second)
+	if err := s.storage.SaveSession(stored); err != nil {
+		return fmt.Errorf("failed to store session: %w", err)
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+// renew drops the session's stored record and gives it a new ID when it is
+// next saved, so an ID handed out before sign-in is never signed in
+func (s *sessionStore) renew(session *sessions.Session) error {
+	if session.ID != "" {
+		if err := s.storage.DeleteSession(randtoken.Hash(session.ID)); err != nil {
+			return fmt.Errorf("failed to delete session: %w", err)
+		}
+	}
+	session.ID = ""
+	session.IsNew = true
+	session.Values = make(map[interface{}]interface{})
+	return nil
+}
+
+// prune deletes expired sessions, at most once per sessionPruneInterval
+func (s *sessionStore) prune(now time.Time) {
+	s.pruneMu.Lock()
+	defer s.pruneMu.Unlock()
+	if now.Sub(s.lastPrune) < sessionPruneInterval {
+		return
+	}
+	s.lastPrune = now
+
+	stored, err := s.storage.ListSessions()
+	if err != nil {
+		slog.Error("Failed to list sessions for pruning", "error", err)
+		return
+	}
+	var expired []string
+	for _, session := range stored {
+		if !now.Before(session.ExpiresAt) {
+			expired = append(expired, session.ID)
+		}
+	}
+	if err := s.storage.DeleteSessions(expired); err != nil {
+		slog.Error("Failed to prune expired sessions", "error", err)
+	}
+}
+
+// setSessionValues fills a gorilla session's values from a stored session
+func setSessionValues(session *sessions.Session, stored *models.Session) {
+	values := map[string]interface{}{
+		"user_id":      stored.UserID,
+		"user_email":   stored.Email,
+		"user_name":    stored.Name,
+		"user_picture": stored.Picture,
+		csrfSessionKey: stored.CSRFToken,
+	}
+	for key, value := range values {
+		if value != "" {
+			session.Values[key] = value
+		}
+	}
+	if stored.Authenticated {
+		session.Values["authenticated"] = true
+		session.Values["is_admin"] = stored.IsAdmin
+		if stored.Role != "" {
+			session.Values["role"] = stored.Role
+		}
+	}
+	if stored.Recovery {
+		session.Values["recovery"] = true
+	}
+}
+
+// storeSessionValues copies a gorilla session's values onto a stored
+// session. Only the values this package sets are supported.
+func storeSessionValues(stored *models.Session, values map[interface{}]interface{}) error {
+	*stored = models.Session{
+		CreatedAt:  stored.CreatedAt,
+		UserAgent:  stored.UserAgent,
+		RemoteAddr: stored.RemoteAddr,
+	}
+	for key, value := range values {
+		var ok bool
+		switch key {
+		case "user_id":
+			stored.UserID, ok = value.(string)
+		case "user_email":
+			stored.Email, ok = value.(string)
+		case "user_name":
+			stored.Name, ok = value.(string)
+		case "user_picture":
+			stored.Picture, ok = value.(string)
+		case csrfSessionKey:
+			stored.CSRFToken, ok = value.(string)
+		case "is_admin":
+			stored.IsAdmin, ok = value.(bool)
+		case "role":
+			stored.Role, ok = value.(string)
+		case "authenticated":
+			stored.Authenticated, ok = value.(bool)
+		case "recovery":
+			stored.Recovery, ok = value.(bool)
+		}
+		if !ok {
+			return fmt.Errorf("unsupported session value %v", key)
+		}
+	}
+	return nil
+}
+
+// ListSessions returns the signed-in sessions that have not expired, oldest
+// first, without their CSRF tokens
+func (a *AuthService) ListSessions() ([]*models.Session, error) {
+	stored, err := a.storage.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	now := a.store.now()
+	active := make([]*models.Session, 0, len(stored))
+	for _, session := range stored {
+		if !session.Authenticated || !now.Before(session.ExpiresAt) {
+			continue
+		}
+		session.CSRFToken = ""
+		active = append(active, session)
+	}
+	return active, nil
+}
+
+// CurrentSessionID returns the ID the request's session is listed under, or
+// "" when it has no stored session
+func (a *AuthService) CurrentSessionID(r *http.Request) string {
+	session, err := a.store.Get(r, SessionCookieName)
+	if err != nil || session.ID == "" || session.IsNew {
+		return ""
+	}
+	return randtoken.Hash(session.ID)
+}
+
+// RevokeSession signs out one session by the ID it is listed under. It
+// returns false when no such session exists.
+func (a *AuthService) RevokeSession(id, revokedBy string) (bool, error) {
+	session, err := a.storage.GetSession(id)
+	if err != nil {
+		return false, err
+	}
+	if session == nil {
+		return false, nil
+	}
+	if err := a.storage.DeleteSession(id); err != nil {
+		return false, err
+	}
+
+	slog.Info("Session revoked", "audit", true, "email", session.Email, "by", revokedBy)
+	return true, nil
+}
+
+// RevokeUserSessions signs a user out on every device. It returns how many
+// sessions were revoked.
+func (a *AuthService) RevokeUserSessions(email, revokedBy string) (int, error) {
+	stored, err := a.storage.ListSessions()
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for _, session := range stored {
+		if strings.EqualFold(session.Email, email) {
+			ids = append(ids, session.ID)
+		}
+	}
+	if err := a.storage.DeleteSessions(ids); err != nil {
+		return 0, err
+	}
+
+	slog.Info("User signed out everywhere", "audit", true, "email", email, "sessions", len(ids), "by", revokedBy)
+	return len(ids), nil
+}
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/storage"
+)
+
+// signIn creates a session for email and returns a request carrying its cookie
+func signIn(t *testing.T, authService *AuthService, email string) *http.Request {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	userInfo := &GoogleUserInfo{ID: "id-" + email, Email: email, Name: "Test User"}
+	if err := authService.CreateSession(w, httptest.NewRequest("GET", "/", nil), userInfo); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+// withCookies returns a new request carrying r's cookies, since sessions are
+// cached for the lifetime of a request
+func withCookies(r *http.Request) *http.Request {
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range r.Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+func TestSessionStore_KeepsValuesOnServer(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	authService := NewAuthService(store, config.AuthConfig{})
+
+	req := signIn(t, authService, "test@example.com")
+	cookie, err := req.Cookie(SessionCookieName)
+	if err != nil {
+		t.Fatalf("Expected a session cookie: %v", err)
+	}
+	if strings.Contains(cookie.Value, "test@example.com") || len(cookie.Value) > 200 {
+		t.Errorf("Expected the cookie to carry only a signed ID, got %q", cookie.Value)
+	}
+
+	user, err := authService.GetCurrentUser(req)
+	if err != nil || user == nil || user.Email != "test@example.com" {
+		t.Fatalf("Expected the session to be signed in, got %+v (%v)", user, err)
+	}
+
+	sessions, _ := store.ListSessions()
+	if len(sessions) != 1 || sessions[0].Email != "test@example.com" || sessions[0].CSRFToken == "" {
+		t.Fatalf("Expected one stored session with a CSRF token, got %+v", sessions)
+	}
+
+	// A tampered cookie starts a new, signed-out session
+	tampered := httptest.NewRequest("GET", "/", nil)
+	tampered.AddCookie(&http.Cookie{Name: SessionCookieName, Value: cookie.Value + "x"})
+	if user, err := authService.GetCurrentUser(tampered); err != nil || user != nil {
+		t.Errorf("Expected a tampered cookie to be signed out, got %+v (%v)", user, err)
+	}
+}
+
+func TestSessionStore_SignInStartsNewSession(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	authService := NewAuthService(store, config.AuthConfig{})
+
+	first := signIn(t, authService, "test@example.com")
+	again := httptest.NewRequest("GET", "/auth/callback", nil)
+	again.AddCookie(first.Cookies()[0])
+	w := httptest.NewRecorder()
+	if err := authService.CreateSession(w, again, &GoogleUserInfo{Email: "test@example.com"}); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if w.Result().Cookies()[0].Value == first.Cookies()[0].Value {
+		t.Error("Expected signing in to issue a new session ID")
+	}
+
+	// The ID from before signing in again is signed out
+	if user, _ := authService.GetCurrentUser(withCookies(first)); user != nil {
+		t.Errorf("Expected the earlier session ID to be signed out, got %+v", user)
+	}
+	if sessions, _ := store.ListSessions(); len(sessions) != 1 {
+		t.Errorf("Expected only the new session to be stored, got %+v", sessions)
+	}
+}
+
+func TestSessionStore_LoginStoresNothing(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	authService := NewAuthService(store, config.AuthConfig{})
+
+	// Starting to sign in only sets the login cookie
+	w := httptest.NewRecorder()
+	state, err := authService.BeginLogin(w, httptest.NewRequest("GET", "/auth/login", nil))
+	if err != nil {
+		t.Fatalf("Failed to begin login: %v", err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != LoginCookieName || cookies[0].MaxAge != loginMaxAge {
+		t.Fatalf("Expected a short-lived login cookie, got %+v", cookies)
+	}
+	if sessions, _ := store.ListSessions(); len(sessions) != 0 {
+		t.Errorf("Expected nothing stored before sign-in, got %+v", sessions)
+	}
+
+	callback := httptest.NewRequest("GET", "/auth/callback", nil)
+	callback.AddCookie(cookies[0])
+	if !authService.ValidLoginState(callback, state) {
+		t.Error("Expected the state from BeginLogin to be valid")
+	}
+	if authService.ValidLoginState(callback, state+"x") {
+		t.Error("Expected a different state to be rejected")
+	}
+	if authService.ValidLoginState(httptest.NewRequest("GET", "/auth/callback", nil), state) {
+		t.Error("Expected the state to be rejected without the login cookie")
+	}
+	tampered := httptest.NewRequest("GET", "/auth/callback", nil)
+	tampered.AddCookie(&http.Cookie{Name: LoginCookieName, Value: cookies[0].Value + "x"})
+	if authService.ValidLoginState(tampered, state) {
+		t.Error("Expected the state to be rejected with a tampered login cookie")
+	}
+
+	// Anonymous sessions are never stored
+	session, err := authService.GetSession(callback)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if err := session.Save(callback, httptest.NewRecorder()); err == nil {
+		t.Error("Expected saving an anonymous session to fail")
+	}
+
+	// Signing in clears the login cookie
+	w = httptest.NewRecorder()
+	if err := authService.CreateSession(w, callback, &GoogleUserInfo{Email: "test@example.com"}); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	cleared := false
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == LoginCookieName && cookie.MaxAge < 0 {
+			cleared = true
+		}
+	}
+	if !cleared {
+		t.Error("Expected signing in to clear the login cookie")
+	}
+}
+
+func TestSessionStore_LogoutDeletesSession(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	authService := NewAuthService(store, config.AuthConfig{})
+
+	req := signIn(t, authService, "test@example.com")
+	if err := authService.ClearSession(httptest.NewRecorder(), req); err != nil {
+		t.Fatalf("Failed to clear session: %v", err)
+	}
+	if sessions, _ := store.ListSessions(); len(sessions) != 0 {
+		t.Errorf("Expected logout to delete the stored session, got %+v", sessions)
+	}
+}
+
+func TestSessionStore_ExpiredSessionsAreSignedOut(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	authService := NewAuthService(store, config.AuthConfig{})
+
+	req := signIn(t, authService, "test@example.com")
+	authService.store.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+
+	if user, err := authService.GetCurrentUser(req); err != nil || user != nil {
+		t.Errorf("Expected an expired session to be signed out, got %+v (%v)", user, err)
+	}
+	if sessions, _ := authService.ListSessions(); len(sessions) != 0 {
+		t.Errorf("Expected expired sessions not to be listed, got %+v", sessions)
+	}
+
+	// The next new session prunes it
+	signIn(t, authService, "other@example.com")
+	if stored, _ := store.ListSessions(); len(stored) != 1 || stored[0].Email != "other@example.com" {
+		t.Errorf("Expected the expired session to be pruned, got %+v", stored)
+	}
+}
+
+func TestAuthService_RevokeSessions(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	authService := NewAuthService(store, config.AuthConfig{})
+
+	laptop := signIn(t, authService, "test@example.com")
+	phone := signIn(t, authService, "test@example.com")
+	admin := signIn(t, authService, "admin@example.com")
+
+	sessions, err := authService.ListSessions()
+	if err != nil || len(sessions) != 3 {
+		t.Fatalf("Expected 3 active sessions, got %+v (%v)", sessions, err)
+	}
+	for _, session := range sessions {
+		if session.CSRFToken != "" {
+			t.Errorf("Expected listed sessions without CSRF tokens, got %+v", session)
+		}
+	}
+
+	// One session
+	revoked, err := authService.RevokeSession(authService.CurrentSessionID(phone), "admin@example.com")
+	if err != nil || !revoked {
+		t.Fatalf("Expected the phone session to be revoked, got %v (%v)", revoked, err)
+	}
+	if user, _ := authService.GetCurrentUser(withCookies(phone)); user != nil {
+		t.Errorf("Expected the phone session to be signed out, got %+v", user)
+	}
+	if revoked, _ := authService.RevokeSession("missing", "admin@example.com"); revoked {
+		t.Error("Expected revoking an unknown session to report false")
+	}
+
+	// Every session of a user
+	count, err := authService.RevokeUserSessions("TEST@example.com", "admin@example.com")
+	if err != nil || count != 1 {
+		t.Fatalf("Expected 1 remaining session to be revoked, got %d (%v)", count, err)
+	}
+	if user, _ := authService.GetCurrentUser(laptop); user != nil {
+		t.Errorf("Expected the laptop session to be signed out, got %+v", user)
+	}
+	if user, _ := authService.GetCurrentUser(admin); user == nil {
+		t.Error("Expected other users to stay signed in")
+	}
+}
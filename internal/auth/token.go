@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/privacy"
+	"watered/internal/randtoken"
+)
+
+const (
+	// DefaultAccessTokenTTL is how long an access token is valid unless
+	// ACCESS_TOKEN_TTL says otherwise
+	DefaultAccessTokenTTL = 15 * time.Minute
+	// DefaultRefreshTokenTTL is how long a refresh token is valid unless
+	// REFRESH_TOKEN_TTL says otherwise
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+	// RefreshTokenPrefix starts every refresh token, telling them apart from
+	// API keys and access tokens
+	RefreshTokenPrefix = "wr_"
+
+	// accessTokenIssuer is the iss claim of every access token
+	accessTokenIssuer = "watered"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for refresh tokens that are
+	// malformed, expired, already used or revoked, or whose user is no
+	// longer allowed
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrAppTokensNotAllowed is returned when a session may not be exchanged
+	// for app tokens, such as a recovery admin session
+	ErrAppTokensNotAllowed = errors.New("this session can't be exchanged for app tokens")
+)
+
+// accessTokenHeader is the encoded JOSE header of every access token. Tokens
+// are only accepted with exactly this header, so the algorithm can't be
+// swapped.
+var accessTokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// accessClaims is the payload of an access token, a JWT naming the app
+// session it was issued to
+type accessClaims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	SessionID string `json:"sid"`
+	Name      string `json:"name,omitempty"`
+	Role      string `json:"role"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// appUserKey is the request context key holding an access token's user
+type appUserKey struct{}
+
+// accessTokenKey derives the access token signing key from the session
+// secret, so a signature made for one purpose never verifies for another
+func accessTokenKey(sessionSecret string) []byte {
+	mac := hmac.New(sha256.New, []byte(sessionSecret))
+	mac.Write([]byte("watered access token"))
+	return mac.Sum(nil)
+}
+
+// IssueAppTokens exchanges the request's signed-in session for a new app
+// session, returning its access and refresh tokens. The cookie session
+// stays signed in.
+func (a *AuthService) IssueAppTokens(r *http.Request) (*models.AppTokens, error) {
+	session, err := a.store.Get(r, SessionCookieName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if authenticated, _ := session.Values["authenticated"].(bool); !authenticated {
+		return nil, ErrAppTokensNotAllowed
+	}
+	if recovery, _ := session.Values["recovery"].(bool); recovery {
+		return nil, ErrAppTokensNotAllowed
+	}
+
+	email, _ := session.Values["user_email"].(string)
+	userID, _ := session.Values["user_id"].(string)
+	name, _ := session.Values["user_name"].(string)
+	picture, _ := session.Values["user_picture"].(string)
+	tokens, err := a.newAppSession(r, &models.Session{
+		UserID:  userID,
+		Email:   email,
+		Name:    name,
+		Picture: picture,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("App tokens issued", "audit", true, "email", email, "remote_addr", r.RemoteAddr)
+	return tokens, nil
+}
+
+// RefreshAppTokens exchanges a refresh token for a new pair. Each refresh
+// token is good for one exchange: its app session is replaced by a new one,
+// taking the user's current role.
+func (a *AuthService) RefreshAppTokens(r *http.Request, refreshToken string) (*models.AppTokens, error) {
+	if !strings.HasPrefix(refreshToken, RefreshTokenPrefix) {
+		return nil, ErrInvalidRefreshToken
+	}
+	id := randtoken.Hash(refreshToken)
+	stored, err := a.storage.GetSession(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	if stored == nil || !stored.App || !a.store.now().Before(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err := a.storage.DeleteSession(id); err != nil {
+		return nil, fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	// Removing the user from the allowlist ends their app sessions too
+	if !a.IsUserAllowed(stored.Email) && !a.IsUserAdmin(stored.Email) {
+		slog.Warn("Refused app token refresh for user no longer allowed", "audit", true, "email", stored.Email, "remote_addr", r.RemoteAddr)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return a.newAppSession(r, &models.Session{
+		UserID:    stored.UserID,
+		Email:     stored.Email,
+		Name:      stored.Name,
+		Picture:   stored.Picture,
+		CreatedAt: stored.CreatedAt,
+	})
+}
+
+// newAppSession stores an app session for the user of session and returns
+// its tokens. The refresh token is the session's ID.
+func (a *AuthService) newAppSession(r *http.Request, session *models.Session) (*models.AppTokens, error) {
+	secret, err := randtoken.String(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	refreshToken := RefreshTokenPrefix + secret
+
+	now := a.store.now()
+	role := a.UserRole(session.Email)
+	session.ID = randtoken.Hash(refreshToken)
+	session.IsAdmin = role == privacy.RoleAdmin
+	session.Role = role.String()
+	session.Authenticated = true
+	session.App = true
+	session.UserAgent = r.UserAgent()
+	session.RemoteAddr = r.RemoteAddr
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	session.LastSeen = now
+	session.ExpiresAt = now.Add(a.refreshTTL)
+	if err := a.storage.SaveSession(session); err != nil {
+		return nil, fmt.Errorf("failed to store session: %w", err)
+	}
+
+	accessToken, err := a.signAccessToken(accessClaims{
+		Issuer:    accessTokenIssuer,
+		Subject:   session.Email,
+		SessionID: session.ID,
+		Name:      session.Name,
+		Role:      session.Role,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(a.accessTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AppTokens{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(a.accessTTL / time.Second),
+		RefreshToken:     refreshToken,
+		RefreshExpiresIn: int(a.refreshTTL / time.Second),
+	}, nil
+}
+
+// signAccessToken encodes claims as an HS256 JWT
+func (a *AuthService) signAccessToken(claims accessClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode access token: %w", err)
+	}
+	signed := accessTokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(a.signToken(signed)), nil
+}
+
+// signToken returns the HMAC-SHA256 of a token's header and payload
+func (a *AuthService) signToken(signed string) []byte {
+	mac := hmac.New(sha256.New, a.tokenKey)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
+
+// AuthenticateAccessToken returns the user an access token was issued to, or
+// nil when the token is malformed, tampered with or expired, or its app
+// session was signed out
+func (a *AuthService) AuthenticateAccessToken(token string) *models.User {
+	header, rest, ok := strings.Cut(token, ".")
+	if !ok || subtle.ConstantTimeCompare([]byte(header), []byte(accessTokenHeader)) != 1 {
+		return nil
+	}
+	payload, signature, ok := strings.Cut(rest, ".")
+	if !ok {
+		return nil
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, a.signToken(header+"."+payload)) {
+		return nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil
+	}
+	var claims accessClaims
+	if err := json.Unmarshal(decoded, &claims); err != nil || claims.Issuer != accessTokenIssuer || claims.Subject == "" {
+		return nil
+	}
+	now := a.store.now()
+	if now.Unix() >= claims.ExpiresAt {
+		return nil
+	}
+
+	// Signing the app session out revokes its access tokens straight away
+	stored, err := a.storage.GetSession(claims.SessionID)
+	if err != nil || stored == nil || !stored.App || !now.Before(stored.ExpiresAt) {
+		return nil
+	}
+
+	return &models.User{
+		Email:   claims.Subject,
+		Name:    claims.Name,
+		IsAdmin: claims.Role == privacy.RoleAdmin.String(),
+		Role:    claims.Role,
+	}
+}
+
+// appUser returns the user authenticated by an access token, if any
+func appUser(r *http.Request) *models.User {
+	user, _ := r.Context().Value(appUserKey{}).(*models.User)
+	return user
+}
+
+// withAppUser returns ctx carrying an access token's user
+func withAppUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, appUserKey{}, user)
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/privacy"
+	"watered/internal/randtoken"
+)
+
+func TestIssueAppTokens(t *testing.T) {
+	authService := newAPIKeyTestService(t)
+
+	if _, err := authService.IssueAppTokens(httptest.NewRequest("POST", "/auth/token", nil)); err != ErrAppTokensNotAllowed {
+		t.Errorf("Expected a signed-out request to be refused, got %v", err)
+	}
+
+	req := withCookies(signIn(t, authService, "user@example.com"))
+	tokens, err := authService.IssueAppTokens(req)
+	if err != nil {
+		t.Fatalf("Failed to issue app tokens: %v", err)
+	}
+	if tokens.TokenType != "Bearer" || tokens.ExpiresIn != int(DefaultAccessTokenTTL/time.Second) ||
+		tokens.RefreshExpiresIn != int(DefaultRefreshTokenTTL/time.Second) {
+		t.Errorf("Unexpected token metadata: %+v", tokens)
+	}
+	if !strings.HasPrefix(tokens.RefreshToken, RefreshTokenPrefix) || strings.Count(tokens.AccessToken, ".") != 2 {
+		t.Errorf("Expected a refresh token and a JWT, got %+v", tokens)
+	}
+
+	user := authService.AuthenticateAccessToken(tokens.AccessToken)
+	if user == nil || user.Email != "user@example.com" || user.Role != privacy.RoleMember.String() || user.IsAdmin {
+		t.Fatalf("Expected the access token to act as the member, got %+v", user)
+	}
+
+	// The app gets its own session, listed and stored under the refresh
+	// token's hash, and the browser stays signed in
+	sessions, _ := authService.ListSessions()
+	if len(sessions) != 2 {
+		t.Fatalf("Expected the browser and app sessions, got %+v", sessions)
+	}
+	stored, _ := authService.storage.GetSession(randtoken.Hash(tokens.RefreshToken))
+	if stored == nil || !stored.App || stored.Email != "user@example.com" {
+		t.Errorf("Expected an app session for the refresh token, got %+v", stored)
+	}
+}
+
+func TestIssueAppTokens_RecoverySession(t *testing.T) {
+	authService := newAPIKeyTestService(t)
+	token, _ := authService.EnableRecovery(time.Hour)
+
+	w := httptest.NewRecorder()
+	if err := authService.RedeemRecoveryToken(w, httptest.NewRequest("POST", "/auth/recovery", nil), token, "admin@example.com"); err != nil {
+		t.Fatalf("Failed to redeem recovery token: %v", err)
+	}
+	req := httptest.NewRequest("POST", "/auth/token", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	if _, err := authService.IssueAppTokens(req); err != ErrAppTokensNotAllowed {
+		t.Errorf("Expected a recovery session to be refused, got %v", err)
+	}
+}
+
+func TestAuthenticateAccessToken(t *testing.T) {
+	authService := newAPIKeyTestService(t)
+	tokens, err := authService.IssueAppTokens(withCookies(signIn(t, authService, "admin@example.com")))
+	if err != nil {
+		t.Fatalf("Failed to issue app tokens: %v", err)
+	}
+	if user := authService.AuthenticateAccessToken(tokens.AccessToken); user == nil || !user.IsAdmin {
+		t.Fatalf("Expected the access token to carry the admin role, got %+v", user)
+	}
+
+	header, rest, _ := strings.Cut(tokens.AccessToken, ".")
+	payload, _, _ := strings.Cut(rest, ".")
+	other := NewAuthService(authService.storage, config.AuthConfig{SessionSecret: "another-secret"})
+	forged, _ := other.IssueAppTokens(withCookies(signIn(t, other, "user@example.com")))
+	for name, invalid := range map[string]string{
+		"empty":          "",
+		"garbage":        "not.a.token",
+		"no signature":   header + "." + payload,
+		"bad signature":  header + "." + payload + ".AAAA",
+		"none algorithm": "eyJhbGciOiJub25lIn0." + payload + ".",
+		"other secret":   forged.AccessToken,
+	} {
+		if user := authService.AuthenticateAccessToken(invalid); user != nil {
+			t.Errorf("Expected %s token to be rejected, got %+v", name, user)
+		}
+	}
+
+	// Tokens stop working once they expire
+	now := time.Now()
+	authService.store.now = func() time.Time { return now.Add(DefaultAccessTokenTTL) }
+	if user := authService.AuthenticateAccessToken(tokens.AccessToken); user != nil {
+		t.Errorf("Expected an expired access token to be rejected, got %+v", user)
+	}
+	authService.store.now = time.Now
+
+	// Signing the app out revokes its access token straight away
+	if _, err := authService.RevokeUserSessions("admin@example.com", "admin@example.com"); err != nil {
+		t.Fatalf("Failed to revoke sessions: %v", err)
+	}
+	if user := authService.AuthenticateAccessToken(tokens.AccessToken); user != nil {
+		t.Errorf("Expected a signed-out app's access token to be rejected, got %+v", user)
+	}
+}
+
+func TestRefreshAppTokens(t *testing.T) {
+	authService := newAPIKeyTestService(t)
+	tokens, err := authService.IssueAppTokens(withCookies(signIn(t, authService, "user@example.com")))
+	if err != nil {
+		t.Fatalf("Failed to issue app tokens: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/auth/token", nil)
+	refreshed, err := authService.RefreshAppTokens(req, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to refresh app tokens: %v", err)
+	}
+	if refreshed.RefreshToken == tokens.RefreshToken {
+		t.Error("Expected refreshing to rotate the refresh token")
+	}
+	if user := authService.AuthenticateAccessToken(refreshed.AccessToken); user == nil || user.Email != "user@example.com" {
+		t.Errorf("Expected the new access token to work, got %+v", user)
+	}
+
+	// Each refresh token is good for one exchange, and the access tokens
+	// issued with it end with it
+	if _, err := authService.RefreshAppTokens(req, tokens.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("Expected a used refresh token to be rejected, got %v", err)
+	}
+	if user := authService.AuthenticateAccessToken(tokens.AccessToken); user != nil {
+		t.Errorf("Expected the old access token to be rejected, got %+v", user)
+	}
+	for _, invalid := range []string{"", "wr_unknown", strings.TrimPrefix(refreshed.RefreshToken, RefreshTokenPrefix)} {
+		if _, err := authService.RefreshAppTokens(req, invalid); err != ErrInvalidRefreshToken {
+			t.Errorf("Expected %q to be rejected, got %v", invalid, err)
+		}
+	}
+
+	// Refreshing takes the user's current role, and removing them from the
+	// allowlist ends the app session
+	authService.storage.UpdateAdminConfig(&models.AdminConfig{
+		AllowedEmails: []string{"user@example.com"},
+		AdminEmails:   []string{"admin@example.com"},
+		ViewerEmails:  []string{"user@example.com"},
+	})
+	viewer, err := authService.RefreshAppTokens(req, refreshed.RefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to refresh app tokens: %v", err)
+	}
+	if user := authService.AuthenticateAccessToken(viewer.AccessToken); user == nil || user.Role != privacy.RoleViewer.String() {
+		t.Errorf("Expected the refreshed token to carry the viewer role, got %+v", user)
+	}
+	authService.storage.UpdateAdminConfig(&models.AdminConfig{AdminEmails: []string{"admin@example.com"}})
+	if _, err := authService.RefreshAppTokens(req, viewer.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("Expected a removed user's refresh token to be rejected, got %v", err)
+	}
+}
+
+func TestAPIKeyAuthMiddleware_AccessToken(t *testing.T) {
+	authService := newAPIKeyTestService(t)
+	tokens, err := authService.IssueAppTokens(withCookies(signIn(t, authService, "user@example.com")))
+	if err != nil {
+		t.Fatalf("Failed to issue app tokens: %v", err)
+	}
+
+	var current *models.User
+	var role privacy.Role
+	var key string
+	handler := authService.APIKeyAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current, _ = authService.GetCurrentUser(r)
+		role = authService.CallerRole(r)
+		key = authService.ClientKey(r)
+	}))
+
+	req := httptest.NewRequest("GET", "/api/plant", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || current == nil || current.Email != "user@example.com" {
+		t.Fatalf("Expected the access token to sign the request in, got %d and %+v", rr.Code, current)
+	}
+	if role != privacy.RoleMember || key != "user:user@example.com" || ViaAPIKey(req) {
+		t.Errorf("Expected access token clients to act as the user, got role %s and key %q", role, key)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken+"x")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized || !strings.Contains(rr.Header().Get("WWW-Authenticate"), "invalid_token") {
+		t.Errorf("Expected an invalid access token to be refused, got %d %q", rr.Code, rr.Header().Get("WWW-Authenticate"))
+	}
+}
@@ -0,0 +1,168 @@
+// Package backup uploads scheduled backup archives, the same archives
+// GET /admin/backup downloads, to an S3-compatible bucket such as Google
+// Cloud Storage, and deletes all but the newest few. The outcome of the
+// latest upload is reported by /health/detailed.
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/storage"
+)
+
+// Object is a stored object's listing entry
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Bucket stores backup archives
+type Bucket interface {
+	Put(ctx context.Context, key string, data []byte) error
+	List(ctx context.Context, prefix string) ([]Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Archive names look like watered-backup-20240601T020000Z.json, so they
+// sort oldest first
+const (
+	namePrefix = "watered-backup-"
+	nameSuffix = ".json"
+	nameTime   = "20060102T150405Z"
+)
+
+// Status reports the latest upload and how many backups the bucket holds
+type Status struct {
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastObject  string     `json:"last_object,omitempty"`
+	LastSize    int        `json:"last_size_bytes,omitempty"`
+	LastFailure *time.Time `json:"last_failure,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	// Retained is how many backups were in the bucket after the latest upload
+	Retained int `json:"retained"`
+}
+
+// Uploader uploads backups of a store to a bucket
+type Uploader struct {
+	store    storage.Storage
+	bucket   Bucket
+	prefix   string
+	retain   int
+	interval time.Duration
+	now      func() time.Time
+	started  time.Time
+
+	mu     sync.Mutex
+	status Status
+}
+
+// New creates an uploader that writes to bucket under cfg.Prefix and keeps
+// the newest cfg.Retain backups
+func New(store storage.Storage, bucket Bucket, cfg config.BackupConfig) *Uploader {
+	return &Uploader{
+		store:    store,
+		bucket:   bucket,
+		prefix:   cfg.Prefix,
+		retain:   cfg.Retain,
+		interval: cfg.Interval,
+		now:      time.Now,
+		started:  time.Now(),
+	}
+}
+
+// Run uploads a backup and then deletes all but the newest backups. A
+// failed upload deletes nothing.
+func (u *Uploader) Run(ctx context.Context) error {
+	key, size, retained, err := u.upload(ctx)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	now := u.now()
+	if key != "" {
+		u.status.LastSuccess = &now
+		u.status.LastObject = key
+		u.status.LastSize = size
+	}
+	if retained > 0 {
+		u.status.Retained = retained
+	}
+	if err != nil {
+		u.status.LastFailure = &now
+		u.status.LastError = err.Error()
+		return err
+	}
+	u.status.LastError = ""
+	return nil
+}
+
+// upload writes one backup and prunes old ones, returning the new object's
+// key once it is uploaded and how many backups remain once pruning is done
+func (u *Uploader) upload(ctx context.Context) (key string, size, retained int, err error) {
+	backup, err := u.store.Backup()
+	if err != nil {
+		return "", 0, 0, err
+	}
+	data, err := json.Marshal(backup)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to encode backup: %w", err)
+	}
+
+	key = u.prefix + namePrefix + backup.CreatedAt.UTC().Format(nameTime) + nameSuffix
+	if err := u.bucket.Put(ctx, key, data); err != nil {
+		return "", 0, 0, fmt.Errorf("failed to upload backup: %w", err)
+	}
+	slog.Info("Uploaded backup", "object", key, "bytes", len(data))
+
+	retained, err = u.prune(ctx)
+	return key, len(data), retained, err
+}
+
+// prune deletes all but the newest u.retain backups and returns how many
+// remain. Other objects under the prefix are left alone.
+func (u *Uploader) prune(ctx context.Context) (int, error) {
+	objects, err := u.bucket.List(ctx, u.prefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var keys []string
+	for _, object := range objects {
+		name := strings.TrimPrefix(object.Key, u.prefix)
+		if strings.HasPrefix(name, namePrefix) && strings.HasSuffix(name, nameSuffix) && !strings.Contains(name, "/") {
+			keys = append(keys, object.Key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) <= u.retain {
+		return len(keys), nil
+	}
+
+	var errs []error
+	remaining := len(keys)
+	for _, key := range keys[:len(keys)-u.retain] {
+		if err := u.bucket.Delete(ctx, key); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete old backup %s: %w", key, err))
+			continue
+		}
+		slog.Info("Deleted old backup", "object", key)
+		remaining--
+	}
+	return remaining, errors.Join(errs...)
+}
+
+// Status reports the latest upload
+func (u *Uploader) Status() Status {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.status
+}
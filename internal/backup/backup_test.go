@@ -0,0 +1,133 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/monitoring"
+	"watered/internal/storage"
+)
+
+// memoryBucket is a Bucket that keeps objects in a map
+type memoryBucket struct {
+	objects   map[string][]byte
+	putErr    error
+	deleteErr error
+}
+
+func (b *memoryBucket) Put(ctx context.Context, key string, data []byte) error {
+	if b.putErr != nil {
+		return b.putErr
+	}
+	b.objects[key] = data
+	return nil
+}
+
+func (b *memoryBucket) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	for key, data := range b.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, Object{Key: key, Size: int64(len(data))})
+		}
+	}
+	return objects, nil
+}
+
+func (b *memoryBucket) Delete(ctx context.Context, key string) error {
+	if b.deleteErr != nil {
+		return b.deleteErr
+	}
+	delete(b.objects, key)
+	return nil
+}
+
+// keys returns the bucket's keys in order
+func (b *memoryBucket) keys() []string {
+	var keys []string
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestUploader_RunKeepsNewest(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	if err := store.CreatePlant(&models.PlantState{Name: "Fern", TimeoutHours: 48}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	bucket := &memoryBucket{objects: map[string][]byte{
+		"watered/notes.txt":                                []byte("keep me"),
+		"watered/watered-backup-20240101T000000Z.json":     []byte(`{}`),
+		"watered/watered-backup-20240102T000000Z.json":     []byte(`{}`),
+		"watered/watered-backup-20240103T000000Z.json":     []byte(`{}`),
+		"watered/old/watered-backup-20200101T000000Z.json": []byte(`{}`),
+	}}
+	uploader := New(store, bucket, config.BackupConfig{Prefix: "watered/", Retain: 2, Interval: 24 * time.Hour})
+
+	if err := uploader.Run(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	status := uploader.Status()
+	if status.LastSuccess == nil || status.LastError != "" || status.Retained != 2 {
+		t.Errorf("Expected a successful upload keeping two backups, got %+v", status)
+	}
+	want := []string{
+		"watered/notes.txt",
+		"watered/old/watered-backup-20200101T000000Z.json",
+		"watered/watered-backup-20240103T000000Z.json",
+		status.LastObject,
+	}
+	if got := bucket.keys(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	backup, err := storage.ParseBackup(bucket.objects[status.LastObject])
+	if err != nil || len(backup.Plants) != 1 || backup.Plants[0].Name != "Fern" {
+		t.Errorf("Expected a restorable backup of the fern, got %+v (%v)", backup, err)
+	}
+}
+
+func TestUploader_Health(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	bucket := &memoryBucket{objects: map[string][]byte{}}
+	uploader := New(store, bucket, config.BackupConfig{Prefix: "watered/", Retain: 7, Interval: time.Hour})
+	now := time.Now()
+	uploader.now = func() time.Time { return now }
+
+	if health := uploader.Check(context.Background()); health.Status != monitoring.HealthStatusHealthy || health.Message != "No backup yet" {
+		t.Errorf("Expected healthy before the first backup, got %+v", health)
+	}
+
+	bucket.putErr = errors.New("bucket returned status 403: AccessDenied: Access denied")
+	if err := uploader.Run(context.Background()); err == nil {
+		t.Fatal("Expected the upload to fail")
+	}
+	health := uploader.Check(context.Background())
+	if health.Status != monitoring.HealthStatusDegraded || !strings.Contains(health.Message, "AccessDenied") {
+		t.Errorf("Expected degraded after a failed upload, got %+v", health)
+	}
+
+	bucket.putErr = nil
+	if err := uploader.Run(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if health := uploader.Check(context.Background()); health.Status != monitoring.HealthStatusHealthy {
+		t.Errorf("Expected healthy after a successful upload, got %+v", health)
+	}
+
+	now = now.Add(3 * time.Hour)
+	if health := uploader.Check(context.Background()); health.Status != monitoring.HealthStatusDegraded || !strings.Contains(health.Message, "No backup for 3h") {
+		t.Errorf("Expected degraded once backups are overdue, got %+v", health)
+	}
+}
@@ -0,0 +1,54 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"watered/internal/monitoring"
+)
+
+// Name returns the name of the uploader's health check
+func (u *Uploader) Name() string {
+	return "backup"
+}
+
+// Check reports the latest upload. Backups are degraded while the latest
+// run failed, or when none has succeeded for two intervals.
+func (u *Uploader) Check(ctx context.Context) monitoring.ComponentHealth {
+	start := time.Now()
+	status := u.Status()
+
+	health := monitoring.ComponentHealth{
+		Name:        u.Name(),
+		Status:      monitoring.HealthStatusHealthy,
+		LastChecked: start,
+		Details: map[string]interface{}{
+			"interval": u.interval.String(),
+			"retain":   u.retain,
+			"status":   status,
+		},
+	}
+
+	since := u.started
+	if status.LastSuccess != nil {
+		since = *status.LastSuccess
+	}
+	age := u.now().Sub(since)
+
+	switch {
+	case status.LastError != "":
+		health.Status = monitoring.HealthStatusDegraded
+		health.Message = "Latest backup failed: " + status.LastError
+	case age > 2*u.interval:
+		health.Status = monitoring.HealthStatusDegraded
+		health.Message = fmt.Sprintf("No backup for %s", age.Round(time.Minute))
+	case status.LastSuccess == nil:
+		health.Message = "No backup yet"
+	default:
+		health.Message = fmt.Sprintf("Last backup %s ago, %d kept", age.Round(time.Second), status.Retained)
+	}
+
+	health.Duration = time.Since(start)
+	return health
+}
@@ -0,0 +1,69 @@
+// Package badge draws small status badges in the style of shields.io, for
+// embedding a plant's status in a README or dashboard.
+package badge
+
+import (
+	"fmt"
+	"html"
+	"time"
+	"unicode/utf8"
+)
+
+// Colors for the message half of a badge
+const (
+	Green  = "#4c1"
+	Yellow = "#dfb317"
+	Red    = "#e05d44"
+	Grey   = "#9f9f9f"
+)
+
+// labelColor fills the label half of every badge
+const labelColor = "#555"
+
+// charWidth approximates the advance of an 11px Verdana character. Badges
+// are not typeset, so long text overflows slightly rather than being cut.
+const charWidth = 7
+
+// padding surrounds the text of each half
+const padding = 10
+
+// SVG draws a badge reading label on the left and message on the right, with
+// the message half filled in color
+func SVG(label, message, color string) []byte {
+	labelWidth := textWidth(label)
+	messageWidth := textWidth(message)
+	width := labelWidth + messageWidth
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[3]s: %[4]s">`+
+		`<title>%[3]s: %[4]s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="%[6]s"/><rect x="%[2]d" width="%[5]d" height="20" fill="%[7]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[3]s</text><text x="%[8]d" y="14">%[3]s</text>`+
+		`<text x="%[9]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[9]d" y="14">%[4]s</text>`+
+		`</g></svg>`,
+		width, labelWidth, label, message, messageWidth, labelColor, color,
+		labelWidth/2, labelWidth+messageWidth/2))
+}
+
+// textWidth estimates the width of one half of a badge holding s
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s)*charWidth + padding
+}
+
+// Age formats how long ago something happened as briefly as a badge needs,
+// such as "5m ago", "3h ago" or "2d ago"
+func Age(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,59 @@
+package badge
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSVG(t *testing.T) {
+	svg := SVG("watered", "3h ago", Green)
+
+	if err := xml.Unmarshal(svg, new(struct{})); err != nil {
+		t.Fatalf("Expected well-formed XML, got %v", err)
+	}
+	for _, want := range []string{`aria-label="watered: 3h ago"`, `fill="#4c1"`, `>3h ago</text>`} {
+		if !strings.Contains(string(svg), want) {
+			t.Errorf("Expected %q in %s", want, svg)
+		}
+	}
+
+	// Wider text makes a wider badge
+	if textWidth("watered") <= textWidth("a") {
+		t.Error("Expected the badge width to follow the text")
+	}
+}
+
+func TestSVG_EscapesText(t *testing.T) {
+	svg := string(SVG(`<script>alert("x")</script>`, "a & b", Red))
+
+	if strings.Contains(svg, "<script>") {
+		t.Errorf("Expected the label to be escaped, got %s", svg)
+	}
+	if !strings.Contains(svg, "a &amp; b") {
+		t.Errorf("Expected the message to be escaped, got %s", svg)
+	}
+	if err := xml.Unmarshal([]byte(svg), new(struct{})); err != nil {
+		t.Errorf("Expected well-formed XML, got %v", err)
+	}
+}
+
+func TestAge(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{3*time.Hour + 40*time.Minute, "3h ago"},
+		{47 * time.Hour, "47h ago"},
+		{50 * time.Hour, "2d ago"},
+		{10 * 24 * time.Hour, "10d ago"},
+	}
+	for _, tt := range tests {
+		if got := Age(tt.age); got != tt.want {
+			t.Errorf("Age(%s) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
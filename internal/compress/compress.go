@@ -0,0 +1,271 @@
+// Package compress gzips text responses, such as JSON and HTML, for clients
+// that accept it, cutting the mobile data the app's polling uses. Responses
+// too small to gain from it, already compressed ones like PNG icons, and
+// streams like server-sent events are sent as they are.
+//
+// Only gzip is offered: Brotli needs an encoder from outside the standard
+// library, which this module does not depend on. Encodings are negotiated in
+// the order of the encodings list, so adding one is a single entry.
+package compress
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// encoding is a content coding the server can produce
+type encoding struct {
+	name string
+	pool *sync.Pool
+}
+
+// encodings are the content codings offered, most preferred first
+var encodings = []encoding{
+	{name: "gzip", pool: &sync.Pool{New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}}},
+}
+
+// compressibleTypes are the media types worth compressing. Images other than
+// SVG, archives and fonts are compressed already.
+var compressibleTypes = map[string]bool{
+	"application/json":          true,
+	"application/problem+json":  true,
+	"application/manifest+json": true,
+	"application/javascript":    true,
+	"application/xml":           true,
+	"application/atom+xml":      true,
+	"image/svg+xml":             true,
+	"text/html":                 true,
+	"text/css":                  true,
+	"text/javascript":           true,
+	"text/plain":                true,
+	"text/calendar":             true,
+	"text/csv":                  true,
+}
+
+// Compressible reports whether responses of contentType are worth
+// compressing
+func Compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && compressibleTypes[mediaType]
+}
+
+// Negotiate returns the encoding to use for a request's Accept-Encoding
+// header, or "" when the client accepts none the server offers
+func Negotiate(acceptEncoding string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		qualities[name] = quality
+	}
+
+	for _, enc := range encodings {
+		quality, ok := qualities[enc.name]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > 0 {
+			return enc.name
+		}
+	}
+	return ""
+}
+
+// Middleware compresses compressible responses of at least minSize bytes.
+// A minSize of 0 or less disables compression.
+func Middleware(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if minSize <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cw := &compressWriter{ResponseWriter: w, minSize: minSize, status: http.StatusOK}
+			// HEAD responses have no body to compress
+			if name := Negotiate(r.Header.Get("Accept-Encoding")); r.Method != http.MethodHead {
+				for i := range encodings {
+					if encodings[i].name == name {
+						cw.encoding = &encodings[i]
+					}
+				}
+			}
+			defer cw.finish()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// compressWriter holds a response's body back until it knows whether the
+// response is worth compressing, then compresses it or passes it through
+type compressWriter struct {
+	http.ResponseWriter
+	// encoding is the negotiated encoding, nil when the client accepts none
+	encoding *encoding
+	minSize  int
+	status   int
+	decided  bool
+	// buffer holds the body written before the decision
+	buffer []byte
+	// encoder is set while compressing
+	encoder *gzip.Writer
+}
+
+// eligible reports whether the response may be compressed, going by its
+// headers
+func (w *compressWriter) eligible() bool {
+	header := w.Header()
+	switch {
+	case w.status < http.StatusOK, w.status == http.StatusNoContent, w.status == http.StatusPartialContent, w.status == http.StatusNotModified:
+		return false
+	case header.Get("Content-Encoding") != "", header.Get("Content-Range") != "":
+		return false
+	case !Compressible(header.Get("Content-Type")):
+		return false
+	}
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil && length < w.minSize {
+		return false
+	}
+	return true
+}
+
+// decide compresses or passes the response through. Compressing needs an
+// accepted encoding, compressible headers and, unless the response is being
+// streamed, a body of at least minSize bytes.
+func (w *compressWriter) decide(streaming bool) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	// Like net/http, sniff the content type when the handler set none
+	if w.Header().Get("Content-Type") == "" && len(w.buffer) > 0 {
+		w.Header().Set("Content-Type", http.DetectContentType(w.buffer))
+	}
+	if !w.eligible() {
+		w.pass()
+		return
+	}
+	// The response would differ for clients accepting other encodings
+	w.Header().Add("Vary", "Accept-Encoding")
+	if w.encoding == nil || (!streaming && len(w.buffer) < w.minSize) {
+		w.pass()
+		return
+	}
+
+	w.Header().Set("Content-Encoding", w.encoding.name)
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	w.encoder = w.encoding.pool.Get().(*gzip.Writer)
+	w.encoder.Reset(w.ResponseWriter)
+	w.encoder.Write(w.buffer)
+	w.buffer = nil
+}
+
+// pass sends the status and any held back body as they are
+func (w *compressWriter) pass() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buffer) > 0 {
+		w.ResponseWriter.Write(w.buffer)
+	}
+	w.buffer = nil
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+	// Informational responses are sent straight away and don't end the
+	// headers of the final one
+	if status >= 100 && status < http.StatusOK && status != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(status)
+		w.status = http.StatusOK
+		return
+	}
+	if !w.eligible() {
+		w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		w.buffer = append(w.buffer, p...)
+		if len(w.buffer) >= w.minSize || (w.Header().Get("Content-Type") != "" && !w.eligible()) {
+			w.decide(false)
+		}
+		return len(p), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends what has been written so far. A response flushed before
+// reaching minSize is being streamed, so it is compressed if it is
+// eligible at all.
+func (w *compressWriter) Flush() {
+	w.decide(true)
+	if w.encoder != nil {
+		w.encoder.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack lets WebSocket upgrades through
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	w.decided = true
+	return hijacker.Hijack()
+}
+
+// finish sends a response that was never decided on, as it stayed below
+// minSize, and ends the compressed stream of one that was compressed
+func (w *compressWriter) finish() {
+	if !w.decided {
+		// Nothing was written, so the response keeps its implicit 200
+		if len(w.buffer) == 0 && w.status == http.StatusOK {
+			w.decided = true
+			return
+		}
+		w.decide(false)
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+		w.encoder.Reset(io.Discard)
+		w.encoding.pool.Put(w.encoder)
+		w.encoder = nil
+	}
+}
@@ -0,0 +1,186 @@
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"gzip, deflate, br", "gzip"},
+		{"br;q=1.0, GZIP;q=0.5", "gzip"},
+		{"gzip;q=0", ""},
+		{"*", "gzip"},
+		{"*;q=0.1, gzip;q=0", ""},
+		{"identity", ""},
+		{"gzip;q=bad", ""},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCompressible(t *testing.T) {
+	for contentType, want := range map[string]bool{
+		"application/json":          true,
+		"text/html; charset=utf-8":  true,
+		"application/manifest+json": true,
+		"image/svg+xml":             true,
+		"image/png":                 false,
+		"text/event-stream":         false,
+		"application/gzip":          false,
+		"":                          false,
+	} {
+		if got := Compressible(contentType); got != want {
+			t.Errorf("Compressible(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	large := `{"plants":"` + strings.Repeat("fern ", 200) + `"}`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		// Written in pieces, so the decision waits for the minimum size
+		for i := 0; i < len(large); i += 100 {
+			io.WriteString(w, large[i:min(i+100, len(large))])
+		}
+	})
+	mux.HandleFunc("/small", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"status":"healthy"}`)
+	})
+	mux.HandleFunc("/sniffed", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<!DOCTYPE html><p>"+strings.Repeat("Watered ", 100))
+	})
+	mux.HandleFunc("/icon.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(make([]byte, 2048))
+	})
+	mux.HandleFunc("/encoded", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		io.WriteString(w, large)
+	})
+	mux.HandleFunc("/unchanged", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotModified)
+	})
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Expected the stream to flush, got %v", err)
+		}
+		io.WriteString(w, "retry: 5000\n\n")
+	})
+	handler := Middleware(512)(mux)
+
+	get := func(method, path, acceptEncoding string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		if acceptEncoding != "" {
+			r.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	gunzip := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		t.Helper()
+		reader, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("Expected a gzip body, got %v", err)
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return string(body)
+	}
+
+	t.Run("large JSON is compressed", func(t *testing.T) {
+		w := get(http.MethodGet, "/large", "gzip, deflate, br")
+		if w.Code != http.StatusCreated {
+			t.Errorf("Expected the handler's status, got %d", w.Code)
+		}
+		if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Fatalf("Expected a gzip response varying on Accept-Encoding, got %v", w.Header())
+		}
+		if w.Body.Len() >= len(large) {
+			t.Errorf("Expected the body to shrink from %d bytes, got %d", len(large), w.Body.Len())
+		}
+		if body := gunzip(t, w); body != large {
+			t.Errorf("Expected the original body back, got %q", body)
+		}
+	})
+
+	t.Run("sniffed HTML is compressed", func(t *testing.T) {
+		w := get(http.MethodGet, "/sniffed", "gzip")
+		if w.Header().Get("Content-Encoding") != "gzip" || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+			t.Fatalf("Expected sniffed HTML compressed, got %v", w.Header())
+		}
+		if body := gunzip(t, w); !strings.HasPrefix(body, "<!DOCTYPE html>") {
+			t.Errorf("Expected the page back, got %q", body)
+		}
+	})
+
+	t.Run("client without gzip", func(t *testing.T) {
+		w := get(http.MethodGet, "/large", "")
+		if w.Header().Get("Content-Encoding") != "" || w.Body.String() != large {
+			t.Errorf("Expected the body as is, got %v", w.Header())
+		}
+		if w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("Expected Vary: Accept-Encoding, got %q", w.Header().Get("Vary"))
+		}
+	})
+
+	for _, tt := range []struct {
+		name, method, path string
+		status             int
+	}{
+		{"small response", http.MethodGet, "/small", http.StatusOK},
+		{"already compressed type", http.MethodGet, "/icon.png", http.StatusOK},
+		{"not modified", http.MethodGet, "/unchanged", http.StatusNotModified},
+		{"head", http.MethodHead, "/large", http.StatusCreated},
+		{"event stream", http.MethodGet, "/events", http.StatusOK},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(tt.method, tt.path, "gzip")
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if encoding := w.Header().Get("Content-Encoding"); encoding != "" {
+				t.Errorf("Expected no Content-Encoding, got %q", encoding)
+			}
+		})
+	}
+
+	t.Run("already encoded", func(t *testing.T) {
+		w := get(http.MethodGet, "/encoded", "gzip")
+		if w.Body.String() != large {
+			t.Error("Expected a handler's own encoding left alone")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/large", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		Middleware(0)(mux).ServeHTTP(w, r)
+		if w.Header().Get("Content-Encoding") != "" || w.Body.String() != large {
+			t.Errorf("Expected compression disabled, got %v", w.Header())
+		}
+	})
+}
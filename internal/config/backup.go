@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// BackupConfig holds scheduled backups to an S3-compatible bucket. Google
+// Cloud Storage is reached through its XML API with an HMAC key.
+type BackupConfig struct {
+	Bucket string // BACKUP_BUCKET
+	// Endpoint is the storage service, such as https://s3.eu-west-1.amazonaws.com
+	Endpoint  string // BACKUP_ENDPOINT
+	Region    string // BACKUP_REGION, "auto" for Google Cloud Storage
+	AccessKey string // BACKUP_ACCESS_KEY
+	SecretKey string // BACKUP_SECRET_KEY
+	// Prefix is prepended to every object name, such as "watered/"
+	Prefix   string        // BACKUP_PREFIX
+	Interval time.Duration // BACKUP_INTERVAL
+	// Retain is how many backups are kept; older ones are deleted after
+	// each upload
+	Retain int // BACKUP_RETAIN
+}
+
+// Enabled reports whether a bucket is configured
+func (c BackupConfig) Enabled() bool {
+	return c.Bucket != ""
+}
+
+// validate returns the problems with the backup settings
+func (c BackupConfig) validate() []string {
+	var problems []string
+	if endpoint, err := url.Parse(c.Endpoint); err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		problems = append(problems, fmt.Sprintf("BACKUP_ENDPOINT must be an http or https URL, got %q", c.Endpoint))
+	}
+	if strings.Contains(c.Bucket, "/") {
+		problems = append(problems, fmt.Sprintf("BACKUP_BUCKET must be a bucket name, got %q", c.Bucket))
+	}
+	if c.Region == "" {
+		problems = append(problems, "BACKUP_REGION must not be empty")
+	}
+	if c.AccessKey == "" || c.SecretKey == "" {
+		problems = append(problems, "BACKUP_BUCKET requires BACKUP_ACCESS_KEY and BACKUP_SECRET_KEY")
+	}
+	if c.Interval <= 0 {
+		problems = append(problems, fmt.Sprintf("BACKUP_INTERVAL must be positive, got %s", c.Interval))
+	}
+	if c.Retain < 1 {
+		problems = append(problems, fmt.Sprintf("BACKUP_RETAIN must be at least 1, got %d", c.Retain))
+	}
+	return problems
+}
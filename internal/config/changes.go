@@ -0,0 +1,26 @@
+package config
+
+import "sort"
+
+// Change is a setting whose value differs between two configurations. The
+// values of secrets are masked.
+type Change struct {
+	Setting string `json:"setting"`
+	Old     string `json:"old"`
+	New     string `json:"new"`
+}
+
+// Changes lists the settings that differ in next, sorted by name
+func (c *Config) Changes(next *Config) []Change {
+	oldValues, newValues := c.settings(false), next.settings(false)
+	oldMasked, newMasked := c.settings(true), next.settings(true)
+
+	var changes []Change
+	for setting, value := range newValues {
+		if oldValues[setting] != value {
+			changes = append(changes, Change{Setting: setting, Old: oldMasked[setting], New: newMasked[setting]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Setting < changes[j].Setting })
+	return changes
+}
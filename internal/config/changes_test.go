@@ -0,0 +1,33 @@
+package config
+
+import "testing"
+
+func TestConfig_Changes(t *testing.T) {
+	current, err := LoadFrom(envFrom(map[string]string{"LOG_LEVEL": "info", "SMTP_PASS": "old-password"}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	next, err := LoadFrom(envFrom(map[string]string{"LOG_LEVEL": "debug", "SMTP_PASS": "new-password", "ALLOWED_EMAILS": "a@example.com"}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	changes := current.Changes(next)
+	want := []Change{
+		{Setting: "ALLOWED_EMAILS", Old: "", New: "a@example.com"},
+		{Setting: "LOG_LEVEL", Old: "info", New: "debug"},
+		{Setting: "SMTP_PASS", Old: maskedSecret, New: maskedSecret},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want[i], changes[i])
+		}
+	}
+
+	if changes := next.Changes(next); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v", changes)
+	}
+}
@@ -0,0 +1,920 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Modes accepted by WATERED_MODE
+const (
+	ModeProduction = "production"
+	ModeDemo       = "demo"
+)
+
+// ntfyTopicPattern matches the topic names ntfy accepts
+var ntfyTopicPattern = regexp.MustCompile(`^[-_A-Za-z0-9]{1,64}$`)
+
+// Config holds every setting the server reads from the environment
+type Config struct {
+	Server        ServerConfig
+	Auth          AuthConfig
+	Storage       StorageConfig
+	Notifications NotificationConfig
+	Push          PushConfig
+	SMTP          SMTPConfig
+	Slack         SlackConfig
+	Discord       DiscordConfig
+	Ntfy          NtfyConfig
+	Telegram      TelegramConfig
+	Sensors       SensorConfig
+	Weather       WeatherConfig
+	MQTT          MQTTConfig
+	Buttons       ButtonConfig
+	CSP           CSPConfig
+	Privacy       PrivacyConfig
+	Features      FeatureConfig
+	Update        UpdateConfig
+	Backup        BackupConfig
+	Tracing       TracingConfig
+	Escalation    EscalationConfig
+	RateLimit     RateLimitConfig
+	Demo          DemoConfig
+}
+
+// ServerConfig holds HTTP server and operational settings
+type ServerConfig struct {
+	Port                string // PORT
+	Profile             string // PROFILE, a bundled set of defaults
+	Environment         string // ENVIRONMENT
+	Mode                string // WATERED_MODE
+	Recovery            bool   // WATERED_RECOVERY
+	IntegrityAutoRepair bool   // INTEGRITY_AUTO_REPAIR
+	SmokeTestToken      string // SMOKE_TEST_TOKEN
+	CapacityWarnDays    int    // CAPACITY_WARN_DAYS, 0 disables the disk space warning
+	// LogLevel is the least severe level logged: debug, info, warn or error
+	LogLevel slog.Level // LOG_LEVEL
+	// ClockSkewTolerance is how far client clocks may drift before responses
+	// warn about it and client timestamps in the future are rejected
+	ClockSkewTolerance time.Duration // CLOCK_SKEW_TOLERANCE
+	// UndoWateringWindow is how long after recording a watering its
+	// waterer may undo it
+	UndoWateringWindow time.Duration // UNDO_WATERING_WINDOW
+	// HealthCacheTTL is how long /health/detailed serves a report before
+	// running the checks again, 0 runs them on every request
+	HealthCacheTTL time.Duration // HEALTH_CACHE_TTL
+	// HealthMetricsInterval is how often memory and runtime metrics are
+	// sampled for health checks, 0 reads them on every check
+	HealthMetricsInterval time.Duration // HEALTH_METRICS_INTERVAL
+	// HealthHistoryInterval is how often a health check is recorded for the
+	// admin uptime timeline, 0 disables recording
+	HealthHistoryInterval time.Duration // HEALTH_HISTORY_INTERVAL
+	// HealthHistoryRetention is how long recorded health checks are kept
+	HealthHistoryRetention time.Duration // HEALTH_HISTORY_RETENTION
+	ReadTimeout            time.Duration // HTTP_READ_TIMEOUT
+	WriteTimeout           time.Duration // HTTP_WRITE_TIMEOUT
+	IdleTimeout            time.Duration // HTTP_IDLE_TIMEOUT
+	// CompressionMinSize is the smallest JSON, HTML or other text response
+	// gzipped for clients that accept it, in bytes; below it the framing
+	// eats most of the saving. 0 disables compression.
+	CompressionMinSize int // COMPRESSION_MIN_SIZE
+	// PublicURL is the address users reach the server at, used for links in
+	// reminders. It defaults to the origin of REDIRECT_URL.
+	PublicURL string // PUBLIC_URL
+	// BadgeRequireToken only serves /badge.svg for plants shared with a
+	// share link, whose token must be passed as ?token=
+	BadgeRequireToken bool // BADGE_REQUIRE_TOKEN
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For and
+	// X-Real-IP headers are believed. Requests from anywhere else are keyed
+	// on their socket address, however they are forwarded.
+	TrustedProxies []netip.Prefix // TRUSTED_PROXIES
+}
+
+// AuthConfig holds Google OAuth, session and allowlist settings
+type AuthConfig struct {
+	GoogleClientID     string   // GOOGLE_CLIENT_ID
+	GoogleClientSecret string   // GOOGLE_CLIENT_SECRET
+	SessionSecret      string   // SESSION_SECRET
+	RedirectURL        string   // REDIRECT_URL
+	SecureCookies      bool     // SECURE_COOKIES, forced on in production
+	AllowedEmails      []string // ALLOWED_EMAILS
+	AdminEmails        []string // ADMIN_EMAILS
+	ViewerEmails       []string // VIEWER_EMAILS, allowed read-only
+	DemoMode           bool     // DEMO_MODE, or WATERED_MODE=demo
+	DemoUsers          []string // DEMO_USERS, offered on the demo login form
+	Environment        string   // ENVIRONMENT
+	// Bearer tokens issued to native apps by POST /auth/token
+	AccessTokenTTL  time.Duration // ACCESS_TOKEN_TTL
+	RefreshTokenTTL time.Duration // REFRESH_TOKEN_TTL
+}
+
+// StorageConfig selects the storage backend
+type StorageConfig struct {
+	DataFile    string // DATA_FILE
+	JournalFile string // JOURNAL_FILE, used when DATA_FILE is empty
+}
+
+// NotificationConfig holds reminder scheduling settings
+type NotificationConfig struct {
+	CheckInterval time.Duration // NOTIFICATION_CHECK_INTERVAL
+	DigestEnabled bool          // EMAIL_DIGEST
+	DigestHour    int           // EMAIL_DIGEST_HOUR
+	// The weekly report is sent at DigestHour on WeeklyReportDay
+	WeeklyReportEnabled bool         // EMAIL_WEEKLY_REPORT
+	WeeklyReportDay     time.Weekday // EMAIL_WEEKLY_REPORT_DAY
+	// SnoozeDuration is how long a reminder's snooze link silences reminders
+	// about that plant for the user who clicked it
+	SnoozeDuration time.Duration // SNOOZE_DURATION
+}
+
+// PushConfig holds the Web Push VAPID keys
+type PushConfig struct {
+	VAPIDPublicKey  string // VAPID_PUBLIC_KEY
+	VAPIDPrivateKey string // VAPID_PRIVATE_KEY
+	VAPIDSubject    string // VAPID_SUBJECT
+}
+
+// Enabled reports whether VAPID keys are configured
+func (c PushConfig) Enabled() bool {
+	return c.VAPIDPublicKey != "" || c.VAPIDPrivateKey != ""
+}
+
+// SMTPConfig holds the SMTP server settings for email reminders
+type SMTPConfig struct {
+	Host     string // SMTP_HOST
+	Port     int    // SMTP_PORT
+	Username string // SMTP_USER
+	Password string // SMTP_PASS
+	From     string // SMTP_FROM, defaults to SMTP_USER
+}
+
+// Enabled reports whether an SMTP server is configured
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != ""
+}
+
+// SlackConfig holds the Slack incoming webhook and its message templates.
+// Empty templates use the built-in messages.
+type SlackConfig struct {
+	WebhookURL      string // SLACK_WEBHOOK_URL
+	OverdueTemplate string // SLACK_OVERDUE_TEMPLATE
+	WateredTemplate string // SLACK_WATERED_TEMPLATE
+}
+
+// Enabled reports whether a Slack webhook is configured
+func (c SlackConfig) Enabled() bool {
+	return c.WebhookURL != ""
+}
+
+// DiscordConfig holds the Discord webhook
+type DiscordConfig struct {
+	WebhookURL string // DISCORD_WEBHOOK_URL
+}
+
+// Enabled reports whether a Discord webhook is configured
+func (c DiscordConfig) Enabled() bool {
+	return c.WebhookURL != ""
+}
+
+// NtfyConfig holds the ntfy server and topic that reminders are published
+// to. On a public server anyone who knows the topic can subscribe to it, so
+// it should be hard to guess.
+type NtfyConfig struct {
+	ServerURL string // NTFY_SERVER_URL
+	Topic     string // NTFY_TOPIC
+	Token     string // NTFY_TOKEN, an access token for protected topics
+}
+
+// Enabled reports whether an ntfy topic is configured
+func (c NtfyConfig) Enabled() bool {
+	return c.Topic != ""
+}
+
+// CSPConfig holds Content-Security-Policy settings
+type CSPConfig struct {
+	Disabled   bool   // CSP_DISABLED
+	ReportOnly bool   // CSP_REPORT_ONLY
+	ReportURI  string // CSP_REPORT_URI
+}
+
+// PrivacyConfig holds analytics anonymization settings
+type PrivacyConfig struct {
+	AnonymizeAnalytics bool   // ANONYMIZE_ANALYTICS
+	AnonymizationSalt  string // ANONYMIZATION_SALT
+}
+
+// FeatureConfig holds whether each feature flag is on when no admin has
+// switched it on or off; see the features package
+type FeatureConfig struct {
+	Notifications bool // FEATURE_NOTIFICATIONS, reminders and watering notifications
+	Sensors       bool // FEATURE_SENSORS, soil sensor readings over HTTP and MQTT
+	MultiPlant    bool // FEATURE_MULTI_PLANT, adding plants beyond the first
+}
+
+// UpdateConfig holds self-update settings
+type UpdateConfig struct {
+	PublicKey     string        // UPDATE_PUBLIC_KEY, Ed25519 key that signs release binaries
+	Repository    string        // UPDATE_REPOSITORY, GitHub owner/name to check for releases
+	CheckInterval time.Duration // UPDATE_CHECK_INTERVAL, 0 disables automatic updates
+}
+
+// Enabled reports whether a release signing key is configured
+func (c UpdateConfig) Enabled() bool {
+	return c.PublicKey != ""
+}
+
+// RateLimitConfig holds per-client request limits for /api and /admin, and
+// the stricter token buckets for sign-in and write requests
+type RateLimitConfig struct {
+	Limit  int           // RATE_LIMIT, requests per window before 429s, 0 disables limiting
+	Warn   int           // RATE_LIMIT_WARN, requests per window before clients are warned
+	Window time.Duration // RATE_LIMIT_WINDOW
+
+	Auth  BucketConfig // RATE_LIMIT_AUTH_BURST, RATE_LIMIT_AUTH_REFILL for /auth
+	Write BucketConfig // RATE_LIMIT_WRITE_BURST, RATE_LIMIT_WRITE_REFILL for writes to /api and /admin
+
+	Lockout LockoutConfig // AUTH_LOCKOUT_* for failed sign-ins
+}
+
+// Enabled reports whether requests are rate limited
+func (c RateLimitConfig) Enabled() bool {
+	return c.Limit > 0
+}
+
+// BucketConfig describes a token bucket: a client may send Burst requests at
+// once and earns another every Refill
+type BucketConfig struct {
+	Burst  int // 0 disables the bucket
+	Refill time.Duration
+}
+
+// Enabled reports whether the bucket limits requests
+func (c BucketConfig) Enabled() bool {
+	return c.Burst > 0
+}
+
+// LockoutConfig describes how failed sign-ins lock a client address or email
+// out: after Threshold failures each further one locks it out for Base,
+// doubling with every failure up to Max
+type LockoutConfig struct {
+	Threshold int           // AUTH_LOCKOUT_THRESHOLD, 0 disables lockouts
+	Base      time.Duration // AUTH_LOCKOUT_BASE
+	Max       time.Duration // AUTH_LOCKOUT_MAX
+}
+
+// Enabled reports whether failed sign-ins lead to lockouts
+func (c LockoutConfig) Enabled() bool {
+	return c.Threshold > 0
+}
+
+// Default returns the configuration used when no environment variables are set
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:                   "8080",
+			Mode:                   ModeProduction,
+			CapacityWarnDays:       30,
+			LogLevel:               slog.LevelInfo,
+			ClockSkewTolerance:     time.Minute,
+			UndoWateringWindow:     10 * time.Minute,
+			HealthCacheTTL:         5 * time.Second,
+			HealthMetricsInterval:  15 * time.Second,
+			HealthHistoryInterval:  5 * time.Minute,
+			HealthHistoryRetention: 7 * 24 * time.Hour,
+			ReadTimeout:            15 * time.Second,
+			WriteTimeout:           15 * time.Second,
+			IdleTimeout:            60 * time.Second,
+			CompressionMinSize:     256,
+		},
+		Auth: AuthConfig{
+			RedirectURL:     "http://localhost:8080/auth/callback",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+		Notifications: NotificationConfig{
+			CheckInterval:   5 * time.Minute,
+			DigestHour:      8,
+			WeeklyReportDay: time.Monday,
+			SnoozeDuration:  3 * time.Hour,
+		},
+		SMTP: SMTPConfig{
+			Port: 587,
+		},
+		Ntfy: NtfyConfig{
+			ServerURL: "https://ntfy.sh",
+		},
+		Sensors: SensorConfig{
+			WateringCooldown: 6 * time.Hour,
+		},
+		Weather: WeatherConfig{
+			RefreshInterval: 3 * time.Hour,
+			APIURL:          "https://api.open-meteo.com",
+		},
+		MQTT: MQTTConfig{
+			Topic:    "watered/sensors/+",
+			ClientID: "watered",
+		},
+		Buttons: ButtonConfig{
+			Debounce: 10 * time.Minute,
+		},
+		Features: FeatureConfig{
+			Notifications: true,
+			Sensors:       true,
+			MultiPlant:    true,
+		},
+		Update: UpdateConfig{
+			Repository: "JohnFodero/watered",
+		},
+		Backup: BackupConfig{
+			Endpoint: "https://storage.googleapis.com",
+			Region:   "auto",
+			Prefix:   "watered/",
+			Interval: 24 * time.Hour,
+			Retain:   7,
+		},
+		Tracing: TracingConfig{
+			ServiceName: "watered",
+			SampleRatio: 1,
+		},
+		RateLimit: RateLimitConfig{
+			Limit:   120,
+			Warn:    60,
+			Window:  time.Minute,
+			Auth:    BucketConfig{Burst: 10, Refill: 6 * time.Second},
+			Write:   BucketConfig{Burst: 30, Refill: time.Second},
+			Lockout: LockoutConfig{Threshold: 5, Base: time.Minute, Max: time.Hour},
+		},
+		Demo: DemoConfig{
+			ResetInterval: time.Hour,
+		},
+	}
+}
+
+// Load reads the configuration from the process environment
+func Load() (*Config, error) {
+	return LoadFrom(os.Getenv)
+}
+
+// LoadFrom reads the configuration using getenv. It always returns a usable
+// Config, with defaults in place of invalid values; the error lists every
+// invalid setting. When PROFILE names a bundled profile, its settings apply
+// to every variable getenv leaves empty.
+func LoadFrom(getenv func(string) string) (*Config, error) {
+	l := &loader{getenv: getenv}
+	c := Default()
+
+	if name := getenv("PROFILE"); name != "" {
+		settings, err := Profile(name)
+		if err != nil {
+			l.problems = append(l.problems, err.Error())
+		} else {
+			c.Server.Profile = name
+			l.getenv = withProfile(getenv, settings)
+			getenv = l.getenv
+		}
+	}
+
+	c.Server.Port = l.string("PORT", c.Server.Port)
+	c.Server.Environment = getenv("ENVIRONMENT")
+	c.Server.Mode = l.string("WATERED_MODE", c.Server.Mode)
+	c.Server.Recovery = l.bool("WATERED_RECOVERY")
+	c.Server.IntegrityAutoRepair = l.bool("INTEGRITY_AUTO_REPAIR")
+	c.Server.SmokeTestToken = getenv("SMOKE_TEST_TOKEN")
+	c.Server.CapacityWarnDays = l.int("CAPACITY_WARN_DAYS", c.Server.CapacityWarnDays)
+	c.Server.LogLevel = l.level("LOG_LEVEL", c.Server.LogLevel)
+	c.Server.ClockSkewTolerance = l.duration("CLOCK_SKEW_TOLERANCE", c.Server.ClockSkewTolerance)
+	c.Server.UndoWateringWindow = l.duration("UNDO_WATERING_WINDOW", c.Server.UndoWateringWindow)
+	c.Server.HealthCacheTTL = l.duration("HEALTH_CACHE_TTL", c.Server.HealthCacheTTL)
+	c.Server.HealthMetricsInterval = l.duration("HEALTH_METRICS_INTERVAL", c.Server.HealthMetricsInterval)
+	c.Server.HealthHistoryInterval = l.duration("HEALTH_HISTORY_INTERVAL", c.Server.HealthHistoryInterval)
+	c.Server.HealthHistoryRetention = l.duration("HEALTH_HISTORY_RETENTION", c.Server.HealthHistoryRetention)
+	c.Server.ReadTimeout = l.duration("HTTP_READ_TIMEOUT", c.Server.ReadTimeout)
+	c.Server.WriteTimeout = l.duration("HTTP_WRITE_TIMEOUT", c.Server.WriteTimeout)
+	c.Server.IdleTimeout = l.duration("HTTP_IDLE_TIMEOUT", c.Server.IdleTimeout)
+	c.Server.CompressionMinSize = l.int("COMPRESSION_MIN_SIZE", c.Server.CompressionMinSize)
+
+	c.Auth.GoogleClientID = getenv("GOOGLE_CLIENT_ID")
+	c.Auth.GoogleClientSecret = getenv("GOOGLE_CLIENT_SECRET")
+	c.Auth.SessionSecret = getenv("SESSION_SECRET")
+	c.Auth.RedirectURL = l.string("REDIRECT_URL", c.Auth.RedirectURL)
+	c.Auth.SecureCookies = l.bool("SECURE_COOKIES") || c.IsProduction()
+	c.Auth.AllowedEmails = l.emails("ALLOWED_EMAILS")
+	c.Auth.AdminEmails = l.emails("ADMIN_EMAILS")
+	c.Auth.ViewerEmails = l.emails("VIEWER_EMAILS")
+	// The sandbox never holds real data, so it always offers demo login
+	c.Auth.DemoMode = l.bool("DEMO_MODE") || c.Server.Mode == ModeDemo
+	c.Auth.DemoUsers = l.emails("DEMO_USERS")
+	c.Auth.Environment = c.Server.Environment
+	c.Auth.AccessTokenTTL = l.duration("ACCESS_TOKEN_TTL", c.Auth.AccessTokenTTL)
+	c.Auth.RefreshTokenTTL = l.duration("REFRESH_TOKEN_TTL", c.Auth.RefreshTokenTTL)
+	c.Server.PublicURL = strings.TrimSuffix(l.string("PUBLIC_URL", origin(c.Auth.RedirectURL)), "/")
+	c.Server.BadgeRequireToken = l.bool("BADGE_REQUIRE_TOKEN")
+	c.Server.TrustedProxies = l.prefixes("TRUSTED_PROXIES")
+
+	c.Storage.DataFile = getenv("DATA_FILE")
+	c.Storage.JournalFile = getenv("JOURNAL_FILE")
+
+	c.Notifications.CheckInterval = l.duration("NOTIFICATION_CHECK_INTERVAL", c.Notifications.CheckInterval)
+	c.Notifications.DigestEnabled = l.bool("EMAIL_DIGEST")
+	c.Notifications.DigestHour = l.int("EMAIL_DIGEST_HOUR", c.Notifications.DigestHour)
+	c.Notifications.WeeklyReportEnabled = l.bool("EMAIL_WEEKLY_REPORT")
+	c.Notifications.WeeklyReportDay = l.weekday("EMAIL_WEEKLY_REPORT_DAY", c.Notifications.WeeklyReportDay)
+	c.Notifications.SnoozeDuration = l.duration("SNOOZE_DURATION", c.Notifications.SnoozeDuration)
+
+	c.Push.VAPIDPublicKey = getenv("VAPID_PUBLIC_KEY")
+	c.Push.VAPIDPrivateKey = getenv("VAPID_PRIVATE_KEY")
+	c.Push.VAPIDSubject = getenv("VAPID_SUBJECT")
+
+	c.SMTP.Host = getenv("SMTP_HOST")
+	c.SMTP.Port = l.int("SMTP_PORT", c.SMTP.Port)
+	c.SMTP.Username = getenv("SMTP_USER")
+	c.SMTP.Password = getenv("SMTP_PASS")
+	c.SMTP.From = l.string("SMTP_FROM", c.SMTP.Username)
+
+	c.Slack.WebhookURL = getenv("SLACK_WEBHOOK_URL")
+	c.Slack.OverdueTemplate = getenv("SLACK_OVERDUE_TEMPLATE")
+	c.Slack.WateredTemplate = getenv("SLACK_WATERED_TEMPLATE")
+	c.Discord.WebhookURL = getenv("DISCORD_WEBHOOK_URL")
+	c.Ntfy.ServerURL = l.string("NTFY_SERVER_URL", c.Ntfy.ServerURL)
+	c.Ntfy.Topic = getenv("NTFY_TOPIC")
+	c.Ntfy.Token = getenv("NTFY_TOKEN")
+
+	c.Telegram.BotToken = getenv("TELEGRAM_BOT_TOKEN")
+	c.Telegram.WebhookSecret = getenv("TELEGRAM_WEBHOOK_SECRET")
+	if chatID := getenv("TELEGRAM_CHAT_ID"); chatID != "" {
+		parsed, err := strconv.ParseInt(chatID, 10, 64)
+		if err != nil {
+			l.problems = append(l.problems, fmt.Sprintf("TELEGRAM_CHAT_ID must be a whole number, got %q", chatID))
+		}
+		c.Telegram.ChatID = parsed
+	}
+	if users := getenv("TELEGRAM_USERS"); users != "" {
+		parsed, err := ParseTelegramUsers(users)
+		if err != nil {
+			l.problems = append(l.problems, fmt.Sprintf("TELEGRAM_USERS: %v", err))
+		}
+		c.Telegram.Users = parsed
+	}
+
+	if devices := getenv("SENSOR_DEVICES"); devices != "" {
+		parsed, err := ParseSensorDevices(devices)
+		if err != nil {
+			l.problems = append(l.problems, fmt.Sprintf("SENSOR_DEVICES: %v", err))
+		}
+		c.Sensors.Devices = parsed
+	}
+	c.Sensors.WateringRise = l.int("SENSOR_WATERING_RISE", c.Sensors.WateringRise)
+	c.Sensors.WateringCooldown = l.duration("SENSOR_WATERING_COOLDOWN", c.Sensors.WateringCooldown)
+	if (getenv("WEATHER_LATITUDE") == "") != (getenv("WEATHER_LONGITUDE") == "") {
+		l.problems = append(l.problems, "WEATHER_LATITUDE and WEATHER_LONGITUDE must be set together")
+	}
+	c.Weather.Latitude = l.coordinate("WEATHER_LATITUDE", 90)
+	c.Weather.Longitude = l.coordinate("WEATHER_LONGITUDE", 180)
+	c.Weather.RefreshInterval = l.duration("WEATHER_REFRESH_INTERVAL", c.Weather.RefreshInterval)
+	c.Weather.APIURL = l.string("WEATHER_API_URL", c.Weather.APIURL)
+	c.MQTT.BrokerURL = getenv("MQTT_BROKER_URL")
+	c.MQTT.Topic = l.string("MQTT_TOPIC", c.MQTT.Topic)
+	c.MQTT.Username = getenv("MQTT_USERNAME")
+	c.MQTT.Password = getenv("MQTT_PASSWORD")
+	c.MQTT.ClientID = l.string("MQTT_CLIENT_ID", c.MQTT.ClientID)
+	c.Buttons.Debounce = l.duration("BUTTON_DEBOUNCE", c.Buttons.Debounce)
+
+	c.CSP.Disabled = l.bool("CSP_DISABLED")
+	c.CSP.ReportOnly = l.bool("CSP_REPORT_ONLY")
+	c.CSP.ReportURI = getenv("CSP_REPORT_URI")
+
+	c.Privacy.AnonymizeAnalytics = l.bool("ANONYMIZE_ANALYTICS")
+	c.Privacy.AnonymizationSalt = getenv("ANONYMIZATION_SALT")
+
+	c.Features.Notifications = l.flag("FEATURE_NOTIFICATIONS", c.Features.Notifications)
+	c.Features.Sensors = l.flag("FEATURE_SENSORS", c.Features.Sensors)
+	c.Features.MultiPlant = l.flag("FEATURE_MULTI_PLANT", c.Features.MultiPlant)
+
+	c.Update.PublicKey = getenv("UPDATE_PUBLIC_KEY")
+	c.Update.Repository = l.string("UPDATE_REPOSITORY", c.Update.Repository)
+	c.Update.CheckInterval = l.duration("UPDATE_CHECK_INTERVAL", c.Update.CheckInterval)
+
+	c.Backup.Bucket = getenv("BACKUP_BUCKET")
+	c.Backup.Endpoint = strings.TrimSuffix(l.string("BACKUP_ENDPOINT", c.Backup.Endpoint), "/")
+	c.Backup.Region = l.string("BACKUP_REGION", c.Backup.Region)
+	c.Backup.AccessKey = getenv("BACKUP_ACCESS_KEY")
+	c.Backup.SecretKey = getenv("BACKUP_SECRET_KEY")
+	c.Backup.Prefix = l.string("BACKUP_PREFIX", c.Backup.Prefix)
+	c.Backup.Interval = l.duration("BACKUP_INTERVAL", c.Backup.Interval)
+	c.Backup.Retain = l.int("BACKUP_RETAIN", c.Backup.Retain)
+
+	c.Tracing.Endpoint = strings.TrimSuffix(getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "/")
+	c.Tracing.Headers = l.headers("OTEL_EXPORTER_OTLP_HEADERS")
+	c.Tracing.ServiceName = l.string("OTEL_SERVICE_NAME", c.Tracing.ServiceName)
+	c.Tracing.SampleRatio = l.ratio("OTEL_TRACES_SAMPLER_ARG", c.Tracing.SampleRatio)
+
+	c.RateLimit.Limit = l.int("RATE_LIMIT", c.RateLimit.Limit)
+	c.RateLimit.Warn = l.int("RATE_LIMIT_WARN", c.RateLimit.Warn)
+	c.RateLimit.Window = l.duration("RATE_LIMIT_WINDOW", c.RateLimit.Window)
+	c.RateLimit.Auth.Burst = l.int("RATE_LIMIT_AUTH_BURST", c.RateLimit.Auth.Burst)
+	c.RateLimit.Auth.Refill = l.duration("RATE_LIMIT_AUTH_REFILL", c.RateLimit.Auth.Refill)
+	c.RateLimit.Write.Burst = l.int("RATE_LIMIT_WRITE_BURST", c.RateLimit.Write.Burst)
+	c.RateLimit.Write.Refill = l.duration("RATE_LIMIT_WRITE_REFILL", c.RateLimit.Write.Refill)
+	c.RateLimit.Lockout.Threshold = l.int("AUTH_LOCKOUT_THRESHOLD", c.RateLimit.Lockout.Threshold)
+	c.RateLimit.Lockout.Base = l.duration("AUTH_LOCKOUT_BASE", c.RateLimit.Lockout.Base)
+	c.RateLimit.Lockout.Max = l.duration("AUTH_LOCKOUT_MAX", c.RateLimit.Lockout.Max)
+
+	if chain := getenv("ESCALATION_CHAIN"); chain != "" {
+		steps, err := ParseEscalationChain(chain)
+		if err != nil {
+			l.problems = append(l.problems, fmt.Sprintf("ESCALATION_CHAIN: %v", err))
+		}
+		c.Escalation.Steps = steps
+	}
+	if hours := getenv("ESCALATION_WORKING_HOURS"); hours != "" {
+		workingHours, err := ParseWorkingHours(hours)
+		if err != nil {
+			l.problems = append(l.problems, fmt.Sprintf("ESCALATION_WORKING_HOURS: %v", err))
+		}
+		c.Escalation.WorkingHours = workingHours
+	}
+
+	c.Demo.ResetInterval = l.duration("DEMO_RESET_INTERVAL", c.Demo.ResetInterval)
+	if c.IsDemoMode() {
+		c.sandbox()
+	}
+
+	l.problems = append(l.problems, c.validate()...)
+	if len(l.problems) > 0 {
+		return c, fmt.Errorf("invalid configuration:\n  %s", strings.Join(l.problems, "\n  "))
+	}
+	return c, nil
+}
+
+// IsDemoMode reports whether WATERED_MODE=demo
+func (c *Config) IsDemoMode() bool {
+	return c.Server.Mode == ModeDemo
+}
+
+// IsProduction reports whether ENVIRONMENT names a production deployment
+func (c *Config) IsProduction() bool {
+	return c.Server.Environment == "production" || c.Server.Environment == "prod"
+}
+
+// validate checks values that parsed but are out of range or inconsistent
+func (c *Config) validate() []string {
+	var problems []string
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port <= 0 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("PORT must be a number between 1 and 65535, got %q", c.Server.Port))
+	}
+	if c.Server.Mode != ModeProduction && c.Server.Mode != ModeDemo {
+		problems = append(problems, fmt.Sprintf("WATERED_MODE must be %q or %q, got %q", ModeProduction, ModeDemo, c.Server.Mode))
+	}
+	if c.Server.CapacityWarnDays < 0 {
+		problems = append(problems, fmt.Sprintf("CAPACITY_WARN_DAYS must not be negative, got %d", c.Server.CapacityWarnDays))
+	}
+	if c.Server.ClockSkewTolerance <= 0 {
+		problems = append(problems, fmt.Sprintf("CLOCK_SKEW_TOLERANCE must be positive, got %s", c.Server.ClockSkewTolerance))
+	}
+	if c.Server.UndoWateringWindow <= 0 {
+		problems = append(problems, fmt.Sprintf("UNDO_WATERING_WINDOW must be positive, got %s", c.Server.UndoWateringWindow))
+	}
+	if c.Server.HealthCacheTTL < 0 {
+		problems = append(problems, fmt.Sprintf("HEALTH_CACHE_TTL must not be negative, got %s", c.Server.HealthCacheTTL))
+	}
+	if c.Server.HealthMetricsInterval < 0 {
+		problems = append(problems, fmt.Sprintf("HEALTH_METRICS_INTERVAL must not be negative, got %s", c.Server.HealthMetricsInterval))
+	}
+	if c.Server.HealthHistoryInterval < 0 {
+		problems = append(problems, fmt.Sprintf("HEALTH_HISTORY_INTERVAL must not be negative, got %s", c.Server.HealthHistoryInterval))
+	}
+	if c.Server.HealthHistoryRetention <= 0 {
+		problems = append(problems, fmt.Sprintf("HEALTH_HISTORY_RETENTION must be positive, got %s", c.Server.HealthHistoryRetention))
+	}
+	if c.Server.ReadTimeout <= 0 {
+		problems = append(problems, fmt.Sprintf("HTTP_READ_TIMEOUT must be positive, got %s", c.Server.ReadTimeout))
+	}
+	if c.Server.WriteTimeout <= 0 {
+		problems = append(problems, fmt.Sprintf("HTTP_WRITE_TIMEOUT must be positive, got %s", c.Server.WriteTimeout))
+	}
+	if c.Server.IdleTimeout <= 0 {
+		problems = append(problems, fmt.Sprintf("HTTP_IDLE_TIMEOUT must be positive, got %s", c.Server.IdleTimeout))
+	}
+	if c.Server.CompressionMinSize < 0 {
+		problems = append(problems, fmt.Sprintf("COMPRESSION_MIN_SIZE must not be negative, got %d", c.Server.CompressionMinSize))
+	}
+	if public, err := url.Parse(c.Server.PublicURL); err != nil || (public.Scheme != "https" && public.Scheme != "http") || public.Host == "" {
+		problems = append(problems, fmt.Sprintf("PUBLIC_URL must be an http or https URL, got %q", c.Server.PublicURL))
+	}
+	if c.Auth.DemoMode && !c.IsDemoMode() && c.IsProduction() {
+		problems = append(problems, "DEMO_MODE must not be enabled when ENVIRONMENT is production")
+	}
+	if (c.Auth.GoogleClientID == "") != (c.Auth.GoogleClientSecret == "") {
+		problems = append(problems, "GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET must be set together")
+	}
+	if c.Auth.AccessTokenTTL <= 0 || c.Auth.RefreshTokenTTL < c.Auth.AccessTokenTTL {
+		problems = append(problems, fmt.Sprintf("ACCESS_TOKEN_TTL must be positive and at most REFRESH_TOKEN_TTL, got %s and %s", c.Auth.AccessTokenTTL, c.Auth.RefreshTokenTTL))
+	}
+
+	if c.Notifications.CheckInterval <= 0 {
+		problems = append(problems, fmt.Sprintf("NOTIFICATION_CHECK_INTERVAL must be positive, got %s", c.Notifications.CheckInterval))
+	}
+	if c.Notifications.DigestHour < 0 || c.Notifications.DigestHour > 23 {
+		problems = append(problems, fmt.Sprintf("EMAIL_DIGEST_HOUR must be between 0 and 23, got %d", c.Notifications.DigestHour))
+	}
+	if c.Notifications.SnoozeDuration <= 0 {
+		problems = append(problems, fmt.Sprintf("SNOOZE_DURATION must be positive, got %s", c.Notifications.SnoozeDuration))
+	}
+
+	if c.Push.Enabled() {
+		if c.Push.VAPIDPublicKey == "" || c.Push.VAPIDPrivateKey == "" {
+			problems = append(problems, "VAPID_PUBLIC_KEY and VAPID_PRIVATE_KEY must be set together")
+		}
+		if c.Push.VAPIDSubject == "" {
+			problems = append(problems, "VAPID_SUBJECT is required when VAPID keys are set")
+		}
+	}
+
+	if c.SMTP.Enabled() {
+		if c.SMTP.Port <= 0 || c.SMTP.Port > 65535 {
+			problems = append(problems, fmt.Sprintf("SMTP_PORT must be between 1 and 65535, got %d", c.SMTP.Port))
+		}
+		if _, err := mail.ParseAddress(c.SMTP.From); err != nil {
+			problems = append(problems, fmt.Sprintf("SMTP_FROM (or SMTP_USER) must be a valid email address, got %q", c.SMTP.From))
+		}
+	}
+	if c.Notifications.DigestEnabled && !c.SMTP.Enabled() {
+		problems = append(problems, "EMAIL_DIGEST requires SMTP_HOST")
+	}
+	if c.Notifications.WeeklyReportEnabled && !c.SMTP.Enabled() {
+		problems = append(problems, "EMAIL_WEEKLY_REPORT requires SMTP_HOST")
+	}
+
+	if c.Slack.Enabled() {
+		if webhook, err := url.Parse(c.Slack.WebhookURL); err != nil || (webhook.Scheme != "https" && webhook.Scheme != "http") || webhook.Host == "" {
+			problems = append(problems, "SLACK_WEBHOOK_URL must be an http or https URL")
+		}
+	}
+	if _, err := template.New("overdue").Parse(c.Slack.OverdueTemplate); err != nil {
+		problems = append(problems, fmt.Sprintf("SLACK_OVERDUE_TEMPLATE is not a valid template: %v", err))
+	}
+	if _, err := template.New("watered").Parse(c.Slack.WateredTemplate); err != nil {
+		problems = append(problems, fmt.Sprintf("SLACK_WATERED_TEMPLATE is not a valid template: %v", err))
+	}
+	if c.Discord.Enabled() {
+		if webhook, err := url.Parse(c.Discord.WebhookURL); err != nil || (webhook.Scheme != "https" && webhook.Scheme != "http") || webhook.Host == "" {
+			problems = append(problems, "DISCORD_WEBHOOK_URL must be an http or https URL")
+		}
+	}
+	if c.Ntfy.Enabled() {
+		if server, err := url.Parse(c.Ntfy.ServerURL); err != nil || (server.Scheme != "https" && server.Scheme != "http") || server.Host == "" {
+			problems = append(problems, fmt.Sprintf("NTFY_SERVER_URL must be an http or https URL, got %q", c.Ntfy.ServerURL))
+		}
+		if !ntfyTopicPattern.MatchString(c.Ntfy.Topic) {
+			problems = append(problems, "NTFY_TOPIC must be 1 to 64 letters, digits, dashes or underscores")
+		}
+	}
+
+	if c.Telegram.Enabled() {
+		if c.Telegram.ChatID == 0 {
+			problems = append(problems, "TELEGRAM_BOT_TOKEN requires TELEGRAM_CHAT_ID")
+		}
+		if c.Telegram.WebhookSecret == "" {
+			problems = append(problems, "TELEGRAM_BOT_TOKEN requires TELEGRAM_WEBHOOK_SECRET")
+		}
+	}
+
+	if c.Sensors.WateringRise < 0 || c.Sensors.WateringRise > 100 {
+		problems = append(problems, fmt.Sprintf("SENSOR_WATERING_RISE must be between 0 and 100, got %d", c.Sensors.WateringRise))
+	}
+	if c.Sensors.WateringCooldown <= 0 {
+		problems = append(problems, fmt.Sprintf("SENSOR_WATERING_COOLDOWN must be positive, got %s", c.Sensors.WateringCooldown))
+	}
+	if c.Weather.Enabled() {
+		problems = append(problems, c.Weather.validate()...)
+	}
+	if c.MQTT.Enabled() {
+		problems = append(problems, c.MQTT.validate()...)
+	}
+	if c.Buttons.Debounce <= 0 {
+		problems = append(problems, fmt.Sprintf("BUTTON_DEBOUNCE must be positive, got %s", c.Buttons.Debounce))
+	}
+
+	if c.Update.CheckInterval < 0 {
+		problems = append(problems, fmt.Sprintf("UPDATE_CHECK_INTERVAL must not be negative, got %s", c.Update.CheckInterval))
+	}
+	if c.Update.CheckInterval > 0 && !c.Update.Enabled() {
+		problems = append(problems, "UPDATE_CHECK_INTERVAL requires UPDATE_PUBLIC_KEY")
+	}
+	if owner, name, ok := strings.Cut(c.Update.Repository, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		problems = append(problems, fmt.Sprintf("UPDATE_REPOSITORY must look like owner/name, got %q", c.Update.Repository))
+	}
+
+	if c.Backup.Enabled() {
+		problems = append(problems, c.Backup.validate()...)
+	}
+	if c.Tracing.Enabled() {
+		problems = append(problems, c.Tracing.validate()...)
+	}
+
+	if c.RateLimit.Limit < 0 {
+		problems = append(problems, fmt.Sprintf("RATE_LIMIT must not be negative, got %d", c.RateLimit.Limit))
+	}
+	if c.RateLimit.Enabled() {
+		if c.RateLimit.Warn <= 0 || c.RateLimit.Warn > c.RateLimit.Limit {
+			problems = append(problems, fmt.Sprintf("RATE_LIMIT_WARN must be between 1 and RATE_LIMIT (%d), got %d", c.RateLimit.Limit, c.RateLimit.Warn))
+		}
+		if c.RateLimit.Window <= 0 {
+			problems = append(problems, fmt.Sprintf("RATE_LIMIT_WINDOW must be positive, got %s", c.RateLimit.Window))
+		}
+	}
+	buckets := []struct {
+		name   string
+		bucket BucketConfig
+	}{{"RATE_LIMIT_AUTH", c.RateLimit.Auth}, {"RATE_LIMIT_WRITE", c.RateLimit.Write}}
+	for _, b := range buckets {
+		if b.bucket.Burst < 0 {
+			problems = append(problems, fmt.Sprintf("%s_BURST must not be negative, got %d", b.name, b.bucket.Burst))
+		}
+		if b.bucket.Enabled() && b.bucket.Refill <= 0 {
+			problems = append(problems, fmt.Sprintf("%s_REFILL must be positive, got %s", b.name, b.bucket.Refill))
+		}
+	}
+	if lockout := c.RateLimit.Lockout; lockout.Threshold < 0 {
+		problems = append(problems, fmt.Sprintf("AUTH_LOCKOUT_THRESHOLD must not be negative, got %d", lockout.Threshold))
+	} else if lockout.Enabled() && (lockout.Base <= 0 || lockout.Max < lockout.Base) {
+		problems = append(problems, fmt.Sprintf("AUTH_LOCKOUT_BASE must be positive and at most AUTH_LOCKOUT_MAX, got %s and %s", lockout.Base, lockout.Max))
+	}
+
+	for _, step := range c.Escalation.Steps {
+		if step.Channel == EscalationEmail && !c.SMTP.Enabled() {
+			problems = append(problems, fmt.Sprintf("ESCALATION_CHAIN step %s requires SMTP_HOST", step))
+		}
+		if step.Channel == EscalationPush && !c.Push.Enabled() {
+			problems = append(problems, fmt.Sprintf("ESCALATION_CHAIN step %s requires VAPID keys", step))
+		}
+	}
+	if c.Escalation.WorkingHours != nil && !c.Escalation.Enabled() {
+		problems = append(problems, "ESCALATION_WORKING_HOURS requires ESCALATION_CHAIN")
+	}
+
+	if c.Demo.ResetInterval < 0 {
+		problems = append(problems, fmt.Sprintf("DEMO_RESET_INTERVAL must not be negative, got %s", c.Demo.ResetInterval))
+	}
+
+	for _, email := range append(append(append([]string{}, c.Auth.AllowedEmails...), c.Auth.AdminEmails...), c.Auth.ViewerEmails...) {
+		if _, err := mail.ParseAddress(email); err != nil {
+			problems = append(problems, fmt.Sprintf("%q in ALLOWED_EMAILS, ADMIN_EMAILS or VIEWER_EMAILS is not a valid email address", email))
+		}
+	}
+	for _, email := range c.Auth.DemoUsers {
+		if _, err := mail.ParseAddress(email); err != nil {
+			problems = append(problems, fmt.Sprintf("%q in DEMO_USERS is not a valid email address", email))
+		}
+	}
+	for _, email := range c.Auth.ViewerEmails {
+		if slices.Contains(c.Auth.AdminEmails, email) {
+			problems = append(problems, fmt.Sprintf("%q is in both ADMIN_EMAILS and VIEWER_EMAILS", email))
+		}
+	}
+
+	return problems
+}
+
+// loader parses typed values and collects the problems it finds
+type loader struct {
+	getenv   func(string) string
+	problems []string
+}
+
+func (l *loader) string(key, fallback string) string {
+	if value := l.getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func (l *loader) bool(key string) bool {
+	value := l.getenv(key)
+	if value == "" {
+		return false
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%s must be true or false, got %q", key, value))
+		return false
+	}
+	return parsed
+}
+
+// flag parses a boolean that is on unless set otherwise
+func (l *loader) flag(key string, fallback bool) bool {
+	if l.getenv(key) == "" {
+		return fallback
+	}
+	return l.bool(key)
+}
+
+func (l *loader) int(key string, fallback int) int {
+	value := l.getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%s must be a whole number, got %q", key, value))
+		return fallback
+	}
+	return parsed
+}
+
+func (l *loader) duration(key string, fallback time.Duration) time.Duration {
+	value := l.getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%s must be a duration such as 5m or 1h, got %q", key, value))
+		return fallback
+	}
+	return parsed
+}
+
+func (l *loader) level(key string, fallback slog.Level) slog.Level {
+	value := l.getenv(key)
+	if value == "" {
+		return fallback
+	}
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(value)); err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%s must be debug, info, warn or error, got %q", key, value))
+		return fallback
+	}
+	return parsed
+}
+
+// weekday parses a day name such as monday or Mon
+func (l *loader) weekday(key string, fallback time.Weekday) time.Weekday {
+	value := strings.ToLower(strings.TrimSpace(l.getenv(key)))
+	if value == "" {
+		return fallback
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if value == name || value == name[:3] {
+			return day
+		}
+	}
+	l.problems = append(l.problems, fmt.Sprintf("%s must be a day of the week such as monday, got %q", key, value))
+	return fallback
+}
+
+// emails parses a comma-separated email list, dropping empty entries
+func (l *loader) emails(key string) []string {
+	var emails []string
+	for _, email := range strings.Split(l.getenv(key), ",") {
+		if trimmed := strings.TrimSpace(email); trimmed != "" {
+			emails = append(emails, trimmed)
+		}
+	}
+	return emails
+}
+
+// prefixes parses a comma-separated list of CIDR ranges, where a bare
+// address stands for itself
+func (l *loader) prefixes(key string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, value := range strings.Split(l.getenv(key), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(value); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			l.problems = append(l.problems, fmt.Sprintf("%s must be a comma-separated list of addresses or CIDR ranges such as 10.0.0.0/8, got %q", key, value))
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+// origin returns the scheme and host of rawURL, or rawURL itself if it does
+// not parse
+func origin(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Scheme + "://" + u.Host
+}
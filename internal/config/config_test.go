@@ -0,0 +1,322 @@
+package config
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envFrom returns a getenv function backed by a map
+func envFrom(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestLoadFrom_Defaults(t *testing.T) {
+	cfg, err := LoadFrom(envFrom(nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.Server.Port != "8080" || cfg.Server.Mode != ModeProduction || cfg.Server.CompressionMinSize != 256 {
+		t.Errorf("Unexpected server defaults: %+v", cfg.Server)
+	}
+	if cfg.Auth.RedirectURL != "http://localhost:8080/auth/callback" || cfg.Auth.SecureCookies {
+		t.Errorf("Unexpected auth defaults: %+v", cfg.Auth)
+	}
+	if cfg.Server.PublicURL != "http://localhost:8080" {
+		t.Errorf("Expected the public URL to default to the redirect origin, got %q", cfg.Server.PublicURL)
+	}
+	if cfg.Notifications.CheckInterval != 5*time.Minute || cfg.Notifications.DigestHour != 8 || cfg.Notifications.SnoozeDuration != 3*time.Hour ||
+		cfg.Notifications.WeeklyReportEnabled || cfg.Notifications.WeeklyReportDay != time.Monday {
+		t.Errorf("Unexpected notification defaults: %+v", cfg.Notifications)
+	}
+	if cfg.Push.Enabled() || cfg.SMTP.Enabled() || cfg.IsDemoMode() {
+		t.Error("Expected optional features to be disabled by default")
+	}
+}
+
+func TestLoadFrom_Values(t *testing.T) {
+	cfg, err := LoadFrom(envFrom(map[string]string{
+		"PORT":                        "9090",
+		"ENVIRONMENT":                 "production",
+		"GOOGLE_CLIENT_ID":            "client-id",
+		"GOOGLE_CLIENT_SECRET":        "client-secret",
+		"ALLOWED_EMAILS":              " user1@example.com, ,user2@example.com ",
+		"ADMIN_EMAILS":                "admin@example.com",
+		"VIEWER_EMAILS":               "sitter@example.com",
+		"DATA_FILE":                   "/data/watered.json",
+		"NOTIFICATION_CHECK_INTERVAL": "1m",
+		"SMTP_HOST":                   "smtp.example.com",
+		"SMTP_USER":                   "bot@example.com",
+		"EMAIL_DIGEST":                "true",
+		"EMAIL_DIGEST_HOUR":           "7",
+		"EMAIL_WEEKLY_REPORT":         "true",
+		"EMAIL_WEEKLY_REPORT_DAY":     "Sun",
+		"CSP_REPORT_ONLY":             "1",
+		"LOG_LEVEL":                   "DEBUG",
+		"CLOCK_SKEW_TOLERANCE":        "5m",
+		"UNDO_WATERING_WINDOW":        "1h",
+		"HEALTH_CACHE_TTL":            "0s",
+		"HEALTH_METRICS_INTERVAL":     "30s",
+		"HEALTH_HISTORY_INTERVAL":     "1m",
+		"HEALTH_HISTORY_RETENTION":    "24h",
+		"PUBLIC_URL":                  "https://plants.example.com/",
+		"BADGE_REQUIRE_TOKEN":         "true",
+		"COMPRESSION_MIN_SIZE":        "0",
+		"TRUSTED_PROXIES":             "10.0.0.0/8, 192.168.1.5",
+		"SNOOZE_DURATION":             "90m",
+		"TELEGRAM_BOT_TOKEN":          "123:abc",
+		"TELEGRAM_CHAT_ID":            "-100123",
+		"TELEGRAM_WEBHOOK_SECRET":     "hook-secret",
+		"TELEGRAM_USERS":              "111=Alice@example.com, 222=bob@example.com",
+		"SLACK_WEBHOOK_URL":           "https://hooks.slack.com/services/T000/B000/XXX",
+		"SLACK_WATERED_TEMPLATE":      "{{.WateredBy}} watered {{.Plant}}",
+		"NTFY_TOPIC":                  "watered-home-7f3a",
+		"SENSOR_DEVICES":              "kitchen=1:kitchen-token, balcony-2=3:balcony:token",
+		"MQTT_BROKER_URL":             "ssl://mqtt.example.com",
+		"MQTT_TOPIC":                  "home/+/soil",
+		"SENSOR_WATERING_RISE":        "15",
+		"BACKUP_BUCKET":               "watered-backups",
+		"BACKUP_ENDPOINT":             "https://s3.eu-west-1.amazonaws.com/",
+		"BACKUP_REGION":               "eu-west-1",
+		"BACKUP_ACCESS_KEY":           "access",
+		"BACKUP_SECRET_KEY":           "secret",
+		"BACKUP_RETAIN":               "30",
+		"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318/",
+		"OTEL_EXPORTER_OTLP_HEADERS":  "x-api-key=abc%3D, x-team = plants",
+		"OTEL_TRACES_SAMPLER_ARG":     "0.25",
+		"FEATURE_SENSORS":             "false",
+	}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.Server.Port != "9090" || !cfg.IsProduction() || cfg.IsDemoMode() || cfg.Server.LogLevel != slog.LevelDebug || cfg.Server.ClockSkewTolerance != 5*time.Minute || cfg.Server.UndoWateringWindow != time.Hour || cfg.Server.HealthCacheTTL != 0 || cfg.Server.HealthMetricsInterval != 30*time.Second || cfg.Server.HealthHistoryInterval != time.Minute || cfg.Server.HealthHistoryRetention != 24*time.Hour || !cfg.Server.BadgeRequireToken || cfg.Server.CompressionMinSize != 0 {
+		t.Errorf("Unexpected server config: %+v", cfg.Server)
+	}
+	if !cfg.Auth.SecureCookies {
+		t.Error("Expected secure cookies to be forced on in production")
+	}
+	if len(cfg.Auth.AllowedEmails) != 2 || cfg.Auth.AllowedEmails[1] != "user2@example.com" {
+		t.Errorf("Expected trimmed allowed emails, got %v", cfg.Auth.AllowedEmails)
+	}
+	if len(cfg.Auth.ViewerEmails) != 1 || cfg.Auth.ViewerEmails[0] != "sitter@example.com" {
+		t.Errorf("Expected viewer emails, got %v", cfg.Auth.ViewerEmails)
+	}
+	if cfg.Storage.DataFile != "/data/watered.json" {
+		t.Errorf("Unexpected storage config: %+v", cfg.Storage)
+	}
+	if len(cfg.Server.TrustedProxies) != 2 || cfg.Server.TrustedProxies[0].String() != "10.0.0.0/8" || cfg.Server.TrustedProxies[1].String() != "192.168.1.5/32" {
+		t.Errorf("Expected trusted proxy ranges, got %v", cfg.Server.TrustedProxies)
+	}
+	if cfg.Server.PublicURL != "https://plants.example.com" {
+		t.Errorf("Expected the public URL without a trailing slash, got %q", cfg.Server.PublicURL)
+	}
+	if cfg.Notifications.CheckInterval != time.Minute || !cfg.Notifications.DigestEnabled || cfg.Notifications.DigestHour != 7 || cfg.Notifications.SnoozeDuration != 90*time.Minute ||
+		!cfg.Notifications.WeeklyReportEnabled || cfg.Notifications.WeeklyReportDay != time.Sunday {
+		t.Errorf("Unexpected notification config: %+v", cfg.Notifications)
+	}
+	if cfg.SMTP.Port != 587 || cfg.SMTP.From != "bot@example.com" {
+		t.Errorf("Expected SMTP defaults, got %+v", cfg.SMTP)
+	}
+	if !cfg.Slack.Enabled() || cfg.Slack.WateredTemplate != "{{.WateredBy}} watered {{.Plant}}" || cfg.Slack.OverdueTemplate != "" {
+		t.Errorf("Unexpected Slack config: %+v", cfg.Slack)
+	}
+	if !cfg.Telegram.Enabled() || cfg.Telegram.ChatID != -100123 || len(cfg.Telegram.Users) != 2 || cfg.Telegram.Users[111] != "alice@example.com" {
+		t.Errorf("Unexpected Telegram config: %+v", cfg.Telegram)
+	}
+	if !cfg.Ntfy.Enabled() || cfg.Ntfy.ServerURL != "https://ntfy.sh" || cfg.Ntfy.Topic != "watered-home-7f3a" {
+		t.Errorf("Expected the public ntfy server by default, got %+v", cfg.Ntfy)
+	}
+	if !cfg.Sensors.Enabled() || cfg.Sensors.Devices["kitchen"] != (SensorDevice{PlantID: 1, Token: "kitchen-token"}) || cfg.Sensors.Devices["balcony-2"].Token != "balcony:token" || cfg.Sensors.WateringRise != 15 || cfg.Sensors.WateringCooldown != 6*time.Hour {
+		t.Errorf("Unexpected sensor config: %+v", cfg.Sensors)
+	}
+	if !cfg.MQTT.Enabled() || !cfg.MQTT.TLS() || cfg.MQTT.Topic != "home/+/soil" || cfg.MQTT.ClientID != "watered" {
+		t.Errorf("Unexpected MQTT config: %+v", cfg.MQTT)
+	}
+	if !cfg.Backup.Enabled() || cfg.Backup.Endpoint != "https://s3.eu-west-1.amazonaws.com" || cfg.Backup.Prefix != "watered/" || cfg.Backup.Interval != 24*time.Hour || cfg.Backup.Retain != 30 {
+		t.Errorf("Unexpected backup config: %+v", cfg.Backup)
+	}
+	if !cfg.Tracing.Enabled() || cfg.Tracing.Endpoint != "http://collector:4318" || cfg.Tracing.Headers["x-api-key"] != "abc=" || cfg.Tracing.Headers["x-team"] != "plants" ||
+		cfg.Tracing.ServiceName != "watered" || cfg.Tracing.SampleRatio != 0.25 {
+		t.Errorf("Unexpected tracing config: %+v", cfg.Tracing)
+	}
+	if !cfg.CSP.ReportOnly {
+		t.Error("Expected CSP report-only mode")
+	}
+	if cfg.Features != (FeatureConfig{Notifications: true, MultiPlant: true}) {
+		t.Errorf("Expected only the sensors flag to be off, got %+v", cfg.Features)
+	}
+}
+
+func TestLoadFrom_DemoMode(t *testing.T) {
+	cfg, err := LoadFrom(envFrom(map[string]string{
+		"DEMO_MODE":   "true",
+		"DEMO_USERS":  "guest@example.com, host@example.com",
+		"ENVIRONMENT": "staging",
+	}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Demo login alone doesn't make a sandbox
+	if !cfg.Auth.DemoMode || cfg.IsDemoMode() || cfg.AuthMode() != AuthModeDemo {
+		t.Errorf("Expected demo login without the sandbox, got %+v", cfg.Auth)
+	}
+	if len(cfg.Auth.DemoUsers) != 2 || cfg.Auth.DemoUsers[1] != "host@example.com" {
+		t.Errorf("Expected demo users, got %v", cfg.Auth.DemoUsers)
+	}
+
+	// Missing OAuth credentials no longer turn demo login on
+	cfg, err = LoadFrom(envFrom(map[string]string{"ENVIRONMENT": "staging"}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Auth.DemoMode || cfg.AuthMode() != AuthModeUnconfigured {
+		t.Errorf("Expected demo login off without DEMO_MODE, got %+v", cfg.Auth)
+	}
+}
+
+func TestLoadFrom_DemoSandbox(t *testing.T) {
+	cfg, err := LoadFrom(envFrom(map[string]string{
+		"WATERED_MODE":          "demo",
+		"DEMO_RESET_INTERVAL":   "15m",
+		"DATA_FILE":             "/data/watered.json",
+		"SMTP_HOST":             "smtp.example.com",
+		"SMTP_USER":             "bot@example.com",
+		"EMAIL_DIGEST":          "true",
+		"EMAIL_WEEKLY_REPORT":   "true",
+		"SLACK_WEBHOOK_URL":     "https://hooks.slack.com/services/T000/B000/XXX",
+		"ESCALATION_CHAIN":      "email:alice@example.com, 2h email",
+		"UPDATE_PUBLIC_KEY":     "key",
+		"UPDATE_CHECK_INTERVAL": "1h",
+	}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !cfg.IsDemoMode() || !cfg.Auth.DemoMode || cfg.Demo.ResetInterval != 15*time.Minute {
+		t.Errorf("Unexpected demo config: %+v", cfg.Demo)
+	}
+	if cfg.Storage.DataFile != "" || cfg.SMTP.Enabled() || cfg.Notifications.DigestEnabled || cfg.Notifications.WeeklyReportEnabled || cfg.Slack.Enabled() || cfg.Escalation.Enabled() || cfg.Update.Enabled() {
+		t.Errorf("Expected demo mode to turn off storage files and integrations, got %+v", cfg)
+	}
+	want := []string{"DATA_FILE", "SMTP_HOST", "SLACK_WEBHOOK_URL", "ESCALATION_CHAIN", "UPDATE_PUBLIC_KEY"}
+	if strings.Join(cfg.Demo.Ignored, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected ignored settings %v, got %v", want, cfg.Demo.Ignored)
+	}
+}
+
+func TestLoadFrom_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		message string
+	}{
+		{"port", map[string]string{"PORT": "http"}, "PORT must be a number"},
+		{"mode", map[string]string{"WATERED_MODE": "staging"}, "WATERED_MODE must be"},
+		{"boolean", map[string]string{"SECURE_COOKIES": "yes please"}, "SECURE_COOKIES must be true or false"},
+		{"capacity warn days", map[string]string{"CAPACITY_WARN_DAYS": "-1"}, "CAPACITY_WARN_DAYS must not be negative"},
+		{"log level", map[string]string{"LOG_LEVEL": "verbose"}, "LOG_LEVEL must be debug, info, warn or error"},
+		{"clock skew tolerance", map[string]string{"CLOCK_SKEW_TOLERANCE": "0s"}, "CLOCK_SKEW_TOLERANCE must be positive"},
+		{"undo watering window", map[string]string{"UNDO_WATERING_WINDOW": "0s"}, "UNDO_WATERING_WINDOW must be positive"},
+		{"health cache ttl", map[string]string{"HEALTH_CACHE_TTL": "-1s"}, "HEALTH_CACHE_TTL must not be negative"},
+		{"health metrics interval", map[string]string{"HEALTH_METRICS_INTERVAL": "-1s"}, "HEALTH_METRICS_INTERVAL must not be negative"},
+		{"health history interval", map[string]string{"HEALTH_HISTORY_INTERVAL": "-1m"}, "HEALTH_HISTORY_INTERVAL must not be negative"},
+		{"health history retention", map[string]string{"HEALTH_HISTORY_RETENTION": "0s"}, "HEALTH_HISTORY_RETENTION must be positive"},
+		{"http timeout", map[string]string{"HTTP_WRITE_TIMEOUT": "0s"}, "HTTP_WRITE_TIMEOUT must be positive"},
+		{"compression min size", map[string]string{"COMPRESSION_MIN_SIZE": "-1"}, "COMPRESSION_MIN_SIZE must not be negative"},
+		{"trusted proxies", map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8,proxy.local"}, "TRUSTED_PROXIES must be a comma-separated list of addresses or CIDR ranges"},
+		{"feature flag", map[string]string{"FEATURE_MULTI_PLANT": "beta"}, "FEATURE_MULTI_PLANT must be true or false"},
+		{"profile", map[string]string{"PROFILE": "kubernetes"}, `PROFILE must be one of cloud-run, development, raspberry-pi, got "kubernetes"`},
+		{"partial oauth", map[string]string{"GOOGLE_CLIENT_ID": "id"}, "must be set together"},
+		{"demo login in production", map[string]string{"DEMO_MODE": "true", "ENVIRONMENT": "production"}, "DEMO_MODE must not be enabled when ENVIRONMENT is production"},
+		{"interval", map[string]string{"NOTIFICATION_CHECK_INTERVAL": "often"}, "NOTIFICATION_CHECK_INTERVAL must be a duration"},
+		{"negative interval", map[string]string{"NOTIFICATION_CHECK_INTERVAL": "-1m"}, "must be positive"},
+		{"digest hour", map[string]string{"SMTP_HOST": "smtp.example.com", "SMTP_FROM": "a@example.com", "EMAIL_DIGEST_HOUR": "24"}, "EMAIL_DIGEST_HOUR must be between 0 and 23"},
+		{"digest without smtp", map[string]string{"EMAIL_DIGEST": "true"}, "EMAIL_DIGEST requires SMTP_HOST"},
+		{"weekly report without smtp", map[string]string{"EMAIL_WEEKLY_REPORT": "true"}, "EMAIL_WEEKLY_REPORT requires SMTP_HOST"},
+		{"weekly report day", map[string]string{"EMAIL_WEEKLY_REPORT_DAY": "someday"}, "EMAIL_WEEKLY_REPORT_DAY must be a day of the week"},
+		{"smtp port", map[string]string{"SMTP_HOST": "smtp.example.com", "SMTP_FROM": "a@example.com", "SMTP_PORT": "0"}, "SMTP_PORT must be between"},
+		{"smtp from", map[string]string{"SMTP_HOST": "smtp.example.com"}, "SMTP_FROM (or SMTP_USER)"},
+		{"slack webhook", map[string]string{"SLACK_WEBHOOK_URL": "hooks.slack.com/services/T000"}, "SLACK_WEBHOOK_URL must be an http or https URL"},
+		{"slack template", map[string]string{"SLACK_OVERDUE_TEMPLATE": "{{.Plant"}, "SLACK_OVERDUE_TEMPLATE is not a valid template"},
+		{"public url", map[string]string{"PUBLIC_URL": "plants.example.com"}, "PUBLIC_URL must be an http or https URL"},
+		{"snooze duration", map[string]string{"SNOOZE_DURATION": "0s"}, "SNOOZE_DURATION must be positive"},
+		{"demo reset interval", map[string]string{"DEMO_RESET_INTERVAL": "-1h"}, "DEMO_RESET_INTERVAL must not be negative"},
+		{"telegram chat", map[string]string{"TELEGRAM_BOT_TOKEN": "123:abc", "TELEGRAM_WEBHOOK_SECRET": "s"}, "TELEGRAM_BOT_TOKEN requires TELEGRAM_CHAT_ID"},
+		{"telegram secret", map[string]string{"TELEGRAM_BOT_TOKEN": "123:abc", "TELEGRAM_CHAT_ID": "42"}, "TELEGRAM_BOT_TOKEN requires TELEGRAM_WEBHOOK_SECRET"},
+		{"telegram chat id", map[string]string{"TELEGRAM_CHAT_ID": "general"}, "TELEGRAM_CHAT_ID must be a whole number"},
+		{"telegram users", map[string]string{"TELEGRAM_USERS": "alice@example.com"}, "TELEGRAM_USERS: entry \"alice@example.com\" must be"},
+		{"telegram user id", map[string]string{"TELEGRAM_USERS": "abc=alice@example.com"}, "invalid Telegram user ID"},
+		{"telegram duplicate", map[string]string{"TELEGRAM_USERS": "1=a@example.com,1=b@example.com"}, "listed more than once"},
+		{"discord webhook", map[string]string{"DISCORD_WEBHOOK_URL": "ftp://discord.com/api/webhooks/1"}, "DISCORD_WEBHOOK_URL must be an http or https URL"},
+		{"ntfy server", map[string]string{"NTFY_TOPIC": "watered", "NTFY_SERVER_URL": "ntfy.example.com"}, "NTFY_SERVER_URL must be an http or https URL"},
+		{"ntfy topic", map[string]string{"NTFY_TOPIC": "my plants"}, "NTFY_TOPIC must be 1 to 64 letters"},
+		{"sensor format", map[string]string{"SENSOR_DEVICES": "kitchen=1"}, "SENSOR_DEVICES: entry \"kitchen=1\" must look like device=plant:token"},
+		{"sensor device id", map[string]string{"SENSOR_DEVICES": "my kitchen=1:kitchen-token"}, "invalid device ID"},
+		{"sensor plant id", map[string]string{"SENSOR_DEVICES": "kitchen=fern:kitchen-token"}, "invalid plant ID"},
+		{"sensor token", map[string]string{"SENSOR_DEVICES": "kitchen=1:short"}, "token of at least 8 characters"},
+		{"sensor duplicate", map[string]string{"SENSOR_DEVICES": "kitchen=1:kitchen-token,kitchen=2:kitchen-token"}, "device kitchen is listed more than once"},
+		{"button debounce", map[string]string{"BUTTON_DEBOUNCE": "0s"}, "BUTTON_DEBOUNCE must be positive"},
+		{"sensor watering rise", map[string]string{"SENSOR_WATERING_RISE": "120"}, "SENSOR_WATERING_RISE must be between 0 and 100"},
+		{"sensor watering cooldown", map[string]string{"SENSOR_WATERING_COOLDOWN": "0s"}, "SENSOR_WATERING_COOLDOWN must be positive"},
+		{"weather longitude", map[string]string{"WEATHER_LATITUDE": "52.52"}, "WEATHER_LATITUDE and WEATHER_LONGITUDE must be set together"},
+		{"weather latitude", map[string]string{"WEATHER_LATITUDE": "152.5", "WEATHER_LONGITUDE": "13.41"}, "WEATHER_LATITUDE must be a number between -90 and 90"},
+		{"weather refresh", map[string]string{"WEATHER_LATITUDE": "52.52", "WEATHER_LONGITUDE": "13.41", "WEATHER_REFRESH_INTERVAL": "0s"}, "WEATHER_REFRESH_INTERVAL must be positive"},
+		{"mqtt broker", map[string]string{"SENSOR_DEVICES": "kitchen=1:kitchen-token", "MQTT_BROKER_URL": "http://mqtt.example.com"}, "MQTT_BROKER_URL must be a tcp:// or ssl:// URL"},
+		{"mqtt topic", map[string]string{"SENSOR_DEVICES": "kitchen=1:kitchen-token", "MQTT_BROKER_URL": "tcp://mqtt.example.com", "MQTT_TOPIC": "watered/#"}, "MQTT_TOPIC must have exactly one + level"},
+		{"mqtt topic wildcards", map[string]string{"SENSOR_DEVICES": "kitchen=1:kitchen-token", "MQTT_BROKER_URL": "tcp://mqtt.example.com", "MQTT_TOPIC": "+/sensors/+"}, "MQTT_TOPIC must have exactly one + level"},
+		{"partial vapid", map[string]string{"VAPID_PUBLIC_KEY": "key", "VAPID_SUBJECT": "mailto:a@example.com"}, "VAPID_PUBLIC_KEY and VAPID_PRIVATE_KEY"},
+		{"vapid subject", map[string]string{"VAPID_PUBLIC_KEY": "key", "VAPID_PRIVATE_KEY": "key"}, "VAPID_SUBJECT is required"},
+		{"update interval without key", map[string]string{"UPDATE_CHECK_INTERVAL": "24h"}, "UPDATE_CHECK_INTERVAL requires UPDATE_PUBLIC_KEY"},
+		{"update repository", map[string]string{"UPDATE_REPOSITORY": "watered"}, "UPDATE_REPOSITORY must look like owner/name"},
+		{"backup credentials", map[string]string{"BACKUP_BUCKET": "watered-backups"}, "BACKUP_BUCKET requires BACKUP_ACCESS_KEY and BACKUP_SECRET_KEY"},
+		{"backup endpoint", map[string]string{"BACKUP_BUCKET": "watered-backups", "BACKUP_ACCESS_KEY": "a", "BACKUP_SECRET_KEY": "s", "BACKUP_ENDPOINT": "storage.googleapis.com"}, "BACKUP_ENDPOINT must be an http or https URL"},
+		{"backup retain", map[string]string{"BACKUP_BUCKET": "watered-backups", "BACKUP_ACCESS_KEY": "a", "BACKUP_SECRET_KEY": "s", "BACKUP_RETAIN": "0"}, "BACKUP_RETAIN must be at least 1"},
+		{"tracing endpoint", map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "collector:4318"}, "OTEL_EXPORTER_OTLP_ENDPOINT must be an http or https URL"},
+		{"tracing headers", map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_EXPORTER_OTLP_HEADERS": "x-api-key"}, "OTEL_EXPORTER_OTLP_HEADERS must be key=value pairs"},
+		{"sample ratio", map[string]string{"OTEL_TRACES_SAMPLER_ARG": "1.5"}, "OTEL_TRACES_SAMPLER_ARG must be a number between 0 and 1"},
+		{"negative rate limit", map[string]string{"RATE_LIMIT": "-1"}, "RATE_LIMIT must not be negative"},
+		{"rate limit warn", map[string]string{"RATE_LIMIT": "10", "RATE_LIMIT_WARN": "20"}, "RATE_LIMIT_WARN must be between 1 and RATE_LIMIT"},
+		{"rate limit window", map[string]string{"RATE_LIMIT_WINDOW": "0s"}, "RATE_LIMIT_WINDOW must be positive"},
+		{"auth bucket burst", map[string]string{"RATE_LIMIT_AUTH_BURST": "-1"}, "RATE_LIMIT_AUTH_BURST must not be negative"},
+		{"token lifetimes", map[string]string{"ACCESS_TOKEN_TTL": "48h", "REFRESH_TOKEN_TTL": "24h"}, "ACCESS_TOKEN_TTL must be positive and at most REFRESH_TOKEN_TTL"},
+		{"lockout threshold", map[string]string{"AUTH_LOCKOUT_THRESHOLD": "-1"}, "AUTH_LOCKOUT_THRESHOLD must not be negative"},
+		{"lockout backoff", map[string]string{"AUTH_LOCKOUT_BASE": "2h"}, "AUTH_LOCKOUT_BASE must be positive and at most AUTH_LOCKOUT_MAX"},
+		{"write bucket refill", map[string]string{"RATE_LIMIT_WRITE_REFILL": "0s"}, "RATE_LIMIT_WRITE_REFILL must be positive"},
+		{"escalation chain", map[string]string{"ESCALATION_CHAIN": "sms:alice@example.com"}, "ESCALATION_CHAIN: step"},
+		{"escalation without smtp", map[string]string{"ESCALATION_CHAIN": "email:alice@example.com"}, "requires SMTP_HOST"},
+		{"working hours without chain", map[string]string{"ESCALATION_WORKING_HOURS": "Mon-Fri 09:00-17:00"}, "ESCALATION_WORKING_HOURS requires ESCALATION_CHAIN"},
+		{"email list", map[string]string{"ADMIN_EMAILS": "admin"}, `"admin" in ALLOWED_EMAILS, ADMIN_EMAILS or VIEWER_EMAILS`},
+		{"demo user", map[string]string{"DEMO_USERS": "guest"}, `"guest" in DEMO_USERS is not a valid email address`},
+		{"admin and viewer", map[string]string{"ADMIN_EMAILS": "admin@example.com", "VIEWER_EMAILS": "admin@example.com"}, `"admin@example.com" is in both ADMIN_EMAILS and VIEWER_EMAILS`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadFrom(envFrom(tt.env))
+			if err == nil {
+				t.Fatal("Expected validation error")
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error to mention %q, got %v", tt.message, err)
+			}
+			if cfg == nil {
+				t.Error("Expected a usable config alongside the error")
+			}
+		})
+	}
+}
+
+func TestLoadFrom_ReportsEveryProblem(t *testing.T) {
+	_, err := LoadFrom(envFrom(map[string]string{"PORT": "0", "EMAIL_DIGEST_HOUR": "soon"}))
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	if !strings.Contains(err.Error(), "PORT") || !strings.Contains(err.Error(), "EMAIL_DIGEST_HOUR") {
+		t.Errorf("Expected both problems to be reported, got %v", err)
+	}
+}
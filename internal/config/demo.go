@@ -0,0 +1,49 @@
+package config
+
+import "time"
+
+// DemoConfig holds the demo sandbox settings, used when WATERED_MODE=demo
+type DemoConfig struct {
+	// ResetInterval is how often the sandbox is wiped and seeded with the
+	// sample data again, 0 keeps visitors' changes until restart
+	ResetInterval time.Duration // DEMO_RESET_INTERVAL
+	// Ignored lists the settings demo mode turned off, for the startup report
+	Ignored []string
+}
+
+// sandbox turns off everything that would make a demo touch real data or
+// reach real people: the data file or journal, and every outbound
+// integration. It records the settings that were set and ignored.
+func (c *Config) sandbox() {
+	ignore := func(key string, set bool) {
+		if set {
+			c.Demo.Ignored = append(c.Demo.Ignored, key)
+		}
+	}
+
+	ignore("DATA_FILE", c.Storage.DataFile != "")
+	ignore("JOURNAL_FILE", c.Storage.JournalFile != "")
+	c.Storage = StorageConfig{}
+
+	ignore("SMTP_HOST", c.SMTP.Enabled())
+	c.SMTP.Host = ""
+	c.Notifications.DigestEnabled = false
+	c.Notifications.WeeklyReportEnabled = false
+	ignore("VAPID_PUBLIC_KEY", c.Push.Enabled())
+	c.Push = PushConfig{}
+	ignore("SLACK_WEBHOOK_URL", c.Slack.Enabled())
+	c.Slack.WebhookURL = ""
+	ignore("DISCORD_WEBHOOK_URL", c.Discord.Enabled())
+	c.Discord = DiscordConfig{}
+	ignore("NTFY_TOPIC", c.Ntfy.Enabled())
+	c.Ntfy.Topic = ""
+	ignore("TELEGRAM_BOT_TOKEN", c.Telegram.Enabled())
+	c.Telegram = TelegramConfig{}
+	ignore("ESCALATION_CHAIN", c.Escalation.Enabled())
+	c.Escalation = EscalationConfig{}
+	ignore("UPDATE_PUBLIC_KEY", c.Update.Enabled())
+	c.Update.PublicKey = ""
+	c.Update.CheckInterval = 0
+	ignore("BACKUP_BUCKET", c.Backup.Enabled())
+	c.Backup.Bucket = ""
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Escalation channels. They match the notification channel names.
+const (
+	EscalationEmail = "email"
+	EscalationPush  = "push"
+)
+
+// EscalationConfig holds the escalation chain for overdue plants
+type EscalationConfig struct {
+	Steps        []EscalationStep // ESCALATION_CHAIN
+	WorkingHours *WorkingHours    // ESCALATION_WORKING_HOURS, nil means any time
+}
+
+// Enabled reports whether an escalation chain is configured
+func (c EscalationConfig) Enabled() bool {
+	return len(c.Steps) > 0
+}
+
+// EscalationStep notifies one recipient, or everyone on a channel, once a
+// plant has been overdue for Delay
+type EscalationStep struct {
+	Delay     time.Duration
+	Channel   string
+	Recipient string // empty notifies every user on the channel
+}
+
+func (s EscalationStep) String() string {
+	target := s.Channel
+	if s.Recipient != "" {
+		target += ":" + s.Recipient
+	}
+	return fmt.Sprintf("%s after %s", target, s.Delay)
+}
+
+// ParseEscalationChain parses a comma-separated list of steps such as
+// "email:alice@example.com, 2h email:bob@example.com, 4h push". Each step is
+// an optional delay followed by a channel and an optional recipient. Delays
+// must not decrease along the chain.
+func ParseEscalationChain(value string) ([]EscalationStep, error) {
+	var steps []EscalationStep
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		var step EscalationStep
+		target := field
+		if delay, rest, ok := strings.Cut(field, " "); ok {
+			parsed, err := time.ParseDuration(delay)
+			if err != nil || parsed < 0 {
+				return nil, fmt.Errorf("step %q must start with a delay such as 30m or 2h", field)
+			}
+			step.Delay = parsed
+			target = strings.TrimSpace(rest)
+		}
+
+		step.Channel, step.Recipient, _ = strings.Cut(target, ":")
+		if step.Channel != EscalationEmail && step.Channel != EscalationPush {
+			return nil, fmt.Errorf("step %q must use channel %q or %q", field, EscalationEmail, EscalationPush)
+		}
+		if step.Recipient != "" {
+			if _, err := mail.ParseAddress(step.Recipient); err != nil {
+				return nil, fmt.Errorf("step %q has an invalid recipient", field)
+			}
+			step.Recipient = strings.ToLower(step.Recipient)
+		}
+
+		if len(steps) > 0 && step.Delay < steps[len(steps)-1].Delay {
+			return nil, fmt.Errorf("step %q is delayed less than the step before it", field)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// WorkingHours is a weekly calendar of when escalation steps may be sent
+type WorkingHours struct {
+	Days  [7]bool // indexed by time.Weekday
+	Start int     // minutes after midnight
+	End   int     // minutes after midnight, exclusive
+}
+
+// weekdays maps three-letter day names to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseWorkingHours parses a calendar such as "Mon-Fri 09:00-17:00" or
+// "Mon,Wed,Fri 08:00-12:00". Ranges may not wrap past midnight.
+func ParseWorkingHours(value string) (*WorkingHours, error) {
+	days, hours, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok {
+		return nil, fmt.Errorf("working hours must look like \"Mon-Fri 09:00-17:00\", got %q", value)
+	}
+
+	wh := &WorkingHours{}
+	for _, part := range strings.Split(days, ",") {
+		from, to, isRange := strings.Cut(strings.ToLower(strings.TrimSpace(part)), "-")
+		first, ok := weekdays[from]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q in working hours", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[to]; !ok {
+				return nil, fmt.Errorf("unknown day %q in working hours", to)
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			wh.Days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+
+	start, end, ok := strings.Cut(strings.TrimSpace(hours), "-")
+	if !ok {
+		return nil, fmt.Errorf("working hours must include a time range such as 09:00-17:00, got %q", hours)
+	}
+	var err error
+	if wh.Start, err = parseClock(start); err != nil {
+		return nil, err
+	}
+	if wh.End, err = parseClock(end); err != nil {
+		return nil, err
+	}
+	if wh.End <= wh.Start {
+		return nil, fmt.Errorf("working hours must end after they start, got %q", hours)
+	}
+	return wh, nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight; "24:00" is allowed as an end time
+func parseClock(value string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil ||
+		hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, fmt.Errorf("invalid time %q in working hours", value)
+	}
+	return hour*60 + minute, nil
+}
+
+// Contains reports whether t falls within working hours in t's location
+func (w *WorkingHours) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	return w.Days[t.Weekday()] && minute >= w.Start && minute < w.End
+}
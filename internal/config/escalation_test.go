@@ -0,0 +1,67 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseEscalationChain(t *testing.T) {
+	steps, err := ParseEscalationChain("email:Alice@Example.com, 2h email:bob@example.com,, 4h30m push")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []EscalationStep{
+		{Delay: 0, Channel: EscalationEmail, Recipient: "alice@example.com"},
+		{Delay: 2 * time.Hour, Channel: EscalationEmail, Recipient: "bob@example.com"},
+		{Delay: 4*time.Hour + 30*time.Minute, Channel: EscalationPush},
+	}
+	if !reflect.DeepEqual(steps, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, steps)
+	}
+
+	for _, invalid := range []string{
+		"sms:alice@example.com",
+		"soon email:alice@example.com",
+		"-1h push",
+		"email:not-an-address",
+		"2h push, 1h email:alice@example.com",
+	} {
+		if _, err := ParseEscalationChain(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestParseWorkingHours(t *testing.T) {
+	tests := []struct {
+		value string
+		time  time.Time
+		want  bool
+	}{
+		{"Mon-Fri 09:00-17:00", time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC), true},   // Monday opening
+		{"Mon-Fri 09:00-17:00", time.Date(2024, 5, 6, 17, 0, 0, 0, time.UTC), false}, // Monday closing
+		{"Mon-Fri 09:00-17:00", time.Date(2024, 5, 11, 12, 0, 0, 0, time.UTC), false},
+		{"mon,wed 08:00-12:00", time.Date(2024, 5, 8, 11, 59, 0, 0, time.UTC), true},
+		{"mon,wed 08:00-12:00", time.Date(2024, 5, 7, 10, 0, 0, 0, time.UTC), false},
+		{"Fri-Mon 00:00-24:00", time.Date(2024, 5, 12, 23, 59, 0, 0, time.UTC), true}, // Sunday, wrapping day range
+		{"Fri-Mon 00:00-24:00", time.Date(2024, 5, 8, 12, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		hours, err := ParseWorkingHours(tt.value)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.value, err)
+		}
+		if got := hours.Contains(tt.time); got != tt.want {
+			t.Errorf("%q contains %s: expected %v, got %v", tt.value, tt.time.Format(time.RFC1123), tt.want, got)
+		}
+	}
+
+	for _, invalid := range []string{"", "Mon-Fri", "Someday 09:00-17:00", "Mon-Fri 17:00-09:00", "Mon 9-17", "Mon 09:00-25:00"} {
+		if _, err := ParseWorkingHours(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
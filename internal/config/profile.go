@@ -0,0 +1,50 @@
+package config
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// profiles holds the bundled PROFILE files, one .env file per profile
+//
+//go:embed profiles/*.env
+var profiles embed.FS
+
+// Profiles returns the names of the bundled profiles, sorted
+func Profiles() []string {
+	entries, _ := profiles.ReadDir("profiles")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".env"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns the settings a bundled profile sets
+func Profile(name string) (map[string]string, error) {
+	data, err := profiles.ReadFile("profiles/" + name + ".env")
+	if err != nil {
+		return nil, fmt.Errorf("PROFILE must be one of %s, got %q", strings.Join(Profiles(), ", "), name)
+	}
+	settings, err := godotenv.Unmarshal(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("profile %s is malformed: %w", name, err)
+	}
+	return settings, nil
+}
+
+// withProfile returns a getenv that falls back to the profile's settings for
+// variables the environment leaves empty, so anything set explicitly wins
+func withProfile(getenv func(string) string, settings map[string]string) func(string) string {
+	return func(key string) string {
+		if value := getenv(key); value != "" {
+			return value
+		}
+		return settings[key]
+	}
+}
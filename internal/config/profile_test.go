@@ -0,0 +1,60 @@
+package config
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestProfiles_LoadCleanly(t *testing.T) {
+	for _, name := range Profiles() {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := LoadFrom(envFrom(map[string]string{"PROFILE": name}))
+			if err != nil {
+				t.Fatalf("Expected profile %s to be valid on its own, got %v", name, err)
+			}
+			if cfg.Server.Profile != name {
+				t.Errorf("Expected profile %s to be recorded, got %q", name, cfg.Server.Profile)
+			}
+		})
+	}
+}
+
+func TestLoadFrom_Profile(t *testing.T) {
+	cfg, err := LoadFrom(envFrom(map[string]string{"PROFILE": "cloud-run"}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !cfg.IsProduction() || !cfg.Auth.SecureCookies {
+		t.Errorf("Expected a secure production configuration, got %+v", cfg.Auth)
+	}
+	if cfg.Storage.DataFile != "/data/watered.json" || cfg.Server.WriteTimeout != 30*time.Second {
+		t.Errorf("Expected the profile's storage and timeouts, got %+v %+v", cfg.Storage, cfg.Server)
+	}
+
+	cfg, err = LoadFrom(envFrom(map[string]string{"PROFILE": "raspberry-pi"}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report := cfg.Report(); report.Profile != "raspberry-pi" || report.StorageDriver != "journal" {
+		t.Errorf("Expected the journal driver to be reported for the profile, got %+v", report)
+	}
+}
+
+func TestLoadFrom_EnvironmentOverridesProfile(t *testing.T) {
+	cfg, err := LoadFrom(envFrom(map[string]string{
+		"PROFILE":           "raspberry-pi",
+		"LOG_LEVEL":         "debug",
+		"SECURE_COOKIES":    "true",
+		"HTTP_READ_TIMEOUT": "5s",
+	}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Server.LogLevel != slog.LevelDebug || !cfg.Auth.SecureCookies || cfg.Server.ReadTimeout != 5*time.Second {
+		t.Errorf("Expected explicit settings to win over the profile, got %+v %+v", cfg.Server, cfg.Auth)
+	}
+	if cfg.Server.IdleTimeout != 2*time.Minute {
+		t.Errorf("Expected the profile to fill in the rest, got idle timeout %s", cfg.Server.IdleTimeout)
+	}
+}
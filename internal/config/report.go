@@ -0,0 +1,392 @@
+package config
+
+import (
+	"log/slog"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maskedSecret replaces the value of every secret that is set
+const maskedSecret = "********"
+
+// developmentSessionSecret is the session secret used when none is set
+const developmentSessionSecret = "development-secret-change-in-production"
+
+// Auth modes reported by Report
+const (
+	AuthModeGoogle       = "google"
+	AuthModeDemo         = "demo"
+	AuthModeUnconfigured = "unconfigured"
+)
+
+// Report describes what a configuration turns on, for the startup log and
+// the admin environment page. Secrets are never included, only whether they
+// are set.
+type Report struct {
+	Mode        string `json:"mode"`
+	Profile     string `json:"profile,omitempty"`
+	Environment string `json:"environment"`
+	LogLevel    string `json:"log_level"`
+	Port        string `json:"port"`
+	// StorageDriver is file, journal or memory
+	StorageDriver string `json:"storage_driver"`
+	StoragePath   string `json:"storage_path,omitempty"`
+	// AuthMode is demo when demo login is on, google, or unconfigured when
+	// neither is set up
+	AuthMode string `json:"auth_mode"`
+	// Modules are the optional subsystems and whether they run
+	Modules map[string]bool `json:"modules"`
+	// Integrations are the configured external services
+	Integrations map[string]string `json:"integrations"`
+	// Features are behaviour switches
+	Features map[string]bool `json:"features"`
+	// Settings are the effective value of every environment variable, with
+	// secrets masked
+	Settings map[string]string `json:"settings"`
+	Warnings []string          `json:"warnings"`
+}
+
+// Report summarises the configuration
+func (c *Config) Report() Report {
+	report := Report{
+		Mode:        c.Server.Mode,
+		Profile:     c.Server.Profile,
+		Environment: c.Server.Environment,
+		LogLevel:    strings.ToLower(c.Server.LogLevel.String()),
+		Port:        c.Server.Port,
+		AuthMode:    c.AuthMode(),
+		Warnings:    []string{},
+	}
+	if report.Environment == "" {
+		report.Environment = "development"
+	}
+
+	switch {
+	case c.IsDemoMode():
+		// The demo sandbox is reset on purpose, so losing it is not a problem
+		report.StorageDriver = "memory"
+	case c.Storage.DataFile != "":
+		report.StorageDriver, report.StoragePath = "file", c.Storage.DataFile
+	case c.Storage.JournalFile != "":
+		report.StorageDriver, report.StoragePath = "journal", c.Storage.JournalFile
+	default:
+		report.StorageDriver = "memory"
+		report.Warnings = append(report.Warnings, "No DATA_FILE or JOURNAL_FILE set, data is lost on restart")
+	}
+
+	report.Modules = map[string]bool{
+		"push_notifications":    c.Push.Enabled(),
+		"email_reminders":       c.SMTP.Enabled(),
+		"email_digest":          c.SMTP.Enabled() && c.Notifications.DigestEnabled,
+		"email_weekly_report":   c.SMTP.Enabled() && c.Notifications.WeeklyReportEnabled,
+		"slack_notifications":   c.Slack.Enabled(),
+		"discord_notifications": c.Discord.Enabled(),
+		"ntfy_notifications":    c.Ntfy.Enabled(),
+		"telegram_bot":          c.Telegram.Enabled(),
+		"sensors":               c.Sensors.Enabled(),
+		"mqtt_sensors":          c.MQTT.Enabled(),
+		"weather_adjustment":    c.Weather.Enabled(),
+		"sensor_waterings":      c.Sensors.Enabled() && c.Sensors.WateringRise > 0,
+		"escalation":            c.Escalation.Enabled(),
+		"self_update":           c.Update.Enabled(),
+		"automatic_updates":     c.Update.Enabled() && c.Update.CheckInterval > 0,
+		"scheduled_backups":     c.Backup.Enabled(),
+		"tracing":               c.Tracing.Enabled(),
+		"rate_limiting":         c.RateLimit.Enabled(),
+		"capacity_warnings":     c.Server.CapacityWarnDays > 0,
+		"demo_reset":            c.IsDemoMode() && c.Demo.ResetInterval > 0,
+	}
+
+	report.Integrations = map[string]string{}
+	if report.AuthMode == AuthModeGoogle {
+		report.Integrations["google_oauth"] = c.Auth.GoogleClientID
+	}
+	if c.SMTP.Enabled() {
+		report.Integrations["smtp"] = c.SMTP.Host + ":" + strconv.Itoa(c.SMTP.Port)
+	}
+	if c.Push.Enabled() {
+		report.Integrations["web_push"] = c.Push.VAPIDSubject
+	}
+	if c.Slack.Enabled() {
+		// The webhook path is a credential, so only the host is shown
+		if webhook, err := url.Parse(c.Slack.WebhookURL); err == nil {
+			report.Integrations["slack"] = webhook.Host
+		}
+	}
+	if c.Telegram.Enabled() {
+		report.Integrations["telegram"] = "chat " + strconv.FormatInt(c.Telegram.ChatID, 10)
+	}
+	if c.Discord.Enabled() {
+		if webhook, err := url.Parse(c.Discord.WebhookURL); err == nil {
+			report.Integrations["discord"] = webhook.Host
+		}
+	}
+	if c.Ntfy.Enabled() {
+		// The topic is effectively a password on public servers
+		if server, err := url.Parse(c.Ntfy.ServerURL); err == nil {
+			report.Integrations["ntfy"] = server.Host
+		}
+	}
+	if c.Weather.Enabled() {
+		if server, err := url.Parse(c.Weather.APIURL); err == nil {
+			report.Integrations["open_meteo"] = server.Host
+		}
+	}
+	if c.MQTT.Enabled() {
+		if broker, err := url.Parse(c.MQTT.BrokerURL); err == nil {
+			report.Integrations["mqtt"] = broker.Host
+		}
+	}
+	if c.Update.Enabled() {
+		report.Integrations["github_releases"] = c.Update.Repository
+	}
+	if c.Backup.Enabled() {
+		if endpoint, err := url.Parse(c.Backup.Endpoint); err == nil {
+			report.Integrations["backup_bucket"] = endpoint.Host + "/" + c.Backup.Bucket
+		}
+	}
+	if c.Tracing.Enabled() {
+		if endpoint, err := url.Parse(c.Tracing.Endpoint); err == nil {
+			report.Integrations["otlp"] = endpoint.Host
+		}
+	}
+
+	report.Features = map[string]bool{
+		"demo_mode":             c.IsDemoMode(),
+		"demo_login":            c.Auth.DemoMode,
+		"production":            c.IsProduction(),
+		"secure_cookies":        c.Auth.SecureCookies,
+		"recovery":              c.Server.Recovery,
+		"integrity_auto_repair": c.Server.IntegrityAutoRepair,
+		"smoke_test_token":      c.Server.SmokeTestToken != "",
+		"csp":                   !c.CSP.Disabled,
+		"csp_report_only":       !c.CSP.Disabled && c.CSP.ReportOnly,
+		"anonymize_analytics":   c.Privacy.AnonymizeAnalytics,
+		"badge_require_token":   c.Server.BadgeRequireToken,
+		"compression":           c.Server.CompressionMinSize > 0,
+	}
+
+	if report.AuthMode == AuthModeUnconfigured {
+		report.Warnings = append(report.Warnings, "GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET are not set and DEMO_MODE is off, so only credentials saved by the setup wizard let anyone sign in")
+	}
+	if !c.IsDemoMode() && (c.Auth.SessionSecret == "" || c.Auth.SessionSecret == developmentSessionSecret) {
+		report.Warnings = append(report.Warnings, "SESSION_SECRET is not set, sessions use the development default")
+	}
+	if len(c.Demo.Ignored) > 0 {
+		report.Warnings = append(report.Warnings, "Demo mode ignores "+strings.Join(c.Demo.Ignored, ", "))
+	}
+	if c.Privacy.AnonymizeAnalytics && c.Privacy.AnonymizationSalt == "" {
+		report.Warnings = append(report.Warnings, "ANONYMIZATION_SALT is not set, anonymized IDs change on restart")
+	}
+
+	report.Settings = c.settings(true)
+	return report
+}
+
+// AuthMode reports how users sign in: with demo accounts when DEMO_MODE is
+// on, with Google, or not at all until OAuth credentials are configured
+func (c *Config) AuthMode() string {
+	switch {
+	case c.Auth.DemoMode:
+		return AuthModeDemo
+	case c.Auth.GoogleClientID != "" && c.Auth.GoogleClientID != "demo-client-id":
+		return AuthModeGoogle
+	default:
+		return AuthModeUnconfigured
+	}
+}
+
+// settings returns the effective value of every environment variable, with
+// secrets masked if masked is set
+func (c *Config) settings(masked bool) map[string]string {
+	secret := func(value string) string {
+		if value == "" || !masked {
+			return value
+		}
+		return maskedSecret
+	}
+
+	steps := make([]string, len(c.Escalation.Steps))
+	for i, step := range c.Escalation.Steps {
+		steps[i] = step.String()
+	}
+	workingHours := ""
+	if c.Escalation.WorkingHours != nil {
+		workingHours = "set"
+	}
+	trustedProxies := make([]string, len(c.Server.TrustedProxies))
+	for i, prefix := range c.Server.TrustedProxies {
+		trustedProxies[i] = prefix.String()
+	}
+	telegramUsers := make([]string, 0, len(c.Telegram.Users))
+	for id, email := range c.Telegram.Users {
+		telegramUsers = append(telegramUsers, strconv.FormatInt(id, 10)+"="+email)
+	}
+	sort.Strings(telegramUsers)
+	// Header values are usually API keys, so only the names are shown
+	tracingHeaders := make([]string, 0, len(c.Tracing.Headers))
+	for name, value := range c.Tracing.Headers {
+		tracingHeaders = append(tracingHeaders, name+"="+secret(value))
+	}
+	sort.Strings(tracingHeaders)
+	// Device tokens are credentials, so only the placement is shown
+	sensorDevices := make([]string, 0, len(c.Sensors.Devices))
+	for id, device := range c.Sensors.Devices {
+		entry := id + "=" + strconv.Itoa(device.PlantID)
+		if !masked {
+			entry += ":" + device.Token
+		}
+		sensorDevices = append(sensorDevices, entry)
+	}
+	sort.Strings(sensorDevices)
+	telegramChat := ""
+	if c.Telegram.ChatID != 0 {
+		telegramChat = strconv.FormatInt(c.Telegram.ChatID, 10)
+	}
+
+	return map[string]string{
+		"PORT":                        c.Server.Port,
+		"PROFILE":                     c.Server.Profile,
+		"ENVIRONMENT":                 c.Server.Environment,
+		"WATERED_MODE":                c.Server.Mode,
+		"DEMO_MODE":                   strconv.FormatBool(c.Auth.DemoMode),
+		"DEMO_USERS":                  strings.Join(c.Auth.DemoUsers, ","),
+		"WATERED_RECOVERY":            strconv.FormatBool(c.Server.Recovery),
+		"INTEGRITY_AUTO_REPAIR":       strconv.FormatBool(c.Server.IntegrityAutoRepair),
+		"SMOKE_TEST_TOKEN":            secret(c.Server.SmokeTestToken),
+		"CAPACITY_WARN_DAYS":          strconv.Itoa(c.Server.CapacityWarnDays),
+		"LOG_LEVEL":                   strings.ToLower(c.Server.LogLevel.String()),
+		"CLOCK_SKEW_TOLERANCE":        c.Server.ClockSkewTolerance.String(),
+		"UNDO_WATERING_WINDOW":        c.Server.UndoWateringWindow.String(),
+		"HEALTH_CACHE_TTL":            c.Server.HealthCacheTTL.String(),
+		"HEALTH_METRICS_INTERVAL":     c.Server.HealthMetricsInterval.String(),
+		"HEALTH_HISTORY_INTERVAL":     c.Server.HealthHistoryInterval.String(),
+		"HEALTH_HISTORY_RETENTION":    c.Server.HealthHistoryRetention.String(),
+		"HTTP_READ_TIMEOUT":           c.Server.ReadTimeout.String(),
+		"HTTP_WRITE_TIMEOUT":          c.Server.WriteTimeout.String(),
+		"HTTP_IDLE_TIMEOUT":           c.Server.IdleTimeout.String(),
+		"COMPRESSION_MIN_SIZE":        strconv.Itoa(c.Server.CompressionMinSize),
+		"PUBLIC_URL":                  c.Server.PublicURL,
+		"BADGE_REQUIRE_TOKEN":         strconv.FormatBool(c.Server.BadgeRequireToken),
+		"TRUSTED_PROXIES":             strings.Join(trustedProxies, ","),
+		"GOOGLE_CLIENT_ID":            c.Auth.GoogleClientID,
+		"GOOGLE_CLIENT_SECRET":        secret(c.Auth.GoogleClientSecret),
+		"SESSION_SECRET":              secret(c.Auth.SessionSecret),
+		"REDIRECT_URL":                c.Auth.RedirectURL,
+		"ACCESS_TOKEN_TTL":            c.Auth.AccessTokenTTL.String(),
+		"REFRESH_TOKEN_TTL":           c.Auth.RefreshTokenTTL.String(),
+		"SECURE_COOKIES":              strconv.FormatBool(c.Auth.SecureCookies),
+		"ALLOWED_EMAILS":              strings.Join(c.Auth.AllowedEmails, ","),
+		"ADMIN_EMAILS":                strings.Join(c.Auth.AdminEmails, ","),
+		"VIEWER_EMAILS":               strings.Join(c.Auth.ViewerEmails, ","),
+		"DATA_FILE":                   c.Storage.DataFile,
+		"JOURNAL_FILE":                c.Storage.JournalFile,
+		"NOTIFICATION_CHECK_INTERVAL": c.Notifications.CheckInterval.String(),
+		"EMAIL_DIGEST":                strconv.FormatBool(c.Notifications.DigestEnabled),
+		"EMAIL_DIGEST_HOUR":           strconv.Itoa(c.Notifications.DigestHour),
+		"EMAIL_WEEKLY_REPORT":         strconv.FormatBool(c.Notifications.WeeklyReportEnabled),
+		"EMAIL_WEEKLY_REPORT_DAY":     strings.ToLower(c.Notifications.WeeklyReportDay.String()),
+		"SNOOZE_DURATION":             c.Notifications.SnoozeDuration.String(),
+		"VAPID_PUBLIC_KEY":            c.Push.VAPIDPublicKey,
+		"VAPID_PRIVATE_KEY":           secret(c.Push.VAPIDPrivateKey),
+		"VAPID_SUBJECT":               c.Push.VAPIDSubject,
+		"SMTP_HOST":                   c.SMTP.Host,
+		"SMTP_PORT":                   strconv.Itoa(c.SMTP.Port),
+		"SMTP_USER":                   c.SMTP.Username,
+		"SMTP_PASS":                   secret(c.SMTP.Password),
+		"SMTP_FROM":                   c.SMTP.From,
+		"SLACK_WEBHOOK_URL":           secret(c.Slack.WebhookURL),
+		"SLACK_OVERDUE_TEMPLATE":      c.Slack.OverdueTemplate,
+		"SLACK_WATERED_TEMPLATE":      c.Slack.WateredTemplate,
+		"DISCORD_WEBHOOK_URL":         secret(c.Discord.WebhookURL),
+		"NTFY_SERVER_URL":             c.Ntfy.ServerURL,
+		"NTFY_TOPIC":                  secret(c.Ntfy.Topic),
+		"NTFY_TOKEN":                  secret(c.Ntfy.Token),
+		"TELEGRAM_BOT_TOKEN":          secret(c.Telegram.BotToken),
+		"TELEGRAM_CHAT_ID":            telegramChat,
+		"TELEGRAM_WEBHOOK_SECRET":     secret(c.Telegram.WebhookSecret),
+		"TELEGRAM_USERS":              strings.Join(telegramUsers, ","),
+		"SENSOR_DEVICES":              strings.Join(sensorDevices, ","),
+		"SENSOR_WATERING_RISE":        strconv.Itoa(c.Sensors.WateringRise),
+		"SENSOR_WATERING_COOLDOWN":    c.Sensors.WateringCooldown.String(),
+		"WEATHER_LATITUDE":            strconv.FormatFloat(c.Weather.Latitude, 'f', -1, 64),
+		"WEATHER_LONGITUDE":           strconv.FormatFloat(c.Weather.Longitude, 'f', -1, 64),
+		"WEATHER_REFRESH_INTERVAL":    c.Weather.RefreshInterval.String(),
+		"WEATHER_API_URL":             c.Weather.APIURL,
+		"MQTT_BROKER_URL":             c.MQTT.BrokerURL,
+		"MQTT_TOPIC":                  c.MQTT.Topic,
+		"MQTT_USERNAME":               c.MQTT.Username,
+		"MQTT_PASSWORD":               secret(c.MQTT.Password),
+		"MQTT_CLIENT_ID":              c.MQTT.ClientID,
+		"BUTTON_DEBOUNCE":             c.Buttons.Debounce.String(),
+		"CSP_DISABLED":                strconv.FormatBool(c.CSP.Disabled),
+		"CSP_REPORT_ONLY":             strconv.FormatBool(c.CSP.ReportOnly),
+		"CSP_REPORT_URI":              c.CSP.ReportURI,
+		"ANONYMIZE_ANALYTICS":         strconv.FormatBool(c.Privacy.AnonymizeAnalytics),
+		"ANONYMIZATION_SALT":          secret(c.Privacy.AnonymizationSalt),
+		"FEATURE_NOTIFICATIONS":       strconv.FormatBool(c.Features.Notifications),
+		"FEATURE_SENSORS":             strconv.FormatBool(c.Features.Sensors),
+		"FEATURE_MULTI_PLANT":         strconv.FormatBool(c.Features.MultiPlant),
+		"UPDATE_PUBLIC_KEY":           c.Update.PublicKey,
+		"UPDATE_REPOSITORY":           c.Update.Repository,
+		"UPDATE_CHECK_INTERVAL":       c.Update.CheckInterval.String(),
+		"BACKUP_BUCKET":               c.Backup.Bucket,
+		"BACKUP_ENDPOINT":             c.Backup.Endpoint,
+		"BACKUP_REGION":               c.Backup.Region,
+		"BACKUP_ACCESS_KEY":           c.Backup.AccessKey,
+		"BACKUP_SECRET_KEY":           secret(c.Backup.SecretKey),
+		"BACKUP_PREFIX":               c.Backup.Prefix,
+		"BACKUP_INTERVAL":             c.Backup.Interval.String(),
+		"BACKUP_RETAIN":               strconv.Itoa(c.Backup.Retain),
+		"OTEL_EXPORTER_OTLP_ENDPOINT": c.Tracing.Endpoint,
+		"OTEL_EXPORTER_OTLP_HEADERS":  strings.Join(tracingHeaders, ","),
+		"OTEL_SERVICE_NAME":           c.Tracing.ServiceName,
+		"OTEL_TRACES_SAMPLER_ARG":     strconv.FormatFloat(c.Tracing.SampleRatio, 'g', -1, 64),
+		"RATE_LIMIT":                  strconv.Itoa(c.RateLimit.Limit),
+		"RATE_LIMIT_WARN":             strconv.Itoa(c.RateLimit.Warn),
+		"RATE_LIMIT_WINDOW":           c.RateLimit.Window.String(),
+		"RATE_LIMIT_AUTH_BURST":       strconv.Itoa(c.RateLimit.Auth.Burst),
+		"RATE_LIMIT_AUTH_REFILL":      c.RateLimit.Auth.Refill.String(),
+		"RATE_LIMIT_WRITE_BURST":      strconv.Itoa(c.RateLimit.Write.Burst),
+		"RATE_LIMIT_WRITE_REFILL":     c.RateLimit.Write.Refill.String(),
+		"AUTH_LOCKOUT_THRESHOLD":      strconv.Itoa(c.RateLimit.Lockout.Threshold),
+		"AUTH_LOCKOUT_BASE":           c.RateLimit.Lockout.Base.String(),
+		"AUTH_LOCKOUT_MAX":            c.RateLimit.Lockout.Max.String(),
+		"ESCALATION_CHAIN":            strings.Join(steps, ", "),
+		"ESCALATION_WORKING_HOURS":    workingHours,
+		"DEMO_RESET_INTERVAL":         c.Demo.ResetInterval.String(),
+	}
+}
+
+// LogValue logs the report as one group, listing only the modules and
+// features that are on. Settings and warnings are left out; warnings are
+// logged on their own lines.
+func (r Report) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("mode", r.Mode),
+		slog.String("profile", r.Profile),
+		slog.String("environment", r.Environment),
+		slog.String("log_level", r.LogLevel),
+		slog.String("port", r.Port),
+		slog.String("storage_driver", r.StorageDriver),
+		slog.String("storage_path", r.StoragePath),
+		slog.String("auth_mode", r.AuthMode),
+		slog.Any("modules", enabled(r.Modules)),
+		slog.Any("integrations", r.Integrations),
+		slog.Any("features", enabled(r.Features)),
+	)
+}
+
+// enabled returns the sorted names of the switches that are on
+func enabled(switches map[string]bool) []string {
+	names := []string{}
+	for name, on := range switches {
+		if on {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestReport_Defaults(t *testing.T) {
+	report := Default().Report()
+
+	if report.StorageDriver != "memory" || report.AuthMode != AuthModeUnconfigured || report.Environment != "development" {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if report.Modules["email_reminders"] || report.Modules["push_notifications"] || !report.Modules["rate_limiting"] {
+		t.Errorf("Unexpected modules: %v", report.Modules)
+	}
+	if len(report.Integrations) != 0 {
+		t.Errorf("Expected no integrations, got %v", report.Integrations)
+	}
+	if len(report.Warnings) != 3 {
+		t.Errorf("Expected storage, sign-in and session secret warnings, got %v", report.Warnings)
+	}
+}
+
+func TestReport_MasksSecrets(t *testing.T) {
+	cfg, err := LoadFrom(envFrom(map[string]string{
+		"GOOGLE_CLIENT_ID":        "client-id",
+		"GOOGLE_CLIENT_SECRET":    "google-secret",
+		"SESSION_SECRET":          "session-secret",
+		"DATA_FILE":               "/data/watered.json",
+		"SMTP_HOST":               "smtp.example.com",
+		"SMTP_USER":               "bot@example.com",
+		"SMTP_PASS":               "smtp-secret",
+		"SMOKE_TEST_TOKEN":        "smoke-secret",
+		"ANONYMIZE_ANALYTICS":     "true",
+		"ANONYMIZATION_SALT":      "salt-secret",
+		"SLACK_WEBHOOK_URL":       "https://hooks.slack.com/services/T000/B000/secret",
+		"DISCORD_WEBHOOK_URL":     "https://discord.com/api/webhooks/123/secret",
+		"TELEGRAM_BOT_TOKEN":      "123:secret",
+		"TELEGRAM_CHAT_ID":        "-100123",
+		"TELEGRAM_WEBHOOK_SECRET": "webhook-secret",
+		"NTFY_SERVER_URL":         "https://ntfy.example.com",
+		"NTFY_TOPIC":              "secret-topic",
+		"NTFY_TOKEN":              "tk_secret",
+		"SENSOR_DEVICES":          "kitchen=1:sensor-secret",
+		"MQTT_BROKER_URL":         "tcp://mqtt.example.com:1883",
+		"MQTT_PASSWORD":           "mqtt-secret",
+	}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	report := cfg.Report()
+	if report.StorageDriver != "file" || report.StoragePath != "/data/watered.json" || report.AuthMode != AuthModeGoogle {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if report.Integrations["smtp"] != "smtp.example.com:587" || report.Integrations["google_oauth"] != "client-id" || report.Integrations["slack"] != "hooks.slack.com" || report.Integrations["discord"] != "discord.com" || report.Integrations["telegram"] != "chat -100123" || report.Integrations["ntfy"] != "ntfy.example.com" || report.Integrations["mqtt"] != "mqtt.example.com:1883" {
+		t.Errorf("Unexpected integrations: %v", report.Integrations)
+	}
+	if !report.Modules["email_reminders"] || !report.Modules["slack_notifications"] || !report.Modules["discord_notifications"] || !report.Modules["ntfy_notifications"] || !report.Modules["telegram_bot"] || !report.Modules["sensors"] || !report.Modules["mqtt_sensors"] || !report.Features["smoke_test_token"] || !report.Features["anonymize_analytics"] {
+		t.Errorf("Unexpected modules %v or features %v", report.Modules, report.Features)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", report.Warnings)
+	}
+
+	for key, value := range report.Settings {
+		if strings.Contains(value, "secret") {
+			t.Errorf("Expected %s to be masked, got %q", key, value)
+		}
+	}
+	if report.Settings["SMTP_PASS"] != maskedSecret || report.Settings["VAPID_PRIVATE_KEY"] != "" || report.Settings["SMTP_HOST"] != "smtp.example.com" || report.Settings["SENSOR_DEVICES"] != "kitchen=1" {
+		t.Errorf("Unexpected settings: %v", report.Settings)
+	}
+}
+
+func TestReport_DemoSandbox(t *testing.T) {
+	cfg, err := LoadFrom(envFrom(map[string]string{"WATERED_MODE": "demo", "JOURNAL_FILE": "/data/watered.journal"}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	report := cfg.Report()
+
+	if report.StorageDriver != "memory" || report.AuthMode != AuthModeDemo || !report.Modules["demo_reset"] {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0] != "Demo mode ignores JOURNAL_FILE" {
+		t.Errorf("Expected only the ignored settings warning, got %v", report.Warnings)
+	}
+	if report.Settings["DEMO_RESET_INTERVAL"] != "1h0m0s" {
+		t.Errorf("Unexpected settings: %v", report.Settings)
+	}
+}
+
+func TestReport_LogValue(t *testing.T) {
+	var out strings.Builder
+	slog.New(slog.NewJSONHandler(&out, nil)).Info("Startup report", "config", Default().Report())
+
+	line := out.String()
+	if !strings.Contains(line, `"storage_driver":"memory"`) || !strings.Contains(line, `"modules":["capacity_warnings","rate_limiting"]`) {
+		t.Errorf("Unexpected log line: %s", line)
+	}
+	if strings.Contains(line, `"settings"`) || strings.Contains(line, `"warnings"`) {
+		t.Errorf("Expected settings and warnings to be left out, got %s", line)
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sensorDevicePattern matches device IDs, which appear in the ingestion URL
+var sensorDevicePattern = regexp.MustCompile(`^[-_A-Za-z0-9]{1,64}$`)
+
+// SensorConfig holds the soil sensors allowed to report readings
+type SensorConfig struct {
+	Devices map[string]SensorDevice // SENSOR_DEVICES
+	// WateringRise is how many percentage points moisture must rise within
+	// an hour to record a watering, 0 turns detection off
+	WateringRise int // SENSOR_WATERING_RISE
+	// WateringCooldown is how close to another watering no watering is
+	// detected, so noisy readings cannot record the same watering twice
+	WateringCooldown time.Duration // SENSOR_WATERING_COOLDOWN
+}
+
+// SensorDevice is a soil sensor placed in one plant's pot
+type SensorDevice struct {
+	PlantID int
+	// Token authenticates the device's readings
+	Token string
+}
+
+// Enabled reports whether any sensor is configured
+func (c SensorConfig) Enabled() bool {
+	return len(c.Devices) > 0
+}
+
+// MQTTConfig holds the MQTT broker that sensors publish readings to
+type MQTTConfig struct {
+	// BrokerURL is tcp://host:1883, or ssl://host:8883 for TLS
+	BrokerURL string // MQTT_BROKER_URL
+	// Topic is the filter subscribed to; its + level is the device ID
+	Topic    string // MQTT_TOPIC
+	Username string // MQTT_USERNAME
+	Password string // MQTT_PASSWORD
+	ClientID string // MQTT_CLIENT_ID
+}
+
+// Enabled reports whether an MQTT broker is configured
+func (c MQTTConfig) Enabled() bool {
+	return c.BrokerURL != ""
+}
+
+// TLS reports whether the broker is reached over TLS
+func (c MQTTConfig) TLS() bool {
+	broker, err := url.Parse(c.BrokerURL)
+	return err == nil && (broker.Scheme == "ssl" || broker.Scheme == "tls" || broker.Scheme == "mqtts")
+}
+
+// ButtonConfig holds the settings for "I watered it" buttons, which are
+// registered as devices
+type ButtonConfig struct {
+	// Debounce is how soon after a watering a press is ignored, so a bouncing
+	// contact or an impatient finger records one watering
+	Debounce time.Duration // BUTTON_DEBOUNCE
+}
+
+// validate returns the problems with the broker URL and topic filter
+func (c MQTTConfig) validate() []string {
+	var problems []string
+	broker, err := url.Parse(c.BrokerURL)
+	if err != nil || broker.Host == "" || (broker.Scheme != "tcp" && broker.Scheme != "mqtt" && !c.TLS()) {
+		problems = append(problems, fmt.Sprintf("MQTT_BROKER_URL must be a tcp:// or ssl:// URL, got %q", c.BrokerURL))
+	}
+
+	wildcards := 0
+	for _, level := range strings.Split(c.Topic, "/") {
+		if level == "+" {
+			wildcards++
+		}
+	}
+	if wildcards != 1 || strings.Contains(c.Topic, "#") || strings.Count(c.Topic, "+") != 1 {
+		problems = append(problems, fmt.Sprintf("MQTT_TOPIC must have exactly one + level for the device ID and no #, got %q", c.Topic))
+	}
+	return problems
+}
+
+// ParseSensorDevices parses a comma-separated list of devices, each a device
+// ID, the plant it sits in and its token, such as
+// "kitchen=1:s3cret, balcony=3:0ther"
+func ParseSensorDevices(value string) (map[string]SensorDevice, error) {
+	devices := make(map[string]SensorDevice)
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		id, rest, ok := strings.Cut(field, "=")
+		plant, token, hasToken := strings.Cut(rest, ":")
+		if !ok || !hasToken {
+			return nil, fmt.Errorf("entry %q must look like device=plant:token", field)
+		}
+		id = strings.TrimSpace(id)
+		if !sensorDevicePattern.MatchString(id) {
+			return nil, fmt.Errorf("entry %q has an invalid device ID, use letters, digits, - and _", field)
+		}
+		plantID, err := strconv.Atoi(strings.TrimSpace(plant))
+		if err != nil || plantID <= 0 {
+			return nil, fmt.Errorf("entry %q has an invalid plant ID", field)
+		}
+		token = strings.TrimSpace(token)
+		if len(token) < 8 {
+			return nil, fmt.Errorf("entry %q needs a token of at least 8 characters", field)
+		}
+		if _, exists := devices[id]; exists {
+			return nil, fmt.Errorf("device %s is listed more than once", id)
+		}
+		devices[id] = SensorDevice{PlantID: plantID, Token: token}
+	}
+	return devices, nil
+}
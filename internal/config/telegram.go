@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+)
+
+// TelegramConfig holds the Telegram bot that posts reminders to a chat and
+// accepts /watered commands
+type TelegramConfig struct {
+	BotToken string // TELEGRAM_BOT_TOKEN
+	ChatID   int64  // TELEGRAM_CHAT_ID
+	// WebhookSecret must match the secret_token the webhook was registered
+	// with, so only Telegram can deliver commands
+	WebhookSecret string // TELEGRAM_WEBHOOK_SECRET
+	// Users maps Telegram user IDs to the Watered users they act as
+	Users map[int64]string // TELEGRAM_USERS
+}
+
+// Enabled reports whether a Telegram bot is configured
+func (c TelegramConfig) Enabled() bool {
+	return c.BotToken != ""
+}
+
+// ParseTelegramUsers parses a comma-separated list of Telegram user IDs and
+// emails such as "123456789=alice@example.com, 987654321=bob@example.com"
+func ParseTelegramUsers(value string) (map[int64]string, error) {
+	users := make(map[int64]string)
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		id, email, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must be a Telegram user ID and an email joined by =", field)
+		}
+		userID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if err != nil || userID <= 0 {
+			return nil, fmt.Errorf("entry %q has an invalid Telegram user ID", field)
+		}
+		email = strings.ToLower(strings.TrimSpace(email))
+		if _, err := mail.ParseAddress(email); err != nil {
+			return nil, fmt.Errorf("entry %q has an invalid email", field)
+		}
+		if _, exists := users[userID]; exists {
+			return nil, fmt.Errorf("Telegram user %d is listed more than once", userID)
+		}
+		users[userID] = email
+	}
+	return users, nil
+}
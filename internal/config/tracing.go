@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TracingConfig holds the OTLP/HTTP endpoint request traces are sent to,
+// such as an OpenTelemetry collector or Jaeger. The variables are the
+// standard OpenTelemetry ones.
+type TracingConfig struct {
+	// Endpoint is the collector's base URL; spans are posted to /v1/traces
+	// below it
+	Endpoint string // OTEL_EXPORTER_OTLP_ENDPOINT
+	// Headers are sent with every export, such as an API key
+	Headers     map[string]string // OTEL_EXPORTER_OTLP_HEADERS, key=value pairs separated by commas
+	ServiceName string            // OTEL_SERVICE_NAME
+	// SampleRatio is the fraction of new traces recorded. Requests that
+	// carry a traceparent header follow the caller's decision.
+	SampleRatio float64 // OTEL_TRACES_SAMPLER_ARG
+}
+
+// Enabled reports whether an endpoint is configured
+func (c TracingConfig) Enabled() bool {
+	return c.Endpoint != ""
+}
+
+// headers parses a list of key=value pairs whose values may be URL-encoded,
+// as OpenTelemetry specifies for OTEL_EXPORTER_OTLP_HEADERS
+func (l *loader) headers(key string) map[string]string {
+	value := strings.TrimSpace(l.getenv(key))
+	if value == "" {
+		return nil
+	}
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		name, raw, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		decoded, err := url.PathUnescape(strings.TrimSpace(raw))
+		if !ok || name == "" || err != nil {
+			l.problems = append(l.problems, fmt.Sprintf("%s must be key=value pairs separated by commas, got %q", key, pair))
+			continue
+		}
+		headers[name] = decoded
+	}
+	return headers
+}
+
+// ratio parses a fraction between 0 and 1
+func (l *loader) ratio(key string, fallback float64) float64 {
+	value := strings.TrimSpace(l.getenv(key))
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 || parsed > 1 {
+		l.problems = append(l.problems, fmt.Sprintf("%s must be a number between 0 and 1, got %q", key, value))
+		return fallback
+	}
+	return parsed
+}
+
+// validate returns the problems with the tracing settings
+func (c TracingConfig) validate() []string {
+	var problems []string
+	if endpoint, err := url.Parse(c.Endpoint); err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		problems = append(problems, fmt.Sprintf("OTEL_EXPORTER_OTLP_ENDPOINT must be an http or https URL, got %q", c.Endpoint))
+	}
+	if c.ServiceName == "" {
+		problems = append(problems, "OTEL_SERVICE_NAME must not be empty")
+	}
+	return problems
+}
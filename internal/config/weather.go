@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WeatherConfig holds the location whose Open-Meteo forecast adjusts the
+// watering timeout of outdoor plants
+type WeatherConfig struct {
+	Latitude  float64 // WEATHER_LATITUDE
+	Longitude float64 // WEATHER_LONGITUDE
+	// RefreshInterval is how often the forecast is fetched
+	RefreshInterval time.Duration // WEATHER_REFRESH_INTERVAL
+	// APIURL is the Open-Meteo server, which can be self-hosted
+	APIURL string // WEATHER_API_URL
+}
+
+// Enabled reports whether a location is configured. The coordinates 0,0
+// lie in the open ocean, so they mean no location.
+func (c WeatherConfig) Enabled() bool {
+	return c.Latitude != 0 || c.Longitude != 0
+}
+
+// coordinate parses a latitude or longitude of at most limit degrees either way
+func (l *loader) coordinate(key string, limit float64) float64 {
+	value := strings.TrimSpace(l.getenv(key))
+	if value == "" {
+		return 0
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < -limit || parsed > limit {
+		l.problems = append(l.problems, fmt.Sprintf("%s must be a number between %g and %g, got %q", key, -limit, limit, value))
+		return 0
+	}
+	return parsed
+}
+
+// validate returns the problems with the weather settings
+func (c WeatherConfig) validate() []string {
+	var problems []string
+	if c.RefreshInterval <= 0 {
+		problems = append(problems, fmt.Sprintf("WEATHER_REFRESH_INTERVAL must be positive, got %s", c.RefreshInterval))
+	}
+	if server, err := url.Parse(c.APIURL); err != nil || (server.Scheme != "https" && server.Scheme != "http") || server.Host == "" {
+		problems = append(problems, fmt.Sprintf("WEATHER_API_URL must be an http or https URL, got %q", c.APIURL))
+	}
+	return problems
+}
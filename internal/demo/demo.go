@@ -0,0 +1,137 @@
+// Package demo runs the sandbox behind WATERED_MODE=demo. The sandbox lives in
+// its own in-memory store, starts with a sample household and is put back to
+// that state on a schedule, so visitors to a public demo always find the same
+// plants and nobody's changes stick around.
+package demo
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+// AdminEmail is the sample household's admin
+const AdminEmail = "admin@example.com"
+
+// Users is the sample household. The emails match the demo accounts that
+// may sign in without Google.
+var Users = []models.User{
+	{Email: AdminEmail, Name: "Demo Admin", IsAdmin: true},
+	{Email: "demo@example.com", Name: "Demo"},
+	{Email: "test@example.com", Name: "Demo User"},
+	{Email: "user1@example.com", Name: "Avery"},
+	{Email: "user2@example.com", Name: "Jordan"},
+}
+
+// samplePlant is a seeded plant and how long ago it was last watered
+type samplePlant struct {
+	name             string
+	timeoutHours     int
+	gracePeriodHours int
+	wateredAgo       time.Duration
+}
+
+// plants cover every health status, so the dashboard shows each badge
+var plants = []samplePlant{
+	{name: "Our Plant", timeoutHours: 24, wateredAgo: 6 * time.Hour},
+	{name: "Fern", timeoutHours: 48, gracePeriodHours: 12, wateredAgo: 40 * time.Hour},
+	{name: "Basil", timeoutHours: 24, gracePeriodHours: 12, wateredAgo: 28 * time.Hour},
+	{name: "Cactus", timeoutHours: 24 * 14, wateredAgo: 20 * 24 * time.Hour},
+}
+
+// historyDays is how far back the sample watering history goes
+const historyDays = 30
+
+// Seed fills an empty store with the sample household: its users and admin
+// settings, a few plants and a month of watering history
+func Seed(store storage.Storage, now time.Time) error {
+	allowed := make([]string, 0, len(Users))
+	for _, user := range Users {
+		allowed = append(allowed, user.Email)
+	}
+	if err := store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:   24,
+		AllowedEmails:  allowed,
+		AdminEmails:    []string{AdminEmail},
+		Timezone:       "UTC",
+		SetupCompleted: true,
+		LastModified:   now,
+		ModifiedBy:     AdminEmail,
+	}); err != nil {
+		return fmt.Errorf("failed to seed admin config: %w", err)
+	}
+
+	for _, user := range Users {
+		user.JoinedAt = now.Add(-historyDays * 24 * time.Hour)
+		if err := store.CreateUser(&user); err != nil {
+			return fmt.Errorf("failed to seed user %s: %w", user.Email, err)
+		}
+	}
+
+	// Waterings rotate through the household, oldest first, so the history
+	// and the leaderboard have something to show
+	waterer := 0
+	for i, sample := range plants {
+		lastWatered := now.Add(-sample.wateredAgo)
+		plant := &models.PlantState{
+			ID:               models.DefaultPlantID + i,
+			Name:             sample.name,
+			LastWatered:      &lastWatered,
+			TimeoutHours:     sample.timeoutHours,
+			GracePeriodHours: sample.gracePeriodHours,
+			CreatedAt:        now.Add(-historyDays * 24 * time.Hour),
+			UpdatedAt:        lastWatered,
+		}
+
+		interval := time.Duration(sample.timeoutHours) * time.Hour * 9 / 10
+		var waterings []time.Time
+		for at := lastWatered; now.Sub(at) < historyDays*24*time.Hour; at = at.Add(-interval) {
+			waterings = append([]time.Time{at}, waterings...)
+		}
+		for _, at := range waterings {
+			wateredBy := Users[waterer%len(Users)].Email
+			waterer++
+			plant.WateredBy = wateredBy
+			if err := store.AddWateringEvent(&models.PlantWateringEvent{PlantID: plant.ID, WateredAt: at, WateredBy: wateredBy}); err != nil {
+				return fmt.Errorf("failed to seed watering history: %w", err)
+			}
+		}
+
+		if err := store.UpdatePlantState(plant); err != nil {
+			return fmt.Errorf("failed to seed plant %s: %w", sample.name, err)
+		}
+	}
+	return nil
+}
+
+// Sandbox owns the demo store and puts it back to the sample data
+type Sandbox struct {
+	store *storage.MemoryStorage
+	now   func() time.Time
+}
+
+// NewSandbox creates a sandbox over store, which must be a plain in-memory
+// store so that resetting it cannot touch a data file
+func NewSandbox(store storage.Storage) (*Sandbox, error) {
+	memory, ok := store.(*storage.MemoryStorage)
+	if !ok {
+		return nil, errors.New("demo mode needs an in-memory store")
+	}
+	return &Sandbox{store: memory, now: time.Now}, nil
+}
+
+// Reset wipes everything visitors changed and seeds the sample data again
+func (s *Sandbox) Reset() error {
+	if err := s.store.Reset(); err != nil {
+		return fmt.Errorf("failed to reset demo store: %w", err)
+	}
+	if err := Seed(s.store, s.now()); err != nil {
+		return err
+	}
+	slog.Info("Demo sandbox reset", "audit", true)
+	return nil
+}
@@ -0,0 +1,97 @@
+package demo
+
+import (
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func TestSeed(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	now := time.Now()
+	if err := Seed(store, now); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+
+	config, _ := store.GetAdminConfig()
+	if config == nil || !config.SetupCompleted || len(config.AllowedEmails) != len(Users) || config.AdminEmails[0] != AdminEmail {
+		t.Errorf("Unexpected admin config: %+v", config)
+	}
+	if user, _ := store.GetUser("user1@example.com"); user == nil || user.Name != "Avery" {
+		t.Errorf("Expected the sample users, got %+v", user)
+	}
+
+	plants, _ := store.ListPlants()
+	if len(plants) != 4 || plants[0].ID != models.DefaultPlantID || plants[0].Name != "Our Plant" {
+		t.Fatalf("Unexpected plants: %+v", plants)
+	}
+	statuses := map[models.PlantHealthStatus]bool{}
+	for _, plant := range plants {
+		statuses[plant.GetHealthStatus()] = true
+	}
+	for _, status := range []models.PlantHealthStatus{models.HealthStatusHealthy, models.HealthStatusNeedsWater, models.HealthStatusDue, models.HealthStatusCritical} {
+		if !statuses[status] {
+			t.Errorf("Expected a plant that is %s", status)
+		}
+	}
+
+	// The last watering in the history is the plant's current state
+	events, _ := store.ListWateringEvents(models.DefaultPlantID)
+	if len(events) < 20 {
+		t.Fatalf("Expected a month of history, got %d waterings", len(events))
+	}
+	last := events[len(events)-1]
+	if !last.WateredAt.Equal(*plants[0].LastWatered) || last.WateredBy != plants[0].WateredBy {
+		t.Errorf("Expected the latest watering %+v to match the plant %+v", last, plants[0])
+	}
+	if events[0].WateredAt.Before(now.Add(-historyDays * 24 * time.Hour)) {
+		t.Errorf("Expected history within %d days, got %v", historyDays, events[0].WateredAt)
+	}
+}
+
+func TestSandbox_Reset(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	sandbox, err := NewSandbox(store)
+	if err != nil {
+		t.Fatalf("Failed to create sandbox: %v", err)
+	}
+	if err := sandbox.Reset(); err != nil {
+		t.Fatalf("Failed to reset: %v", err)
+	}
+
+	// A visitor renames a plant, adds one and invites a friend
+	plant, _ := store.GetPlant(models.DefaultPlantID)
+	plant.Name = "Renamed"
+	store.UpdatePlant(plant)
+	store.CreatePlant(&models.PlantState{Name: "Visitor's plant"})
+	store.CreateUser(&models.User{Email: "visitor@example.com"})
+
+	if err := sandbox.Reset(); err != nil {
+		t.Fatalf("Failed to reset: %v", err)
+	}
+	plants, _ := store.ListPlants()
+	if len(plants) != 4 || plants[0].Name != "Our Plant" {
+		t.Errorf("Expected the sample plants back, got %+v", plants)
+	}
+	if user, _ := store.GetUser("visitor@example.com"); user != nil {
+		t.Errorf("Expected the visitor's account to be gone, got %+v", user)
+	}
+}
+
+func TestNewSandbox_RequiresMemoryStorage(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir() + "/watered.json")
+	if err != nil {
+		t.Fatalf("Failed to open file storage: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := NewSandbox(store); err == nil {
+		t.Error("Expected a file store to be rejected")
+	}
+}
@@ -0,0 +1,109 @@
+package demo
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Header marks every response served by the demo sandbox
+const Header = "X-Watered-Demo"
+
+// Middleware labels responses as coming from the demo sandbox. JSON objects
+// get a "demo": true field, so API clients cannot mistake sample data for a
+// real household; every other response carries the X-Watered-Demo header.
+// Streaming responses such as server-sent events pass through untouched.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(Header, "true")
+		lw := &labelWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(lw, r)
+		lw.finish()
+	})
+}
+
+// labelWriter buffers JSON responses so the demo field can be added to them
+// and passes anything else straight through
+type labelWriter struct {
+	http.ResponseWriter
+	status  int
+	decided bool
+	buffer  *bytes.Buffer
+}
+
+// decide chooses between buffering and passing through once the handler has
+// set its headers
+func (w *labelWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.buffer = &bytes.Buffer{}
+	}
+}
+
+func (w *labelWriter) WriteHeader(status int) {
+	w.decide()
+	if w.buffer != nil {
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *labelWriter) Write(p []byte) (int, error) {
+	w.decide()
+	if w.buffer != nil {
+		return w.buffer.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends buffered output to the client, except for JSON responses,
+// which are only complete once the handler returns
+func (w *labelWriter) Flush() {
+	w.decide()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && w.buffer == nil {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets WebSocket upgrades through
+func (w *labelWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	w.decided = true
+	return hijacker.Hijack()
+}
+
+// finish writes a buffered JSON response with the demo field added
+func (w *labelWriter) finish() {
+	if w.buffer == nil {
+		return
+	}
+	body := Label(w.buffer.Bytes())
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(body)
+}
+
+// Label adds "demo": true to a JSON object. Other JSON values, such as
+// arrays, are returned unchanged.
+func Label(body []byte) []byte {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return body
+	}
+	rest := bytes.TrimLeft(trimmed[1:], " \t\r\n")
+	if len(rest) > 0 && rest[0] == '}' {
+		return append([]byte(`{"demo":true`), rest...)
+	}
+	return append([]byte(`{"demo":true,`), trimmed[1:]...)
+}
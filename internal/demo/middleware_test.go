@@ -0,0 +1,71 @@
+package demo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"status":"healthy"}`, `{"demo":true,"status":"healthy"}`},
+		{"{\n  \"a\": 1\n}\n", "{\"demo\":true,\n  \"a\": 1\n}\n"},
+		{`{}`, `{"demo":true}`},
+		{`{ }`, `{"demo":true}`},
+		{`[1,2]`, `[1,2]`},
+		{``, ``},
+	}
+	for _, tt := range tests {
+		if got := string(Label([]byte(tt.body))); got != tt.want {
+			t.Errorf("Label(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/plant", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"name": "Fern"})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<h1>Watered</h1>"))
+	})
+	mux.HandleFunc("/api/plant/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {}\n\n"))
+		w.(http.Flusher).Flush()
+	})
+	handler := Middleware(mux)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plant", nil))
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected valid JSON, got %q: %v", rec.Body.String(), err)
+	}
+	if rec.Code != http.StatusCreated || body["demo"] != true || body["name"] != "Fern" {
+		t.Errorf("Expected a labeled 201 response, got %d %v", rec.Code, body)
+	}
+	if rec.Header().Get("Content-Length") != "28" {
+		t.Errorf("Unexpected Content-Length %q", rec.Header().Get("Content-Length"))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Body.String() != "<h1>Watered</h1>" || rec.Header().Get(Header) != "true" {
+		t.Errorf("Expected HTML untouched with the demo header, got %q %v", rec.Body.String(), rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plant/events", nil))
+	if rec.Body.String() != "data: {}\n\n" || !rec.Flushed {
+		t.Errorf("Expected events to stream untouched, got %q (flushed %v)", rec.Body.String(), rec.Flushed)
+	}
+}
@@ -0,0 +1,159 @@
+// Package features holds the feature flags that let risky subsystems be
+// rolled out gradually. Each flag defaults to its FEATURE_* environment
+// variable, and admins can switch it on or off at runtime; the override is
+// stored in the admin configuration so it survives restarts.
+package features
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/respond"
+)
+
+// Flag names a feature that can be switched on or off
+type Flag string
+
+// Feature flags. A new subsystem gets a flag here and in
+// config.FeatureConfig, off by default until it has proven itself.
+const (
+	Notifications Flag = "notifications"
+	Sensors       Flag = "sensors"
+	MultiPlant    Flag = "multi_plant"
+)
+
+// Definition describes a flag and where its default comes from
+type Definition struct {
+	Flag        Flag
+	Description string
+	defaultFrom func(config.FeatureConfig) bool
+}
+
+// Definitions lists every flag, in the order the admin page shows them
+var Definitions = []Definition{
+	{Notifications, "Overdue reminders, digests and watering notifications on every channel", func(c config.FeatureConfig) bool { return c.Notifications }},
+	{Sensors, "Soil sensor readings over HTTP and MQTT, and the waterings they detect", func(c config.FeatureConfig) bool { return c.Sensors }},
+	{MultiPlant, "Adding plants beyond the first", func(c config.FeatureConfig) bool { return c.MultiPlant }},
+}
+
+// Known reports whether name is a flag
+func Known(name string) bool {
+	for _, definition := range Definitions {
+		if string(definition.Flag) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigSource reads the admin configuration holding the overrides
+type ConfigSource interface {
+	GetAdminConfig() (*models.AdminConfig, error)
+}
+
+// State is a flag's current setting
+type State struct {
+	Name        Flag   `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	// Default is the FEATURE_* setting, used when no admin overrode it
+	Default    bool `json:"default"`
+	Overridden bool `json:"overridden"`
+}
+
+// Flags answers whether each feature is on
+type Flags struct {
+	source   ConfigSource
+	defaults map[Flag]bool
+}
+
+// New creates flags defaulting to cfg and overridden by the admin
+// configuration in source
+func New(source ConfigSource, cfg config.FeatureConfig) *Flags {
+	defaults := make(map[Flag]bool, len(Definitions))
+	for _, definition := range Definitions {
+		defaults[definition.Flag] = definition.defaultFrom(cfg)
+	}
+	return &Flags{source: source, defaults: defaults}
+}
+
+// Enabled reports whether flag is on. The override is read on every call,
+// so a toggle applies at once. If the admin configuration cannot be read
+// the default applies. A nil Flags has every feature on.
+func (f *Flags) Enabled(flag Flag) bool {
+	if f == nil {
+		return true
+	}
+	overrides, err := f.overrides()
+	if err != nil {
+		slog.Warn("Failed to read feature flag overrides, using defaults", "flag", flag, "error", err)
+	}
+	if enabled, ok := overrides[string(flag)]; ok {
+		return enabled
+	}
+	return f.defaults[flag]
+}
+
+// List returns the state of every flag
+func (f *Flags) List() ([]State, error) {
+	overrides, err := f.overrides()
+	if err != nil {
+		return nil, err
+	}
+	states := make([]State, 0, len(Definitions))
+	for _, definition := range Definitions {
+		state := State{
+			Name:        definition.Flag,
+			Description: definition.Description,
+			Enabled:     f.defaults[definition.Flag],
+			Default:     f.defaults[definition.Flag],
+		}
+		if enabled, ok := overrides[string(definition.Flag)]; ok {
+			state.Enabled, state.Overridden = enabled, true
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// overrides returns the flags an admin switched on or off
+func (f *Flags) overrides() (map[string]bool, error) {
+	config, err := f.source.GetAdminConfig()
+	if err != nil || config == nil {
+		return nil, err
+	}
+	return config.Features, nil
+}
+
+// Check returns a func reporting whether flag is on, for components that
+// must not depend on this package
+func (f *Flags) Check(flag Flag) func() bool {
+	return func() bool { return f.Enabled(flag) }
+}
+
+// Gate wraps a background job so it does nothing while flag is off
+func (f *Flags) Gate(flag Flag, run func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if !f.Enabled(flag) {
+			return nil
+		}
+		return run(ctx)
+	}
+}
+
+// Require answers 404 for routes of a feature that is switched off, as if
+// they did not exist
+func (f *Flags) Require(flag Flag) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !f.Enabled(flag) {
+				respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "The "+string(flag)+" feature is switched off")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package features
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func TestFlags_Enabled(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	flags := New(store, config.FeatureConfig{Notifications: true})
+
+	if !flags.Enabled(Notifications) || flags.Enabled(Sensors) {
+		t.Error("Expected the FEATURE_* defaults without an admin configuration")
+	}
+
+	if err := store.UpdateAdminConfig(&models.AdminConfig{Features: map[string]bool{"notifications": false, "sensors": true}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if flags.Enabled(Notifications) || !flags.Enabled(Sensors) || flags.Enabled(MultiPlant) {
+		t.Error("Expected admin overrides to apply over the defaults")
+	}
+
+	var nilFlags *Flags
+	if !nilFlags.Enabled(MultiPlant) {
+		t.Error("Expected every feature on without flags")
+	}
+}
+
+func TestFlags_List(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{Features: map[string]bool{"multi_plant": false, "retired": true}})
+
+	states, err := New(store, config.Default().Features).List()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(states) != len(Definitions) {
+		t.Fatalf("Expected a state per flag, got %d", len(states))
+	}
+	if multiPlant := states[2]; multiPlant.Name != MultiPlant || multiPlant.Enabled || !multiPlant.Default || !multiPlant.Overridden {
+		t.Errorf("Expected multi_plant overridden off, got %+v", multiPlant)
+	}
+	if sensors := states[1]; !sensors.Enabled || sensors.Overridden {
+		t.Errorf("Expected sensors on by default, got %+v", sensors)
+	}
+}
+
+func TestFlags_Gate(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	flags := New(store, config.FeatureConfig{})
+
+	runs := 0
+	run := flags.Gate(Notifications, func(ctx context.Context) error { runs++; return nil })
+	run(context.Background())
+	if runs != 0 {
+		t.Error("Expected the job to be skipped while the flag is off")
+	}
+
+	store.UpdateAdminConfig(&models.AdminConfig{Features: map[string]bool{"notifications": true}})
+	run(context.Background())
+	if runs != 1 {
+		t.Error("Expected the job to run once the flag is switched on")
+	}
+}
+
+func TestFlags_Require(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	flags := New(store, config.FeatureConfig{Sensors: true})
+	handler := flags.Require(Sensors)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/plant/sensors", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200 while the flag is on, got %d", w.Code)
+	}
+
+	store.UpdateAdminConfig(&models.AdminConfig{Features: map[string]bool{"sensors": false}})
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/plant/sensors", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 while the flag is off, got %d", w.Code)
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"watered/internal/about"
+	"watered/internal/i18n"
+	"watered/internal/logger"
+	"watered/internal/render"
+	"watered/internal/respond"
+)
+
+// AboutHandler serves build and license information
+type AboutHandler struct {
+	info     *about.Info
+	renderer *render.Renderer
+}
+
+// NewAboutHandler creates a new about handler instance
+func NewAboutHandler(info *about.Info, renderer *render.Renderer) *AboutHandler {
+	return &AboutHandler{
+		info:     info,
+		renderer: renderer,
+	}
+}
+
+// GetAboutHandler returns version, build and license information as JSON
+// for API clients and as an HTML page for browsers
+// GET /about
+func (h *AboutHandler) GetAboutHandler(w http.ResponseWriter, r *http.Request) {
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h.info)
+		return
+	}
+
+	if err := h.renderer.Render(w, "about.html", map[string]interface{}{
+		"About":        h.info,
+		render.I18nKey: i18n.FromContext(r.Context()),
+	}); err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Template error")
+		logger.FromContext(r.Context()).Error("Template error", "error", err)
+	}
+}
+
+// GetVersionHandler returns the running version, the commit and time it was
+// built from and the Go runtime, for deploy checks and bug reports
+// GET /api/v1/version
+func (h *AboutHandler) GetVersionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.info.Build())
+}
+
+// wantsJSON reports whether the client asked for JSON with ?format=json or
+// an Accept header that does not include HTML
+func wantsJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "json" {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"watered/internal/about"
+	"watered/internal/render"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAboutHandler(t *testing.T) {
+	info, err := about.Load("v1.2.3")
+	require.NoError(t, err)
+
+	templates := template.Must(template.ParseFiles(filepath.Join("..", "..", "web", "templates", "about.html")))
+	handler := NewAboutHandler(info, render.NewRenderer(templates, nil))
+
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/about", nil)
+		req.Header.Set("Accept", "application/json")
+		rr := httptest.NewRecorder()
+		handler.GetAboutHandler(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "v1.2.3", response["version"])
+		assert.NotEmpty(t, response["licenses"])
+	})
+
+	t.Run("html", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/about", nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml,application/json;q=0.9")
+		rr := httptest.NewRecorder()
+		handler.GetAboutHandler(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "v1.2.3")
+		assert.Contains(t, rr.Body.String(), "github.com/go-chi/chi/v5")
+	})
+
+	t.Run("format query", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.GetAboutHandler(rr, httptest.NewRequest(http.MethodGet, "/about?format=json", nil))
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	})
+}
+
+func TestGetVersionHandler(t *testing.T) {
+	info, err := about.Load("v1.2.3")
+	require.NoError(t, err)
+	handler := NewAboutHandler(info, nil)
+
+	rr := httptest.NewRecorder()
+	handler.GetVersionHandler(rr, httptest.NewRequest(http.MethodGet, "/api/v1/version", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var response about.Build
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, info.Build(), response)
+	assert.Equal(t, "v1.2.3", response.Version)
+	assert.NotEmpty(t, response.GoVersion)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"watered/internal/activity"
+	"watered/internal/auth"
+	"watered/internal/logger"
+	"watered/internal/respond"
+	"watered/internal/services"
+)
+
+// AccountHandlers lets signed-in users download and delete their own data
+type AccountHandlers struct {
+	userService *services.UserService
+	authService *auth.AuthService
+	activity    *activity.Tracker
+}
+
+// NewAccountHandlers creates a new account handlers instance
+func NewAccountHandlers(userService *services.UserService, authService *auth.AuthService) *AccountHandlers {
+	return &AccountHandlers{
+		userService: userService,
+		authService: authService,
+	}
+}
+
+// SetActivityTracker exports and forgets activity the tracker hasn't
+// flushed to storage yet
+func (h *AccountHandlers) SetActivityTracker(tracker *activity.Tracker) {
+	h.activity = tracker
+}
+
+// ExportHandler downloads everything stored about the current user as JSON
+// GET /api/v1/me/export
+func (h *AccountHandlers) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+	if auth.ViaAPIKey(r) {
+		respond.Error(w, http.StatusForbidden, respond.CodeForbidden, "Sign in to export your data")
+		return
+	}
+
+	export, err := h.userService.ExportAccount(user.Email)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to export account", "email", user.Email, "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to export account")
+		return
+	}
+	export.Role = h.authService.UserRole(export.Email).String()
+	if h.activity != nil {
+		for _, entry := range h.activity.List() {
+			if entry.Email == export.Email {
+				export.Activity = entry
+			}
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Disposition", `attachment; filename="watered-export.json"`)
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(export)
+}
+
+// DeleteHandler deletes the current user's account and signs them out.
+// Their waterings stay in the history under a placeholder. Admins must
+// first be removed from the admin list by another admin.
+// DELETE /api/v1/me
+func (h *AccountHandlers) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+	if auth.ViaAPIKey(r) {
+		respond.Error(w, http.StatusForbidden, respond.CodeForbidden, "Sign in to delete your account")
+		return
+	}
+	if h.authService.IsUserAdmin(user.Email) {
+		respond.Error(w, http.StatusConflict, respond.CodeConflict, "Admins can't delete their own account. Ask another admin to remove you from the admin list first.")
+		return
+	}
+
+	log := logger.FromContext(r.Context())
+	result, err := h.userService.DeleteAccount(user.Email)
+	if err != nil {
+		log.Error("Failed to delete account", "email", user.Email, "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to delete account")
+		return
+	}
+	if h.activity != nil {
+		if err := h.activity.Forget(result.Email); err != nil {
+			log.Error("Failed to delete account activity", "email", result.Email, "error", err)
+		}
+	}
+	if err := h.authService.ClearSession(w, r); err != nil {
+		log.Warn("Failed to clear session of deleted account", "error", err)
+	}
+	if _, err := h.authService.RevokeUserSessions(result.Email, result.Email); err != nil {
+		log.Error("Failed to revoke sessions of deleted account", "email", result.Email, "error", err)
+	}
+
+	// Emails allowed through the environment can still sign in, which
+	// starts a new, empty account
+	response := map[string]interface{}{
+		"success":       true,
+		"message":       "Account deleted",
+		"deletion":      result,
+		"still_allowed": h.authService.IsUserAllowed(result.Email),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountHandlers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"admin@example.com", "sam@example.com"},
+		AdminEmails:   []string{"admin@example.com"},
+	})
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 1, WateredAt: time.Now(), WateredBy: "sam@example.com"})
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	handlers := NewAccountHandlers(services.NewUserService(store), authService)
+	request := func(method, path, email string) *http.Request {
+		req := httptest.NewRequest(method, path, nil)
+		for _, cookie := range sessionCookies(t, authService, email) {
+			req.AddCookie(cookie)
+		}
+		return req
+	}
+
+	w := httptest.NewRecorder()
+	handlers.ExportHandler(w, httptest.NewRequest("GET", "/api/v1/me/export", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	handlers.ExportHandler(w, request("GET", "/api/v1/me/export", "sam@example.com"))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	var export models.AccountExport
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&export))
+	assert.Equal(t, "sam@example.com", export.Email)
+	assert.Equal(t, "member", export.Role)
+	assert.Len(t, export.Waterings, 1)
+
+	t.Run("admins can't delete themselves", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handlers.DeleteHandler(w, request("DELETE", "/api/v1/me", "admin@example.com"))
+		assert.Equal(t, http.StatusConflict, w.Code)
+		user, _ := store.GetUser("admin@example.com")
+		assert.NotNil(t, user)
+	})
+
+	w = httptest.NewRecorder()
+	handlers.DeleteHandler(w, request("DELETE", "/api/v1/me", "sam@example.com"))
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Deletion     models.AccountDeletion `json:"deletion"`
+		StillAllowed bool                   `json:"still_allowed"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.False(t, response.StillAllowed)
+	assert.Equal(t, 1, response.Deletion.Anonymized["waterings"])
+
+	events, _ := store.ListWateringEvents(0)
+	require.Len(t, events, 1)
+	assert.True(t, strings.HasPrefix(events[0].WateredBy, models.DeletedUserPrefix))
+	user, _ := store.GetUser("sam@example.com")
+	assert.Nil(t, user)
+	assert.False(t, authService.IsUserAllowed("sam@example.com"))
+	sessions, _ := store.ListSessions()
+	for _, session := range sessions {
+		assert.NotEqual(t, "sam@example.com", session.Email)
+	}
+}
@@ -2,97 +2,205 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
-	"os"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"watered/internal/activity"
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/features"
+	"watered/internal/logger"
 	"watered/internal/models"
+	"watered/internal/privacy"
+	"watered/internal/reload"
+	"watered/internal/respond"
+	"watered/internal/services"
+	"watered/internal/stats"
 	"watered/internal/storage"
+	"watered/internal/update"
+	"watered/internal/validate"
 
 	"github.com/go-chi/chi/v5"
 )
 
 // AdminHandler handles admin-related HTTP requests
 type AdminHandler struct {
-	storage storage.Storage
+	auditor
+
+	storage          storage.Storage
+	userService      *services.UserService
+	plantService     *services.PlantService
+	integrityService *services.IntegrityService
+	emailService     *services.EmailService
+	slackService     *services.SlackService
+	discordService   *services.DiscordService
+	ntfyService      *services.NtfyService
+	anonymizer       *privacy.Anonymizer
+	publisher        services.Publisher
+	activity         *activity.Tracker
+	features         *features.Flags
+	reloader         *reload.Reloader
+	authService      *auth.AuthService
+	authConfig       config.AuthConfig
+	environment      config.Report
 }
 
 // NewAdminHandler creates a new admin handler
-func NewAdminHandler(storage storage.Storage) *AdminHandler {
+func NewAdminHandler(storage storage.Storage, cfg *config.Config) *AdminHandler {
 	return &AdminHandler{
-		storage: storage,
+		storage:          storage,
+		userService:      services.NewUserService(storage),
+		plantService:     services.NewPlantService(storage),
+		integrityService: services.NewIntegrityService(storage),
+		emailService:     services.NewEmailService(storage, nil),
+		slackService:     services.NewSlackService(storage, nil),
+		discordService:   services.NewDiscordService(storage, nil),
+		ntfyService:      services.NewNtfyService(storage, nil, ""),
+		anonymizer:       privacy.NewAnonymizerFromConfig(cfg.Privacy),
+		features:         features.New(storage, cfg.Features),
+		authConfig:       cfg.Auth,
+		environment:      cfg.Report(),
 	}
 }
 
-// getEmailsFromEnv parses comma-separated emails from environment variable
-func getEmailsFromEnv(envVar string, fallback []string) []string {
-	if envValue := os.Getenv(envVar); envValue != "" {
-		var emails []string
-		for _, email := range strings.Split(envValue, ",") {
-			if trimmed := strings.TrimSpace(email); trimmed != "" {
-				emails = append(emails, trimmed)
-			}
+// SetEmailService replaces the email service used for test emails
+func (h *AdminHandler) SetEmailService(emailService *services.EmailService) {
+	h.emailService = emailService
+}
+
+// SetSlackService replaces the Slack service used for test messages
+func (h *AdminHandler) SetSlackService(slackService *services.SlackService) {
+	h.slackService = slackService
+}
+
+// SetDiscordService replaces the Discord service used for test messages
+func (h *AdminHandler) SetDiscordService(discordService *services.DiscordService) {
+	h.discordService = discordService
+}
+
+// SetNtfyService replaces the ntfy service used for test messages
+func (h *AdminHandler) SetNtfyService(ntfyService *services.NtfyService) {
+	h.ntfyService = ntfyService
+}
+
+// SetAnonymizer replaces the anonymizer used for exported reports
+func (h *AdminHandler) SetAnonymizer(anonymizer *privacy.Anonymizer) {
+	h.anonymizer = anonymizer
+}
+
+// SetPublisher sets where configuration changes are published for
+// real-time clients
+func (h *AdminHandler) SetPublisher(publisher services.Publisher) {
+	h.publisher = publisher
+}
+
+// SetAuthService sets the service whose sessions SetUserRoleHandler revokes,
+// so a new role takes effect at once. Without it users keep their old role
+// until they next sign in.
+func (h *AdminHandler) SetAuthService(authService *auth.AuthService) {
+	h.authService = authService
+}
+
+// SetActivityTracker sets where GetUsersHandler reads when users were last
+// seen
+func (h *AdminHandler) SetActivityTracker(tracker *activity.Tracker) {
+	h.activity = tracker
+}
+
+// publishConfig tells real-time clients about settings that change what
+// they display; allowlists and credentials are never published
+func (h *AdminHandler) publishConfig(config *models.AdminConfig) {
+	if h.publisher == nil {
+		return
+	}
+	h.publisher.Publish(services.ConfigUpdatedMessage, map[string]interface{}{
+		"timeout_hours": config.TimeoutHours,
+		"privacy_mode":  config.PrivacyMode,
+	})
+}
+
+// shouldAnonymize reports whether identities should be hashed in a report,
+// either by deployment default or by the anonymize query parameter
+func (h *AdminHandler) shouldAnonymize(r *http.Request) bool {
+	if value := r.URL.Query().Get("anonymize"); value != "" {
+		return value == "true"
+	}
+	return h.anonymizer.Enabled()
+}
+
+// defaultAdminConfig builds the initial admin configuration from the
+// configured allow, admin and viewer lists, falling back to demo users when
+// none are set
+func (h *AdminHandler) defaultAdminConfig(timeoutHours int) *models.AdminConfig {
+	allowedEmails := append([]string{}, h.authConfig.AllowedEmails...)
+	adminEmails := append([]string{}, h.authConfig.AdminEmails...)
+
+	// In demo mode (no emails configured), provide demo defaults
+	if len(allowedEmails) == 0 && len(adminEmails) == 0 {
+		allowedEmails = []string{"demo@example.com", "user1@example.com", "user2@example.com", "test@example.com"}
+		adminEmails = []string{"admin@example.com"}
+	}
+
+	// Ensure admin emails are also in allowed emails
+	allowedEmailsMap := make(map[string]bool)
+	for _, email := range allowedEmails {
+		allowedEmailsMap[email] = true
+	}
+	viewerEmails := append([]string{}, h.authConfig.ViewerEmails...)
+	for _, email := range append(append([]string{}, adminEmails...), viewerEmails...) {
+		if !allowedEmailsMap[email] {
+			allowedEmailsMap[email] = true
+			allowedEmails = append(allowedEmails, email)
 		}
-		return emails
 	}
-	return fallback
+
+	return &models.AdminConfig{
+		TimeoutHours:  timeoutHours,
+		AllowedEmails: allowedEmails,
+		AdminEmails:   adminEmails,
+		ViewerEmails:  viewerEmails,
+	}
 }
 
 // GetConfigHandler returns the current admin configuration
 func (h *AdminHandler) GetConfigHandler(w http.ResponseWriter, r *http.Request) {
 	config, err := h.storage.GetAdminConfig()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get admin config: %v", err), http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get admin config: %v", err))
 		return
 	}
 
 	// If no config exists, create default
 	if config == nil {
-		// Get emails from environment variables, with empty fallback for production
-		allowedEmails := getEmailsFromEnv("ALLOWED_EMAILS", []string{})
-		adminEmails := getEmailsFromEnv("ADMIN_EMAILS", []string{})
-
-		// In demo mode (no env vars set), provide demo defaults
-		if len(allowedEmails) == 0 && len(adminEmails) == 0 {
-			allowedEmails = []string{"demo@example.com", "user1@example.com", "user2@example.com", "test@example.com"}
-			adminEmails = []string{"admin@example.com"}
-		}
-
-		// Ensure admin emails are also in allowed emails
-		allowedEmailsMap := make(map[string]bool)
-		for _, email := range allowedEmails {
-			allowedEmailsMap[email] = true
-		}
-		for _, email := range adminEmails {
-			if !allowedEmailsMap[email] {
-				allowedEmails = append(allowedEmails, email)
-			}
-		}
-
-		config = &models.AdminConfig{
-			TimeoutHours:  24, // This will be overridden below
-			AllowedEmails: allowedEmails,
-			AdminEmails:   adminEmails,
-		}
+		config = h.defaultAdminConfig(24) // Timeout will be overridden below
 		if err := h.storage.UpdateAdminConfig(config); err != nil {
-			http.Error(w, fmt.Sprintf("Failed to create default config: %v", err), http.StatusInternalServerError)
+			respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to create default config: %v", err))
 			return
 		}
 	}
 
 	// Always sync timeout with current plant state (plant is source of truth)
 	if plant, err := h.storage.GetPlantState(); err == nil && plant != nil {
-		log.Printf("DEBUG GetConfig: Plant timeout is %d hours, setting admin config to match", plant.TimeoutHours)
+		logger.FromContext(r.Context()).Debug("Syncing admin config timeout with plant", "timeout_hours", plant.TimeoutHours)
 		config.TimeoutHours = plant.TimeoutHours
 	} else {
-		log.Printf("DEBUG GetConfig: No plant found or error: %v", err)
+		logger.FromContext(r.Context()).Debug("No plant to sync admin config timeout with", "error", err)
 	}
 
+	// Never expose the OAuth client secret
+	response := *config
+	response.GoogleClientSecret = ""
+
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(config); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to encode config: %v", err), http.StatusInternalServerError)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to encode config: %v", err))
 		return
 	}
 }
@@ -100,83 +208,54 @@ func (h *AdminHandler) GetConfigHandler(w http.ResponseWriter, r *http.Request)
 // UpdateTimeoutHandler updates the watering timeout configuration
 func (h *AdminHandler) UpdateTimeoutHandler(w http.ResponseWriter, r *http.Request) {
 	var request struct {
-		TimeoutHours int `json:"timeoutHours"`
+		TimeoutHours int `json:"timeoutHours" validate:"min=1,max=168"`
 	}
-
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
-	}
-
-	// Validate timeout range
-	if request.TimeoutHours < 1 || request.TimeoutHours > 168 {
-		http.Error(w, "Timeout must be between 1 and 168 hours", http.StatusBadRequest)
+	if !validate.DecodeJSON(w, r, &request) {
 		return
 	}
 
 	// Get current config
 	config, err := h.storage.GetAdminConfig()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get admin config: %v", err), http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get admin config: %v", err))
 		return
 	}
 
+	var oldTimeout interface{}
 	if config == nil {
-		// Use environment variable logic for initial config
-		allowedEmails := getEmailsFromEnv("ALLOWED_EMAILS", []string{})
-		adminEmails := getEmailsFromEnv("ADMIN_EMAILS", []string{})
-
-		// In demo mode (no env vars set), provide demo defaults
-		if len(allowedEmails) == 0 && len(adminEmails) == 0 {
-			allowedEmails = []string{"demo@example.com", "user1@example.com", "user2@example.com", "test@example.com"}
-			adminEmails = []string{"admin@example.com"}
-		}
-
-		// Ensure admin emails are also in allowed emails
-		allowedEmailsMap := make(map[string]bool)
-		for _, email := range allowedEmails {
-			allowedEmailsMap[email] = true
-		}
-		for _, email := range adminEmails {
-			if !allowedEmailsMap[email] {
-				allowedEmails = append(allowedEmails, email)
-			}
-		}
-
-		config = &models.AdminConfig{
-			TimeoutHours:  request.TimeoutHours,
-			AllowedEmails: allowedEmails,
-			AdminEmails:   adminEmails,
-		}
+		config = h.defaultAdminConfig(request.TimeoutHours)
 	} else {
+		oldTimeout = config.TimeoutHours
 		config.TimeoutHours = request.TimeoutHours
 	}
 
 	// Update config
 	if err := h.storage.UpdateAdminConfig(config); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update config: %v", err), http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to update config: %v", err))
 		return
 	}
 
 	// Also update the plant timeout to keep them synchronized
 	plant, err := h.storage.GetPlantState()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get plant state: %v", err), http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get plant state: %v", err))
 		return
 	}
 
 	if plant != nil {
-		log.Printf("DEBUG UpdateTimeout: Updating plant timeout from %d to %d hours", plant.TimeoutHours, request.TimeoutHours)
+		logger.FromContext(r.Context()).Debug("Updating plant timeout", "from_hours", plant.TimeoutHours, "to_hours", request.TimeoutHours)
 		plant.TimeoutHours = request.TimeoutHours
 		if err := h.storage.UpdatePlantState(plant); err != nil {
-			http.Error(w, fmt.Sprintf("Failed to update plant timeout: %v", err), http.StatusInternalServerError)
+			respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to update plant timeout: %v", err))
 			return
 		}
-		log.Printf("DEBUG UpdateTimeout: Plant timeout successfully updated to %d hours", plant.TimeoutHours)
 	} else {
-		log.Printf("DEBUG UpdateTimeout: No plant found to update")
+		logger.FromContext(r.Context()).Debug("No plant found to update timeout")
 	}
 
+	h.publishConfig(config)
+	h.audit(r, models.AuditConfigTimeout, "", oldTimeout, request.TimeoutHours)
+
 	// Return success response
 	response := map[string]interface{}{
 		"success":      true,
@@ -188,11 +267,69 @@ func (h *AdminHandler) UpdateTimeoutHandler(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(response)
 }
 
-// GetUsersHandler returns the list of whitelisted users
+// UpdatePrivacyModeHandler toggles hiding waterer identities from non-admin users
+func (h *AdminHandler) UpdatePrivacyModeHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		PrivacyMode *bool `json:"privacyMode"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	if request.PrivacyMode == nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "privacyMode is required")
+		return
+	}
+
+	config, err := h.storage.GetAdminConfig()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get admin config: %v", err))
+		return
+	}
+
+	var oldPrivacyMode interface{}
+	if config == nil {
+		config = h.defaultAdminConfig(24)
+		if plant, err := h.storage.GetPlantState(); err == nil && plant != nil {
+			config.TimeoutHours = plant.TimeoutHours
+		}
+	} else {
+		oldPrivacyMode = config.PrivacyMode
+	}
+
+	config.PrivacyMode = *request.PrivacyMode
+
+	if err := h.storage.UpdateAdminConfig(config); err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to update config: %v", err))
+		return
+	}
+
+	h.publishConfig(config)
+	h.audit(r, models.AuditConfigPrivacyMode, "", oldPrivacyMode, config.PrivacyMode)
+
+	state := "disabled"
+	if config.PrivacyMode {
+		state = "enabled"
+	}
+
+	response := map[string]interface{}{
+		"success":     true,
+		"privacyMode": config.PrivacyMode,
+		"message":     fmt.Sprintf("Privacy mode %s", state),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetUsersHandler returns the list of whitelisted users and when each was
+// last seen
 func (h *AdminHandler) GetUsersHandler(w http.ResponseWriter, r *http.Request) {
 	config, err := h.storage.GetAdminConfig()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get admin config: %v", err), http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get admin config: %v", err))
 		return
 	}
 
@@ -207,39 +344,95 @@ func (h *AdminHandler) GetUsersHandler(w http.ResponseWriter, r *http.Request) {
 	response := map[string]interface{}{
 		"allowedEmails": config.AllowedEmails,
 		"adminEmails":   config.AdminEmails,
+		"viewerEmails":  config.ViewerEmails,
+		"users":         h.userActivity(config),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// AddUserHandler adds a user to the whitelist
+// userActivityEntry is an allowlisted user and when they were last seen.
+// LastSeen is nil for users who have never signed in since tracking began.
+type userActivityEntry struct {
+	Email     string     `json:"email"`
+	Admin     bool       `json:"admin"`
+	Role      string     `json:"role"`
+	FirstSeen *time.Time `json:"first_seen"`
+	LastSeen  *time.Time `json:"last_seen"`
+	UserAgent string     `json:"user_agent,omitempty"`
+	ClientIP  string     `json:"client_ip,omitempty"`
+}
+
+// userActivity lists every allowed and admin user with their activity, in
+// allowlist order
+func (h *AdminHandler) userActivity(config *models.AdminConfig) []userActivityEntry {
+	seen := make(map[string]*models.UserActivity)
+	if h.activity != nil {
+		for _, record := range h.activity.List() {
+			seen[record.Email] = record
+		}
+	}
+
+	admins := make(map[string]bool, len(config.AdminEmails))
+	for _, email := range config.AdminEmails {
+		admins[email] = true
+	}
+	viewers := make(map[string]bool, len(config.ViewerEmails))
+	for _, email := range config.ViewerEmails {
+		viewers[email] = true
+	}
+
+	entries := []userActivityEntry{}
+	listed := make(map[string]bool)
+	for _, email := range append(append([]string{}, config.AllowedEmails...), config.AdminEmails...) {
+		if listed[email] {
+			continue
+		}
+		listed[email] = true
+
+		entry := userActivityEntry{Email: email, Admin: admins[email], Role: privacy.RoleMember.String()}
+		switch {
+		case admins[email]:
+			entry.Role = privacy.RoleAdmin.String()
+		case viewers[email]:
+			entry.Role = privacy.RoleViewer.String()
+		}
+		if record := seen[email]; record != nil {
+			entry.FirstSeen = &record.FirstSeen
+			entry.LastSeen = &record.LastSeen
+			entry.UserAgent = record.UserAgent
+			entry.ClientIP = record.ClientIP
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// AddUserHandler adds a user to the whitelist, as a member or, with role
+// "viewer", as a read-only user such as a plant sitter
 func (h *AdminHandler) AddUserHandler(w http.ResponseWriter, r *http.Request) {
 	var request struct {
-		Email string `json:"email"`
+		Email string `json:"email" validate:"required,email"`
+		Role  string `json:"role"`
 	}
-
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	if !validate.DecodeJSON(w, r, &request) {
 		return
 	}
-
 	email := strings.TrimSpace(strings.ToLower(request.Email))
-	if email == "" {
-		http.Error(w, "Email is required", http.StatusBadRequest)
-		return
+	role := strings.TrimSpace(strings.ToLower(request.Role))
+	if role == "" {
+		role = privacy.RoleMember.String()
 	}
-
-	// Basic email validation
-	if !strings.Contains(email, "@") || !strings.Contains(email, ".") {
-		http.Error(w, "Invalid email format", http.StatusBadRequest)
+	if role != privacy.RoleMember.String() && role != privacy.RoleViewer.String() {
+		respond.ValidationError(w, []respond.FieldError{{Field: "role", Message: "must be member or viewer"}})
 		return
 	}
 
 	// Get current config
 	config, err := h.storage.GetAdminConfig()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get admin config: %v", err), http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get admin config: %v", err))
 		return
 	}
 
@@ -254,25 +447,31 @@ func (h *AdminHandler) AddUserHandler(w http.ResponseWriter, r *http.Request) {
 	// Check if email already exists
 	for _, existingEmail := range config.AllowedEmails {
 		if existingEmail == email {
-			http.Error(w, "Email already exists in whitelist", http.StatusConflict)
+			respond.Error(w, http.StatusConflict, respond.CodeConflict, "Email already exists in whitelist")
 			return
 		}
 	}
 
 	// Add email to allowed list
 	config.AllowedEmails = append(config.AllowedEmails, email)
+	if role == privacy.RoleViewer.String() {
+		config.ViewerEmails = append(config.ViewerEmails, email)
+	}
 
 	// Update config
 	if err := h.storage.UpdateAdminConfig(config); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update config: %v", err), http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to update config: %v", err))
 		return
 	}
 
+	h.audit(r, models.AuditUserAdd, email, nil, email)
+
 	// Return success response
 	response := map[string]interface{}{
 		"success": true,
-		"message": fmt.Sprintf("Added %s to allowed users", email),
+		"message": fmt.Sprintf("Added %s to allowed users as a %s", email, role),
 		"email":   email,
+		"role":    role,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -280,25 +479,31 @@ func (h *AdminHandler) AddUserHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// RemoveUserHandler removes a user from the whitelist
+// RemoveUserHandler removes a user from the whitelist. Admins can't remove
+// themselves or the last admin, which would leave nobody able to sign in to
+// the admin panel.
 func (h *AdminHandler) RemoveUserHandler(w http.ResponseWriter, r *http.Request) {
 	email := chi.URLParam(r, "email")
 	if email == "" {
-		http.Error(w, "Email parameter is required", http.StatusBadRequest)
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Email parameter is required")
 		return
 	}
 
 	email = strings.TrimSpace(strings.ToLower(email))
+	if strings.EqualFold(auth.Actor(r), email) {
+		respond.Error(w, http.StatusConflict, respond.CodeConflict, "You can't remove yourself; ask another admin to remove you")
+		return
+	}
 
 	// Get current config
 	config, err := h.storage.GetAdminConfig()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get admin config: %v", err), http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get admin config: %v", err))
 		return
 	}
 
 	if config == nil {
-		http.Error(w, "No configuration found", http.StatusNotFound)
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "No configuration found")
 		return
 	}
 
@@ -314,18 +519,26 @@ func (h *AdminHandler) RemoveUserHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	if !found {
-		http.Error(w, "Email not found in whitelist", http.StatusNotFound)
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Email not found in whitelist")
+		return
+	}
+	if slices.Contains(config.AdminEmails, email) && h.isLastAdmin(config, email) {
+		respond.Error(w, http.StatusConflict, respond.CodeConflict, fmt.Sprintf("%s is the last admin; make someone else an admin first", email))
 		return
 	}
 
 	config.AllowedEmails = newAllowedEmails
+	config.AdminEmails = slices.DeleteFunc(config.AdminEmails, func(admin string) bool { return admin == email })
+	config.ViewerEmails = slices.DeleteFunc(config.ViewerEmails, func(viewer string) bool { return viewer == email })
 
 	// Update config
 	if err := h.storage.UpdateAdminConfig(config); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update config: %v", err), http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to update config: %v", err))
 		return
 	}
 
+	h.audit(r, models.AuditUserRemove, email, email, nil)
+
 	// Return success response
 	response := map[string]interface{}{
 		"success": true,
@@ -337,54 +550,777 @@ func (h *AdminHandler) RemoveUserHandler(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(response)
 }
 
-// GetHistoryHandler returns plant watering history
+// SetUserRoleHandler makes an allowed user an admin, member or viewer.
+// Admins set by ADMIN_EMAILS can only be demoted there, and the last admin
+// can't be demoted at all. Sessions keep the role they signed in with, so
+// the user is signed out everywhere and gets the new role when they sign
+// back in.
+func (h *AdminHandler) SetUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	email := strings.TrimSpace(strings.ToLower(chi.URLParam(r, "email")))
+	if email == "" {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Email parameter is required")
+		return
+	}
+
+	var request struct {
+		Role string `json:"role" validate:"required"`
+	}
+	if !validate.DecodeJSON(w, r, &request) {
+		return
+	}
+	role := strings.TrimSpace(strings.ToLower(request.Role))
+	if role != privacy.RoleAdmin.String() && role != privacy.RoleMember.String() && role != privacy.RoleViewer.String() {
+		respond.ValidationError(w, []respond.FieldError{{Field: "role", Message: "must be admin, member or viewer"}})
+		return
+	}
+
+	config, err := h.storage.GetAdminConfig()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get admin config: %v", err))
+		return
+	}
+	if config == nil {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "No configuration found")
+		return
+	}
+	if !slices.Contains(config.AllowedEmails, email) && !slices.Contains(config.AdminEmails, email) {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Email not found in whitelist")
+		return
+	}
+
+	previousRole := storedRole(config, email)
+	if previousRole == privacy.RoleAdmin.String() && role != previousRole {
+		if auth.ConfiguredAdmins(h.authConfig)[email] {
+			respond.Error(w, http.StatusConflict, respond.CodeConflict, fmt.Sprintf("%s is an admin through the ADMIN_EMAILS setting; remove them there instead", email))
+			return
+		}
+		if h.isLastAdmin(config, email) {
+			respond.Error(w, http.StatusConflict, respond.CodeConflict, fmt.Sprintf("%s is the last admin; make someone else an admin first", email))
+			return
+		}
+	}
+
+	if role != previousRole {
+		config.AdminEmails = slices.DeleteFunc(config.AdminEmails, func(admin string) bool { return admin == email })
+		config.ViewerEmails = slices.DeleteFunc(config.ViewerEmails, func(viewer string) bool { return viewer == email })
+		switch role {
+		case privacy.RoleAdmin.String():
+			config.AdminEmails = append(config.AdminEmails, email)
+		case privacy.RoleViewer.String():
+			config.ViewerEmails = append(config.ViewerEmails, email)
+		}
+		// Demoted admins stay allowed
+		if !slices.Contains(config.AllowedEmails, email) {
+			config.AllowedEmails = append(config.AllowedEmails, email)
+		}
+
+		if err := h.storage.UpdateAdminConfig(config); err != nil {
+			respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to update config: %v", err))
+			return
+		}
+		h.audit(r, models.AuditUserRole, email, previousRole, role)
+
+		if h.authService != nil {
+			if _, err := h.authService.RevokeUserSessions(email, auth.Actor(r)); err != nil {
+				respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Role changed, but failed to sign %s out: %v", email, err))
+				return
+			}
+		}
+	}
+
+	response := map[string]interface{}{
+		"success":      true,
+		"message":      fmt.Sprintf("%s is now a %s and has been signed out to apply it", email, role),
+		"email":        email,
+		"role":         role,
+		"previousRole": previousRole,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// storedRole returns the role the stored configuration gives an allowed user
+func storedRole(config *models.AdminConfig, email string) string {
+	switch {
+	case slices.Contains(config.AdminEmails, email):
+		return privacy.RoleAdmin.String()
+	case slices.Contains(config.ViewerEmails, email):
+		return privacy.RoleViewer.String()
+	default:
+		return privacy.RoleMember.String()
+	}
+}
+
+// isLastAdmin reports whether email is the only admin in the stored
+// configuration and ADMIN_EMAILS
+func (h *AdminHandler) isLastAdmin(config *models.AdminConfig, email string) bool {
+	for _, admin := range append(append([]string{}, config.AdminEmails...), h.authConfig.AdminEmails...) {
+		if admin != email {
+			return false
+		}
+	}
+	return true
+}
+
+// demoUserEntry is a demo user with the role they sign in with. Allowed is
+// false for users no longer on the allowlist, who are left off the form.
+type demoUserEntry struct {
+	Email   string `json:"email"`
+	Name    string `json:"name,omitempty"`
+	Role    string `json:"role"`
+	Allowed bool   `json:"allowed"`
+}
+
+// demoUsers returns the demo users an admin saved, or DEMO_USERS until one
+// has
+func (h *AdminHandler) demoUsers(config *models.AdminConfig) []models.DemoUser {
+	if config.DemoUsers != nil {
+		return config.DemoUsers
+	}
+	return auth.ConfiguredDemoUsers(h.authConfig)
+}
+
+// GetDemoUsersHandler lists the users offered on the demo login form.
+// Saved is false while the list still comes from DEMO_USERS.
+func (h *AdminHandler) GetDemoUsersHandler(w http.ResponseWriter, r *http.Request) {
+	config, err := h.storage.GetAdminConfig()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get admin config: %v", err))
+		return
+	}
+	if config == nil {
+		config = h.defaultAdminConfig(24)
+	}
+
+	entries := []demoUserEntry{}
+	for _, user := range h.demoUsers(config) {
+		entries = append(entries, demoUserEntry{
+			Email:   user.Email,
+			Name:    user.Name,
+			Role:    storedRole(config, user.Email),
+			Allowed: slices.Contains(config.AllowedEmails, user.Email) || slices.Contains(config.AdminEmails, user.Email),
+		})
+	}
+
+	response := map[string]interface{}{
+		"demoUsers": entries,
+		"saved":     config.DemoUsers != nil,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// AddDemoUserHandler offers an allowed user on the demo login form. The
+// first change saves the list, which replaces DEMO_USERS from then on.
+func (h *AdminHandler) AddDemoUserHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Email string `json:"email" validate:"required,email"`
+		Name  string `json:"name" validate:"max=100"`
+	}
+	if !validate.DecodeJSON(w, r, &request) {
+		return
+	}
+	user := models.DemoUser{
+		Email: strings.TrimSpace(strings.ToLower(request.Email)),
+		Name:  strings.TrimSpace(request.Name),
+	}
+
+	config, err := h.storage.GetAdminConfig()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get admin config: %v", err))
+		return
+	}
+	if config == nil {
+		config = h.defaultAdminConfig(24)
+	}
+
+	if !slices.Contains(config.AllowedEmails, user.Email) && !slices.Contains(config.AdminEmails, user.Email) {
+		respond.Error(w, http.StatusConflict, respond.CodeConflict, fmt.Sprintf("%s isn't an allowed user; add them first", user.Email))
+		return
+	}
+	demoUsers := h.demoUsers(config)
+	if slices.ContainsFunc(demoUsers, func(existing models.DemoUser) bool { return existing.Email == user.Email }) {
+		respond.Error(w, http.StatusConflict, respond.CodeConflict, fmt.Sprintf("%s is already a demo user", user.Email))
+		return
+	}
+
+	config.DemoUsers = append(append([]models.DemoUser{}, demoUsers...), user)
+	if err := h.storage.UpdateAdminConfig(config); err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to update config: %v", err))
+		return
+	}
+
+	h.audit(r, models.AuditDemoUserAdd, user.Email, nil, user)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Added %s to the demo users", user.Email),
+		"email":   user.Email,
+		"name":    user.Name,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// RemoveDemoUserHandler takes a user off the demo login form. They stay
+// allowed to sign in.
+func (h *AdminHandler) RemoveDemoUserHandler(w http.ResponseWriter, r *http.Request) {
+	email := strings.TrimSpace(strings.ToLower(chi.URLParam(r, "email")))
+	if email == "" {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Email parameter is required")
+		return
+	}
+
+	config, err := h.storage.GetAdminConfig()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get admin config: %v", err))
+		return
+	}
+	if config == nil {
+		config = h.defaultAdminConfig(24)
+	}
+
+	demoUsers := h.demoUsers(config)
+	index := slices.IndexFunc(demoUsers, func(user models.DemoUser) bool { return user.Email == email })
+	if index < 0 {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Email not found in demo users")
+		return
+	}
+	removed := demoUsers[index]
+
+	// An empty list is kept, so removing everyone doesn't bring DEMO_USERS
+	// back
+	config.DemoUsers = slices.Delete(append([]models.DemoUser{}, demoUsers...), index, index+1)
+	if err := h.storage.UpdateAdminConfig(config); err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to update config: %v", err))
+		return
+	}
+
+	h.audit(r, models.AuditDemoUserRemove, email, removed, nil)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Removed %s from the demo users", email),
+		"email":   email,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// MergeUsersHandler merges a duplicate user account into another
+func (h *AdminHandler) MergeUsersHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		From   string `json:"from"`
+		To     string `json:"to"`
+		DryRun bool   `json:"dryRun"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	result, err := h.userService.MergeUsers(request.From, request.To, request.DryRun)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, fmt.Sprintf("Failed to merge users: %v", err))
+		return
+	}
+
+	message := fmt.Sprintf("Merged %s into %s", result.FromEmail, result.ToEmail)
+	if result.DryRun {
+		message = fmt.Sprintf("Preview of merging %s into %s", result.FromEmail, result.ToEmail)
+	} else {
+		h.audit(r, models.AuditUserMerge, result.FromEmail, result.FromEmail, result)
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": message,
+		"result":  result,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetIntegrityHandler reports data consistency problems without changing anything
+func (h *AdminHandler) GetIntegrityHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeIntegrityReport(w, r, false)
+}
+
+// RepairIntegrityHandler repairs the data consistency problems that can be fixed automatically
+func (h *AdminHandler) RepairIntegrityHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeIntegrityReport(w, r, true)
+}
+
+// writeIntegrityReport runs the integrity checker and writes its report.
+// Repairs that changed anything are audited.
+func (h *AdminHandler) writeIntegrityReport(w http.ResponseWriter, r *http.Request, repair bool) {
+	report, err := h.integrityService.Check(repair)
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to check data integrity: %v", err))
+		return
+	}
+	if repair && len(report.Issues) > len(report.Unresolved()) {
+		h.audit(r, models.AuditIntegrityRepair, "", nil, report)
+	}
+
+	response := map[string]interface{}{
+		"ok":         report.OK(),
+		"report":     report,
+		"unresolved": len(report.Unresolved()),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// maxBackupBytes is the largest backup RestoreHandler accepts
+const maxBackupBytes = 64 << 20
+
+// BackupHandler downloads everything in the store except sessions as one
+// JSON archive. The archive holds credential hashes, so treat it like the
+// data file itself.
+// GET /admin/backup
+func (h *AdminHandler) BackupHandler(w http.ResponseWriter, r *http.Request) {
+	backup, err := h.storage.Backup()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to create backup", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to create backup")
+		return
+	}
+	logger.FromContext(r.Context()).Info("Backup downloaded", "audit", true, "admin", auth.Actor(r))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="watered-backup-%s.json"`, backup.CreatedAt.Format("2006-01-02")))
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(backup)
+}
+
+// RestoreHandler replaces everything in the store except sessions with a
+// backup from BackupHandler. The backup is validated in full before
+// anything is replaced, so a rejected backup changes nothing.
+// POST /admin/restore
+func (h *AdminHandler) RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBackupBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respond.Error(w, http.StatusRequestEntityTooLarge, respond.CodeTooLarge, fmt.Sprintf("Backup must not exceed %d bytes", maxBackupBytes))
+			return
+		}
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Failed to read backup")
+		return
+	}
+
+	backup, err := storage.ParseBackup(data)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+		return
+	}
+	summary := map[string]int{
+		"plants":          len(backup.Plants),
+		"users":           len(backup.Users),
+		"watering_events": len(backup.Waterings),
+		"care_tasks":      len(backup.CareTasks),
+		"audit_entries":   len(backup.Audit),
+	}
+	if err := h.storage.Restore(backup); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to restore backup", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to restore backup")
+		return
+	}
+	logger.FromContext(r.Context()).Info("Backup restored", "audit", true, "admin", auth.Actor(r), "created_at", backup.CreatedAt)
+
+	// Recorded after restoring so the entry lands in the restored audit log
+	h.audit(r, models.AuditBackupRestore, "", nil, map[string]interface{}{"created_at": backup.CreatedAt, "restored": summary})
+	if backup.Config != nil {
+		h.publishConfig(backup.Config)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    true,
+		"created_at": backup.CreatedAt,
+		"restored":   summary,
+	})
+}
+
+// Defaults for the history endpoints' limit and days parameters
+const (
+	defaultHistoryEntries = 50
+	defaultHistoryDays    = 30
+)
+
+// parseHistoryQuery reads the plant, limit and days query parameters shared
+// by the history endpoints. plant is 0 for all plants.
+func parseHistoryQuery(r *http.Request) (plantID, limit, days int, problem string) {
+	query := r.URL.Query()
+	if raw := query.Get("plant"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			return 0, 0, 0, "Invalid plant ID"
+		}
+		plantID = id
+	}
+	limit = defaultHistoryEntries
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > services.MaxHistoryEntries {
+			return 0, 0, 0, "limit must be between 1 and " + strconv.Itoa(services.MaxHistoryEntries)
+		}
+		limit = n
+	}
+	days = defaultHistoryDays
+	if raw := query.Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > stats.MaxHistoryDays {
+			return 0, 0, 0, "days must be between 1 and " + strconv.Itoa(stats.MaxHistoryDays)
+		}
+		days = n
+	}
+	return plantID, limit, days, ""
+}
+
+// historyError responds to a failed history lookup
+func historyError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, services.ErrPlantNotFound) {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Plant not found")
+		return
+	}
+	logger.FromContext(r.Context()).Error("Failed to get watering history", "error", err)
+	respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to get watering history")
+}
+
+// GetHistoryHandler returns the default plant's current state and the latest
+// waterings of one plant or all of them, newest first
+// GET /admin/history?plant=1&limit=50
 func (h *AdminHandler) GetHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	plantID, limit, _, problem := parseHistoryQuery(r)
+	if problem != "" {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, problem)
+		return
+	}
+
 	// Get current plant state
 	plant, err := h.storage.GetPlantState()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get plant state: %v", err), http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get plant state: %v", err))
+		return
+	}
+	events, err := h.plantService.WithContext(r.Context()).WateringHistory(plantID, limit)
+	if err != nil {
+		historyError(w, r, err)
 		return
 	}
 
-	// For now, return current state as history
-	// In a real implementation, this would return historical watering events
+	if h.shouldAnonymize(r) {
+		if plant != nil {
+			anonymized := *plant
+			anonymized.WateredBy = h.anonymizer.Email(plant.WateredBy)
+			plant = &anonymized
+		}
+		for _, event := range events {
+			if !models.IsDeviceWaterer(event.WateredBy) {
+				event.WateredBy = h.anonymizer.Email(event.WateredBy)
+			}
+		}
+	}
+
 	history := map[string]interface{}{
 		"currentState": plant,
-		"events":       []interface{}{}, // TODO: Implement watering history storage
-		"message":      "Plant history feature will be enhanced in future versions",
+		"events":       events,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(history)
 }
 
+// GetDailyHistoryHandler counts waterings per day of the household timezone
+// over the last days days, oldest first, for charting how often plants are
+// watered. Days without a watering are included with a count of zero.
+// GET /admin/history/daily?plant=1&days=30
+func (h *AdminHandler) GetDailyHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	plantID, _, days, problem := parseHistoryQuery(r)
+	if problem != "" {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, problem)
+		return
+	}
+
+	counts, err := h.plantService.WithContext(r.Context()).DailyWaterings(plantID, days)
+	if err != nil {
+		historyError(w, r, err)
+		return
+	}
+	total := 0
+	for _, count := range counts {
+		total += count.Waterings
+	}
+
+	response := map[string]interface{}{
+		"days":      days,
+		"timezone":  h.plantService.Location().String(),
+		"total":     total,
+		"waterings": counts,
+	}
+	if plantID != 0 {
+		response["plant_id"] = plantID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetUserHistoryHandler counts each user's waterings over the last days days
+// of the household timezone, most first. Sensors and buttons appear under
+// their own names.
+// GET /admin/history/by-user?plant=1&days=30
+func (h *AdminHandler) GetUserHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	plantID, _, days, problem := parseHistoryQuery(r)
+	if problem != "" {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, problem)
+		return
+	}
+
+	users, err := h.plantService.WithContext(r.Context()).WateringsByUser(plantID, days)
+	if err != nil {
+		historyError(w, r, err)
+		return
+	}
+	if h.shouldAnonymize(r) {
+		for i := range users {
+			if !models.IsDeviceWaterer(users[i].Email) {
+				users[i].Email = h.anonymizer.Email(users[i].Email)
+			}
+		}
+	}
+
+	response := map[string]interface{}{
+		"days":     days,
+		"timezone": h.plantService.Location().String(),
+		"users":    users,
+	}
+	if plantID != 0 {
+		response["plant_id"] = plantID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetWeeklyReportHandler summarises the waterings of the last seven days, as
+// JSON or, with ?format=html, as the weekly report email
+// GET /admin/reports/weekly?format=html
+func (h *AdminHandler) GetWeeklyReportHandler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "html" {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "format must be json or html")
+		return
+	}
+
+	report, err := h.plantService.WithContext(r.Context()).WeeklyReport()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to build weekly report", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to build weekly report")
+		return
+	}
+	if report.MostActive != nil && h.shouldAnonymize(r) {
+		report.MostActive.Email = h.anonymizer.Email(report.MostActive.Email)
+		report.MostActive.Name = ""
+	}
+
+	if format != "html" {
+		response := struct {
+			*stats.WeeklyReport
+			Summary string `json:"summary"`
+		}{report, services.ReportSummary(report)}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	_, html, err := services.RenderWeeklyReport(report)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to render weekly report", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to build weekly report")
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(html))
+}
+
+// GetEnvironmentHandler reports the server build and what its configuration
+// turns on, with secrets masked, for diagnosing deployments remotely
+// GET /admin/environment
+func (h *AdminHandler) GetEnvironmentHandler(w http.ResponseWriter, r *http.Request) {
+	response := struct {
+		Version   string `json:"version"`
+		GoVersion string `json:"go_version"`
+		Platform  string `json:"platform"`
+		config.Report
+	}{
+		Version:   update.Version,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Report:    h.environment,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 // GetStatsHandler returns usage statistics
 func (h *AdminHandler) GetStatsHandler(w http.ResponseWriter, r *http.Request) {
 	config, err := h.storage.GetAdminConfig()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get admin config: %v", err), http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get admin config: %v", err))
 		return
 	}
 
 	plant, err := h.storage.GetPlantState()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get plant state: %v", err), http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get plant state: %v", err))
+		return
+	}
+
+	report, err := h.plantService.WithContext(r.Context()).WateringStats()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to compute watering stats: %v", err))
 		return
 	}
+	if h.shouldAnonymize(r) {
+		for i := range report.Users {
+			report.Users[i].Email = h.anonymizer.Email(report.Users[i].Email)
+		}
+	}
 
 	stats := map[string]interface{}{
-		"totalUsers":   len(config.AllowedEmails),
-		"adminUsers":   len(config.AdminEmails),
-		"timeoutHours": config.TimeoutHours,
-		"plantWatered": plant != nil && plant.LastWatered != nil,
-		"systemStatus": "healthy",
+		"totalUsers":           len(config.AllowedEmails),
+		"adminUsers":           len(config.AdminEmails),
+		"timeoutHours":         config.TimeoutHours,
+		"plantWatered":         plant != nil && plant.LastWatered != nil,
+		"systemStatus":         "healthy",
+		"totalWaterings":       report.Household.Waterings,
+		"currentStreakDays":    report.Household.CurrentStreak,
+		"longestStreakDays":    report.Household.LongestStreak,
+		"averageIntervalHours": report.Household.AverageIntervalHours,
+		"onTimePercentage":     report.Household.OnTimePercentage,
+		"users":                report.Users,
 	}
 
 	if plant != nil && plant.LastWatered != nil {
 		stats["lastWatered"] = plant.LastWatered.Format("2006-01-02 15:04:05")
 		stats["wateredBy"] = plant.WateredBy
+		if h.shouldAnonymize(r) {
+			stats["wateredBy"] = h.anonymizer.Email(plant.WateredBy)
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
+
+// SendTestEmailHandler sends a test email to verify the SMTP settings
+func (h *AdminHandler) SendTestEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		To string `json:"to"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	if request.To == "" {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Recipient email is required")
+		return
+	}
+
+	if err := h.emailService.SendTest(request.To); err != nil {
+		if errors.Is(err, services.ErrEmailDisabled) {
+			respond.Error(w, http.StatusNotFound, respond.CodeNotConfigured, "Email is not configured, set SMTP_HOST to enable it")
+			return
+		}
+		logger.FromContext(r.Context()).Error("Failed to send test email", "to", request.To, "error", err)
+		respond.Error(w, http.StatusBadGateway, respond.CodeUpstream, fmt.Sprintf("Failed to send test email: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Test email sent to %s", request.To),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// SendTestSlackHandler posts a test message to verify the Slack webhook
+func (h *AdminHandler) SendTestSlackHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.slackService.SendTest(r.Context()); err != nil {
+		if errors.Is(err, services.ErrSlackDisabled) {
+			respond.Error(w, http.StatusNotFound, respond.CodeNotConfigured, "Slack is not configured, set SLACK_WEBHOOK_URL to enable it")
+			return
+		}
+		logger.FromContext(r.Context()).Error("Failed to send test Slack message", "error", err)
+		respond.Error(w, http.StatusBadGateway, respond.CodeUpstream, fmt.Sprintf("Failed to send test Slack message: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Test message posted to Slack",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// SendTestDiscordHandler posts a test message to verify the Discord webhook
+func (h *AdminHandler) SendTestDiscordHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.discordService.SendTest(r.Context()); err != nil {
+		if errors.Is(err, services.ErrDiscordDisabled) {
+			respond.Error(w, http.StatusNotFound, respond.CodeNotConfigured, "Discord is not configured, set DISCORD_WEBHOOK_URL to enable it")
+			return
+		}
+		logger.FromContext(r.Context()).Error("Failed to send test Discord message", "error", err)
+		respond.Error(w, http.StatusBadGateway, respond.CodeUpstream, fmt.Sprintf("Failed to send test Discord message: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Test message posted to Discord",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// SendTestNtfyHandler publishes a test message to verify the ntfy topic
+func (h *AdminHandler) SendTestNtfyHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.ntfyService.SendTest(r.Context()); err != nil {
+		if errors.Is(err, services.ErrNtfyDisabled) {
+			respond.Error(w, http.StatusNotFound, respond.CodeNotConfigured, "ntfy is not configured, set NTFY_TOPIC to enable it")
+			return
+		}
+		logger.FromContext(r.Context()).Error("Failed to send test ntfy message", "error", err)
+		respond.Error(w, http.StatusBadGateway, respond.CodeUpstream, fmt.Sprintf("Failed to send test ntfy message: %v", err))
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Test message published to ntfy",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
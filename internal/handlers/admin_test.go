@@ -4,20 +4,37 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"watered/internal/activity"
+	"watered/internal/auth"
+	"watered/internal/config"
 	"watered/internal/models"
+	"watered/internal/notify/discord"
+	"watered/internal/notify/email"
+	"watered/internal/notify/ntfy"
+	"watered/internal/notify/slack"
+	"watered/internal/privacy"
+	"watered/internal/respond"
+	"watered/internal/services"
 	"watered/internal/storage"
+	"watered/internal/validate"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// newTestAdminHandler creates an admin handler with the default configuration
+func newTestAdminHandler(store storage.Storage) *AdminHandler {
+	return NewAdminHandler(store, config.Default())
+}
+
 func TestAdminHandler_GetConfigHandler(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -59,7 +76,7 @@ func TestAdminHandler_GetConfigHandler(t *testing.T) {
 			// Setup
 			store := storage.NewMemoryStorage()
 			tt.setupStorage(store)
-			handler := NewAdminHandler(store)
+			handler := newTestAdminHandler(store)
 
 			// Create request
 			req := httptest.NewRequest("GET", "/admin/config", nil)
@@ -110,6 +127,12 @@ func TestAdminHandler_UpdateTimeoutHandler(t *testing.T) {
 			setupStorage:   func(s *storage.MemoryStorage) {},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "should reject non-numeric timeout",
+			requestBody:    map[string]interface{}{"timeoutHours": "a day"},
+			setupStorage:   func(s *storage.MemoryStorage) {},
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:        "should update existing config",
 			requestBody: map[string]interface{}{"timeoutHours": 36},
@@ -131,7 +154,7 @@ func TestAdminHandler_UpdateTimeoutHandler(t *testing.T) {
 			// Setup
 			store := storage.NewMemoryStorage()
 			tt.setupStorage(store)
-			handler := NewAdminHandler(store)
+			handler := newTestAdminHandler(store)
 
 			// Create request
 			body, _ := json.Marshal(tt.requestBody)
@@ -156,11 +179,93 @@ func TestAdminHandler_UpdateTimeoutHandler(t *testing.T) {
 				config, err := store.GetAdminConfig()
 				require.NoError(t, err)
 				assert.Equal(t, tt.expectedHours, config.TimeoutHours)
+			} else {
+				var response respond.ErrorResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, respond.CodeValidation, response.Error.Code)
+				require.Len(t, response.Error.Fields, 1)
+				assert.Equal(t, "timeoutHours", response.Error.Fields[0].Field)
 			}
 		})
 	}
 }
 
+func TestAdminHandler_UpdateTimeoutHandler_BodyTooLarge(t *testing.T) {
+	handler := newTestAdminHandler(storage.NewMemoryStorage())
+	body := `{"timeoutHours": 24, "padding": "` + strings.Repeat("x", validate.MaxBodyBytes) + `"}`
+
+	rr := httptest.NewRecorder()
+	handler.UpdateTimeoutHandler(rr, httptest.NewRequest("PUT", "/admin/config/timeout", strings.NewReader(body)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	assert.Contains(t, rr.Body.String(), respond.CodeTooLarge)
+}
+
+func TestAdminHandler_UpdatePrivacyModeHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+		expectedMode   bool
+	}{
+		{"should enable privacy mode", `{"privacyMode": true}`, http.StatusOK, true},
+		{"should disable privacy mode", `{"privacyMode": false}`, http.StatusOK, false},
+		{"should require privacyMode field", `{}`, http.StatusBadRequest, false},
+		{"should reject invalid JSON", `{invalid`, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewMemoryStorage()
+			handler := newTestAdminHandler(store)
+
+			req := httptest.NewRequest("PUT", "/admin/config/privacy", strings.NewReader(tt.requestBody))
+			rr := httptest.NewRecorder()
+
+			handler.UpdatePrivacyModeHandler(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusOK {
+				config, err := store.GetAdminConfig()
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedMode, config.PrivacyMode)
+			}
+		})
+	}
+}
+
+// publisherFunc adapts a function to services.Publisher
+type publisherFunc func(messageType string, data interface{})
+
+func (f publisherFunc) Publish(messageType string, data interface{}) { f(messageType, data) }
+
+func TestAdminHandler_PublishesConfigUpdates(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	handler := newTestAdminHandler(store)
+
+	var published []map[string]interface{}
+	handler.SetPublisher(publisherFunc(func(messageType string, data interface{}) {
+		assert.Equal(t, services.ConfigUpdatedMessage, messageType)
+		published = append(published, data.(map[string]interface{}))
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.UpdateTimeoutHandler(rr, httptest.NewRequest("PUT", "/admin/config/timeout", strings.NewReader(`{"timeoutHours": 48}`)))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.UpdatePrivacyModeHandler(rr, httptest.NewRequest("PUT", "/admin/config/privacy", strings.NewReader(`{"privacyMode": true}`)))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.UpdateTimeoutHandler(rr, httptest.NewRequest("PUT", "/admin/config/timeout", strings.NewReader(`{"timeoutHours": 0}`)))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	require.Len(t, published, 2)
+	assert.Equal(t, 48, published[0]["timeout_hours"])
+	assert.Equal(t, true, published[1]["privacy_mode"])
+	assert.NotContains(t, published[1], "admin_emails")
+}
+
 func TestAdminHandler_AddUserHandler(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -194,12 +299,14 @@ func TestAdminHandler_AddUserHandler(t *testing.T) {
 			requestBody:    map[string]interface{}{"email": "invalid-email"},
 			setupStorage:   func(s *storage.MemoryStorage) {},
 			expectedStatus: http.StatusBadRequest,
+			shouldContain:  "must be a valid email address",
 		},
 		{
 			name:           "should reject empty email",
 			requestBody:    map[string]interface{}{"email": ""},
 			setupStorage:   func(s *storage.MemoryStorage) {},
 			expectedStatus: http.StatusBadRequest,
+			shouldContain:  "is required",
 		},
 	}
 
@@ -208,7 +315,7 @@ func TestAdminHandler_AddUserHandler(t *testing.T) {
 			// Setup
 			store := storage.NewMemoryStorage()
 			tt.setupStorage(store)
-			handler := NewAdminHandler(store)
+			handler := newTestAdminHandler(store)
 
 			// Create request
 			body, _ := json.Marshal(tt.requestBody)
@@ -227,11 +334,67 @@ func TestAdminHandler_AddUserHandler(t *testing.T) {
 				config, err := store.GetAdminConfig()
 				require.NoError(t, err)
 				assert.Contains(t, config.AllowedEmails, tt.shouldContain)
+			} else if tt.shouldContain != "" {
+				var response respond.ErrorResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, respond.CodeValidation, response.Error.Code)
+				assert.Equal(t, []respond.FieldError{{Field: "email", Message: tt.shouldContain}}, response.Error.Fields)
 			}
 		})
 	}
 }
 
+func TestAdminHandler_ViewerUsers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	handler := newTestAdminHandler(store)
+
+	add := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/admin/users", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		handler.AddUserHandler(rr, req)
+		return rr
+	}
+
+	rr := add(`{"email": "Sitter@example.com", "role": "viewer"}`)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	config, err := store.GetAdminConfig()
+	require.NoError(t, err)
+	assert.Contains(t, config.AllowedEmails, "sitter@example.com")
+	assert.Equal(t, []string{"sitter@example.com"}, config.ViewerEmails)
+
+	rr = add(`{"email": "boss@example.com", "role": "admin"}`)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	var response respond.ErrorResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, []respond.FieldError{{Field: "role", Message: "must be member or viewer"}}, response.Error.Fields)
+
+	// The user list shows each user's role
+	rr = httptest.NewRecorder()
+	handler.GetUsersHandler(rr, httptest.NewRequest("GET", "/admin/users", nil))
+	var users struct {
+		Users []userActivityEntry `json:"users"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &users))
+	roles := map[string]string{}
+	for _, user := range users.Users {
+		roles[user.Email] = user.Role
+	}
+	assert.Equal(t, "viewer", roles["sitter@example.com"])
+
+	// Removing a viewer also drops their role
+	req := httptest.NewRequest("DELETE", "/admin/users/sitter@example.com", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("email", "sitter@example.com")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr = httptest.NewRecorder()
+	handler.RemoveUserHandler(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	config, err = store.GetAdminConfig()
+	require.NoError(t, err)
+	assert.Empty(t, config.ViewerEmails)
+}
+
 func TestAdminHandler_RemoveUserHandler(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -278,7 +441,7 @@ func TestAdminHandler_RemoveUserHandler(t *testing.T) {
 			// Setup
 			store := storage.NewMemoryStorage()
 			tt.setupStorage(store)
-			handler := NewAdminHandler(store)
+			handler := newTestAdminHandler(store)
 
 			// Create request with URL parameter
 			req := httptest.NewRequest("DELETE", "/admin/users/"+tt.emailParam, nil)
@@ -305,6 +468,242 @@ func TestAdminHandler_RemoveUserHandler(t *testing.T) {
 	}
 }
 
+func TestAdminHandler_SetUserRoleHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"root@example.com", "sam@example.com"},
+		AdminEmails:   []string{"root@example.com"},
+	})
+	cfg := config.Default()
+	cfg.Auth.AdminEmails = []string{"root@example.com"}
+	handler := NewAdminHandler(store, cfg)
+	handler.SetAuditService(services.NewAuditService(store))
+
+	router := chi.NewRouter()
+	router.Put("/admin/users/{email}/role", handler.SetUserRoleHandler)
+	setRole := func(email, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/admin/users/"+email+"/role", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Promote a member, then demote them to a viewer
+	rr := setRole("Sam@example.com", `{"role":"admin"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	config, err := store.GetAdminConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root@example.com", "sam@example.com"}, config.AdminEmails)
+
+	rr = setRole("sam@example.com", `{"role":"viewer"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "viewer", response["role"])
+	assert.Equal(t, "admin", response["previousRole"])
+	config, err = store.GetAdminConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root@example.com"}, config.AdminEmails)
+	assert.Equal(t, []string{"sam@example.com"}, config.ViewerEmails)
+	assert.Contains(t, config.AllowedEmails, "sam@example.com")
+
+	entries, err := store.ListAuditEntries(models.AuditFilter{Action: models.AuditUserRole})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "sam@example.com", entries[0].Target)
+	assert.JSONEq(t, `"admin"`, string(entries[0].Old))
+	assert.JSONEq(t, `"viewer"`, string(entries[0].New))
+
+	// ADMIN_EMAILS admins can only be demoted there
+	rr = setRole("root@example.com", `{"role":"member"}`)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "ADMIN_EMAILS")
+
+	assert.Equal(t, http.StatusNotFound, setRole("nobody@example.com", `{"role":"admin"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, setRole("sam@example.com", `{"role":"owner"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, setRole("sam@example.com", `{}`).Code)
+}
+
+func TestAdminHandler_SetUserRoleHandler_RevokesSessions(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"boss@example.com", "deputy@example.com"},
+		AdminEmails:   []string{"boss@example.com", "deputy@example.com"},
+	})
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	handler := newTestAdminHandler(store)
+	handler.SetAuthService(authService)
+
+	router := chi.NewRouter()
+	router.Use(authService.AdminRequired)
+	router.Get("/admin/config", handler.GetConfigHandler)
+	router.Put("/admin/users/{email}/role", handler.SetUserRoleHandler)
+	serve := func(req *http.Request, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	deputy := sessionCookies(t, authService, "deputy@example.com")
+	require.Equal(t, http.StatusOK, serve(httptest.NewRequest("GET", "/admin/config", nil), deputy).Code)
+
+	rr := serve(httptest.NewRequest("PUT", "/admin/users/deputy@example.com/role", bytes.NewBufferString(`{"role":"member"}`)),
+		sessionCookies(t, authService, "boss@example.com"))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	// The demoted admin's existing session no longer reaches the admin API
+	rr = serve(httptest.NewRequest("GET", "/admin/config", nil), deputy)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestAdminHandler_SetUserRoleHandler_LastAdmin(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"sam@example.com"},
+		AdminEmails:   []string{"boss@example.com"},
+	})
+	handler := newTestAdminHandler(store)
+
+	req := httptest.NewRequest("PUT", "/admin/users/boss@example.com/role", bytes.NewBufferString(`{"role":"member"}`))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("email", "boss@example.com")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+	handler.SetUserRoleHandler(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "last admin")
+	config, err := store.GetAdminConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"boss@example.com"}, config.AdminEmails)
+}
+
+func TestAdminHandler_RemoveUserHandler_Guards(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"boss@example.com", "deputy@example.com", "sam@example.com"},
+		AdminEmails:   []string{"boss@example.com", "deputy@example.com"},
+	})
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	handler := newTestAdminHandler(store)
+
+	router := chi.NewRouter()
+	router.Use(authService.AdminRequired)
+	router.Delete("/admin/users/{email}", handler.RemoveUserHandler)
+	remove := func(as, email string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("DELETE", "/admin/users/"+email, nil)
+		for _, cookie := range sessionCookies(t, authService, as) {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Admins can't remove themselves
+	rr := remove("boss@example.com", "Boss@example.com")
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "can't remove yourself")
+
+	// Removing an admin takes their admin rights too
+	require.Equal(t, http.StatusOK, remove("boss@example.com", "deputy@example.com").Code)
+	config, err := store.GetAdminConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"boss@example.com"}, config.AdminEmails)
+
+	// The last admin stays, whoever asks
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"boss@example.com", "sam@example.com"},
+		AdminEmails:   []string{"boss@example.com"},
+	})
+	rr = remove("admin@example.com", "boss@example.com")
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "last admin")
+	config, err = store.GetAdminConfig()
+	require.NoError(t, err)
+	assert.Contains(t, config.AllowedEmails, "boss@example.com")
+
+	assert.Equal(t, http.StatusOK, remove("boss@example.com", "sam@example.com").Code)
+}
+
+func TestAdminHandler_DemoUsers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"demo@example.com", "guest@example.com", "admin@example.com"},
+		AdminEmails:   []string{"admin@example.com"},
+	})
+	handler := newTestAdminHandler(store)
+
+	router := chi.NewRouter()
+	router.Get("/admin/demo-users", handler.GetDemoUsersHandler)
+	router.Post("/admin/demo-users", handler.AddDemoUserHandler)
+	router.Delete("/admin/demo-users/{email}", handler.RemoveDemoUserHandler)
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	list := func() (users []demoUserEntry, saved bool) {
+		rr := serve("GET", "/admin/demo-users", "")
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response struct {
+			DemoUsers []demoUserEntry `json:"demoUsers"`
+			Saved     bool            `json:"saved"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response.DemoUsers, response.Saved
+	}
+
+	// Until an admin edits them, the demo users come from DEMO_USERS
+	users, saved := list()
+	assert.False(t, saved)
+	require.Len(t, users, len(auth.DefaultDemoUsers))
+	assert.Equal(t, demoUserEntry{Email: "admin@example.com", Role: "admin", Allowed: true}, users[3])
+	assert.False(t, users[1].Allowed, "user1@example.com isn't on the stored allowlist")
+
+	rr := serve("POST", "/admin/demo-users", `{"email":"Guest@example.com","name":"Guest"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	users, saved = list()
+	assert.True(t, saved)
+	require.Len(t, users, len(auth.DefaultDemoUsers)+1)
+	assert.Equal(t, demoUserEntry{Email: "guest@example.com", Name: "Guest", Role: "member", Allowed: true}, users[4])
+
+	assert.Equal(t, http.StatusConflict, serve("POST", "/admin/demo-users", `{"email":"guest@example.com"}`).Code)
+	rr = serve("POST", "/admin/demo-users", `{"email":"stranger@example.com"}`)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "isn't an allowed user")
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/admin/demo-users", `{"email":"guest"}`).Code)
+
+	// Removing everyone leaves the form empty rather than going back to
+	// DEMO_USERS
+	assert.Equal(t, http.StatusNotFound, serve("DELETE", "/admin/demo-users/stranger@example.com", "").Code)
+	for _, user := range users {
+		require.Equal(t, http.StatusOK, serve("DELETE", "/admin/demo-users/"+user.Email, "").Code)
+	}
+	users, saved = list()
+	assert.True(t, saved)
+	assert.Empty(t, users)
+	config, err := store.GetAdminConfig()
+	require.NoError(t, err)
+	assert.Contains(t, config.AllowedEmails, "guest@example.com", "Removed demo users stay allowed")
+}
+
 func TestAdminHandler_GetUsersHandler(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -341,7 +740,7 @@ func TestAdminHandler_GetUsersHandler(t *testing.T) {
 			// Setup
 			store := storage.NewMemoryStorage()
 			tt.setupStorage(store)
-			handler := NewAdminHandler(store)
+			handler := newTestAdminHandler(store)
 
 			// Create request
 			req := httptest.NewRequest("GET", "/admin/users", nil)
@@ -368,6 +767,44 @@ func TestAdminHandler_GetUsersHandler(t *testing.T) {
 	}
 }
 
+func TestAdminHandler_GetUsersHandler_Activity(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"user1@example.com", "user2@example.com", "admin@example.com"},
+		AdminEmails:   []string{"admin@example.com"},
+	})
+	tracker, err := activity.NewTracker(store)
+	require.NoError(t, err)
+	tracker.Touch("user1@example.com", "Firefox", "192.0.2.1")
+	tracker.Touch("former@example.com", "Safari", "192.0.2.2")
+
+	handler := newTestAdminHandler(store)
+	handler.SetActivityTracker(tracker)
+	rr := httptest.NewRecorder()
+	handler.GetUsersHandler(rr, httptest.NewRequest("GET", "/admin/users", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response struct {
+		Users []struct {
+			Email     string     `json:"email"`
+			Admin     bool       `json:"admin"`
+			LastSeen  *time.Time `json:"last_seen"`
+			UserAgent string     `json:"user_agent"`
+		} `json:"users"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+	// Only allowlisted users are listed, whether or not they were seen
+	require.Len(t, response.Users, 3)
+	assert.Equal(t, "user1@example.com", response.Users[0].Email)
+	assert.NotNil(t, response.Users[0].LastSeen)
+	assert.Equal(t, "Firefox", response.Users[0].UserAgent)
+	assert.Nil(t, response.Users[1].LastSeen)
+	assert.Equal(t, "admin@example.com", response.Users[2].Email)
+	assert.True(t, response.Users[2].Admin)
+}
+
 func TestAdminHandler_GetConfigWithEnvironmentVariables(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -408,27 +845,18 @@ func TestAdminHandler_GetConfigWithEnvironmentVariables(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set environment variables
-			if tt.allowedEmailsEnv != "" {
-				os.Setenv("ALLOWED_EMAILS", tt.allowedEmailsEnv)
-			} else {
-				os.Unsetenv("ALLOWED_EMAILS")
-			}
-			if tt.adminEmailsEnv != "" {
-				os.Setenv("ADMIN_EMAILS", tt.adminEmailsEnv)
-			} else {
-				os.Unsetenv("ADMIN_EMAILS")
-			}
-
-			// Ensure cleanup
-			defer func() {
-				os.Unsetenv("ALLOWED_EMAILS")
-				os.Unsetenv("ADMIN_EMAILS")
-			}()
+			// Configure the allow and admin lists
+			cfg, err := config.LoadFrom(func(key string) string {
+				return map[string]string{
+					"ALLOWED_EMAILS": tt.allowedEmailsEnv,
+					"ADMIN_EMAILS":   tt.adminEmailsEnv,
+				}[key]
+			})
+			require.NoError(t, err)
 
 			// Setup
 			store := storage.NewMemoryStorage()
-			handler := NewAdminHandler(store)
+			handler := NewAdminHandler(store, cfg)
 
 			// Create request
 			req := httptest.NewRequest("GET", "/admin/config", nil)
@@ -440,12 +868,12 @@ func TestAdminHandler_GetConfigWithEnvironmentVariables(t *testing.T) {
 			// Assert
 			assert.Equal(t, http.StatusOK, rr.Code)
 
-			var config models.AdminConfig
-			err := json.Unmarshal(rr.Body.Bytes(), &config)
+			var adminConfig models.AdminConfig
+			err = json.Unmarshal(rr.Body.Bytes(), &adminConfig)
 			require.NoError(t, err)
 
-			assert.Equal(t, tt.expectedAllowed, config.AllowedEmails)
-			assert.Equal(t, tt.expectedAdmins, config.AdminEmails)
+			assert.Equal(t, tt.expectedAllowed, adminConfig.AllowedEmails)
+			assert.Equal(t, tt.expectedAdmins, adminConfig.AdminEmails)
 		})
 	}
 }
@@ -463,7 +891,7 @@ func TestAdminHandler_TimeoutSynchronization(t *testing.T) {
 		}
 		store.UpdatePlantState(plant)
 
-		handler := NewAdminHandler(store)
+		handler := newTestAdminHandler(store)
 
 		// Create request
 		req := httptest.NewRequest("GET", "/admin/config", nil)
@@ -495,7 +923,7 @@ func TestAdminHandler_TimeoutSynchronization(t *testing.T) {
 		}
 		store.UpdatePlantState(plant)
 
-		handler := NewAdminHandler(store)
+		handler := newTestAdminHandler(store)
 
 		// Update timeout via admin endpoint
 		requestBody := `{"timeoutHours": 72}`
@@ -537,7 +965,7 @@ func TestAdminHandler_GetStatsHandler(t *testing.T) {
 	}
 	store.UpdateAdminConfig(config)
 
-	handler := NewAdminHandler(store)
+	handler := newTestAdminHandler(store)
 
 	// Create request
 	req := httptest.NewRequest("GET", "/admin/stats", nil)
@@ -557,4 +985,496 @@ func TestAdminHandler_GetStatsHandler(t *testing.T) {
 	assert.Equal(t, float64(1), response["adminUsers"].(float64))
 	assert.Equal(t, float64(48), response["timeoutHours"].(float64))
 	assert.Equal(t, "healthy", response["systemStatus"].(string))
+	assert.Equal(t, float64(0), response["totalWaterings"])
+	assert.Equal(t, []interface{}{}, response["users"])
+}
+
+func TestAdminHandler_GetStatsHandler_Anonymized(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24})
+	now := time.Now()
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Plant", TimeoutHours: 24, LastWatered: &now, WateredBy: "test@example.com"})
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 1, WateredAt: now, WateredBy: "test@example.com"})
+
+	anonymizer := privacy.NewAnonymizer("test-salt", false)
+	handler := newTestAdminHandler(store)
+	handler.SetAnonymizer(anonymizer)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"plain by default", "", "test@example.com"},
+		{"anonymized on request", "?anonymize=true", anonymizer.Email("test@example.com")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/admin/stats"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			handler.GetStatsHandler(rr, req)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tt.expected, response["wateredBy"])
+			assert.Equal(t, float64(1), response["totalWaterings"])
+			users := response["users"].([]interface{})
+			require.Len(t, users, 1)
+			assert.Equal(t, tt.expected, users[0].(map[string]interface{})["email"])
+		})
+	}
+}
+
+func TestAdminHandler_HistoryHandlers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, Timezone: "UTC"})
+	now := time.Now()
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Plant", TimeoutHours: 24, LastWatered: &now, WateredBy: "alice@example.com"})
+	store.UpdatePlantState(&models.PlantState{ID: 2, Name: "Fern", TimeoutHours: 48})
+	waterings := []struct {
+		plantID int
+		email   string
+		at      time.Time
+	}{
+		{1, "bob@example.com", now.Add(-72 * time.Hour)},
+		{2, models.SensorWaterer, now.Add(-48 * time.Hour)},
+		{1, "alice@example.com", now.Add(-40 * 24 * time.Hour)},
+		{1, "alice@example.com", now},
+	}
+	for _, w := range waterings {
+		store.AddWateringEvent(&models.PlantWateringEvent{PlantID: w.plantID, WateredAt: w.at, WateredBy: w.email})
+	}
+
+	anonymizer := privacy.NewAnonymizer("test-salt", false)
+	handler := newTestAdminHandler(store)
+	handler.SetAnonymizer(anonymizer)
+
+	get := func(handlerFunc http.HandlerFunc, target string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		rr := httptest.NewRecorder()
+		handlerFunc(rr, httptest.NewRequest("GET", target, nil))
+		var response map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+
+	t.Run("history lists the latest waterings", func(t *testing.T) {
+		rr, response := get(handler.GetHistoryHandler, "/admin/history?limit=2")
+		require.Equal(t, http.StatusOK, rr.Code)
+		events := response["events"].([]interface{})
+		require.Len(t, events, 2)
+		assert.Equal(t, "alice@example.com", events[0].(map[string]interface{})["watered_by"])
+		assert.Equal(t, models.SensorWaterer, events[1].(map[string]interface{})["watered_by"])
+		assert.NotNil(t, response["currentState"])
+	})
+
+	t.Run("history filters by plant and anonymizes", func(t *testing.T) {
+		rr, response := get(handler.GetHistoryHandler, "/admin/history?plant=1&anonymize=true")
+		require.Equal(t, http.StatusOK, rr.Code)
+		events := response["events"].([]interface{})
+		require.Len(t, events, 3)
+		assert.Equal(t, anonymizer.Email("alice@example.com"), events[0].(map[string]interface{})["watered_by"])
+	})
+
+	t.Run("daily counts are zero filled", func(t *testing.T) {
+		rr, response := get(handler.GetDailyHistoryHandler, "/admin/history/daily?days=7")
+		require.Equal(t, http.StatusOK, rr.Code)
+		counts := response["waterings"].([]interface{})
+		require.Len(t, counts, 7)
+		assert.Equal(t, float64(3), response["total"])
+		assert.Equal(t, "UTC", response["timezone"])
+		last := counts[6].(map[string]interface{})
+		assert.Equal(t, now.UTC().Format("2006-01-02"), last["date"])
+		assert.Equal(t, float64(1), last["waterings"])
+	})
+
+	t.Run("by user counts within the window", func(t *testing.T) {
+		rr, response := get(handler.GetUserHistoryHandler, "/admin/history/by-user?days=30&anonymize=true")
+		require.Equal(t, http.StatusOK, rr.Code)
+		users := response["users"].([]interface{})
+		require.Len(t, users, 3)
+		emails := []string{}
+		for _, user := range users {
+			assert.Equal(t, float64(1), user.(map[string]interface{})["waterings"])
+			emails = append(emails, user.(map[string]interface{})["email"].(string))
+		}
+		assert.Contains(t, emails, anonymizer.Email("alice@example.com"))
+		assert.Contains(t, emails, models.SensorWaterer)
+	})
+
+	t.Run("by user for one plant", func(t *testing.T) {
+		rr, response := get(handler.GetUserHistoryHandler, "/admin/history/by-user?plant=2")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, float64(2), response["plant_id"])
+		assert.Len(t, response["users"], 1)
+	})
+
+	invalid := []struct {
+		handler http.HandlerFunc
+		target  string
+	}{
+		{handler.GetDailyHistoryHandler, "/admin/history/daily?days=0"},
+		{handler.GetDailyHistoryHandler, "/admin/history/daily?days=400"},
+		{handler.GetUserHistoryHandler, "/admin/history/by-user?plant=abc"},
+		{handler.GetHistoryHandler, "/admin/history?limit=0"},
+	}
+	for _, tt := range invalid {
+		rr, _ := get(tt.handler, tt.target)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, tt.target)
+	}
+
+	rr, _ := get(handler.GetDailyHistoryHandler, "/admin/history/daily?plant=42")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestAdminHandler_GetWeeklyReportHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, Timezone: "UTC"})
+	now := time.Now()
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Fern", TimeoutHours: 24, LastWatered: &now, WateredBy: "sam@example.com"})
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 1, WateredAt: now.Add(-31 * time.Hour), WateredBy: "sam@example.com"})
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 1, WateredAt: now, WateredBy: "sam@example.com"})
+
+	anonymizer := privacy.NewAnonymizer("test-salt", false)
+	handler := newTestAdminHandler(store)
+	handler.SetAnonymizer(anonymizer)
+
+	t.Run("json", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.GetWeeklyReportHandler(rr, httptest.NewRequest("GET", "/admin/reports/weekly?anonymize=true", nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, float64(2), response["waterings"])
+		assert.Equal(t, float64(31), response["longest_gap_hours"])
+		assert.Equal(t, anonymizer.Email("sam@example.com"), response["most_active"].(map[string]interface{})["email"])
+		assert.Equal(t, "This week: 2 waterings, longest gap 31h, most active: "+anonymizer.Email("sam@example.com"), response["summary"])
+	})
+
+	t.Run("html", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.GetWeeklyReportHandler(rr, httptest.NewRequest("GET", "/admin/reports/weekly?format=html", nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Body.String(), "most active: sam@example.com")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.GetWeeklyReportHandler(rr, httptest.NewRequest("GET", "/admin/reports/weekly?format=pdf", nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestAdminHandler_MergeUsersHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"old@example.com"},
+	})
+	handler := newTestAdminHandler(store)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+		expectedEmails []string
+	}{
+		{"should reject invalid JSON", `{invalid`, http.StatusBadRequest, []string{"old@example.com"}},
+		{"should reject self merge", `{"from":"old@example.com","to":"old@example.com"}`, http.StatusBadRequest, []string{"old@example.com"}},
+		{"should preview without changes", `{"from":"old@example.com","to":"new@example.com","dryRun":true}`, http.StatusOK, []string{"old@example.com"}},
+		{"should merge users", `{"from":"old@example.com","to":"new@example.com"}`, http.StatusOK, []string{"new@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/admin/users/merge", strings.NewReader(tt.requestBody))
+			rr := httptest.NewRecorder()
+
+			handler.MergeUsersHandler(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			config, _ := store.GetAdminConfig()
+			assert.Equal(t, tt.expectedEmails, config.AllowedEmails)
+		})
+	}
+}
+
+func TestAdminHandler_IntegrityHandlers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"user@example.com"},
+		AdminEmails:   []string{"admin@example.com"},
+	})
+	handler := newTestAdminHandler(store)
+
+	rr := httptest.NewRecorder()
+	handler.GetIntegrityHandler(rr, httptest.NewRequest("GET", "/admin/integrity", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, false, response["ok"])
+	assert.Equal(t, 1.0, response["unresolved"])
+
+	rr = httptest.NewRecorder()
+	handler.RepairIntegrityHandler(rr, httptest.NewRequest("POST", "/admin/integrity/repair", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, true, response["ok"])
+
+	config, _ := store.GetAdminConfig()
+	assert.Contains(t, config.AllowedEmails, "admin@example.com")
+}
+
+// emailSenderFunc adapts a function to the services.EmailSender interface
+type emailSenderFunc func(msg email.Message) error
+
+func (f emailSenderFunc) Send(msg email.Message) error {
+	return f(msg)
+}
+
+func TestAdminHandler_SendTestEmailHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	handler := newTestAdminHandler(store)
+
+	var sent []email.Message
+	sender := emailSenderFunc(func(msg email.Message) error {
+		if msg.To[0] == "bounce@example.com" {
+			return errors.New("550 mailbox unavailable")
+		}
+		sent = append(sent, msg)
+		return nil
+	})
+
+	tests := []struct {
+		name           string
+		enabled        bool
+		body           string
+		expectedStatus int
+	}{
+		{"not configured", false, `{"to":"admin@example.com"}`, http.StatusNotFound},
+		{"invalid json", true, `{`, http.StatusBadRequest},
+		{"missing recipient", true, `{}`, http.StatusBadRequest},
+		{"smtp failure", true, `{"to":"bounce@example.com"}`, http.StatusBadGateway},
+		{"sent", true, `{"to":"admin@example.com"}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.enabled {
+				handler.SetEmailService(services.NewEmailService(store, sender))
+			} else {
+				handler.SetEmailService(services.NewEmailService(store, nil))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.SendTestEmailHandler(rr, httptest.NewRequest("POST", "/admin/email/test", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+
+	require.Len(t, sent, 1)
+	assert.Equal(t, []string{"admin@example.com"}, sent[0].To)
+}
+
+// slackSenderFunc adapts a function to the services.SlackSender interface
+type slackSenderFunc func(ctx context.Context, msg slack.Message) error
+
+func (f slackSenderFunc) Send(ctx context.Context, msg slack.Message) error {
+	return f(ctx, msg)
+}
+
+func TestAdminHandler_SendTestSlackHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	handler := newTestAdminHandler(store)
+
+	rr := httptest.NewRecorder()
+	handler.SendTestSlackHandler(rr, httptest.NewRequest("POST", "/admin/slack/test", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	handler.SetSlackService(services.NewSlackService(store, slackSenderFunc(func(ctx context.Context, msg slack.Message) error {
+		return errors.New("slack returned 403: invalid_token")
+	})))
+	rr = httptest.NewRecorder()
+	handler.SendTestSlackHandler(rr, httptest.NewRequest("POST", "/admin/slack/test", nil))
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	assert.Contains(t, rr.Body.String(), "invalid_token")
+
+	var sent []slack.Message
+	handler.SetSlackService(services.NewSlackService(store, slackSenderFunc(func(ctx context.Context, msg slack.Message) error {
+		sent = append(sent, msg)
+		return nil
+	})))
+	rr = httptest.NewRecorder()
+	handler.SendTestSlackHandler(rr, httptest.NewRequest("POST", "/admin/slack/test", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0].Text, "test message")
+}
+
+// discordSenderFunc adapts a function to the services.DiscordSender interface
+type discordSenderFunc func(ctx context.Context, msg discord.Message) error
+
+func (f discordSenderFunc) Send(ctx context.Context, msg discord.Message) error {
+	return f(ctx, msg)
+}
+
+func TestAdminHandler_SendTestDiscordHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	handler := newTestAdminHandler(store)
+
+	rr := httptest.NewRecorder()
+	handler.SendTestDiscordHandler(rr, httptest.NewRequest("POST", "/admin/discord/test", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	handler.SetDiscordService(services.NewDiscordService(store, discordSenderFunc(func(ctx context.Context, msg discord.Message) error {
+		return errors.New("discord returned 404: Unknown Webhook")
+	})))
+	rr = httptest.NewRecorder()
+	handler.SendTestDiscordHandler(rr, httptest.NewRequest("POST", "/admin/discord/test", nil))
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Unknown Webhook")
+
+	var sent []discord.Message
+	handler.SetDiscordService(services.NewDiscordService(store, discordSenderFunc(func(ctx context.Context, msg discord.Message) error {
+		sent = append(sent, msg)
+		return nil
+	})))
+	rr = httptest.NewRecorder()
+	handler.SendTestDiscordHandler(rr, httptest.NewRequest("POST", "/admin/discord/test", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0].Content, "test message")
+}
+
+// ntfySenderFunc adapts a function to the services.NtfySender interface
+type ntfySenderFunc func(ctx context.Context, msg ntfy.Message) error
+
+func (f ntfySenderFunc) Publish(ctx context.Context, msg ntfy.Message) error {
+	return f(ctx, msg)
+}
+
+func TestAdminHandler_SendTestNtfyHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	handler := newTestAdminHandler(store)
+
+	rr := httptest.NewRecorder()
+	handler.SendTestNtfyHandler(rr, httptest.NewRequest("POST", "/admin/ntfy/test", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	handler.SetNtfyService(services.NewNtfyService(store, ntfySenderFunc(func(ctx context.Context, msg ntfy.Message) error {
+		return errors.New("ntfy returned 403: forbidden")
+	}), ""))
+	rr = httptest.NewRecorder()
+	handler.SendTestNtfyHandler(rr, httptest.NewRequest("POST", "/admin/ntfy/test", nil))
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	assert.Contains(t, rr.Body.String(), "forbidden")
+
+	var sent []ntfy.Message
+	handler.SetNtfyService(services.NewNtfyService(store, ntfySenderFunc(func(ctx context.Context, msg ntfy.Message) error {
+		sent = append(sent, msg)
+		return nil
+	}), ""))
+	rr = httptest.NewRecorder()
+	handler.SendTestNtfyHandler(rr, httptest.NewRequest("POST", "/admin/ntfy/test", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0].Message, "test message")
+}
+
+func TestAdminHandler_GetEnvironmentHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	cfg := config.Default()
+	cfg.Auth.GoogleClientID = "client-id"
+	cfg.Auth.GoogleClientSecret = "google-secret"
+	cfg.SMTP.Host = "smtp.example.com"
+	cfg.SMTP.Password = "smtp-secret"
+	handler := NewAdminHandler(store, cfg)
+
+	req := httptest.NewRequest("GET", "/admin/environment", nil)
+	w := httptest.NewRecorder()
+	handler.GetEnvironmentHandler(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret\"")
+
+	var response struct {
+		Version       string            `json:"version"`
+		GoVersion     string            `json:"go_version"`
+		StorageDriver string            `json:"storage_driver"`
+		AuthMode      string            `json:"auth_mode"`
+		Modules       map[string]bool   `json:"modules"`
+		Settings      map[string]string `json:"settings"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEmpty(t, response.Version)
+	assert.NotEmpty(t, response.GoVersion)
+	assert.Equal(t, "memory", response.StorageDriver)
+	assert.Equal(t, config.AuthModeGoogle, response.AuthMode)
+	assert.True(t, response.Modules["email_reminders"])
+	assert.Equal(t, "********", response.Settings["SMTP_PASS"])
+	assert.Equal(t, "********", response.Settings["GOOGLE_CLIENT_SECRET"])
+	assert.Equal(t, "client-id", response.Settings["GOOGLE_CLIENT_ID"])
+}
+
+func TestAdminHandler_BackupAndRestore(t *testing.T) {
+	source := storage.NewMemoryStorage()
+	defer source.Close()
+	_, err := services.NewPlantService(source).WaterPlant("alice@example.com")
+	require.NoError(t, err)
+	require.NoError(t, source.SaveSession(&models.Session{ID: "alice", Email: "alice@example.com"}))
+
+	rr := httptest.NewRecorder()
+	newTestAdminHandler(source).BackupHandler(rr, httptest.NewRequest("GET", "/admin/backup", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	assert.Regexp(t, `^attachment; filename="watered-backup-\d{4}-\d{2}-\d{2}\.json"$`, rr.Header().Get("Content-Disposition"))
+	assert.NotContains(t, rr.Body.String(), `"sessions"`)
+	archive := rr.Body.Bytes()
+
+	target := storage.NewMemoryStorage()
+	defer target.Close()
+	handler := newTestAdminHandler(target)
+	handler.SetAuditService(services.NewAuditService(target))
+	_, err = services.NewPlantService(target).CreatePlant("Cactus", 336)
+	require.NoError(t, err)
+
+	restore := func(body []byte) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.RestoreHandler(rr, httptest.NewRequest("POST", "/admin/restore", bytes.NewReader(body)))
+		return rr
+	}
+
+	// Rejected backups change nothing
+	rr = restore([]byte(`{"format":"watered-backup","plants":[{"id":1,"name":"","timeout_hours":48}]}`))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "invalid backup")
+	assert.Equal(t, http.StatusBadRequest, restore([]byte(`{"plants":[]}`)).Code)
+	plants, err := target.ListPlants()
+	require.NoError(t, err)
+	assert.Len(t, plants, 2)
+
+	rr = restore(archive)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"plants":1`)
+	assert.Contains(t, rr.Body.String(), `"watering_events":1`)
+
+	plant, err := target.GetPlantState()
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", plant.WateredBy)
+	plants, err = target.ListPlants()
+	require.NoError(t, err)
+	assert.Len(t, plants, 1)
+
+	entries, err := target.ListAuditEntries(models.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, models.AuditBackupRestore, entries[0].Action)
 }
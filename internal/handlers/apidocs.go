@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"watered/internal/apidocs"
+	"watered/internal/auth"
+	"watered/internal/i18n"
+	"watered/internal/logger"
+	"watered/internal/render"
+	"watered/internal/respond"
+)
+
+// APIDocsHandlers serves the OpenAPI document and the Swagger UI page
+type APIDocsHandlers struct {
+	renderer    *render.Renderer
+	authService *auth.AuthService
+}
+
+// NewAPIDocsHandlers creates a new API docs handlers instance
+func NewAPIDocsHandlers(renderer *render.Renderer, authService *auth.AuthService) *APIDocsHandlers {
+	return &APIDocsHandlers{
+		renderer:    renderer,
+		authService: authService,
+	}
+}
+
+// GetOpenAPIHandler returns the OpenAPI 3 document
+// GET /api/v1/openapi.json
+func (h *APIDocsHandlers) GetOpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(apidocs.Spec())
+}
+
+// GetDocsHandler renders Swagger UI for the OpenAPI document. Requests made
+// from the page carry the session's CSRF token.
+// GET /api/v1/docs
+func (h *APIDocsHandlers) GetDocsHandler(w http.ResponseWriter, r *http.Request) {
+	csrfToken, err := h.authService.CSRFToken(w, r)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to get CSRF token", "error", err)
+	}
+
+	if err := h.renderer.Render(w, "api-docs.html", map[string]interface{}{
+		auth.CSRFTokenKey: csrfToken,
+		render.I18nKey:    i18n.FromContext(r.Context()),
+	}); err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Template error")
+		logger.FromContext(r.Context()).Error("Template error", "error", err)
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/render"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIDocsHandlers(t *testing.T) {
+	templates := template.Must(template.ParseFiles(filepath.Join("..", "..", "web", "templates", "api-docs.html")))
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	handler := NewAPIDocsHandlers(render.NewRenderer(templates, render.DefaultCSPPolicy()), authService)
+
+	t.Run("spec", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.GetOpenAPIHandler(rr, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+		var spec map[string]interface{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &spec))
+		assert.Equal(t, "3.0.3", spec["openapi"])
+	})
+
+	t.Run("swagger ui", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.GetDocsHandler(rr, httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "swagger-ui-bundle.js")
+		assert.Contains(t, rr.Body.String(), "/api/v1/openapi.json")
+		assert.Contains(t, rr.Header().Get("Content-Security-Policy"), "style-src 'self' https://cdn.jsdelivr.net")
+	})
+}
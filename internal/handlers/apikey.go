@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"watered/internal/auth"
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/respond"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// APIKeyHandlers contains API key management HTTP handlers
+type APIKeyHandlers struct {
+	auditor
+
+	authService *auth.AuthService
+}
+
+// NewAPIKeyHandlers creates a new API key handlers instance
+func NewAPIKeyHandlers(authService *auth.AuthService) *APIKeyHandlers {
+	return &APIKeyHandlers{
+		authService: authService,
+	}
+}
+
+// ListAPIKeysHandler returns all issued API keys without their secrets
+// GET /admin/apikeys
+func (h *APIKeyHandlers) ListAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.authService.ListAPIKeys()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list API keys", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to list API keys")
+		return
+	}
+
+	response := map[string]interface{}{
+		"apiKeys": keys,
+		"count":   len(keys),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateAPIKeyHandler issues a new API key. The key is only returned in
+// this response.
+// POST /admin/apikeys
+func (h *APIKeyHandlers) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	var request struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	key, plaintext, err := h.authService.CreateAPIKey(request.Name, user.Email)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidAPIKeyName) {
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+			return
+		}
+		logger.FromContext(r.Context()).Error("Failed to create API key", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to create API key")
+		return
+	}
+	key.Hash = ""
+	h.audit(r, models.AuditAPIKeyCreate, key.ID, nil, key)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "API key created. Copy it now, it will not be shown again.",
+		"key":     plaintext,
+		"apiKey":  key,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// RevokeAPIKeyHandler deletes an API key
+// DELETE /admin/apikeys/{id}
+func (h *APIKeyHandlers) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	revoked, err := h.authService.RevokeAPIKey(id, user.Email)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to revoke API key", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to revoke API key")
+		return
+	}
+	if !revoked {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "API key not found")
+		return
+	}
+	h.audit(r, models.AuditAPIKeyRevoke, id, nil, nil)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "API key revoked",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
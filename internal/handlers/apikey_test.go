@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyHandlers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{AdminEmails: []string{"admin@example.com"}})
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	handler := NewAPIKeyHandlers(authService)
+	cookies := sessionCookies(t, authService, "admin@example.com")
+
+	router := chi.NewRouter()
+	router.Get("/admin/apikeys", handler.ListAPIKeysHandler)
+	router.Post("/admin/apikeys", handler.CreateAPIKeyHandler)
+	router.Delete("/admin/apikeys/{id}", handler.RevokeAPIKeyHandler)
+
+	do := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Invalid requests
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/admin/apikeys", []byte("{")).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/admin/apikeys", []byte(`{"name":""}`)).Code)
+
+	// Create
+	rr := do("POST", "/admin/apikeys", []byte(`{"name":"Home Assistant"}`))
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	var created struct {
+		Key    string                 `json:"key"`
+		APIKey map[string]interface{} `json:"apiKey"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.Key)
+	assert.Equal(t, "Home Assistant", created.APIKey["name"])
+	assert.Equal(t, "admin@example.com", created.APIKey["created_by"])
+	assert.NotContains(t, created.APIKey, "hash")
+	assert.NotNil(t, authService.AuthenticateAPIKey(created.Key))
+
+	// List never includes secrets
+	rr = do("GET", "/admin/apikeys", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), created.Key)
+	assert.NotContains(t, rr.Body.String(), "hash")
+
+	var listed struct {
+		APIKeys []map[string]interface{} `json:"apiKeys"`
+		Count   int                      `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &listed))
+	assert.Equal(t, 1, listed.Count)
+
+	// Revoke
+	id := created.APIKey["id"].(string)
+	assert.Equal(t, http.StatusOK, do("DELETE", "/admin/apikeys/"+id, nil).Code)
+	assert.Nil(t, authService.AuthenticateAPIKey(created.Key))
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/admin/apikeys/"+id, nil).Code)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"watered/internal/auth"
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/respond"
+	"watered/internal/services"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// auditor records admin changes in the audit log. Handlers that make admin
+// changes embed it; without an audit service nothing is recorded.
+type auditor struct {
+	auditService *services.AuditService
+}
+
+// SetAuditService sets the audit log admin changes are recorded in
+func (a *auditor) SetAuditService(auditService *services.AuditService) {
+	a.auditService = auditService
+}
+
+// audit records a change made by the admin behind r. Failures are logged
+// rather than returned since the change has already been made.
+func (a *auditor) audit(r *http.Request, action, target string, oldValue, newValue interface{}) {
+	if a.auditService == nil {
+		return
+	}
+	if _, err := a.auditService.Record(auth.Actor(r), action, target, oldValue, newValue); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to record audit entry", "action", action, "target", target, "error", err)
+	}
+}
+
+// AuditHandlers contains audit log HTTP handlers
+type AuditHandlers struct {
+	auditService *services.AuditService
+}
+
+// NewAuditHandlers creates a new audit handlers instance
+func NewAuditHandlers(auditService *services.AuditService) *AuditHandlers {
+	return &AuditHandlers{
+		auditService: auditService,
+	}
+}
+
+// parseAuditQuery builds a filter and page from actor, action, target,
+// since, until, limit and offset query parameters
+func parseAuditQuery(r *http.Request) (filter models.AuditFilter, limit, offset int, problem string) {
+	query := r.URL.Query()
+	filter = models.AuditFilter{
+		Actor:  strings.TrimSpace(query.Get("actor")),
+		Action: strings.TrimSpace(query.Get("action")),
+		Target: strings.TrimSpace(query.Get("target")),
+	}
+
+	bounds := []struct {
+		name string
+		time *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}}
+	for _, bound := range bounds {
+		if value := query.Get(bound.name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, 0, 0, "Invalid " + bound.name + " parameter, expected RFC 3339"
+			}
+			*bound.time = parsed
+		}
+	}
+
+	limit = defaultAuditLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return filter, 0, 0, "Invalid limit parameter"
+		}
+		if limit > maxAuditLimit {
+			limit = maxAuditLimit
+		}
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		var err error
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return filter, 0, 0, "Invalid offset parameter"
+		}
+	}
+
+	return filter, limit, offset, ""
+}
+
+// GetAuditLogHandler returns admin changes, newest first. It filters by
+// actor, action, target and an RFC 3339 since/until range, and pages with
+// limit and offset.
+// GET /admin/audit
+func (h *AuditHandlers) GetAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	filter, limit, offset, problem := parseAuditQuery(r)
+	if problem != "" {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, problem)
+		return
+	}
+
+	page, err := h.auditService.List(filter, limit, offset)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list audit entries", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to get audit log")
+		return
+	}
+
+	response := map[string]interface{}{
+		"entries": page.Entries,
+		"count":   len(page.Entries),
+		"total":   page.Total,
+		"limit":   limit,
+		"offset":  offset,
+	}
+	if next := offset + len(page.Entries); next < page.Total {
+		response["next_offset"] = next
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditHandlers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, AdminEmails: []string{"admin@example.com"}})
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	auditService := services.NewAuditService(store)
+	adminHandler := NewAdminHandler(store, config.Default())
+	adminHandler.SetAuditService(auditService)
+	plantHandlers := NewPlantHandlers(services.NewPlantService(store), authService)
+	plantHandlers.SetAuditService(auditService)
+	cookies := sessionCookies(t, authService, "admin@example.com")
+
+	router := chi.NewRouter()
+	router.Use(authService.AdminRequired)
+	router.Put("/admin/config/timeout", adminHandler.UpdateTimeoutHandler)
+	router.Post("/admin/users", adminHandler.AddUserHandler)
+	router.Post("/api/plant/reset", plantHandlers.ResetPlantHandler)
+	router.Get("/admin/audit", NewAuditHandlers(auditService).GetAuditLogHandler)
+
+	do := func(method, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	require.Equal(t, http.StatusOK, do("PUT", "/admin/config/timeout", `{"timeoutHours":48}`).Code)
+	require.Equal(t, http.StatusCreated, do("POST", "/admin/users", `{"email":"new@example.com"}`).Code)
+	require.Equal(t, http.StatusOK, do("POST", "/api/plant/reset", "").Code)
+	// Rejected changes are not recorded
+	require.Equal(t, http.StatusBadRequest, do("PUT", "/admin/config/timeout", `{"timeoutHours":0}`).Code)
+
+	type auditLog struct {
+		Entries []struct {
+			Actor  string          `json:"actor"`
+			Action string          `json:"action"`
+			Target string          `json:"target"`
+			Old    json.RawMessage `json:"old"`
+			New    json.RawMessage `json:"new"`
+		} `json:"entries"`
+		Total      int  `json:"total"`
+		NextOffset *int `json:"next_offset"`
+	}
+	list := func(query string) auditLog {
+		rr := do("GET", "/admin/audit"+query, "")
+		require.Equal(t, http.StatusOK, rr.Code)
+		var log auditLog
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &log))
+		return log
+	}
+
+	log := list("")
+	require.Equal(t, 3, log.Total)
+	assert.Equal(t, models.AuditPlantReset, log.Entries[0].Action)
+	assert.Equal(t, "1", log.Entries[0].Target)
+	assert.Equal(t, models.AuditUserAdd, log.Entries[1].Action)
+	assert.Equal(t, "new@example.com", log.Entries[1].Target)
+
+	timeout := list("?action=" + models.AuditConfigTimeout)
+	require.Equal(t, 1, timeout.Total)
+	assert.Equal(t, "admin@example.com", timeout.Entries[0].Actor)
+	assert.JSONEq(t, "24", string(timeout.Entries[0].Old))
+	assert.JSONEq(t, "48", string(timeout.Entries[0].New))
+
+	page := list("?limit=2")
+	assert.Len(t, page.Entries, 2)
+	require.NotNil(t, page.NextOffset)
+	assert.Equal(t, 2, *page.NextOffset)
+	assert.Len(t, list("?limit=2&offset=2").Entries, 1)
+
+	assert.Empty(t, list("?actor=other@example.com").Entries)
+	assert.Equal(t, http.StatusBadRequest, do("GET", "/admin/audit?since=yesterday", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do("GET", "/admin/audit?offset=-1", "").Code)
+}
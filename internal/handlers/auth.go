@@ -2,15 +2,27 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
 
 	"watered/internal/auth"
+	"watered/internal/i18n"
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/ratelimit"
+	"watered/internal/render"
+	"watered/internal/respond"
+	"watered/internal/services"
 )
 
 // AuthHandlers contains all authentication-related HTTP handlers
 type AuthHandlers struct {
-	authService *auth.AuthService
+	authService   *auth.AuthService
+	inviteService *services.InviteService
+	lockout       *ratelimit.Lockout
+	renderer      *render.Renderer
 }
 
 // NewAuthHandlers creates a new auth handlers instance
@@ -20,30 +32,68 @@ func NewAuthHandlers(authService *auth.AuthService) *AuthHandlers {
 	}
 }
 
-// LoginHandler redirects users to Google OAuth2
-func (h *AuthHandlers) LoginHandler(w http.ResponseWriter, r *http.Request) {
-	// Generate state token for CSRF protection
-	state, err := h.authService.GenerateStateToken()
-	if err != nil {
-		log.Printf("Failed to generate state token: %v", err)
-		http.Error(w, "Failed to initiate login", http.StatusInternalServerError)
-		return
+// SetInviteService sets the service that admits people signing in through
+// an invite link. Without it invite links are ignored at sign-in.
+func (h *AuthHandlers) SetInviteService(inviteService *services.InviteService) {
+	h.inviteService = inviteService
+}
+
+// SetRenderer sets the renderer for the demo login form
+func (h *AuthHandlers) SetRenderer(renderer *render.Renderer) {
+	h.renderer = renderer
+}
+
+// SetLockout sets the lockout failed sign-ins count against. Without it
+// failures are not counted.
+func (h *AuthHandlers) SetLockout(lockout *ratelimit.Lockout) {
+	h.lockout = lockout
+}
+
+// lockoutKeys returns the lockout keys of a sign-in from r for email, which
+// may not be known yet
+func (h *AuthHandlers) lockoutKeys(r *http.Request, email string) []string {
+	keys := []string{h.authService.ClientIPKey(r)}
+	if email = strings.TrimSpace(strings.ToLower(email)); email != "" {
+		keys = append(keys, ratelimit.EmailKey(email))
 	}
+	return keys
+}
 
-	// Store state in session for validation
-	session, err := h.authService.GetSession(r)
-	if err != nil {
-		log.Printf("LoginHandler: Failed to get session - %v", err)
-		log.Printf("LoginHandler: User-Agent: %s", r.Header.Get("User-Agent"))
-		log.Printf("LoginHandler: Request from: %s", r.RemoteAddr)
-		http.Error(w, "Session initialization failed. Please clear your browser cookies and try again.", http.StatusInternalServerError)
-		return
+// lockedOut refuses a sign-in when the client address or email is locked
+// out after too many failures, reporting whether it did
+func (h *AuthHandlers) lockedOut(w http.ResponseWriter, r *http.Request, email string) bool {
+	if h.lockout == nil {
+		return false
+	}
+	if !h.lockout.Refuse(w, h.lockout.Locked(h.lockoutKeys(r, email)...)) {
+		return false
+	}
+	logger.FromContext(r.Context()).Warn("Refused sign-in from locked out client", "email", email)
+	return true
+}
+
+// signInFailed counts a failed sign-in against the client address and email
+func (h *AuthHandlers) signInFailed(r *http.Request, email string) {
+	if h.lockout != nil {
+		h.lockout.Fail(h.lockoutKeys(r, email)...)
 	}
+}
 
-	session.Values["oauth_state"] = state
-	if err := session.Save(r, w); err != nil {
-		log.Printf("LoginHandler: Failed to save session state - %v", err)
-		http.Error(w, "Session storage failed. Please clear your browser cookies and try again.", http.StatusInternalServerError)
+// signedIn clears the failed sign-ins of email
+func (h *AuthHandlers) signedIn(email string) {
+	if h.lockout != nil {
+		h.lockout.Succeed(ratelimit.EmailKey(strings.TrimSpace(strings.ToLower(email))))
+	}
+}
+
+// LoginHandler redirects users to Google OAuth2
+func (h *AuthHandlers) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	// The state token protects the callback from CSRF. It waits in a
+	// short-lived cookie, so nothing is stored until the user signs in.
+	state, err := h.authService.BeginLogin(w, r)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to start login", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to initiate login")
 		return
 	}
 
@@ -54,55 +104,64 @@ func (h *AuthHandlers) LoginHandler(w http.ResponseWriter, r *http.Request) {
 
 // CallbackHandler handles OAuth2 callback from Google
 func (h *AuthHandlers) CallbackHandler(w http.ResponseWriter, r *http.Request) {
+	if h.lockedOut(w, r, "") {
+		return
+	}
+
 	// Get the authorization code
 	code := r.FormValue("code")
 	if code == "" {
-		http.Error(w, "Authorization code not found", http.StatusBadRequest)
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Authorization code not found")
 		return
 	}
 
 	// Validate state parameter
-	state := r.FormValue("state")
-	session, err := h.authService.GetSession(r)
-	if err != nil {
-		log.Printf("CallbackHandler: Failed to get session - %v", err)
-		log.Printf("CallbackHandler: User-Agent: %s", r.Header.Get("User-Agent"))
-		log.Printf("CallbackHandler: Request from: %s", r.RemoteAddr)
-		log.Printf("CallbackHandler: State parameter: %s", state)
-		http.Error(w, "Session validation failed. Please clear your browser cookies and try logging in again.", http.StatusInternalServerError)
-		return
-	}
-
-	expectedState, ok := session.Values["oauth_state"].(string)
-	if !ok || state != expectedState {
-		log.Printf("Invalid state parameter: expected %s, got %s", expectedState, state)
-		http.Error(w, "Invalid state parameter", http.StatusBadRequest)
+	if !h.authService.ValidLoginState(r, r.FormValue("state")) {
+		logger.FromContext(r.Context()).Warn("Invalid or expired OAuth state parameter")
+		h.signInFailed(r, "")
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Invalid state parameter")
 		return
 	}
 
 	// Exchange code for token and get user info
 	userInfo, err := h.authService.HandleCallback(r.Context(), code)
 	if err != nil {
-		log.Printf("OAuth callback failed: %v", err)
-		http.Error(w, "Authentication failed", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("OAuth callback failed", "error", err)
+		h.signInFailed(r, "")
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Authentication failed")
+		return
+	}
+
+	if h.lockedOut(w, r, userInfo.Email) {
 		return
 	}
 
+	// Someone who followed an invite link joins the allowlist. Google only
+	// vouches for verified addresses, so unverified ones can't use invites.
+	if token := h.authService.PendingInvite(r); token != "" && h.inviteService != nil &&
+		userInfo.VerifiedEmail && !h.authService.IsUserAllowed(userInfo.Email) {
+		if _, err := h.inviteService.Accept(token, userInfo.Email); err != nil {
+			logger.FromContext(r.Context()).Warn("Failed to accept invite", "email", userInfo.Email, "error", err)
+		}
+	}
+
 	// Check if user is allowed
 	if !h.authService.IsUserAllowed(userInfo.Email) {
-		log.Printf("User %s not in allowlist", userInfo.Email)
-		http.Error(w, "Access denied: User not authorized", http.StatusForbidden)
+		logger.FromContext(r.Context()).Warn("User not in allowlist", "email", userInfo.Email)
+		h.signInFailed(r, userInfo.Email)
+		respond.Error(w, http.StatusForbidden, respond.CodeForbidden, "Access denied: User not authorized")
 		return
 	}
 
 	// Create session for user
 	if err := h.authService.CreateSession(w, r, userInfo); err != nil {
-		log.Printf("Failed to create session: %v", err)
-		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("Failed to create session", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to create session")
 		return
 	}
 
-	log.Printf("User %s (%s) logged in successfully", userInfo.Name, userInfo.Email)
+	h.signedIn(userInfo.Email)
+	logger.FromContext(r.Context()).Info("User logged in", "name", userInfo.Name, "email", userInfo.Email)
 
 	// Redirect to home page
 	http.Redirect(w, r, "/", http.StatusSeeOther)
@@ -115,13 +174,13 @@ func (h *AuthHandlers) LogoutHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Clear session
 	if err := h.authService.ClearSession(w, r); err != nil {
-		log.Printf("Failed to clear session: %v", err)
-		http.Error(w, "Logout failed", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("Failed to clear session", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Logout failed")
 		return
 	}
 
 	if user != nil {
-		log.Printf("User %s logged out", user.Email)
+		logger.FromContext(r.Context()).Info("User logged out", "email", user.Email)
 	}
 
 	// Redirect to login page
@@ -131,27 +190,49 @@ func (h *AuthHandlers) LogoutHandler(w http.ResponseWriter, r *http.Request) {
 // DemoLoginHandler provides demo authentication for testing (only in demo mode)
 func (h *AuthHandlers) DemoLoginHandler(w http.ResponseWriter, r *http.Request) {
 	if !h.authService.IsDemoMode() {
-		http.Error(w, "Demo login only available in demo mode", http.StatusNotFound)
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Demo login only available in demo mode")
 		return
 	}
 
-	// Handle GET for simple demo login (auto-login test user)
+	// Handle GET for simple demo login (auto-login the first demo user)
 	if r.Method == "GET" {
 		// Check if user is requesting a simple login
 		if r.URL.Query().Get("simple") == "true" {
-			// Create demo session with default test user
-			if err := h.authService.CreateDemoSession(w, r, "test@example.com", "Demo User", false); err != nil {
-				log.Printf("Failed to create demo session: %v", err)
-				http.Error(w, "Failed to create demo session: "+err.Error(), http.StatusBadRequest)
+			users, err := h.authService.DemoUsers()
+			if err != nil {
+				logger.FromContext(r.Context()).Error("Failed to list demo users", "error", err)
+				respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to list demo users")
+				return
+			}
+			if len(users) == 0 {
+				respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "No demo users are set up")
+				return
+			}
+			email := users[0].Email
+			name := h.demoUserName(email)
+			if h.lockedOut(w, r, email) {
 				return
 			}
 
-			log.Printf("Demo user %s (%s) logged in successfully", "Demo User", "test@example.com")
+			// Create demo session with the first demo user
+			if err := h.authService.CreateDemoSession(w, r, email, name, false); err != nil {
+				logger.FromContext(r.Context()).Error("Failed to create demo session", "error", err)
+				h.signInFailed(r, email)
+				respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Failed to create demo session: "+err.Error())
+				return
+			}
+
+			h.signedIn(email)
+			logger.FromContext(r.Context()).Info("Demo user logged in", "name", name, "email", email)
 
 			// Return JSON response for API users
 			if r.Header.Get("Accept") == "application/json" {
 				w.Header().Set("Content-Type", "application/json")
-				w.Write([]byte(`{"success": true, "message": "Demo login successful", "user": {"email": "test@example.com", "name": "Demo User"}}`))
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"success": true,
+					"message": "Demo login successful",
+					"user":    map[string]string{"email": email, "name": name},
+				})
 				return
 			}
 
@@ -167,115 +248,156 @@ func (h *AuthHandlers) DemoLoginHandler(w http.ResponseWriter, r *http.Request)
 		isAdmin := r.FormValue("admin") == "true"
 
 		if email == "" {
-			http.Error(w, "Email is required", http.StatusBadRequest)
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Email is required")
 			return
 		}
 
 		if name == "" {
-			name = "Demo User"
+			name = h.demoUserName(email)
+		}
+		if h.lockedOut(w, r, email) {
+			return
 		}
 
 		// Create demo session
 		if err := h.authService.CreateDemoSession(w, r, email, name, isAdmin); err != nil {
-			log.Printf("Failed to create demo session: %v", err)
-			http.Error(w, "Failed to create demo session: "+err.Error(), http.StatusBadRequest)
+			logger.FromContext(r.Context()).Error("Failed to create demo session", "error", err)
+			h.signInFailed(r, email)
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Failed to create demo session: "+err.Error())
 			return
 		}
 
-		log.Printf("Demo user %s (%s) logged in successfully", name, email)
+		h.signedIn(email)
+		logger.FromContext(r.Context()).Info("Demo user logged in", "name", name, "email", email)
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
 
 	// Show demo login form
-	html := `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Demo Login - Watered</title>
-    <link rel="icon" type="image/svg+xml" href="/static/favicon.svg">
-    <link rel="stylesheet" href="/static/styles.css">
-</head>
-<body>
-    <header class="header">
-        <div class="header-content">
-            <a href="/" class="logo">🌱 Watered</a>
-            <nav>
-                <ul class="nav-links">
-                    <li><a href="/">Home</a></li>
-                    <li><a href="/login">Login</a></li>
-                </ul>
-            </nav>
-        </div>
-    </header>
-
-    <div class="container">
-        <main class="login-container">
-            <h1 class="login-title">🧪 Demo Login</h1>
-            <p style="text-align: center; margin-bottom: 2rem; color: var(--muted-text);">
-                Test authentication without Google OAuth
-            </p>
-
-            <form method="post" style="margin-bottom: 2rem;">
-                <div class="form-group">
-                    <label for="email">Email:</label>
-                    <select id="email" name="email" required>
-                        <option value="">Select a demo user...</option>
-                        <option value="demo@example.com">demo@example.com (Regular User)</option>
-                        <option value="user1@example.com">user1@example.com (Regular User)</option>
-                        <option value="user2@example.com">user2@example.com (Regular User)</option>
-                        <option value="admin@example.com">admin@example.com (Admin)</option>
-                    </select>
-                </div>
-                
-                <div class="form-group">
-                    <label for="name">Display Name:</label>
-                    <input type="text" id="name" name="name" placeholder="Demo User" />
-                </div>
-
-                <div class="form-group">
-                    <label>
-                        <input type="checkbox" name="admin" value="true" /> 
-                        Login as Admin (only works for admin@example.com)
-                    </label>
-                </div>
-
-                <button type="submit" class="btn" style="width: 100%;">🚀 Demo Login</button>
-            </form>
-
-            <div style="background-color: var(--secondary-bg); padding: 1rem; border-radius: var(--border-radius); margin-top: 1rem;">
-                <h4 style="margin: 0 0 0.5rem 0; color: var(--accent-color);">Demo Mode Instructions:</h4>
-                <ul style="margin: 0; padding-left: 1.5rem; font-size: 0.9rem; color: var(--muted-text);">
-                    <li>Choose any of the pre-configured demo users</li>
-                    <li>Only admin@example.com can access admin features</li>
-                    <li>Sessions work exactly like real Google OAuth</li>
-                    <li>You can logout and test different users</li>
-                </ul>
-            </div>
-
-            <div style="text-align: center; margin-top: 1rem;">
-                <a href="/login" class="btn btn-secondary">← Back to Real Login</a>
-            </div>
-        </main>
-    </div>
-</body>
-</html>`
-	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(html))
+	users, err := h.authService.DemoUsers()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list demo users", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to list demo users")
+		return
+	}
+	options := make([]demoLoginUser, 0, len(users))
+	for _, user := range users {
+		options = append(options, demoLoginUser{DemoUser: user, Role: h.authService.UserRole(user.Email).String()})
+	}
+	if err := h.renderer.Render(w, "demo-login.html", map[string]interface{}{
+		"Users":        options,
+		render.I18nKey: i18n.FromContext(r.Context()),
+	}); err != nil {
+		logger.FromContext(r.Context()).Error("Template error", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Template error")
+	}
+}
+
+// demoLoginUser is a demo user offered on the demo login form, with the role
+// they sign in with
+type demoLoginUser struct {
+	models.DemoUser
+	Role string
+}
+
+// demoUserName returns the display name of the demo user with email, or
+// "Demo User" when they have none
+func (h *AuthHandlers) demoUserName(email string) string {
+	users, _ := h.authService.DemoUsers()
+	for _, user := range users {
+		if strings.EqualFold(user.Email, email) && user.Name != "" {
+			return user.Name
+		}
+	}
+	return "Demo User"
 }
 
-// StatusHandler returns the current authentication status
+// RecoveryLoginHandler exchanges a console-issued recovery token for a temporary admin session
+func (h *AuthHandlers) RecoveryLoginHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.authService.IsRecoveryEnabled() {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotConfigured, "Recovery mode is not enabled")
+		return
+	}
+
+	token := r.FormValue("token")
+	email := r.FormValue("email")
+	if token == "" {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Recovery token is required")
+		return
+	}
+	if h.lockedOut(w, r, email) {
+		return
+	}
+
+	if err := h.authService.RedeemRecoveryToken(w, r, token, email); err != nil {
+		h.signInFailed(r, email)
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Invalid or expired recovery token")
+		return
+	}
+
+	h.signedIn(email)
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
+
+// TokenHandler issues bearer tokens to native apps, which send them on /api
+// requests instead of a session cookie. A signed-in session posts no body to
+// exchange itself for a token pair; an app posts {"refresh_token": "..."} to
+// exchange its refresh token for a new pair before the access token expires.
+func (h *AuthHandlers) TokenHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	var tokens *models.AppTokens
+	var err error
+	if request.RefreshToken != "" {
+		tokens, err = h.authService.RefreshAppTokens(r, request.RefreshToken)
+		if errors.Is(err, auth.ErrInvalidRefreshToken) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="watered", error="invalid_token"`)
+			respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Invalid or expired refresh token")
+			return
+		}
+	} else {
+		if !h.authService.IsAuthenticated(r) {
+			respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Sign in or send a refresh token")
+			return
+		}
+		tokens, err = h.authService.IssueAppTokens(r)
+		if errors.Is(err, auth.ErrAppTokensNotAllowed) {
+			respond.Error(w, http.StatusForbidden, respond.CodeForbidden, "Recovery sessions can't be exchanged for app tokens")
+			return
+		}
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to issue app tokens", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to issue tokens")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// StatusHandler returns the current authentication status and, for signed-in
+// sessions, the CSRF token scripts must send with state-changing requests
 func (h *AuthHandlers) StatusHandler(w http.ResponseWriter, r *http.Request) {
 	type UserResponse struct {
 		Email   string `json:"email"`
 		Name    string `json:"name"`
 		IsAdmin bool   `json:"is_admin"`
+		// Role is admin, member or viewer; viewers can't water
+		Role string `json:"role"`
 	}
 
 	type AuthStatus struct {
 		Authenticated bool          `json:"authenticated"`
 		User          *UserResponse `json:"user,omitempty"`
+		CSRFToken     string        `json:"csrf_token,omitempty"`
 	}
 
 	user, err := h.authService.GetCurrentUser(r)
@@ -288,6 +410,12 @@ func (h *AuthHandlers) StatusHandler(w http.ResponseWriter, r *http.Request) {
 			Email:   user.Email,
 			Name:    user.Name,
 			IsAdmin: user.IsAdmin,
+			Role:    auth.RoleOf(user).String(),
+		}
+
+		status.CSRFToken, err = h.authService.CSRFToken(w, r)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("Failed to get CSRF token", "error", err)
 		}
 	}
 
@@ -2,11 +2,24 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
+	"html/template"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/ratelimit"
+	"watered/internal/realip"
+	"watered/internal/render"
+	"watered/internal/services"
 	"watered/internal/storage"
 )
 
@@ -14,7 +27,7 @@ func TestAuthHandlers_LoginHandler(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, config.AuthConfig{})
 	authHandlers := NewAuthHandlers(authService)
 
 	req := httptest.NewRequest("GET", "/auth/login", nil)
@@ -36,13 +49,22 @@ func TestAuthHandlers_LoginHandler(t *testing.T) {
 	if !contains(location, "accounts.google.com") {
 		t.Error("Expected redirect to Google OAuth endpoint")
 	}
+
+	// The state waits in the login cookie; no session is stored
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != auth.LoginCookieName {
+		t.Errorf("Expected only the login cookie to be set, got %+v", cookies)
+	}
+	if sessions, _ := store.ListSessions(); len(sessions) != 0 {
+		t.Errorf("Expected no stored session before sign-in, got %d", len(sessions))
+	}
 }
 
 func TestAuthHandlers_StatusHandler(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, config.AuthConfig{})
 	authHandlers := NewAuthHandlers(authService)
 
 	// Test unauthenticated status
@@ -68,13 +90,17 @@ func TestAuthHandlers_StatusHandler(t *testing.T) {
 	if response["user"] != nil {
 		t.Error("Expected user to be nil for unauthenticated user")
 	}
+
+	if _, ok := response["csrf_token"]; ok {
+		t.Error("Expected no CSRF token for unauthenticated user")
+	}
 }
 
 func TestAuthHandlers_StatusHandler_Authenticated(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, config.AuthConfig{})
 	authHandlers := NewAuthHandlers(authService)
 
 	// Create authenticated session
@@ -126,13 +152,17 @@ func TestAuthHandlers_StatusHandler_Authenticated(t *testing.T) {
 	if user["email"] != "test@example.com" {
 		t.Errorf("Expected email 'test@example.com', got '%v'", user["email"])
 	}
+
+	if token, _ := response["csrf_token"].(string); token == "" {
+		t.Error("Expected CSRF token for authenticated user")
+	}
 }
 
 func TestAuthHandlers_LogoutHandler(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, config.AuthConfig{})
 	authHandlers := NewAuthHandlers(authService)
 
 	// Create authenticated session first
@@ -172,6 +202,207 @@ func TestAuthHandlers_LogoutHandler(t *testing.T) {
 	}
 }
 
+func TestAuthHandlers_DemoLoginHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{
+		DemoMode:      true,
+		AllowedEmails: []string{"guest@example.com"},
+		AdminEmails:   []string{"host@example.com"},
+	})
+	store.UpdateAdminConfig(&models.AdminConfig{DemoUsers: []models.DemoUser{
+		{Email: "host@example.com", Name: "Host"},
+		{Email: "guest@example.com"},
+		{Email: "stranger@example.com"},
+	}})
+	authHandlers := NewAuthHandlers(authService)
+	templates := template.Must(template.ParseFiles(filepath.Join("..", "..", "web", "templates", "demo-login.html")))
+	authHandlers.SetRenderer(render.NewRenderer(templates, nil))
+
+	// The form offers the demo users who may sign in, with their role
+	w := httptest.NewRecorder()
+	authHandlers.DemoLoginHandler(w, httptest.NewRequest("GET", "/auth/demo-login", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"Host &lt;host@example.com&gt; (Admin)", "guest@example.com (Regular User)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected the form to offer %q, got %s", want, body)
+		}
+	}
+	if strings.Contains(body, "stranger@example.com") {
+		t.Error("Expected users who aren't allowed to be left off the form")
+	}
+
+	// Signing in without a name uses the demo user's
+	req := httptest.NewRequest("POST", "/auth/demo-login", strings.NewReader(url.Values{"email": {"host@example.com"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	authHandlers.DemoLoginHandler(w, req)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("Expected status %d, got %d", http.StatusSeeOther, w.Code)
+	}
+	req = httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	if user, err := authService.GetCurrentUser(req); err != nil || user.Name != "Host" {
+		t.Errorf("Expected to sign in as Host, got %+v (%v)", user, err)
+	}
+
+	// Taking an admin off the form stops them signing in through it, even
+	// though they are still allowed
+	store.UpdateAdminConfig(&models.AdminConfig{DemoUsers: []models.DemoUser{{Email: "guest@example.com"}}})
+	req = httptest.NewRequest("POST", "/auth/demo-login", strings.NewReader(url.Values{"email": {"host@example.com"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	authHandlers.DemoLoginHandler(w, req)
+	if w.Code != http.StatusBadRequest || len(w.Result().Cookies()) != 0 {
+		t.Errorf("Expected a removed demo user to be refused, got %d", w.Code)
+	}
+
+	// Simple login signs in as the first demo user
+	req = httptest.NewRequest("GET", "/auth/demo-login?simple=true", nil)
+	req.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	authHandlers.DemoLoginHandler(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"email":"guest@example.com"`) {
+		t.Errorf("Expected simple login as guest@example.com, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthHandlers_Lockout(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{DemoMode: true})
+	authHandlers := NewAuthHandlers(authService)
+	lockout := ratelimit.NewLockout(config.LockoutConfig{Threshold: 2, Base: time.Minute, Max: time.Hour})
+	lockout.SetAuditor(services.NewAuditService(store))
+	authHandlers.SetLockout(lockout)
+
+	signIn := func(addr, email string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/auth/demo-login", strings.NewReader(url.Values{"email": {email}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = addr + ":1234"
+		w := httptest.NewRecorder()
+		authHandlers.DemoLoginHandler(w, req)
+		return w
+	}
+
+	// Two free failures, then the third locks the address and email out
+	for i := 0; i < 3; i++ {
+		if w := signIn("10.0.0.1", "Intruder@example.com"); w.Code != http.StatusBadRequest {
+			t.Fatalf("Attempt %d: expected status %d, got %d", i+1, http.StatusBadRequest, w.Code)
+		}
+	}
+	w := signIn("10.0.0.1", "intruder@example.com")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Errorf("Expected a locked out address to get 429 with Retry-After 60, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := signIn("10.0.0.1", "demo@example.com"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected every email refused from a locked out address, got %d", w.Code)
+	}
+	if w := signIn("10.0.0.2", "intruder@example.com"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected a locked out email refused from every address, got %d", w.Code)
+	}
+	if w := signIn("10.0.0.2", "demo@example.com"); w.Code != http.StatusSeeOther {
+		t.Errorf("Expected other users to sign in from elsewhere, got %d", w.Code)
+	}
+
+	entries, err := store.ListAuditEntries(models.AuditFilter{Action: models.AuditAuthLockout})
+	if err != nil {
+		t.Fatalf("Failed to list audit entries: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Actor != ratelimit.LockoutActor {
+		t.Fatalf("Expected the address and email lockouts audited, got %+v", entries)
+	}
+	targets := []string{entries[0].Target, entries[1].Target}
+	if !slices.Contains(targets, "ip:10.0.0.1") || !slices.Contains(targets, "email:intruder@example.com") {
+		t.Errorf("Expected lockouts of ip:10.0.0.1 and email:intruder@example.com, got %v", targets)
+	}
+}
+
+func TestAuthHandlers_LockoutIgnoresSpoofedForwardedFor(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{DemoMode: true})
+	authHandlers := NewAuthHandlers(authService)
+	authHandlers.SetLockout(ratelimit.NewLockout(config.LockoutConfig{Threshold: 2, Base: time.Minute, Max: time.Hour}))
+	handler := realip.Middleware(nil)(http.HandlerFunc(authHandlers.DemoLoginHandler))
+
+	// Every attempt claims a new address and guesses a new email, so only
+	// the real address can tie them together
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 4; i++ {
+		email := fmt.Sprintf("intruder%d@example.com", i)
+		req := httptest.NewRequest("POST", "/auth/demo-login", strings.NewReader(url.Values{"email": {email}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i+1))
+		req.RemoteAddr = "203.0.113.7:1234"
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+	}
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the real address locked out despite spoofed X-Forwarded-For, got %d", w.Code)
+	}
+}
+
+func TestAuthHandlers_TokenHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	authHandlers := NewAuthHandlers(authService)
+
+	post := func(body string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/auth/token", strings.NewReader(body))
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		authHandlers.TokenHandler(w, req)
+		return w
+	}
+
+	if w := post("", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a signed-out request to get %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	// A signed-in session exchanges itself for a token pair
+	w := httptest.NewRecorder()
+	userInfo := &auth.GoogleUserInfo{ID: "123", Email: "test@example.com", Name: "Test User"}
+	if err := authService.CreateSession(w, httptest.NewRequest("GET", "/", nil), userInfo); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	w = post("", w.Result().Cookies())
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("Expected tokens for a signed-in session, got %d: %s", w.Code, w.Body.String())
+	}
+	var tokens models.AppTokens
+	if err := json.Unmarshal(w.Body.Bytes(), &tokens); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" || tokens.TokenType != "Bearer" {
+		t.Fatalf("Expected an access and refresh token, got %+v", tokens)
+	}
+
+	// The refresh token is exchanged for a new pair once
+	body := `{"refresh_token": "` + tokens.RefreshToken + `"}`
+	if w := post(body, nil); w.Code != http.StatusOK {
+		t.Errorf("Expected the refresh token to be exchanged, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := post(body, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a used refresh token to get %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+	if w := post("{", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected invalid JSON to get %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
 // Helper function to check if string contains substring
 func contains(s, substr string) bool {
 	return len(substr) <= len(s) && (substr == "" ||
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"watered/internal/badge"
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/services"
+)
+
+// maxBadgeLabelLength keeps custom labels to what fits on a badge
+const maxBadgeLabelLength = 32
+
+// BadgeHandlers serves embeddable SVG status badges
+type BadgeHandlers struct {
+	plantService *services.PlantService
+	shareService *services.ShareService
+	// requireToken only serves badges for plants shared with a share link
+	requireToken bool
+}
+
+// NewBadgeHandlers creates a new badge handlers instance
+func NewBadgeHandlers(plantService *services.PlantService, shareService *services.ShareService, requireToken bool) *BadgeHandlers {
+	return &BadgeHandlers{
+		plantService: plantService,
+		shareService: shareService,
+		requireToken: requireToken,
+	}
+}
+
+// GetBadgeHandler draws a badge such as "watered | 3h ago", green, yellow or
+// red by the plant's status. A share link token picks the plant instead of
+// ?plant=, and is required when BADGE_REQUIRE_TOKEN is set. Errors are drawn
+// as grey badges too, so a broken embed explains itself.
+// GET /badge.svg?plant=1&token=ws_...&label=watered
+func (h *BadgeHandlers) GetBadgeHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	label := strings.TrimSpace(query.Get("label"))
+	if label == "" || len(label) > maxBadgeLabelLength {
+		label = "watered"
+	}
+
+	var status *services.SharedPlantStatus
+	var err error
+	switch token := query.Get("token"); {
+	case token != "":
+		status, err = h.shareService.Status(token)
+	case h.requireToken:
+		writeBadge(w, r, http.StatusUnauthorized, label, "token required", badge.Grey)
+		return
+	default:
+		id := models.DefaultPlantID
+		if raw := query.Get("plant"); raw != "" {
+			if id, err = strconv.Atoi(raw); err != nil || id <= 0 {
+				writeBadge(w, r, http.StatusBadRequest, label, "invalid plant", badge.Grey)
+				return
+			}
+		}
+		status, err = h.plantService.WithContext(r.Context()).SharedStatusByID(id)
+	}
+
+	switch {
+	case errors.Is(err, services.ErrInvalidShareToken):
+		writeBadge(w, r, http.StatusNotFound, label, "invalid link", badge.Grey)
+	case errors.Is(err, services.ErrPlantNotFound):
+		writeBadge(w, r, http.StatusNotFound, label, "not found", badge.Grey)
+	case err != nil:
+		logger.FromContext(r.Context()).Error("Failed to get plant status for badge", "error", err)
+		writeBadge(w, r, http.StatusInternalServerError, label, "unavailable", badge.Grey)
+	default:
+		message, color := badgeMessage(status)
+		writeBadge(w, r, http.StatusOK, label, message, color)
+	}
+}
+
+// badgeMessage describes a plant's status as badge text and color
+func badgeMessage(status *services.SharedPlantStatus) (string, string) {
+	message := "never"
+	if status.LastWatered != nil {
+		message = badge.Age(time.Since(*status.LastWatered))
+	}
+
+	switch status.Status {
+	case models.HealthStatusHealthy:
+		return message, badge.Green
+	case models.HealthStatusNeedsWater, models.HealthStatusDue:
+		return message, badge.Yellow
+	case models.HealthStatusCritical:
+		return message, badge.Red
+	case models.HealthStatusPaused:
+		return "paused", badge.Grey
+	default:
+		return message, badge.Grey
+	}
+}
+
+// writeBadge answers with a badge. Successful badges carry an ETag and may be
+// cached for a minute, like the leaderboard; errors are not cached.
+func writeBadge(w http.ResponseWriter, r *http.Request, status int, label, message, color string) {
+	body := badge.SVG(label, message, color)
+
+	if status == http.StatusOK {
+		sum := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.WriteHeader(status)
+	w.Write(body)
+}
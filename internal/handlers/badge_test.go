@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"watered/internal/badge"
+	"watered/internal/models"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBadgeHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := services.NewPlantService(store)
+	shareService := services.NewShareService(store, plantService)
+
+	_, err := plantService.WaterPlantByIDAt(models.DefaultPlantID, "alice@example.com", time.Now().Add(-3*time.Hour-time.Minute))
+	require.NoError(t, err)
+	fern, err := plantService.CreatePlant("Fern", 1)
+	require.NoError(t, err)
+	_, err = plantService.WaterPlantByIDAt(fern.ID, "alice@example.com", time.Now().Add(-5*time.Hour))
+	require.NoError(t, err)
+	_, token, err := shareService.Create("readme", fern.ID, "admin@example.com")
+	require.NoError(t, err)
+
+	get := func(handler *BadgeHandlers, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rr := httptest.NewRecorder()
+		handler.GetBadgeHandler(rr, req)
+		return rr
+	}
+
+	t.Run("public", func(t *testing.T) {
+		handler := NewBadgeHandlers(plantService, shareService, false)
+
+		rr := get(handler, "/badge.svg", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "image/svg+xml", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "public, max-age=60", rr.Header().Get("Cache-Control"))
+		assert.Contains(t, rr.Body.String(), `aria-label="watered: 3h ago"`)
+		assert.Contains(t, rr.Body.String(), badge.Green)
+
+		rr = get(handler, "/badge.svg?plant="+strconv.Itoa(fern.ID)+"&label=fern", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `aria-label="fern: 5h ago"`)
+		assert.Contains(t, rr.Body.String(), badge.Red)
+		assert.NotContains(t, rr.Body.String(), "alice")
+
+		assert.Equal(t, http.StatusNotModified, get(handler, "/badge.svg?plant="+strconv.Itoa(fern.ID)+"&label=fern", http.Header{"If-None-Match": {rr.Header().Get("ETag")}}).Code)
+
+		rr = get(handler, "/badge.svg?plant=42", nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+		assert.Contains(t, rr.Body.String(), "not found")
+		assert.Equal(t, http.StatusBadRequest, get(handler, "/badge.svg?plant=abc", nil).Code)
+	})
+
+	t.Run("token required", func(t *testing.T) {
+		handler := NewBadgeHandlers(plantService, shareService, true)
+
+		rr := get(handler, "/badge.svg?plant=1", nil)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), "token required")
+
+		rr = get(handler, "/badge.svg?token="+token, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `aria-label="watered: 5h ago"`)
+
+		rr = get(handler, "/badge.svg?token=ws_bogus_token", nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Contains(t, rr.Body.String(), "invalid link")
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"watered/internal/logger"
+	"watered/internal/respond"
+	"watered/internal/services"
+)
+
+// ButtonHandlers receives presses from "I watered it" buttons
+type ButtonHandlers struct {
+	buttonService *services.ButtonService
+}
+
+// NewButtonHandlers creates a new button handlers instance
+func NewButtonHandlers(buttonService *services.ButtonService) *ButtonHandlers {
+	return &ButtonHandlers{
+		buttonService: buttonService,
+	}
+}
+
+// PressHandler waters the plant a button is placed with. The button is
+// identified by its token alone, so it only needs a URL and one header.
+// Repeat presses are answered 200 without recording another watering.
+// POST /api/v1/plant/water/button
+func (h *ButtonHandlers) PressHandler(w http.ResponseWriter, r *http.Request) {
+	plant, recorded, err := h.buttonService.Press(r.Header.Get(DeviceTokenHeader))
+	switch {
+	case errors.Is(err, services.ErrInvalidDeviceToken):
+		logger.FromContext(r.Context()).Warn("Rejected button press", "audit", true, "remote_addr", r.RemoteAddr)
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Unauthorized")
+		return
+	case errors.Is(err, services.ErrDeviceInactive):
+		logger.FromContext(r.Context()).Warn("Rejected press from inactive button", "audit", true, "remote_addr", r.RemoteAddr)
+		respond.Error(w, http.StatusForbidden, respond.CodeForbidden, "Device is inactive")
+		return
+	case err != nil:
+		logger.FromContext(r.Context()).Error("Failed to record button press", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to water plant")
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":  true,
+		"recorded": recorded,
+		"plant": map[string]interface{}{
+			"id":           plant.ID,
+			"name":         plant.Name,
+			"last_watered": plant.LastWatered,
+		},
+	}
+	if recorded {
+		response["message"] = "Plant watered successfully! 🌱"
+	} else {
+		response["message"] = "Already watered just now, press ignored"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if recorded {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(response)
+}
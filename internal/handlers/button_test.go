@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestButtonHandlers_PressHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := services.NewPlantService(store)
+	devices := services.NewDeviceService(store, plantService, config.SensorConfig{})
+	handler := NewButtonHandlers(services.NewButtonService(devices, plantService, config.ButtonConfig{Debounce: 10 * time.Minute}))
+
+	_, token, err := devices.Register("hallway", "Hallway button", models.DeviceKindButton, models.DefaultPlantID, "admin@example.com")
+	require.NoError(t, err)
+
+	press := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/plant/water/button", nil)
+		if token != "" {
+			req.Header.Set(DeviceTokenHeader, token)
+		}
+		rr := httptest.NewRecorder()
+		handler.PressHandler(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, press("").Code)
+	assert.Equal(t, http.StatusUnauthorized, press("wd_wrong").Code)
+
+	rr := press(token)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, true, response["recorded"])
+
+	// Pressing again straight away is fine but records nothing
+	rr = press(token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, false, response["recorded"])
+
+	events, _ := store.ListWateringEvents(models.DefaultPlantID)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.ButtonWaterer, events[0].WateredBy)
+
+	inactive := false
+	devices.Update("hallway", services.DeviceUpdate{Active: &inactive}, "admin@example.com")
+	assert.Equal(t, http.StatusForbidden, press(token).Code)
+}
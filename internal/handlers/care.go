@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/respond"
+	"watered/internal/services"
+	"watered/internal/validate"
+)
+
+// careTaskIDFromRequest resolves the plant ID and the {taskID} URL
+// parameter. It writes a 400 response and returns false if either is
+// malformed.
+func careTaskIDFromRequest(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	plantID, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+
+	taskID, err := strconv.Atoi(chi.URLParam(r, "taskID"))
+	if err != nil || taskID <= 0 {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Invalid task ID")
+		return 0, 0, false
+	}
+	return plantID, taskID, true
+}
+
+// writeCareTaskError maps a care task service error to an HTTP response,
+// answering 404 for unknown plants and tasks and status, code and message
+// otherwise
+func writeCareTaskError(w http.ResponseWriter, err error, status int, code, message string) {
+	switch {
+	case errors.Is(err, services.ErrCareTaskNotFound):
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Care task not found")
+	case errors.Is(err, services.ErrInvalidCareTask):
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+	case errors.Is(err, services.ErrDuplicateCareTask):
+		respond.Error(w, http.StatusConflict, respond.CodeConflict, err.Error())
+	default:
+		writePlantError(w, err, status, code, message)
+	}
+}
+
+// careTask builds the representation of a care task with its current status
+func (h *PlantHandlers) careTask(r *http.Request, task *models.CareTask) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             task.ID,
+		"plant_id":       task.PlantID,
+		"type":           task.Type,
+		"interval_hours": task.IntervalHours,
+		"last_done":      task.LastDone,
+		"last_done_by":   h.displayWateredBy(r, task.LastDoneBy),
+		"due_at":         task.DueAt(),
+		"status":         task.Status(),
+		"created_at":     task.CreatedAt,
+		"updated_at":     task.UpdatedAt,
+	}
+}
+
+// careTaskSettings returns the admin-managed settings of a care task, as
+// recorded in the audit log
+func careTaskSettings(task *models.CareTask) map[string]interface{} {
+	return map[string]interface{}{
+		"plant_id":       task.PlantID,
+		"type":           task.Type,
+		"interval_hours": task.IntervalHours,
+	}
+}
+
+// ListCareTasksHandler returns a plant's care tasks and its overall status
+// across watering and the tasks
+// GET /api/v1/plant/tasks, GET /api/v1/plants/{id}/tasks
+func (h *PlantHandlers) ListCareTasksHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	service := h.plantService.WithContext(r.Context())
+	plant, err := service.GetPlantByID(id)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to get plant", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to list care tasks")
+		return
+	}
+	tasks, err := service.ListCareTasksByID(id)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list care tasks", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to list care tasks")
+		return
+	}
+
+	views := make([]map[string]interface{}, len(tasks))
+	for i, task := range tasks {
+		views[i] = h.careTask(r, task)
+	}
+
+	response := map[string]interface{}{
+		"tasks":          views,
+		"count":          len(views),
+		"health_status":  plant.GetHealthStatus(),
+		"overall_status": models.OverallStatus(plant.GetHealthStatus(), tasks),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateCareTaskHandler adds a recurring care task to a plant (admin only)
+// POST /api/v1/plant/tasks, POST /api/v1/plants/{id}/tasks
+func (h *PlantHandlers) CreateCareTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Type          string `json:"type" validate:"required"`
+		IntervalHours *int   `json:"interval_hours" validate:"required,min=1,max=17520"`
+	}
+	if !validate.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	task, err := h.plantService.WithContext(r.Context()).CreateCareTaskByID(id, models.CareTaskType(req.Type), *req.IntervalHours)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("Failed to create care task", "error", err)
+		writeCareTaskError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to create care task")
+		return
+	}
+	h.audit(r, models.AuditCareTaskCreate, strconv.Itoa(task.ID), nil, careTaskSettings(task))
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Care task created",
+		"task":    h.careTask(r, task),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateCareTaskHandler changes how often a care task recurs (admin only)
+// PUT /api/v1/plant/tasks/{taskID}, PUT /api/v1/plants/{id}/tasks/{taskID}
+func (h *PlantHandlers) UpdateCareTaskHandler(w http.ResponseWriter, r *http.Request) {
+	plantID, taskID, ok := careTaskIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		IntervalHours *int `json:"interval_hours" validate:"required,min=1,max=17520"`
+	}
+	if !validate.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	var oldSettings interface{}
+	service := h.plantService.WithContext(r.Context())
+	if task, err := service.GetCareTaskByID(plantID, taskID); err == nil {
+		oldSettings = careTaskSettings(task)
+	}
+
+	task, err := service.UpdateCareTaskByID(plantID, taskID, *req.IntervalHours)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to update care task", "error", err)
+		writeCareTaskError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to update care task")
+		return
+	}
+	h.audit(r, models.AuditCareTaskUpdate, strconv.Itoa(taskID), oldSettings, careTaskSettings(task))
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Care task updated",
+		"task":    h.careTask(r, task),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteCareTaskHandler removes a care task and its history (admin only)
+// DELETE /api/v1/plant/tasks/{taskID}, DELETE /api/v1/plants/{id}/tasks/{taskID}
+func (h *PlantHandlers) DeleteCareTaskHandler(w http.ResponseWriter, r *http.Request) {
+	plantID, taskID, ok := careTaskIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	task, err := h.plantService.WithContext(r.Context()).DeleteCareTaskByID(plantID, taskID)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to delete care task", "error", err)
+		writeCareTaskError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to delete care task")
+		return
+	}
+	h.audit(r, models.AuditCareTaskDelete, strconv.Itoa(taskID), careTaskSettings(task), nil)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Care task deleted",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CompleteCareTaskHandler records that the current user did a care task,
+// restarting its interval
+// POST /api/v1/plant/tasks/{taskID}/complete, POST /api/v1/plants/{id}/tasks/{taskID}/complete
+func (h *PlantHandlers) CompleteCareTaskHandler(w http.ResponseWriter, r *http.Request) {
+	plantID, taskID, ok := careTaskIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	task, err := h.plantService.WithContext(r.Context()).CompleteCareTaskByID(plantID, taskID, user.Email)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to complete care task", "error", err)
+		writeCareTaskError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to complete care task")
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Care task completed",
+		"task":    h.careTask(r, task),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CareTaskHistoryHandler returns when a care task was done, oldest first
+// GET /api/v1/plant/tasks/{taskID}/history, GET /api/v1/plants/{id}/tasks/{taskID}/history
+func (h *PlantHandlers) CareTaskHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	plantID, taskID, ok := careTaskIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := h.plantService.WithContext(r.Context()).CareTaskHistoryByID(plantID, taskID)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to get care task history", "error", err)
+		writeCareTaskError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to get care task history")
+		return
+	}
+
+	views := make([]map[string]interface{}, len(events))
+	for i, event := range events {
+		views[i] = map[string]interface{}{
+			"id":      event.ID,
+			"type":    event.Type,
+			"done_at": event.DoneAt,
+			"done_by": h.displayWateredBy(r, event.DoneBy),
+		}
+	}
+
+	response := map[string]interface{}{
+		"events": views,
+		"count":  len(views),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlantHandlers_CareTasks(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{AdminEmails: []string{"admin@example.com"}})
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	auditService := services.NewAuditService(store)
+	handler := NewPlantHandlers(plantService, authService)
+	handler.SetAuditService(auditService)
+	cookies := sessionCookies(t, authService, "admin@example.com")
+
+	router := chi.NewRouter()
+	router.Get("/api/plant/tasks", handler.ListCareTasksHandler)
+	router.Post("/api/plant/tasks", handler.CreateCareTaskHandler)
+	router.Put("/api/plant/tasks/{taskID}", handler.UpdateCareTaskHandler)
+	router.Delete("/api/plant/tasks/{taskID}", handler.DeleteCareTaskHandler)
+	router.Post("/api/plant/tasks/{taskID}/complete", handler.CompleteCareTaskHandler)
+	router.Get("/api/plant/tasks/{taskID}/history", handler.CareTaskHistoryHandler)
+	router.Get("/api/plants/{id}/tasks", handler.ListCareTasksHandler)
+	router.Post("/api/plants/{id}/tasks/{taskID}/complete", handler.CompleteCareTaskHandler)
+
+	do := func(method, path, body string, signedIn bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if signedIn {
+			for _, cookie := range cookies {
+				req.AddCookie(cookie)
+			}
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// A freshly watered plant with no tasks is healthy
+	_, err := plantService.WaterPlant("admin@example.com")
+	require.NoError(t, err)
+
+	// Invalid tasks
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/api/plant/tasks", `{"type":"fertilize"}`, true).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/api/plant/tasks", `{"type":"prune","interval_hours":24}`, true).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/api/plant/tasks", `{"type":"mist","interval_hours":0}`, true).Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/api/plants/42/tasks", "", false).Code)
+
+	rr := do("POST", "/api/plant/tasks", `{"type":"mist","interval_hours":48}`, true)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var created struct {
+		Task struct {
+			ID     int                   `json:"id"`
+			Status models.CareTaskStatus `json:"status"`
+		} `json:"task"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.Equal(t, models.CareTaskStatusOK, created.Task.Status)
+	assert.Equal(t, http.StatusConflict, do("POST", "/api/plant/tasks", `{"type":"mist","interval_hours":24}`, true).Code)
+
+	// A task that has come due rolls up into the plant's overall status
+	task, err := store.GetCareTask(created.Task.ID)
+	require.NoError(t, err)
+	longAgo := time.Now().Add(-72 * time.Hour)
+	task.LastDone = &longAgo
+	require.NoError(t, store.SaveCareTask(task))
+
+	var listed struct {
+		Tasks []struct {
+			Status     models.CareTaskStatus `json:"status"`
+			LastDoneBy string                `json:"last_done_by"`
+		} `json:"tasks"`
+		Count         int                      `json:"count"`
+		HealthStatus  models.PlantHealthStatus `json:"health_status"`
+		OverallStatus models.CareStatus        `json:"overall_status"`
+	}
+	rr = do("GET", "/api/plant/tasks", "", false)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &listed))
+	require.Equal(t, 1, listed.Count)
+	assert.Equal(t, models.CareTaskStatusDue, listed.Tasks[0].Status)
+	assert.Equal(t, models.HealthStatusHealthy, listed.HealthStatus)
+	assert.Equal(t, models.CareStatusDue, listed.OverallStatus)
+
+	// Completing it restarts the interval and records who did it
+	path := "/api/plant/tasks/" + strconv.Itoa(created.Task.ID)
+	assert.Equal(t, http.StatusUnauthorized, do("POST", path+"/complete", "", false).Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/api/plant/tasks/99/complete", "", true).Code)
+	assert.Equal(t, http.StatusNotFound, do("POST", "/api/plants/42/tasks/"+strconv.Itoa(created.Task.ID)+"/complete", "", true).Code)
+	require.Equal(t, http.StatusOK, do("POST", path+"/complete", "", true).Code)
+
+	rr = do("GET", "/api/plant/tasks", "", false)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &listed))
+	assert.Equal(t, models.CareTaskStatusOK, listed.Tasks[0].Status)
+	assert.Equal(t, models.CareStatusHealthy, listed.OverallStatus)
+	assert.Equal(t, models.AnonymousWaterer, listed.Tasks[0].LastDoneBy, "viewers must not see who did the task")
+
+	rr = do("GET", path+"/history", "", true)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"done_by":"admin@example.com"`)
+	assert.Contains(t, rr.Body.String(), `"count":1`)
+
+	// Update and delete are audited
+	assert.Equal(t, http.StatusBadRequest, do("PUT", path, `{"interval_hours":20000}`, true).Code)
+	rr = do("PUT", path, `{"interval_hours":24}`, true)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"interval_hours":24`)
+	require.Equal(t, http.StatusOK, do("DELETE", path, "", true).Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", path+"/history", "", true).Code)
+
+	entries, err := store.ListAuditEntries(models.AuditFilter{})
+	require.NoError(t, err)
+	actions := make([]string, len(entries))
+	for i, entry := range entries {
+		actions[i] = entry.Action
+	}
+	assert.Equal(t, []string{models.AuditCareTaskDelete, models.AuditCareTaskUpdate, models.AuditCareTaskCreate}, actions)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"watered/internal/auth"
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/respond"
+	"watered/internal/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DeviceHandlers contains device management HTTP handlers
+type DeviceHandlers struct {
+	auditor
+
+	deviceService *services.DeviceService
+	authService   *auth.AuthService
+}
+
+// NewDeviceHandlers creates a new device handlers instance
+func NewDeviceHandlers(deviceService *services.DeviceService, authService *auth.AuthService) *DeviceHandlers {
+	return &DeviceHandlers{
+		deviceService: deviceService,
+		authService:   authService,
+	}
+}
+
+// ListDevicesHandler returns all registered devices with when they were
+// last seen, without their tokens
+// GET /admin/devices
+func (h *DeviceHandlers) ListDevicesHandler(w http.ResponseWriter, r *http.Request) {
+	devices, err := h.deviceService.List()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list devices", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to list devices")
+		return
+	}
+
+	response := map[string]interface{}{
+		"devices": devices,
+		"count":   len(devices),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetDeviceHandler returns one registered device
+// GET /admin/devices/{id}
+func (h *DeviceHandlers) GetDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	device, err := h.deviceService.Get(chi.URLParam(r, "id"))
+	if errors.Is(err, services.ErrDeviceNotFound) {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Device not found")
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to get device", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to get device")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(device)
+}
+
+// CreateDeviceHandler registers a sensor or button and issues its token.
+// The token is only returned in this response.
+// POST /admin/devices
+func (h *DeviceHandlers) CreateDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	var request struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Kind    string `json:"kind"`
+		PlantID int    `json:"plant_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	device, token, err := h.deviceService.Register(request.ID, request.Name, request.Kind, request.PlantID, user.Email)
+	switch {
+	case errors.Is(err, services.ErrInvalidDevice):
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+		return
+	case errors.Is(err, services.ErrDeviceExists):
+		respond.Error(w, http.StatusConflict, respond.CodeConflict, err.Error())
+		return
+	case err != nil:
+		logger.FromContext(r.Context()).Error("Failed to register device", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to register device")
+		return
+	}
+	device.TokenHash = ""
+	h.audit(r, models.AuditDeviceCreate, device.ID, nil, device)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Device registered. Copy the token now, it will not be shown again.",
+		"token":   token,
+		"device":  device,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateDeviceHandler renames a device, moves it to another plant or marks
+// it active or inactive
+// PATCH /admin/devices/{id}
+func (h *DeviceHandlers) UpdateDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	var update services.DeviceUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	old, _ := h.deviceService.Get(id)
+	device, err := h.deviceService.Update(id, update, user.Email)
+	switch {
+	case errors.Is(err, services.ErrDeviceNotFound):
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Device not found")
+		return
+	case errors.Is(err, services.ErrInvalidDevice):
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+		return
+	case err != nil:
+		logger.FromContext(r.Context()).Error("Failed to update device", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to update device")
+		return
+	}
+	h.audit(r, models.AuditDeviceUpdate, id, old, device)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(device)
+}
+
+// RotateDeviceTokenHandler issues a new token for a device, revoking the
+// old one. The token is only returned in this response.
+// POST /admin/devices/{id}/token
+func (h *DeviceHandlers) RotateDeviceTokenHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	device, token, err := h.deviceService.RotateToken(chi.URLParam(r, "id"), user.Email)
+	if errors.Is(err, services.ErrDeviceNotFound) {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Device not found")
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to rotate device token", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to rotate device token")
+		return
+	}
+	h.audit(r, models.AuditDeviceToken, device.ID, nil, nil)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Token rotated. Copy it now, it will not be shown again.",
+		"token":   token,
+		"device":  device,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteDeviceHandler removes a device. Its readings are kept.
+// DELETE /admin/devices/{id}
+func (h *DeviceHandlers) DeleteDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	old, _ := h.deviceService.Get(id)
+	deleted, err := h.deviceService.Delete(id, user.Email)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to delete device", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to delete device")
+		return
+	}
+	if !deleted {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Device not found")
+		return
+	}
+	h.audit(r, models.AuditDeviceDelete, id, old, nil)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Device deleted",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceHandlers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{AdminEmails: []string{"admin@example.com"}})
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	deviceService := services.NewDeviceService(store, plantService, config.SensorConfig{})
+	handler := NewDeviceHandlers(deviceService, authService)
+	sensors := NewSensorHandlers(services.NewSensorService(store, plantService, deviceService, config.SensorConfig{}), plantService)
+	cookies := sessionCookies(t, authService, "admin@example.com")
+
+	router := chi.NewRouter()
+	router.Get("/admin/devices", handler.ListDevicesHandler)
+	router.Post("/admin/devices", handler.CreateDeviceHandler)
+	router.Get("/admin/devices/{id}", handler.GetDeviceHandler)
+	router.Patch("/admin/devices/{id}", handler.UpdateDeviceHandler)
+	router.Post("/admin/devices/{id}/token", handler.RotateDeviceTokenHandler)
+	router.Delete("/admin/devices/{id}", handler.DeleteDeviceHandler)
+	router.Post("/api/sensors/{deviceID}/readings", sensors.RecordReadingHandler)
+
+	do := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	report := func(token string) int {
+		req := httptest.NewRequest("POST", "/api/sensors/kitchen/readings", strings.NewReader(`{"moisture": 40}`))
+		req.Header.Set(DeviceTokenHeader, token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// Invalid requests
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/admin/devices", []byte("{")).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/admin/devices", []byte(`{"id":"kitchen","kind":"doorbell","plant_id":1}`)).Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/admin/devices", []byte(`{"id":"kitchen","kind":"sensor","plant_id":42}`)).Code)
+
+	// Register
+	rr := do("POST", "/admin/devices", []byte(`{"id":"kitchen","name":"Kitchen sensor","kind":"sensor","plant_id":1}`))
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var created struct {
+		Token  string                 `json:"token"`
+		Device map[string]interface{} `json:"device"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.True(t, strings.HasPrefix(created.Token, services.DeviceTokenPrefix))
+	assert.Equal(t, true, created.Device["active"])
+	assert.Nil(t, created.Device["last_seen"])
+	assert.NotContains(t, created.Device, "token_hash")
+	assert.Equal(t, http.StatusConflict, do("POST", "/admin/devices", []byte(`{"id":"kitchen","kind":"sensor","plant_id":1}`)).Code)
+
+	// The device reports, and the list shows when it was last seen
+	assert.Equal(t, http.StatusCreated, report(created.Token))
+	rr = do("GET", "/admin/devices", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), created.Token)
+	assert.NotContains(t, rr.Body.String(), "token_hash")
+	var listed struct {
+		Devices []map[string]interface{} `json:"devices"`
+		Count   int                      `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &listed))
+	require.Equal(t, 1, listed.Count)
+	assert.NotNil(t, listed.Devices[0]["last_seen"])
+
+	// Deactivate
+	rr = do("PATCH", "/admin/devices/kitchen", []byte(`{"active":false}`))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"active":false`)
+	assert.Equal(t, http.StatusForbidden, report(created.Token))
+	assert.Equal(t, http.StatusBadRequest, do("PATCH", "/admin/devices/kitchen", []byte(`{"plant_id":42}`)).Code)
+	assert.Equal(t, http.StatusNotFound, do("PATCH", "/admin/devices/balcony", []byte(`{"active":true}`)).Code)
+	do("PATCH", "/admin/devices/kitchen", []byte(`{"active":true}`))
+
+	// Rotate
+	rr = do("POST", "/admin/devices/kitchen/token", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var rotated struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rotated))
+	assert.Equal(t, http.StatusUnauthorized, report(created.Token))
+	assert.Equal(t, http.StatusCreated, report(rotated.Token))
+
+	// Delete
+	assert.Equal(t, http.StatusOK, do("GET", "/admin/devices/kitchen", nil).Code)
+	assert.Equal(t, http.StatusOK, do("DELETE", "/admin/devices/kitchen", nil).Code)
+	assert.Equal(t, http.StatusNotFound, do("GET", "/admin/devices/kitchen", nil).Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/admin/devices/kitchen", nil).Code)
+}
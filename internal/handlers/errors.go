@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"watered/internal/i18n"
+	"watered/internal/logger"
+	"watered/internal/render"
+	"watered/internal/respond"
+)
+
+// ErrorHandlers answer requests the router can't serve: unknown routes,
+// unsupported methods and handlers that panic. API requests get the JSON
+// error envelope and browsers a styled page.
+type ErrorHandlers struct {
+	renderer *render.Renderer
+	// routes indexes every route without subrouters, for the Allow header
+	routes *chi.Mux
+}
+
+// NewErrorHandlers creates a new error handlers instance
+func NewErrorHandlers(renderer *render.Renderer) *ErrorHandlers {
+	return &ErrorHandlers{renderer: renderer}
+}
+
+// NotFoundHandler answers requests for routes that don't exist
+func (h *ErrorHandlers) NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	h.write(w, r, http.StatusNotFound, respond.CodeNotFound, "No such route", "error.not_found")
+}
+
+// MethodNotAllowedHandler answers requests using a method the route doesn't
+// support, listing the ones it does in the Allow header
+func (h *ErrorHandlers) MethodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	if allowed := h.allowedMethods(r); len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+	}
+	h.write(w, r, http.StatusMethodNotAllowed, respond.CodeMethodNotAllowed, "Method not allowed", "error.method_not_allowed")
+}
+
+// Recoverer turns a panicking handler into a 500 response and logs the panic
+// with its stack. It replaces chi's middleware.Recoverer, whose plain text
+// reply browsers and API clients would both get.
+func (h *ErrorHandlers) Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// net/http uses this panic to abort a response on purpose
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			logger.FromContext(r.Context()).Error("Panic serving request", "panic", rec, "stack", string(debug.Stack()))
+			// A hijacked WebSocket connection has no response to write to
+			if r.Header.Get("Connection") != "Upgrade" {
+				h.write(w, r, http.StatusInternalServerError, respond.CodeInternal, "Internal server error", "error.internal")
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SetRoutes indexes the router's routes so 405 responses can list the
+// methods a path takes. chi only sets the Allow header in its own 405
+// handler, and its Match reports any method as allowed once a parent router
+// matches, so the index flattens the routes into one router. Call it after
+// every route is registered.
+func (h *ErrorHandlers) SetRoutes(routes chi.Routes) error {
+	index := chi.NewRouter()
+	marker := http.NotFoundHandler()
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		index.Method(method, route, marker)
+		// A subrouter's "/" route also answers without the trailing slash
+		if trimmed := strings.TrimSuffix(route, "/"); trimmed != route && trimmed != "" {
+			index.Method(method, trimmed, marker)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.routes = index
+	return nil
+}
+
+// allowedMethods returns the methods the request's path is routed for
+func (h *ErrorHandlers) allowedMethods(r *http.Request) []string {
+	if h.routes == nil {
+		return nil
+	}
+	var allowed []string
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions} {
+		if h.routes.Match(chi.NewRouteContext(), method, r.URL.Path) {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// write replies with the JSON error envelope to API requests and clients
+// asking for JSON, and with the error page to everyone else. key names the
+// page's catalog messages, key + ".title" and key + ".detail".
+func (h *ErrorHandlers) write(w http.ResponseWriter, r *http.Request, status int, code, message, key string) {
+	if strings.HasPrefix(r.URL.Path, "/api/") || wantsJSON(r) {
+		respond.Error(w, status, code, message)
+		return
+	}
+
+	printer := i18n.FromContext(r.Context())
+	w.Header().Set("Cache-Control", "no-store")
+	if err := h.renderer.RenderStatus(w, status, "error.html", map[string]interface{}{
+		"Status":       status,
+		"Title":        printer.T(key + ".title"),
+		"Detail":       printer.T(key + ".detail"),
+		render.I18nKey: printer,
+	}); err != nil {
+		// The status has been sent by now
+		logger.FromContext(r.Context()).Error("Template error", "error", err)
+		io.WriteString(w, http.StatusText(status))
+	}
+}
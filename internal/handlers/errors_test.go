@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"watered/internal/i18n"
+	"watered/internal/render"
+	"watered/internal/respond"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestErrorRouter() chi.Router {
+	templates := template.Must(template.ParseFiles(filepath.Join("..", "..", "web", "templates", "error.html")))
+	handlers := NewErrorHandlers(render.NewRenderer(templates, nil))
+
+	r := chi.NewRouter()
+	r.Use(i18n.Middleware(func(r *http.Request) string { return "" }))
+	r.Use(handlers.Recoverer)
+	r.NotFound(handlers.NotFoundHandler)
+	r.MethodNotAllowed(handlers.MethodNotAllowedHandler)
+	r.Get("/panic", func(w http.ResponseWriter, r *http.Request) { panic("boom") })
+	r.Get("/api/v1/panic", func(w http.ResponseWriter, r *http.Request) { panic("boom") })
+	r.Route("/api/v1/plant", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+		r.Post("/water", func(w http.ResponseWriter, r *http.Request) {})
+	})
+	if err := handlers.SetRoutes(r); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func serveError(router http.Handler, method, path, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func decodeErrorCode(t *testing.T, rr *httptest.ResponseRecorder) string {
+	t.Helper()
+	var response respond.ErrorResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	return response.Error.Code
+}
+
+func TestErrorHandlers_NotFound(t *testing.T) {
+	router := newTestErrorRouter()
+
+	t.Run("page", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/no-such-page", nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml")
+		req.Header.Set("Accept-Language", "es")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Body.String(), "Página no encontrada")
+		assert.Contains(t, rr.Body.String(), "Error 404")
+	})
+
+	t.Run("api", func(t *testing.T) {
+		rr := serveError(router, http.MethodGet, "/api/v1/no-such-route", "text/html")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, respond.CodeNotFound, decodeErrorCode(t, rr))
+	})
+
+	t.Run("accept json", func(t *testing.T) {
+		rr := serveError(router, http.MethodGet, "/no-such-page", "application/json")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, respond.CodeNotFound, decodeErrorCode(t, rr))
+	})
+}
+
+func TestErrorHandlers_MethodNotAllowed(t *testing.T) {
+	rr := serveError(newTestErrorRouter(), http.MethodDelete, "/api/v1/plant", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	assert.Equal(t, http.MethodGet, rr.Header().Get("Allow"))
+	assert.Equal(t, respond.CodeMethodNotAllowed, decodeErrorCode(t, rr))
+}
+
+func TestErrorHandlers_Recoverer(t *testing.T) {
+	router := newTestErrorRouter()
+
+	rr := serveError(router, http.MethodGet, "/panic", "text/html")
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Something went wrong")
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+
+	rr = serveError(router, http.MethodGet, "/api/v1/panic", "")
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, respond.CodeInternal, decodeErrorCode(t, rr))
+
+	// Aborting a response on purpose still reaches net/http
+	abort := NewErrorHandlers(nil).Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		abort.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"watered/internal/logger"
+	"watered/internal/privacy"
+	"watered/internal/respond"
+)
+
+// eventKeepAliveInterval is how often an idle event stream sends a comment so
+// proxies do not close the connection
+const eventKeepAliveInterval = 25 * time.Second
+
+// PlantEventsHandler streams plant status changes for all plants as
+// Server-Sent Events until the client disconnects
+// GET /api/v1/plant/events
+func (h *PlantHandlers) PlantEventsHandler(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	// The stream outlives the server's write timeout
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && err != http.ErrNotSupported {
+		logger.FromContext(r.Context()).Error("Failed to clear write deadline for event stream", "error", err)
+	}
+
+	role := h.authService.CallerRole(r)
+	events, unsubscribe := h.plantService.Events().Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	if err := rc.Flush(); err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Streaming not supported")
+		return
+	}
+
+	// Ask browsers to reconnect after 5s if the connection drops
+	fmt.Fprint(w, "retry: 5000\n\n")
+	rc.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case event, ok := <-events:
+			if !ok {
+				// The server is shutting down
+				return
+			}
+			event.WateredBy = h.displayWateredBy(r, event.WateredBy)
+			data, err := json.Marshal(privacy.Mask(event, role))
+			if err != nil {
+				logger.FromContext(r.Context()).Error("Failed to encode plant event", "error", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
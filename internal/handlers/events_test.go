@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlantEventsHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	plantService := services.NewPlantService(store)
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	handlers := NewPlantHandlers(plantService, authService)
+
+	server := httptest.NewServer(http.HandlerFunc(handlers.PlantEventsHandler))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() (string, string) {
+		t.Helper()
+		var name, data string
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimRight(line, "\n")
+			if line == "" {
+				return name, data
+			}
+			if value, ok := strings.CutPrefix(line, "event: "); ok {
+				name = value
+			}
+			if value, ok := strings.CutPrefix(line, "data: "); ok {
+				data = value
+			}
+		}
+	}
+
+	// The retry hint is sent once the stream is subscribed
+	name, _ := readEvent()
+	assert.Empty(t, name)
+
+	_, err = plantService.WaterPlant("user@example.com")
+	require.NoError(t, err)
+
+	name, data := readEvent()
+	assert.Equal(t, "watered", name)
+
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &event))
+	assert.Equal(t, float64(1), event["plant_id"])
+	// Anonymous subscribers are viewers and never see who watered
+	assert.NotContains(t, event, "watered_by")
+	assert.Equal(t, "healthy", event["status"].(map[string]interface{})["status"])
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"watered/internal/features"
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/respond"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetFeaturesHandler lists the feature flags, whether each is on and
+// whether an admin overrode its default.
+// GET /admin/features
+func (h *AdminHandler) GetFeaturesHandler(w http.ResponseWriter, r *http.Request) {
+	states, err := h.features.List()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list feature flags", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to list feature flags")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"features": states})
+}
+
+// UpdateFeatureHandler switches a feature flag on or off, overriding its
+// FEATURE_* default until the override is removed.
+// PUT /admin/features/{name}
+func (h *AdminHandler) UpdateFeatureHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+	if request.Enabled == nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "enabled is required")
+		return
+	}
+	h.setFeature(w, r, request.Enabled)
+}
+
+// ResetFeatureHandler removes an admin's override, so the flag follows its
+// FEATURE_* default again.
+// DELETE /admin/features/{name}
+func (h *AdminHandler) ResetFeatureHandler(w http.ResponseWriter, r *http.Request) {
+	h.setFeature(w, r, nil)
+}
+
+// setFeature stores the override for the flag named in the URL, removing it
+// when enabled is nil, and responds with the flag's new state
+func (h *AdminHandler) setFeature(w http.ResponseWriter, r *http.Request, enabled *bool) {
+	name := chi.URLParam(r, "name")
+	if !features.Known(name) {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, fmt.Sprintf("Unknown feature flag %q", name))
+		return
+	}
+
+	config, err := h.storage.GetAdminConfig()
+	if err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to get admin config: %v", err))
+		return
+	}
+	if config == nil {
+		config = h.defaultAdminConfig(24)
+		if plant, err := h.storage.GetPlantState(); err == nil && plant != nil {
+			config.TimeoutHours = plant.TimeoutHours
+		}
+	}
+
+	oldValue := h.features.Enabled(features.Flag(name))
+	if enabled == nil {
+		delete(config.Features, name)
+	} else {
+		if config.Features == nil {
+			config.Features = make(map[string]bool)
+		}
+		config.Features[name] = *enabled
+	}
+	if err := h.storage.UpdateAdminConfig(config); err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, fmt.Sprintf("Failed to update config: %v", err))
+		return
+	}
+
+	states, err := h.features.List()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list feature flags", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to list feature flags")
+		return
+	}
+	var state features.State
+	for _, s := range states {
+		if string(s.Name) == name {
+			state = s
+		}
+	}
+	h.audit(r, models.AuditConfigFeature, name, oldValue, state.Enabled)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"watered/internal/features"
+	"watered/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withURLParam adds a chi URL parameter to r
+func withURLParam(r *http.Request, key, value string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add(key, value)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestAdminHandler_Features(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	handler := newTestAdminHandler(store)
+
+	rr := httptest.NewRecorder()
+	handler.UpdateFeatureHandler(rr, withURLParam(httptest.NewRequest("PUT", "/admin/features/sensors", strings.NewReader(`{"enabled": false}`)), "name", "sensors"))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var state features.State
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&state))
+	assert.Equal(t, features.State{Name: features.Sensors, Description: state.Description, Enabled: false, Default: true, Overridden: true}, state)
+
+	config, err := store.GetAdminConfig()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"sensors": false}, config.Features)
+	assert.NotEmpty(t, config.AdminEmails, "Expected a new admin configuration to keep the default admins")
+
+	rr = httptest.NewRecorder()
+	handler.GetFeaturesHandler(rr, httptest.NewRequest("GET", "/admin/features", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response struct {
+		Features []features.State `json:"features"`
+	}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.Len(t, response.Features, len(features.Definitions))
+	assert.False(t, response.Features[1].Enabled)
+
+	rr = httptest.NewRecorder()
+	handler.ResetFeatureHandler(rr, withURLParam(httptest.NewRequest("DELETE", "/admin/features/sensors", nil), "name", "sensors"))
+	require.Equal(t, http.StatusOK, rr.Code)
+	config, _ = store.GetAdminConfig()
+	assert.Empty(t, config.Features)
+}
+
+func TestAdminHandler_UpdateFeatureRejects(t *testing.T) {
+	tests := []struct {
+		name           string
+		flag           string
+		requestBody    string
+		expectedStatus int
+	}{
+		{"should reject unknown flags", "teleport", `{"enabled": true}`, http.StatusNotFound},
+		{"should require enabled", "sensors", `{}`, http.StatusBadRequest},
+		{"should reject invalid JSON", "sensors", `{invalid`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestAdminHandler(storage.NewMemoryStorage())
+
+			rr := httptest.NewRecorder()
+			handler.UpdateFeatureHandler(rr, withURLParam(httptest.NewRequest("PUT", "/admin/features/"+tt.flag, strings.NewReader(tt.requestBody)), "name", tt.flag))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
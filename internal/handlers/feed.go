@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"watered/internal/atom"
+	"watered/internal/logger"
+	"watered/internal/respond"
+	"watered/internal/services"
+)
+
+// defaultFeedEntries is how many entries a feed lists without ?limit=
+const defaultFeedEntries = 50
+
+// FeedHandlers serves the plants' activity as an Atom feed
+type FeedHandlers struct {
+	plantService *services.PlantService
+	// publicURL makes the feed's links absolute, as feed readers require
+	publicURL string
+}
+
+// NewFeedHandlers creates a new feed handlers instance
+func NewFeedHandlers(plantService *services.PlantService, publicURL string) *FeedHandlers {
+	return &FeedHandlers{
+		plantService: plantService,
+		publicURL:    publicURL,
+	}
+}
+
+// GetFeedHandler returns recent waterings, resets and settings changes as an
+// Atom feed, newest first. Entries never say who did something, since feed
+// readers cannot sign in.
+// GET /feed.atom?plant=1&limit=50&after=2024-06-01T00:00:00Z
+func (h *FeedHandlers) GetFeedHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	plantID := 0
+	if raw := query.Get("plant"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Invalid plant ID")
+			return
+		}
+		plantID = id
+	}
+	limit := defaultFeedEntries
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > services.MaxFeedEntries {
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "limit must be between 1 and "+strconv.Itoa(services.MaxFeedEntries))
+			return
+		}
+		limit = n
+	}
+	var after time.Time
+	if raw := query.Get("after"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "after must be an RFC 3339 timestamp")
+			return
+		}
+		after = t
+	}
+
+	service := h.plantService.WithContext(r.Context())
+	entries, err := service.Feed(plantID, after, limit)
+	if errors.Is(err, services.ErrPlantNotFound) {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Plant not found")
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to build activity feed", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to get activity feed")
+		return
+	}
+
+	// The feed's ID names what it follows, regardless of limit and after
+	feedID, title := h.publicURL+"/feed.atom", "Watered activity"
+	if plantID != 0 {
+		feedID += "?plant=" + strconv.Itoa(plantID)
+		if plant, err := service.GetPlantByID(plantID); err == nil {
+			title = plant.Name + " activity"
+		}
+	}
+	feed := &atom.Feed{
+		ID:     feedID,
+		Title:  title,
+		Author: atom.Person{Name: "Watered"},
+		Links: []atom.Link{
+			{Href: h.publicURL + r.URL.RequestURI(), Rel: "self", Type: "application/atom+xml"},
+			{Href: h.publicURL + "/", Rel: "alternate", Type: "text/html"},
+		},
+	}
+	for _, entry := range entries {
+		feed.Entries = append(feed.Entries, atom.Entry{
+			ID:       "urn:watered:" + entry.ID,
+			Title:    entry.Title,
+			Updated:  entry.At,
+			Category: &atom.Category{Term: entry.Kind},
+			Links:    []atom.Link{{Href: h.publicURL + "/", Rel: "alternate", Type: "text/html"}},
+			Summary:  entry.Summary,
+		})
+	}
+
+	var body bytes.Buffer
+	if err := feed.Encode(&body); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to encode activity feed", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to get activity feed")
+		return
+	}
+
+	// Feed readers poll, so let them skip unchanged feeds
+	sum := sha256.Sum256(body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Write(body.Bytes())
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"watered/internal/atom"
+	"watered/internal/models"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := services.NewPlantService(store)
+	handler := NewFeedHandlers(plantService, "https://plants.example.com")
+
+	_, err := plantService.WaterPlant("alice@example.com")
+	require.NoError(t, err)
+	_, err = services.NewAuditService(store).Record("admin@example.com", models.AuditPlantReset, "1", nil, nil)
+	require.NoError(t, err)
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rr := httptest.NewRecorder()
+		handler.GetFeedHandler(rr, req)
+		return rr
+	}
+
+	rr := get("/feed.atom", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/atom+xml; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.NotContains(t, rr.Body.String(), "alice", "feeds must not say who did something")
+	assert.NotContains(t, rr.Body.String(), "admin@")
+
+	var feed atom.Feed
+	require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &feed))
+	assert.Equal(t, "https://plants.example.com/feed.atom", feed.ID)
+	require.Len(t, feed.Entries, 2)
+	assert.Equal(t, "reset", feed.Entries[0].Category.Term)
+	assert.Equal(t, "watered", feed.Entries[1].Category.Term)
+
+	assert.Equal(t, http.StatusNotModified, get("/feed.atom", http.Header{"If-None-Match": {rr.Header().Get("ETag")}}).Code)
+
+	rr = get("/feed.atom?plant=1&limit=1", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	feed = atom.Feed{}
+	require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &feed))
+	assert.Equal(t, "https://plants.example.com/feed.atom?plant=1", feed.ID)
+	assert.Len(t, feed.Entries, 1)
+
+	rr = get("/feed.atom?after=2999-01-01T00:00:00Z", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	feed = atom.Feed{}
+	require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &feed))
+	assert.Empty(t, feed.Entries)
+
+	assert.Equal(t, http.StatusBadRequest, get("/feed.atom?limit=0", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, get("/feed.atom?limit=500", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, get("/feed.atom?after=yesterday", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, get("/feed.atom?plant=abc", nil).Code)
+	assert.Equal(t, http.StatusNotFound, get("/feed.atom?plant=42", nil).Code)
+}
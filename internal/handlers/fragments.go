@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+
+	"watered/internal/i18n"
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/render"
+	"watered/internal/respond"
+)
+
+// FragmentHandlers render parts of the dashboard as HTML snippets, which the
+// index page swaps in with htmx. The plant's status is worked out here and
+// rendered by the templates in fragments.html rather than in the page's
+// script.
+type FragmentHandlers struct {
+	plants   *PlantHandlers
+	renderer *render.Renderer
+}
+
+// NewFragmentHandlers creates a new fragment handlers instance
+func NewFragmentHandlers(plants *PlantHandlers, renderer *render.Renderer) *FragmentHandlers {
+	return &FragmentHandlers{
+		plants:   plants,
+		renderer: renderer,
+	}
+}
+
+// cardStatus returns the dashboard's style class for a health status. A
+// paused plant looks healthy, and one past its grace period or never
+// watered looks critical.
+func cardStatus(status models.PlantHealthStatus) string {
+	switch status {
+	case models.HealthStatusHealthy, models.HealthStatusPaused:
+		return "healthy"
+	case models.HealthStatusNeedsWater:
+		return "needs-water"
+	default:
+		return "critical"
+	}
+}
+
+// PlantCardHandler renders the plant card: the plant, its status, a hint
+// and who watered it last
+// GET /fragments/plant-card
+func (h *FragmentHandlers) PlantCardHandler(w http.ResponseWriter, r *http.Request) {
+	plant, ok := h.plant(w, r)
+	if !ok {
+		return
+	}
+
+	health := plant.GetHealthStatus()
+	h.render(w, r, "plant-card", map[string]interface{}{
+		"Status":    cardStatus(health),
+		"Paused":    health == models.HealthStatusPaused,
+		"Watered":   plant.LastWatered != nil,
+		"WateredBy": h.plants.displayWateredBy(r, plant.WateredBy),
+	})
+}
+
+// TimerHandler renders how long ago the plant was watered
+// GET /fragments/timer
+func (h *FragmentHandlers) TimerHandler(w http.ResponseWriter, r *http.Request) {
+	plant, ok := h.plant(w, r)
+	if !ok {
+		return
+	}
+
+	data := map[string]interface{}{"Watered": false}
+	if since := plant.GetTimeSinceWatering(); since != nil {
+		data["Watered"] = true
+		data["Hours"] = int(since.Hours())
+		data["Minutes"] = int(since.Minutes())
+	}
+	h.render(w, r, "timer", data)
+}
+
+// plant loads the requested plant, answering the request itself if it can't
+func (h *FragmentHandlers) plant(w http.ResponseWriter, r *http.Request) (*models.PlantState, bool) {
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	plant, err := h.plants.plantService.WithContext(r.Context()).GetPlantByID(id)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to get plant", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to get plant state")
+		return nil, false
+	}
+	return plant, true
+}
+
+// render executes the fragment template name in the request's language. The
+// page polls fragments, so they are revalidated rather than cached.
+func (h *FragmentHandlers) render(w http.ResponseWriter, r *http.Request, name string, data map[string]interface{}) {
+	data[render.I18nKey] = i18n.FromContext(r.Context())
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := h.renderer.Render(w, name, data); err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Template error")
+		logger.FromContext(r.Context()).Error("Template error", "error", err)
+	}
+}
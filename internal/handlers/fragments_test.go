@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/i18n"
+	"watered/internal/models"
+	"watered/internal/render"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFragmentHandlers(t *testing.T) (*FragmentHandlers, *services.PlantService) {
+	store := storage.NewMemoryStorage()
+	t.Cleanup(func() { store.Close() })
+
+	plantService := services.NewPlantService(store)
+	plants := NewPlantHandlers(plantService, auth.NewAuthService(store, config.AuthConfig{}))
+	templates := template.Must(template.ParseFiles(filepath.Join("..", "..", "web", "templates", "fragments.html")))
+	return NewFragmentHandlers(plants, render.NewRenderer(templates, nil)), plantService
+}
+
+func serveFragment(handler http.HandlerFunc, locale string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/fragments", nil)
+	req = req.WithContext(i18n.WithPrinter(req.Context(), i18n.Get(locale)))
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+	return rr
+}
+
+func TestFragmentHandlers_NeverWatered(t *testing.T) {
+	handlers, _ := newTestFragmentHandlers(t)
+
+	rr := serveFragment(handlers.TimerHandler, i18n.English)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+	assert.Equal(t, "Plant has never been watered", rr.Body.String())
+
+	rr = serveFragment(handlers.PlantCardHandler, i18n.English)
+	require.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	assert.Contains(t, body, `<div class="plant-visual critical">`)
+	assert.Contains(t, body, "Needs water now!")
+	assert.Contains(t, body, "I need water urgently!")
+	assert.NotContains(t, body, "Last watered by")
+}
+
+func TestFragmentHandlers_Watered(t *testing.T) {
+	handlers, plantService := newTestFragmentHandlers(t)
+	_, err := plantService.WaterPlant("test@example.com")
+	require.NoError(t, err)
+
+	rr := serveFragment(handlers.TimerHandler, i18n.English)
+	assert.Equal(t, "Watered 0 minutes ago", rr.Body.String())
+
+	rr = serveFragment(handlers.PlantCardHandler, i18n.English)
+	body := rr.Body.String()
+	assert.Contains(t, body, `<div class="status-text healthy">Looking great! 🌿</div>`)
+	assert.Contains(t, body, "Tap the plant when you water it!")
+	// Anonymous visitors don't learn who watered the plant
+	assert.Contains(t, body, "Last watered by "+models.AnonymousWaterer)
+	assert.NotContains(t, body, "test@example.com")
+
+	rr = serveFragment(handlers.PlantCardHandler, "es")
+	assert.Contains(t, rr.Body.String(), "Regada por última vez por")
+}
+
+func TestCardStatus(t *testing.T) {
+	for status, want := range map[models.PlantHealthStatus]string{
+		models.HealthStatusHealthy:    "healthy",
+		models.HealthStatusPaused:     "healthy",
+		models.HealthStatusNeedsWater: "needs-water",
+		models.HealthStatusDue:        "critical",
+		models.HealthStatusCritical:   "critical",
+		models.HealthStatusUnknown:    "critical",
+	} {
+		assert.Equal(t, want, cardStatus(status), "status %s", status)
+	}
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"watered/internal/auth"
+	"watered/internal/i18n"
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/render"
+	"watered/internal/respond"
+	"watered/internal/services"
+	"watered/internal/validate"
+)
+
+// InviteHandlers serves invite links and their management
+type InviteHandlers struct {
+	auditor
+
+	inviteService *services.InviteService
+	authService   *auth.AuthService
+	renderer      *render.Renderer
+}
+
+// NewInviteHandlers creates a new invite handlers instance
+func NewInviteHandlers(inviteService *services.InviteService, authService *auth.AuthService, renderer *render.Renderer) *InviteHandlers {
+	return &InviteHandlers{
+		inviteService: inviteService,
+		authService:   authService,
+		renderer:      renderer,
+	}
+}
+
+// AcceptInviteHandler starts signing in through an invite link. The token is
+// kept in the session, and the OAuth callback adds the account the invitee
+// signs in with to the allowlist.
+// GET /invite/{token}
+func (h *InviteHandlers) AcceptInviteHandler(w http.ResponseWriter, r *http.Request) {
+	// The token is the credential, so keep it out of caches, search engines
+	// and the Referer of the sign-in redirect
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	token := chi.URLParam(r, "token")
+	if _, err := h.inviteService.Verify(token); err != nil {
+		if !errors.Is(err, services.ErrInvalidInviteToken) {
+			logger.FromContext(r.Context()).Error("Failed to verify invite", "error", err)
+			respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to verify invite")
+			return
+		}
+		h.invalid(w, r)
+		return
+	}
+
+	if err := h.authService.SetPendingInvite(w, r, token); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to save invite in session", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Session storage failed. Please clear your browser cookies and try again.")
+		return
+	}
+
+	http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+}
+
+// invalid answers an invite link that is malformed, expired, revoked or
+// already used
+func (h *InviteHandlers) invalid(w http.ResponseWriter, r *http.Request) {
+	if wantsJSON(r) {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "This invite link is invalid, has expired or has already been used")
+		return
+	}
+
+	printer := i18n.FromContext(r.Context())
+	if err := h.renderer.RenderStatus(w, http.StatusNotFound, "error.html", map[string]interface{}{
+		"Status":       http.StatusNotFound,
+		"Title":        printer.T("error.invite_invalid.title"),
+		"Detail":       printer.T("error.invite_invalid.detail"),
+		render.I18nKey: printer,
+	}); err != nil {
+		// The status has been sent by now
+		logger.FromContext(r.Context()).Error("Template error", "error", err)
+		io.WriteString(w, http.StatusText(http.StatusNotFound))
+	}
+}
+
+// ListInvitesHandler returns the invites that can still be accepted
+// GET /admin/invites
+func (h *InviteHandlers) ListInvitesHandler(w http.ResponseWriter, r *http.Request) {
+	invites, err := h.inviteService.List()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list invites", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to list invites")
+		return
+	}
+
+	response := map[string]interface{}{
+		"invites": invites,
+		"count":   len(invites),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateInviteHandler issues an invite link, valid for a week unless
+// expires_in_hours says otherwise. The link is only returned in this
+// response.
+// POST /admin/invites
+func (h *InviteHandlers) CreateInviteHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	var req struct {
+		Name           string `json:"name" validate:"required,max=64"`
+		Role           string `json:"role"`
+		ExpiresInHours *int   `json:"expires_in_hours" validate:"min=1,max=720"`
+	}
+	if !validate.DecodeJSON(w, r, &req) {
+		return
+	}
+	var ttl time.Duration
+	if req.ExpiresInHours != nil {
+		ttl = time.Duration(*req.ExpiresInHours) * time.Hour
+	}
+
+	invite, link, err := h.inviteService.Create(req.Name, strings.TrimSpace(strings.ToLower(req.Role)), ttl, user.Email)
+	if errors.Is(err, services.ErrInvalidInvite) {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to create invite", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to create invite")
+		return
+	}
+	h.audit(r, models.AuditInviteCreate, invite.ID, nil, invite)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Invite created. Copy the link now, it will not be shown again.",
+		"url":     link,
+		"invite":  invite,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// RevokeInviteHandler deletes a pending invite so its link stops working
+// DELETE /admin/invites/{id}
+func (h *InviteHandlers) RevokeInviteHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	invite, err := h.inviteService.Revoke(chi.URLParam(r, "id"), user.Email)
+	if errors.Is(err, services.ErrInviteNotFound) {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Invite not found")
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to revoke invite", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to revoke invite")
+		return
+	}
+	h.audit(r, models.AuditInviteRevoke, invite.ID, invite, nil)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Invite revoked",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/i18n"
+	"watered/internal/models"
+	"watered/internal/render"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInviteHandlers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{AdminEmails: []string{"admin@example.com"}})
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	templates := template.Must(template.ParseFiles(filepath.Join("..", "..", "web", "templates", "error.html")))
+	inviteService := services.NewInviteService(store, []byte("test-key"), "https://plant.example.com")
+	handler := NewInviteHandlers(inviteService, authService, render.NewRenderer(templates, nil))
+	handler.SetAuditService(services.NewAuditService(store))
+	cookies := sessionCookies(t, authService, "admin@example.com")
+
+	router := chi.NewRouter()
+	router.Use(i18n.Middleware(func(r *http.Request) string { return "" }))
+	router.Get("/invite/{token}", handler.AcceptInviteHandler)
+	router.Get("/admin/invites", handler.ListInvitesHandler)
+	router.Post("/admin/invites", handler.CreateInviteHandler)
+	router.Delete("/admin/invites/{id}", handler.RevokeInviteHandler)
+
+	do := func(method, path, body, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if strings.HasPrefix(path, "/admin/") {
+			for _, cookie := range cookies {
+				req.AddCookie(cookie)
+			}
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/admin/invites", `{}`, "").Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/admin/invites", `{"name":"Alex","role":"admin"}`, "").Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/admin/invites", `{"name":"Alex","expires_in_hours":1000}`, "").Code)
+
+	rr := do("POST", "/admin/invites", `{"name":"Sitter","role":"Viewer","expires_in_hours":48}`, "")
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var created struct {
+		URL    string         `json:"url"`
+		Invite *models.Invite `json:"invite"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.Equal(t, "viewer", created.Invite.Role)
+	path := strings.TrimPrefix(created.URL, "https://plant.example.com")
+	require.True(t, strings.HasPrefix(path, "/invite/"), created.URL)
+
+	// The list never shows the link
+	rr = do("GET", "/admin/invites", "", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"count":1`)
+	assert.Contains(t, rr.Body.String(), `"name":"Sitter"`)
+	assert.NotContains(t, rr.Body.String(), strings.TrimPrefix(path, "/invite/"))
+
+	t.Run("follow", func(t *testing.T) {
+		rr := do("GET", path, "", "text/html")
+		require.Equal(t, http.StatusSeeOther, rr.Code)
+		assert.Equal(t, "/auth/login", rr.Header().Get("Location"))
+		assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+
+		// The token waits in the session for the OAuth callback
+		req := httptest.NewRequest("GET", "/auth/callback", nil)
+		for _, cookie := range rr.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		assert.Equal(t, strings.TrimPrefix(path, "/invite/"), authService.PendingInvite(req))
+	})
+
+	rr = do("DELETE", "/admin/invites/"+created.Invite.ID, "", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/admin/invites/"+created.Invite.ID, "", "").Code)
+
+	t.Run("revoked", func(t *testing.T) {
+		rr := do("GET", path, "", "application/json")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = do("GET", path, "", "text/html")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Contains(t, rr.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, rr.Body.String(), "Invite not valid")
+		assert.Empty(t, rr.Result().Cookies())
+	})
+
+	entries, err := store.ListAuditEntries(models.AuditFilter{})
+	require.NoError(t, err)
+	actions := make([]string, len(entries))
+	for i, entry := range entries {
+		actions[i] = entry.Action
+	}
+	assert.Equal(t, []string{models.AuditInviteRevoke, models.AuditInviteCreate}, actions)
+}
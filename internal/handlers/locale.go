@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"watered/internal/auth"
+	"watered/internal/i18n"
+	"watered/internal/logger"
+	"watered/internal/respond"
+	"watered/internal/services"
+)
+
+// LocaleHandlers contains the language preference HTTP handlers
+type LocaleHandlers struct {
+	userService *services.UserService
+	authService *auth.AuthService
+}
+
+// NewLocaleHandlers creates a new locale handlers instance
+func NewLocaleHandlers(userService *services.UserService, authService *auth.AuthService) *LocaleHandlers {
+	return &LocaleHandlers{
+		userService: userService,
+		authService: authService,
+	}
+}
+
+// localeResponse is the current user's language preference
+type localeResponse struct {
+	// Locale is the language the user chose, "" when following the browser
+	Locale string `json:"locale"`
+	// Effective is the language responses are in
+	Effective string   `json:"effective"`
+	Supported []string `json:"supported"`
+}
+
+// GetLocaleHandler returns the current user's language preference
+// GET /api/v1/me/locale
+func (h *LocaleHandlers) GetLocaleHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(localeResponse{
+		Locale:    h.userService.Locale(user.Email),
+		Effective: i18n.FromContext(r.Context()).Locale(),
+		Supported: i18n.Supported(),
+	})
+}
+
+// UpdateLocaleHandler sets the current user's language. An empty locale
+// goes back to following the browser's Accept-Language.
+// PUT /api/v1/me/locale
+func (h *LocaleHandlers) UpdateLocaleHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	var request struct {
+		Locale *string `json:"locale"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+	if request.Locale == nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "locale is required")
+		return
+	}
+
+	locale, err := h.userService.SetLocale(user.Email, *request.Locale)
+	if errors.Is(err, services.ErrUnsupportedLocale) {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to set locale", "email", user.Email, "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to set locale")
+		return
+	}
+
+	// Report the language later requests will be in
+	effective := locale
+	if effective == "" {
+		effective = i18n.Match(r.Header.Get("Accept-Language"))
+	}
+	w.Header().Set("Content-Language", effective)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(localeResponse{
+		Locale:    locale,
+		Effective: effective,
+		Supported: i18n.Supported(),
+	})
+}
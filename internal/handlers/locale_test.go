@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/i18n"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocaleHandlers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	userService := services.NewUserService(store)
+	handlers := NewLocaleHandlers(userService, authService)
+	cookies := sessionCookies(t, authService, "test@example.com")
+	request := func(method, body string) *http.Request {
+		req := httptest.NewRequest(method, "/api/v1/me/locale", strings.NewReader(body))
+		req.Header.Set("Accept-Language", "es-ES,es;q=0.9")
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		return req
+	}
+
+	w := httptest.NewRecorder()
+	handlers.GetLocaleHandler(w, httptest.NewRequest("GET", "/api/v1/me/locale", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	handlers.UpdateLocaleHandler(w, request("PUT", `{"locale": "en-GB"}`))
+	require.Equal(t, http.StatusOK, w.Code)
+	var response localeResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, localeResponse{Locale: "en", Effective: "en", Supported: []string{"en", "es"}}, response)
+	user, err := store.GetUser("test@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "en", user.Locale)
+
+	w = httptest.NewRecorder()
+	handlers.UpdateLocaleHandler(w, request("PUT", `{"locale": "fr"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = httptest.NewRecorder()
+	handlers.UpdateLocaleHandler(w, request("PUT", `{}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Clearing the preference follows the browser again
+	w = httptest.NewRecorder()
+	handlers.UpdateLocaleHandler(w, request("PUT", `{"locale": ""}`))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "", response.Locale)
+	assert.Equal(t, "es", response.Effective)
+
+	w = httptest.NewRecorder()
+	req := request("GET", "")
+	handlers.GetLocaleHandler(w, req.WithContext(i18n.WithPrinter(req.Context(), i18n.Get("es"))))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "es", response.Effective)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"watered/internal/auth"
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/privacy"
+	"watered/internal/respond"
+	"watered/internal/services"
+)
+
+const (
+	defaultNotificationLimit = 50
+	maxNotificationLimit     = 500
+)
+
+// NotificationHandlers contains notification history HTTP handlers
+type NotificationHandlers struct {
+	notificationService *services.NotificationService
+	authService         *auth.AuthService
+}
+
+// NewNotificationHandlers creates a new notification handlers instance
+func NewNotificationHandlers(notificationService *services.NotificationService, authService *auth.AuthService) *NotificationHandlers {
+	return &NotificationHandlers{
+		notificationService: notificationService,
+		authService:         authService,
+	}
+}
+
+// parseNotificationFilter builds a filter from channel, status and limit query parameters
+func parseNotificationFilter(r *http.Request) (models.NotificationFilter, error) {
+	query := r.URL.Query()
+	filter := models.NotificationFilter{
+		Channel: strings.TrimSpace(query.Get("channel")),
+		Status:  models.NotificationStatus(strings.TrimSpace(query.Get("status"))),
+		Limit:   defaultNotificationLimit,
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return filter, strconv.ErrSyntax
+		}
+		if limit > maxNotificationLimit {
+			limit = maxNotificationLimit
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+// writeNotifications encodes a notification list response, clearing fields
+// the caller's role may not see
+func writeNotifications(w http.ResponseWriter, notifications []*models.Notification, role privacy.Role) {
+	response := map[string]interface{}{
+		"notifications": notifications,
+		"count":         len(notifications),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(privacy.Mask(response, role))
+}
+
+// GetMyNotificationsHandler returns the notification history of the current user
+// GET /api/v1/me/notifications
+func (h *NotificationHandlers) GetMyNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	filter, err := parseNotificationFilter(r)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Invalid limit parameter")
+		return
+	}
+	filter.UserEmail = user.Email
+
+	notifications, err := h.notificationService.List(filter)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list notifications", "email", user.Email, "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to get notifications")
+		return
+	}
+
+	writeNotifications(w, notifications, h.authService.CallerRole(r))
+}
+
+// GetNotificationsHandler returns notification history for all users (admin only)
+// GET /admin/notifications
+func (h *NotificationHandlers) GetNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseNotificationFilter(r)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Invalid limit parameter")
+		return
+	}
+	filter.UserEmail = strings.TrimSpace(strings.ToLower(r.URL.Query().Get("email")))
+
+	notifications, err := h.notificationService.List(filter)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list notifications", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to get notifications")
+		return
+	}
+
+	writeNotifications(w, notifications, h.authService.CallerRole(r))
+}
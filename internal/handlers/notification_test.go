@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sessionCookies creates a session for the given email and returns its cookies
+func sessionCookies(t *testing.T, authService *auth.AuthService, email string) []*http.Cookie {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	userInfo := &auth.GoogleUserInfo{
+		ID:    "id-" + email,
+		Email: email,
+		Name:  "Test User",
+	}
+	require.NoError(t, authService.CreateSession(w, req, userInfo))
+	return w.Result().Cookies()
+}
+
+func TestNotificationHandlers_GetMyNotificationsHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	notificationService := services.NewNotificationService(store)
+	handlers := NewNotificationHandlers(notificationService, authService)
+
+	notificationService.Record("test@example.com", "email", models.NotificationTriggerDue, "Plant is due", nil)
+	notificationService.Record("test@example.com", "push", models.NotificationTriggerCritical, "Plant is critical", errors.New("subscription expired"))
+	notificationService.Record("other@example.com", "email", models.NotificationTriggerDue, "Plant is due", nil)
+
+	// Unauthenticated requests are rejected
+	req := httptest.NewRequest("GET", "/api/me/notifications", nil)
+	w := httptest.NewRecorder()
+	handlers.GetMyNotificationsHandler(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Authenticated users only see their own notifications
+	req = httptest.NewRequest("GET", "/api/me/notifications", nil)
+	for _, cookie := range sessionCookies(t, authService, "test@example.com") {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	handlers.GetMyNotificationsHandler(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Notifications []models.Notification `json:"notifications"`
+		Count         int                   `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	assert.Equal(t, "push", response.Notifications[0].Channel)
+	assert.Equal(t, models.NotificationStatusFailed, response.Notifications[0].Status)
+	assert.Equal(t, "subscription expired", response.Notifications[0].Error)
+}
+
+func TestNotificationHandlers_GetNotificationsHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	notificationService := services.NewNotificationService(store)
+	handlers := NewNotificationHandlers(notificationService, authService)
+
+	notificationService.Record("test@example.com", "email", models.NotificationTriggerDue, "Plant is due", nil)
+	notificationService.Record("other@example.com", "email", models.NotificationTriggerDue, "Plant is due", errors.New("smtp timeout"))
+	notificationService.Record("other@example.com", "push", models.NotificationTriggerDue, "Plant is due", nil)
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedCode  int
+		expectedCount int
+	}{
+		{"all notifications", "", http.StatusOK, 3},
+		{"filter by email", "?email=other@example.com", http.StatusOK, 2},
+		{"filter by channel", "?channel=push", http.StatusOK, 1},
+		{"filter by status", "?status=failed", http.StatusOK, 1},
+		{"limit results", "?limit=2", http.StatusOK, 2},
+		{"invalid limit", "?limit=abc", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/admin/notifications"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handlers.GetNotificationsHandler(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, float64(tt.expectedCount), response["count"])
+		})
+	}
+}
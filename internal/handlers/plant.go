@@ -1,16 +1,33 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"log"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
 
 	"watered/internal/auth"
+	"watered/internal/i18n"
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/privacy"
+	"watered/internal/respond"
 	"watered/internal/services"
+	"watered/internal/stats"
+	"watered/internal/validate"
 )
 
 // PlantHandlers contains all plant-related HTTP handlers
 type PlantHandlers struct {
+	auditor
+
 	plantService *services.PlantService
 	authService  *auth.AuthService
 }
@@ -23,29 +40,390 @@ func NewPlantHandlers(plantService *services.PlantService, authService *auth.Aut
 	}
 }
 
+// displayWateredBy returns the waterer identity as it should be shown to the
+// requesting user. Viewers never see it, and members only see it when
+// privacy mode is off.
+func (h *PlantHandlers) displayWateredBy(r *http.Request, wateredBy string) string {
+	if wateredBy == "" {
+		return wateredBy
+	}
+
+	switch h.authService.CallerRole(r) {
+	case privacy.RoleAdmin:
+		return wateredBy
+	case privacy.RoleMember:
+		if !h.plantService.WithContext(r.Context()).IsPrivacyModeEnabled() {
+			return wateredBy
+		}
+	}
+	return models.AnonymousWaterer
+}
+
+// displayMetadata returns the plant's custom fields if the requesting user
+// may see them. They can describe the household, such as where a plant
+// stands, so viewers never see them.
+func (h *PlantHandlers) displayMetadata(r *http.Request, metadata map[string]models.MetadataValue) map[string]models.MetadataValue {
+	if h.authService.CallerRole(r) < privacy.RoleMember {
+		return nil
+	}
+	return metadata
+}
+
+// displayVacation returns the plant's vacation if the requesting user may
+// see it. It tells when the household is away, so viewers never see it.
+func (h *PlantHandlers) displayVacation(r *http.Request, vacation *models.Vacation) *models.Vacation {
+	if h.authService.CallerRole(r) < privacy.RoleMember {
+		return nil
+	}
+	return vacation
+}
+
+// clockSkew compares the X-Client-Time header with the server clock. It
+// returns nil when the header is absent or malformed or the clock is within
+// tolerance.
+func (h *PlantHandlers) clockSkew(r *http.Request) *services.ClockSkew {
+	value := r.Header.Get(services.ClientTimeHeader)
+	if value == "" {
+		return nil
+	}
+
+	clientTime, err := services.ParseClientTime(value)
+	if err != nil {
+		logger.FromContext(r.Context()).Debug("Ignoring malformed client time", "error", err)
+		return nil
+	}
+
+	skew := h.plantService.CheckClockSkew(clientTime)
+	if skew != nil {
+		logger.FromContext(r.Context()).Warn("Client clock is skewed", "skew_seconds", skew.SkewSeconds, "user_agent", r.UserAgent())
+	}
+	return skew
+}
+
+// plantSettings returns the admin-managed settings of a plant, as recorded
+// in the audit log
+func plantSettings(plant *models.PlantState) map[string]interface{} {
+	needsWater, critical := plant.HealthThresholds()
+	return map[string]interface{}{
+		"name":                plant.Name,
+		"timeout_hours":       plant.TimeoutHours,
+		"grace_period_hours":  plant.GracePeriodHours,
+		"metadata":            plant.Metadata,
+		"outdoor":             plant.Outdoor,
+		"needs_water_percent": needsWater,
+		"critical_percent":    critical,
+	}
+}
+
+// plantWatering returns the watering state a reset clears, as recorded in
+// the audit log
+func plantWatering(plant *models.PlantState) map[string]interface{} {
+	return map[string]interface{}{
+		"last_watered": plant.LastWatered,
+		"watered_by":   plant.WateredBy,
+	}
+}
+
+// plantIDFromRequest resolves the plant ID from the {id} URL parameter,
+// falling back to the default plant for the legacy /api/plant routes. It
+// writes a 400 response and returns false if the ID is malformed.
+func plantIDFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idParam := chi.URLParam(r, "id")
+	if idParam == "" {
+		return models.DefaultPlantID, true
+	}
+
+	id, err := strconv.Atoi(idParam)
+	if err != nil || id <= 0 {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Invalid plant ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// writePlantError maps a plant service error to an HTTP response, answering
+// 404 for unknown plants and status, code and message otherwise
+func writePlantError(w http.ResponseWriter, err error, status int, code, message string) {
+	if errors.Is(err, services.ErrPlantNotFound) {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Plant not found")
+		return
+	}
+	respond.Error(w, status, code, message)
+}
+
+// plantSummary builds the list representation of a plant
+func (h *PlantHandlers) plantSummary(r *http.Request, plant *models.PlantState) map[string]interface{} {
+	needsWater, critical := plant.HealthThresholds()
+	return map[string]interface{}{
+		"id":                  plant.ID,
+		"name":                plant.Name,
+		"last_watered":        plant.LastWatered,
+		"timeout_hours":       plant.TimeoutHours,
+		"grace_period_hours":  plant.GracePeriodHours,
+		"needs_water_percent": needsWater,
+		"critical_percent":    critical,
+		"watered_by":          h.displayWateredBy(r, plant.WateredBy),
+		"metadata":            h.displayMetadata(r, plant.Metadata),
+		"vacation":            h.displayVacation(r, plant.Vacation),
+		"outdoor":             plant.Outdoor,
+		"updated_at":          plant.UpdatedAt,
+		"health_status":       plant.GetHealthStatus(),
+		"overall_status":      h.plantService.OverallStatus(plant),
+		"time_since_watering": plant.FormatTimeSinceWatering(i18n.FromContext(r.Context())),
+		"is_overdue":          plant.IsOverdue(),
+	}
+}
+
+// metadataFilter collects meta.<key>=<value> query parameters, e.g.
+// ?meta.location=balcony
+func metadataFilter(r *http.Request) map[string]string {
+	filter := make(map[string]string)
+	for param, values := range r.URL.Query() {
+		if key, ok := strings.CutPrefix(param, "meta."); ok && key != "" && len(values) > 0 {
+			filter[key] = values[0]
+		}
+	}
+	return filter
+}
+
+// GetTimeHandler returns the server clock so clients can detect and
+// correct their own clock skew. Clients sending X-Client-Time also get the
+// measured skew.
+// GET /api/v1/time
+func (h *PlantHandlers) GetTimeHandler(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	response := map[string]interface{}{
+		"server_time":            now,
+		"unix_ms":                now.UnixMilli(),
+		"timezone":               h.plantService.Location().String(),
+		"skew_tolerance_seconds": h.plantService.ClockSkewTolerance().Seconds(),
+	}
+	if skew := h.clockSkew(r); skew != nil {
+		response["clock_skew"] = skew
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetPlantStatsHandler reports watering counts, streaks and punctuality for
+// the household and, for signed-in users, per user. With privacy mode on,
+// members only see their own numbers.
+// GET /api/v1/plant/stats
+func (h *PlantHandlers) GetPlantStatsHandler(w http.ResponseWriter, r *http.Request) {
+	service := h.plantService.WithContext(r.Context())
+	report, err := service.WateringStats()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to compute watering stats", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to get watering stats")
+		return
+	}
+
+	role := h.authService.CallerRole(r)
+	if role == privacy.RoleMember && service.IsPrivacyModeEnabled() {
+		users := []stats.UserStats{}
+		if user, err := h.authService.GetCurrentUser(r); err == nil && user != nil {
+			if own := report.User(user.Email); own != nil {
+				users = append(users, *own)
+			}
+		}
+		report.Users = users
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(privacy.Mask(report, role))
+}
+
+// GetLeaderboardHandler ranks household members by waterings this week or
+// month, with each member's change since the previous period. Responses carry
+// an ETag and may be cached briefly; they only change when someone waters.
+// GET /api/v1/leaderboard?period=week|month
+func (h *PlantHandlers) GetLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	period, err := stats.ParsePeriod(r.URL.Query().Get("period"))
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+		return
+	}
+
+	service := h.plantService.WithContext(r.Context())
+	board, err := service.Leaderboard(period)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to build leaderboard", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to get leaderboard")
+		return
+	}
+
+	// With privacy mode on, members keep their ranks but only see their own name
+	role := h.authService.CallerRole(r)
+	if role == privacy.RoleMember && service.IsPrivacyModeEnabled() {
+		var email string
+		if user, err := h.authService.GetCurrentUser(r); err == nil && user != nil {
+			email = user.Email
+		}
+		for i := range board.Entries {
+			if board.Entries[i].Email != email {
+				board.Entries[i].Email = ""
+			}
+		}
+	}
+
+	body, err := json.Marshal(privacy.Mask(board, role))
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to encode leaderboard", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to get leaderboard")
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	// Private, since what a caller sees depends on their role
+	w.Header().Set("Cache-Control", "private, max-age=60")
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// ListPlantsHandler returns all plants, optionally only those whose custom
+// fields match meta.<key>=<value> query parameters
+// GET /api/v1/plants
+func (h *PlantHandlers) ListPlantsHandler(w http.ResponseWriter, r *http.Request) {
+	plants, err := h.plantService.WithContext(r.Context()).ListPlants()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list plants", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to list plants")
+		return
+	}
+
+	// Viewers cannot see custom fields, so they cannot filter by them either
+	filter := metadataFilter(r)
+	if len(filter) > 0 && h.authService.CallerRole(r) < privacy.RoleMember {
+		plants = nil
+	}
+
+	summaries := make([]map[string]interface{}, 0, len(plants))
+	for _, plant := range plants {
+		if !plant.MatchesMetadata(filter) {
+			continue
+		}
+		summaries = append(summaries, h.plantSummary(r, plant))
+	}
+
+	response := map[string]interface{}{
+		"plants": summaries,
+		"count":  len(summaries),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreatePlantHandler adds a new plant (admin only)
+// POST /api/v1/plants
+func (h *PlantHandlers) CreatePlantHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name         string `json:"name"`
+		TimeoutHours int    `json:"timeout_hours"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid request body")
+		return
+	}
+
+	plant, err := h.plantService.WithContext(r.Context()).CreatePlant(req.Name, req.TimeoutHours)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to create plant", "error", err)
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Failed to create plant: "+err.Error())
+		return
+	}
+
+	h.audit(r, models.AuditPlantCreate, strconv.Itoa(plant.ID), nil, plantSettings(plant))
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Plant created successfully",
+		"plant":   h.plantSummary(r, plant),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeletePlantHandler removes a plant (admin only)
+// DELETE /api/v1/plants/{id}
+func (h *PlantHandlers) DeletePlantHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var oldSettings interface{}
+	service := h.plantService.WithContext(r.Context())
+	if plant, err := service.GetPlantByID(id); err == nil {
+		oldSettings = plantSettings(plant)
+	}
+
+	if err := service.DeletePlant(id); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to delete plant", "plant_id", id, "error", err)
+		writePlantError(w, err, http.StatusBadRequest, respond.CodeBadRequest, "Failed to delete plant: "+err.Error())
+		return
+	}
+	h.audit(r, models.AuditPlantDelete, strconv.Itoa(id), oldSettings, nil)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Plant deleted successfully",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 // GetPlantHandler returns the current plant state
-// GET /api/plant
+// GET /api/v1/plant, GET /api/v1/plants/{id}
 func (h *PlantHandlers) GetPlantHandler(w http.ResponseWriter, r *http.Request) {
-	plant, err := h.plantService.GetPlant()
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	plant, err := h.plantService.WithContext(r.Context()).GetPlantByID(id)
 	if err != nil {
-		log.Printf("Failed to get plant: %v", err)
-		http.Error(w, "Failed to get plant state", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("Failed to get plant", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to get plant state")
 		return
 	}
 
 	// Create response with computed status
+	needsWater, critical := plant.HealthThresholds()
 	response := map[string]interface{}{
 		"id":                   plant.ID,
 		"name":                 plant.Name,
 		"last_watered":         plant.LastWatered,
 		"timeout_hours":        plant.TimeoutHours,
-		"watered_by":           plant.WateredBy,
+		"grace_period_hours":   plant.GracePeriodHours,
+		"needs_water_percent":  needsWater,
+		"critical_percent":     critical,
+		"watered_by":           h.displayWateredBy(r, plant.WateredBy),
+		"metadata":             h.displayMetadata(r, plant.Metadata),
+		"vacation":             h.displayVacation(r, plant.Vacation),
+		"outdoor":              plant.Outdoor,
+		"weather_factor":       plant.WeatherFactor(),
 		"created_at":           plant.CreatedAt,
 		"updated_at":           plant.UpdatedAt,
 		"health_status":        plant.GetHealthStatus(),
-		"time_since_watering":  plant.GetFormattedTimeSinceWatering(),
+		"overall_status":       h.plantService.OverallStatus(plant),
+		"time_since_watering":  plant.FormatTimeSinceWatering(i18n.FromContext(r.Context())),
 		"hours_since_watering": plant.GetHoursSinceWatering(),
 		"is_overdue":           plant.IsOverdue(),
+		"is_critical":          plant.IsCritical(),
 		"time_until_due":       plant.GetTimeUntilDue(),
 	}
 
@@ -53,21 +431,71 @@ func (h *PlantHandlers) GetPlantHandler(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(response)
 }
 
-// WaterPlantHandler records a plant watering event
-// POST /api/plant/water
+// WaterPlantHandler records a plant watering event. The watering is
+// credited to the caller unless an admin names another household member in
+// watered_by, e.g. to log a watering someone forgot to tap.
+// POST /api/v1/plant/water, POST /api/v1/plants/{id}/water
 func (h *PlantHandlers) WaterPlantHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
 	// Get the current authenticated user
 	user, err := h.authService.GetCurrentUser(r)
 	if err != nil || user == nil {
-		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	// An optional watered_at backdates the watering, e.g. "yesterday 18:00"
+	// or a timestamp synced from an offline device
+	var req struct {
+		WateredAt string `json:"watered_at"`
+		WateredBy string `json:"watered_by" validate:"email"`
+		Locale    string `json:"locale"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid request body")
+		return
+	}
+	if errs := validate.Struct(&req); len(errs) > 0 {
+		respond.ValidationError(w, errs)
 		return
 	}
 
+	wateredBy := user.Email
+	if email := strings.ToLower(strings.TrimSpace(req.WateredBy)); email != "" && email != user.Email {
+		if !user.IsAdmin {
+			respond.Error(w, http.StatusForbidden, respond.CodeForbidden, "Only admins can record a watering for someone else")
+			return
+		}
+		if !h.authService.IsUserAllowed(email) && !h.authService.IsUserAdmin(email) {
+			respond.ValidationError(w, validate.Errors{{Field: "watered_by", Message: "must be a household member"}})
+			return
+		}
+		wateredBy = email
+	}
+
+	wateredAt := time.Now()
+	skew := h.clockSkew(r)
+	if req.WateredAt != "" {
+		wateredAt, err = h.plantService.TimeParser(req.Locale).Parse(req.WateredAt)
+		if err != nil {
+			message := err.Error()
+			if skew != nil {
+				message += ". " + skew.Warning
+			}
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, message)
+			return
+		}
+	}
+
 	// Water the plant
-	plant, err := h.plantService.WaterPlant(user.Email)
+	plant, err := h.plantService.WithContext(r.Context()).WaterPlantByIDAt(id, wateredBy, wateredAt)
 	if err != nil {
-		log.Printf("Failed to water plant: %v", err)
-		http.Error(w, "Failed to water plant", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("Failed to water plant", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to water plant")
 		return
 	}
 
@@ -80,10 +508,76 @@ func (h *PlantHandlers) WaterPlantHandler(w http.ResponseWriter, r *http.Request
 			"name":                 plant.Name,
 			"last_watered":         plant.LastWatered,
 			"timeout_hours":        plant.TimeoutHours,
-			"watered_by":           plant.WateredBy,
+			"grace_period_hours":   plant.GracePeriodHours,
+			"watered_by":           h.displayWateredBy(r, plant.WateredBy),
+			"updated_at":           plant.UpdatedAt,
+			"health_status":        plant.GetHealthStatus(),
+			"time_since_watering":  plant.FormatTimeSinceWatering(i18n.FromContext(r.Context())),
+			"hours_since_watering": plant.GetHoursSinceWatering(),
+			"is_overdue":           plant.IsOverdue(),
+		},
+	}
+	if skew != nil {
+		response["clock_skew"] = skew
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// UndoWateringHandler reverts a plant's latest watering to the one before
+// it, for waterings recorded by mistake. Only the user who recorded the
+// watering or an admin may undo it, within UNDO_WATERING_WINDOW.
+// POST /api/v1/plant/water/undo, POST /api/v1/plants/{id}/water/undo
+func (h *PlantHandlers) UndoWateringHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	plant, undone, err := h.plantService.WithContext(r.Context()).UndoWateringByID(id, user.Email, user.IsAdmin)
+	switch {
+	case errors.Is(err, services.ErrNothingToUndo):
+		respond.Error(w, http.StatusConflict, respond.CodeConflict, "There is no watering to undo")
+		return
+	case errors.Is(err, services.ErrUndoExpired):
+		respond.Error(w, http.StatusConflict, respond.CodeConflict, "The watering is too old to undo")
+		return
+	case errors.Is(err, services.ErrUndoNotPermitted):
+		respond.Error(w, http.StatusForbidden, respond.CodeForbidden, "Only the person who recorded the watering or an admin can undo it")
+		return
+	case err != nil:
+		logger.FromContext(r.Context()).Error("Failed to undo watering", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to undo watering")
+		return
+	}
+	if user.IsAdmin {
+		h.audit(r, models.AuditPlantUndoWatering, strconv.Itoa(id), undone, plantWatering(plant))
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Watering undone",
+		"undone": map[string]interface{}{
+			"watered_at": undone.WateredAt,
+			"watered_by": h.displayWateredBy(r, undone.WateredBy),
+		},
+		"plant": map[string]interface{}{
+			"id":                   plant.ID,
+			"name":                 plant.Name,
+			"last_watered":         plant.LastWatered,
+			"timeout_hours":        plant.TimeoutHours,
+			"grace_period_hours":   plant.GracePeriodHours,
+			"watered_by":           h.displayWateredBy(r, plant.WateredBy),
 			"updated_at":           plant.UpdatedAt,
 			"health_status":        plant.GetHealthStatus(),
-			"time_since_watering":  plant.GetFormattedTimeSinceWatering(),
+			"time_since_watering":  plant.FormatTimeSinceWatering(i18n.FromContext(r.Context())),
 			"hours_since_watering": plant.GetHoursSinceWatering(),
 			"is_overdue":           plant.IsOverdue(),
 		},
@@ -94,26 +588,37 @@ func (h *PlantHandlers) WaterPlantHandler(w http.ResponseWriter, r *http.Request
 }
 
 // GetPlantStatusHandler returns just the plant health status
-// GET /api/plant/status
+// GET /api/v1/plant/status, GET /api/v1/plants/{id}/status
 func (h *PlantHandlers) GetPlantStatusHandler(w http.ResponseWriter, r *http.Request) {
-	status, err := h.plantService.GetPlantStatus()
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.plantService.WithContext(r.Context()).GetPlantStatusByID(id)
 	if err != nil {
-		log.Printf("Failed to get plant status: %v", err)
-		http.Error(w, "Failed to get plant status", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("Failed to get plant status", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to get plant status")
 		return
 	}
+	status.ClockSkew = h.clockSkew(r)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(status)
 }
 
 // GetPlantTimerHandler returns plant timer information
-// GET /api/plant/timer
+// GET /api/v1/plant/timer, GET /api/v1/plants/{id}/timer
 func (h *PlantHandlers) GetPlantTimerHandler(w http.ResponseWriter, r *http.Request) {
-	timer, err := h.plantService.GetPlantTimer()
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	timer, err := h.plantService.WithContext(r.Context()).GetPlantTimerByID(id)
 	if err != nil {
-		log.Printf("Failed to get plant timer: %v", err)
-		http.Error(w, "Failed to get plant timer", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("Failed to get plant timer", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to get plant timer")
 		return
 	}
 
@@ -122,27 +627,88 @@ func (h *PlantHandlers) GetPlantTimerHandler(w http.ResponseWriter, r *http.Requ
 }
 
 // UpdatePlantSettingsHandler updates plant configuration (admin only)
-// PUT /api/plant/settings
+// PUT /api/v1/plant/settings, PUT /api/v1/plants/{id}/settings
 func (h *PlantHandlers) UpdatePlantSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
 	// Parse request body
 	var req struct {
-		Name         string `json:"name"`
-		TimeoutHours int    `json:"timeout_hours"`
+		Name             string                           `json:"name" validate:"max=100"`
+		TimeoutHours     int                              `json:"timeout_hours" validate:"min=0,max=8760"`
+		GracePeriodHours *int                             `json:"grace_period_hours" validate:"min=0,max=8760"`
+		Metadata         *map[string]models.MetadataValue `json:"metadata"`
+		Outdoor          *bool                            `json:"outdoor"`
+		// Percentages of the timeout; 0 restores the default
+		NeedsWaterPercent *int `json:"needs_water_percent" validate:"min=0,max=500"`
+		CriticalPercent   *int `json:"critical_percent" validate:"min=0,max=500"`
 	}
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !validate.DecodeJSON(w, r, &req) {
 		return
 	}
 
+	var oldSettings interface{}
+	service := h.plantService.WithContext(r.Context())
+	if plant, err := service.GetPlantByID(id); err == nil {
+		oldSettings = plantSettings(plant)
+	}
+
 	// Update plant settings
-	plant, err := h.plantService.UpdatePlantSettings(req.Name, req.TimeoutHours)
+	plant, err := service.UpdatePlantSettingsByID(id, req.Name, req.TimeoutHours)
 	if err != nil {
-		log.Printf("Failed to update plant settings: %v", err)
-		http.Error(w, "Failed to update plant settings: "+err.Error(), http.StatusBadRequest)
+		logger.FromContext(r.Context()).Error("Failed to update plant settings", "error", err)
+		writePlantError(w, err, http.StatusBadRequest, respond.CodeBadRequest, "Failed to update plant settings: "+err.Error())
 		return
 	}
 
+	if req.GracePeriodHours != nil {
+		plant, err = service.UpdateGracePeriodByID(id, *req.GracePeriodHours)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("Failed to update grace period", "error", err)
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Failed to update plant settings: "+err.Error())
+			return
+		}
+	}
+
+	if req.Metadata != nil {
+		plant, err = service.UpdateMetadataByID(id, *req.Metadata)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("Failed to update metadata", "error", err)
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Failed to update plant settings: "+err.Error())
+			return
+		}
+	}
+
+	if req.NeedsWaterPercent != nil || req.CriticalPercent != nil {
+		needsWater, critical := plant.NeedsWaterPercent, plant.CriticalPercent
+		if req.NeedsWaterPercent != nil {
+			needsWater = *req.NeedsWaterPercent
+		}
+		if req.CriticalPercent != nil {
+			critical = *req.CriticalPercent
+		}
+		plant, err = service.UpdateHealthThresholdsByID(id, needsWater, critical)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("Failed to update health thresholds", "error", err)
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Failed to update plant settings: "+err.Error())
+			return
+		}
+	}
+
+	if req.Outdoor != nil {
+		plant, err = service.UpdateOutdoorByID(id, *req.Outdoor)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("Failed to update outdoor setting", "error", err)
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Failed to update plant settings: "+err.Error())
+			return
+		}
+	}
+
+	h.audit(r, models.AuditPlantSettings, strconv.Itoa(id), oldSettings, plantSettings(plant))
+
+	needsWater, critical := plant.HealthThresholds()
 	response := map[string]interface{}{
 		"success": true,
 		"message": "Plant settings updated successfully",
@@ -151,10 +717,15 @@ func (h *PlantHandlers) UpdatePlantSettingsHandler(w http.ResponseWriter, r *htt
 			"name":                plant.Name,
 			"last_watered":        plant.LastWatered,
 			"timeout_hours":       plant.TimeoutHours,
-			"watered_by":          plant.WateredBy,
+			"grace_period_hours":  plant.GracePeriodHours,
+			"needs_water_percent": needsWater,
+			"critical_percent":    critical,
+			"watered_by":          h.displayWateredBy(r, plant.WateredBy),
+			"metadata":            h.displayMetadata(r, plant.Metadata),
+			"outdoor":             plant.Outdoor,
 			"updated_at":          plant.UpdatedAt,
 			"health_status":       plant.GetHealthStatus(),
-			"time_since_watering": plant.GetFormattedTimeSinceWatering(),
+			"time_since_watering": plant.FormatTimeSinceWatering(i18n.FromContext(r.Context())),
 		},
 	}
 
@@ -162,15 +733,111 @@ func (h *PlantHandlers) UpdatePlantSettingsHandler(w http.ResponseWriter, r *htt
 	json.NewEncoder(w).Encode(response)
 }
 
+// SetVacationHandler pauses a plant's reminders and overdue status from
+// start, or now, until end (admin only)
+// PUT /api/v1/plant/vacation, PUT /api/v1/plants/{id}/vacation
+func (h *PlantHandlers) SetVacationHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Start *time.Time `json:"start"`
+		End   *time.Time `json:"end" validate:"required"`
+	}
+	if !validate.DecodeJSON(w, r, &req) {
+		return
+	}
+	start := time.Now()
+	if req.Start != nil {
+		start = *req.Start
+	}
+
+	var oldVacation interface{}
+	service := h.plantService.WithContext(r.Context())
+	if plant, err := service.GetPlantByID(id); err == nil && plant.Vacation != nil {
+		oldVacation = plant.Vacation
+	}
+
+	plant, err := service.SetVacationByID(id, start, *req.End)
+	if errors.Is(err, services.ErrInvalidVacation) {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to set vacation", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to set vacation")
+		return
+	}
+	h.audit(r, models.AuditPlantVacation, strconv.Itoa(id), oldVacation, plant.Vacation)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Vacation set; reminders are paused until it ends",
+		"plant":   h.plantSummary(r, plant),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ClearVacationHandler ends a plant's vacation early or cancels an upcoming
+// one (admin only)
+// DELETE /api/v1/plant/vacation, DELETE /api/v1/plants/{id}/vacation
+func (h *PlantHandlers) ClearVacationHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var oldVacation interface{}
+	service := h.plantService.WithContext(r.Context())
+	if plant, err := service.GetPlantByID(id); err == nil && plant.Vacation != nil {
+		oldVacation = plant.Vacation
+	}
+
+	plant, err := service.ClearVacationByID(id)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to clear vacation", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to clear vacation")
+		return
+	}
+	if oldVacation != nil {
+		h.audit(r, models.AuditPlantVacation, strconv.Itoa(id), oldVacation, nil)
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Vacation cleared",
+		"plant":   h.plantSummary(r, plant),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 // ResetPlantHandler resets the plant to unwatered state (admin only)
-// POST /api/plant/reset
+// POST /api/v1/plant/reset, POST /api/v1/plants/{id}/reset
 func (h *PlantHandlers) ResetPlantHandler(w http.ResponseWriter, r *http.Request) {
-	plant, err := h.plantService.ResetPlant()
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var oldWatering interface{}
+	service := h.plantService.WithContext(r.Context())
+	if plant, err := service.GetPlantByID(id); err == nil {
+		oldWatering = plantWatering(plant)
+	}
+
+	plant, err := service.ResetPlantByID(id)
 	if err != nil {
-		log.Printf("Failed to reset plant: %v", err)
-		http.Error(w, "Failed to reset plant", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("Failed to reset plant", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to reset plant")
 		return
 	}
+	h.audit(r, models.AuditPlantReset, strconv.Itoa(id), oldWatering, plantWatering(plant))
 
 	response := map[string]interface{}{
 		"success": true,
@@ -180,10 +847,11 @@ func (h *PlantHandlers) ResetPlantHandler(w http.ResponseWriter, r *http.Request
 			"name":                plant.Name,
 			"last_watered":        plant.LastWatered,
 			"timeout_hours":       plant.TimeoutHours,
-			"watered_by":          plant.WateredBy,
+			"grace_period_hours":  plant.GracePeriodHours,
+			"watered_by":          h.displayWateredBy(r, plant.WateredBy),
 			"updated_at":          plant.UpdatedAt,
 			"health_status":       plant.GetHealthStatus(),
-			"time_since_watering": plant.GetFormattedTimeSinceWatering(),
+			"time_since_watering": plant.FormatTimeSinceWatering(i18n.FromContext(r.Context())),
 		},
 	}
 
@@ -5,9 +5,18 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
 
 	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/i18n"
+	"watered/internal/models"
+	"watered/internal/respond"
 	"watered/internal/services"
 	"watered/internal/storage"
 )
@@ -16,7 +25,7 @@ func TestPlantHandlers_GetPlantHandler(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, config.AuthConfig{})
 	plantService := services.NewPlantService(store)
 	handlers := NewPlantHandlers(plantService, authService)
 
@@ -51,7 +60,7 @@ func TestPlantHandlers_GetPlantStatusHandler(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, config.AuthConfig{})
 	plantService := services.NewPlantService(store)
 	handlers := NewPlantHandlers(plantService, authService)
 
@@ -82,7 +91,7 @@ func TestPlantHandlers_GetPlantTimerHandler(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, config.AuthConfig{})
 	plantService := services.NewPlantService(store)
 	handlers := NewPlantHandlers(plantService, authService)
 
@@ -117,7 +126,7 @@ func TestPlantHandlers_WaterPlantHandler(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, config.AuthConfig{})
 	plantService := services.NewPlantService(store)
 	handlers := NewPlantHandlers(plantService, authService)
 
@@ -185,11 +194,478 @@ func TestPlantHandlers_WaterPlantHandler(t *testing.T) {
 	}
 }
 
+func TestPlantHandlers_WaterPlantHandler_WateredAt(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+	cookies := sessionCookies(t, authService, "test@example.com")
+
+	water := func(body string, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/plant/water", bytes.NewBufferString(body))
+		if header != "" {
+			req.Header.Set(services.ClientTimeHeader, header)
+		}
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		handlers.WaterPlantHandler(w, req)
+		return w
+	}
+
+	// A backdated watering is accepted
+	wateredAt := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+	w := water(`{"watered_at": "`+wateredAt.Format(time.RFC3339)+`"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	plant, err := plantService.GetPlant()
+	if err != nil {
+		t.Fatalf("Failed to get plant: %v", err)
+	}
+	if !plant.LastWatered.Equal(wateredAt) {
+		t.Errorf("Expected last watered %v, got %v", wateredAt, plant.LastWatered)
+	}
+
+	// A tablet with its clock a day ahead is told why its watering was refused
+	tomorrow := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	w = water(`{"watered_at": "`+tomorrow+`"}`, tomorrow)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d for a future watering, got %d", http.StatusBadRequest, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "in the future") || !strings.Contains(w.Body.String(), "ahead of the server") {
+		t.Errorf("Expected future and clock skew errors, got %q", w.Body.String())
+	}
+
+	// Watering now from a skewed device succeeds with a warning
+	w = water("", time.Now().Add(-time.Hour).UTC().Format(time.RFC3339))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var response struct {
+		ClockSkew *services.ClockSkew `json:"clock_skew"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.ClockSkew == nil || response.ClockSkew.SkewSeconds > -3500 {
+		t.Errorf("Expected a clock skew of about an hour behind, got %+v", response.ClockSkew)
+	}
+
+	if w := water("{", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a malformed body, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestPlantHandlers_WaterPlantHandler_WateredBy(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AdminEmails:   []string{"admin@example.com"},
+		AllowedEmails: []string{"alice@example.com", "bob@example.com"},
+	})
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+
+	water := func(cookies []*http.Cookie, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/plant/water", bytes.NewBufferString(body))
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		handlers.WaterPlantHandler(w, req)
+		return w
+	}
+	alice := sessionCookies(t, authService, "alice@example.com")
+	admin := sessionCookies(t, authService, "admin@example.com")
+
+	// Members can only record their own waterings
+	if w := water(alice, `{"watered_by": "bob@example.com"}`); w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+	if w := water(alice, `{"watered_by": "Alice@example.com"}`); w.Code != http.StatusOK {
+		t.Errorf("Expected status %d naming themselves, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Admins can log a forgotten watering for someone in the household
+	yesterday := time.Now().Add(-24 * time.Hour).UTC().Truncate(time.Second)
+	w := water(admin, `{"watered_by": "bob@example.com", "watered_at": "`+yesterday.Format(time.RFC3339)+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	events, _ := store.ListWateringEvents(models.DefaultPlantID)
+	if len(events) != 2 || events[0].WateredBy != "bob@example.com" || !events[0].WateredAt.Equal(yesterday) {
+		t.Errorf("Expected bob's backdated watering in the history, got %+v", events)
+	}
+
+	for body, field := range map[string]string{
+		`{"watered_by": "not an email"}`:        "watered_by",
+		`{"watered_by": "mallory@example.com"}`: "watered_by",
+	} {
+		w := water(admin, body)
+		var failure respond.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &failure); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if w.Code != http.StatusBadRequest || len(failure.Error.Fields) != 1 || failure.Error.Fields[0].Field != field {
+			t.Errorf("Expected a %s field error for %s, got %d %s", field, body, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestPlantHandlers_UndoWateringHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, AdminEmails: []string{"admin@example.com"}})
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+
+	undo := func(cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/plant/water/undo", nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		handlers.UndoWateringHandler(w, req)
+		return w
+	}
+	alice := sessionCookies(t, authService, "alice@example.com")
+	bob := sessionCookies(t, authService, "bob@example.com")
+	admin := sessionCookies(t, authService, "admin@example.com")
+
+	if w := undo(nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d without a session, got %d", http.StatusUnauthorized, w.Code)
+	}
+	if w := undo(alice); w.Code != http.StatusConflict {
+		t.Errorf("Expected status %d with nothing to undo, got %d", http.StatusConflict, w.Code)
+	}
+
+	yesterday := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	plantService.WaterPlantByIDAt(models.DefaultPlantID, "bob@example.com", yesterday)
+	plantService.WaterPlant("alice@example.com")
+
+	if w := undo(bob); w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for someone else's watering, got %d", http.StatusForbidden, w.Code)
+	}
+
+	w := undo(alice)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var response struct {
+		Plant struct {
+			LastWatered time.Time `json:"last_watered"`
+			WateredBy   string    `json:"watered_by"`
+		} `json:"plant"`
+		Undone struct {
+			WateredBy string `json:"watered_by"`
+		} `json:"undone"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if !response.Plant.LastWatered.Equal(yesterday) || response.Plant.WateredBy != "bob@example.com" || response.Undone.WateredBy != "alice@example.com" {
+		t.Errorf("Expected bob's watering to be restored, got %+v", response)
+	}
+
+	// Admins may undo anyone's watering
+	if w := undo(admin); w.Code != http.StatusOK {
+		t.Errorf("Expected status %d for an admin, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if plant, _ := plantService.GetPlant(); plant.LastWatered != nil {
+		t.Errorf("Expected no watering left, got %v", plant.LastWatered)
+	}
+
+	// Waterings outside the window are kept
+	plantService.SetUndoWateringWindow(time.Nanosecond)
+	plantService.WaterPlant("alice@example.com")
+	w = undo(alice)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status %d after the window, got %d", http.StatusConflict, w.Code)
+	}
+	var failure respond.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &failure); err != nil || failure.Error.Code != respond.CodeConflict {
+		t.Errorf("Expected a %s error, got %s", respond.CodeConflict, w.Body.String())
+	}
+}
+
+func TestPlantHandlers_ClockSkew(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+
+	tests := []struct {
+		name   string
+		header string
+		skewed bool
+	}{
+		{"no header", "", false},
+		{"in sync", strconv.FormatInt(time.Now().UnixMilli(), 10), false},
+		{"malformed", "tomorrow", false},
+		{"ahead", strconv.FormatInt(time.Now().Add(10*time.Minute).UnixMilli(), 10), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for path, handler := range map[string]http.HandlerFunc{
+				"/api/time":         handlers.GetTimeHandler,
+				"/api/plant/status": handlers.GetPlantStatusHandler,
+			} {
+				req := httptest.NewRequest("GET", path, nil)
+				if tt.header != "" {
+					req.Header.Set(services.ClientTimeHeader, tt.header)
+				}
+				w := httptest.NewRecorder()
+				handler(w, req)
+
+				if w.Code != http.StatusOK {
+					t.Fatalf("%s: expected status %d, got %d", path, http.StatusOK, w.Code)
+				}
+				var response map[string]interface{}
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("%s: failed to parse response: %v", path, err)
+				}
+				if _, ok := response["clock_skew"]; ok != tt.skewed {
+					t.Errorf("%s: expected clock_skew present %v, got %v", path, tt.skewed, response["clock_skew"])
+				}
+				if path == "/api/time" && (response["unix_ms"] == nil || response["skew_tolerance_seconds"] != float64(60)) {
+					t.Errorf("Unexpected time response: %v", response)
+				}
+			}
+		})
+	}
+}
+
+func TestPlantHandlers_PrivacyMode(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+
+	plantService.WaterPlant("test@example.com")
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, PrivacyMode: true})
+
+	tests := []struct {
+		name     string
+		email    string
+		expected string
+	}{
+		{"anonymous visitor", "", models.AnonymousWaterer},
+		{"regular user", "test@example.com", models.AnonymousWaterer},
+		{"admin", "admin@example.com", "test@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/plant", nil)
+			if tt.email != "" {
+				for _, cookie := range sessionCookies(t, authService, tt.email) {
+					req.AddCookie(cookie)
+				}
+			}
+			w := httptest.NewRecorder()
+
+			handlers.GetPlantHandler(w, req)
+
+			var response map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if response["watered_by"] != tt.expected {
+				t.Errorf("Expected watered_by %q, got %v", tt.expected, response["watered_by"])
+			}
+		})
+	}
+}
+
+func TestPlantHandlers_GetPlantStatsHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+
+	plantService.WaterPlant("test@example.com")
+	plantService.WaterPlant("other@example.com")
+
+	tests := []struct {
+		name        string
+		email       string
+		privacyMode bool
+		users       int
+	}{
+		{"anonymous visitor", "", false, 0},
+		{"regular user", "test@example.com", false, 2},
+		{"regular user in privacy mode", "test@example.com", true, 1},
+		{"admin in privacy mode", "admin@example.com", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, PrivacyMode: tt.privacyMode})
+
+			req := httptest.NewRequest("GET", "/api/plant/stats", nil)
+			if tt.email != "" {
+				for _, cookie := range sessionCookies(t, authService, tt.email) {
+					req.AddCookie(cookie)
+				}
+			}
+			w := httptest.NewRecorder()
+
+			handlers.GetPlantStatsHandler(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			var response struct {
+				Household struct {
+					Waterings     int `json:"waterings"`
+					CurrentStreak int `json:"current_streak_days"`
+				} `json:"household"`
+				Users []struct {
+					Email string `json:"email"`
+				} `json:"users"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if response.Household.Waterings != 2 || response.Household.CurrentStreak != 1 {
+				t.Errorf("Expected 2 waterings today, got %+v", response.Household)
+			}
+			if len(response.Users) != tt.users {
+				t.Errorf("Expected %d users, got %+v", tt.users, response.Users)
+			}
+			if tt.users == 1 && response.Users[0].Email != tt.email {
+				t.Errorf("Expected only the caller's own stats, got %+v", response.Users)
+			}
+		})
+	}
+}
+
+func TestPlantHandlers_GetLeaderboardHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+
+	for _, email := range []string{"alice@example.com", "alice@example.com", "bob@example.com"} {
+		if _, err := plantService.WaterPlant(email); err != nil {
+			t.Fatalf("Failed to water plant: %v", err)
+		}
+	}
+
+	get := func(query, email, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/leaderboard"+query, nil)
+		if email != "" {
+			for _, cookie := range sessionCookies(t, authService, email) {
+				req.AddCookie(cookie)
+			}
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		handlers.GetLeaderboardHandler(w, req)
+		return w
+	}
+	emails := func(w *httptest.ResponseRecorder) []string {
+		var response struct {
+			Entries []struct {
+				Rank      int    `json:"rank"`
+				Email     string `json:"email"`
+				Waterings int    `json:"waterings"`
+			} `json:"entries"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		result := make([]string, len(response.Entries))
+		for i, entry := range response.Entries {
+			result[i] = entry.Email
+		}
+		return result
+	}
+
+	w := get("?period=month", "bob@example.com", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if got := emails(w); len(got) != 2 || got[0] != "alice@example.com" || got[1] != "bob@example.com" {
+		t.Errorf("Expected alice ahead of bob, got %v", got)
+	}
+
+	// Unchanged leaderboards are answered from the client's cache
+	etag := w.Header().Get("ETag")
+	if etag == "" || !strings.HasPrefix(w.Header().Get("Cache-Control"), "private") {
+		t.Errorf("Expected a private cacheable response, got headers %v", w.Header())
+	}
+	if w := get("?period=month", "bob@example.com", etag); w.Code != http.StatusNotModified {
+		t.Errorf("Expected status %d for a matching ETag, got %d", http.StatusNotModified, w.Code)
+	}
+
+	// Viewers see ranks without names
+	if got := emails(get("", "", "")); len(got) != 2 || got[0] != "" || got[1] != "" {
+		t.Errorf("Expected anonymous entries for viewers, got %v", got)
+	}
+
+	// With privacy mode on, members only see their own name
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, PrivacyMode: true})
+	if got := emails(get("", "bob@example.com", "")); len(got) != 2 || got[0] != "" || got[1] != "bob@example.com" {
+		t.Errorf("Expected only bob's name in privacy mode, got %v", got)
+	}
+	if got := emails(get("", "admin@example.com", "")); len(got) != 2 || got[0] != "alice@example.com" {
+		t.Errorf("Expected admins to see every name, got %v", got)
+	}
+
+	if w := get("?period=year", "bob@example.com", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown period, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestPlantHandlers_ViewerNeverSeesWaterer(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+
+	plantService.WaterPlant("test@example.com")
+
+	req := httptest.NewRequest("GET", "/api/plant", nil)
+	w := httptest.NewRecorder()
+	handlers.GetPlantHandler(w, req)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response["watered_by"] != models.AnonymousWaterer {
+		t.Errorf("Expected anonymous visitors to see %q, got %v", models.AnonymousWaterer, response["watered_by"])
+	}
+}
+
 func TestPlantHandlers_UpdatePlantSettingsHandler(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, config.AuthConfig{})
 	plantService := services.NewPlantService(store)
 	handlers := NewPlantHandlers(plantService, authService)
 
@@ -232,11 +708,304 @@ func TestPlantHandlers_UpdatePlantSettingsHandler(t *testing.T) {
 	}
 }
 
+func TestPlantHandlers_UpdatePlantSettingsHandler_GracePeriod(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{"grace_period_hours": 6})
+	req := httptest.NewRequest("PUT", "/api/plant/settings", bytes.NewReader(jsonBody))
+	w := httptest.NewRecorder()
+
+	handlers.UpdatePlantSettingsHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	plant := response["plant"].(map[string]interface{})
+	if plant["grace_period_hours"] != 6.0 {
+		t.Errorf("Expected grace_period_hours 6, got %v", plant["grace_period_hours"])
+	}
+
+	// Negative grace period is rejected
+	jsonBody, _ = json.Marshal(map[string]interface{}{"grace_period_hours": -2})
+	req = httptest.NewRequest("PUT", "/api/plant/settings", bytes.NewReader(jsonBody))
+	w = httptest.NewRecorder()
+
+	handlers.UpdatePlantSettingsHandler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var failure respond.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &failure); err != nil {
+		t.Fatalf("Failed to parse error response: %v", err)
+	}
+	want := respond.FieldError{Field: "grace_period_hours", Message: "must be between 0 and 8760"}
+	if len(failure.Error.Fields) != 1 || failure.Error.Fields[0] != want {
+		t.Errorf("Expected field error %+v, got %+v", want, failure.Error.Fields)
+	}
+}
+
+func TestPlantHandlers_UpdatePlantSettingsHandler_Thresholds(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{"needs_water_percent": 70, "critical_percent": 120})
+	req := httptest.NewRequest("PUT", "/api/v1/plant/settings", bytes.NewReader(jsonBody))
+	w := httptest.NewRecorder()
+
+	handlers.UpdatePlantSettingsHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	plant := response["plant"].(map[string]interface{})
+	if plant["needs_water_percent"] != 70.0 || plant["critical_percent"] != 120.0 {
+		t.Errorf("Expected thresholds 70%% and 120%%, got %v and %v", plant["needs_water_percent"], plant["critical_percent"])
+	}
+
+	// The timer counts down to the critical threshold
+	w = httptest.NewRecorder()
+	handlers.GetPlantTimerHandler(w, httptest.NewRequest("GET", "/api/v1/plant/timer", nil))
+	var timer map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &timer); err != nil {
+		t.Fatalf("Failed to parse timer: %v", err)
+	}
+	if timer["critical_percent"] != 120.0 {
+		t.Errorf("Expected the timer to report the critical threshold, got %v", timer["critical_percent"])
+	}
+
+	// A needs water threshold at or above the critical one is rejected
+	for _, body := range []map[string]interface{}{{"needs_water_percent": 120}, {"critical_percent": 600}} {
+		jsonBody, _ = json.Marshal(body)
+		w = httptest.NewRecorder()
+		handlers.UpdatePlantSettingsHandler(w, httptest.NewRequest("PUT", "/api/v1/plant/settings", bytes.NewReader(jsonBody)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %v, got %d", http.StatusBadRequest, body, w.Code)
+		}
+	}
+	if plant, _ := plantService.GetPlant(); plant.NeedsWaterPercent != 70 || plant.CriticalPercent != 120 {
+		t.Errorf("Expected rejected thresholds to leave the plant unchanged, got %d%% and %d%%", plant.NeedsWaterPercent, plant.CriticalPercent)
+	}
+}
+
+func TestPlantHandlers_UpdatePlantSettingsHandler_Outdoor(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{"outdoor": true})
+	req := httptest.NewRequest("PUT", "/api/v1/plant/settings", bytes.NewReader(jsonBody))
+	w := httptest.NewRecorder()
+
+	handlers.UpdatePlantSettingsHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if plant := response["plant"].(map[string]interface{}); plant["outdoor"] != true {
+		t.Errorf("Expected outdoor true, got %v", plant["outdoor"])
+	}
+
+	// The timer shows no adjustment until the forecast has been fetched
+	w = httptest.NewRecorder()
+	handlers.GetPlantTimerHandler(w, httptest.NewRequest("GET", "/api/v1/plant/timer", nil))
+
+	var timer map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &timer); err != nil {
+		t.Fatalf("Failed to parse timer: %v", err)
+	}
+	if timer["weather_factor"] != 1.0 || timer["effective_timeout_hours"] != 24.0 {
+		t.Errorf("Expected an unadjusted timer, got %v", timer)
+	}
+}
+
+func TestPlantHandlers_Metadata(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+
+	plantService.GetPlant()
+	if _, err := plantService.CreatePlant("Fern", 48); err != nil {
+		t.Fatalf("Failed to create plant: %v", err)
+	}
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"location": map[string]string{"value": "Balcony"},
+			"pot_size": map[string]string{"type": "number", "value": "18.0"},
+		},
+	})
+	req := httptest.NewRequest("PUT", "/api/plant/settings", bytes.NewReader(jsonBody))
+	w := httptest.NewRecorder()
+	handlers.UpdatePlantSettingsHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Values that do not match their type are rejected
+	jsonBody, _ = json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"purchased": map[string]string{"type": "date", "value": "last spring"}},
+	})
+	req = httptest.NewRequest("PUT", "/api/plant/settings", bytes.NewReader(jsonBody))
+	w = httptest.NewRecorder()
+	handlers.UpdatePlantSettingsHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid date, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	tests := []struct {
+		name     string
+		email    string
+		query    string
+		expected int
+	}{
+		{"no filter", "test@example.com", "", 2},
+		{"matching filter", "test@example.com", "?meta.location=balcony", 1},
+		{"normalized number", "test@example.com", "?meta.pot_size=18", 1},
+		{"no match", "test@example.com", "?meta.location=kitchen", 0},
+		{"viewer cannot filter", "", "?meta.location=balcony", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/plants"+tt.query, nil)
+			if tt.email != "" {
+				for _, cookie := range sessionCookies(t, authService, tt.email) {
+					req.AddCookie(cookie)
+				}
+			}
+			w := httptest.NewRecorder()
+			handlers.ListPlantsHandler(w, req)
+
+			var response struct {
+				Plants []map[string]interface{} `json:"plants"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if len(response.Plants) != tt.expected {
+				t.Fatalf("Expected %d plants, got %d", tt.expected, len(response.Plants))
+			}
+			if tt.query != "" && tt.expected == 1 {
+				metadata, _ := response.Plants[0]["metadata"].(map[string]interface{})
+				if pot, _ := metadata["pot_size"].(map[string]interface{}); pot["value"] != "18" {
+					t.Errorf("Expected pot_size 18, got %v", metadata["pot_size"])
+				}
+			}
+		})
+	}
+
+	// Viewers do not see custom fields
+	w = httptest.NewRecorder()
+	handlers.GetPlantHandler(w, httptest.NewRequest("GET", "/api/plant", nil))
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response["metadata"] != nil {
+		t.Errorf("Expected no metadata for anonymous visitors, got %v", response["metadata"])
+	}
+}
+
+func TestPlantHandlers_Vacation(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+	cookies := sessionCookies(t, authService, "test@example.com")
+
+	end := time.Now().Add(7 * 24 * time.Hour).UTC().Truncate(time.Second)
+	req := httptest.NewRequest("PUT", "/api/v1/plant/vacation", bytes.NewBufferString(`{"end": "`+end.Format(time.RFC3339)+`"}`))
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	handlers.SetVacationHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Plant struct {
+			HealthStatus models.PlantHealthStatus `json:"health_status"`
+			Vacation     *models.Vacation         `json:"vacation"`
+		} `json:"plant"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Plant.HealthStatus != models.HealthStatusPaused || response.Plant.Vacation == nil || !response.Plant.Vacation.End.Equal(end) {
+		t.Errorf("Expected a paused plant until %v, got %+v", end, response.Plant)
+	}
+
+	// Viewers see the plant is paused but not until when
+	w = httptest.NewRecorder()
+	handlers.GetPlantHandler(w, httptest.NewRequest("GET", "/api/v1/plant", nil))
+	if strings.Contains(w.Body.String(), end.Format(time.RFC3339)) {
+		t.Errorf("Expected the vacation to be hidden from viewers, got %s", w.Body.String())
+	}
+
+	for body, code := range map[string]string{
+		`{}`: respond.CodeValidation,
+		`{"start": "` + end.Format(time.RFC3339) + `", "end": "` + end.Add(-time.Hour).Format(time.RFC3339) + `"}`: respond.CodeBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		handlers.SetVacationHandler(w, httptest.NewRequest("PUT", "/api/v1/plant/vacation", bytes.NewBufferString(body)))
+		var failure respond.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &failure); err != nil || w.Code != http.StatusBadRequest || failure.Error.Code != code {
+			t.Errorf("Expected a 400 %s error for %s, got %d %s", code, body, w.Code, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	handlers.ClearVacationHandler(w, httptest.NewRequest("DELETE", "/api/v1/plant/vacation", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if plant, _ := plantService.GetPlant(); plant.Vacation != nil || plant.GetHealthStatus() == models.HealthStatusPaused {
+		t.Errorf("Expected the vacation to be cleared, got %+v", plant.Vacation)
+	}
+}
+
 func TestPlantHandlers_ResetPlantHandler(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, config.AuthConfig{})
 	plantService := services.NewPlantService(store)
 	handlers := NewPlantHandlers(plantService, authService)
 
@@ -281,3 +1050,95 @@ func TestPlantHandlers_ResetPlantHandler(t *testing.T) {
 		t.Errorf("Expected health_status 'critical' after reset, got %v", plant["health_status"])
 	}
 }
+
+func TestPlantHandlers_PlantIDRouting(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	handlers := NewPlantHandlers(plantService, authService)
+
+	r := chi.NewRouter()
+	r.Get("/api/plants", handlers.ListPlantsHandler)
+	r.Post("/api/plants", handlers.CreatePlantHandler)
+	r.Get("/api/plants/{id}", handlers.GetPlantHandler)
+	r.Delete("/api/plants/{id}", handlers.DeletePlantHandler)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{"name": "Fern", "timeout_hours": 48})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/plants", bytes.NewReader(jsonBody)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d creating plant, got %d", http.StatusCreated, w.Code)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		expected int
+		code     string
+	}{
+		{"list", "GET", "/api/plants", http.StatusOK, ""},
+		{"get default", "GET", "/api/plants/1", http.StatusOK, ""},
+		{"get new", "GET", "/api/plants/2", http.StatusOK, ""},
+		{"get missing", "GET", "/api/plants/42", http.StatusNotFound, respond.CodeNotFound},
+		{"invalid id", "GET", "/api/plants/abc", http.StatusBadRequest, respond.CodeBadRequest},
+		{"delete default", "DELETE", "/api/plants/1", http.StatusBadRequest, respond.CodeBadRequest},
+		{"delete new", "DELETE", "/api/plants/2", http.StatusOK, ""},
+		{"delete missing", "DELETE", "/api/plants/2", http.StatusNotFound, respond.CodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+			if tt.code == "" {
+				return
+			}
+
+			var response respond.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode error response %q: %v", w.Body.String(), err)
+			}
+			if response.Error.Code != tt.code || response.Error.Message == "" {
+				t.Errorf("Expected error code %q with a message, got %+v", tt.code, response.Error)
+			}
+		})
+	}
+}
+
+func TestPlantHandlers_Translated(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := services.NewPlantService(store)
+	plantHandlers := NewPlantHandlers(plantService, auth.NewAuthService(store, config.AuthConfig{}))
+	if _, err := plantService.WaterPlant("test@example.com"); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/plant/timer", nil)
+	req = req.WithContext(i18n.WithPrinter(req.Context(), i18n.Get("es")))
+	w := httptest.NewRecorder()
+	plantHandlers.GetPlantTimerHandler(w, req)
+	var timer services.PlantTimerResponse
+	if err := json.NewDecoder(w.Body).Decode(&timer); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if timer.TimeSinceWateringFormatted != "hace 0 minutos" {
+		t.Errorf("Expected the timer in Spanish, got %q", timer.TimeSinceWateringFormatted)
+	}
+
+	w = httptest.NewRecorder()
+	plantHandlers.GetPlantHandler(w, req)
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response["time_since_watering"] != "hace 0 minutos" {
+		t.Errorf("Expected the plant in Spanish, got %v", response["time_since_watering"])
+	}
+}
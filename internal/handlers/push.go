@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"watered/internal/auth"
+	"watered/internal/logger"
+	"watered/internal/privacy"
+	"watered/internal/respond"
+	"watered/internal/services"
+)
+
+// PushHandlers contains Web Push subscription HTTP handlers
+type PushHandlers struct {
+	pushService *services.PushService
+	authService *auth.AuthService
+}
+
+// NewPushHandlers creates a new push handlers instance
+func NewPushHandlers(pushService *services.PushService, authService *auth.AuthService) *PushHandlers {
+	return &PushHandlers{
+		pushService: pushService,
+		authService: authService,
+	}
+}
+
+// pushSubscriptionRequest mirrors the browser's PushSubscription.toJSON() output
+type pushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// GetVAPIDPublicKeyHandler returns the key browsers pass as applicationServerKey
+// GET /api/v1/push/vapid-public-key
+func (h *PushHandlers) GetVAPIDPublicKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.pushService.Enabled() {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Push notifications are not configured")
+		return
+	}
+
+	response := map[string]interface{}{
+		"publicKey": h.pushService.PublicKey(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// SubscribeHandler registers a push subscription for the current user
+// POST /api/v1/push/subscriptions
+func (h *PushHandlers) SubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	var req pushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid request body")
+		return
+	}
+
+	subscription, err := h.pushService.Subscribe(user.Email, req.Endpoint, req.Keys.P256dh, req.Keys.Auth, r.UserAgent())
+	if errors.Is(err, services.ErrPushDisabled) {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Push notifications are not configured")
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to register push subscription", "email", user.Email, "error", err)
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Failed to register push subscription: "+err.Error())
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":      true,
+		"message":      "Push notifications enabled",
+		"subscription": subscription,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(privacy.Mask(response, h.authService.CallerRole(r)))
+}
+
+// UnsubscribeHandler removes one of the current user's push subscriptions
+// DELETE /api/v1/push/subscriptions
+func (h *PushHandlers) UnsubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	var req struct {
+		Endpoint string `json:"endpoint"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Endpoint == "" {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid request body")
+		return
+	}
+
+	if err := h.pushService.Unsubscribe(user.Email, req.Endpoint); err != nil {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Subscription not found")
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Push notifications disabled",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
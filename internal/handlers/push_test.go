@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/push"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPushService(t *testing.T, store storage.Storage) *services.PushService {
+	t.Helper()
+
+	keys, err := push.GenerateVAPIDKeys("mailto:admin@example.com")
+	require.NoError(t, err)
+	return services.NewPushService(store, push.NewSender(keys))
+}
+
+func subscriptionBody(t *testing.T, endpoint string) []byte {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	secret := make([]byte, 16)
+	rand.Read(secret)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"endpoint": endpoint,
+		"keys": map[string]string{
+			"p256dh": base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			"auth":   base64.RawURLEncoding.EncodeToString(secret),
+		},
+	})
+	require.NoError(t, err)
+	return body
+}
+
+func TestPushHandlers_Disabled(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	handlers := NewPushHandlers(services.NewPushService(store, nil), authService)
+
+	req := httptest.NewRequest("GET", "/api/push/vapid-public-key", nil)
+	w := httptest.NewRecorder()
+	handlers.GetVAPIDPublicKeyHandler(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req = httptest.NewRequest("POST", "/api/push/subscriptions", bytes.NewReader(subscriptionBody(t, "https://push.example.com/1")))
+	for _, cookie := range sessionCookies(t, authService, "test@example.com") {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	handlers.SubscribeHandler(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPushHandlers_Subscriptions(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	pushService := newTestPushService(t, store)
+	handlers := NewPushHandlers(pushService, authService)
+	cookies := sessionCookies(t, authService, "test@example.com")
+
+	// The public key is available without a session
+	req := httptest.NewRequest("GET", "/api/push/vapid-public-key", nil)
+	w := httptest.NewRecorder()
+	handlers.GetVAPIDPublicKeyHandler(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var keyResponse map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &keyResponse))
+	assert.Equal(t, pushService.PublicKey(), keyResponse["publicKey"])
+
+	tests := []struct {
+		name           string
+		body           []byte
+		authenticated  bool
+		expectedStatus int
+	}{
+		{"unauthenticated", subscriptionBody(t, "https://push.example.com/1"), false, http.StatusUnauthorized},
+		{"invalid json", []byte("{"), true, http.StatusBadRequest},
+		{"http endpoint", subscriptionBody(t, "http://push.example.com/1"), true, http.StatusBadRequest},
+		{"valid subscription", subscriptionBody(t, "https://push.example.com/1"), true, http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/push/subscriptions", bytes.NewReader(tt.body))
+			if tt.authenticated {
+				for _, cookie := range cookies {
+					req.AddCookie(cookie)
+				}
+			}
+			w := httptest.NewRecorder()
+			handlers.SubscribeHandler(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	subscriptions, err := pushService.Subscriptions("test@example.com")
+	require.NoError(t, err)
+	assert.Len(t, subscriptions, 1)
+
+	// Unsubscribing removes the subscription, a second attempt finds nothing
+	for _, expected := range []int{http.StatusOK, http.StatusNotFound} {
+		req := httptest.NewRequest("DELETE", "/api/push/subscriptions", bytes.NewReader([]byte(`{"endpoint":"https://push.example.com/1"}`)))
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		handlers.UnsubscribeHandler(w, req)
+		assert.Equal(t, expected, w.Code)
+	}
+}
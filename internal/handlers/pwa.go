@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"watered/internal/i18n"
+	"watered/internal/logger"
+	"watered/internal/render"
+	"watered/internal/respond"
+)
+
+// Colors of the bundled stylesheet's header and page background, so the
+// installed app's title bar and splash screen match the pages
+const (
+	themeColor      = "#ede5de"
+	backgroundColor = "#f8efe7"
+)
+
+// PWAHandlers serves what browsers need to install Watered to a home screen
+// and open it offline
+type PWAHandlers struct {
+	renderer *render.Renderer
+}
+
+// NewPWAHandlers creates a new PWA handlers instance
+func NewPWAHandlers(renderer *render.Renderer) *PWAHandlers {
+	return &PWAHandlers{renderer: renderer}
+}
+
+// webManifestIcon is an icon entry of the web app manifest
+type webManifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes"`
+	Type    string `json:"type"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// webManifest is the web app manifest; see
+// https://www.w3.org/TR/appmanifest/
+type webManifest struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	ShortName       string            `json:"short_name"`
+	Description     string            `json:"description"`
+	Lang            string            `json:"lang"`
+	StartURL        string            `json:"start_url"`
+	Scope           string            `json:"scope"`
+	Display         string            `json:"display"`
+	ThemeColor      string            `json:"theme_color"`
+	BackgroundColor string            `json:"background_color"`
+	Icons           []webManifestIcon `json:"icons"`
+}
+
+// manifestIcons are the bundled icons under web/static/icons. Maskable ones
+// keep the artwork inside the safe zone launchers may crop to.
+var manifestIcons = []webManifestIcon{
+	{Src: "/static/favicon.svg", Sizes: "any", Type: "image/svg+xml"},
+	{Src: "/static/icons/icon-192.png", Sizes: "192x192", Type: "image/png"},
+	{Src: "/static/icons/icon-512.png", Sizes: "512x512", Type: "image/png"},
+	{Src: "/static/icons/icon-maskable-512.png", Sizes: "512x512", Type: "image/png", Purpose: "maskable"},
+}
+
+// GetManifestHandler returns the web app manifest in the request's language
+// GET /manifest.webmanifest
+func (h *PWAHandlers) GetManifestHandler(w http.ResponseWriter, r *http.Request) {
+	printer := i18n.FromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/manifest+json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(webManifest{
+		ID:              "/",
+		Name:            "Watered",
+		ShortName:       "Watered",
+		Description:     printer.T("app.description"),
+		Lang:            printer.Locale(),
+		StartURL:        "/",
+		Scope:           "/",
+		Display:         "standalone",
+		ThemeColor:      themeColor,
+		BackgroundColor: backgroundColor,
+		Icons:           manifestIcons,
+	})
+}
+
+// GetOfflineHandler renders the page the service worker shows when a page
+// can't be loaded. It shows the last plant status the service worker cached.
+// GET /offline
+func (h *PWAHandlers) GetOfflineHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := h.renderer.Render(w, "offline.html", map[string]interface{}{
+		render.I18nKey: i18n.FromContext(r.Context()),
+	}); err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Template error")
+		logger.FromContext(r.Context()).Error("Template error", "error", err)
+	}
+}
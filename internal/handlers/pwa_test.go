@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"watered/internal/i18n"
+	"watered/internal/render"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPWAHandlers() *PWAHandlers {
+	templates := template.Must(template.ParseFiles(filepath.Join("..", "..", "web", "templates", "offline.html")))
+	return NewPWAHandlers(render.NewRenderer(templates, render.DefaultCSPPolicy()))
+}
+
+func TestPWAHandlers_Manifest(t *testing.T) {
+	handler := newTestPWAHandlers()
+
+	rr := httptest.NewRecorder()
+	handler.GetManifestHandler(rr, httptest.NewRequest(http.MethodGet, "/manifest.webmanifest", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/manifest+json", rr.Header().Get("Content-Type"))
+
+	var manifest webManifest
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&manifest))
+	assert.Equal(t, "Watered", manifest.Name)
+	assert.Equal(t, "/", manifest.StartURL)
+	assert.Equal(t, "standalone", manifest.Display)
+	assert.Equal(t, "en", manifest.Lang)
+	assert.Equal(t, "Keeps track of when the plant was last watered", manifest.Description)
+
+	// Installable on Android needs 192px and 512px icons, and every icon
+	// must ship with the static files
+	sizes := map[string]bool{}
+	for _, icon := range manifest.Icons {
+		sizes[icon.Sizes] = true
+		_, err := os.Stat(filepath.Join("..", "..", "web", "static", strings.TrimPrefix(icon.Src, "/static/")))
+		assert.NoError(t, err, "icon %s", icon.Src)
+	}
+	assert.True(t, sizes["192x192"] && sizes["512x512"], "Expected 192px and 512px icons, got %v", sizes)
+
+	req := httptest.NewRequest(http.MethodGet, "/manifest.webmanifest", nil)
+	rr = httptest.NewRecorder()
+	handler.GetManifestHandler(rr, req.WithContext(i18n.WithPrinter(req.Context(), i18n.Get("es"))))
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&manifest))
+	assert.Equal(t, "es", manifest.Lang)
+	assert.Equal(t, "Registra cuándo se regó la planta por última vez", manifest.Description)
+}
+
+func TestPWAHandlers_Offline(t *testing.T) {
+	handler := newTestPWAHandlers()
+
+	req := httptest.NewRequest(http.MethodGet, "/offline", nil)
+	rr := httptest.NewRecorder()
+	handler.GetOfflineHandler(rr, req.WithContext(i18n.WithPrinter(req.Context(), i18n.Get("es"))))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+
+	body := rr.Body.String()
+	assert.Contains(t, body, `<html lang="es">`)
+	assert.Contains(t, body, "Sin conexión")
+	assert.Contains(t, body, `href="/manifest.webmanifest"`)
+	// The inline script runs under the page's CSP nonce
+	assert.Contains(t, body, `<script nonce="`)
+	assert.Contains(t, rr.Header().Get("Content-Security-Policy"), "'nonce-")
+}
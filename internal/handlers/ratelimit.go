@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"watered/internal/logger"
+	"watered/internal/ratelimit"
+	"watered/internal/respond"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RateLimitHandlers contains rate limiting HTTP handlers
+type RateLimitHandlers struct {
+	limiter *ratelimit.Limiter
+	buckets []*ratelimit.BucketLimiter
+	lockout *ratelimit.Lockout
+}
+
+// NewRateLimitHandlers creates a new rate limit handlers instance. A nil
+// limiter means per-window limiting is disabled; nil buckets are skipped.
+func NewRateLimitHandlers(limiter *ratelimit.Limiter, buckets ...*ratelimit.BucketLimiter) *RateLimitHandlers {
+	h := &RateLimitHandlers{limiter: limiter}
+	for _, bucket := range buckets {
+		if bucket != nil {
+			h.buckets = append(h.buckets, bucket)
+		}
+	}
+	return h
+}
+
+// SetLockout sets the failed sign-in lockout to report and reset. A nil
+// lockout means lockouts are disabled.
+func (h *RateLimitHandlers) SetLockout(lockout *ratelimit.Lockout) {
+	h.lockout = lockout
+}
+
+// disabled reports whether no limiter is configured at all
+func (h *RateLimitHandlers) disabled() bool {
+	return h.limiter == nil && len(h.buckets) == 0 && h.lockout == nil
+}
+
+// GetRateLimitStatsHandler reports the limits and which clients have been
+// warned or refused, including by the sign-in and write buckets, and who is
+// locked out after failed sign-ins
+// GET /admin/limits, GET /admin/ratelimit
+func (h *RateLimitHandlers) GetRateLimitStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if h.disabled() {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotConfigured, "Rate limiting is not configured, set RATE_LIMIT to enable it")
+		return
+	}
+
+	var stats ratelimit.Stats
+	if h.limiter != nil {
+		stats = h.limiter.Stats()
+	}
+	for _, bucket := range h.buckets {
+		stats.Buckets = append(stats.Buckets, bucket.Stats())
+	}
+	if h.lockout != nil {
+		lockout := h.lockout.Stats()
+		stats.Lockout = &lockout
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// ResetClientHandler clears a client's counters, refills its buckets and
+// lifts its lockout, so someone refused by mistake can carry on without
+// waiting for the window to reset. The key is the client key from the
+// stats, such as user:alice@example.com or email:alice@example.com.
+// DELETE /admin/limits/{key}
+func (h *RateLimitHandlers) ResetClientHandler(w http.ResponseWriter, r *http.Request) {
+	if h.disabled() {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotConfigured, "Rate limiting is not configured, set RATE_LIMIT to enable it")
+		return
+	}
+
+	key, err := url.PathUnescape(chi.URLParam(r, "key"))
+	if err != nil || key == "" {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Invalid client key")
+		return
+	}
+	known := h.limiter != nil && h.limiter.Reset(key)
+	for _, bucket := range h.buckets {
+		if bucket.Reset(key) {
+			known = true
+		}
+	}
+	if h.lockout != nil && h.lockout.Reset(key) {
+		known = true
+	}
+	if !known {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "No rate limit counters for this client")
+		return
+	}
+	logger.FromContext(r.Context()).Info("Rate limit counters reset", "audit", true, "client", key)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Rate limit counters reset for " + key,
+	})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/ratelimit"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRateLimitStatsHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewRateLimitHandlers(nil).GetRateLimitStatsHandler(rr, httptest.NewRequest(http.MethodGet, "/admin/ratelimit", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	limiter := ratelimit.NewLimiter(config.RateLimitConfig{Limit: 2, Warn: 1, Window: time.Minute})
+	limited := limiter.Middleware(func(r *http.Request) string { return "ip:192.0.2.1" })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 3; i++ {
+		limited.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/plant", nil))
+	}
+
+	rr = httptest.NewRecorder()
+	NewRateLimitHandlers(limiter).GetRateLimitStatsHandler(rr, httptest.NewRequest(http.MethodGet, "/admin/ratelimit", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var stats ratelimit.Stats
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats))
+	assert.Equal(t, 2, stats.Limit)
+	assert.Equal(t, 1, stats.Warned)
+	assert.Equal(t, 1, stats.Rejected)
+	require.Len(t, stats.Clients, 1)
+	assert.Equal(t, "ip:192.0.2.1", stats.Clients[0].Key)
+	assert.Equal(t, 3, stats.Clients[0].Requests)
+	assert.Empty(t, stats.Buckets)
+
+	// Bucket rejections are reported even with per-window limiting disabled
+	buckets := ratelimit.NewBucketLimiter("auth", config.BucketConfig{Burst: 1, Refill: time.Minute})
+	limited = buckets.Middleware(func(r *http.Request) string { return "ip:192.0.2.1" })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 3; i++ {
+		limited.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	}
+
+	rr = httptest.NewRecorder()
+	NewRateLimitHandlers(nil, buckets, nil).GetRateLimitStatsHandler(rr, httptest.NewRequest(http.MethodGet, "/admin/ratelimit", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	stats = ratelimit.Stats{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats))
+	require.Len(t, stats.Buckets, 1)
+	assert.Equal(t, "auth", stats.Buckets[0].Name)
+	assert.Equal(t, 2, stats.Buckets[0].Rejected)
+}
+
+func TestResetClientHandler(t *testing.T) {
+	reset := func(handlers *RateLimitHandlers, path string) *httptest.ResponseRecorder {
+		r := chi.NewRouter()
+		r.Delete("/admin/limits/{key}", handlers.ResetClientHandler)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, path, nil))
+		return rr
+	}
+	assert.Equal(t, http.StatusNotFound, reset(NewRateLimitHandlers(nil), "/admin/limits/ip:192.0.2.1").Code)
+
+	limiter := ratelimit.NewLimiter(config.RateLimitConfig{Limit: 1, Warn: 1, Window: time.Minute})
+	limited := limiter.Middleware(func(r *http.Request) string { return "apikey:Home Assistant" })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 2; i++ {
+		limited.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/plant", nil))
+	}
+	require.True(t, limiter.Stats().Clients[0].Blocked)
+
+	handlers := NewRateLimitHandlers(limiter)
+	assert.Equal(t, http.StatusNotFound, reset(handlers, "/admin/limits/ip:192.0.2.1").Code)
+
+	rr := reset(handlers, "/admin/limits/apikey:Home%20Assistant")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Rate limit counters reset for apikey:Home Assistant")
+	assert.Empty(t, limiter.Stats().Clients)
+
+	rr = httptest.NewRecorder()
+	limited.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/plant", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Resetting a client also refills its buckets
+	buckets := ratelimit.NewBucketLimiter("write", config.BucketConfig{Burst: 1, Refill: time.Minute})
+	limited = buckets.Middleware(func(r *http.Request) string { return "ip:192.0.2.1" })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 2; i++ {
+		limited.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/plant/water", nil))
+	}
+	assert.Equal(t, http.StatusOK, reset(NewRateLimitHandlers(nil, buckets), "/admin/limits/ip:192.0.2.1").Code)
+
+	rr = httptest.NewRecorder()
+	limited.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/plant/water", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
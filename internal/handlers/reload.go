@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"watered/internal/auth"
+	"watered/internal/logger"
+	"watered/internal/reload"
+	"watered/internal/respond"
+)
+
+// SetReloader sets what ReloadConfigHandler reloads the configuration with
+func (h *AdminHandler) SetReloader(reloader *reload.Reloader) {
+	h.reloader = reloader
+}
+
+// ReloadConfigHandler re-reads the environment and .env files and applies
+// the settings that can change without a restart, like SIGHUP does. The
+// response lists the changes applied and those waiting for a restart.
+// POST /admin/config/reload
+func (h *AdminHandler) ReloadConfigHandler(w http.ResponseWriter, r *http.Request) {
+	if h.reloader == nil {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotConfigured, "Configuration reloading is not available")
+		return
+	}
+
+	result, err := h.reloader.Reload(auth.Actor(r))
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("Rejected reloaded configuration", "error", err)
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
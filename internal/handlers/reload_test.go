@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"watered/internal/config"
+	"watered/internal/reload"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler_ReloadConfig(t *testing.T) {
+	handler := newTestAdminHandler(storage.NewMemoryStorage())
+
+	rr := httptest.NewRecorder()
+	handler.ReloadConfigHandler(rr, httptest.NewRequest("POST", "/admin/config/reload", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code, "Expected 404 without a reloader")
+
+	env := map[string]string{"SNOOZE_DURATION": "3h"}
+	load := func() (*config.Config, error) {
+		return config.LoadFrom(func(key string) string { return env[key] })
+	}
+	current, err := load()
+	require.NoError(t, err)
+	reloader := reload.New(current, load)
+	reloader.Handle([]string{"SNOOZE_DURATION"}, func(cfg *config.Config) error { return nil })
+	handler.SetReloader(reloader)
+
+	env["SNOOZE_DURATION"] = "1h"
+	rr = httptest.NewRecorder()
+	handler.ReloadConfigHandler(rr, httptest.NewRequest("POST", "/admin/config/reload", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var result reload.Result
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&result))
+	assert.Equal(t, []config.Change{{Setting: "SNOOZE_DURATION", Old: "3h0m0s", New: "1h0m0s"}}, result.Applied)
+	assert.Empty(t, result.RestartRequired)
+
+	env["SNOOZE_DURATION"] = "soon"
+	rr = httptest.NewRecorder()
+	handler.ReloadConfigHandler(rr, httptest.NewRequest("POST", "/admin/config/reload", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "SNOOZE_DURATION")
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"watered/internal/auth"
+	"watered/internal/logger"
+	"watered/internal/privacy"
+	"watered/internal/respond"
+	"watered/internal/services"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchHandlers contains search HTTP handlers
+type SearchHandlers struct {
+	searchService *services.SearchService
+	plantService  *services.PlantService
+	authService   *auth.AuthService
+}
+
+// NewSearchHandlers creates a new search handlers instance
+func NewSearchHandlers(searchService *services.SearchService, plantService *services.PlantService, authService *auth.AuthService) *SearchHandlers {
+	return &SearchHandlers{
+		searchService: searchService,
+		plantService:  plantService,
+		authService:   authService,
+	}
+}
+
+// parseSearchPage reads limit and offset query parameters
+func parseSearchPage(r *http.Request) (limit, offset int, err error) {
+	query := r.URL.Query()
+	limit = defaultSearchLimit
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return 0, 0, strconv.ErrSyntax
+		}
+		if limit > maxSearchLimit {
+			limit = maxSearchLimit
+		}
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return 0, 0, strconv.ErrSyntax
+		}
+	}
+	return limit, offset, nil
+}
+
+// SearchHandler searches plant names, custom fields, waterers and
+// notification history, matching only what the caller may see: viewers
+// search plant names, members also custom fields, waterers when privacy mode
+// is off and their own notifications, and admins everything
+// GET /api/v1/search?q=
+func (h *SearchHandlers) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parseSearchPage(r)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Invalid limit or offset parameter")
+		return
+	}
+
+	query := services.SearchQuery{
+		Text:   r.URL.Query().Get("q"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	switch h.authService.CallerRole(r) {
+	case privacy.RoleAdmin:
+		query.IncludeMetadata = true
+		query.IncludeWaterers = true
+		query.AllNotifications = true
+	case privacy.RoleMember:
+		query.IncludeMetadata = true
+		query.IncludeWaterers = !h.plantService.WithContext(r.Context()).IsPrivacyModeEnabled()
+		if user, err := h.authService.GetCurrentUser(r); err == nil && user != nil {
+			query.NotificationEmail = user.Email
+		}
+	}
+
+	results, err := h.searchService.Search(query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSearchQuery) {
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+			return
+		}
+		logger.FromContext(r.Context()).Error("Failed to search", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to search")
+		return
+	}
+
+	response := map[string]interface{}{
+		"query":   query.Text,
+		"results": results.Results,
+		"count":   len(results.Results),
+		"total":   results.Total,
+		"limit":   limit,
+		"offset":  offset,
+	}
+	if next := offset + len(results.Results); next < results.Total {
+		response["next_offset"] = next
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchHandlers_SearchHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{AdminEmails: []string{"admin@example.com"}})
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	notificationService := services.NewNotificationService(store)
+	handler := NewSearchHandlers(services.NewSearchService(store), plantService, authService)
+
+	for _, name := range []string{"Fern", "Monstera"} {
+		_, err := plantService.CreatePlant(name, 24)
+		require.NoError(t, err)
+	}
+	plants, err := plantService.ListPlants()
+	require.NoError(t, err)
+	_, err = plantService.WaterPlantByID(plants[len(plants)-1].ID, "member@example.com")
+	require.NoError(t, err)
+	notificationService.Record("member@example.com", "email", models.NotificationTriggerDue, "Monstera is due", nil)
+	notificationService.Record("other@example.com", "email", models.NotificationTriggerDue, "Monstera is due", nil)
+
+	type searchResponse struct {
+		Results    []services.SearchResult `json:"results"`
+		Count      int                     `json:"count"`
+		Total      int                     `json:"total"`
+		NextOffset *int                    `json:"next_offset"`
+	}
+	search := func(target string, email string) (int, searchResponse) {
+		req := httptest.NewRequest("GET", target, nil)
+		if email != "" {
+			for _, cookie := range sessionCookies(t, authService, email) {
+				req.AddCookie(cookie)
+			}
+		}
+		rr := httptest.NewRecorder()
+		handler.SearchHandler(rr, req)
+
+		var response searchResponse
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		}
+		return rr.Code, response
+	}
+
+	// Viewers only match plant names, never waterers or notifications
+	code, response := search("/api/search?q=member", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 0, response.Total)
+
+	code, response = search("/api/search?q=MONSTERA", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, response.Total)
+
+	// Members match waterers and their own notifications
+	code, response = search("/api/search?q=monstera", "member@example.com")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, response.Total)
+	assert.Equal(t, services.SearchResultPlant, response.Results[0].Kind, "name matches rank first")
+
+	code, response = search("/api/search?q=member@", "member@example.com")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, response.Total)
+
+	// Privacy mode hides waterers from members
+	store.UpdateAdminConfig(&models.AdminConfig{AdminEmails: []string{"admin@example.com"}, PrivacyMode: true})
+	code, response = search("/api/search?q=member@", "member@example.com")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, 1, response.Total)
+	assert.Equal(t, services.SearchResultNotification, response.Results[0].Kind)
+
+	// Admins match every notification, one page at a time
+	code, response = search("/api/search?q=monstera&limit=2", "admin@example.com")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 3, response.Total)
+	assert.Equal(t, 2, response.Count)
+	require.NotNil(t, response.NextOffset)
+	assert.Equal(t, 2, *response.NextOffset)
+
+	code, response = search("/api/search?q=monstera&limit=2&offset=2", "admin@example.com")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, response.Count)
+	assert.Nil(t, response.NextOffset)
+
+	for _, target := range []string{"/api/search", "/api/search?q=fern&limit=0", "/api/search?q=fern&offset=-1"} {
+		code, _ = search(target, "")
+		assert.Equal(t, http.StatusBadRequest, code, target)
+	}
+}
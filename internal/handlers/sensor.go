@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/respond"
+	"watered/internal/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DeviceTokenHeader carries a sensor's or button's token. Devices cannot use
+// the Authorization header, which the API reserves for API keys.
+const DeviceTokenHeader = "X-Device-Token"
+
+// maxSensorHours is how far back readings can be requested, the week of
+// readings storage keeps
+const maxSensorHours = 7 * 24
+
+// SensorHandlers receives soil sensor readings and serves them to the dashboard
+type SensorHandlers struct {
+	sensorService *services.SensorService
+	plantService  *services.PlantService
+}
+
+// NewSensorHandlers creates a new sensor handlers instance
+func NewSensorHandlers(sensorService *services.SensorService, plantService *services.PlantService) *SensorHandlers {
+	return &SensorHandlers{
+		sensorService: sensorService,
+		plantService:  plantService,
+	}
+}
+
+// sensorReadingRequest is the body a sensor posts. RecordedAt is optional
+// for devices without a clock.
+type sensorReadingRequest struct {
+	Moisture    *float64  `json:"moisture"`
+	Temperature *float64  `json:"temperature"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+// RecordReadingHandler stores a reading from a soil sensor, authenticated
+// by the device's token
+// POST /api/v1/sensors/{deviceID}/readings
+func (h *SensorHandlers) RecordReadingHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.sensorService.Enabled() {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Sensors are not configured")
+		return
+	}
+
+	var req sensorReadingRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	deviceID := chi.URLParam(r, "deviceID")
+	reading := &models.SensorReading{
+		Moisture:    req.Moisture,
+		Temperature: req.Temperature,
+		RecordedAt:  req.RecordedAt,
+	}
+	err := h.sensorService.Record(deviceID, r.Header.Get(DeviceTokenHeader), reading)
+	switch {
+	case errors.Is(err, services.ErrUnknownSensor), errors.Is(err, services.ErrInvalidSensorToken):
+		logger.FromContext(r.Context()).Warn("Rejected sensor reading", "audit", true, "device", deviceID, "remote_addr", r.RemoteAddr)
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Unauthorized")
+		return
+	case errors.Is(err, services.ErrDeviceInactive):
+		logger.FromContext(r.Context()).Warn("Rejected reading from inactive sensor", "audit", true, "device", deviceID, "remote_addr", r.RemoteAddr)
+		respond.Error(w, http.StatusForbidden, respond.CodeForbidden, "Device is inactive")
+		return
+	case errors.Is(err, services.ErrInvalidSensorReading):
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+		return
+	case err != nil:
+		logger.FromContext(r.Context()).Error("Failed to record sensor reading", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to record sensor reading")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(reading)
+}
+
+// GetPlantSensorsHandler returns a plant's recent sensor readings, newest
+// first, for the last ?hours (24 by default)
+// GET /api/v1/plant/sensors, GET /api/v1/plants/{id}/sensors
+func (h *SensorHandlers) GetPlantSensorsHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	hours := 24
+	if value := r.URL.Query().Get("hours"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxSensorHours {
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "hours must be between 1 and "+strconv.Itoa(maxSensorHours))
+			return
+		}
+		hours = parsed
+	}
+
+	if _, err := h.plantService.WithContext(r.Context()).GetPlantByID(id); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to get plant", "error", err)
+		writePlantError(w, err, http.StatusInternalServerError, respond.CodeInternal, "Failed to get plant")
+		return
+	}
+	readings, err := h.sensorService.Recent(id, time.Duration(hours)*time.Hour)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list sensor readings", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to list sensor readings")
+		return
+	}
+
+	var latest *models.SensorReading
+	if len(readings) > 0 {
+		latest = readings[0]
+	}
+	response := map[string]interface{}{
+		"plant_id": id,
+		"hours":    hours,
+		"latest":   latest,
+		"readings": readings,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
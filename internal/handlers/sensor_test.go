@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"watered/internal/config"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSensorRouter(store storage.Storage, cfg config.SensorConfig) chi.Router {
+	plantService := services.NewPlantService(store)
+	handlers := NewSensorHandlers(services.NewSensorService(store, plantService, services.NewDeviceService(store, plantService, cfg), cfg), plantService)
+
+	r := chi.NewRouter()
+	r.Post("/api/sensors/{deviceID}/readings", handlers.RecordReadingHandler)
+	r.Get("/api/plant/sensors", handlers.GetPlantSensorsHandler)
+	r.Get("/api/plants/{id}/sensors", handlers.GetPlantSensorsHandler)
+	return r
+}
+
+func TestSensorHandlers_RecordReadingHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	r := newTestSensorRouter(store, config.SensorConfig{Devices: map[string]config.SensorDevice{
+		"kitchen": {PlantID: 1, Token: "kitchen-token"},
+	}})
+
+	post := func(device, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/sensors/"+device+"/readings", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set(DeviceTokenHeader, token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("kitchen", "", `{"moisture": 40}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, post("kitchen", "wrong-token", `{"moisture": 40}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, post("balcony", "kitchen-token", `{"moisture": 40}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post("kitchen", "kitchen-token", `{"moisture": 140}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post("kitchen", "kitchen-token", `moisture=40`).Code)
+
+	w := post("kitchen", "kitchen-token", `{"moisture": 38.5, "temperature": 19}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var reading map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reading))
+	assert.Equal(t, "kitchen", reading["device_id"])
+	assert.Equal(t, float64(1), reading["plant_id"])
+	assert.Equal(t, 38.5, reading["moisture"])
+	assert.NotEmpty(t, reading["recorded_at"])
+}
+
+func TestSensorHandlers_RecordReadingHandler_Disabled(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	r := newTestSensorRouter(store, config.SensorConfig{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/sensors/kitchen/readings", strings.NewReader(`{"moisture": 40}`)))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSensorHandlers_GetPlantSensorsHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	r := newTestSensorRouter(store, config.SensorConfig{Devices: map[string]config.SensorDevice{
+		"kitchen": {PlantID: 1, Token: "kitchen-token"},
+	}})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/api/plant/sensors")
+	require.Equal(t, http.StatusOK, w.Code)
+	var empty map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &empty))
+	assert.Nil(t, empty["latest"])
+	assert.Empty(t, empty["readings"])
+
+	for _, body := range []string{`{"moisture": 45}`, `{"moisture": 41}`} {
+		req := httptest.NewRequest("POST", "/api/sensors/kitchen/readings", strings.NewReader(body))
+		req.Header.Set(DeviceTokenHeader, "kitchen-token")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w = get("/api/plants/1/sensors?hours=2")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(1), response["plant_id"])
+	assert.Equal(t, float64(2), response["hours"])
+	assert.Len(t, response["readings"], 2)
+	assert.Equal(t, 41.0, response["latest"].(map[string]interface{})["moisture"])
+
+	assert.Equal(t, http.StatusNotFound, get("/api/plants/42/sensors").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/plant/sensors?hours=0").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/plant/sensors?hours=1000").Code)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"watered/internal/auth"
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/respond"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SessionHandlers contains session management HTTP handlers
+type SessionHandlers struct {
+	auditor
+
+	authService *auth.AuthService
+}
+
+// NewSessionHandlers creates a new session handlers instance
+func NewSessionHandlers(authService *auth.AuthService) *SessionHandlers {
+	return &SessionHandlers{
+		authService: authService,
+	}
+}
+
+// ListSessionsHandler returns the signed-in sessions that have not expired.
+// current_id is the caller's own session.
+// GET /admin/sessions
+func (h *SessionHandlers) ListSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.authService.ListSessions()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list sessions", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to list sessions")
+		return
+	}
+
+	response := map[string]interface{}{
+		"sessions":   sessions,
+		"count":      len(sessions),
+		"current_id": h.authService.CurrentSessionID(r),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// RevokeSessionHandler signs out one session
+// DELETE /admin/sessions/{id}
+func (h *SessionHandlers) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	revoked, err := h.authService.RevokeSession(id, user.Email)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to revoke session", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to revoke session")
+		return
+	}
+	if !revoked {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Session not found")
+		return
+	}
+	h.audit(r, models.AuditSessionRevoke, id, nil, nil)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Session revoked",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// RevokeUserSessionsHandler signs a user out on every device. It does not
+// remove them from the allowlist, so they can sign in again.
+// DELETE /admin/users/{email}/sessions
+func (h *SessionHandlers) RevokeUserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	email := strings.TrimSpace(chi.URLParam(r, "email"))
+	if email == "" {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Email parameter is required")
+		return
+	}
+
+	revoked, err := h.authService.RevokeUserSessions(email, user.Email)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to revoke user sessions", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to revoke user sessions")
+		return
+	}
+	h.audit(r, models.AuditUserSignOut, email, nil, map[string]int{"revoked": revoked})
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "User signed out everywhere",
+		"revoked": revoked,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
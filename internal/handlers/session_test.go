@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionHandlers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{AdminEmails: []string{"admin@example.com"}})
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	handler := NewSessionHandlers(authService)
+	adminCookies := sessionCookies(t, authService, "admin@example.com")
+	userCookies := sessionCookies(t, authService, "test@example.com")
+	sessionCookies(t, authService, "test@example.com")
+
+	router := chi.NewRouter()
+	router.Get("/admin/sessions", handler.ListSessionsHandler)
+	router.Delete("/admin/sessions/{id}", handler.RevokeSessionHandler)
+	router.Delete("/admin/users/{email}/sessions", handler.RevokeUserSessionsHandler)
+
+	do := func(method, path string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	signedIn := func(cookies []*http.Cookie) bool {
+		req := httptest.NewRequest("GET", "/", nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		return authService.IsAuthenticated(req)
+	}
+
+	// List
+	rr := do("GET", "/admin/sessions", adminCookies)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "csrf_token")
+
+	var listed struct {
+		Sessions  []map[string]interface{} `json:"sessions"`
+		Count     int                      `json:"count"`
+		CurrentID string                   `json:"current_id"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &listed))
+	require.Equal(t, 3, listed.Count)
+	assert.NotEmpty(t, listed.CurrentID)
+
+	var userSessionID string
+	for _, session := range listed.Sessions {
+		if session["email"] == "test@example.com" {
+			userSessionID = session["id"].(string)
+			break
+		}
+	}
+	require.NotEmpty(t, userSessionID)
+
+	// Revoke one session
+	assert.Equal(t, http.StatusOK, do("DELETE", "/admin/sessions/"+userSessionID, adminCookies).Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/admin/sessions/"+userSessionID, adminCookies).Code)
+
+	// Force logout a user everywhere
+	rr = do("DELETE", "/admin/users/test@example.com/sessions", adminCookies)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var revoked struct {
+		Revoked int `json:"revoked"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &revoked))
+	assert.Equal(t, 1, revoked.Revoked)
+	assert.False(t, signedIn(userCookies))
+	assert.True(t, signedIn(adminCookies))
+
+	// Revoked sessions cannot call admin endpoints
+	assert.Equal(t, http.StatusUnauthorized, do("DELETE", "/admin/sessions/"+listed.CurrentID, userCookies).Code)
+}
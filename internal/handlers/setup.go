@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"watered/internal/auth"
+	"watered/internal/logger"
+	"watered/internal/respond"
+	"watered/internal/services"
+)
+
+// SetupHandlers contains the first-run setup wizard HTTP handlers
+type SetupHandlers struct {
+	setupService *services.SetupService
+	authService  *auth.AuthService
+}
+
+// NewSetupHandlers creates a new setup handlers instance
+func NewSetupHandlers(setupService *services.SetupService, authService *auth.AuthService) *SetupHandlers {
+	return &SetupHandlers{
+		setupService: setupService,
+		authService:  authService,
+	}
+}
+
+// GetSetupStatusHandler reports whether the instance still needs first-run setup
+// GET /setup/status
+func (h *SetupHandlers) GetSetupStatusHandler(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"setupRequired": h.setupService.IsSetupRequired(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CompleteSetupHandler applies the initial configuration
+// POST /setup
+func (h *SetupHandlers) CompleteSetupHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.setupService.IsSetupRequired() {
+		respond.Error(w, http.StatusGone, respond.CodeGone, "Setup has already been completed")
+		return
+	}
+
+	if !h.setupService.ValidateToken(r.Header.Get("X-Setup-Token")) {
+		logger.FromContext(r.Context()).Warn("Rejected setup attempt with invalid bootstrap token", "remote_addr", r.RemoteAddr)
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Invalid setup token")
+		return
+	}
+
+	var req services.SetupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	config, err := h.setupService.CompleteSetup(req)
+	if err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Setup failed: "+err.Error())
+		return
+	}
+
+	if config.GoogleClientID != "" {
+		h.authService.SetOAuthCredentials(config.GoogleClientID, config.GoogleClientSecret)
+	}
+
+	response := map[string]interface{}{
+		"success":    true,
+		"message":    "Setup completed. Sign in as " + config.AdminEmails[0] + " to continue.",
+		"adminEmail": config.AdminEmails[0],
+		"timezone":   config.Timezone,
+		"oauth":      config.GoogleClientID != "",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupHandlers_CompleteSetupHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	setupService := services.NewSetupService(store, config.AuthConfig{})
+	handlers := NewSetupHandlers(setupService, authService)
+
+	body := `{"adminEmail":"owner@example.com","timezone":"UTC","googleClientId":"real-id","googleClientSecret":"real-secret"}`
+
+	// Status reports setup is required
+	w := httptest.NewRecorder()
+	handlers.GetSetupStatusHandler(w, httptest.NewRequest("GET", "/setup/status", nil))
+	assert.Contains(t, w.Body.String(), `"setupRequired":true`)
+
+	// Wrong token is rejected
+	req := httptest.NewRequest("POST", "/setup", strings.NewReader(body))
+	req.Header.Set("X-Setup-Token", "wrong")
+	w = httptest.NewRecorder()
+	handlers.CompleteSetupHandler(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Correct token completes setup
+	req = httptest.NewRequest("POST", "/setup", strings.NewReader(body))
+	req.Header.Set("X-Setup-Token", setupService.BootstrapToken())
+	w = httptest.NewRecorder()
+	handlers.CompleteSetupHandler(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "owner@example.com", response["adminEmail"])
+
+	// Setup cannot be run twice
+	req = httptest.NewRequest("POST", "/setup", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	handlers.CompleteSetupHandler(w, req)
+	assert.Equal(t, http.StatusGone, w.Code)
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"watered/internal/auth"
+	"watered/internal/i18n"
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/render"
+	"watered/internal/respond"
+	"watered/internal/services"
+	"watered/internal/validate"
+)
+
+// ShareHandlers serves read-only share links and their management
+type ShareHandlers struct {
+	auditor
+
+	shareService *services.ShareService
+	authService  *auth.AuthService
+	renderer     *render.Renderer
+}
+
+// NewShareHandlers creates a new share handlers instance
+func NewShareHandlers(shareService *services.ShareService, authService *auth.AuthService, renderer *render.Renderer) *ShareHandlers {
+	return &ShareHandlers{
+		shareService: shareService,
+		authService:  authService,
+		renderer:     renderer,
+	}
+}
+
+// GetShareHandler shows the status of the plant a share link points to,
+// without signing in. Browsers get a page and API clients JSON.
+// GET /share/{token}
+func (h *ShareHandlers) GetShareHandler(w http.ResponseWriter, r *http.Request) {
+	// The token is the credential, so keep it out of caches, search engines
+	// and the Referer of links followed from the page
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	status, err := h.shareService.Status(chi.URLParam(r, "token"))
+	if err != nil && !errors.Is(err, services.ErrInvalidShareToken) {
+		logger.FromContext(r.Context()).Error("Failed to get shared plant status", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to get plant status")
+		return
+	}
+
+	printer := i18n.FromContext(r.Context())
+	if status != nil {
+		status.TimeSinceWateringFormatted = models.FormatTimeSince(printer, status.LastWatered)
+	}
+
+	if wantsJSON(r) {
+		if status == nil {
+			respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "This share link is invalid or has been revoked")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+		return
+	}
+
+	code := http.StatusOK
+	if status == nil {
+		code = http.StatusNotFound
+	}
+	if err := h.renderer.RenderStatus(w, code, "share.html", map[string]interface{}{
+		"Plant":        status,
+		render.I18nKey: printer,
+	}); err != nil {
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Template error")
+		logger.FromContext(r.Context()).Error("Template error", "error", err)
+	}
+}
+
+// CalendarHandler returns a plant's waterings and watering schedule as an
+// iCalendar feed for calendar apps to subscribe to. Calendar apps cannot
+// sign in, so a share link token for the plant is required instead.
+// GET /api/v1/plant/calendar.ics?token=ws_..., GET /api/v1/plants/{id}/calendar.ics?token=ws_...
+func (h *ShareHandlers) CalendarHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := plantIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	calendar, err := h.shareService.Calendar(r.URL.Query().Get("token"), id)
+	if errors.Is(err, services.ErrInvalidShareToken) {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "This share link is invalid or has been revoked")
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to build watering calendar", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to get calendar")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="watering.ics"`)
+	if err := calendar.Encode(w, time.Now()); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to write watering calendar", "error", err)
+	}
+}
+
+// ListSharesHandler returns all share links without their tokens
+// GET /admin/shares
+func (h *ShareHandlers) ListSharesHandler(w http.ResponseWriter, r *http.Request) {
+	links, err := h.shareService.List()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list share links", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to list share links")
+		return
+	}
+
+	response := map[string]interface{}{
+		"shares": links,
+		"count":  len(links),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateShareHandler issues a share link to a plant's status. The token is
+// only returned in this response.
+// POST /admin/shares
+func (h *ShareHandlers) CreateShareHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	var req struct {
+		Name    string `json:"name" validate:"required,max=64"`
+		PlantID *int   `json:"plant_id"`
+	}
+	if !validate.DecodeJSON(w, r, &req) {
+		return
+	}
+	plantID := models.DefaultPlantID
+	if req.PlantID != nil {
+		plantID = *req.PlantID
+	}
+
+	link, token, err := h.shareService.Create(req.Name, plantID, user.Email)
+	if errors.Is(err, services.ErrInvalidShareLink) {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to create share link", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to create share link")
+		return
+	}
+	link.TokenHash = ""
+	h.audit(r, models.AuditShareCreate, link.ID, nil, link)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Share link created. Copy it now, it will not be shown again.",
+		"token":   token,
+		"url":     "/share/" + token,
+		"share":   link,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// RevokeShareHandler deletes a share link so it stops working
+// DELETE /admin/shares/{id}
+func (h *ShareHandlers) RevokeShareHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.authService.GetCurrentUser(r)
+	if err != nil || user == nil {
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	link, err := h.shareService.Revoke(chi.URLParam(r, "id"), user.Email)
+	if errors.Is(err, services.ErrShareLinkNotFound) {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Share link not found")
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to revoke share link", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to revoke share link")
+		return
+	}
+	h.audit(r, models.AuditShareRevoke, link.ID, link, nil)
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Share link revoked",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/render"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareHandlers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{AdminEmails: []string{"admin@example.com"}})
+
+	authService := auth.NewAuthService(store, config.AuthConfig{})
+	plantService := services.NewPlantService(store)
+	templates := template.Must(template.ParseFiles(filepath.Join("..", "..", "web", "templates", "share.html")))
+	handler := NewShareHandlers(services.NewShareService(store, plantService), authService, render.NewRenderer(templates, nil))
+	handler.SetAuditService(services.NewAuditService(store))
+	cookies := sessionCookies(t, authService, "admin@example.com")
+
+	router := chi.NewRouter()
+	router.Get("/share/{token}", handler.GetShareHandler)
+	router.Get("/admin/shares", handler.ListSharesHandler)
+	router.Post("/admin/shares", handler.CreateShareHandler)
+	router.Delete("/admin/shares/{id}", handler.RevokeShareHandler)
+
+	do := func(method, path, body, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	_, err := plantService.WaterPlant("alice@example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/admin/shares", `{}`, "").Code)
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/admin/shares", `{"name":"fern","plant_id":42}`, "").Code)
+
+	rr := do("POST", "/admin/shares", `{"name":"grandma"}`, "")
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var created struct {
+		Token string            `json:"token"`
+		URL   string            `json:"url"`
+		Share *models.ShareLink `json:"share"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.Equal(t, "/share/"+created.Token, created.URL)
+	assert.Empty(t, created.Share.TokenHash)
+
+	// The list never shows the token or its hash
+	rr = do("GET", "/admin/shares", "", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"count":1`)
+	assert.Contains(t, rr.Body.String(), `"name":"grandma"`)
+	assert.NotContains(t, rr.Body.String(), created.Token)
+	assert.NotContains(t, rr.Body.String(), "token_hash")
+
+	t.Run("json", func(t *testing.T) {
+		rr := do("GET", created.URL, "", "application/json")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+		assert.Equal(t, "no-referrer", rr.Header().Get("Referrer-Policy"))
+		assert.Contains(t, rr.Body.String(), `"status":"healthy"`)
+		assert.NotContains(t, rr.Body.String(), "alice", "share links must not reveal who watered the plant")
+	})
+
+	t.Run("html", func(t *testing.T) {
+		rr := do("GET", created.URL, "", "text/html")
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, rr.Body.String(), "Healthy")
+		assert.NotContains(t, rr.Body.String(), "<script")
+	})
+
+	rr = do("DELETE", "/admin/shares/"+created.Share.ID, "", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/admin/shares/"+created.Share.ID, "", "").Code)
+
+	t.Run("revoked", func(t *testing.T) {
+		rr := do("GET", created.URL, "", "application/json")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = do("GET", created.URL, "", "text/html")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Contains(t, rr.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, rr.Body.String(), "invalid or has been revoked")
+	})
+
+	entries, err := store.ListAuditEntries(models.AuditFilter{})
+	require.NoError(t, err)
+	actions := make([]string, len(entries))
+	for i, entry := range entries {
+		actions[i] = entry.Action
+	}
+	assert.Equal(t, []string{models.AuditShareRevoke, models.AuditShareCreate}, actions)
+}
+
+func TestShareHandlers_Calendar(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	plantService := services.NewPlantService(store)
+	shareService := services.NewShareService(store, plantService)
+	handler := NewShareHandlers(shareService, auth.NewAuthService(store, config.AuthConfig{}), nil)
+	_, token, err := shareService.Create("calendar", models.DefaultPlantID, "admin@example.com")
+	require.NoError(t, err)
+	_, err = plantService.WaterPlant("alice@example.com")
+	require.NoError(t, err)
+
+	router := chi.NewRouter()
+	router.Get("/api/plant/calendar.ics", handler.CalendarHandler)
+	router.Get("/api/plants/{id}/calendar.ics", handler.CalendarHandler)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	rr := get("/api/plant/calendar.ics?token=" + token)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	assert.Contains(t, rr.Body.String(), "BEGIN:VCALENDAR\r\n")
+	assert.Equal(t, 2, strings.Count(rr.Body.String(), "BEGIN:VEVENT"))
+	assert.NotContains(t, rr.Body.String(), "alice")
+	assert.Equal(t, http.StatusOK, get("/api/plants/1/calendar.ics?token="+token).Code)
+
+	assert.Equal(t, http.StatusNotFound, get("/api/plant/calendar.ics").Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/plant/calendar.ics?token=ws_bogus_token").Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/plants/2/calendar.ics?token="+token).Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/plants/abc/calendar.ics?token="+token).Code)
+}
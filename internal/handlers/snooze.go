@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"watered/internal/logger"
+	"watered/internal/respond"
+	"watered/internal/services"
+)
+
+// SnoozeHandlers serves the snooze links carried by reminders
+type SnoozeHandlers struct {
+	snoozeService *services.SnoozeService
+	plantService  *services.PlantService
+}
+
+// NewSnoozeHandlers creates a new snooze handlers instance
+func NewSnoozeHandlers(snoozeService *services.SnoozeService, plantService *services.PlantService) *SnoozeHandlers {
+	return &SnoozeHandlers{
+		snoozeService: snoozeService,
+		plantService:  plantService,
+	}
+}
+
+// SnoozeHandler silences reminders about a plant for the user a snooze link
+// was sent to. The signed token is the only credential, so the link works
+// from an email client or a push notification without a session.
+// GET /api/v1/snooze?token=...
+func (h *SnoozeHandlers) SnoozeHandler(w http.ResponseWriter, r *http.Request) {
+	snooze, err := h.snoozeService.Snooze(r.URL.Query().Get("token"))
+	switch {
+	case errors.Is(err, services.ErrInvalidSnoozeToken):
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "This snooze link is invalid or has expired")
+		return
+	case errors.Is(err, services.ErrPlantNotFound):
+		respond.Error(w, http.StatusNotFound, respond.CodeNotFound, "Plant not found")
+		return
+	case err != nil:
+		logger.FromContext(r.Context()).Error("Failed to snooze reminders", "error", err)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to snooze reminders")
+		return
+	}
+
+	until := snooze.Until.In(h.plantService.Location())
+	response := map[string]interface{}{
+		"success":  true,
+		"message":  fmt.Sprintf("Reminders about %s are snoozed until %s", snooze.Plant.Name, until.Format("Mon 3:04 PM")),
+		"plant_id": snooze.Plant.ID,
+		"until":    until,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnoozeHandlers_SnoozeHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	plantService := services.NewPlantService(store)
+	snoozeService := services.NewSnoozeService(store, plantService, []byte("test-key"), 3*time.Hour, "http://localhost:8080")
+	handlers := NewSnoozeHandlers(snoozeService, plantService)
+
+	snooze := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/snooze?token="+url.QueryEscape(token), nil)
+		w := httptest.NewRecorder()
+		handlers.SnoozeHandler(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, snooze("").Code)
+	assert.Equal(t, http.StatusBadRequest, snooze("forged.token").Code)
+	assert.Equal(t, http.StatusNotFound, snooze(snoozeService.Token(42, "a@example.com", services.EmailChannel)).Code)
+
+	w := snooze(snoozeService.Token(models.DefaultPlantID, "a@example.com", services.EmailChannel))
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, true, response["success"])
+	assert.Contains(t, response["message"], "Reminders about Our Plant are snoozed until")
+	assert.True(t, snoozeService.Snoozed(models.DefaultPlantID, "a@example.com"))
+	assert.False(t, snoozeService.Snoozed(models.DefaultPlantID, "b@example.com"))
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"watered/internal/respond"
+	"watered/internal/update"
 )
 
 var serverStartTime = time.Now()
@@ -51,7 +54,7 @@ func GetStatus(w http.ResponseWriter, r *http.Request) {
 	response := StatusResponse{
 		Status:          "ok",
 		Service:         "watered-api",
-		Version:         "1.0.0",
+		Version:         update.Version,
 		Timestamp:       now,
 		UptimeSeconds:   uptime.Seconds(),
 		UptimeFormatted: formatUptime(uptime),
@@ -61,7 +64,7 @@ func GetStatus(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to encode response")
 		return
 	}
 }
@@ -6,6 +6,8 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"watered/internal/update"
 )
 
 func TestGetStatus(t *testing.T) {
@@ -43,8 +45,8 @@ func TestGetStatus(t *testing.T) {
 		t.Errorf("Expected service 'watered-api', got '%s'", response.Service)
 	}
 
-	if response.Version != "1.0.0" {
-		t.Errorf("Expected version '1.0.0', got '%s'", response.Version)
+	if response.Version != update.Version {
+		t.Errorf("Expected version '%s', got '%s'", update.Version, response.Version)
 	}
 
 	// Check uptime fields
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	"watered/internal/auth"
+	"watered/internal/logger"
+	"watered/internal/notify/telegram"
+	"watered/internal/respond"
+	"watered/internal/services"
+)
+
+// TelegramHandlers receives updates from the Telegram bot webhook
+type TelegramHandlers struct {
+	telegramService *services.TelegramService
+	authService     *auth.AuthService
+	webhookSecret   string
+}
+
+// NewTelegramHandlers creates a new Telegram handlers instance. Updates must
+// carry webhookSecret in the secret token header.
+func NewTelegramHandlers(telegramService *services.TelegramService, authService *auth.AuthService, webhookSecret string) *TelegramHandlers {
+	return &TelegramHandlers{
+		telegramService: telegramService,
+		authService:     authService,
+		webhookSecret:   webhookSecret,
+	}
+}
+
+// WebhookHandler runs the command in an update and answers it in the
+// response body. Updates that cannot be handled are still acknowledged, so
+// Telegram does not redeliver them.
+// POST /api/v1/telegram/webhook
+func (h *TelegramHandlers) WebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.telegramService.Enabled() {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotConfigured, "Telegram is not configured")
+		return
+	}
+	provided := r.Header.Get(telegram.SecretHeader)
+	if h.webhookSecret == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(h.webhookSecret)) != 1 {
+		logger.FromContext(r.Context()).Warn("Rejected Telegram update without the webhook secret", "audit", true, "remote_addr", r.RemoteAddr)
+		respond.Error(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var update telegram.Update
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&update); err != nil {
+		respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "Invalid update")
+		return
+	}
+	if update.Message == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	reply := h.telegramService.HandleMessage(update.Message, h.authService.CanWater)
+	if reply == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(telegram.NewReply(update.Message.Chat.ID, reply))
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"watered/internal/auth"
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/notify/telegram"
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// telegramSenderFunc adapts a function to the services.TelegramSender interface
+type telegramSenderFunc func(ctx context.Context, chatID int64, text string) error
+
+func (f telegramSenderFunc) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return f(ctx, chatID, text)
+}
+
+func TestTelegramHandlers_WebhookHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	authService := auth.NewAuthService(store, config.AuthConfig{AllowedEmails: []string{"alice@example.com"}})
+	telegramConfig := config.TelegramConfig{ChatID: -100123, Users: map[int64]string{111: "alice@example.com"}}
+	sender := telegramSenderFunc(func(ctx context.Context, chatID int64, text string) error { return nil })
+	telegramService := services.NewTelegramService(store, services.NewPlantService(store), sender, telegramConfig)
+	handlers := NewTelegramHandlers(telegramService, authService, "hook-secret")
+
+	post := func(secret, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/telegram/webhook", strings.NewReader(body))
+		if secret != "" {
+			req.Header.Set(telegram.SecretHeader, secret)
+		}
+		w := httptest.NewRecorder()
+		handlers.WebhookHandler(w, req)
+		return w
+	}
+	update := `{"update_id":1,"message":{"message_id":7,"from":{"id":111},"chat":{"id":-100123},"text":"/watered"}}`
+
+	assert.Equal(t, http.StatusUnauthorized, post("", update).Code)
+	assert.Equal(t, http.StatusUnauthorized, post("wrong", update).Code)
+	assert.Equal(t, http.StatusBadRequest, post("hook-secret", "not json").Code)
+
+	// Updates without a command are acknowledged without a reply
+	w := post("hook-secret", `{"update_id":2,"edited_message":{}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = post("hook-secret", update)
+	require.Equal(t, http.StatusOK, w.Code)
+	var reply telegram.Reply
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reply))
+	assert.Equal(t, telegram.NewReply(-100123, "💧 Our Plant is watered. Thanks!"), reply)
+
+	plant, err := store.GetPlant(models.DefaultPlantID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", plant.WateredBy)
+
+	// Without a bot the webhook does not exist
+	disabled := NewTelegramHandlers(services.NewTelegramService(store, services.NewPlantService(store), nil, telegramConfig), authService, "hook-secret")
+	req := httptest.NewRequest("POST", "/api/telegram/webhook", strings.NewReader(update))
+	req.Header.Set(telegram.SecretHeader, "hook-secret")
+	w = httptest.NewRecorder()
+	disabled.WebhookHandler(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
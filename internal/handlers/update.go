@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"watered/internal/logger"
+	"watered/internal/respond"
+	"watered/internal/update"
+)
+
+// updateInstallTimeout bounds downloading and installing a release
+const updateInstallTimeout = 10 * time.Minute
+
+// Updater checks for and installs signed server releases
+type Updater interface {
+	Check(ctx context.Context) (*update.Status, error)
+	Update(ctx context.Context) (*update.Release, error)
+}
+
+// UpdateHandlers contains self-update HTTP handlers
+type UpdateHandlers struct {
+	updater Updater
+	restart func()
+}
+
+// NewUpdateHandlers creates a new update handlers instance. A nil updater
+// means self-update is not configured; restart is called after a release
+// has been installed.
+func NewUpdateHandlers(updater Updater, restart func()) *UpdateHandlers {
+	return &UpdateHandlers{
+		updater: updater,
+		restart: restart,
+	}
+}
+
+// GetUpdateStatusHandler reports the running version and the latest release
+// GET /admin/update
+func (h *UpdateHandlers) GetUpdateStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if h.updater == nil {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotConfigured, "Self-update is not configured, set UPDATE_PUBLIC_KEY to enable it")
+		return
+	}
+
+	status, err := h.updater.Check(r.Context())
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Update check failed", "error", err)
+		respond.Error(w, http.StatusBadGateway, respond.CodeUpstream, fmt.Sprintf("Update check failed: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// ApplyUpdateHandler installs the latest release in the background and
+// restarts the server once it is in place
+// POST /admin/update
+func (h *UpdateHandlers) ApplyUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	if h.updater == nil {
+		respond.Error(w, http.StatusNotFound, respond.CodeNotConfigured, "Self-update is not configured, set UPDATE_PUBLIC_KEY to enable it")
+		return
+	}
+
+	status, err := h.updater.Check(r.Context())
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Update check failed", "error", err)
+		respond.Error(w, http.StatusBadGateway, respond.CodeUpstream, fmt.Sprintf("Update check failed: %v", err))
+		return
+	}
+
+	if status.Installing {
+		respond.Error(w, http.StatusConflict, respond.CodeConflict, update.ErrUpdateInProgress.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if !status.UpdateAvailable {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"updated": false,
+			"message": fmt.Sprintf("Already running the latest version (%s)", status.CurrentVersion),
+		})
+		return
+	}
+
+	// Downloads can outlast the request, so install detached from it
+	reqLogger := logger.FromContext(r.Context())
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), updateInstallTimeout)
+		defer cancel()
+
+		release, err := h.updater.Update(ctx)
+		if err != nil {
+			if !errors.Is(err, update.ErrUpdateInProgress) {
+				reqLogger.Error("Update failed", "error", err)
+			}
+			return
+		}
+		if release != nil {
+			reqLogger.Info("Installed release, restarting", "audit", true, "version", release.Version)
+			h.restart()
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"updated": true,
+		"version": status.LatestVersion,
+		"message": fmt.Sprintf("Installing %s, the server will restart when it is done", status.LatestVersion),
+	})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"watered/internal/update"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUpdater reports a fixed status and signals when Update runs
+type fakeUpdater struct {
+	status   *update.Status
+	checkErr error
+	updated  chan struct{}
+}
+
+func (f *fakeUpdater) Check(ctx context.Context) (*update.Status, error) {
+	return f.status, f.checkErr
+}
+
+func (f *fakeUpdater) Update(ctx context.Context) (*update.Release, error) {
+	defer close(f.updated)
+	return f.status.Release, nil
+}
+
+func TestUpdateHandlers_NotConfigured(t *testing.T) {
+	handlers := NewUpdateHandlers(nil, func() {})
+
+	for _, handler := range []http.HandlerFunc{handlers.GetUpdateStatusHandler, handlers.ApplyUpdateHandler} {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest(http.MethodGet, "/admin/update", nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestGetUpdateStatusHandler(t *testing.T) {
+	updater := &fakeUpdater{status: &update.Status{CurrentVersion: "v1.0.0", LatestVersion: "v1.1.0", UpdateAvailable: true}}
+	handlers := NewUpdateHandlers(updater, func() {})
+
+	rr := httptest.NewRecorder()
+	handlers.GetUpdateStatusHandler(rr, httptest.NewRequest(http.MethodGet, "/admin/update", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "v1.1.0", response["latest_version"])
+	assert.Equal(t, true, response["update_available"])
+
+	updater.checkErr = errors.New("GitHub returned 503")
+	rr = httptest.NewRecorder()
+	handlers.GetUpdateStatusHandler(rr, httptest.NewRequest(http.MethodGet, "/admin/update", nil))
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+}
+
+func TestApplyUpdateHandler(t *testing.T) {
+	t.Run("up to date", func(t *testing.T) {
+		updater := &fakeUpdater{status: &update.Status{CurrentVersion: "v1.1.0", LatestVersion: "v1.1.0"}}
+		handlers := NewUpdateHandlers(updater, func() { t.Error("Unexpected restart") })
+
+		rr := httptest.NewRecorder()
+		handlers.ApplyUpdateHandler(rr, httptest.NewRequest(http.MethodPost, "/admin/update", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Already running the latest version")
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		updater := &fakeUpdater{status: &update.Status{UpdateAvailable: true, Installing: true}}
+		handlers := NewUpdateHandlers(updater, func() {})
+
+		rr := httptest.NewRecorder()
+		handlers.ApplyUpdateHandler(rr, httptest.NewRequest(http.MethodPost, "/admin/update", nil))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("installs and restarts", func(t *testing.T) {
+		release := &update.Release{Version: "v1.2.0"}
+		updater := &fakeUpdater{
+			status:  &update.Status{CurrentVersion: "v1.1.0", LatestVersion: "v1.2.0", UpdateAvailable: true, Release: release},
+			updated: make(chan struct{}),
+		}
+		restarted := make(chan struct{})
+		handlers := NewUpdateHandlers(updater, func() { close(restarted) })
+
+		rr := httptest.NewRecorder()
+		handlers.ApplyUpdateHandler(rr, httptest.NewRequest(http.MethodPost, "/admin/update", nil))
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		assert.Contains(t, rr.Body.String(), "v1.2.0")
+
+		select {
+		case <-restarted:
+		case <-time.After(time.Second):
+			t.Fatal("Expected restart after install")
+		}
+	})
+}
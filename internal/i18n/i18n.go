@@ -0,0 +1,211 @@
+// Package i18n translates pages and human-readable API strings. Messages
+// live in one JSON catalog per language under locales/, keyed by a dotted
+// name such as "login.title"; a message that differs by count has a ".one"
+// and an ".other" form. Messages missing from a catalog fall back to
+// English, so a new string can ship before it is translated.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// English is the default language, used when no supported language matches
+const English = "en"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalogs holds the messages of every supported language
+var catalogs = loadCatalogs()
+
+// loadCatalogs reads the embedded catalogs. They are part of the binary, so
+// a malformed one is a programming error.
+func loadCatalogs() map[string]map[string]string {
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: reading catalogs: %v", err))
+	}
+	catalogs := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := localeFiles.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: reading %s: %v", file.Name(), err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: parsing %s: %v", file.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(file.Name(), ".json")] = messages
+	}
+	return catalogs
+}
+
+// Supported returns the languages with a catalog, sorted
+func Supported() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Normalize returns the supported language for a tag such as "es-MX" or
+// "ES", and whether there is one
+func Normalize(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	_, ok := catalogs[tag]
+	return tag, ok
+}
+
+// Match returns the supported language the client prefers most from an
+// Accept-Language header, or English when none is supported
+func Match(acceptLanguage string) string {
+	best, bestQuality := English, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		// Earlier entries win ties, as the header lists them by preference
+		if locale, ok := Normalize(tag); ok && quality > bestQuality {
+			best, bestQuality = locale, quality
+		}
+	}
+	return best
+}
+
+// Printer looks up messages in one language. A nil Printer prints English.
+type Printer struct {
+	locale   string
+	messages map[string]string
+}
+
+// printers holds a printer per supported language
+var printers = func() map[string]*Printer {
+	printers := make(map[string]*Printer, len(catalogs))
+	for locale, messages := range catalogs {
+		printers[locale] = &Printer{locale: locale, messages: messages}
+	}
+	return printers
+}()
+
+// Get returns the printer for locale, or for English when it is not
+// supported
+func Get(locale string) *Printer {
+	if normalized, ok := Normalize(locale); ok {
+		return printers[normalized]
+	}
+	return printers[English]
+}
+
+// Locale returns the printer's language
+func (p *Printer) Locale() string {
+	if p == nil {
+		return English
+	}
+	return p.locale
+}
+
+// T returns the message for key, formatted with args like fmt.Sprintf. An
+// unknown key is returned as is, so it stands out on the page.
+func (p *Printer) T(key string, args ...interface{}) string {
+	message, ok := p.lookup(key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// N returns the form of the message for key that suits count, formatted
+// with count followed by args
+func (p *Printer) N(key string, count int, args ...interface{}) string {
+	form := key + ".other"
+	if count == 1 {
+		form = key + ".one"
+	}
+	return p.T(form, append([]interface{}{count}, args...)...)
+}
+
+// Messages returns every message in the printer's language, English ones
+// filling the gaps, for pages that build text in the browser
+func (p *Printer) Messages() map[string]string {
+	messages := make(map[string]string, len(catalogs[English]))
+	for key, message := range catalogs[English] {
+		messages[key] = message
+	}
+	for key, message := range p.catalog() {
+		messages[key] = message
+	}
+	return messages
+}
+
+// lookup returns the message for key in the printer's language, or in
+// English when it has not been translated
+func (p *Printer) lookup(key string) (string, bool) {
+	if message, ok := p.catalog()[key]; ok {
+		return message, true
+	}
+	message, ok := catalogs[English][key]
+	return message, ok
+}
+
+// catalog returns the printer's messages
+func (p *Printer) catalog() map[string]string {
+	if p == nil {
+		return catalogs[English]
+	}
+	return p.messages
+}
+
+type contextKey struct{}
+
+// WithPrinter returns a copy of ctx carrying p
+func WithPrinter(ctx context.Context, p *Printer) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the printer for the request ctx belongs to, or the
+// English one outside a request
+func FromContext(ctx context.Context) *Printer {
+	if p, ok := ctx.Value(contextKey{}).(*Printer); ok {
+		return p
+	}
+	return printers[English]
+}
+
+// Middleware picks each request's language: the signed-in user's
+// preference returned by preferred, else the best match for the
+// Accept-Language header. Handlers read it with FromContext.
+func Middleware(preferred func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			locale, ok := Normalize(preferred(r))
+			if !ok {
+				locale = Match(r.Header.Get("Accept-Language"))
+			}
+			w.Header().Add("Vary", "Accept-Language")
+			w.Header().Set("Content-Language", locale)
+			next.ServeHTTP(w, r.WithContext(WithPrinter(r.Context(), printers[locale])))
+		})
+	}
+}
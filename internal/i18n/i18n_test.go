@@ -0,0 +1,111 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCatalogs_Complete(t *testing.T) {
+	verbs := regexp.MustCompile(`%[a-z]`)
+	for _, locale := range Supported() {
+		for key, english := range catalogs[English] {
+			message, ok := catalogs[locale][key]
+			if !ok {
+				t.Errorf("Expected %s to translate %q", locale, key)
+				continue
+			}
+			if got, want := strings.Join(verbs.FindAllString(message, -1), ""), strings.Join(verbs.FindAllString(english, -1), ""); got != want {
+				t.Errorf("Expected %s %q to use %q like English, got %q", locale, key, want, got)
+			}
+		}
+		for key := range catalogs[locale] {
+			if _, ok := catalogs[English][key]; !ok {
+				t.Errorf("Expected %s key %q to exist in English", locale, key)
+			}
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "en"},
+		{"es", "es"},
+		{"es-MX,es;q=0.9,en;q=0.8", "es"},
+		{"fr-FR,fr;q=0.9,es;q=0.5,en;q=0.4", "es"},
+		{"en-US,es;q=0.9", "en"},
+		{"de,fr", "en"},
+		{"es;q=0", "en"},
+		{"es;q=bad,en", "en"},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.header); got != tt.want {
+			t.Errorf("Match(%q): expected %s, got %s", tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	spanish := Get("es-ES")
+	if spanish.Locale() != "es" {
+		t.Fatalf("Expected es, got %s", spanish.Locale())
+	}
+	if got := spanish.N("time.hours_ago", 1); got != "hace 1 hora" {
+		t.Errorf("Expected the singular form, got %q", got)
+	}
+	if got := spanish.N("time.hours_ago", 3); got != "hace 3 horas" {
+		t.Errorf("Expected the plural form, got %q", got)
+	}
+	if got := spanish.T("share.watered", "hace 3 horas"); got != "Regada hace 3 horas" {
+		t.Errorf("Expected arguments formatted, got %q", got)
+	}
+	if got := spanish.T("no.such.key"); got != "no.such.key" {
+		t.Errorf("Expected an unknown key returned as is, got %q", got)
+	}
+
+	// A message not yet translated falls back to English
+	delete(spanish.messages, "nav.home")
+	defer func() { spanish.messages["nav.home"] = "Inicio" }()
+	if got := spanish.T("nav.home"); got != "Home" {
+		t.Errorf("Expected the English message, got %q", got)
+	}
+	if got := spanish.Messages()["nav.home"]; got != "Home" {
+		t.Errorf("Expected Messages to fill gaps with English, got %q", got)
+	}
+
+	var nilPrinter *Printer
+	if got := nilPrinter.N("time.days_ago", 2); got != "2 days ago" {
+		t.Errorf("Expected a nil printer to print English, got %q", got)
+	}
+	if Get("fr") != Get(English) {
+		t.Error("Expected an unsupported language to get English")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	preference := ""
+	var got string
+	handler := Middleware(func(r *http.Request) string { return preference })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context()).Locale()
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", "es-AR,es;q=0.9")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if got != "es" || w.Header().Get("Content-Language") != "es" {
+		t.Errorf("Expected Accept-Language to pick es, got %s", got)
+	}
+
+	// The user's preference wins over the browser's
+	preference = "en"
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	if got != "en" {
+		t.Errorf("Expected the preference to pick en, got %s", got)
+	}
+}
@@ -0,0 +1,115 @@
+// Package ical writes iCalendar (RFC 5545) feeds that calendar apps such as
+// Google Calendar and Apple Calendar can subscribe to.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// prodID identifies the program that wrote a feed
+const prodID = "-//Watered//Watered//EN"
+
+// maxLineOctets is the longest content line RFC 5545 allows before folding
+const maxLineOctets = 75
+
+// Calendar is a feed of events
+type Calendar struct {
+	Name string
+	// RefreshInterval suggests how often subscribers fetch the feed again,
+	// 0 leaves it to them
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// Event is a VEVENT. Events with an RRule repeat from Start.
+type Event struct {
+	UID         string
+	Start       time.Time
+	Duration    time.Duration
+	Summary     string
+	Description string
+	RRule       string
+}
+
+// Encode writes the calendar to w, stamping events with now
+func (c *Calendar) Encode(w io.Writer, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeLine(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", prodID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		line("REFRESH-INTERVAL;VALUE=DURATION", duration(c.RefreshInterval))
+		line("X-PUBLISHED-TTL", duration(c.RefreshInterval))
+	}
+	for _, event := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escape(event.UID))
+		line("DTSTAMP", timestamp(now))
+		line("DTSTART", timestamp(event.Start))
+		line("DURATION", duration(event.Duration))
+		line("SUMMARY", escape(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", escape(event.Description))
+		}
+		if event.RRule != "" {
+			line("RRULE", event.RRule)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// writeLine writes a content line, folding it into continuation lines of at
+// most maxLineOctets without splitting UTF-8 characters
+func writeLine(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space, which counts toward the limit
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// timestamp formats t as a UTC DATE-TIME
+func timestamp(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// duration formats d as a DURATION in whole minutes, such as PT15M or PT72H
+func duration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes <= 0 {
+		return "PT0S"
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("PT%dH", minutes/60)
+	}
+	return fmt.Sprintf("PT%dM", minutes)
+}
@@ -0,0 +1,75 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestCalendar_Encode(t *testing.T) {
+	start := time.Date(2024, 6, 12, 9, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	calendar := &Calendar{
+		Name:            "Fern, by the window",
+		RefreshInterval: time.Hour,
+		Events: []Event{
+			{UID: "watering-1@watered", Start: start, Duration: 15 * time.Minute, Summary: "Fern watered"},
+			{UID: "schedule-1@watered", Start: start, Duration: time.Hour, Summary: "Water; soon", Description: "line one\nline two", RRule: "FREQ=DAILY;INTERVAL=3"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := calendar.Encode(&buf, start); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"X-WR-CALNAME:Fern\\, by the window\r\n",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H\r\n",
+		"DTSTART:20240612T073000Z\r\n",
+		"DURATION:PT15M\r\n",
+		"SUMMARY:Water\\; soon\r\n",
+		"DESCRIPTION:line one\\nline two\r\n",
+		"RRULE:FREQ=DAILY;INTERVAL=3\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "BEGIN:VEVENT") != 2 || strings.Count(out, "DTSTAMP:20240612T073000Z") != 2 {
+		t.Errorf("Expected two stamped events, got:\n%s", out)
+	}
+}
+
+func TestWriteLine_Folds(t *testing.T) {
+	var buf bytes.Buffer
+	calendar := &Calendar{Events: []Event{{UID: "x", Summary: strings.Repeat("🪴 water ", 30)}}}
+	if err := calendar.Encode(&buf, time.Now()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	var summary strings.Builder
+	folded := false
+	for _, line := range lines {
+		if len(line) > maxLineOctets {
+			t.Errorf("Expected lines of at most %d octets, got %d: %q", maxLineOctets, len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("Expected folding to keep characters whole, got %q", line)
+		}
+		if strings.HasPrefix(line, "SUMMARY:") {
+			summary.WriteString(line)
+		} else if strings.HasPrefix(line, " ") && summary.Len() > 0 {
+			summary.WriteString(line[1:])
+			folded = true
+		}
+	}
+	if !folded || summary.String() != "SUMMARY:"+strings.Repeat("🪴 water ", 30) {
+		t.Errorf("Expected the summary to unfold to the original, got %q", summary.String())
+	}
+}
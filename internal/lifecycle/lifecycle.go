@@ -0,0 +1,85 @@
+// Package lifecycle stops the server's components in order on shutdown, so
+// work in flight when a deploy stops the server is finished rather than
+// dropped.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// StopFunc stops a component, waiting for its in-flight work until ctx is
+// done
+type StopFunc func(ctx context.Context) error
+
+// step is one component to stop
+type step struct {
+	name string
+	stop StopFunc
+}
+
+// Manager stops components in the order they were added. Add components
+// that start work before the components that finish it, such as the HTTP
+// server before the notifications its requests send.
+type Manager struct {
+	mu    sync.Mutex
+	steps []step
+}
+
+// New creates a manager with no components
+func New() *Manager {
+	return &Manager{}
+}
+
+// Add registers a component to stop after those already added
+func (m *Manager) Add(name string, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.steps = append(m.steps, step{name: name, stop: stop})
+}
+
+// Shutdown stops every component in turn, sharing ctx's deadline. A
+// component that fails or runs out of time does not stop the rest, since
+// later steps such as flushing state matter most when draining ran late.
+// The errors of all components are returned together.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	steps := append([]step(nil), m.steps...)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, s := range steps {
+		start := time.Now()
+		if err := s.stop(ctx); err != nil {
+			slog.Warn("Component did not stop cleanly", "component", s.name, "duration", time.Since(start), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			continue
+		}
+		slog.Info("Component stopped", "component", s.name, "duration", time.Since(start))
+	}
+	return errors.Join(errs...)
+}
+
+// Cancel returns a StopFunc for a goroutine that runs until its context is
+// cancelled: it calls cancel and waits for done to be closed
+func Cancel(cancel context.CancelFunc, done <-chan struct{}) StopFunc {
+	return func(ctx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Func adapts a stop function that cannot wait, such as a flush, to a
+// StopFunc
+func Func(stop func() error) StopFunc {
+	return func(context.Context) error { return stop() }
+}
@@ -0,0 +1,69 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManager_ShutdownInOrder(t *testing.T) {
+	manager := New()
+	var stopped []string
+	for _, name := range []string{"http", "scheduler", "hub"} {
+		manager.Add(name, func(ctx context.Context) error {
+			stopped = append(stopped, name)
+			return nil
+		})
+	}
+
+	if err := manager.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Join(stopped, ",") != "http,scheduler,hub" {
+		t.Errorf("Expected components stopped in order, got %v", stopped)
+	}
+}
+
+func TestManager_ShutdownContinuesAfterFailure(t *testing.T) {
+	manager := New()
+	flushed := false
+	manager.Add("scheduler", func(ctx context.Context) error { return errors.New("job still running") })
+	manager.Add("activity", Func(func() error { flushed = true; return nil }))
+
+	err := manager.Shutdown(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "scheduler: job still running") {
+		t.Errorf("Expected the scheduler's error, got %v", err)
+	}
+	if !flushed {
+		t.Error("Expected later components to stop after a failure")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		// Finish the message being handled
+		time.Sleep(10 * time.Millisecond)
+		close(done)
+	}()
+
+	if err := Cancel(cancel, done)(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	select {
+	case <-done:
+	default:
+		t.Error("Expected Cancel to wait for the goroutine")
+	}
+
+	// A goroutine that never finishes is abandoned at the deadline
+	deadline, stop := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer stop()
+	if err := Cancel(func() {}, make(chan struct{}))(deadline); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to be exceeded, got %v", err)
+	}
+}
@@ -1,37 +1,93 @@
+// Package logger sets up structured JSON logging with log/slog and carries
+// a request-scoped logger through the request context, so every line logged
+// while serving a request names its request ID and user.
 package logger
 
 import (
-	"log"
-	"os"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
 )
 
-// Logger provides structured logging for the application
-type Logger struct {
-	*log.Logger
+// New returns a logger writing one JSON object per line for records at level
+// and above. Pass a *slog.LevelVar to change the level at runtime.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
 }
 
-// NewLogger creates a new logger instance
-func NewLogger() *Logger {
-	return &Logger{
-		Logger: log.New(os.Stdout, "[WATERED] ", log.LstdFlags|log.Lshortfile),
-	}
+// requestLogger is the mutable logger of one request. Middleware further
+// down the chain, such as API key authentication, adds attributes to it
+// that the access log line written by Middleware then includes.
+type requestLogger struct {
+	mu     sync.Mutex
+	logger *slog.Logger
 }
 
-// Info logs informational messages
-func (l *Logger) Info(msg string) {
-	l.Printf("INFO: %s", msg)
+type contextKey struct{}
+
+// FromContext returns the request-scoped logger, or the default logger when
+// ctx does not belong to a request
+func FromContext(ctx context.Context) *slog.Logger {
+	if rl, ok := ctx.Value(contextKey{}).(*requestLogger); ok {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		return rl.logger
+	}
+	return slog.Default()
 }
 
-// Error logs error messages
-func (l *Logger) Error(msg string, err error) {
-	if err != nil {
-		l.Printf("ERROR: %s - %v", msg, err)
-	} else {
-		l.Printf("ERROR: %s", msg)
+// AddAttrs adds attributes to the request-scoped logger in ctx. It does
+// nothing outside a request.
+func AddAttrs(ctx context.Context, args ...any) {
+	if rl, ok := ctx.Value(contextKey{}).(*requestLogger); ok {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		rl.logger = rl.logger.With(args...)
 	}
 }
 
-// Debug logs debug messages
-func (l *Logger) Debug(msg string) {
-	l.Printf("DEBUG: %s", msg)
+// Middleware gives each request a logger carrying its request ID and the
+// email returned by user, if any, and logs one access line per request once
+// it has been served. It must run after chi's RequestID middleware.
+func Middleware(user func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			l := slog.Default().With("request_id", middleware.GetReqID(r.Context()))
+			if email := user(r); email != "" {
+				l = l.With("user", email)
+			}
+			rl := &requestLogger{logger: l}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), contextKey{}, rl)))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			rl.mu.Lock()
+			l = rl.logger
+			rl.mu.Unlock()
+			l.LogAttrs(r.Context(), level, "request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remote_addr", r.RemoteAddr),
+			)
+		})
+	}
 }
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// captureDefault routes the default logger to a buffer for the test
+func captureDefault(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(New(&buf, level))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+// records decodes the JSON lines written to buf
+func records(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected a JSON log line, got %q: %v", line, err)
+		}
+		out = append(out, record)
+	}
+	return out
+}
+
+func TestNewLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, slog.LevelWarn)
+	l.Info("hidden")
+	l.Warn("shown", "plant_id", 1)
+
+	lines := records(t, &buf)
+	if len(lines) != 1 || lines[0]["msg"] != "shown" || lines[0]["plant_id"] != 1.0 {
+		t.Errorf("Expected only the warning to be logged, got %v", lines)
+	}
+}
+
+func TestFromContextOutsideRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	if FromContext(req.Context()) != slog.Default() {
+		t.Error("Expected the default logger outside a request")
+	}
+	// Adding attributes outside a request is a no-op
+	AddAttrs(req.Context(), "user", "nobody@example.com")
+}
+
+func TestMiddleware(t *testing.T) {
+	buf := captureDefault(t, slog.LevelInfo)
+
+	handler := middleware.RequestID(Middleware(func(r *http.Request) string {
+		return r.Header.Get("X-Test-User")
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		AddAttrs(r.Context(), "api_key", "Home Assistant")
+		FromContext(r.Context()).Info("watering")
+		w.WriteHeader(http.StatusTeapot)
+	})))
+
+	req := httptest.NewRequest("POST", "/api/plant/water", nil)
+	req.Header.Set("X-Test-User", "user@example.com")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := records(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("Expected a handler line and an access line, got %v", lines)
+	}
+	for _, line := range lines {
+		if line["request_id"] == "" || line["request_id"] == nil {
+			t.Errorf("Expected a request ID, got %v", line)
+		}
+		if line["user"] != "user@example.com" || line["api_key"] != "Home Assistant" {
+			t.Errorf("Expected request attributes, got %v", line)
+		}
+	}
+
+	access := lines[1]
+	if access["msg"] != "request" || access["method"] != "POST" || access["path"] != "/api/plant/water" || access["status"] != 418.0 {
+		t.Errorf("Unexpected access log line: %v", access)
+	}
+}
+
+func TestMiddlewareServerErrors(t *testing.T) {
+	buf := captureDefault(t, slog.LevelInfo)
+
+	handler := Middleware(func(r *http.Request) string { return "" })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	lines := records(t, buf)
+	if len(lines) != 1 || lines[0]["level"] != "ERROR" {
+		t.Errorf("Expected server errors to be logged at error level, got %v", lines)
+	}
+	if _, ok := lines[0]["user"]; ok {
+		t.Errorf("Expected no user for anonymous requests, got %v", lines[0])
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// APIKey lets an automation client such as Home Assistant call the API
+// without a browser session. Only a hash of the secret is stored; the key
+// itself is shown once when it is issued.
+type APIKey struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Hash is the hex SHA-256 of the full key
+	Hash string `json:"hash,omitempty" mask:"admin"`
+	// CreatedBy is the admin who issued the key; requests made with it act
+	// as that user without admin rights
+	CreatedBy string    `json:"created_by" mask:"admin"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Admin actions recorded in the audit log
+const (
+	AuditConfigTimeout     = "config.timeout"
+	AuditConfigPrivacyMode = "config.privacy_mode"
+	AuditConfigFeature     = "config.feature"
+	AuditConfigReload      = "config.reload"
+	AuditUserAdd           = "user.add"
+	AuditUserRemove        = "user.remove"
+	AuditUserMerge         = "user.merge"
+	AuditUserRole          = "user.role"
+	AuditUserSignOut       = "user.sign_out"
+	AuditDemoUserAdd       = "demo_user.add"
+	AuditDemoUserRemove    = "demo_user.remove"
+	AuditPlantCreate       = "plant.create"
+	AuditPlantSettings     = "plant.settings"
+	AuditPlantReset        = "plant.reset"
+	AuditPlantUndoWatering = "plant.undo_watering"
+	AuditPlantVacation     = "plant.vacation"
+	AuditPlantDelete       = "plant.delete"
+	AuditCareTaskCreate    = "care_task.create"
+	AuditCareTaskUpdate    = "care_task.update"
+	AuditCareTaskDelete    = "care_task.delete"
+	AuditIntegrityRepair   = "integrity.repair"
+	AuditAPIKeyCreate      = "apikey.create"
+	AuditAPIKeyRevoke      = "apikey.revoke"
+	AuditDeviceCreate      = "device.create"
+	AuditDeviceUpdate      = "device.update"
+	AuditDeviceToken       = "device.rotate_token"
+	AuditDeviceDelete      = "device.delete"
+	AuditSessionRevoke     = "session.revoke"
+	AuditShareCreate       = "share.create"
+	AuditShareRevoke       = "share.revoke"
+	AuditInviteCreate      = "invite.create"
+	AuditInviteRevoke      = "invite.revoke"
+	AuditBackupRestore     = "backup.restore"
+	// AuditAuthLockout is recorded by the server, not an admin, when failed
+	// sign-ins lock a client address or email out
+	AuditAuthLockout = "auth.lockout"
+)
+
+// AuditEntry records one change an admin made. Old and New hold the changed
+// values as JSON and are omitted when there was nothing before or after,
+// such as when a user is added or removed.
+type AuditEntry struct {
+	ID     int       `json:"id"`
+	At     time.Time `json:"at"`
+	Actor  string    `json:"actor"`
+	Action string    `json:"action"`
+	// Target names what was changed, such as a plant ID or an email
+	Target string          `json:"target,omitempty"`
+	Old    json.RawMessage `json:"old,omitempty"`
+	New    json.RawMessage `json:"new,omitempty"`
+}
+
+// AuditFilter narrows an audit log query. Empty fields match everything.
+type AuditFilter struct {
+	Actor  string
+	Action string
+	Target string
+	Since  time.Time
+	Until  time.Time
+}
+
+// Matches reports whether the entry satisfies the filter
+func (f AuditFilter) Matches(e *AuditEntry) bool {
+	if f.Actor != "" && !strings.EqualFold(e.Actor, f.Actor) {
+		return false
+	}
+	if f.Action != "" && e.Action != f.Action {
+		return false
+	}
+	if f.Target != "" && e.Target != f.Target {
+		return false
+	}
+	if !f.Since.IsZero() && e.At.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.At.Before(f.Until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// CareTaskType is a kind of recurring care besides watering
+type CareTaskType string
+
+const (
+	CareTaskFertilize CareTaskType = "fertilize"
+	CareTaskMist      CareTaskType = "mist"
+	CareTaskRepot     CareTaskType = "repot"
+)
+
+// CareTaskTypes lists the supported care task types
+var CareTaskTypes = []CareTaskType{CareTaskFertilize, CareTaskMist, CareTaskRepot}
+
+// Valid reports whether the type is a supported care task type
+func (t CareTaskType) Valid() bool {
+	for _, known := range CareTaskTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// CareTaskStatus reports how close a care task is to being due
+type CareTaskStatus string
+
+const (
+	CareTaskStatusOK      CareTaskStatus = "ok"
+	CareTaskStatusDueSoon CareTaskStatus = "due_soon"
+	CareTaskStatusDue     CareTaskStatus = "due"
+)
+
+// CareTaskDueSoonPercent is the share of a task's interval after which it
+// is due soon
+const CareTaskDueSoonPercent = 80
+
+// MaxCareTaskIntervalHours is the longest allowed task interval, two years,
+// long enough for repotting
+const MaxCareTaskIntervalHours = 2 * 8760
+
+// CareTask is a recurring care task for a plant, such as fertilizing every
+// four weeks. Each plant has at most one task of each type.
+type CareTask struct {
+	ID      int          `json:"id"`
+	PlantID int          `json:"plant_id"`
+	Type    CareTaskType `json:"type"`
+	// IntervalHours is how long after the last completion the task is due
+	IntervalHours int        `json:"interval_hours"`
+	LastDone      *time.Time `json:"last_done"`
+	LastDoneBy    string     `json:"last_done_by" mask:"member"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// CareTaskEvent records one completion of a care task
+type CareTaskEvent struct {
+	ID      int          `json:"id"`
+	TaskID  int          `json:"task_id"`
+	PlantID int          `json:"plant_id"`
+	Type    CareTaskType `json:"type"`
+	DoneAt  time.Time    `json:"done_at"`
+	DoneBy  string       `json:"done_by" mask:"member"`
+}
+
+// DueAt returns when the task is next due: one interval after it was last
+// done, or after it was created if it has never been done
+func (t *CareTask) DueAt() time.Time {
+	from := t.CreatedAt
+	if t.LastDone != nil {
+		from = *t.LastDone
+	}
+	return from.Add(time.Duration(t.IntervalHours) * time.Hour)
+}
+
+// Status reports whether the task is due, due soon or not yet due
+func (t *CareTask) Status() CareTaskStatus {
+	dueAt := t.DueAt()
+	now := time.Now()
+	if !now.Before(dueAt) {
+		return CareTaskStatusDue
+	}
+	interval := time.Duration(t.IntervalHours) * time.Hour
+	if dueAt.Sub(now) <= interval*(100-CareTaskDueSoonPercent)/100 {
+		return CareTaskStatusDueSoon
+	}
+	return CareTaskStatusOK
+}
+
+// Validate checks the task's type and interval
+func (t *CareTask) Validate() error {
+	if !t.Type.Valid() {
+		return fmt.Errorf("unknown care task type %q", t.Type)
+	}
+	if t.IntervalHours <= 0 {
+		return fmt.Errorf("interval hours must be positive")
+	}
+	if t.IntervalHours > MaxCareTaskIntervalHours {
+		return fmt.Errorf("interval hours cannot exceed %d (2 years)", MaxCareTaskIntervalHours)
+	}
+	return nil
+}
+
+// CareStatus is a plant's overall status across watering and its care tasks
+type CareStatus string
+
+const (
+	CareStatusHealthy        CareStatus = "healthy"
+	CareStatusNeedsAttention CareStatus = "needs_attention"
+	CareStatusDue            CareStatus = "due"
+	CareStatusCritical       CareStatus = "critical"
+	CareStatusPaused         CareStatus = "paused"
+)
+
+// OverallStatus rolls a plant's watering status and its care tasks up into
+// one status. Critical watering outranks everything; a due task counts as
+// much as due watering, and a task due soon as much as needing water. Paused
+// plants are paused whatever their tasks say.
+func OverallStatus(watering PlantHealthStatus, tasks []*CareTask) CareStatus {
+	var overall CareStatus
+	switch watering {
+	case HealthStatusPaused:
+		return CareStatusPaused
+	case HealthStatusCritical:
+		return CareStatusCritical
+	case HealthStatusDue:
+		overall = CareStatusDue
+	case HealthStatusNeedsWater:
+		overall = CareStatusNeedsAttention
+	default:
+		overall = CareStatusHealthy
+	}
+
+	for _, task := range tasks {
+		switch task.Status() {
+		case CareTaskStatusDue:
+			overall = CareStatusDue
+		case CareTaskStatusDueSoon:
+			if overall == CareStatusHealthy {
+				overall = CareStatusNeedsAttention
+			}
+		}
+	}
+	return overall
+}
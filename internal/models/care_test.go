@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCareTask_Status(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		task     CareTask
+		expected CareTaskStatus
+	}{
+		{"Just created", CareTask{IntervalHours: 100, CreatedAt: now}, CareTaskStatusOK},
+		{"Never done, interval elapsed since creation", CareTask{IntervalHours: 100, CreatedAt: now.Add(-101 * time.Hour)}, CareTaskStatusDue},
+		{"Recently done", CareTask{IntervalHours: 100, CreatedAt: now.Add(-500 * time.Hour), LastDone: timePtr(now.Add(-10 * time.Hour))}, CareTaskStatusOK},
+		{"Most of the interval gone", CareTask{IntervalHours: 100, LastDone: timePtr(now.Add(-85 * time.Hour))}, CareTaskStatusDueSoon},
+		{"Interval elapsed", CareTask{IntervalHours: 100, LastDone: timePtr(now.Add(-100 * time.Hour))}, CareTaskStatusDue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.task.Status(); got != tt.expected {
+				t.Errorf("Status() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCareTask_Validate(t *testing.T) {
+	valid := CareTask{Type: CareTaskRepot, IntervalHours: 8760}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected a yearly repot task to be valid, got %v", err)
+	}
+	for _, task := range []CareTask{
+		{Type: "prune", IntervalHours: 24},
+		{Type: CareTaskMist, IntervalHours: 0},
+		{Type: CareTaskMist, IntervalHours: MaxCareTaskIntervalHours + 1},
+	} {
+		if err := task.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", task)
+		}
+	}
+}
+
+func TestOverallStatus(t *testing.T) {
+	now := time.Now()
+	ok := &CareTask{IntervalHours: 100, LastDone: timePtr(now)}
+	dueSoon := &CareTask{IntervalHours: 100, LastDone: timePtr(now.Add(-90 * time.Hour))}
+	due := &CareTask{IntervalHours: 100, LastDone: timePtr(now.Add(-200 * time.Hour))}
+
+	tests := []struct {
+		name     string
+		watering PlantHealthStatus
+		tasks    []*CareTask
+		expected CareStatus
+	}{
+		{"Healthy without tasks", HealthStatusHealthy, nil, CareStatusHealthy},
+		{"Healthy with tasks done", HealthStatusHealthy, []*CareTask{ok}, CareStatusHealthy},
+		{"Task due soon", HealthStatusHealthy, []*CareTask{ok, dueSoon}, CareStatusNeedsAttention},
+		{"Needs water", HealthStatusNeedsWater, []*CareTask{ok}, CareStatusNeedsAttention},
+		{"Task due", HealthStatusNeedsWater, []*CareTask{dueSoon, due}, CareStatusDue},
+		{"Watering due", HealthStatusDue, []*CareTask{dueSoon}, CareStatusDue},
+		{"Critical outranks tasks", HealthStatusCritical, []*CareTask{due}, CareStatusCritical},
+		{"Paused outranks tasks", HealthStatusPaused, []*CareTask{due}, CareStatusPaused},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OverallStatus(tt.watering, tt.tasks); got != tt.expected {
+				t.Errorf("OverallStatus() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
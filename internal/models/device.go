@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Kinds of device that can be registered
+const (
+	DeviceKindSensor = "sensor"
+	DeviceKindButton = "button"
+)
+
+// ButtonWaterer is recorded as the waterer of waterings a button recorded,
+// since the button cannot tell who pressed it
+const ButtonWaterer = "button"
+
+// IsDeviceWaterer reports whether a watering was recorded by a device rather
+// than a person
+func IsDeviceWaterer(wateredBy string) bool {
+	return wateredBy == SensorWaterer || wateredBy == ButtonWaterer
+}
+
+// Device is a piece of hardware registered by an admin, such as a soil
+// sensor or an "I watered it" button, placed with one plant. It
+// authenticates with its ID and a token; only a hash of the token is
+// stored, and the token itself is shown once when it is issued or rotated.
+type Device struct {
+	// ID names the device in URLs and MQTT topics
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	PlantID int    `json:"plant_id"`
+	// Active devices may authenticate; inactive ones keep their history
+	Active bool `json:"active"`
+	// TokenHash is the hex SHA-256 of the device's token
+	TokenHash      string     `json:"token_hash,omitempty" mask:"admin"`
+	TokenRotatedAt time.Time  `json:"token_rotated_at"`
+	CreatedBy      string     `json:"created_by" mask:"admin"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastSeen       *time.Time `json:"last_seen"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// HealthSample is the result of one scheduled health check, kept so the
+// admin page can show how components fared over time. Components maps each
+// component name to its status.
+type HealthSample struct {
+	ID         int               `json:"id"`
+	CheckedAt  time.Time         `json:"checked_at"`
+	Status     string            `json:"status"`
+	Components map[string]string `json:"components"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// IntegritySeverity indicates how serious a consistency problem is
+type IntegritySeverity string
+
+const (
+	IntegritySeverityWarning IntegritySeverity = "warning"
+	IntegritySeverityError   IntegritySeverity = "error"
+)
+
+// IntegrityIssue is a single consistency problem found in stored data
+type IntegrityIssue struct {
+	Code       string            `json:"code"`
+	Severity   IntegritySeverity `json:"severity"`
+	Message    string            `json:"message"`
+	Repairable bool              `json:"repairable"`
+	Repaired   bool              `json:"repaired"`
+}
+
+// IntegrityReport is the result of a data consistency check
+type IntegrityReport struct {
+	CheckedAt time.Time        `json:"checked_at"`
+	Repair    bool             `json:"repair"`
+	Issues    []IntegrityIssue `json:"issues"`
+}
+
+// OK reports whether no unrepaired issues remain
+func (r *IntegrityReport) OK() bool {
+	return len(r.Unresolved()) == 0
+}
+
+// Unresolved returns the issues that were not repaired
+func (r *IntegrityReport) Unresolved() []IntegrityIssue {
+	unresolved := []IntegrityIssue{}
+	for _, issue := range r.Issues {
+		if !issue.Repaired {
+			unresolved = append(unresolved, issue)
+		}
+	}
+	return unresolved
+}
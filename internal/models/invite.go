@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Invite lets whoever signs in through its link join the allowlist, so
+// admins don't have to type the new user's email. The link carries a signed
+// token naming the invite; the invite is used up by the first sign-in and
+// stops working when it expires or is revoked.
+type Invite struct {
+	ID string `json:"id"`
+	// Name says who the invite was made for, such as "Alex"
+	Name string `json:"name"`
+	// Role is the role the invitee joins with, member or viewer
+	Role      string    `json:"role"`
+	CreatedBy string    `json:"created_by" mask:"admin"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the invite can no longer be accepted at now
+func (i *Invite) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
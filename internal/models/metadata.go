@@ -0,0 +1,135 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetadataType is the type a custom plant field is validated against
+type MetadataType string
+
+const (
+	MetadataTypeString MetadataType = "string"
+	MetadataTypeNumber MetadataType = "number"
+	MetadataTypeBool   MetadataType = "bool"
+	// MetadataTypeDate is a calendar date, YYYY-MM-DD
+	MetadataTypeDate MetadataType = "date"
+	// MetadataTypeCoordinates is a "latitude,longitude" pair in degrees
+	MetadataTypeCoordinates MetadataType = "coordinates"
+)
+
+// Limits on custom plant fields
+const (
+	MaxMetadataFields      = 32
+	MaxMetadataKeyLength   = 40
+	MaxMetadataValueLength = 256
+)
+
+// metadataKeyPattern allows lowercase keys such as "pot_size" or "location"
+var metadataKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// MetadataValue is one custom field on a plant, such as a pot size or
+// purchase date. Values are stored as strings in a canonical form for their
+// type.
+type MetadataValue struct {
+	Type  MetadataType `json:"type"`
+	Value string       `json:"value"`
+}
+
+// Normalize validates the value against its type and returns it in
+// canonical form: numbers without redundant digits, booleans as true/false
+// and coordinates without spaces. An empty type means string.
+func (v MetadataValue) Normalize() (MetadataValue, error) {
+	if v.Type == "" {
+		v.Type = MetadataTypeString
+	}
+
+	switch v.Type {
+	case MetadataTypeString:
+		v.Value = strings.TrimSpace(v.Value)
+		if v.Value == "" {
+			return v, fmt.Errorf("value cannot be empty")
+		}
+		if len(v.Value) > MaxMetadataValueLength {
+			return v, fmt.Errorf("value cannot exceed %d characters", MaxMetadataValueLength)
+		}
+
+	case MetadataTypeNumber:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v.Value), 64)
+		if err != nil {
+			return v, fmt.Errorf("%q is not a number", v.Value)
+		}
+		v.Value = strconv.FormatFloat(n, 'f', -1, 64)
+
+	case MetadataTypeBool:
+		b, err := strconv.ParseBool(strings.TrimSpace(v.Value))
+		if err != nil {
+			return v, fmt.Errorf("%q is not true or false", v.Value)
+		}
+		v.Value = strconv.FormatBool(b)
+
+	case MetadataTypeDate:
+		d, err := time.Parse("2006-01-02", strings.TrimSpace(v.Value))
+		if err != nil {
+			return v, fmt.Errorf("%q is not a YYYY-MM-DD date", v.Value)
+		}
+		v.Value = d.Format("2006-01-02")
+
+	case MetadataTypeCoordinates:
+		lat, lng, ok := strings.Cut(v.Value, ",")
+		latitude, latErr := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+		longitude, lngErr := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+		if !ok || latErr != nil || lngErr != nil {
+			return v, fmt.Errorf("%q is not a latitude,longitude pair", v.Value)
+		}
+		if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+			return v, fmt.Errorf("%q is outside the range of latitude and longitude", v.Value)
+		}
+		v.Value = strconv.FormatFloat(latitude, 'f', -1, 64) + "," + strconv.FormatFloat(longitude, 'f', -1, 64)
+
+	default:
+		return v, fmt.Errorf("unknown type %q", v.Type)
+	}
+
+	return v, nil
+}
+
+// NormalizeMetadata validates custom plant fields and returns them in
+// canonical form. An empty map clears all fields and is returned as nil.
+func NormalizeMetadata(metadata map[string]MetadataValue) (map[string]MetadataValue, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	if len(metadata) > MaxMetadataFields {
+		return nil, fmt.Errorf("a plant cannot have more than %d custom fields", MaxMetadataFields)
+	}
+
+	normalized := make(map[string]MetadataValue, len(metadata))
+	for key, value := range metadata {
+		if len(key) > MaxMetadataKeyLength || !metadataKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid field name %q: use up to %d lowercase letters, digits and underscores", key, MaxMetadataKeyLength)
+		}
+
+		value, err := value.Normalize()
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		normalized[key] = value
+	}
+	return normalized, nil
+}
+
+// MatchesMetadata reports whether the plant has every given field with the
+// given value, compared case-insensitively
+func (p *PlantState) MatchesMetadata(filter map[string]string) bool {
+	for key, want := range filter {
+		value, ok := p.Metadata[key]
+		if !ok || !strings.EqualFold(value.Value, strings.TrimSpace(want)) {
+			return false
+		}
+	}
+	return true
+}
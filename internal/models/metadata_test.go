@@ -0,0 +1,74 @@
+package models
+
+import "testing"
+
+func TestMetadataValue_Normalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    MetadataValue
+		expected string
+		wantErr  bool
+	}{
+		{"untyped string", MetadataValue{Value: "  balcony "}, "balcony", false},
+		{"empty string", MetadataValue{Type: MetadataTypeString, Value: " "}, "", true},
+		{"number", MetadataValue{Type: MetadataTypeNumber, Value: "18.50"}, "18.5", false},
+		{"not a number", MetadataValue{Type: MetadataTypeNumber, Value: "large"}, "", true},
+		{"bool", MetadataValue{Type: MetadataTypeBool, Value: "TRUE"}, "true", false},
+		{"date", MetadataValue{Type: MetadataTypeDate, Value: "2024-03-01"}, "2024-03-01", false},
+		{"invalid date", MetadataValue{Type: MetadataTypeDate, Value: "01/03/2024"}, "", true},
+		{"coordinates", MetadataValue{Type: MetadataTypeCoordinates, Value: "52.520, 13.40"}, "52.52,13.4", false},
+		{"coordinates out of range", MetadataValue{Type: MetadataTypeCoordinates, Value: "91,0"}, "", true},
+		{"unknown type", MetadataValue{Type: "color", Value: "green"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.value.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && result.Value != tt.expected {
+				t.Errorf("Expected value %q, got %q", tt.expected, result.Value)
+			}
+		})
+	}
+}
+
+func TestNormalizeMetadata(t *testing.T) {
+	if metadata, err := NormalizeMetadata(map[string]MetadataValue{}); err != nil || metadata != nil {
+		t.Errorf("Expected empty metadata to clear all fields, got %v (%v)", metadata, err)
+	}
+
+	for _, key := range []string{"Location", "pot size", "1st", ""} {
+		if _, err := NormalizeMetadata(map[string]MetadataValue{key: {Value: "x"}}); err == nil {
+			t.Errorf("Expected field name %q to be rejected", key)
+		}
+	}
+
+	metadata, err := NormalizeMetadata(map[string]MetadataValue{"pot_size": {Type: MetadataTypeNumber, Value: "18"}})
+	if err != nil {
+		t.Fatalf("Failed to normalize metadata: %v", err)
+	}
+	if metadata["pot_size"].Type != MetadataTypeNumber {
+		t.Errorf("Expected pot_size to keep its type, got %v", metadata["pot_size"])
+	}
+}
+
+func TestPlantState_MatchesMetadata(t *testing.T) {
+	plant := &PlantState{Metadata: map[string]MetadataValue{
+		"location": {Type: MetadataTypeString, Value: "Balcony"},
+	}}
+
+	if !plant.MatchesMetadata(nil) {
+		t.Error("Expected an empty filter to match")
+	}
+	if !plant.MatchesMetadata(map[string]string{"location": "balcony"}) {
+		t.Error("Expected a case-insensitive match")
+	}
+	if plant.MatchesMetadata(map[string]string{"location": "kitchen"}) {
+		t.Error("Expected a different value not to match")
+	}
+	if plant.MatchesMetadata(map[string]string{"pot_size": "18"}) {
+		t.Error("Expected a missing field not to match")
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// NotificationStatus represents the delivery status of a notification
+type NotificationStatus string
+
+const (
+	NotificationStatusPending   NotificationStatus = "pending"
+	NotificationStatusDelivered NotificationStatus = "delivered"
+	NotificationStatusFailed    NotificationStatus = "failed"
+)
+
+// Notification represents a single notification sent (or attempted) to a user
+type Notification struct {
+	ID        int                 `json:"id"`
+	UserEmail string              `json:"user_email" mask:"member"`
+	Channel   string              `json:"channel"`
+	Trigger   NotificationTrigger `json:"trigger"`
+	Status    NotificationStatus  `json:"status"`
+	Summary   string              `json:"summary"`
+	Error     string              `json:"error,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+// NotificationFilter narrows a notification history query. Empty fields match everything.
+type NotificationFilter struct {
+	UserEmail string
+	Channel   string
+	Status    NotificationStatus
+	Limit     int
+}
+
+// Matches reports whether the notification satisfies the filter
+func (f NotificationFilter) Matches(n *Notification) bool {
+	if f.UserEmail != "" && n.UserEmail != f.UserEmail {
+		return false
+	}
+	if f.Channel != "" && n.Channel != f.Channel {
+		return false
+	}
+	if f.Status != "" && n.Status != f.Status {
+		return false
+	}
+	return true
+}
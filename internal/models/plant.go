@@ -3,6 +3,8 @@ package models
 import (
 	"fmt"
 	"time"
+
+	"watered/internal/i18n"
 )
 
 // PlantHealthStatus represents the health status of a plant
@@ -11,8 +13,38 @@ type PlantHealthStatus string
 const (
 	HealthStatusHealthy    PlantHealthStatus = "healthy"
 	HealthStatusNeedsWater PlantHealthStatus = "needs_water"
+	HealthStatusDue        PlantHealthStatus = "due"
 	HealthStatusCritical   PlantHealthStatus = "critical"
 	HealthStatusUnknown    PlantHealthStatus = "unknown"
+	// HealthStatusPaused is reported while the plant's vacation is active
+	HealthStatusPaused PlantHealthStatus = "paused"
+)
+
+// NotificationTrigger identifies the watering milestone a reminder is sent for
+type NotificationTrigger string
+
+const (
+	NotificationTriggerNone         NotificationTrigger = ""
+	NotificationTriggerNeedsWater   NotificationTrigger = "needs_water"
+	NotificationTriggerDue          NotificationTrigger = "due"
+	NotificationTriggerCritical     NotificationTrigger = "critical"
+	NotificationTriggerDigest       NotificationTrigger = "digest"
+	NotificationTriggerWeeklyReport NotificationTrigger = "weekly_report"
+	NotificationTriggerTest         NotificationTrigger = "test"
+	// NotificationTriggerSnoozed records in the history that a user snoozed
+	// reminders about a plant
+	NotificationTriggerSnoozed NotificationTrigger = "snoozed"
+)
+
+// DefaultPlantID is the plant served by the single-plant routes
+const DefaultPlantID = 1
+
+// Default health thresholds, as percentages of the timeout
+const (
+	DefaultNeedsWaterPercent = 50
+	DefaultCriticalPercent   = 100
+	// MaxThresholdPercent bounds both thresholds
+	MaxThresholdPercent = 500
 )
 
 // PlantState represents the current state of the plant
@@ -21,9 +53,50 @@ type PlantState struct {
 	Name         string     `json:"name"`
 	LastWatered  *time.Time `json:"last_watered"` // Pointer to handle null case
 	TimeoutHours int        `json:"timeout_hours"`
-	WateredBy    string     `json:"watered_by"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	// GracePeriodHours is how long past the timeout the plant stays "due"
+	// before it is considered critical
+	GracePeriodHours int `json:"grace_period_hours"`
+	// NeedsWaterPercent is the share of the timeout after which the plant
+	// needs water, and CriticalPercent the share after which it is overdue
+	// and, once the grace period has also passed, critical. 0 means the
+	// default.
+	NeedsWaterPercent int    `json:"needs_water_percent,omitempty"`
+	CriticalPercent   int    `json:"critical_percent,omitempty"`
+	WateredBy         string `json:"watered_by" mask:"member"`
+	// Metadata holds custom fields such as pot size or location
+	Metadata map[string]MetadataValue `json:"metadata,omitempty" mask:"member"`
+	// Vacation pauses reminders and overdue status while it is active. It
+	// reveals when the household is away, so viewers never see it.
+	Vacation *Vacation `json:"vacation,omitempty" mask:"member"`
+	// Outdoor plants have their timeout adjusted for the local weather
+	Outdoor bool `json:"outdoor"`
+	// Weather is the latest weather adjustment, kept only for outdoor plants
+	Weather   *WeatherAdjustment `json:"weather,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// WeatherAdjustmentMaxAge is how long a weather adjustment applies without
+// being refreshed, so a broken forecast feed cannot skew reminders for good
+const WeatherAdjustmentMaxAge = 24 * time.Hour
+
+// WeatherAdjustment scales an outdoor plant's timeout for recent weather,
+// stretching it after rain and shrinking it in a heatwave
+type WeatherAdjustment struct {
+	Factor    float64   `json:"factor"`
+	Reason    string    `json:"reason,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Vacation is a window during which a plant is not expected to be watered
+type Vacation struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Active reports whether t falls within the vacation
+func (v *Vacation) Active(t time.Time) bool {
+	return v != nil && !t.Before(v.Start) && t.Before(v.End)
 }
 
 // PlantWateringEvent represents a single watering event
@@ -31,28 +104,89 @@ type PlantWateringEvent struct {
 	ID        int       `json:"id"`
 	PlantID   int       `json:"plant_id"`
 	WateredAt time.Time `json:"watered_at"`
-	WateredBy string    `json:"watered_by"`
+	WateredBy string    `json:"watered_by" mask:"member"`
+	// RecordedAt is when the watering was entered, which differs from
+	// WateredAt for backdated waterings. It is nil for waterings recorded
+	// before it was tracked.
+	RecordedAt *time.Time `json:"recorded_at,omitempty"`
+}
+
+// IsPaused reports whether the plant's vacation is active
+func (p *PlantState) IsPaused() bool {
+	return p.Vacation.Active(time.Now())
+}
+
+// CurrentWeather returns the plant's weather adjustment if it is outdoors
+// and the adjustment has not expired
+func (p *PlantState) CurrentWeather() *WeatherAdjustment {
+	if !p.Outdoor || p.Weather == nil || p.Weather.Factor <= 0 || time.Since(p.Weather.UpdatedAt) > WeatherAdjustmentMaxAge {
+		return nil
+	}
+	return p.Weather
+}
+
+// WeatherFactor returns what the plant's timeout is multiplied by: its
+// current weather adjustment's factor, or 1
+func (p *PlantState) WeatherFactor() float64 {
+	if weather := p.CurrentWeather(); weather != nil {
+		return weather.Factor
+	}
+	return 1
+}
+
+// EffectiveTimeout returns the plant's timeout adjusted for the weather
+func (p *PlantState) EffectiveTimeout() time.Duration {
+	return time.Duration(float64(p.TimeoutHours) * p.WeatherFactor() * float64(time.Hour))
+}
+
+// HealthThresholds returns the plant's needs water and critical thresholds
+// as percentages of its timeout, with defaults filled in
+func (p *PlantState) HealthThresholds() (needsWater, critical int) {
+	needsWater, critical = p.NeedsWaterPercent, p.CriticalPercent
+	if needsWater == 0 {
+		needsWater = DefaultNeedsWaterPercent
+	}
+	if critical == 0 {
+		critical = DefaultCriticalPercent
+	}
+	return needsWater, critical
+}
+
+// DueAfter returns how long after a watering the plant is overdue: its
+// effective timeout scaled by its critical threshold
+func (p *PlantState) DueAfter() time.Duration {
+	_, critical := p.HealthThresholds()
+	return p.EffectiveTimeout() * time.Duration(critical) / 100
 }
 
 // GetHealthStatus calculates the current health status based on last watering time
 func (p *PlantState) GetHealthStatus() PlantHealthStatus {
+	if p.IsPaused() {
+		return HealthStatusPaused
+	}
 	if p.LastWatered == nil {
 		return HealthStatusCritical
 	}
 
-	hoursSinceWatering := time.Since(*p.LastWatered).Hours()
+	sinceWatering := time.Since(*p.LastWatered)
+	needsWater, _ := p.HealthThresholds()
 
-	// Healthy: less than 50% of timeout
-	if hoursSinceWatering < float64(p.TimeoutHours)*0.5 {
+	// Healthy: less than the needs water share of the timeout, 50% by default
+	if sinceWatering < p.EffectiveTimeout()*time.Duration(needsWater)/100 {
 		return HealthStatusHealthy
 	}
 
-	// Needs water: between 50% and 100% of timeout
-	if hoursSinceWatering < float64(p.TimeoutHours) {
+	// Needs water: until the critical share of the timeout, 100% by default
+	if sinceWatering < p.DueAfter() {
 		return HealthStatusNeedsWater
 	}
 
-	// Critical: past timeout
+	// Due: past timeout but still within the grace period
+	if sinceWatering < p.DueAfter()+time.Duration(p.GracePeriodHours)*time.Hour {
+		return HealthStatusDue
+	}
+
+	// Critical: past timeout and grace period
 	return HealthStatusCritical
 }
 
@@ -76,13 +210,41 @@ func (p *PlantState) GetHoursSinceWatering() *float64 {
 	return &hours
 }
 
-// IsOverdue returns true if the plant is past its watering timeout
+// IsOverdue returns true if the plant is past its watering timeout. Paused
+// plants are never overdue.
 func (p *PlantState) IsOverdue() bool {
+	if p.IsPaused() {
+		return false
+	}
 	if p.LastWatered == nil {
 		return true
 	}
 
-	return time.Since(*p.LastWatered).Hours() > float64(p.TimeoutHours)
+	return time.Since(*p.LastWatered) > p.DueAfter()
+}
+
+// IsCritical returns true if the plant is past both its timeout and grace
+// period. Paused plants are never critical.
+func (p *PlantState) IsCritical() bool {
+	if p.IsPaused() {
+		return false
+	}
+	if p.LastWatered == nil {
+		return true
+	}
+
+	return time.Since(*p.LastWatered) >= p.DueAfter()+time.Duration(p.GracePeriodHours)*time.Hour
+}
+
+// GetNotificationTrigger returns the reminder milestone the plant has reached
+func (p *PlantState) GetNotificationTrigger() NotificationTrigger {
+	if p.IsCritical() {
+		return NotificationTriggerCritical
+	}
+	if p.IsOverdue() {
+		return NotificationTriggerDue
+	}
+	return NotificationTriggerNone
 }
 
 // GetTimeUntilDue returns duration until watering is due (negative if overdue)
@@ -91,40 +253,40 @@ func (p *PlantState) GetTimeUntilDue() *time.Duration {
 		return nil
 	}
 
-	nextWateringTime := p.LastWatered.Add(time.Duration(p.TimeoutHours) * time.Hour)
+	nextWateringTime := p.LastWatered.Add(p.DueAfter())
 	timeUntilDue := time.Until(nextWateringTime)
 	return &timeUntilDue
 }
 
 // GetFormattedTimeSinceWatering returns a human-readable string of time since watering
 func (p *PlantState) GetFormattedTimeSinceWatering() string {
-	if p.LastWatered == nil {
-		return "Never watered"
+	return p.FormatTimeSinceWatering(nil)
+}
+
+// FormatTimeSinceWatering returns the time since watering in printer's
+// language, such as "3 hours ago"
+func (p *PlantState) FormatTimeSinceWatering(printer *i18n.Printer) string {
+	return FormatTimeSince(printer, p.LastWatered)
+}
+
+// FormatTimeSince returns the time since lastWatered in printer's language
+func FormatTimeSince(printer *i18n.Printer, lastWatered *time.Time) string {
+	if lastWatered == nil {
+		return printer.T("time.never_watered")
 	}
 
-	duration := time.Since(*p.LastWatered)
+	duration := time.Since(*lastWatered)
 
 	if duration.Hours() < 1 {
-		minutes := int(duration.Minutes())
-		if minutes == 1 {
-			return "1 minute ago"
-		}
-		return fmt.Sprintf("%d minutes ago", minutes)
+		return printer.N("time.minutes_ago", int(duration.Minutes()))
 	}
 
 	hours := int(duration.Hours())
-	if hours == 1 {
-		return "1 hour ago"
-	}
 	if hours < 24 {
-		return fmt.Sprintf("%d hours ago", hours)
+		return printer.N("time.hours_ago", hours)
 	}
 
-	days := int(duration.Hours() / 24)
-	if days == 1 {
-		return "1 day ago"
-	}
-	return fmt.Sprintf("%d days ago", days)
+	return printer.N("time.days_ago", int(duration.Hours()/24))
 }
 
 // Validate checks if the plant state is valid
@@ -141,22 +303,72 @@ func (p *PlantState) Validate() error {
 		return fmt.Errorf("timeout hours cannot exceed 8760 (1 year)")
 	}
 
+	if p.GracePeriodHours < 0 {
+		return fmt.Errorf("grace period hours cannot be negative")
+	}
+
+	if p.GracePeriodHours > 8760 {
+		return fmt.Errorf("grace period hours cannot exceed 8760 (1 year)")
+	}
+
+	if p.NeedsWaterPercent < 0 || p.NeedsWaterPercent > MaxThresholdPercent || p.CriticalPercent < 0 || p.CriticalPercent > MaxThresholdPercent {
+		return fmt.Errorf("health thresholds must be between 0 and %d percent", MaxThresholdPercent)
+	}
+
+	if needsWater, critical := p.HealthThresholds(); needsWater >= critical {
+		return fmt.Errorf("needs water threshold (%d%%) must be below the critical threshold (%d%%)", needsWater, critical)
+	}
+
 	return nil
 }
 
 // User represents a user in the system
 type User struct {
-	Email    string    `json:"email"`
-	Name     string    `json:"name"`
-	IsAdmin  bool      `json:"is_admin"`
+	Email   string `json:"email" mask:"member"`
+	Name    string `json:"name"`
+	IsAdmin bool   `json:"is_admin"`
+	// Role is "admin", "member" or "viewer". Viewers, such as a plant
+	// sitter, can look but not water. Empty means member, or admin when
+	// IsAdmin is set.
+	Role     string    `json:"role,omitempty"`
 	JoinedAt time.Time `json:"joined_at"`
+	// Locale is the language the user chose for pages and messages, such
+	// as "es"; empty follows the browser's Accept-Language
+	Locale string `json:"locale,omitempty"`
+}
+
+// AnonymousWaterer is shown instead of the waterer's identity when privacy mode is on
+const AnonymousWaterer = "a household member"
+
+// DemoUser is a user offered on the demo login form
+type DemoUser struct {
+	Email string `json:"email"`
+	// Name is the display name used unless the form gives another
+	Name string `json:"name,omitempty"`
 }
 
 // AdminConfig represents system configuration
 type AdminConfig struct {
-	TimeoutHours  int       `json:"timeout_hours"`
-	AllowedEmails []string  `json:"allowed_emails"`
-	AdminEmails   []string  `json:"admin_emails"`
-	LastModified  time.Time `json:"last_modified"`
-	ModifiedBy    string    `json:"modified_by"`
+	TimeoutHours  int      `json:"timeout_hours"`
+	AllowedEmails []string `json:"allowed_emails" mask:"admin"`
+	AdminEmails   []string `json:"admin_emails" mask:"admin"`
+	// ViewerEmails are allowed users with the read-only viewer role
+	ViewerEmails []string `json:"viewer_emails,omitempty" mask:"admin"`
+	// PrivacyMode hides who watered the plant from non-admin users
+	PrivacyMode bool `json:"privacy_mode"`
+	// Timezone is the IANA timezone of the household, e.g. "Europe/Berlin"
+	Timezone string `json:"timezone,omitempty"`
+	// DemoUsers are offered on the demo login form in place of DEMO_USERS.
+	// They stay nil until an admin edits them, and an empty list offers
+	// nobody.
+	DemoUsers []DemoUser `json:"demo_users" mask:"admin"`
+	// Features switches feature flags on or off, overriding their
+	// FEATURE_* defaults. Flags not listed follow the default.
+	Features map[string]bool `json:"features,omitempty"`
+	// OAuth credentials set through the setup wizard
+	GoogleClientID     string    `json:"google_client_id,omitempty"`
+	GoogleClientSecret string    `json:"google_client_secret,omitempty" mask:"admin"`
+	SetupCompleted     bool      `json:"setup_completed"`
+	LastModified       time.Time `json:"last_modified"`
+	ModifiedBy         string    `json:"modified_by" mask:"admin"`
 }
@@ -3,6 +3,8 @@ package models
 import (
 	"testing"
 	"time"
+
+	"watered/internal/i18n"
 )
 
 func TestPlantState_GetHealthStatus(t *testing.T) {
@@ -53,6 +55,180 @@ func TestPlantState_GetHealthStatus(t *testing.T) {
 	}
 }
 
+func TestPlantState_GracePeriod(t *testing.T) {
+	tests := []struct {
+		name            string
+		lastWatered     *time.Time
+		gracePeriod     int
+		expectedStatus  PlantHealthStatus
+		expectedTrigger NotificationTrigger
+	}{
+		{
+			name:            "Before timeout",
+			lastWatered:     timePtr(time.Now().Add(-1 * time.Hour)),
+			gracePeriod:     12,
+			expectedStatus:  HealthStatusHealthy,
+			expectedTrigger: NotificationTriggerNone,
+		},
+		{
+			name:            "Past timeout within grace period",
+			lastWatered:     timePtr(time.Now().Add(-30 * time.Hour)),
+			gracePeriod:     12,
+			expectedStatus:  HealthStatusDue,
+			expectedTrigger: NotificationTriggerDue,
+		},
+		{
+			name:            "Past grace period",
+			lastWatered:     timePtr(time.Now().Add(-37 * time.Hour)),
+			gracePeriod:     12,
+			expectedStatus:  HealthStatusCritical,
+			expectedTrigger: NotificationTriggerCritical,
+		},
+		{
+			name:            "No grace period goes straight to critical",
+			lastWatered:     timePtr(time.Now().Add(-25 * time.Hour)),
+			gracePeriod:     0,
+			expectedStatus:  HealthStatusCritical,
+			expectedTrigger: NotificationTriggerCritical,
+		},
+		{
+			name:            "Never watered",
+			lastWatered:     nil,
+			gracePeriod:     12,
+			expectedStatus:  HealthStatusCritical,
+			expectedTrigger: NotificationTriggerCritical,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant := &PlantState{
+				LastWatered:      tt.lastWatered,
+				TimeoutHours:     24,
+				GracePeriodHours: tt.gracePeriod,
+			}
+
+			if status := plant.GetHealthStatus(); status != tt.expectedStatus {
+				t.Errorf("Expected health status %s, got %s", tt.expectedStatus, status)
+			}
+			if trigger := plant.GetNotificationTrigger(); trigger != tt.expectedTrigger {
+				t.Errorf("Expected trigger %q, got %q", tt.expectedTrigger, trigger)
+			}
+		})
+	}
+}
+
+func TestPlantState_Vacation(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name            string
+		vacation        *Vacation
+		expectedStatus  PlantHealthStatus
+		expectedTrigger NotificationTrigger
+	}{
+		{"no vacation", nil, HealthStatusCritical, NotificationTriggerCritical},
+		{"active", &Vacation{Start: now.Add(-time.Hour), End: now.Add(time.Hour)}, HealthStatusPaused, NotificationTriggerNone},
+		{"not started", &Vacation{Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)}, HealthStatusCritical, NotificationTriggerCritical},
+		{"ended", &Vacation{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)}, HealthStatusCritical, NotificationTriggerCritical},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant := &PlantState{
+				LastWatered:  timePtr(now.Add(-72 * time.Hour)),
+				TimeoutHours: 24,
+				Vacation:     tt.vacation,
+			}
+
+			if status := plant.GetHealthStatus(); status != tt.expectedStatus {
+				t.Errorf("Expected health status %s, got %s", tt.expectedStatus, status)
+			}
+			if trigger := plant.GetNotificationTrigger(); trigger != tt.expectedTrigger {
+				t.Errorf("Expected trigger %q, got %q", tt.expectedTrigger, trigger)
+			}
+			if paused := tt.expectedStatus == HealthStatusPaused; plant.IsOverdue() == paused || plant.IsCritical() == paused {
+				t.Errorf("Expected overdue and critical to be %v, got %v and %v", !paused, plant.IsOverdue(), plant.IsCritical())
+			}
+		})
+	}
+}
+
+func TestPlantState_WeatherFactor(t *testing.T) {
+	now := time.Now()
+	rainy := &WeatherAdjustment{Factor: 1.5, Reason: "12.0 mm of rain in 2 days", UpdatedAt: now.Add(-time.Hour)}
+	tests := []struct {
+		name           string
+		outdoor        bool
+		weather        *WeatherAdjustment
+		expectedFactor float64
+		expectedStatus PlantHealthStatus
+	}{
+		{"indoor", false, rainy, 1, HealthStatusDue},
+		{"outdoor without forecast", true, nil, 1, HealthStatusDue},
+		{"outdoor after rain", true, rainy, 1.5, HealthStatusNeedsWater},
+		{"outdoor in a heatwave", true, &WeatherAdjustment{Factor: 0.5, UpdatedAt: now}, 0.5, HealthStatusCritical},
+		{"expired adjustment", true, &WeatherAdjustment{Factor: 1.5, UpdatedAt: now.Add(-WeatherAdjustmentMaxAge - time.Hour)}, 1, HealthStatusDue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 30 hours since watering: due at 24h, critical from 36h
+			plant := &PlantState{
+				LastWatered:      timePtr(now.Add(-30 * time.Hour)),
+				TimeoutHours:     24,
+				GracePeriodHours: 12,
+				Outdoor:          tt.outdoor,
+				Weather:          tt.weather,
+			}
+
+			if factor := plant.WeatherFactor(); factor != tt.expectedFactor {
+				t.Errorf("Expected factor %v, got %v", tt.expectedFactor, factor)
+			}
+			if timeout := plant.EffectiveTimeout(); timeout != time.Duration(24*tt.expectedFactor*float64(time.Hour)) {
+				t.Errorf("Expected effective timeout of %v hours, got %v", 24*tt.expectedFactor, timeout)
+			}
+			if status := plant.GetHealthStatus(); status != tt.expectedStatus {
+				t.Errorf("Expected health status %s, got %s", tt.expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestPlantState_HealthThresholds(t *testing.T) {
+	tests := []struct {
+		name              string
+		needsWaterPercent int
+		criticalPercent   int
+		hoursSince        float64
+		expectedStatus    PlantHealthStatus
+	}{
+		{"default healthy", 0, 0, 11, HealthStatusHealthy},
+		{"default needs water", 0, 0, 13, HealthStatusNeedsWater},
+		{"late needs water", 75, 0, 13, HealthStatusHealthy},
+		{"late critical", 0, 150, 30, HealthStatusNeedsWater},
+		{"past late critical", 0, 150, 37, HealthStatusCritical},
+		{"early critical", 25, 80, 20, HealthStatusCritical},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant := &PlantState{
+				LastWatered:       timePtr(time.Now().Add(-time.Duration(tt.hoursSince * float64(time.Hour)))),
+				TimeoutHours:      24,
+				NeedsWaterPercent: tt.needsWaterPercent,
+				CriticalPercent:   tt.criticalPercent,
+			}
+
+			if status := plant.GetHealthStatus(); status != tt.expectedStatus {
+				t.Errorf("Expected health status %s, got %s", tt.expectedStatus, status)
+			}
+			if critical := tt.expectedStatus == HealthStatusCritical; plant.IsOverdue() != critical || plant.IsCritical() != critical {
+				t.Errorf("Expected overdue and critical to be %v, got %v and %v", critical, plant.IsOverdue(), plant.IsCritical())
+			}
+		})
+	}
+}
+
 func TestPlantState_IsOverdue(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -158,6 +334,26 @@ func TestPlantState_GetFormattedTimeSinceWatering(t *testing.T) {
 	}
 }
 
+func TestPlantState_FormatTimeSinceWatering(t *testing.T) {
+	spanish := i18n.Get("es")
+	tests := []struct {
+		lastWatered *time.Time
+		expected    string
+	}{
+		{nil, "Nunca regada"},
+		{timePtr(time.Now().Add(-1 * time.Minute)), "hace 1 minuto"},
+		{timePtr(time.Now().Add(-5 * time.Hour)), "hace 5 horas"},
+		{timePtr(time.Now().Add(-72 * time.Hour)), "hace 3 días"},
+	}
+
+	for _, tt := range tests {
+		plant := &PlantState{LastWatered: tt.lastWatered}
+		if result := plant.FormatTimeSinceWatering(spanish); result != tt.expected {
+			t.Errorf("Expected formatted time %s, got %s", tt.expected, result)
+		}
+	}
+}
+
 func TestPlantState_Validate(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -204,6 +400,43 @@ func TestPlantState_Validate(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "Negative grace period",
+			plant: PlantState{
+				Name:             "Test Plant",
+				TimeoutHours:     24,
+				GracePeriodHours: -1,
+			},
+			expectErr: true,
+		},
+		{
+			name: "Custom thresholds",
+			plant: PlantState{
+				Name:              "Test Plant",
+				TimeoutHours:      24,
+				NeedsWaterPercent: 75,
+				CriticalPercent:   150,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Needs water threshold above default critical",
+			plant: PlantState{
+				Name:              "Test Plant",
+				TimeoutHours:      24,
+				NeedsWaterPercent: 120,
+			},
+			expectErr: true,
+		},
+		{
+			name: "Threshold too large",
+			plant: PlantState{
+				Name:            "Test Plant",
+				TimeoutHours:    24,
+				CriticalPercent: MaxThresholdPercent + 1,
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
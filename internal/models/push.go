@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// PushSubscription is a browser's Web Push subscription for a user
+type PushSubscription struct {
+	UserEmail string    `json:"user_email" mask:"member"`
+	Endpoint  string    `json:"endpoint" mask:"member"`
+	P256dh    string    `json:"p256dh" mask:"member"`
+	Auth      string    `json:"auth" mask:"member"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// SensorReading is one measurement reported by a soil sensor in a plant's pot.
+// A device may report either value or both.
+type SensorReading struct {
+	ID       int    `json:"id"`
+	DeviceID string `json:"device_id"`
+	PlantID  int    `json:"plant_id"`
+	// Moisture is the soil moisture in percent, 0 being bone dry
+	Moisture *float64 `json:"moisture,omitempty"`
+	// Temperature is the soil temperature in degrees Celsius
+	Temperature *float64  `json:"temperature,omitempty"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+// SensorWaterer is recorded as the waterer of waterings a sensor detected
+const SensorWaterer = "sensor"
+
+// SensorReadingFilter narrows a sensor reading query. Empty fields match everything.
+type SensorReadingFilter struct {
+	PlantID  int
+	DeviceID string
+	// Since excludes readings recorded before it
+	Since time.Time
+	Limit int
+}
+
+// Matches reports whether the reading satisfies the filter
+func (f SensorReadingFilter) Matches(r *SensorReading) bool {
+	if f.PlantID != 0 && r.PlantID != f.PlantID {
+		return false
+	}
+	if f.DeviceID != "" && r.DeviceID != f.DeviceID {
+		return false
+	}
+	if !f.Since.IsZero() && r.RecordedAt.Before(f.Since) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Session is a browser session kept on the server. The session cookie only
+// carries an opaque random ID; Session.ID is the hex SHA-256 of that ID, so
+// stored and listed sessions cannot be used to sign in.
+type Session struct {
+	ID      string `json:"id"`
+	UserID  string `json:"user_id,omitempty" mask:"admin"`
+	Email   string `json:"email,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Picture string `json:"picture,omitempty"`
+	IsAdmin bool   `json:"is_admin"`
+	// Role is the user's role when they signed in; see User.Role
+	Role string `json:"role,omitempty"`
+	// Authenticated is true for every stored session; sessions are only
+	// stored once the user has signed in
+	Authenticated bool `json:"authenticated"`
+	// Recovery marks short-lived admin sessions granted by a recovery token
+	Recovery bool `json:"recovery,omitempty"`
+	// App marks sessions of a native app signed in with bearer tokens. They
+	// are stored under the hash of the refresh token instead of a cookie.
+	App        bool      `json:"app,omitempty"`
+	CSRFToken  string    `json:"csrf_token,omitempty" mask:"admin"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	RemoteAddr string    `json:"remote_addr,omitempty" mask:"admin"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeen   time.Time `json:"last_seen"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// AppTokens are the bearer tokens POST /auth/token issues to native apps.
+// The access token authenticates /api requests until it expires; the
+// refresh token is exchanged for a new pair.
+type AppTokens struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	// RefreshExpiresIn is how many seconds the refresh token stays valid
+	RefreshExpiresIn int `json:"refresh_expires_in"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ShareLink gives anyone holding its token a read-only view of one plant's
+// status without signing in, such as a relative checking on the plant. Only
+// a hash of the token is stored; the token itself is shown once when the
+// link is created.
+type ShareLink struct {
+	ID string `json:"id"`
+	// Name says who the link was made for, such as "Grandma"
+	Name    string `json:"name"`
+	PlantID int    `json:"plant_id"`
+	// TokenHash is the hex SHA-256 of the link's token
+	TokenHash string    `json:"token_hash,omitempty" mask:"admin"`
+	CreatedBy string    `json:"created_by" mask:"admin"`
+	CreatedAt time.Time `json:"created_at"`
+}
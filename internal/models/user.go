@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// UserMergeResult describes what merging one user account into another changes
+type UserMergeResult struct {
+	FromEmail  string         `json:"from_email" mask:"admin"`
+	ToEmail    string         `json:"to_email" mask:"admin"`
+	DryRun     bool           `json:"dry_run"`
+	Reassigned map[string]int `json:"reassigned"`
+	Changes    []string       `json:"changes"`
+}
+
+// UserActivity records when a signed-in user was last seen and from which
+// client, so admins can tell who actually uses the app
+type UserActivity struct {
+	Email     string    `json:"email" mask:"admin"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	UserAgent string    `json:"user_agent,omitempty" mask:"admin"`
+	ClientIP  string    `json:"client_ip,omitempty" mask:"admin"`
+}
+
+// DeletedUserPrefix starts the placeholder that replaces a deleted user's
+// email in the history they leave behind, such as "deleted-3f9a0c1e5b7d2a64"
+const DeletedUserPrefix = "deleted-"
+
+// AccountExport holds everything stored about one user, for them to download
+type AccountExport struct {
+	ExportedAt time.Time `json:"exported_at"`
+	Email      string    `json:"email"`
+	// Role is what the user can currently do, admin, member or viewer
+	Role string `json:"role"`
+	// User is the stored user record, nil if none was stored yet
+	User              *User                 `json:"user"`
+	Activity          *UserActivity         `json:"activity"`
+	Waterings         []*PlantWateringEvent `json:"waterings"`
+	CareTaskEvents    []*CareTaskEvent      `json:"care_task_events"`
+	Notifications     []*Notification       `json:"notifications"`
+	PushSubscriptions []*PushSubscription   `json:"push_subscriptions"`
+}
+
+// AccountDeletion describes what deleting a user's account changed
+type AccountDeletion struct {
+	Email string `json:"email" mask:"admin"`
+	// ReplacedBy is the placeholder left in the user's history
+	ReplacedBy string         `json:"replaced_by"`
+	Anonymized map[string]int `json:"anonymized"`
+	Deleted    map[string]int `json:"deleted"`
+}
@@ -0,0 +1,206 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+// CapacitySampleInterval is how often storage size is sampled to estimate
+// its growth rate
+const CapacitySampleInterval = 15 * time.Minute
+
+const (
+	// capacityWindow is how far back growth and event rates are averaged
+	capacityWindow = 7 * 24 * time.Hour
+	// minGrowthWindow is how much sample history is needed before a growth
+	// rate is reported
+	minGrowthWindow = time.Hour
+	// minSampleSpacing keeps frequent health checks from piling up samples
+	minSampleSpacing = time.Minute
+)
+
+// CapacityMetrics describes disk usage and how fast it is growing
+type CapacityMetrics struct {
+	StorageBytes      int64    `json:"storage_bytes"`
+	PlantCount        int      `json:"plant_count"`
+	NotificationCount int      `json:"notification_count"`
+	EventsPerDay      float64  `json:"events_per_day"`
+	GrowthBytesPerDay float64  `json:"growth_bytes_per_day"`
+	DiskFreeBytes     uint64   `json:"disk_free_bytes,omitempty"`
+	DiskTotalBytes    uint64   `json:"disk_total_bytes,omitempty"`
+	DaysUntilFull     *float64 `json:"days_until_full,omitempty"`
+	Error             string   `json:"error,omitempty"`
+}
+
+// capacitySample is the storage size observed at a point in time
+type capacitySample struct {
+	at    time.Time
+	bytes int64
+}
+
+// CapacityMonitor tracks the size of the data files and the free space on
+// the disk holding them, and warns before the disk fills up
+type CapacityMonitor struct {
+	storage   storage.Storage
+	paths     []string
+	warnDays  int
+	now       func() time.Time
+	diskUsage func(path string) (free, total uint64, err error)
+
+	mu      sync.Mutex
+	samples []capacitySample
+}
+
+// NewCapacityMonitor creates a capacity monitor for the given data files.
+// The health check is degraded when the disk is projected to fill within
+// warnDays; zero disables the warning.
+func NewCapacityMonitor(store storage.Storage, warnDays int, paths ...string) *CapacityMonitor {
+	var files []string
+	for _, path := range paths {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+
+	return &CapacityMonitor{
+		storage:   store,
+		paths:     files,
+		warnDays:  warnDays,
+		now:       time.Now,
+		diskUsage: diskUsage,
+	}
+}
+
+// Metrics measures current usage, records it as a sample and projects when
+// the disk will be full at the observed growth rate
+func (c *CapacityMonitor) Metrics() CapacityMetrics {
+	now := c.now()
+	var metrics CapacityMetrics
+
+	for _, path := range c.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				metrics.Error = err.Error()
+			}
+			continue
+		}
+		metrics.StorageBytes += info.Size()
+	}
+
+	if plants, err := c.storage.ListPlants(); err == nil {
+		metrics.PlantCount = len(plants)
+	}
+	if notifications, err := c.storage.ListNotifications(models.NotificationFilter{}); err == nil {
+		metrics.NotificationCount = len(notifications)
+		since := now.Add(-capacityWindow)
+		recent := 0
+		for _, n := range notifications {
+			if n.CreatedAt.After(since) {
+				recent++
+			}
+		}
+		metrics.EventsPerDay = float64(recent) / capacityWindow.Hours() * 24
+	}
+
+	metrics.GrowthBytesPerDay = c.record(now, metrics.StorageBytes)
+
+	free, total, err := c.diskUsage(c.diskPath())
+	if err != nil {
+		metrics.Error = err.Error()
+		return metrics
+	}
+	metrics.DiskFreeBytes = free
+	metrics.DiskTotalBytes = total
+
+	if metrics.GrowthBytesPerDay > 0 {
+		days := float64(free) / metrics.GrowthBytesPerDay
+		metrics.DaysUntilFull = &days
+	}
+	return metrics
+}
+
+// record adds a sample, drops those outside the averaging window and returns
+// the growth in bytes per day across the remaining samples. Shrinking after
+// a journal compaction counts as no growth.
+func (c *CapacityMonitor) record(now time.Time, bytes int64) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.samples) == 0 || now.Sub(c.samples[len(c.samples)-1].at) >= minSampleSpacing {
+		c.samples = append(c.samples, capacitySample{at: now, bytes: bytes})
+	}
+
+	cutoff := now.Add(-capacityWindow)
+	keep := 0
+	for keep < len(c.samples)-1 && c.samples[keep].at.Before(cutoff) {
+		keep++
+	}
+	c.samples = c.samples[keep:]
+
+	oldest := c.samples[0]
+	elapsed := now.Sub(oldest.at)
+	if elapsed < minGrowthWindow || bytes <= oldest.bytes {
+		return 0
+	}
+	return float64(bytes-oldest.bytes) / elapsed.Hours() * 24
+}
+
+// diskPath is the directory whose file system holds the data files
+func (c *CapacityMonitor) diskPath() string {
+	if len(c.paths) == 0 {
+		return "."
+	}
+	return filepath.Dir(c.paths[0])
+}
+
+// Name returns the name of this health checker
+func (c *CapacityMonitor) Name() string {
+	return "capacity"
+}
+
+// Check reports whether the disk is projected to fill within the warning
+// window
+func (c *CapacityMonitor) Check(ctx context.Context) ComponentHealth {
+	start := time.Now()
+	health := ComponentHealth{
+		Name:        c.Name(),
+		LastChecked: start,
+	}
+
+	metrics := c.Metrics()
+	health.Details = map[string]interface{}{
+		"storage_bytes":        metrics.StorageBytes,
+		"growth_bytes_per_day": metrics.GrowthBytesPerDay,
+		"disk_free_bytes":      metrics.DiskFreeBytes,
+	}
+
+	switch {
+	case metrics.DiskTotalBytes == 0:
+		health.Status = HealthStatusHealthy
+		health.Message = "Disk usage unavailable"
+	case metrics.DaysUntilFull == nil:
+		health.Status = HealthStatusHealthy
+		health.Message = "Storage is not growing"
+	default:
+		days := *metrics.DaysUntilFull
+		health.Details["days_until_full"] = days
+		health.Status = HealthStatusHealthy
+		if days < 1 {
+			health.Status = HealthStatusUnhealthy
+		} else if c.warnDays > 0 && days < float64(c.warnDays) {
+			health.Status = HealthStatusDegraded
+		}
+		health.Message = fmt.Sprintf("Disk projected to fill in %.0f days at current growth", days)
+	}
+
+	health.Duration = time.Since(start)
+	return health
+}
@@ -0,0 +1,159 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCapacityMonitor returns a monitor over a temporary data file with a
+// controllable clock and a disk reporting free bytes
+func newTestCapacityMonitor(t *testing.T, warnDays int, free uint64) (*CapacityMonitor, string, *time.Time) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "watered.json")
+	require.NoError(t, os.WriteFile(path, make([]byte, 1000), 0o600))
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	monitor := NewCapacityMonitor(storage.NewMemoryStorage(), warnDays, path, "")
+	monitor.now = func() time.Time { return now }
+	monitor.diskUsage = func(string) (uint64, uint64, error) { return free, 1 << 30, nil }
+	return monitor, path, &now
+}
+
+func TestCapacityMonitorGrowth(t *testing.T) {
+	monitor, path, now := newTestCapacityMonitor(t, 30, 10000)
+
+	metrics := monitor.Metrics()
+	assert.Equal(t, int64(1000), metrics.StorageBytes)
+	assert.Zero(t, metrics.GrowthBytesPerDay)
+	assert.Nil(t, metrics.DaysUntilFull)
+
+	// 500 bytes over half a day is 1000 bytes per day, filling 10000 free bytes in 10 days
+	*now = now.Add(12 * time.Hour)
+	require.NoError(t, os.WriteFile(path, make([]byte, 1500), 0o600))
+
+	metrics = monitor.Metrics()
+	assert.Equal(t, int64(1500), metrics.StorageBytes)
+	assert.InDelta(t, 1000, metrics.GrowthBytesPerDay, 0.01)
+	require.NotNil(t, metrics.DaysUntilFull)
+	assert.InDelta(t, 10, *metrics.DaysUntilFull, 0.01)
+
+	health := monitor.Check(context.Background())
+	assert.Equal(t, "capacity", health.Name)
+	assert.Equal(t, HealthStatusDegraded, health.Status)
+	assert.Contains(t, health.Message, "fill in 10 days")
+}
+
+func TestCapacityMonitorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		warnDays int
+		free     uint64
+		want     HealthStatus
+	}{
+		{"plenty of space", 30, 1 << 30, HealthStatusHealthy},
+		{"within warning window", 30, 10000, HealthStatusDegraded},
+		{"warning disabled", 0, 10000, HealthStatusHealthy},
+		{"full within a day", 30, 500, HealthStatusUnhealthy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor, path, now := newTestCapacityMonitor(t, tt.warnDays, tt.free)
+			monitor.Metrics()
+
+			*now = now.Add(24 * time.Hour)
+			require.NoError(t, os.WriteFile(path, make([]byte, 2000), 0o600))
+
+			health := monitor.Check(context.Background())
+			assert.Equal(t, tt.want, health.Status)
+		})
+	}
+}
+
+func TestCapacityMonitorShrinking(t *testing.T) {
+	monitor, path, now := newTestCapacityMonitor(t, 30, 100)
+	monitor.Metrics()
+
+	// A journal compaction shrinks the file, which is not growth
+	*now = now.Add(24 * time.Hour)
+	require.NoError(t, os.WriteFile(path, make([]byte, 200), 0o600))
+
+	metrics := monitor.Metrics()
+	assert.Zero(t, metrics.GrowthBytesPerDay)
+	assert.Nil(t, metrics.DaysUntilFull)
+
+	health := monitor.Check(context.Background())
+	assert.Equal(t, HealthStatusHealthy, health.Status)
+	assert.Equal(t, "Storage is not growing", health.Message)
+}
+
+func TestCapacityMonitorWindow(t *testing.T) {
+	monitor, path, now := newTestCapacityMonitor(t, 30, 1<<30)
+	monitor.Metrics()
+
+	// Growth older than the averaging window no longer counts
+	*now = now.Add(time.Hour)
+	require.NoError(t, os.WriteFile(path, make([]byte, 5000), 0o600))
+	monitor.Metrics()
+
+	*now = now.Add(capacityWindow)
+	metrics := monitor.Metrics()
+	assert.Zero(t, metrics.GrowthBytesPerDay)
+}
+
+func TestCapacityMonitorEvents(t *testing.T) {
+	monitor, _, now := newTestCapacityMonitor(t, 30, 1<<30)
+
+	for i, age := range []time.Duration{time.Hour, 2 * 24 * time.Hour, 30 * 24 * time.Hour} {
+		require.NoError(t, monitor.storage.CreateNotification(&models.Notification{
+			UserEmail: "user@example.com",
+			Channel:   "email",
+			Summary:   "reminder",
+			CreatedAt: now.Add(-age),
+		}), "notification %d", i)
+	}
+
+	metrics := monitor.Metrics()
+	assert.Equal(t, 3, metrics.NotificationCount)
+	assert.Zero(t, metrics.PlantCount)
+	assert.InDelta(t, 2.0/7, metrics.EventsPerDay, 0.001)
+}
+
+func TestCapacityMonitorDiskUnavailable(t *testing.T) {
+	monitor, _, _ := newTestCapacityMonitor(t, 30, 0)
+	monitor.diskUsage = func(string) (uint64, uint64, error) {
+		return 0, 0, errors.New("not supported")
+	}
+
+	metrics := monitor.Metrics()
+	assert.Equal(t, "not supported", metrics.Error)
+
+	health := monitor.Check(context.Background())
+	assert.Equal(t, HealthStatusHealthy, health.Status)
+	assert.Equal(t, "Disk usage unavailable", health.Message)
+}
+
+func TestHealthMonitorCapacity(t *testing.T) {
+	monitor := NewHealthMonitor("test-1.0.0")
+	report := monitor.CheckHealth(context.Background())
+	assert.Nil(t, report.System.Capacity)
+
+	capacity, _, _ := newTestCapacityMonitor(t, 30, 1<<30)
+	monitor.SetCapacityMonitor(capacity)
+
+	report = monitor.CheckHealth(context.Background())
+	require.NotNil(t, report.System.Capacity)
+	assert.Equal(t, int64(1000), report.System.Capacity.StorageBytes)
+	assert.Contains(t, report.Components, "capacity")
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package monitoring
+
+import "errors"
+
+// diskUsage is not implemented on this platform
+func diskUsage(path string) (free, total uint64, err error) {
+	return 0, 0, errors.New("disk usage is not supported on this platform")
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin
+
+package monitoring
+
+import "syscall"
+
+// diskUsage returns the bytes available to unprivileged users and the total
+// size of the file system holding path
+func diskUsage(path string) (free, total uint64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), stat.Blocks * uint64(stat.Bsize), nil
+}
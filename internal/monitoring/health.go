@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"strconv"
 	"sync"
 	"time"
 
@@ -44,11 +45,15 @@ type HealthReport struct {
 
 // SystemMetrics represents system-level metrics
 type SystemMetrics struct {
-	MemoryUsage  MemoryMetrics `json:"memory"`
-	GoRoutines   int           `json:"goroutines"`
-	CGOCalls     int64         `json:"cgo_calls"`
-	GCStats      GCMetrics     `json:"gc_stats"`
-	OpenFileDesc int           `json:"open_file_descriptors,omitempty"`
+	MemoryUsage  MemoryMetrics    `json:"memory"`
+	GoRoutines   int              `json:"goroutines"`
+	CGOCalls     int64            `json:"cgo_calls"`
+	GCStats      GCMetrics        `json:"gc_stats"`
+	OpenFileDesc int              `json:"open_file_descriptors,omitempty"`
+	Capacity     *CapacityMetrics `json:"capacity,omitempty"`
+	// SampledAt is when the metrics were read, which is up to twice the
+	// metrics interval before the report
+	SampledAt time.Time `json:"sampled_at"`
 }
 
 // MemoryMetrics represents memory usage metrics
@@ -77,23 +82,199 @@ type HealthChecker interface {
 	Name() string
 }
 
+// DefaultCacheTTL is how long HTTPHandler serves a health report before
+// running the checks again
+const DefaultCacheTTL = 5 * time.Second
+
+// DefaultMetricsInterval is how often the system metrics are sampled. Reading
+// them calls runtime.ReadMemStats, which stops the world.
+const DefaultMetricsInterval = 15 * time.Second
+
+// DefaultMinDwellTime is how long a component must report a better status
+// before the monitor stops reporting the previous, worse one
+const DefaultMinDwellTime = 30 * time.Second
+
+// severity orders health statuses from best to worst
+func severity(status HealthStatus) int {
+	switch status {
+	case HealthStatusHealthy:
+		return 0
+	case HealthStatusDegraded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// componentState tracks the stabilized status of a component between checks
+type componentState struct {
+	status       HealthStatus
+	pending      HealthStatus
+	pendingSince time.Time
+	hasPending   bool
+}
+
+// healthFlight is a run of the health checks that concurrent requests share
+type healthFlight struct {
+	done   chan struct{}
+	report *HealthReport
+}
+
 // HealthMonitor manages health checks for the application
 type HealthMonitor struct {
-	checkers  map[string]HealthChecker
-	startTime time.Time
-	version   string
-	mu        sync.RWMutex
+	checkers     map[string]HealthChecker
+	states       map[string]*componentState
+	minDwellTime time.Duration
+	capacity     *CapacityMonitor
+	startTime    time.Time
+	version      string
+	mu           sync.RWMutex
+
+	historyStore     storage.Storage
+	historyInterval  time.Duration
+	historyRetention time.Duration
+
+	cacheTTL time.Duration
+	cached   *HealthReport
+	inflight *healthFlight
+	cacheMu  sync.Mutex
+
+	metricsInterval time.Duration
+	metrics         *SystemMetrics
+	memStats        runtime.MemStats
+	metricsMu       sync.Mutex
 }
 
 // NewHealthMonitor creates a new health monitor
 func NewHealthMonitor(version string) *HealthMonitor {
 	return &HealthMonitor{
-		checkers:  make(map[string]HealthChecker),
-		startTime: time.Now(),
-		version:   version,
+		checkers:        make(map[string]HealthChecker),
+		states:          make(map[string]*componentState),
+		minDwellTime:    DefaultMinDwellTime,
+		cacheTTL:        DefaultCacheTTL,
+		metricsInterval: DefaultMetricsInterval,
+		startTime:       time.Now(),
+		version:         version,
 	}
 }
 
+// SetMinDwellTime sets how long a recovered status must hold before it is reported
+func (hm *HealthMonitor) SetMinDwellTime(d time.Duration) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	hm.minDwellTime = d
+}
+
+// SetCacheTTL sets how long HTTPHandler serves a health report before running
+// the checks again. Zero runs them on every request.
+func (hm *HealthMonitor) SetCacheTTL(d time.Duration) {
+	hm.cacheMu.Lock()
+	defer hm.cacheMu.Unlock()
+	hm.cacheTTL = d
+	hm.cached = nil
+}
+
+// SetMetricsInterval sets how often SampleMetrics refreshes the system
+// metrics. Checks serve the latest sample until it is twice the interval
+// old, so a missed refresh is made up by the next check. Zero reads the
+// metrics on every check.
+func (hm *HealthMonitor) SetMetricsInterval(d time.Duration) {
+	hm.metricsMu.Lock()
+	defer hm.metricsMu.Unlock()
+	hm.metricsInterval = d
+	hm.metrics = nil
+}
+
+// SampleMetrics reads the system metrics and keeps them for checks to serve
+func (hm *HealthMonitor) SampleMetrics() SystemMetrics {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	metrics := hm.systemMetricsFrom(&memStats)
+
+	hm.metricsMu.Lock()
+	hm.metrics = &metrics
+	hm.memStats = memStats
+	hm.metricsMu.Unlock()
+	return metrics
+}
+
+// systemMetrics returns the latest sample, reading the metrics again once
+// it is stale
+func (hm *HealthMonitor) systemMetrics() SystemMetrics {
+	hm.metricsMu.Lock()
+	if hm.metrics != nil && time.Since(hm.metrics.SampledAt) < 2*hm.metricsInterval {
+		metrics := *hm.metrics
+		hm.metricsMu.Unlock()
+		return metrics
+	}
+	hm.metricsMu.Unlock()
+	return hm.SampleMetrics()
+}
+
+// ReadMemStats fills stats from the latest sample, for checkers that would
+// otherwise call runtime.ReadMemStats themselves
+func (hm *HealthMonitor) ReadMemStats(stats *runtime.MemStats) {
+	hm.systemMetrics()
+	hm.metricsMu.Lock()
+	defer hm.metricsMu.Unlock()
+	*stats = hm.memStats
+}
+
+// stabilize applies hysteresis to a component's status. Worse statuses are
+// reported immediately; better statuses are only reported once they have
+// been observed continuously for the minimum dwell time.
+func (hm *HealthMonitor) stabilize(health ComponentHealth) ComponentHealth {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	state, exists := hm.states[health.Name]
+	if !exists {
+		hm.states[health.Name] = &componentState{status: health.Status}
+		return health
+	}
+
+	if severity(health.Status) >= severity(state.status) {
+		state.status = health.Status
+		state.hasPending = false
+		return health
+	}
+
+	if !state.hasPending || state.pending != health.Status {
+		state.pending = health.Status
+		state.pendingSince = health.LastChecked
+		state.hasPending = true
+	}
+
+	if health.LastChecked.Sub(state.pendingSince) >= hm.minDwellTime {
+		state.status = health.Status
+		state.hasPending = false
+		return health
+	}
+
+	// Hold the previous status until the recovery has proven stable
+	if health.Details == nil {
+		health.Details = make(map[string]interface{})
+	}
+	health.Details["observed_status"] = health.Status
+	health.Details["recovering_since"] = state.pendingSince
+	health.Status = state.status
+	return health
+}
+
+// SetCapacityMonitor adds capacity metrics to the system metrics and
+// registers the monitor's disk space check
+func (hm *HealthMonitor) SetCapacityMonitor(capacity *CapacityMonitor) {
+	hm.mu.Lock()
+	hm.capacity = capacity
+	hm.mu.Unlock()
+	hm.RegisterChecker(capacity)
+
+	// Drop the sample taken without capacity metrics
+	hm.metricsMu.Lock()
+	hm.metrics = nil
+	hm.metricsMu.Unlock()
+}
+
 // RegisterChecker registers a health checker
 func (hm *HealthMonitor) RegisterChecker(checker HealthChecker) {
 	hm.mu.Lock()
@@ -115,7 +296,7 @@ func (hm *HealthMonitor) CheckHealth(ctx context.Context) *HealthReport {
 		Version:    hm.version,
 		Uptime:     time.Since(hm.startTime),
 		Components: make(map[string]ComponentHealth),
-		System:     hm.getSystemMetrics(),
+		System:     hm.systemMetrics(),
 	}
 
 	// Check all components in parallel
@@ -131,7 +312,7 @@ func (hm *HealthMonitor) CheckHealth(ctx context.Context) *HealthReport {
 			checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 			defer cancel()
 
-			health := c.Check(checkCtx)
+			health := hm.stabilize(c.Check(checkCtx))
 
 			mu.Lock()
 			report.Components[n] = health
@@ -155,11 +336,60 @@ func (hm *HealthMonitor) CheckHealth(ctx context.Context) *HealthReport {
 	return report
 }
 
+// CachedHealth returns the latest report if it is younger than the cache TTL,
+// and otherwise runs the checks. Concurrent callers share one run, so a burst
+// of requests probes storage once. With fresh set the cached report is
+// skipped, but a run already in progress is still shared since its results
+// are current.
+func (hm *HealthMonitor) CachedHealth(ctx context.Context, fresh bool) *HealthReport {
+	hm.cacheMu.Lock()
+	if !fresh && hm.cached != nil && time.Since(hm.cached.Timestamp) < hm.cacheTTL {
+		report := hm.cached
+		hm.cacheMu.Unlock()
+		return report
+	}
+
+	flight := hm.inflight
+	if flight == nil {
+		flight = &healthFlight{done: make(chan struct{})}
+		hm.inflight = flight
+		// The run outlives the request that started it, since other
+		// requests wait for it
+		go hm.fly(context.WithoutCancel(ctx), flight)
+	}
+	hm.cacheMu.Unlock()
+
+	select {
+	case <-flight.done:
+		return flight.report
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// fly runs the checks for a shared run and caches the report
+func (hm *HealthMonitor) fly(ctx context.Context, flight *healthFlight) {
+	flight.report = hm.CheckHealth(ctx)
+
+	hm.cacheMu.Lock()
+	hm.inflight = nil
+	if hm.cacheTTL > 0 {
+		hm.cached = flight.report
+	}
+	hm.cacheMu.Unlock()
+	close(flight.done)
+}
+
 // getSystemMetrics collects system-level metrics
 func (hm *HealthMonitor) getSystemMetrics() SystemMetrics {
 	var memStats runtime.MemStats
 	runtime.ReadMemStats(&memStats)
+	return hm.systemMetricsFrom(&memStats)
+}
 
+// systemMetricsFrom builds the system metrics from memory statistics read
+// at the time of the call
+func (hm *HealthMonitor) systemMetricsFrom(memStats *runtime.MemStats) SystemMetrics {
 	gcMetrics := GCMetrics{
 		NumGC:      memStats.NumGC,
 		PauseTotal: time.Duration(memStats.PauseTotalNs),
@@ -172,6 +402,16 @@ func (hm *HealthMonitor) getSystemMetrics() SystemMetrics {
 
 	memoryUsagePercent := float64(memStats.Alloc) / float64(memStats.Sys) * 100
 
+	hm.mu.RLock()
+	capacity := hm.capacity
+	hm.mu.RUnlock()
+
+	var capacityMetrics *CapacityMetrics
+	if capacity != nil {
+		metrics := capacity.Metrics()
+		capacityMetrics = &metrics
+	}
+
 	return SystemMetrics{
 		MemoryUsage: MemoryMetrics{
 			Alloc:       memStats.Alloc,
@@ -186,14 +426,23 @@ func (hm *HealthMonitor) getSystemMetrics() SystemMetrics {
 		GoRoutines: runtime.NumGoroutine(),
 		CGOCalls:   runtime.NumCgoCall(),
 		GCStats:    gcMetrics,
+		Capacity:   capacityMetrics,
+		SampledAt:  time.Now(),
 	}
 }
 
 // HTTPHandler returns an HTTP handler for health checks
 func (hm *HealthMonitor) HTTPHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-		report := hm.CheckHealth(ctx)
+		report := hm.CachedHealth(r.Context(), r.URL.Query().Get("fresh") == "true")
+		if report == nil {
+			// The client went away while the checks ran
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		w.Header().Set("Age", strconv.Itoa(int(time.Since(report.Timestamp).Seconds())))
 
 		// Set appropriate HTTP status code
 		switch report.Status {
@@ -205,9 +454,6 @@ func (hm *HealthMonitor) HTTPHandler() http.HandlerFunc {
 			w.WriteHeader(http.StatusServiceUnavailable)
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-
 		if err := json.NewEncoder(w).Encode(report); err != nil {
 			http.Error(w, "Failed to encode health report", http.StatusInternalServerError)
 		}
@@ -250,16 +496,60 @@ func (d *DatabaseHealthChecker) Check(ctx context.Context) ComponentHealth {
 	return health
 }
 
+// MemoryHysteresisPercent is how far usage must drop below a threshold
+// before the memory checker reports the better status again
+const MemoryHysteresisPercent = 5.0
+
 // MemoryHealthChecker checks memory usage
 type MemoryHealthChecker struct {
-	maxMemoryMB float64
+	maxMemoryMB  float64
+	lastStatus   HealthStatus
+	readMemStats func(*runtime.MemStats)
+	mu           sync.Mutex
 }
 
 // NewMemoryHealthChecker creates a new memory health checker
 func NewMemoryHealthChecker(maxMemoryMB float64) *MemoryHealthChecker {
 	return &MemoryHealthChecker{
-		maxMemoryMB: maxMemoryMB,
+		maxMemoryMB:  maxMemoryMB,
+		lastStatus:   HealthStatusHealthy,
+		readMemStats: runtime.ReadMemStats,
+	}
+}
+
+// SetMemStatsSource reads memory statistics with read instead of
+// runtime.ReadMemStats, such as HealthMonitor.ReadMemStats to share its
+// sampled statistics
+func (m *MemoryHealthChecker) SetMemStatsSource(read func(*runtime.MemStats)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.readMemStats = read
+}
+
+// statusForUsage maps a usage percentage to a status, requiring usage to fall
+// MemoryHysteresisPercent below a threshold before leaving the worse status
+func (m *MemoryHealthChecker) statusForUsage(usagePercent float64) HealthStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	unhealthyAt, degradedAt := 90.0, 75.0
+	switch m.lastStatus {
+	case HealthStatusUnhealthy:
+		unhealthyAt -= MemoryHysteresisPercent
+		degradedAt -= MemoryHysteresisPercent
+	case HealthStatusDegraded:
+		degradedAt -= MemoryHysteresisPercent
+	}
+
+	status := HealthStatusHealthy
+	if usagePercent > unhealthyAt {
+		status = HealthStatusUnhealthy
+	} else if usagePercent > degradedAt {
+		status = HealthStatusDegraded
 	}
+
+	m.lastStatus = status
+	return status
 }
 
 // Name returns the name of this health checker
@@ -275,8 +565,11 @@ func (m *MemoryHealthChecker) Check(ctx context.Context) ComponentHealth {
 		LastChecked: start,
 	}
 
+	m.mu.Lock()
+	readMemStats := m.readMemStats
+	m.mu.Unlock()
 	var memStats runtime.MemStats
-	runtime.ReadMemStats(&memStats)
+	readMemStats(&memStats)
 
 	currentMemoryMB := float64(memStats.Alloc) / 1024 / 1024
 	usagePercent := (currentMemoryMB / m.maxMemoryMB) * 100
@@ -289,14 +582,13 @@ func (m *MemoryHealthChecker) Check(ctx context.Context) ComponentHealth {
 		"gc_cycles":         memStats.NumGC,
 	}
 
-	if usagePercent > 90 {
-		health.Status = HealthStatusUnhealthy
+	health.Status = m.statusForUsage(usagePercent)
+	switch health.Status {
+	case HealthStatusUnhealthy:
 		health.Message = fmt.Sprintf("Memory usage critically high: %.1f%%", usagePercent)
-	} else if usagePercent > 75 {
-		health.Status = HealthStatusDegraded
+	case HealthStatusDegraded:
 		health.Message = fmt.Sprintf("Memory usage high: %.1f%%", usagePercent)
-	} else {
-		health.Status = HealthStatusHealthy
+	default:
 		health.Message = fmt.Sprintf("Memory usage normal: %.1f%%", usagePercent)
 	}
 
@@ -306,12 +598,14 @@ func (m *MemoryHealthChecker) Check(ctx context.Context) ComponentHealth {
 
 // ApplicationHealthChecker checks application-specific health
 type ApplicationHealthChecker struct {
-	storage storage.Storage
+	storage  storage.Storage
+	demoMode bool
 }
 
-// NewApplicationHealthChecker creates a new application health checker
-func NewApplicationHealthChecker(storage storage.Storage) *ApplicationHealthChecker {
-	return &ApplicationHealthChecker{storage: storage}
+// NewApplicationHealthChecker creates a new application health checker.
+// demoMode is reported in the check's details.
+func NewApplicationHealthChecker(storage storage.Storage, demoMode bool) *ApplicationHealthChecker {
+	return &ApplicationHealthChecker{storage: storage, demoMode: demoMode}
 }
 
 // Name returns the name of this health checker
@@ -338,7 +632,7 @@ func (a *ApplicationHealthChecker) Check(ctx context.Context) ComponentHealth {
 		health.Status = HealthStatusHealthy
 		health.Message = "Application components functional"
 		health.Details = map[string]interface{}{
-			"demo_mode": true, // Could check actual mode
+			"demo_mode": a.demoMode,
 		}
 	}
 
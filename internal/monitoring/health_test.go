@@ -4,6 +4,9 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -56,11 +59,62 @@ func TestMemoryHealthChecker(t *testing.T) {
 	assert.Contains(t, health.Details, "usage_percent")
 }
 
+func TestMemoryHealthCheckerHysteresis(t *testing.T) {
+	checker := NewMemoryHealthChecker(512.0)
+
+	assert.Equal(t, HealthStatusDegraded, checker.statusForUsage(76))
+	// Dropping just below the threshold keeps the degraded status
+	assert.Equal(t, HealthStatusDegraded, checker.statusForUsage(74))
+	// Dropping below the hysteresis margin recovers
+	assert.Equal(t, HealthStatusHealthy, checker.statusForUsage(69))
+	assert.Equal(t, HealthStatusHealthy, checker.statusForUsage(74))
+
+	assert.Equal(t, HealthStatusUnhealthy, checker.statusForUsage(91))
+	assert.Equal(t, HealthStatusUnhealthy, checker.statusForUsage(88))
+	assert.Equal(t, HealthStatusDegraded, checker.statusForUsage(84))
+}
+
+// flappingChecker reports the statuses it is given in order
+type flappingChecker struct {
+	statuses []HealthStatus
+	calls    int
+}
+
+func (f *flappingChecker) Name() string { return "flapping" }
+
+func (f *flappingChecker) Check(ctx context.Context) ComponentHealth {
+	status := f.statuses[f.calls%len(f.statuses)]
+	f.calls++
+	return ComponentHealth{Name: f.Name(), Status: status, LastChecked: time.Now()}
+}
+
+func TestHealthMonitorMinDwellTime(t *testing.T) {
+	monitor := NewHealthMonitor("test-1.0.0")
+	monitor.RegisterChecker(&flappingChecker{
+		statuses: []HealthStatus{HealthStatusDegraded, HealthStatusHealthy},
+	})
+
+	// Degradation is reported immediately
+	report := monitor.CheckHealth(context.Background())
+	assert.Equal(t, HealthStatusDegraded, report.Status)
+
+	// Recovery is held until the dwell time has passed
+	report = monitor.CheckHealth(context.Background())
+	assert.Equal(t, HealthStatusDegraded, report.Status)
+	assert.Equal(t, HealthStatusHealthy, report.Components["flapping"].Details["observed_status"])
+
+	// With no dwell time the recovery is reported straight away
+	monitor.SetMinDwellTime(0)
+	monitor.CheckHealth(context.Background())
+	report = monitor.CheckHealth(context.Background())
+	assert.Equal(t, HealthStatusHealthy, report.Status)
+}
+
 func TestApplicationHealthChecker(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
 
-	checker := NewApplicationHealthChecker(store)
+	checker := NewApplicationHealthChecker(store, false)
 	assert.Equal(t, "application", checker.Name())
 
 	health := checker.Check(context.Background())
@@ -68,6 +122,10 @@ func TestApplicationHealthChecker(t *testing.T) {
 	assert.Equal(t, HealthStatusHealthy, health.Status)
 	assert.Contains(t, health.Message, "functional")
 	assert.True(t, health.Duration > 0)
+	assert.Equal(t, false, health.Details["demo_mode"])
+
+	health = NewApplicationHealthChecker(store, true).Check(context.Background())
+	assert.Equal(t, true, health.Details["demo_mode"])
 }
 
 func TestHealthMonitorWithCheckers(t *testing.T) {
@@ -77,7 +135,7 @@ func TestHealthMonitorWithCheckers(t *testing.T) {
 	monitor := NewHealthMonitor("test-1.0.0")
 	monitor.RegisterChecker(NewDatabaseHealthChecker(store))
 	monitor.RegisterChecker(NewMemoryHealthChecker(512.0))
-	monitor.RegisterChecker(NewApplicationHealthChecker(store))
+	monitor.RegisterChecker(NewApplicationHealthChecker(store, false))
 
 	report := monitor.CheckHealth(context.Background())
 
@@ -121,6 +179,88 @@ func TestHealthHTTPHandler(t *testing.T) {
 	assert.Contains(t, body, "\"system\":")
 }
 
+// countingChecker counts its checks, each taking delay
+type countingChecker struct {
+	checks atomic.Int32
+	delay  time.Duration
+}
+
+func (c *countingChecker) Name() string {
+	return "counting"
+}
+
+func (c *countingChecker) Check(ctx context.Context) ComponentHealth {
+	c.checks.Add(1)
+	time.Sleep(c.delay)
+	return ComponentHealth{Name: c.Name(), Status: HealthStatusHealthy, LastChecked: time.Now()}
+}
+
+func TestHealthHTTPHandlerCachesReport(t *testing.T) {
+	monitor := NewHealthMonitor("test-1.0.0")
+	checker := &countingChecker{}
+	monitor.RegisterChecker(checker)
+	handler := monitor.HTTPHandler()
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest("GET", "/health/detailed", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "0", w.Header().Get("Age"))
+	}
+	assert.Equal(t, int32(1), checker.checks.Load(), "Expected repeated requests to share the cached report")
+
+	// fresh=true skips the cache and refreshes it
+	handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/health/detailed?fresh=true", nil))
+	assert.Equal(t, int32(2), checker.checks.Load())
+	handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/health/detailed", nil))
+	assert.Equal(t, int32(2), checker.checks.Load())
+
+	// Without a TTL every request runs the checks
+	monitor.SetCacheTTL(0)
+	handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/health/detailed", nil))
+	handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/health/detailed", nil))
+	assert.Equal(t, int32(4), checker.checks.Load())
+}
+
+func TestCachedHealthSharesConcurrentRuns(t *testing.T) {
+	monitor := NewHealthMonitor("test-1.0.0")
+	monitor.SetCacheTTL(0)
+	checker := &countingChecker{delay: 50 * time.Millisecond}
+	monitor.RegisterChecker(checker)
+
+	var wg sync.WaitGroup
+	reports := make([]*HealthReport, 10)
+	for i := range reports {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reports[i] = monitor.CachedHealth(context.Background(), i%2 == 0)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), checker.checks.Load(), "Expected concurrent requests to share one run")
+	for _, report := range reports {
+		assert.Same(t, reports[0], report)
+	}
+}
+
+func TestCachedHealthCanceledRequest(t *testing.T) {
+	monitor := NewHealthMonitor("test-1.0.0")
+	checker := &countingChecker{delay: 50 * time.Millisecond}
+	monitor.RegisterChecker(checker)
+
+	// A request that gives up does not cancel the run for everyone else
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Nil(t, monitor.CachedHealth(ctx, false))
+
+	report := monitor.CachedHealth(context.Background(), false)
+	assert.NotNil(t, report)
+	assert.Equal(t, HealthStatusHealthy, report.Components["counting"].Status)
+	assert.Equal(t, int32(1), checker.checks.Load())
+}
+
 func TestHealthCheckTimeout(t *testing.T) {
 	monitor := NewHealthMonitor("test-1.0.0")
 
@@ -180,3 +320,36 @@ func TestSystemMetrics(t *testing.T) {
 	assert.True(t, metrics.MemoryUsage.MemoryUsage >= 0)
 	assert.True(t, metrics.MemoryUsage.MemoryUsage <= 100)
 }
+
+func TestHealthMonitor_MetricsSampling(t *testing.T) {
+	monitor := NewHealthMonitor("test-1.0.0")
+	monitor.SetMetricsInterval(time.Hour)
+
+	sample := monitor.SampleMetrics()
+	report := monitor.CheckHealth(context.Background())
+	assert.Equal(t, sample.SampledAt, report.System.SampledAt, "Expected checks to serve the sample")
+
+	// A sample older than twice the interval is taken again
+	monitor.metricsMu.Lock()
+	monitor.metrics.SampledAt = time.Now().Add(-3 * time.Hour)
+	monitor.metricsMu.Unlock()
+	report = monitor.CheckHealth(context.Background())
+	assert.WithinDuration(t, time.Now(), report.System.SampledAt, time.Minute)
+
+	monitor.SetMetricsInterval(0)
+	first := monitor.CheckHealth(context.Background())
+	time.Sleep(time.Millisecond)
+	second := monitor.CheckHealth(context.Background())
+	assert.True(t, second.System.SampledAt.After(first.System.SampledAt), "Expected metrics to be read on every check")
+}
+
+func TestMemoryHealthChecker_MemStatsSource(t *testing.T) {
+	checker := NewMemoryHealthChecker(100)
+	checker.SetMemStatsSource(func(stats *runtime.MemStats) {
+		stats.Alloc = 80 * 1024 * 1024
+	})
+
+	health := checker.Check(context.Background())
+	assert.Equal(t, HealthStatusDegraded, health.Status)
+	assert.Equal(t, 80.0, health.Details["current_memory_mb"])
+}
@@ -0,0 +1,234 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/respond"
+	"watered/internal/storage"
+)
+
+// HealthStatusUnknown only appears in health history, for time when no
+// check was recorded, such as while the server was down
+const HealthStatusUnknown HealthStatus = "unknown"
+
+// HealthHistoryRanges are the windows GET /admin/health/history can show
+var HealthHistoryRanges = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+// defaultHealthHistoryRange is shown when no range is requested
+const defaultHealthHistoryRange = "24h"
+
+// HealthSegment is a stretch of time with one status
+type HealthSegment struct {
+	Status HealthStatus `json:"status"`
+	Start  time.Time    `json:"start"`
+	End    time.Time    `json:"end"`
+}
+
+// HealthTimeline is the recorded status of the overall report or one
+// component. UptimePercent is the share of recorded time it was not
+// unhealthy and is nil when nothing was recorded.
+type HealthTimeline struct {
+	Name          string          `json:"name"`
+	UptimePercent *float64        `json:"uptime_percent"`
+	Segments      []HealthSegment `json:"segments"`
+}
+
+// HealthHistory is the uptime timeline over a window
+type HealthHistory struct {
+	Range      string           `json:"range,omitempty"`
+	From       time.Time        `json:"from"`
+	To         time.Time        `json:"to"`
+	Overall    HealthTimeline   `json:"overall"`
+	Components []HealthTimeline `json:"components"`
+}
+
+// SetHistory records the result of RecordHealth in store every interval and
+// keeps it for the retention window
+func (hm *HealthMonitor) SetHistory(store storage.Storage, interval, retention time.Duration) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	hm.historyStore = store
+	hm.historyInterval = interval
+	hm.historyRetention = retention
+}
+
+// RecordHealth runs the checks, saves the result in the health history and
+// removes results older than the retention window. It does nothing unless
+// SetHistory was called.
+func (hm *HealthMonitor) RecordHealth(ctx context.Context) error {
+	hm.mu.RLock()
+	store, retention := hm.historyStore, hm.historyRetention
+	hm.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+
+	report := hm.CheckHealth(ctx)
+	sample := &models.HealthSample{
+		CheckedAt:  report.Timestamp,
+		Status:     string(report.Status),
+		Components: make(map[string]string, len(report.Components)),
+	}
+	for name, component := range report.Components {
+		sample.Components[name] = string(component.Status)
+	}
+	if err := store.AddHealthSample(sample); err != nil {
+		return fmt.Errorf("failed to record health check: %w", err)
+	}
+	if _, err := store.PruneHealthSamples(report.Timestamp.Add(-retention)); err != nil {
+		return fmt.Errorf("failed to prune health history: %w", err)
+	}
+	return nil
+}
+
+// History returns the uptime timeline over the window ending now
+func (hm *HealthMonitor) History(window time.Duration) (*HealthHistory, error) {
+	hm.mu.RLock()
+	store, interval := hm.historyStore, hm.historyInterval
+	hm.mu.RUnlock()
+	if store == nil {
+		return nil, fmt.Errorf("health history is not enabled")
+	}
+
+	to := time.Now()
+	from := to.Add(-window)
+	// A check recorded just before the window still covers its start
+	maxGap := 2 * interval
+	samples, err := store.ListHealthSamples(from.Add(-maxGap))
+	if err != nil {
+		return nil, err
+	}
+	return buildHealthHistory(samples, from, to, maxGap), nil
+}
+
+// buildHealthHistory turns samples, oldest first, into timelines. Each
+// sample's status holds until the next sample, or for at most maxGap, after
+// which the status is unknown.
+func buildHealthHistory(samples []*models.HealthSample, from, to time.Time, maxGap time.Duration) *HealthHistory {
+	history := &HealthHistory{
+		From:       from,
+		To:         to,
+		Overall:    buildTimeline("overall", samples, from, to, maxGap, func(s *models.HealthSample) string { return s.Status }),
+		Components: []HealthTimeline{},
+	}
+
+	names := map[string]bool{}
+	for _, sample := range samples {
+		for name := range sample.Components {
+			names[name] = true
+		}
+	}
+	for name := range names {
+		history.Components = append(history.Components, buildTimeline(name, samples, from, to, maxGap, func(s *models.HealthSample) string { return s.Components[name] }))
+	}
+	sort.Slice(history.Components, func(i, j int) bool { return history.Components[i].Name < history.Components[j].Name })
+	return history
+}
+
+// buildTimeline merges consecutive samples with the same status into
+// segments covering from to to
+func buildTimeline(name string, samples []*models.HealthSample, from, to time.Time, maxGap time.Duration, statusOf func(*models.HealthSample) string) HealthTimeline {
+	timeline := HealthTimeline{Name: name, Segments: []HealthSegment{}}
+	add := func(status HealthStatus, start, end time.Time) {
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			return
+		}
+		if n := len(timeline.Segments); n > 0 && timeline.Segments[n-1].Status == status && timeline.Segments[n-1].End.Equal(start) {
+			timeline.Segments[n-1].End = end
+			return
+		}
+		timeline.Segments = append(timeline.Segments, HealthSegment{Status: status, Start: start, End: end})
+	}
+
+	cursor := from
+	for i, sample := range samples {
+		status := HealthStatus(statusOf(sample))
+		if status == "" {
+			// The component was not checked yet, or no longer is
+			status = HealthStatusUnknown
+		}
+		end := sample.CheckedAt.Add(maxGap)
+		if i+1 < len(samples) && samples[i+1].CheckedAt.Before(end) {
+			end = samples[i+1].CheckedAt
+		}
+		add(HealthStatusUnknown, cursor, sample.CheckedAt)
+		add(status, sample.CheckedAt, end)
+		if end.After(cursor) {
+			cursor = end
+		}
+	}
+	add(HealthStatusUnknown, cursor, to)
+
+	var recorded, up time.Duration
+	for _, segment := range timeline.Segments {
+		if segment.Status == HealthStatusUnknown {
+			continue
+		}
+		length := segment.End.Sub(segment.Start)
+		recorded += length
+		if segment.Status != HealthStatusUnhealthy {
+			up += length
+		}
+	}
+	if recorded > 0 {
+		percent := math.Round(float64(up)/float64(recorded)*10000) / 100
+		timeline.UptimePercent = &percent
+	}
+	return timeline
+}
+
+// HistoryHandler returns an HTTP handler for the uptime timeline, for the
+// admin page.
+// GET /admin/health/history?range=24h
+func (hm *HealthMonitor) HistoryHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("range")
+		if name == "" {
+			name = defaultHealthHistoryRange
+		}
+		window, ok := HealthHistoryRanges[name]
+		if !ok {
+			respond.Error(w, http.StatusBadRequest, respond.CodeBadRequest, "range must be 24h or 7d")
+			return
+		}
+
+		hm.mu.RLock()
+		enabled := hm.historyStore != nil
+		hm.mu.RUnlock()
+		if !enabled {
+			respond.Error(w, http.StatusNotFound, respond.CodeNotConfigured, "Health history is disabled, set HEALTH_HISTORY_INTERVAL to enable it")
+			return
+		}
+
+		history, err := hm.History(window)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("Failed to load health history", "error", err)
+			respond.Error(w, http.StatusInternalServerError, respond.CodeInternal, "Failed to load health history")
+			return
+		}
+		history.Range = name
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		if err := json.NewEncoder(w).Encode(history); err != nil {
+			logger.FromContext(r.Context()).Error("Failed to encode health history", "error", err)
+		}
+	}
+}
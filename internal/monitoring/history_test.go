@@ -0,0 +1,116 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildHealthHistory(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	sample := func(minutes int, status string, components map[string]string) *models.HealthSample {
+		return &models.HealthSample{CheckedAt: from.Add(time.Duration(minutes) * time.Minute), Status: status, Components: components}
+	}
+	samples := []*models.HealthSample{
+		sample(-5, "healthy", map[string]string{"database": "healthy"}),
+		sample(5, "healthy", map[string]string{"database": "healthy"}),
+		sample(10, "unhealthy", map[string]string{"database": "unhealthy"}),
+		sample(15, "healthy", map[string]string{"database": "healthy", "backups": "degraded"}),
+		// The server was down until minute 40
+		sample(40, "healthy", map[string]string{"database": "healthy", "backups": "healthy"}),
+	}
+
+	history := buildHealthHistory(samples, from, to, 10*time.Minute)
+
+	overall := history.Overall
+	assert.Equal(t, "overall", overall.Name)
+	require.Len(t, overall.Segments, 6)
+	assert.Equal(t, HealthSegment{Status: HealthStatusHealthy, Start: from, End: from.Add(10 * time.Minute)}, overall.Segments[0])
+	assert.Equal(t, HealthStatusUnhealthy, overall.Segments[1].Status)
+	assert.Equal(t, HealthSegment{Status: HealthStatusHealthy, Start: from.Add(15 * time.Minute), End: from.Add(25 * time.Minute)}, overall.Segments[2])
+	assert.Equal(t, HealthSegment{Status: HealthStatusUnknown, Start: from.Add(25 * time.Minute), End: from.Add(40 * time.Minute)}, overall.Segments[3])
+	assert.Equal(t, HealthSegment{Status: HealthStatusHealthy, Start: from.Add(40 * time.Minute), End: from.Add(50 * time.Minute)}, overall.Segments[4])
+	assert.Equal(t, HealthStatusUnknown, overall.Segments[5].Status, "Expected no status once the last check is too old")
+	// 5 of 35 recorded minutes were unhealthy
+	require.NotNil(t, overall.UptimePercent)
+	assert.Equal(t, 85.71, *overall.UptimePercent)
+
+	require.Len(t, history.Components, 2)
+	backups := history.Components[0]
+	assert.Equal(t, "backups", backups.Name)
+	assert.Equal(t, HealthStatusUnknown, backups.Segments[0].Status)
+	assert.Equal(t, from.Add(15*time.Minute), backups.Segments[0].End)
+	assert.Equal(t, HealthStatusDegraded, backups.Segments[1].Status)
+	assert.Equal(t, 100.0, *backups.UptimePercent)
+	assert.Equal(t, "database", history.Components[1].Name)
+}
+
+func TestBuildHealthHistory_NoSamples(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	history := buildHealthHistory(nil, from, from.Add(time.Hour), 10*time.Minute)
+
+	assert.Nil(t, history.Overall.UptimePercent)
+	assert.Equal(t, []HealthSegment{{Status: HealthStatusUnknown, Start: from, End: from.Add(time.Hour)}}, history.Overall.Segments)
+	assert.Empty(t, history.Components)
+}
+
+func TestHealthMonitor_RecordHealth(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	monitor := NewHealthMonitor("test")
+	monitor.RegisterChecker(NewDatabaseHealthChecker(store))
+
+	// Without a history nothing is recorded
+	require.NoError(t, monitor.RecordHealth(context.Background()))
+	samples, _ := store.ListHealthSamples(time.Time{})
+	assert.Empty(t, samples)
+
+	monitor.SetHistory(store, 5*time.Minute, time.Hour)
+	store.AddHealthSample(&models.HealthSample{CheckedAt: time.Now().Add(-2 * time.Hour), Status: "unhealthy"})
+	require.NoError(t, monitor.RecordHealth(context.Background()))
+
+	samples, _ = store.ListHealthSamples(time.Time{})
+	require.Len(t, samples, 1, "Expected the sample past the retention window to be pruned")
+	assert.Equal(t, "healthy", samples[0].Status)
+	assert.Equal(t, map[string]string{"database": "healthy"}, samples[0].Components)
+
+	history, err := monitor.History(24 * time.Hour)
+	require.NoError(t, err)
+	require.Len(t, history.Components, 1)
+	assert.Equal(t, HealthStatusHealthy, history.Components[0].Segments[len(history.Components[0].Segments)-1].Status)
+}
+
+func TestHealthMonitor_HistoryHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	monitor := NewHealthMonitor("test")
+
+	w := httptest.NewRecorder()
+	monitor.HistoryHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/health/history", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	monitor.SetHistory(store, 5*time.Minute, 7*24*time.Hour)
+
+	w = httptest.NewRecorder()
+	monitor.HistoryHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/health/history?range=30d", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	monitor.HistoryHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/health/history?range=7d", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var history HealthHistory
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&history))
+	assert.Equal(t, "7d", history.Range)
+	assert.Equal(t, 7*24*time.Hour, history.To.Sub(history.From))
+}
@@ -0,0 +1,176 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"watered/internal/storage"
+)
+
+// ReadinessTimeout bounds each readiness check, so a hung storage call
+// fails the probe instead of stalling it
+const ReadinessTimeout = 2 * time.Second
+
+// ReadinessCheck returns an error while a dependency cannot serve traffic
+type ReadinessCheck func(ctx context.Context) error
+
+// ReadinessReport is the result of a readiness probe. Checks maps each
+// check to "ok" or the reason it failed.
+type ReadinessReport struct {
+	Status    string            `json:"status"`
+	Timestamp time.Time         `json:"timestamp"`
+	Checks    map[string]string `json:"checks"`
+}
+
+// Ready reports whether every check passed
+func (r *ReadinessReport) Ready() bool {
+	return r.Status == "ready"
+}
+
+// Readiness decides whether the server should receive traffic. Unlike the
+// detailed health report, which also covers things like memory use and
+// failing jobs, it only fails when requests cannot be served: before
+// startup has finished, while shutting down, and while a check fails.
+type Readiness struct {
+	mu     sync.RWMutex
+	checks map[string]ReadinessCheck
+	// serving is set once startup finishes and cleared when shutdown begins
+	serving bool
+}
+
+// NewReadiness creates a readiness probe that is not ready until
+// SetServing(true) is called
+func NewReadiness() *Readiness {
+	return &Readiness{checks: make(map[string]ReadinessCheck)}
+}
+
+// AddCheck registers a check under name
+func (r *Readiness) AddCheck(name string, check ReadinessCheck) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+}
+
+// SetServing marks startup as finished, or shutdown as begun, so load
+// balancers route traffic to the server only in between
+func (r *Readiness) SetServing(serving bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.serving = serving
+}
+
+// Check runs every check in parallel
+func (r *Readiness) Check(ctx context.Context) *ReadinessReport {
+	r.mu.RLock()
+	serving := r.serving
+	checks := make(map[string]ReadinessCheck, len(r.checks))
+	for name, check := range r.checks {
+		checks[name] = check
+	}
+	r.mu.RUnlock()
+
+	report := &ReadinessReport{Status: "ready", Timestamp: time.Now(), Checks: make(map[string]string, len(checks)+1)}
+	report.Checks["server"] = "ok"
+	if !serving {
+		report.Checks["server"] = "starting up or shutting down"
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := "ok"
+			if err := runCheck(ctx, check); err != nil {
+				result = err.Error()
+			}
+			mu.Lock()
+			report.Checks[name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result != "ok" {
+			report.Status = "not_ready"
+		}
+	}
+	return report
+}
+
+// runCheck runs a check, giving up after ReadinessTimeout even when the
+// check ignores its context
+func runCheck(ctx context.Context, check ReadinessCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, ReadinessTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.New("timed out")
+	}
+}
+
+// HTTPHandler serves the readiness probe: 200 when ready and 503 otherwise
+func (r *Readiness) HTTPHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context())
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		if report.Ready() {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// LiveHandler serves the liveness probe. It answers 200 whenever the
+// process can handle a request at all, so an orchestrator only restarts
+// the server when it has hung, never because a dependency is down.
+func LiveHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"ok","service":"watered"}`))
+	}
+}
+
+// StorageReady checks that storage answers a read
+func StorageReady(store storage.Storage) ReadinessCheck {
+	return func(ctx context.Context) error {
+		if _, err := store.GetAdminConfig(); err != nil {
+			return fmt.Errorf("storage unreachable: %v", err)
+		}
+		return nil
+	}
+}
+
+// MigrationsReady checks that every schema migration has been applied to
+// storage that keeps a schema version. Other storage always passes.
+func MigrationsReady(store storage.Storage) ReadinessCheck {
+	return func(ctx context.Context) error {
+		versioned, ok := store.(interface{ PendingMigrations() []string })
+		if !ok {
+			return nil
+		}
+		if pending := versioned.PendingMigrations(); len(pending) > 0 {
+			return fmt.Errorf("migrations pending: %s", strings.Join(pending, ", "))
+		}
+		return nil
+	}
+}
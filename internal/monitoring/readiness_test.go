@@ -0,0 +1,78 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadiness(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	readiness := NewReadiness()
+	readiness.AddCheck("storage", StorageReady(store))
+	readiness.AddCheck("migrations", MigrationsReady(store))
+
+	// Not ready until startup finishes
+	report := readiness.Check(context.Background())
+	assert.False(t, report.Ready())
+	assert.Equal(t, "starting up or shutting down", report.Checks["server"])
+	assert.Equal(t, "ok", report.Checks["storage"])
+
+	readiness.SetServing(true)
+	rec := httptest.NewRecorder()
+	readiness.HTTPHandler()(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var body ReadinessReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "ready", body.Status)
+	assert.Equal(t, map[string]string{"server": "ok", "storage": "ok", "migrations": "ok"}, body.Checks)
+
+	// A failing check takes the server out of rotation
+	readiness.AddCheck("scheduler", func(ctx context.Context) error { return errors.New("scheduler is not running") })
+	rec = httptest.NewRecorder()
+	readiness.HTTPHandler()(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "not_ready", body.Status)
+	assert.Equal(t, "scheduler is not running", body.Checks["scheduler"])
+}
+
+func TestReadiness_Timeout(t *testing.T) {
+	readiness := NewReadiness()
+	readiness.SetServing(true)
+	hung := make(chan struct{})
+	defer close(hung)
+	readiness.AddCheck("storage", func(ctx context.Context) error { <-hung; return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report := readiness.Check(ctx)
+	assert.False(t, report.Ready())
+	assert.Equal(t, "timed out", report.Checks["storage"])
+}
+
+func TestMigrationsReady_FileStorage(t *testing.T) {
+	store, err := storage.NewFileStorage(filepath.Join(t.TempDir(), "watered.json"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	assert.NoError(t, MigrationsReady(store)(context.Background()))
+}
+
+func TestLiveHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	LiveHandler()(rec, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok","service":"watered"}`, rec.Body.String())
+}
@@ -0,0 +1,128 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"watered/internal/logger"
+	"watered/internal/models"
+	"watered/internal/services"
+)
+
+// SmokeTestWaterer is recorded as the waterer of sandbox plants
+const SmokeTestWaterer = "smoke-test@watered.local"
+
+// SmokeStep is the outcome of one step of a smoke test
+type SmokeStep struct {
+	Name     string        `json:"name"`
+	OK       bool          `json:"ok"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// SmokeReport is the outcome of a full smoke test run
+type SmokeReport struct {
+	OK        bool          `json:"ok"`
+	Timestamp time.Time     `json:"timestamp"`
+	Duration  time.Duration `json:"duration"`
+	Steps     []SmokeStep   `json:"steps"`
+}
+
+// SmokeTester runs an end-to-end synthetic transaction through the plant
+// service: create a sandbox plant, water it, read it back and delete it
+type SmokeTester struct {
+	plantService *services.PlantService
+}
+
+// NewSmokeTester creates a new smoke tester
+func NewSmokeTester(plantService *services.PlantService) *SmokeTester {
+	return &SmokeTester{plantService: plantService}
+}
+
+// Run performs the smoke test. The sandbox plant is always deleted, even
+// when an earlier step fails.
+func (s *SmokeTester) Run(ctx context.Context) *SmokeReport {
+	start := time.Now()
+	report := &SmokeReport{OK: true, Timestamp: start, Steps: []SmokeStep{}}
+
+	step := func(name string, fn func() error) bool {
+		if ctx.Err() != nil {
+			report.Steps = append(report.Steps, SmokeStep{Name: name, Error: ctx.Err().Error()})
+			report.OK = false
+			return false
+		}
+
+		stepStart := time.Now()
+		err := fn()
+		result := SmokeStep{Name: name, OK: err == nil, Duration: time.Since(stepStart)}
+		if err != nil {
+			result.Error = err.Error()
+			report.OK = false
+		}
+		report.Steps = append(report.Steps, result)
+		return err == nil
+	}
+
+	var plant *models.PlantState
+	created := step("create", func() error {
+		var err error
+		plant, err = s.plantService.CreatePlant(fmt.Sprintf("smoke-test-%d", start.UnixNano()), 24)
+		return err
+	})
+
+	if created {
+		watered := step("water", func() error {
+			_, err := s.plantService.WaterPlantByID(plant.ID, SmokeTestWaterer)
+			return err
+		})
+		if watered {
+			step("read", func() error {
+				readBack, err := s.plantService.GetPlantByID(plant.ID)
+				if err != nil {
+					return err
+				}
+				if readBack.WateredBy != SmokeTestWaterer || readBack.LastWatered == nil {
+					return fmt.Errorf("watering was not persisted")
+				}
+				return nil
+			})
+		}
+
+		// Clean up regardless of the earlier steps, ignoring cancellation
+		cleanupStart := time.Now()
+		err := s.plantService.DeletePlant(plant.ID)
+		cleanup := SmokeStep{Name: "delete", OK: err == nil, Duration: time.Since(cleanupStart)}
+		if err != nil {
+			cleanup.Error = err.Error()
+			report.OK = false
+			logger.FromContext(ctx).Warn("Smoke test could not delete sandbox plant", "plant_id", plant.ID, "error", err)
+		}
+		report.Steps = append(report.Steps, cleanup)
+	}
+
+	report.Duration = time.Since(start)
+	return report
+}
+
+// HTTPHandler returns an HTTP handler that runs the smoke test
+func (s *SmokeTester) HTTPHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := s.Run(r.Context())
+		logger.FromContext(r.Context()).Info("Smoke test finished", "ok", report.OK, "duration", report.Duration)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		if report.OK {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			logger.FromContext(r.Context()).Error("Failed to encode smoke test report", "error", err)
+		}
+	}
+}
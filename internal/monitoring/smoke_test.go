@@ -0,0 +1,68 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"watered/internal/services"
+	"watered/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSmokeTester_Run(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	plantService := services.NewPlantService(store)
+	tester := NewSmokeTester(plantService)
+
+	report := tester.Run(context.Background())
+
+	assert.True(t, report.OK)
+	require.Len(t, report.Steps, 4)
+	for i, name := range []string{"create", "water", "read", "delete"} {
+		assert.Equal(t, name, report.Steps[i].Name)
+		assert.True(t, report.Steps[i].OK, "step %s failed: %s", name, report.Steps[i].Error)
+	}
+
+	// The sandbox plant is cleaned up
+	plants, err := plantService.ListPlants()
+	require.NoError(t, err)
+	assert.Len(t, plants, 1)
+}
+
+func TestSmokeTester_CanceledContext(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	tester := NewSmokeTester(services.NewPlantService(store))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := tester.Run(ctx)
+
+	assert.False(t, report.OK)
+	require.Len(t, report.Steps, 1)
+	assert.Equal(t, "create", report.Steps[0].Name)
+	assert.NotEmpty(t, report.Steps[0].Error)
+}
+
+func TestSmokeTester_HTTPHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	tester := NewSmokeTester(services.NewPlantService(store))
+
+	req := httptest.NewRequest("POST", "/health/smoke", nil)
+	w := httptest.NewRecorder()
+
+	tester.HTTPHandler()(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var report SmokeReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.True(t, report.OK)
+	assert.Len(t, report.Steps, 4)
+}
@@ -0,0 +1,369 @@
+// Package mqtt is a minimal MQTT 3.1.1 subscriber, enough to receive sensor
+// readings from a broker: it connects, subscribes to one topic filter at QoS
+// 1 and hands every message to a callback, reconnecting until it is stopped.
+// Publishing and QoS 2 are not supported.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"watered/internal/config"
+)
+
+// Control packet types, in the high nibble of the fixed header
+const (
+	packetConnect    = 1
+	packetConnAck    = 2
+	packetPublish    = 3
+	packetPubAck     = 4
+	packetSubscribe  = 8
+	packetSubAck     = 9
+	packetPingReq    = 12
+	packetPingResp   = 13
+	packetDisconnect = 14
+)
+
+const (
+	// keepAlive is how often the broker hears from us when no messages flow
+	keepAlive = 60 * time.Second
+	// handshakeTimeout bounds connecting, CONNACK and SUBACK
+	handshakeTimeout = 10 * time.Second
+	// maxPacketSize guards against brokers sending absurdly large packets
+	maxPacketSize = 1 << 20
+	// maxBackoff is the longest wait between reconnection attempts
+	maxBackoff = time.Minute
+)
+
+// connectErrors explains the CONNACK return codes
+var connectErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client ID rejected",
+	3: "server unavailable",
+	4: "bad username or password",
+	5: "not authorized",
+}
+
+// Message is a message received on a subscribed topic
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// Subscriber receives the messages published to a topic filter
+type Subscriber struct {
+	address  string
+	tls      bool
+	topic    string
+	username string
+	password string
+	clientID string
+	dial     func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// NewSubscriber creates a subscriber for the configured broker and topic
+func NewSubscriber(cfg config.MQTTConfig) *Subscriber {
+	address := cfg.BrokerURL
+	if broker, err := url.Parse(cfg.BrokerURL); err == nil {
+		address = broker.Host
+		if broker.Port() == "" {
+			port := "1883"
+			if cfg.TLS() {
+				port = "8883"
+			}
+			address = net.JoinHostPort(broker.Hostname(), port)
+		}
+	}
+
+	return &Subscriber{
+		address:  address,
+		tls:      cfg.TLS(),
+		topic:    cfg.Topic,
+		username: cfg.Username,
+		password: cfg.Password,
+		clientID: cfg.ClientID,
+		dial:     (&net.Dialer{Timeout: handshakeTimeout}).DialContext,
+	}
+}
+
+// Run subscribes and calls handle for every message until ctx is done,
+// reconnecting with a growing delay whenever the connection drops. Messages
+// are handled one at a time, in the order they arrive.
+func (s *Subscriber) Run(ctx context.Context, handle func(Message)) {
+	backoff := time.Second
+	for {
+		connected, err := s.session(ctx, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = time.Second
+		}
+		slog.Warn("MQTT subscriber disconnected, retrying", "broker", s.address, "retry_in", backoff.String(), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// session connects, subscribes and reads messages until the connection
+// fails or ctx is done. It reports whether the subscription was set up.
+func (s *Subscriber) session(ctx context.Context, handle func(Message)) (bool, error) {
+	conn, err := s.dial(ctx, "tcp", s.address)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect: %w", err)
+	}
+	if s.tls {
+		host, _, _ := net.SplitHostPort(s.address)
+		conn = tls.Client(conn, &tls.Config{ServerName: host})
+	}
+	defer conn.Close()
+	c := &connection{conn: conn, reader: bufio.NewReader(conn)}
+
+	// Closing the connection unblocks the reader when the subscriber stops
+	stop := context.AfterFunc(ctx, func() {
+		c.write(packetDisconnect<<4, nil)
+		conn.Close()
+	})
+	defer stop()
+
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err := s.handshake(c); err != nil {
+		return false, err
+	}
+	conn.SetDeadline(time.Time{})
+	slog.Info("Subscribed to MQTT topic", "broker", s.address, "topic", s.topic)
+
+	pingCtx, stopPing := context.WithCancel(ctx)
+	defer stopPing()
+	go c.ping(pingCtx)
+
+	for {
+		// The broker drops us after one and a half keep-alives of silence,
+		// so a PINGRESP should always arrive well within two
+		conn.SetReadDeadline(time.Now().Add(2 * keepAlive))
+		header, body, err := c.read()
+		if err != nil {
+			return true, err
+		}
+
+		switch header >> 4 {
+		case packetPublish:
+			msg, id, err := parsePublish(header, body)
+			if err != nil {
+				return true, err
+			}
+			handle(msg)
+			if id != 0 {
+				if err := c.write(packetPubAck<<4, binary.BigEndian.AppendUint16(nil, id)); err != nil {
+					return true, err
+				}
+			}
+		case packetPingResp:
+		default:
+			return true, fmt.Errorf("unexpected MQTT packet type %d", header>>4)
+		}
+	}
+}
+
+// handshake sends CONNECT and SUBSCRIBE and checks the broker's answers
+func (s *Subscriber) handshake(c *connection) error {
+	flags := byte(0x02) // clean session
+	payload := appendString(nil, s.clientID)
+	if s.username != "" {
+		flags |= 0x80
+		payload = appendString(payload, s.username)
+		if s.password != "" {
+			flags |= 0x40
+			payload = appendString(payload, s.password)
+		}
+	}
+	connect := appendString(nil, "MQTT")
+	connect = append(connect, 4, flags)
+	connect = binary.BigEndian.AppendUint16(connect, uint16(keepAlive/time.Second))
+	if err := c.write(packetConnect<<4, append(connect, payload...)); err != nil {
+		return fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+
+	header, body, err := c.read()
+	if err != nil {
+		return fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+	if header>>4 != packetConnAck || len(body) != 2 {
+		return errors.New("broker did not answer CONNECT with CONNACK")
+	}
+	if code := body[1]; code != 0 {
+		reason, ok := connectErrors[code]
+		if !ok {
+			reason = fmt.Sprintf("return code %d", code)
+		}
+		return fmt.Errorf("broker refused connection: %s", reason)
+	}
+
+	subscribe := binary.BigEndian.AppendUint16(nil, 1)
+	subscribe = appendString(subscribe, s.topic)
+	subscribe = append(subscribe, 1) // QoS 1
+	if err := c.write(packetSubscribe<<4|0x02, subscribe); err != nil {
+		return fmt.Errorf("failed to send SUBSCRIBE: %w", err)
+	}
+
+	header, body, err = c.read()
+	if err != nil {
+		return fmt.Errorf("failed to read SUBACK: %w", err)
+	}
+	if header>>4 != packetSubAck || len(body) != 3 || body[2] == 0x80 {
+		return fmt.Errorf("broker refused subscription to %s", s.topic)
+	}
+	return nil
+}
+
+// connection is an open connection to the broker. Writes come from both the
+// reader, acknowledging messages, and the pinger, so they are serialised.
+type connection struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	mu sync.Mutex
+}
+
+// write sends one packet
+func (c *connection) write(header byte, body []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return writePacket(c.conn, header, body)
+}
+
+// read receives one packet, returning its fixed header byte and body
+func (c *connection) read() (byte, []byte, error) {
+	header, err := c.reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		if i == 3 {
+			return 0, nil, errors.New("malformed MQTT packet length")
+		}
+		multiplier *= 128
+	}
+	if length > maxPacketSize {
+		return 0, nil, fmt.Errorf("MQTT packet of %d bytes is too large", length)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// ping keeps the connection alive until ctx is done
+func (c *connection) ping(ctx context.Context) {
+	ticker := time.NewTicker(keepAlive / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.write(packetPingReq<<4, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writePacket writes a packet with its remaining length encoded
+func writePacket(w io.Writer, header byte, body []byte) error {
+	packet := []byte{header}
+	length := len(body)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if length == 0 {
+			break
+		}
+	}
+	_, err := w.Write(append(packet, body...))
+	return err
+}
+
+// parsePublish decodes a PUBLISH packet, returning its packet ID when it
+// must be acknowledged
+func parsePublish(header byte, body []byte) (Message, uint16, error) {
+	if len(body) < 2 {
+		return Message{}, 0, errors.New("malformed MQTT PUBLISH")
+	}
+	topicLength := int(binary.BigEndian.Uint16(body))
+	if len(body) < 2+topicLength {
+		return Message{}, 0, errors.New("malformed MQTT PUBLISH topic")
+	}
+	msg := Message{Topic: string(body[2 : 2+topicLength])}
+	rest := body[2+topicLength:]
+
+	var id uint16
+	if qos := (header >> 1) & 0x03; qos > 0 {
+		if len(rest) < 2 {
+			return Message{}, 0, errors.New("malformed MQTT PUBLISH packet ID")
+		}
+		id = binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+	}
+	msg.Payload = rest
+	return msg, id, nil
+}
+
+// appendString appends an MQTT length-prefixed string
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// DeviceFromTopic returns the topic level matched by the + in filter, which
+// names the device that published to topic
+func DeviceFromTopic(filter, topic string) (string, bool) {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	if len(filterLevels) != len(topicLevels) {
+		return "", false
+	}
+
+	device := ""
+	for i, level := range filterLevels {
+		switch {
+		case level == "+":
+			device = topicLevels[i]
+		case level != topicLevels[i]:
+			return "", false
+		}
+	}
+	return device, device != ""
+}
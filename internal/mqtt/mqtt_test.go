@@ -0,0 +1,135 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+)
+
+// fakeBroker answers one client over conn: it checks the CONNECT and
+// SUBSCRIBE, publishes payload to topic at QoS 1 and waits for the PUBACK
+func fakeBroker(t *testing.T, conn net.Conn, connectCode byte, topic, payload string) <-chan uint16 {
+	acked := make(chan uint16, 1)
+	go func() {
+		defer conn.Close()
+		c := &connection{conn: conn, reader: bufio.NewReader(conn)}
+
+		header, body, err := c.read()
+		if err != nil || header>>4 != packetConnect {
+			t.Errorf("Expected CONNECT, got %d (%v)", header>>4, err)
+			return
+		}
+		if string(body[2:6]) != "MQTT" || body[6] != 4 || body[7] != 0xC2 {
+			t.Errorf("Unexpected CONNECT header % x", body[:10])
+		}
+		c.write(packetConnAck<<4, []byte{0, connectCode})
+		if connectCode != 0 {
+			return
+		}
+
+		header, body, err = c.read()
+		if err != nil || header != packetSubscribe<<4|0x02 {
+			t.Errorf("Expected SUBSCRIBE, got %x (%v)", header, err)
+			return
+		}
+		if filter := string(body[4 : len(body)-1]); filter != "watered/sensors/+" || body[len(body)-1] != 1 {
+			t.Errorf("Unexpected subscription %q at QoS %d", filter, body[len(body)-1])
+		}
+		c.write(packetSubAck<<4, []byte{body[0], body[1], 1})
+
+		publish := appendString(nil, topic)
+		publish = binary.BigEndian.AppendUint16(publish, 7)
+		c.write(packetPublish<<4|0x02, append(publish, payload...))
+
+		header, body, err = c.read()
+		if err != nil || header>>4 != packetPubAck {
+			t.Errorf("Expected PUBACK, got %x (%v)", header, err)
+			return
+		}
+		acked <- binary.BigEndian.Uint16(body)
+	}()
+	return acked
+}
+
+func newTestSubscriber(t *testing.T) (*Subscriber, net.Conn) {
+	t.Helper()
+	client, broker := net.Pipe()
+	subscriber := NewSubscriber(config.MQTTConfig{
+		BrokerURL: "tcp://broker.example.com",
+		Topic:     "watered/sensors/+",
+		Username:  "watered",
+		Password:  "secret",
+		ClientID:  "watered-test",
+	})
+	subscriber.dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if address != "broker.example.com:1883" {
+			t.Errorf("Expected the default port, dialled %s", address)
+		}
+		return client, nil
+	}
+	return subscriber, broker
+}
+
+func TestSubscriber_ReceivesMessages(t *testing.T) {
+	subscriber, broker := newTestSubscriber(t)
+	acked := fakeBroker(t, broker, 0, "watered/sensors/kitchen", `{"moisture": 40}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	messages := make(chan Message, 1)
+	connected, _ := subscriber.session(ctx, func(msg Message) { messages <- msg })
+
+	if !connected {
+		t.Fatal("Expected the subscription to be set up")
+	}
+	select {
+	case msg := <-messages:
+		if msg.Topic != "watered/sensors/kitchen" || string(msg.Payload) != `{"moisture": 40}` {
+			t.Errorf("Unexpected message %q: %s", msg.Topic, msg.Payload)
+		}
+	default:
+		t.Fatal("Expected a message")
+	}
+	select {
+	case id := <-acked:
+		if id != 7 {
+			t.Errorf("Expected PUBACK for packet 7, got %d", id)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the message to be acknowledged")
+	}
+}
+
+func TestSubscriber_RefusedConnection(t *testing.T) {
+	subscriber, broker := newTestSubscriber(t)
+	fakeBroker(t, broker, 4, "", "")
+
+	connected, err := subscriber.session(context.Background(), func(Message) {})
+	if connected || err == nil || err.Error() != "broker refused connection: bad username or password" {
+		t.Errorf("Expected a refused connection, got %v (connected %v)", err, connected)
+	}
+}
+
+func TestDeviceFromTopic(t *testing.T) {
+	tests := []struct {
+		filter, topic, device string
+		ok                    bool
+	}{
+		{"watered/sensors/+", "watered/sensors/kitchen", "kitchen", true},
+		{"home/+/moisture", "home/balcony/moisture", "balcony", true},
+		{"watered/sensors/+", "watered/sensors/kitchen/extra", "", false},
+		{"watered/sensors/+", "other/sensors/kitchen", "", false},
+		{"watered/sensors/+", "watered/sensors/", "", false},
+	}
+	for _, tt := range tests {
+		device, ok := DeviceFromTopic(tt.filter, tt.topic)
+		if device != tt.device || ok != tt.ok {
+			t.Errorf("DeviceFromTopic(%q, %q) = %q, %v, want %q, %v", tt.filter, tt.topic, device, ok, tt.device, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"watered/internal/config"
+)
+
+// Message is the payload of a Discord webhook
+type Message struct {
+	Content string  `json:"content,omitempty"`
+	Embeds  []Embed `json:"embeds,omitempty"`
+}
+
+// Embed is a rich card shown below the message content
+type Embed struct {
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Color       int     `json:"color,omitempty"`
+	Fields      []Field `json:"fields,omitempty"`
+	// Timestamp is shown in the embed footer in each reader's local time
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// Field is a name and value pair inside an embed
+type Field struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// Sender posts messages to a Discord webhook
+type Sender struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSender creates a sender for the configured webhook
+func NewSender(cfg config.DiscordConfig) *Sender {
+	return &Sender{
+		webhookURL: cfg.WebhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send posts a message to the webhook
+func (s *Sender) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode Discord message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Discord request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The webhook URL contains its token, so keep it out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to reach Discord: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("discord returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+)
+
+func TestSender_Send(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	timestamp := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	sender := NewSender(config.DiscordConfig{WebhookURL: server.URL + "/api/webhooks/1/secret"})
+	err := sender.Send(context.Background(), Message{Embeds: []Embed{{
+		Title:     "Fern needs water",
+		Color:     0xe74c3c,
+		Fields:    []Field{{Name: "Status", Value: "Critical", Inline: true}},
+		Timestamp: &timestamp,
+	}}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	embeds, _ := got["embeds"].([]interface{})
+	if len(embeds) != 1 {
+		t.Fatalf("Expected one embed, got %v", got)
+	}
+	embed := embeds[0].(map[string]interface{})
+	if embed["title"] != "Fern needs water" || embed["color"] != float64(0xe74c3c) || embed["timestamp"] != "2024-06-01T12:00:00Z" {
+		t.Errorf("Unexpected embed: %v", embed)
+	}
+	if _, ok := got["content"]; ok {
+		t.Errorf("Expected empty content to be omitted, got %v", got)
+	}
+}
+
+func TestSender_SendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Unknown Webhook", "code": 10015}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	sender := NewSender(config.DiscordConfig{WebhookURL: server.URL + "/api/webhooks/1/secret"})
+	err := sender.Send(context.Background(), Message{Content: "hello"})
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "Unknown Webhook") {
+		t.Errorf("Expected the Discord error in the message, got %v", err)
+	}
+
+	// Connection errors must not reveal the webhook token
+	server.Close()
+	err = sender.Send(context.Background(), Message{Content: "hello"})
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("Expected an error without the webhook URL, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"watered/internal/config"
+)
+
+// Message is a plain text email, optionally with an HTML alternative
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+	// HTML is sent alongside Body for clients that display it
+	HTML string
+}
+
+// Sender delivers email through an SMTP server
+type Sender struct {
+	config   config.SMTPConfig
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	now      func() time.Time
+}
+
+// NewSender creates a sender for the given SMTP configuration
+func NewSender(cfg config.SMTPConfig) *Sender {
+	return &Sender{
+		config:   cfg,
+		sendMail: smtp.SendMail,
+		now:      time.Now,
+	}
+}
+
+// Send delivers a message. The connection is upgraded with STARTTLS when the
+// server supports it.
+func (s *Sender) Send(msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+
+	data, err := s.build(msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	from, _ := mail.ParseAddress(s.config.From)
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	if err := s.sendMail(addr, auth, from.Address, msg.To, data); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// build renders the message headers and quoted-printable body, as a
+// multipart/alternative message when it has an HTML part
+func (s *Sender) build(msg Message) ([]byte, error) {
+	for _, to := range msg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, fmt.Errorf("subject must not contain line breaks")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.config.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", s.now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTML == "" {
+		buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, msg.Body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+	// Clients show the last part they understand, so HTML goes last
+	for _, part := range []struct{ contentType, content string }{{"text/plain", msg.Body}, {"text/html", msg.HTML}} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode body: %w", err)
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeQuotedPrintable writes content with CRLF line endings, quoted-printable encoded
+func writeQuotedPrintable(w io.Writer, content string) error {
+	body := quotedprintable.NewWriter(w)
+	if _, err := body.Write([]byte(strings.ReplaceAll(strings.ReplaceAll(content, "\r\n", "\n"), "\n", "\r\n"))); err != nil {
+		return fmt.Errorf("failed to encode body: %w", err)
+	}
+	if err := body.Close(); err != nil {
+		return fmt.Errorf("failed to encode body: %w", err)
+	}
+	return nil
+}
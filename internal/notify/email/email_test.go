@@ -0,0 +1,152 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+)
+
+func TestSender_Send(t *testing.T) {
+	sender := NewSender(config.SMTPConfig{
+		Host:     "smtp.example.com",
+		Port:     587,
+		Username: "bot@example.com",
+		Password: "secret",
+		From:     "Watered <bot@example.com>",
+	})
+	sender.now = func() time.Time { return time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC) }
+
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMsg []byte
+	var gotAuth smtp.Auth
+	sender.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotAuth, gotFrom, gotTo, gotMsg = addr, a, from, to, msg
+		return nil
+	}
+
+	err := sender.Send(Message{
+		To:      []string{"user@example.com"},
+		Subject: "Fern needs water now! 🥀",
+		Body:    "Please water it.\nThanks!",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if gotAddr != "smtp.example.com:587" || gotFrom != "bot@example.com" || gotAuth == nil {
+		t.Errorf("Unexpected envelope: addr=%s from=%s auth=%v", gotAddr, gotFrom, gotAuth)
+	}
+	if len(gotTo) != 1 || gotTo[0] != "user@example.com" {
+		t.Errorf("Unexpected recipients: %v", gotTo)
+	}
+
+	msg := string(gotMsg)
+	for _, expected := range []string{
+		"From: Watered <bot@example.com>\r\n",
+		"To: user@example.com\r\n",
+		"Subject: =?utf-8?q?Fern_needs_water_now!_",
+		"Date: Fri, 01 Mar 2024 08:00:00 +0000\r\n",
+		"Content-Type: text/plain; charset=UTF-8\r\n",
+		"\r\n\r\nPlease water it.\r\nThanks!",
+	} {
+		if !strings.Contains(msg, expected) {
+			t.Errorf("Expected message to contain %q, got %q", expected, msg)
+		}
+	}
+}
+
+func TestSender_SendHTML(t *testing.T) {
+	sender := NewSender(config.SMTPConfig{Host: "smtp.example.com", Port: 587, From: "bot@example.com"})
+	var gotMsg []byte
+	sender.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotMsg = msg
+		return nil
+	}
+
+	err := sender.Send(Message{
+		To:      []string{"user@example.com"},
+		Subject: "Your week",
+		Body:    "5 waterings",
+		HTML:    "<p>5 waterings</p>",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(gotMsg))
+	if err != nil {
+		t.Fatalf("Expected a valid message, got %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative, got %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	expected := []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", "5 waterings"},
+		{"text/html; charset=UTF-8", "<p>5 waterings</p>"},
+	}
+	for _, want := range expected {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("Expected a %s part, got %v", want.contentType, err)
+		}
+		content, _ := io.ReadAll(part)
+		if part.Header.Get("Content-Type") != want.contentType || string(content) != want.content {
+			t.Errorf("Expected %s part %q, got %s part %q", want.contentType, want.content, part.Header.Get("Content-Type"), content)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("Expected two parts, got %v", err)
+	}
+}
+
+func TestSender_SendRejectsInvalidMessages(t *testing.T) {
+	sender := NewSender(config.SMTPConfig{Host: "smtp.example.com", Port: 587, From: "bot@example.com"})
+	sender.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		t.Fatal("Expected invalid message not to be sent")
+		return nil
+	}
+
+	tests := []struct {
+		name string
+		msg  Message
+	}{
+		{"no recipients", Message{Subject: "Hello"}},
+		{"invalid recipient", Message{To: []string{"not an email"}, Subject: "Hello"}},
+		{"header injection", Message{To: []string{"user@example.com"}, Subject: "Hello\r\nBcc: victim@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := sender.Send(tt.msg); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestSender_SendError(t *testing.T) {
+	sender := NewSender(config.SMTPConfig{Host: "smtp.example.com", Port: 587, From: "bot@example.com"})
+	sender.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		if a != nil {
+			t.Error("Expected no auth without a username")
+		}
+		return errors.New("connection refused")
+	}
+
+	if err := sender.Send(Message{To: []string{"user@example.com"}, Subject: "Hello"}); err == nil {
+		t.Error("Expected send error to be returned")
+	}
+}
@@ -0,0 +1,91 @@
+package ntfy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"watered/internal/config"
+)
+
+// Message priorities, shown by the ntfy apps as how insistently to alert
+const (
+	PriorityMin     = 1
+	PriorityLow     = 2
+	PriorityDefault = 3
+	PriorityHigh    = 4
+	PriorityUrgent  = 5
+)
+
+// Message is a notification published with ntfy's JSON API
+type Message struct {
+	Topic    string `json:"topic"`
+	Title    string `json:"title,omitempty"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority,omitempty"`
+	// Tags are emoji short codes, such as "droplet", shown before the title
+	Tags []string `json:"tags,omitempty"`
+	// Click is opened when the notification is tapped
+	Click string `json:"click,omitempty"`
+}
+
+// Sender publishes messages to a topic on an ntfy server
+type Sender struct {
+	serverURL string
+	topic     string
+	token     string
+	client    *http.Client
+}
+
+// NewSender creates a sender for the configured server and topic
+func NewSender(cfg config.NtfyConfig) *Sender {
+	return &Sender{
+		serverURL: strings.TrimSuffix(cfg.ServerURL, "/"),
+		topic:     cfg.Topic,
+		token:     cfg.Token,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish sends a message to the topic
+func (s *Sender) Publish(ctx context.Context, msg Message) error {
+	msg.Topic = s.topic
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode ntfy message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.serverURL+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create ntfy request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The server URL may carry basic auth credentials, so keep it out
+		// of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to reach ntfy: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("ntfy returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
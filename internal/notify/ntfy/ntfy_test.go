@@ -0,0 +1,68 @@
+package ntfy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"watered/internal/config"
+)
+
+func TestSender_Publish(t *testing.T) {
+	var got map[string]interface{}
+	var path, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"id":"abc","event":"message"}`))
+	}))
+	defer server.Close()
+
+	sender := NewSender(config.NtfyConfig{ServerURL: server.URL + "/", Topic: "watered-home", Token: "tk_secret"})
+	err := sender.Publish(context.Background(), Message{
+		Title:    "Fern needs water now!",
+		Message:  "It was last watered 3 days ago.",
+		Priority: PriorityUrgent,
+		Tags:     []string{"wilted_flower"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if path != "/" || auth != "Bearer tk_secret" {
+		t.Errorf("Expected a JSON publish to the server root with the token, got %q %q", path, auth)
+	}
+	if got["topic"] != "watered-home" || got["title"] != "Fern needs water now!" || got["priority"] != float64(5) {
+		t.Errorf("Unexpected message: %v", got)
+	}
+	if _, ok := got["click"]; ok {
+		t.Errorf("Expected an empty click URL to be omitted, got %v", got)
+	}
+}
+
+func TestSender_PublishError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Expected no Authorization header without a token")
+		}
+		http.Error(w, `{"code":40301,"http":403,"error":"forbidden"}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	sender := NewSender(config.NtfyConfig{ServerURL: server.URL, Topic: "watered-home"})
+	err := sender.Publish(context.Background(), Message{Message: "hello"})
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "forbidden") {
+		t.Errorf("Expected the ntfy error in the message, got %v", err)
+	}
+
+	// Connection errors must not reveal credentials in the server URL
+	server.Close()
+	sender = NewSender(config.NtfyConfig{ServerURL: strings.Replace(server.URL, "http://", "http://user:secret@", 1), Topic: "watered-home"})
+	err = sender.Publish(context.Background(), Message{Message: "hello"})
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("Expected an error without the server URL, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"watered/internal/config"
+)
+
+// Message is the payload of a Slack incoming webhook. Text may use Slack's
+// mrkdwn formatting, such as *bold* and :emoji: codes.
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Sender posts messages to a Slack incoming webhook
+type Sender struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSender creates a sender for the configured webhook
+func NewSender(cfg config.SlackConfig) *Sender {
+	return &Sender{
+		webhookURL: cfg.WebhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send posts a message to the webhook
+func (s *Sender) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The webhook URL is a credential, so keep it out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to reach Slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"watered/internal/config"
+)
+
+func TestSender_Send(t *testing.T) {
+	var got Message
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	sender := NewSender(config.SlackConfig{WebhookURL: server.URL + "/services/T000/B000/secret"})
+	if err := sender.Send(context.Background(), Message{Text: "*Fern* needs water"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Text != "*Fern* needs water" || contentType != "application/json" {
+		t.Errorf("Unexpected request: %q with content type %q", got.Text, contentType)
+	}
+}
+
+func TestSender_SendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	sender := NewSender(config.SlackConfig{WebhookURL: server.URL + "/services/T000/B000/secret"})
+	err := sender.Send(context.Background(), Message{Text: "hello"})
+	if err == nil || !strings.Contains(err.Error(), "403: invalid_token") {
+		t.Errorf("Expected the Slack error in the message, got %v", err)
+	}
+
+	// Connection errors must not reveal the webhook URL
+	server.Close()
+	err = sender.Send(context.Background(), Message{Text: "hello"})
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("Expected an error without the webhook URL, got %v", err)
+	}
+}
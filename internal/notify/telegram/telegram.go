@@ -0,0 +1,113 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"watered/internal/config"
+)
+
+// DefaultAPIURL is the Telegram Bot API
+const DefaultAPIURL = "https://api.telegram.org"
+
+// SecretHeader carries the secret_token the webhook was registered with
+const SecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// Update is an incoming webhook update. Only messages are used.
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message,omitempty"`
+}
+
+// Message is a chat message sent to the bot
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	From      *User  `json:"from,omitempty"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text,omitempty"`
+}
+
+// User is the Telegram account that sent a message
+type User struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+}
+
+// Chat is the chat a message was sent in
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+// Reply answers a webhook update in the response body, saving a separate
+// call to the Bot API
+type Reply struct {
+	Method string `json:"method"`
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+// NewReply returns a reply sending text to a chat
+func NewReply(chatID int64, text string) Reply {
+	return Reply{Method: "sendMessage", ChatID: chatID, Text: text}
+}
+
+// Sender posts messages through the Telegram Bot API
+type Sender struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+// NewSender creates a sender for the configured bot
+func NewSender(cfg config.TelegramConfig) *Sender {
+	return &Sender{
+		apiURL: DefaultAPIURL,
+		token:  cfg.BotToken,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SendMessage posts text to a chat
+func (s *Sender) SendMessage(ctx context.Context, chatID int64, text string) error {
+	body, err := json.Marshal(map[string]interface{}{"chat_id": chatID, "text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode Telegram message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.apiURL+"/bot"+s.token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Telegram request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The request URL contains the bot token, so keep it out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to reach Telegram: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var result struct {
+			Description string `json:"description"`
+		}
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if json.Unmarshal(message, &result) == nil && result.Description != "" {
+			message = []byte(result.Description)
+		}
+		return fmt.Errorf("telegram returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
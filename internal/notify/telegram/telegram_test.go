@@ -0,0 +1,57 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"watered/internal/config"
+)
+
+func TestSender_SendMessage(t *testing.T) {
+	var path string
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	sender := NewSender(config.TelegramConfig{BotToken: "123:secret"})
+	sender.apiURL = server.URL
+	if err := sender.SendMessage(context.Background(), -100123, "Fern needs water"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if path != "/bot123:secret/sendMessage" {
+		t.Errorf("Unexpected path %q", path)
+	}
+	if got["chat_id"] != float64(-100123) || got["text"] != "Fern needs water" {
+		t.Errorf("Unexpected request: %v", got)
+	}
+}
+
+func TestSender_SendMessageError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+	}))
+	defer server.Close()
+
+	sender := NewSender(config.TelegramConfig{BotToken: "123:secret"})
+	sender.apiURL = server.URL
+	err := sender.SendMessage(context.Background(), 42, "hello")
+	if err == nil || !strings.Contains(err.Error(), "400: Bad Request: chat not found") {
+		t.Errorf("Expected the Telegram error in the message, got %v", err)
+	}
+
+	// Connection errors must not reveal the bot token
+	server.Close()
+	err = sender.SendMessage(context.Background(), 42, "hello")
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("Expected an error without the bot token, got %v", err)
+	}
+}
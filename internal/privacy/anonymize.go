@@ -0,0 +1,74 @@
+package privacy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+
+	"watered/internal/config"
+)
+
+// anonymousPrefix marks identifiers that were produced by the Anonymizer
+const anonymousPrefix = "user-"
+
+// Anonymizer replaces email addresses with stable salted hashes so usage
+// reports can be shared without exposing identities
+type Anonymizer struct {
+	salt    []byte
+	enabled bool
+}
+
+// NewAnonymizer creates an anonymizer with the given salt. An empty salt is
+// replaced with a random one, which keeps hashes stable only for this process.
+func NewAnonymizer(salt string, enabled bool) *Anonymizer {
+	saltBytes := []byte(salt)
+	if len(saltBytes) == 0 {
+		saltBytes = make([]byte, 32)
+		if _, err := rand.Read(saltBytes); err != nil {
+			slog.Warn("Failed to generate anonymization salt", "error", err)
+		}
+	}
+
+	return &Anonymizer{
+		salt:    saltBytes,
+		enabled: enabled,
+	}
+}
+
+// NewAnonymizerFromConfig creates an anonymizer from the privacy settings
+func NewAnonymizerFromConfig(cfg config.PrivacyConfig) *Anonymizer {
+	if cfg.AnonymizeAnalytics && cfg.AnonymizationSalt == "" {
+		slog.Warn("ANONYMIZATION_SALT not set, anonymized IDs will change on restart")
+	}
+
+	return NewAnonymizer(cfg.AnonymizationSalt, cfg.AnonymizeAnalytics)
+}
+
+// Enabled reports whether anonymization is on by default for this deployment
+func (a *Anonymizer) Enabled() bool {
+	return a.enabled
+}
+
+// Email returns a stable pseudonymous identifier for an email address
+func (a *Anonymizer) Email(email string) string {
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, a.salt)
+	mac.Write([]byte(email))
+	return anonymousPrefix + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// Emails anonymizes a list of email addresses
+func (a *Anonymizer) Emails(emails []string) []string {
+	result := make([]string, len(emails))
+	for i, email := range emails {
+		result[i] = a.Email(email)
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package privacy
+
+import (
+	"strings"
+	"testing"
+
+	"watered/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymizer_Email(t *testing.T) {
+	a := NewAnonymizer("test-salt", true)
+
+	hashed := a.Email("test@example.com")
+	assert.True(t, strings.HasPrefix(hashed, "user-"))
+	assert.NotContains(t, hashed, "example.com")
+
+	// Hashes are stable and case-insensitive
+	assert.Equal(t, hashed, a.Email("Test@Example.com "))
+	assert.NotEqual(t, hashed, a.Email("other@example.com"))
+
+	// Different salts produce different hashes
+	assert.NotEqual(t, hashed, NewAnonymizer("other-salt", true).Email("test@example.com"))
+
+	assert.Equal(t, "", a.Email(""))
+}
+
+func TestAnonymizer_Emails(t *testing.T) {
+	a := NewAnonymizer("test-salt", false)
+
+	result := a.Emails([]string{"a@example.com", "b@example.com"})
+	assert.Len(t, result, 2)
+	assert.Equal(t, a.Email("a@example.com"), result[0])
+	assert.False(t, a.Enabled())
+}
+
+func TestNewAnonymizerFromConfig(t *testing.T) {
+	a := NewAnonymizerFromConfig(config.PrivacyConfig{AnonymizeAnalytics: true, AnonymizationSalt: "env-salt"})
+	assert.True(t, a.Enabled())
+	assert.Equal(t, NewAnonymizer("env-salt", true).Email("test@example.com"), a.Email("test@example.com"))
+}
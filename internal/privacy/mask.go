@@ -0,0 +1,116 @@
+package privacy
+
+import "reflect"
+
+// MaskTag is the struct tag naming the least privileged role allowed to see
+// a field, e.g. `mask:"member"`. Fields the caller may not see are cleared
+// before the response is encoded.
+const MaskTag = "mask"
+
+// Role is how much of a response a caller may see, and for signed-in users
+// what they may change
+type Role int
+
+const (
+	// RoleViewer is an anonymous visitor, an API key client or a signed-in
+	// read-only user such as a plant sitter
+	RoleViewer Role = iota
+	// RoleMember is a signed-in household member
+	RoleMember
+	// RoleAdmin is a signed-in administrator
+	RoleAdmin
+)
+
+// String returns the role name used in mask tags
+func (r Role) String() string {
+	switch r {
+	case RoleAdmin:
+		return "admin"
+	case RoleMember:
+		return "member"
+	default:
+		return "viewer"
+	}
+}
+
+// ParseRole parses a role name. Unknown names are treated as admin so a
+// mistyped tag hides the field rather than exposing it.
+func ParseRole(name string) Role {
+	switch name {
+	case "viewer":
+		return RoleViewer
+	case "member":
+		return RoleMember
+	default:
+		return RoleAdmin
+	}
+}
+
+// Mask returns a copy of v with every field the role may not see set to its
+// zero value. Structs, pointers, slices, maps and interface values are
+// walked recursively; v itself is never modified.
+func Mask(v interface{}, role Role) interface{} {
+	if v == nil {
+		return nil
+	}
+	return mask(reflect.ValueOf(v), role).Interface()
+}
+
+// mask copies v, clearing fields tagged for roles above role
+func mask(v reflect.Value, role Role) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Elem().Type())
+		out.Elem().Set(mask(v.Elem(), role))
+		return out
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		return mask(v.Elem(), role)
+
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if required, ok := field.Tag.Lookup(MaskTag); ok && role < ParseRole(required) {
+				out.Field(i).SetZero()
+				continue
+			}
+			out.Field(i).Set(mask(v.Field(i), role))
+		}
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(mask(v.Index(i), role))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), mask(iter.Value(), role))
+		}
+		return out
+
+	default:
+		return v
+	}
+}
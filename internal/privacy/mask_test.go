@@ -0,0 +1,89 @@
+package privacy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type maskedPlant struct {
+	Name      string      `json:"name"`
+	WateredBy string      `json:"watered_by" mask:"member"`
+	Secret    string      `json:"secret" mask:"admin"`
+	Typo      string      `json:"typo" mask:"membr"`
+	Owner     *maskedUser `json:"owner"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type maskedUser struct {
+	Email string `json:"email" mask:"member"`
+	Name  string `json:"name"`
+}
+
+func TestParseRole(t *testing.T) {
+	for _, role := range []Role{RoleViewer, RoleMember, RoleAdmin} {
+		assert.Equal(t, role, ParseRole(role.String()))
+	}
+	assert.Equal(t, RoleAdmin, ParseRole("unknown"))
+}
+
+func TestMask(t *testing.T) {
+	now := time.Now()
+	plant := &maskedPlant{
+		Name:      "Fern",
+		WateredBy: "user@example.com",
+		Secret:    "s3cret",
+		Typo:      "hidden",
+		Owner:     &maskedUser{Email: "owner@example.com", Name: "Owner"},
+		UpdatedAt: now,
+	}
+
+	tests := []struct {
+		role      Role
+		wateredBy string
+		secret    string
+		email     string
+	}{
+		{RoleViewer, "", "", ""},
+		{RoleMember, "user@example.com", "", "owner@example.com"},
+		{RoleAdmin, "user@example.com", "s3cret", "owner@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role.String(), func(t *testing.T) {
+			masked := Mask(plant, tt.role).(*maskedPlant)
+			assert.Equal(t, "Fern", masked.Name)
+			assert.Equal(t, tt.wateredBy, masked.WateredBy)
+			assert.Equal(t, tt.secret, masked.Secret)
+			assert.Equal(t, tt.email, masked.Owner.Email)
+			assert.Equal(t, "Owner", masked.Owner.Name)
+			assert.True(t, masked.UpdatedAt.Equal(now))
+			assert.Equal(t, tt.role == RoleAdmin, masked.Typo != "", "unknown roles are admin only")
+		})
+	}
+
+	// The original is never modified
+	assert.Equal(t, "user@example.com", plant.WateredBy)
+	assert.Equal(t, "owner@example.com", plant.Owner.Email)
+}
+
+func TestMask_Containers(t *testing.T) {
+	response := map[string]interface{}{
+		"count":  2,
+		"plants": []*maskedPlant{{Name: "Fern", WateredBy: "a@example.com"}, nil},
+		"owner":  maskedUser{Email: "b@example.com", Name: "B"},
+		"raw":    []byte("data"),
+	}
+
+	masked := Mask(response, RoleViewer).(map[string]interface{})
+	assert.Equal(t, 2, masked["count"])
+	plants := masked["plants"].([]*maskedPlant)
+	assert.Equal(t, "", plants[0].WateredBy)
+	assert.Nil(t, plants[1])
+	assert.Equal(t, "", masked["owner"].(maskedUser).Email)
+	assert.Equal(t, []byte("data"), masked["raw"])
+
+	assert.Equal(t, "a@example.com", response["plants"].([]*maskedPlant)[0].WateredBy)
+	assert.Nil(t, Mask(nil, RoleViewer))
+}
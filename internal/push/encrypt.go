@@ -0,0 +1,100 @@
+package push
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// recordSize is the aes128gcm record size advertised in the payload header.
+// Payloads are sent as a single record, so they must fit within it.
+const recordSize = 4096
+
+// MaxPayloadSize is the largest plaintext payload that fits in one record
+// (record size minus the 16-byte GCM tag and the 1-byte padding delimiter)
+const MaxPayloadSize = recordSize - 16 - 1
+
+// encrypt encrypts a payload for a subscription using RFC 8291 aes128gcm.
+// uaPublic is the subscription's p256dh key and authSecret its auth secret.
+func encrypt(payload, uaPublic, authSecret []byte, random io.Reader) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, fmt.Errorf("payload too large: %d bytes (max %d)", len(payload), MaxPayloadSize)
+	}
+
+	curve := ecdh.P256()
+	uaKey, err := curve.NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription p256dh key: %w", err)
+	}
+	if len(authSecret) != 16 {
+		return nil, fmt.Errorf("invalid subscription auth secret length %d", len(authSecret))
+	}
+
+	asKey, err := curve.GenerateKey(random)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	asPublic := asKey.PublicKey().Bytes()
+
+	ecdhSecret, err := asKey.ECDH(uaKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive shared secret: %w", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(random, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	cek, nonce := deriveKeys(ecdhSecret, authSecret, uaPublic, asPublic, salt)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single, final record: payload followed by the 0x02 padding delimiter
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+
+	// Header: salt(16) || rs(4) || idlen(1) || keyid(as_public)
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return append(header, ciphertext...), nil
+}
+
+// deriveKeys derives the content encryption key and nonce (RFC 8291 section 3.4)
+func deriveKeys(ecdhSecret, authSecret, uaPublic, asPublic, salt []byte) ([]byte, []byte) {
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm := hkdf(authSecret, ecdhSecret, keyInfo, 32)
+
+	cek := hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+	return cek, nonce
+}
+
+// hkdf implements HKDF-SHA256 (RFC 5869) for outputs of at most one hash block
+func hkdf(salt, ikm, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(ikm)
+	prk := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, prk)
+	expand.Write(info)
+	expand.Write([]byte{0x01})
+	return expand.Sum(nil)[:length]
+}
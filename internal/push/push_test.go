@@ -0,0 +1,214 @@
+package push
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBrowser is a simulated user agent holding subscription keys
+type testBrowser struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+}
+
+func newTestBrowser(t *testing.T) *testBrowser {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return &testBrowser{key: key, auth: auth}
+}
+
+func (b *testBrowser) subscription(endpoint string) Subscription {
+	return Subscription{
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(b.key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(b.auth),
+	}
+}
+
+// decrypt reverses encrypt the way a browser would
+func (b *testBrowser) decrypt(t *testing.T, body []byte) []byte {
+	salt := body[:16]
+	rs := binary.BigEndian.Uint32(body[16:20])
+	idlen := int(body[20])
+	asPublic := body[21 : 21+idlen]
+	ciphertext := body[21+idlen:]
+	assert.Equal(t, uint32(recordSize), rs)
+
+	asKey, err := ecdh.P256().NewPublicKey(asPublic)
+	require.NoError(t, err)
+	secret, err := b.key.ECDH(asKey)
+	require.NoError(t, err)
+
+	cek, nonce := deriveKeys(secret, b.auth, b.key.PublicKey().Bytes(), asPublic, salt)
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1], "expected final record delimiter")
+	return plaintext[:len(plaintext)-1]
+}
+
+func TestHKDF_RFC5869Vector(t *testing.T) {
+	// RFC 5869 test case 1, truncated to one hash block
+	ikm := make([]byte, 22)
+	for i := range ikm {
+		ikm[i] = 0x0b
+	}
+	salt := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c}
+	info := []byte{0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9}
+
+	okm := hkdf(salt, ikm, info, 32)
+
+	expected := "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf"
+	assert.Equal(t, expected, hexString(okm))
+}
+
+func hexString(b []byte) string {
+	const digits = "0123456789abcdef"
+	var sb strings.Builder
+	for _, c := range b {
+		sb.WriteByte(digits[c>>4])
+		sb.WriteByte(digits[c&0x0f])
+	}
+	return sb.String()
+}
+
+func TestEncrypt_RoundTrip(t *testing.T) {
+	browser := newTestBrowser(t)
+	payload := []byte(`{"title":"Water me!"}`)
+
+	body, err := encrypt(payload, browser.key.PublicKey().Bytes(), browser.auth, rand.Reader)
+	require.NoError(t, err)
+
+	assert.Equal(t, payload, browser.decrypt(t, body))
+}
+
+func TestEncrypt_RejectsOversizedPayload(t *testing.T) {
+	browser := newTestBrowser(t)
+	_, err := encrypt(make([]byte, MaxPayloadSize+1), browser.key.PublicKey().Bytes(), browser.auth, rand.Reader)
+	assert.Error(t, err)
+}
+
+func TestNewVAPIDKeys(t *testing.T) {
+	keys, err := GenerateVAPIDKeys("mailto:admin@example.com")
+	require.NoError(t, err)
+
+	parsed, err := NewVAPIDKeys(keys.PublicKey, keys.PrivateKey, keys.Subject)
+	require.NoError(t, err)
+	assert.Equal(t, keys.PublicKey, parsed.PublicKey)
+
+	other, _ := GenerateVAPIDKeys("mailto:admin@example.com")
+	_, err = NewVAPIDKeys(other.PublicKey, keys.PrivateKey, keys.Subject)
+	assert.Error(t, err, "mismatched key pair should be rejected")
+
+	_, err = NewVAPIDKeys(keys.PublicKey, keys.PrivateKey, "")
+	assert.Error(t, err, "missing subject should be rejected")
+}
+
+// verifyVAPID checks the Authorization header's JWT signature and claims
+func verifyVAPID(t *testing.T, header string, keys *VAPIDKeys, audience string) {
+	require.True(t, strings.HasPrefix(header, "vapid t="))
+	parts := strings.SplitN(strings.TrimPrefix(header, "vapid t="), ", k=", 2)
+	require.Len(t, parts, 2)
+	assert.Equal(t, keys.PublicKey, parts[1])
+
+	segments := strings.Split(parts[0], ".")
+	require.Len(t, segments, 3)
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(segments[1])
+	require.NoError(t, err)
+	var claims map[string]interface{}
+	require.NoError(t, json.Unmarshal(claimsJSON, &claims))
+	assert.Equal(t, audience, claims["aud"])
+	assert.Equal(t, keys.Subject, claims["sub"])
+	assert.Greater(t, claims["exp"].(float64), float64(time.Now().Unix()))
+
+	signature, err := base64.RawURLEncoding.DecodeString(segments[2])
+	require.NoError(t, err)
+	require.Len(t, signature, 64)
+	digest := sha256.Sum256([]byte(segments[0] + "." + segments[1]))
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	assert.True(t, ecdsa.Verify(&keys.signer.PublicKey, digest[:], r, s), "VAPID signature should verify")
+}
+
+func TestSender_Send(t *testing.T) {
+	keys, err := GenerateVAPIDKeys("mailto:admin@example.com")
+	require.NoError(t, err)
+	browser := newTestBrowser(t)
+
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "aes128gcm", r.Header.Get("Content-Encoding"))
+		assert.NotEmpty(t, r.Header.Get("TTL"))
+		verifyVAPID(t, r.Header.Get("Authorization"), keys, "http://"+r.Host)
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	sender := NewSender(keys)
+	err = sender.Send(context.Background(), browser.subscription(server.URL+"/push/abc"), []byte("hello"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("hello"), browser.decrypt(t, received))
+}
+
+func TestSender_SubscriptionGone(t *testing.T) {
+	keys, _ := GenerateVAPIDKeys("mailto:admin@example.com")
+	browser := newTestBrowser(t)
+
+	for _, status := range []int{http.StatusNotFound, http.StatusGone} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+		err := NewSender(keys).Send(context.Background(), browser.subscription(server.URL), []byte("hello"))
+		assert.True(t, errors.Is(err, ErrSubscriptionGone), "status %d should report a gone subscription", status)
+		server.Close()
+	}
+}
+
+func TestValidateSubscription(t *testing.T) {
+	browser := newTestBrowser(t)
+	valid := browser.subscription("https://push.example.com/send/abc")
+
+	assert.NoError(t, ValidateSubscription(valid))
+
+	insecure := valid
+	insecure.Endpoint = "http://push.example.com/send/abc"
+	assert.Error(t, ValidateSubscription(insecure))
+
+	badKey := valid
+	badKey.P256dh = "short"
+	assert.Error(t, ValidateSubscription(badKey))
+
+	badAuth := valid
+	badAuth.Auth = base64.RawURLEncoding.EncodeToString([]byte("too-short"))
+	assert.Error(t, ValidateSubscription(badAuth))
+}
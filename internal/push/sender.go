@@ -0,0 +1,124 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultTTL is how long a push service should hold an undelivered message
+const DefaultTTL = 24 * time.Hour
+
+// ErrSubscriptionGone is returned when the push service reports that a
+// subscription has expired or been unsubscribed (404/410); it should be deleted
+var ErrSubscriptionGone = errors.New("push subscription is no longer valid")
+
+// Subscription is the browser-provided target of a push message
+type Subscription struct {
+	Endpoint string
+	// P256dh is the browser's public key, base64url encoded
+	P256dh string
+	// Auth is the browser's auth secret, base64url encoded
+	Auth string
+}
+
+// Sender delivers encrypted push messages to push services
+type Sender struct {
+	keys   *VAPIDKeys
+	client *http.Client
+	ttl    time.Duration
+}
+
+// NewSender creates a push sender authenticated with the given VAPID keys
+func NewSender(keys *VAPIDKeys) *Sender {
+	return &Sender{
+		keys:   keys,
+		client: &http.Client{Timeout: 15 * time.Second},
+		ttl:    DefaultTTL,
+	}
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with
+func (s *Sender) PublicKey() string {
+	return s.keys.PublicKey
+}
+
+// Send encrypts payload for the subscription and posts it to its push service
+func (s *Sender) Send(ctx context.Context, sub Subscription, payload []byte) error {
+	uaPublic, err := decodeKey(sub.P256dh)
+	if err != nil {
+		return fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decodeKey(sub.Auth)
+	if err != nil {
+		return fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	body, err := encrypt(payload, uaPublic, authSecret, rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	authorization, err := s.keys.authorization(sub.Endpoint, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(s.ttl.Seconds())))
+	req.Header.Set("Urgency", "high")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach push service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("push service returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// decodeKey decodes a base64url key, accepting padded and unpadded forms
+func decodeKey(value string) ([]byte, error) {
+	if decoded, err := base64.RawURLEncoding.DecodeString(value); err == nil {
+		return decoded, nil
+	}
+	return base64.URLEncoding.DecodeString(value)
+}
+
+// ValidateSubscription checks that a subscription's keys are well formed
+func ValidateSubscription(sub Subscription) error {
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("endpoint must be an https URL")
+	}
+	uaPublic, err := decodeKey(sub.P256dh)
+	if err != nil || len(uaPublic) != 65 {
+		return fmt.Errorf("p256dh must be a base64url-encoded 65-byte P-256 public key")
+	}
+	authSecret, err := decodeKey(sub.Auth)
+	if err != nil || len(authSecret) != 16 {
+		return fmt.Errorf("auth must be a base64url-encoded 16-byte secret")
+	}
+	return nil
+}
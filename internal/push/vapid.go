@@ -0,0 +1,123 @@
+// Package push sends Web Push notifications (RFC 8030) with VAPID
+// authentication (RFC 8292) and aes128gcm payload encryption (RFC 8291).
+package push
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+
+	"watered/internal/config"
+)
+
+// vapidTokenTTL is how long a signed VAPID JWT stays valid (RFC 8292 allows up to 24h)
+const vapidTokenTTL = 12 * time.Hour
+
+// VAPIDKeys identifies this server to push services
+type VAPIDKeys struct {
+	// PublicKey is the uncompressed P-256 public key, base64url encoded without
+	// padding; browsers pass it as applicationServerKey when subscribing
+	PublicKey string
+	// PrivateKey is the raw P-256 private scalar, base64url encoded without padding
+	PrivateKey string
+	// Subject is a contact URI for the push service operator (mailto: or https:)
+	Subject string
+
+	signer *ecdsa.PrivateKey
+}
+
+// GenerateVAPIDKeys creates a new VAPID key pair
+func GenerateVAPIDKeys(subject string) (*VAPIDKeys, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate VAPID key: %w", err)
+	}
+	return NewVAPIDKeys(
+		base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()),
+		subject,
+	)
+}
+
+// NewVAPIDKeys parses and validates an encoded VAPID key pair
+func NewVAPIDKeys(publicKey, privateKey, subject string) (*VAPIDKeys, error) {
+	rawPrivate, err := base64.RawURLEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key encoding: %w", err)
+	}
+	ecdhKey, err := ecdh.P256().NewPrivateKey(rawPrivate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	derivedPublic := base64.RawURLEncoding.EncodeToString(ecdhKey.PublicKey().Bytes())
+	if publicKey != derivedPublic {
+		return nil, fmt.Errorf("VAPID public key does not match private key")
+	}
+
+	if subject == "" {
+		return nil, fmt.Errorf("VAPID subject is required (mailto: or https: URI)")
+	}
+
+	curve := elliptic.P256()
+	d := new(big.Int).SetBytes(rawPrivate)
+	x, y := curve.ScalarBaseMult(rawPrivate)
+
+	return &VAPIDKeys{
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		Subject:    subject,
+		signer: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y},
+			D:         d,
+		},
+	}, nil
+}
+
+// LoadVAPIDKeys builds the key pair from the push settings. It returns nil
+// without error when push is not configured.
+func LoadVAPIDKeys(cfg config.PushConfig) (*VAPIDKeys, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	return NewVAPIDKeys(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, cfg.VAPIDSubject)
+}
+
+// authorization builds the VAPID Authorization header value for a push endpoint
+func (k *VAPIDKeys) authorization(endpoint string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid push endpoint: %w", err)
+	}
+
+	header, _ := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(vapidTokenTTL).Unix(),
+		"sub": k.Subject,
+	})
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	r, s, err := ecdsa.Sign(rand.Reader, k.signer, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+
+	// JWS ES256 signatures are the fixed-width concatenation r || s
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	token := signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+	return fmt.Sprintf("vapid t=%s, k=%s", token, k.PublicKey), nil
+}
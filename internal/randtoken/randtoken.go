@@ -0,0 +1,28 @@
+// Package randtoken generates the random secrets behind API keys, sessions,
+// share links and device tokens, and hashes them for storage. Only the hash
+// is stored, so a leaked data file doesn't hand out working credentials.
+package randtoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// String encodes n random bytes with encode, such as hex.EncodeToString for
+// IDs or base64.RawURLEncoding.EncodeToString for secrets
+func String(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
+
+// Hash returns the hex SHA-256 a token is stored under. Secrets carry 256
+// bits of randomness, so a fast hash is sufficient; a slow password hash
+// would only make every request slower.
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,33 @@
+package randtoken
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	first, err := String(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		t.Fatalf("Failed to generate string: %v", err)
+	}
+	second, _ := String(32, base64.RawURLEncoding.EncodeToString)
+	if len(first) != 43 || first == second {
+		t.Errorf("Expected two different 43 character strings, got %q and %q", first, second)
+	}
+
+	id, _ := String(8, hex.EncodeToString)
+	if len(id) != 16 {
+		t.Errorf("Expected a 16 character hex ID, got %q", id)
+	}
+}
+
+func TestHash(t *testing.T) {
+	// SHA-256 of "abc"
+	if got := Hash("abc"); got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("Unexpected hash %s", got)
+	}
+	if Hash("wk_a") == Hash("wk_b") {
+		t.Error("Expected different tokens to hash differently")
+	}
+}
@@ -0,0 +1,274 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/respond"
+)
+
+// bucket holds one client's tokens and lifetime counters
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limited bool
+
+	requests int
+	rejected int
+	lastSeen time.Time
+}
+
+// BucketClientStats reports a client's bucket since the server started
+type BucketClientStats struct {
+	Key string `json:"key"`
+	// Tokens is how many requests the client may send right now
+	Tokens   int       `json:"tokens"`
+	Requests int       `json:"requests"`
+	Rejected int       `json:"rejected"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// BucketStats summarises one bucket limiter for the admin panel
+type BucketStats struct {
+	Name     string              `json:"name"`
+	Burst    int                 `json:"burst"`
+	Refill   string              `json:"refill"`
+	Requests int                 `json:"requests"`
+	Rejected int                 `json:"rejected"`
+	Clients  []BucketClientStats `json:"clients"`
+}
+
+// BucketLimiter gives each client a token bucket: a burst of requests is
+// allowed at once, after which requests are refused until tokens refill.
+// It guards endpoints that are cheap to abuse, such as sign-in and writes,
+// more tightly than the per-window Limiter.
+type BucketLimiter struct {
+	name   string
+	burst  int
+	refill time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+// NewBucketLimiter creates a named bucket limiter. It returns nil when the
+// bucket is disabled.
+func NewBucketLimiter(name string, cfg config.BucketConfig) *BucketLimiter {
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	return &BucketLimiter{
+		name:    name,
+		burst:   cfg.Burst,
+		refill:  cfg.Refill,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Name returns the limiter's name, such as auth or write
+func (b *BucketLimiter) Name() string {
+	return b.name
+}
+
+// fill tops up a bucket with the tokens earned since it was last updated
+func (b *BucketLimiter) fill(c *bucket, now time.Time) {
+	earned := float64(now.Sub(c.updated)) / float64(b.refill)
+	c.tokens = math.Min(float64(b.burst), c.tokens+earned)
+	c.updated = now
+}
+
+// bucketDecision is the outcome of taking tokens for one request
+type bucketDecision struct {
+	// remaining and reset describe the emptiest bucket the request drew from
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+	allowed    bool
+}
+
+// take draws a token from every key's bucket, or from none of them if any
+// is empty
+func (b *BucketLimiter) take(keys []string) bucketDecision {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.prune(now)
+
+	buckets := make([]*bucket, len(keys))
+	emptiest := -1
+	for i, key := range keys {
+		c, ok := b.buckets[key]
+		if !ok {
+			c = &bucket{tokens: float64(b.burst), updated: now}
+			b.buckets[key] = c
+		}
+		b.fill(c, now)
+		c.requests++
+		c.lastSeen = now
+		buckets[i] = c
+		if emptiest < 0 || c.tokens < buckets[emptiest].tokens {
+			emptiest = i
+		}
+	}
+
+	d := bucketDecision{allowed: buckets[emptiest].tokens >= 1}
+	if d.allowed {
+		for _, c := range buckets {
+			c.tokens--
+			c.limited = false
+		}
+	} else {
+		c := buckets[emptiest]
+		c.rejected++
+		d.retryAfter = time.Duration((1 - c.tokens) * float64(b.refill))
+		if !c.limited {
+			c.limited = true
+			slog.Warn("Rate limit bucket empty, refusing requests", "limiter", b.name, "client", keys[emptiest], "burst", b.burst, "refill", b.refill)
+		}
+	}
+
+	c := buckets[emptiest]
+	d.remaining = int(math.Floor(c.tokens))
+	d.reset = time.Duration((float64(b.burst) - c.tokens) * float64(b.refill))
+	return d
+}
+
+// prune drops clients that have been idle for a day, at most once a refill
+func (b *BucketLimiter) prune(now time.Time) {
+	if now.Sub(b.lastPrune) < b.refill {
+		return
+	}
+	b.lastPrune = now
+
+	for key, c := range b.buckets {
+		if now.Sub(c.lastSeen) > idleClientTTL {
+			delete(b.buckets, key)
+		}
+	}
+}
+
+// seconds rounds a wait up to whole seconds for response headers
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// Middleware takes a token from the bucket of every client key named by
+// keys, so a signed-in user is limited both on their own and by their
+// address. Empty and repeated keys are skipped. Refused requests get 429
+// with Retry-After and X-RateLimit-* headers describing the emptiest
+// bucket; allowed requests carry the headers unless an outer Limiter has
+// already set them.
+func (b *BucketLimiter) Middleware(keys ...KeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clients := make([]string, 0, len(keys))
+			seen := make(map[string]bool, len(keys))
+			for _, key := range keys {
+				if client := key(r); client != "" && !seen[client] {
+					seen[client] = true
+					clients = append(clients, client)
+				}
+			}
+			if len(clients) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			d := b.take(clients)
+			if !d.allowed || w.Header().Get(HeaderLimit) == "" {
+				w.Header().Set(HeaderLimit, strconv.Itoa(b.burst))
+				w.Header().Set(HeaderRemaining, strconv.Itoa(d.remaining))
+				w.Header().Set(HeaderReset, strconv.Itoa(seconds(d.reset)))
+				w.Header().Del(HeaderWarning)
+			}
+
+			if !d.allowed {
+				retryAfter := seconds(d.retryAfter)
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				respond.Error(w, http.StatusTooManyRequests, respond.CodeRateLimited, fmt.Sprintf("Too many %s requests, retry in %d seconds", b.name, retryAfter))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Reset refills a client's bucket. It reports whether the client was known.
+func (b *BucketLimiter) Reset(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.buckets[key]; !ok {
+		return false
+	}
+	delete(b.buckets, key)
+	return true
+}
+
+// Stats returns the bucket settings and per-client counters, most refused
+// clients first
+func (b *BucketLimiter) Stats() BucketStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	stats := BucketStats{
+		Name:    b.name,
+		Burst:   b.burst,
+		Refill:  b.refill.String(),
+		Clients: make([]BucketClientStats, 0, len(b.buckets)),
+	}
+
+	for key, c := range b.buckets {
+		b.fill(c, now)
+		stats.Clients = append(stats.Clients, BucketClientStats{
+			Key:      key,
+			Tokens:   int(math.Floor(c.tokens)),
+			Requests: c.requests,
+			Rejected: c.rejected,
+			LastSeen: c.lastSeen,
+		})
+		stats.Requests += c.requests
+		stats.Rejected += c.rejected
+	}
+
+	sort.Slice(stats.Clients, func(i, j int) bool {
+		if stats.Clients[i].Rejected != stats.Clients[j].Rejected {
+			return stats.Clients[i].Rejected > stats.Clients[j].Rejected
+		}
+		if stats.Clients[i].Requests != stats.Clients[j].Requests {
+			return stats.Clients[i].Requests > stats.Clients[j].Requests
+		}
+		return stats.Clients[i].Key < stats.Clients[j].Key
+	})
+	return stats
+}
+
+// WritesOnly applies limit to requests that change state and passes reads
+// straight through
+func WritesOnly(limit func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		limited := limit(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+			default:
+				limited.ServeHTTP(w, r)
+			}
+		})
+	}
+}
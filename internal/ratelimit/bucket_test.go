@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBuckets returns a bucket limiter with a controllable clock wrapped
+// around a handler that always succeeds, keyed by the X-Client and X-User
+// test headers
+func newTestBuckets(t *testing.T, burst int, refill time.Duration) (*BucketLimiter, http.Handler, *time.Time) {
+	t.Helper()
+
+	limiter := NewBucketLimiter("auth", config.BucketConfig{Burst: burst, Refill: refill})
+	require.NotNil(t, limiter)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	handler := limiter.Middleware(
+		func(r *http.Request) string { return r.Header.Get("X-Client") },
+		func(r *http.Request) string { return r.Header.Get("X-User") },
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	return limiter, handler, &now
+}
+
+// requestAs sends one request from the client address and user
+func requestAs(handler http.Handler, client, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/auth/recovery", nil)
+	req.Header.Set("X-Client", client)
+	req.Header.Set("X-User", user)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestNewBucketLimiterDisabled(t *testing.T) {
+	assert.Nil(t, NewBucketLimiter("auth", config.BucketConfig{Burst: 0, Refill: time.Second}))
+}
+
+func TestBucketBurstAndRefill(t *testing.T) {
+	_, handler, now := newTestBuckets(t, 2, 10*time.Second)
+
+	for i, remaining := range []string{"1", "0"} {
+		rr := requestAs(handler, "ip:a", "")
+		assert.Equal(t, http.StatusOK, rr.Code, "request %d", i+1)
+		assert.Equal(t, "2", rr.Header().Get(HeaderLimit), "request %d", i+1)
+		assert.Equal(t, remaining, rr.Header().Get(HeaderRemaining), "request %d", i+1)
+	}
+
+	rr := requestAs(handler, "ip:a", "")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "10", rr.Header().Get("Retry-After"))
+	assert.Equal(t, "0", rr.Header().Get(HeaderRemaining))
+	assert.Equal(t, "20", rr.Header().Get(HeaderReset))
+	assert.Contains(t, rr.Body.String(), "Too many auth requests")
+
+	// Refused requests do not spend tokens, so one refill lets one through
+	*now = now.Add(4 * time.Second)
+	assert.Equal(t, "6", requestAs(handler, "ip:a", "").Header().Get("Retry-After"))
+	*now = now.Add(6 * time.Second)
+	assert.Equal(t, http.StatusOK, requestAs(handler, "ip:a", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, requestAs(handler, "ip:a", "").Code)
+
+	// Buckets never hold more than the burst
+	*now = now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusOK, requestAs(handler, "ip:a", "").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, requestAs(handler, "ip:a", "").Code)
+}
+
+func TestBucketPerAddressAndUser(t *testing.T) {
+	_, handler, _ := newTestBuckets(t, 2, time.Minute)
+
+	// A user is limited across addresses
+	assert.Equal(t, http.StatusOK, requestAs(handler, "ip:a", "user:alice").Code)
+	assert.Equal(t, http.StatusOK, requestAs(handler, "ip:b", "user:alice").Code)
+	assert.Equal(t, http.StatusTooManyRequests, requestAs(handler, "ip:c", "user:alice").Code)
+
+	// And an address is limited across users, though ip:a has a token left
+	assert.Equal(t, http.StatusOK, requestAs(handler, "ip:a", "user:bob").Code)
+	assert.Equal(t, http.StatusTooManyRequests, requestAs(handler, "ip:a", "user:carol").Code)
+
+	// Repeated keys draw one token, not two
+	assert.Equal(t, http.StatusOK, requestAs(handler, "ip:d", "ip:d").Code)
+	assert.Equal(t, http.StatusOK, requestAs(handler, "ip:d", "ip:d").Code)
+}
+
+func TestBucketHeadersDeferToLimiter(t *testing.T) {
+	_, buckets, _ := newTestBuckets(t, 1, time.Minute)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderLimit, "120")
+		buckets.ServeHTTP(w, r)
+	})
+
+	assert.Equal(t, "120", requestAs(handler, "ip:a", "").Header().Get(HeaderLimit))
+
+	rr := requestAs(handler, "ip:a", "")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get(HeaderLimit))
+}
+
+func TestBucketStatsAndReset(t *testing.T) {
+	limiter, handler, _ := newTestBuckets(t, 1, time.Minute)
+
+	requestAs(handler, "ip:a", "")
+	requestAs(handler, "ip:a", "")
+	requestAs(handler, "ip:b", "")
+
+	stats := limiter.Stats()
+	assert.Equal(t, "auth", stats.Name)
+	assert.Equal(t, 1, stats.Burst)
+	assert.Equal(t, "1m0s", stats.Refill)
+	assert.Equal(t, 3, stats.Requests)
+	assert.Equal(t, 1, stats.Rejected)
+	require.Len(t, stats.Clients, 2)
+	assert.Equal(t, "ip:a", stats.Clients[0].Key)
+	assert.Equal(t, 1, stats.Clients[0].Rejected)
+	assert.Zero(t, stats.Clients[0].Tokens)
+
+	assert.True(t, limiter.Reset("ip:a"))
+	assert.False(t, limiter.Reset("ip:a"))
+	assert.Equal(t, http.StatusOK, requestAs(handler, "ip:a", "").Code)
+}
+
+func TestWritesOnly(t *testing.T) {
+	_, buckets, _ := newTestBuckets(t, 1, time.Minute)
+	limited := WritesOnly(func(http.Handler) http.Handler { return buckets })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/plant", nil)
+		req.Header.Set("X-Client", "ip:a")
+		rr := httptest.NewRecorder()
+		limited.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get(HeaderLimit))
+	}
+
+	assert.Equal(t, http.StatusOK, requestAs(limited, "ip:a", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, requestAs(limited, "ip:a", "").Code)
+}
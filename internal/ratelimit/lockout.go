@@ -0,0 +1,261 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/respond"
+)
+
+// LockoutActor is the audit log actor for lockouts, which no admin made
+const LockoutActor = "lockout"
+
+// Auditor records lockouts in the audit log
+type Auditor interface {
+	Record(actor, action, target string, oldValue, newValue interface{}) (*models.AuditEntry, error)
+}
+
+// failures tracks one client's failed sign-ins
+type failures struct {
+	count       int
+	lockedUntil time.Time
+	lockouts    int
+	lastFailure time.Time
+}
+
+// LockoutClientStats reports a client address or email with failed sign-ins
+type LockoutClientStats struct {
+	Key      string `json:"key"`
+	Failures int    `json:"failures"`
+	// LockedUntil is when the client may try again, omitted when it is not
+	// locked out
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	Lockouts    int        `json:"lockouts"`
+	LastFailure time.Time  `json:"last_failure"`
+}
+
+// LockoutStats summarises failed sign-ins for the admin panel
+type LockoutStats struct {
+	Threshold int                  `json:"threshold"`
+	Base      string               `json:"base"`
+	Max       string               `json:"max"`
+	Locked    int                  `json:"locked"`
+	Clients   []LockoutClientStats `json:"clients"`
+}
+
+// Lockout slows down password-style guessing at sign-in. Every failed
+// sign-in counts against the client address and, once known, the email it
+// was for. Past the threshold each further failure locks the key out,
+// first for the base duration and twice as long with every failure after,
+// up to the maximum. A successful sign-in clears the email's count; the
+// address keeps its count so one good account can't unlock guessing at
+// others. Failures are forgotten a day after the last one.
+type Lockout struct {
+	threshold int
+	base      time.Duration
+	max       time.Duration
+	now       func() time.Time
+	auditor   Auditor
+
+	mu        sync.Mutex
+	clients   map[string]*failures
+	lastPrune time.Time
+}
+
+// NewLockout creates a lockout. It returns nil when lockouts are disabled.
+func NewLockout(cfg config.LockoutConfig) *Lockout {
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	return &Lockout{
+		threshold: cfg.Threshold,
+		base:      cfg.Base,
+		max:       cfg.Max,
+		now:       time.Now,
+		clients:   make(map[string]*failures),
+	}
+}
+
+// SetAuditor sets the audit log lockouts are recorded in
+func (l *Lockout) SetAuditor(auditor Auditor) {
+	l.auditor = auditor
+}
+
+// EmailKey identifies the account a sign-in was for
+func EmailKey(email string) string {
+	return "email:" + email
+}
+
+// Locked returns how long the most locked out of keys has to wait before
+// trying again, or 0 when none of them are locked out. Empty keys are
+// skipped.
+func (l *Lockout) Locked(keys ...string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var wait time.Duration
+	for _, key := range keys {
+		c, ok := l.clients[key]
+		if key == "" || !ok {
+			continue
+		}
+		wait = max(wait, c.lockedUntil.Sub(now))
+	}
+	return wait
+}
+
+// Fail records a failed sign-in against every key, locking out those past
+// the threshold. Empty keys are skipped.
+func (l *Lockout) Fail(keys ...string) {
+	l.mu.Lock()
+	now := l.now()
+	l.prune(now)
+
+	type lockout struct {
+		key      string
+		failures int
+		until    time.Time
+	}
+	var locked []lockout
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		c, ok := l.clients[key]
+		if !ok {
+			c = &failures{}
+			l.clients[key] = c
+		}
+		c.count++
+		c.lastFailure = now
+		if c.count <= l.threshold {
+			continue
+		}
+		c.lockedUntil = now.Add(l.backoff(c.count - l.threshold))
+		c.lockouts++
+		locked = append(locked, lockout{key: key, failures: c.count, until: c.lockedUntil})
+	}
+	l.mu.Unlock()
+
+	for _, lock := range locked {
+		duration := lock.until.Sub(now)
+		slog.Warn("Too many failed sign-ins, locking out", "audit", true, "client", lock.key, "failures", lock.failures, "duration", duration)
+		if l.auditor == nil {
+			continue
+		}
+		state := map[string]interface{}{"failures": lock.failures, "locked_until": lock.until, "duration": duration.String()}
+		if _, err := l.auditor.Record(LockoutActor, models.AuditAuthLockout, lock.key, nil, state); err != nil {
+			slog.Error("Failed to record audit entry", "action", models.AuditAuthLockout, "target", lock.key, "error", err)
+		}
+	}
+}
+
+// backoff returns how long the nth failure past the threshold locks a
+// client out
+func (l *Lockout) backoff(n int) time.Duration {
+	duration := l.base
+	for i := 1; i < n && duration < l.max; i++ {
+		duration *= 2
+	}
+	return min(duration, l.max)
+}
+
+// Succeed clears the failures of keys after a successful sign-in
+func (l *Lockout) Succeed(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		delete(l.clients, key)
+	}
+}
+
+// prune drops clients a day after their last failure, at most once per base
+// duration
+func (l *Lockout) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < l.base {
+		return
+	}
+	l.lastPrune = now
+
+	for key, c := range l.clients {
+		if now.Sub(c.lastFailure) > idleClientTTL && !now.Before(c.lockedUntil) {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// Refuse answers a locked out sign-in with 429 and Retry-After, reporting
+// whether it did. A wait of 0 means the client isn't locked out.
+func (l *Lockout) Refuse(w http.ResponseWriter, wait time.Duration) bool {
+	if wait <= 0 {
+		return false
+	}
+	retryAfter := seconds(wait)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	respond.Error(w, http.StatusTooManyRequests, respond.CodeRateLimited, fmt.Sprintf("Too many failed sign-in attempts, retry in %d seconds", retryAfter))
+	return true
+}
+
+// Reset clears a client's failures and lifts its lockout. It reports
+// whether the client was known.
+func (l *Lockout) Reset(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.clients[key]; !ok {
+		return false
+	}
+	delete(l.clients, key)
+	return true
+}
+
+// Stats returns the lockout settings and every client with failed sign-ins,
+// locked out clients first
+func (l *Lockout) Stats() LockoutStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	stats := LockoutStats{
+		Threshold: l.threshold,
+		Base:      l.base.String(),
+		Max:       l.max.String(),
+		Clients:   make([]LockoutClientStats, 0, len(l.clients)),
+	}
+	for key, c := range l.clients {
+		client := LockoutClientStats{
+			Key:         key,
+			Failures:    c.count,
+			Lockouts:    c.lockouts,
+			LastFailure: c.lastFailure,
+		}
+		if now.Before(c.lockedUntil) {
+			until := c.lockedUntil
+			client.LockedUntil = &until
+			stats.Locked++
+		}
+		stats.Clients = append(stats.Clients, client)
+	}
+
+	sort.Slice(stats.Clients, func(i, j int) bool {
+		a, b := stats.Clients[i], stats.Clients[j]
+		if (a.LockedUntil != nil) != (b.LockedUntil != nil) {
+			return a.LockedUntil != nil
+		}
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		return a.Key < b.Key
+	})
+	return stats
+}
@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditor keeps the audit entries it is asked to record
+type recordingAuditor struct {
+	entries []*models.AuditEntry
+}
+
+func (a *recordingAuditor) Record(actor, action, target string, oldValue, newValue interface{}) (*models.AuditEntry, error) {
+	entry := &models.AuditEntry{Actor: actor, Action: action, Target: target}
+	a.entries = append(a.entries, entry)
+	return entry, nil
+}
+
+// newTestLockout returns a lockout with a controllable clock
+func newTestLockout(t *testing.T, threshold int, base, max time.Duration) (*Lockout, *time.Time) {
+	t.Helper()
+
+	lockout := NewLockout(config.LockoutConfig{Threshold: threshold, Base: base, Max: max})
+	require.NotNil(t, lockout)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	lockout.now = func() time.Time { return now }
+	return lockout, &now
+}
+
+func TestNewLockoutDisabled(t *testing.T) {
+	assert.Nil(t, NewLockout(config.LockoutConfig{Threshold: 0, Base: time.Minute, Max: time.Hour}))
+}
+
+func TestLockoutBackoff(t *testing.T) {
+	lockout, now := newTestLockout(t, 3, time.Minute, 5*time.Minute)
+	auditor := &recordingAuditor{}
+	lockout.SetAuditor(auditor)
+
+	// Failures up to the threshold are free
+	for i := 0; i < 3; i++ {
+		lockout.Fail("ip:10.0.0.1")
+		assert.Zero(t, lockout.Locked("ip:10.0.0.1"), "failure %d", i+1)
+	}
+	assert.Empty(t, auditor.entries)
+
+	// Each failure after that doubles the lockout, up to the maximum
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute} {
+		lockout.Fail("ip:10.0.0.1")
+		assert.Equal(t, want, lockout.Locked("ip:10.0.0.1"))
+		*now = now.Add(want)
+		assert.Zero(t, lockout.Locked("ip:10.0.0.1"))
+	}
+
+	require.Len(t, auditor.entries, 5)
+	assert.Equal(t, LockoutActor, auditor.entries[0].Actor)
+	assert.Equal(t, models.AuditAuthLockout, auditor.entries[0].Action)
+	assert.Equal(t, "ip:10.0.0.1", auditor.entries[0].Target)
+}
+
+func TestLockoutPerAddressAndEmail(t *testing.T) {
+	lockout, now := newTestLockout(t, 1, time.Minute, time.Hour)
+
+	lockout.Fail("ip:10.0.0.1", EmailKey("alice@example.com"))
+	lockout.Fail("ip:10.0.0.2", EmailKey("alice@example.com"))
+
+	// Guessing from two addresses locks the email out from every address,
+	// while other emails can still sign in from both
+	assert.Equal(t, time.Minute, lockout.Locked("ip:10.0.0.3", EmailKey("alice@example.com")))
+	assert.Zero(t, lockout.Locked("ip:10.0.0.1", EmailKey("bob@example.com")))
+	assert.Zero(t, lockout.Locked("ip:10.0.0.2", EmailKey("bob@example.com")))
+	assert.Zero(t, lockout.Locked("", ""))
+
+	// Signing in clears only the email
+	lockout.Succeed(EmailKey("alice@example.com"))
+	assert.Zero(t, lockout.Locked(EmailKey("alice@example.com")))
+	lockout.Fail("ip:10.0.0.1")
+	assert.Equal(t, time.Minute, lockout.Locked("ip:10.0.0.1"))
+
+	// Failures are forgotten a day after the last one
+	*now = now.Add(25 * time.Hour)
+	lockout.Fail("ip:10.0.0.3")
+	stats := lockout.Stats()
+	require.Len(t, stats.Clients, 1)
+	assert.Equal(t, "ip:10.0.0.3", stats.Clients[0].Key)
+}
+
+func TestLockoutRefuse(t *testing.T) {
+	lockout, _ := newTestLockout(t, 1, time.Minute, time.Hour)
+
+	rr := httptest.NewRecorder()
+	assert.False(t, lockout.Refuse(rr, 0))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	assert.True(t, lockout.Refuse(rr, 90*time.Second))
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "90", rr.Header().Get("Retry-After"))
+	assert.Contains(t, rr.Body.String(), "rate_limited")
+}
+
+func TestLockoutStatsAndReset(t *testing.T) {
+	lockout, now := newTestLockout(t, 1, time.Minute, time.Hour)
+
+	lockout.Fail("ip:10.0.0.1")
+	lockout.Fail("ip:10.0.0.2")
+	lockout.Fail("ip:10.0.0.2")
+
+	stats := lockout.Stats()
+	assert.Equal(t, 1, stats.Threshold)
+	assert.Equal(t, "1m0s", stats.Base)
+	assert.Equal(t, 1, stats.Locked)
+	require.Len(t, stats.Clients, 2)
+	assert.Equal(t, "ip:10.0.0.2", stats.Clients[0].Key)
+	require.NotNil(t, stats.Clients[0].LockedUntil)
+	assert.Equal(t, now.Add(time.Minute), *stats.Clients[0].LockedUntil)
+	assert.Equal(t, 1, stats.Clients[0].Lockouts)
+	assert.Nil(t, stats.Clients[1].LockedUntil)
+
+	assert.True(t, lockout.Reset("ip:10.0.0.2"))
+	assert.False(t, lockout.Reset("ip:10.0.0.2"))
+	assert.Zero(t, lockout.Locked("ip:10.0.0.2"))
+}
@@ -0,0 +1,256 @@
+// Package ratelimit counts requests per client in fixed windows. Clients are
+// told how much of their allowance is left on every response and warned once
+// they pass a soft threshold, so integrations can back off before requests
+// are refused with 429 Too Many Requests at the hard limit. Sign-in and write
+// requests are also drawn from per-client token buckets, which allow a short
+// burst and then refill slowly.
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/respond"
+)
+
+// Response headers describing the client's allowance
+const (
+	HeaderLimit     = "X-RateLimit-Limit"
+	HeaderRemaining = "X-RateLimit-Remaining"
+	HeaderReset     = "X-RateLimit-Reset"
+	HeaderWarning   = "X-RateLimit-Warning"
+)
+
+// idleClientTTL is how long a client's counters are kept after its last request
+const idleClientTTL = 24 * time.Hour
+
+// KeyFunc identifies the client making a request
+type KeyFunc func(r *http.Request) string
+
+// client tracks one client's current window and lifetime counters
+type client struct {
+	windowStart time.Time
+	count       int
+	warnedAt    time.Time
+
+	requests int
+	warned   int
+	rejected int
+	lastSeen time.Time
+}
+
+// ClientStats reports a client's counters since the server started
+type ClientStats struct {
+	Key     string `json:"key"`
+	Current int    `json:"current_window_requests"`
+	// Blocked reports whether the client's requests are being refused until
+	// its window resets
+	Blocked  bool      `json:"blocked"`
+	Requests int       `json:"requests"`
+	Warned   int       `json:"warned"`
+	Rejected int       `json:"rejected"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Stats summarises rate limiting for the admin panel
+type Stats struct {
+	Limit    int           `json:"limit"`
+	Warn     int           `json:"warn"`
+	Window   string        `json:"window"`
+	Warned   int           `json:"warned"`
+	Rejected int           `json:"rejected"`
+	Clients  []ClientStats `json:"clients"`
+	// Buckets reports the token bucket limiters guarding sign-in and writes
+	Buckets []BucketStats `json:"buckets,omitempty"`
+	// Lockout reports failed sign-ins and who is locked out
+	Lockout *LockoutStats `json:"lockout,omitempty"`
+}
+
+// Limiter enforces per-client request limits
+type Limiter struct {
+	limit  int
+	warn   int
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*client
+	lastPrune time.Time
+}
+
+// NewLimiter creates a limiter from the rate limit settings. It returns nil
+// when rate limiting is disabled.
+func NewLimiter(cfg config.RateLimitConfig) *Limiter {
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	return &Limiter{
+		limit:   cfg.Limit,
+		warn:    cfg.Warn,
+		window:  cfg.Window,
+		now:     time.Now,
+		clients: make(map[string]*client),
+	}
+}
+
+// decision is the outcome of counting one request
+type decision struct {
+	count   int
+	reset   time.Time
+	warn    bool
+	allowed bool
+}
+
+// take counts a request from key and reports whether it may proceed
+func (l *Limiter) take(key string) decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	c, ok := l.clients[key]
+	if !ok {
+		c = &client{}
+		l.clients[key] = c
+	}
+	if now.Sub(c.windowStart) >= l.window {
+		c.windowStart = now
+		c.count = 0
+	}
+
+	c.count++
+	c.requests++
+	c.lastSeen = now
+
+	d := decision{
+		count:   c.count,
+		reset:   c.windowStart.Add(l.window),
+		warn:    c.count > l.warn,
+		allowed: c.count <= l.limit,
+	}
+
+	switch {
+	case !d.allowed:
+		c.rejected++
+		if c.count == l.limit+1 {
+			slog.Warn("Rate limit exceeded, refusing requests", "client", key, "limit", l.limit, "window", l.window, "until", d.reset.Format(time.RFC3339))
+		}
+	case d.warn:
+		c.warned++
+		if c.warnedAt.Before(c.windowStart) {
+			c.warnedAt = now
+			slog.Info("Rate limit warning threshold passed", "client", key, "warn", l.warn, "window", l.window)
+		}
+	}
+	return d
+}
+
+// prune drops clients that have been idle for a day, at most once a window
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < l.window {
+		return
+	}
+	l.lastPrune = now
+
+	for key, c := range l.clients {
+		if now.Sub(c.lastSeen) > idleClientTTL {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// Middleware counts every request by the client named by key. Responses
+// carry X-RateLimit-Limit, -Remaining and -Reset; past the warning threshold
+// they also carry X-RateLimit-Warning, and past the limit the request is
+// refused with 429 and Retry-After.
+func (l *Limiter) Middleware(key KeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d := l.take(key(r))
+
+			resetSeconds := int(math.Ceil(d.reset.Sub(l.now()).Seconds()))
+			remaining := l.limit - d.count
+			if remaining < 0 {
+				remaining = 0
+			}
+
+			w.Header().Set(HeaderLimit, strconv.Itoa(l.limit))
+			w.Header().Set(HeaderRemaining, strconv.Itoa(remaining))
+			w.Header().Set(HeaderReset, strconv.Itoa(resetSeconds))
+
+			if !d.allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(resetSeconds))
+				respond.Error(w, http.StatusTooManyRequests, respond.CodeRateLimited, fmt.Sprintf("Rate limit of %d requests per %s exceeded, retry in %d seconds", l.limit, l.window, resetSeconds))
+				return
+			}
+			if d.warn {
+				w.Header().Set(HeaderWarning, fmt.Sprintf("%d of %d requests used this window, slow down to avoid 429 responses", d.count, l.limit))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Reset forgets a client's counters, so a client that was refused by mistake
+// gets a full allowance on its next request. It reports whether the client
+// was known.
+func (l *Limiter) Reset(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.clients[key]; !ok {
+		return false
+	}
+	delete(l.clients, key)
+	return true
+}
+
+// Stats returns the limits and per-client counters, busiest clients first
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	stats := Stats{
+		Limit:   l.limit,
+		Warn:    l.warn,
+		Window:  l.window.String(),
+		Clients: make([]ClientStats, 0, len(l.clients)),
+	}
+
+	for key, c := range l.clients {
+		current := c.count
+		if now.Sub(c.windowStart) >= l.window {
+			current = 0
+		}
+		stats.Clients = append(stats.Clients, ClientStats{
+			Key:      key,
+			Current:  current,
+			Blocked:  current > l.limit,
+			Requests: c.requests,
+			Warned:   c.warned,
+			Rejected: c.rejected,
+			LastSeen: c.lastSeen,
+		})
+		stats.Warned += c.warned
+		stats.Rejected += c.rejected
+	}
+
+	sort.Slice(stats.Clients, func(i, j int) bool {
+		if stats.Clients[i].Requests != stats.Clients[j].Requests {
+			return stats.Clients[i].Requests > stats.Clients[j].Requests
+		}
+		return stats.Clients[i].Key < stats.Clients[j].Key
+	})
+	return stats
+}
@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestLimiter returns a limiter with a controllable clock wrapped around
+// a handler that always succeeds, keyed by the X-Client test header
+func newTestLimiter(t *testing.T, limit, warn int) (*Limiter, http.Handler, *time.Time) {
+	t.Helper()
+
+	limiter := NewLimiter(config.RateLimitConfig{Limit: limit, Warn: warn, Window: time.Minute})
+	require.NotNil(t, limiter)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	handler := limiter.Middleware(func(r *http.Request) string {
+		return r.Header.Get("X-Client")
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	return limiter, handler, &now
+}
+
+// request sends one request as client
+func request(handler http.Handler, client string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/plant", nil)
+	req.Header.Set("X-Client", client)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestNewLimiterDisabled(t *testing.T) {
+	assert.Nil(t, NewLimiter(config.RateLimitConfig{Limit: 0, Warn: 0, Window: time.Minute}))
+}
+
+func TestMiddlewareThresholds(t *testing.T) {
+	_, handler, _ := newTestLimiter(t, 3, 2)
+
+	tests := []struct {
+		code      int
+		remaining string
+		warning   bool
+	}{
+		{http.StatusOK, "2", false},
+		{http.StatusOK, "1", false},
+		{http.StatusOK, "0", true},
+		{http.StatusTooManyRequests, "0", false},
+	}
+
+	for i, tt := range tests {
+		rr := request(handler, "a")
+		assert.Equal(t, tt.code, rr.Code, "request %d", i+1)
+		assert.Equal(t, "3", rr.Header().Get(HeaderLimit), "request %d", i+1)
+		assert.Equal(t, tt.remaining, rr.Header().Get(HeaderRemaining), "request %d", i+1)
+		assert.Equal(t, "60", rr.Header().Get(HeaderReset), "request %d", i+1)
+		assert.Equal(t, tt.warning, rr.Header().Get(HeaderWarning) != "", "request %d", i+1)
+	}
+
+	rr := request(handler, "a")
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+	assert.Contains(t, rr.Body.String(), "Rate limit of 3 requests per 1m0s exceeded")
+
+	// Other clients have their own allowance
+	assert.Equal(t, http.StatusOK, request(handler, "b").Code)
+}
+
+func TestMiddlewareWindowReset(t *testing.T) {
+	_, handler, now := newTestLimiter(t, 1, 1)
+
+	assert.Equal(t, http.StatusOK, request(handler, "a").Code)
+
+	*now = now.Add(45 * time.Second)
+	rr := request(handler, "a")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "15", rr.Header().Get("Retry-After"))
+
+	*now = now.Add(15 * time.Second)
+	rr = request(handler, "a")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get(HeaderRemaining))
+}
+
+func TestStats(t *testing.T) {
+	limiter, handler, now := newTestLimiter(t, 2, 1)
+
+	for i := 0; i < 3; i++ {
+		request(handler, "busy")
+	}
+	request(handler, "quiet")
+
+	stats := limiter.Stats()
+	assert.Equal(t, 2, stats.Limit)
+	assert.Equal(t, 1, stats.Warn)
+	assert.Equal(t, "1m0s", stats.Window)
+	assert.Equal(t, 1, stats.Warned)
+	assert.Equal(t, 1, stats.Rejected)
+	require.Len(t, stats.Clients, 2)
+	assert.Equal(t, "busy", stats.Clients[0].Key)
+	assert.Equal(t, 3, stats.Clients[0].Current)
+	assert.True(t, stats.Clients[0].Blocked)
+	assert.Equal(t, "quiet", stats.Clients[1].Key)
+	assert.False(t, stats.Clients[1].Blocked)
+
+	// Counters for the current window reset, lifetime counters do not
+	*now = now.Add(time.Minute)
+	stats = limiter.Stats()
+	assert.Zero(t, stats.Clients[0].Current)
+	assert.False(t, stats.Clients[0].Blocked)
+	assert.Equal(t, 3, stats.Clients[0].Requests)
+
+	// Idle clients are forgotten and start over on their next request
+	*now = now.Add(idleClientTTL + time.Minute)
+	request(handler, "busy")
+	stats = limiter.Stats()
+	require.Len(t, stats.Clients, 1)
+	assert.Equal(t, "busy", stats.Clients[0].Key)
+	assert.Equal(t, 1, stats.Clients[0].Requests)
+}
+
+func TestReset(t *testing.T) {
+	limiter, handler, _ := newTestLimiter(t, 2, 1)
+
+	for i := 0; i < 3; i++ {
+		request(handler, "blocked")
+	}
+	assert.Equal(t, http.StatusTooManyRequests, request(handler, "blocked").Code)
+
+	assert.True(t, limiter.Reset("blocked"))
+	assert.False(t, limiter.Reset("blocked"))
+	assert.False(t, limiter.Reset("unknown"))
+
+	rr := request(handler, "blocked")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get(HeaderRemaining))
+}
@@ -0,0 +1,79 @@
+// Package realip sets a request's RemoteAddr to the client's address when the
+// server runs behind a reverse proxy. Forwarded headers are only believed
+// when the connection comes from a trusted proxy: anyone can send them, and
+// rate limits and sign-in lockouts keyed on a spoofed address would give an
+// attacker a fresh key with every request.
+package realip
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Middleware rewrites RemoteAddr from the X-Forwarded-For header, or
+// X-Real-IP without one, of requests whose peer is in trusted. Requests from
+// anywhere else keep the socket address, as do all requests when trusted is
+// empty.
+func Middleware(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if client, ok := clientAddr(r, trusted); ok {
+				r.RemoteAddr = client.String()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientAddr returns the client address forwarded by a trusted proxy,
+// reporting false when the peer is not trusted or forwarded none
+func clientAddr(r *http.Request, trusted []netip.Prefix) (netip.Addr, bool) {
+	peer, ok := parseAddr(r.RemoteAddr)
+	if !ok || !contains(trusted, peer) {
+		return netip.Addr{}, false
+	}
+
+	forwarded := r.Header.Values("X-Forwarded-For")
+	if len(forwarded) == 0 {
+		return parseAddr(r.Header.Get("X-Real-IP"))
+	}
+
+	// Each proxy appends the address it received the request from, so walk
+	// back from the nearest hop and take the first one not run by us. Hops
+	// further left were written by the client and cannot be trusted.
+	hops := strings.Split(strings.Join(forwarded, ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseAddr(strings.TrimSpace(hops[i]))
+		if !ok {
+			return netip.Addr{}, false
+		}
+		if !contains(trusted, addr) || i == 0 {
+			return addr, true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+// parseAddr parses an address with or without a port
+func parseAddr(value string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// contains reports whether addr falls in any of prefixes
+func contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
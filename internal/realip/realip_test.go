@@ -0,0 +1,67 @@
+package realip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "203.0.113.7:5000"},
+		{"spoofed forwarded for", "203.0.113.7:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "203.0.113.7:5000"},
+		{"spoofed real ip", "203.0.113.7:5000", map[string]string{"X-Real-IP": "198.51.100.1"}, "203.0.113.7:5000"},
+		{"trusted proxy", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"trusted proxy over ipv6", "[::1]:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"client prepends a hop", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "192.0.2.9, 198.51.100.1"}, "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.1, 10.0.0.3"}, "198.51.100.1"},
+		{"real ip without forwarded for", "10.0.0.2:5000", map[string]string{"X-Real-IP": "198.51.100.1"}, "198.51.100.1"},
+		{"forwarded for wins over real ip", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "192.0.2.9"}, "198.51.100.1"},
+		{"malformed hop", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "unknown"}, "10.0.0.2:5000"},
+		{"trusted proxy without headers", "10.0.0.2:5000", nil, "10.0.0.2:5000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := Middleware(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("Expected RemoteAddr %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMiddleware_NoTrustedProxies(t *testing.T) {
+	var got string
+	handler := Middleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "127.0.0.1:5000" {
+		t.Errorf("Expected forwarded headers ignored without trusted proxies, got %q", got)
+	}
+}
@@ -0,0 +1,218 @@
+// Package realtime pushes state changes to connected browsers over
+// WebSockets so every open device shows the same plant state.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// writeTimeout bounds each frame write to a client
+	writeTimeout = 10 * time.Second
+	// pingInterval is how often idle clients are pinged
+	pingInterval = 30 * time.Second
+	// readTimeout is how long a client may stay silent, including pongs
+	readTimeout = 2 * pingInterval
+	// clientBuffer is how many messages a client may fall behind before it
+	// is disconnected
+	clientBuffer = 32
+)
+
+// Message is the JSON envelope sent to clients
+type Message struct {
+	Type      string      `json:"type"`
+	Data      interface{} `json:"data"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// client is a connected WebSocket and its outgoing queue
+type client struct {
+	conn *Conn
+	send chan []byte
+}
+
+// Hub broadcasts messages to every connected WebSocket client
+type Hub struct {
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	closed  bool
+	// writers counts clients whose queued messages are still being sent
+	writers sync.WaitGroup
+}
+
+// NewHub creates a hub with no clients
+func NewHub() *Hub {
+	return &Hub{
+		clients: make(map[*client]struct{}),
+	}
+}
+
+// Publish broadcasts a message to all clients. Clients that cannot keep up
+// are disconnected; browsers reconnect and reload the current state.
+func (h *Hub) Publish(messageType string, data interface{}) {
+	payload, err := json.Marshal(Message{Type: messageType, Data: data, Timestamp: time.Now()})
+	if err != nil {
+		slog.Error("Realtime: failed to encode message", "type", messageType, "error", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.clients {
+		select {
+		case c.send <- payload:
+		default:
+			slog.Warn("Realtime: disconnecting slow client")
+			h.remove(c)
+		}
+	}
+}
+
+// ClientCount returns the number of connected clients
+func (h *Hub) ClientCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// Shutdown disconnects every client like Close, then waits until each has
+// been sent its queued messages and a close frame, or ctx is done
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.Close()
+
+	done := make(chan struct{})
+	go func() {
+		h.writers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close disconnects every client and rejects new connections. Hijacked
+// connections are not closed by http.Server.Shutdown, so call this (or
+// Shutdown) when the server stops.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for c := range h.clients {
+		h.remove(c)
+	}
+}
+
+// remove unregisters a client and stops its writer; h.mu must be held
+func (h *Hub) remove(c *client) {
+	if _, ok := h.clients[c]; ok {
+		delete(h.clients, c)
+		close(c.send)
+	}
+}
+
+// ServeHTTP upgrades the request to a WebSocket and streams messages to it
+// until either side disconnects
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "Cross-origin WebSocket connections are not allowed", http.StatusForbidden)
+		return
+	}
+
+	conn, err := Upgrade(w, r)
+	if err != nil {
+		return
+	}
+
+	c := &client{conn: conn, send: make(chan []byte, clientBuffer)}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		conn.WriteClose(CloseGoingAway)
+		conn.Close()
+		return
+	}
+	h.clients[c] = struct{}{}
+	h.writers.Add(1)
+	h.mu.Unlock()
+
+	go h.writeLoop(c)
+	h.readLoop(c)
+}
+
+// writeLoop sends queued messages and keep-alive pings until the client is
+// removed, then closes the connection
+func (h *Hub) writeLoop(c *client) {
+	ticker := time.NewTicker(pingInterval)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+		h.writers.Done()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-c.send:
+			if !ok {
+				c.conn.WriteClose(CloseGoingAway)
+				return
+			}
+			if err := c.conn.WriteText(payload); err != nil {
+				h.unregister(c)
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WritePing(); err != nil {
+				h.unregister(c)
+				return
+			}
+		}
+	}
+}
+
+// readLoop consumes client frames so pings, pongs and close frames are
+// handled; message content from clients is ignored
+func (h *Hub) readLoop(c *client) {
+	defer h.unregister(c)
+
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(readTimeout))
+		if _, _, err := c.conn.ReadFrame(); err != nil {
+			return
+		}
+	}
+}
+
+// unregister removes a client if it is still registered
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(c)
+}
+
+// sameOrigin rejects browser connections from other sites, which would
+// otherwise ride on the user's session cookie. Clients that send no Origin
+// header (non-browser apps) are allowed.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Host == r.Host
+}
@@ -0,0 +1,209 @@
+package realtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClient is a minimal WebSocket client for exercising the hub
+type testClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dial(t *testing.T, server *httptest.Server, origin string) *testClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	request := "GET /ws HTTP/1.1\r\n" +
+		"Host: " + strings.TrimPrefix(server.URL, "http://") + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n"
+	if origin != "" {
+		request += "Origin: " + origin + "\r\n"
+	}
+	_, err = conn.Write([]byte(request + "\r\n"))
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+	return &testClient{conn: conn, reader: reader}
+}
+
+// readFrame reads one unmasked server frame
+func (c *testClient) readFrame(t *testing.T) (byte, []byte) {
+	t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var header [2]byte
+	_, err := io.ReadFull(c.reader, header[:])
+	require.NoError(t, err)
+
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var extended [2]byte
+		_, err := io.ReadFull(c.reader, extended[:])
+		require.NoError(t, err)
+		length = int(binary.BigEndian.Uint16(extended[:]))
+	}
+
+	payload := make([]byte, length)
+	_, err = io.ReadFull(c.reader, payload)
+	require.NoError(t, err)
+	return header[0] & 0x0F, payload
+}
+
+// writeFrame writes one masked client frame
+func (c *testClient) writeFrame(t *testing.T, opcode byte, payload []byte) {
+	t.Helper()
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := c.conn.Write(frame)
+	require.NoError(t, err)
+}
+
+func waitForClients(t *testing.T, hub *Hub, count int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.ClientCount() != count {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d clients, got %d", count, hub.ClientCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHub_Broadcast(t *testing.T) {
+	hub := NewHub()
+	server := httptest.NewServer(hub)
+	defer server.Close()
+
+	first := dial(t, server, "")
+	second := dial(t, server, server.URL)
+	waitForClients(t, hub, 2)
+
+	hub.Publish("plant_updated", map[string]interface{}{"id": 1})
+
+	for _, client := range []*testClient{first, second} {
+		opcode, payload := client.readFrame(t)
+		assert.Equal(t, byte(opText), opcode)
+
+		var message Message
+		require.NoError(t, json.Unmarshal(payload, &message))
+		assert.Equal(t, "plant_updated", message.Type)
+		assert.Equal(t, float64(1), message.Data.(map[string]interface{})["id"])
+	}
+}
+
+func TestHub_PingAndClose(t *testing.T) {
+	hub := NewHub()
+	server := httptest.NewServer(hub)
+	defer server.Close()
+
+	client := dial(t, server, "")
+	waitForClients(t, hub, 1)
+
+	client.writeFrame(t, opPing, []byte("hi"))
+	opcode, payload := client.readFrame(t)
+	assert.Equal(t, byte(opPong), opcode)
+	assert.Equal(t, "hi", string(payload))
+
+	client.writeFrame(t, opClose, []byte{0x03, 0xE8})
+	opcode, _ = client.readFrame(t)
+	assert.Equal(t, byte(opClose), opcode)
+	waitForClients(t, hub, 0)
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub()
+	server := httptest.NewServer(hub)
+	defer server.Close()
+
+	client := dial(t, server, "")
+	waitForClients(t, hub, 1)
+
+	hub.Close()
+	opcode, payload := client.readFrame(t)
+	assert.Equal(t, byte(opClose), opcode)
+	assert.Equal(t, uint16(CloseGoingAway), binary.BigEndian.Uint16(payload))
+	assert.Equal(t, 0, hub.ClientCount())
+}
+
+func TestHub_ShutdownSendsQueuedMessages(t *testing.T) {
+	hub := NewHub()
+	server := httptest.NewServer(hub)
+	defer server.Close()
+
+	client := dial(t, server, "")
+	waitForClients(t, hub, 1)
+
+	hub.Publish("plant_updated", map[string]interface{}{"id": 1})
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- hub.Shutdown(context.Background()) }()
+
+	opcode, payload := client.readFrame(t)
+	assert.Equal(t, byte(opText), opcode)
+	assert.Contains(t, string(payload), "plant_updated")
+	opcode, _ = client.readFrame(t)
+	assert.Equal(t, byte(opClose), opcode)
+
+	select {
+	case err := <-shutdown:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected Shutdown to return once the client was closed")
+	}
+}
+
+func TestHub_RejectsCrossOrigin(t *testing.T) {
+	hub := NewHub()
+	server := httptest.NewServer(hub)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestUpgrade_RejectsPlainRequests(t *testing.T) {
+	hub := NewHub()
+	server := httptest.NewServer(hub)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
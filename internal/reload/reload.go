@@ -0,0 +1,149 @@
+// Package reload applies configuration changes to the running server, on
+// SIGHUP or from the admin page, without a restart. Components register the
+// settings they can pick up at runtime; changes to any other setting are
+// reported as needing a restart.
+package reload
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"watered/internal/config"
+	"watered/internal/models"
+)
+
+// ApplyFunc applies the reloaded configuration to a component
+type ApplyFunc func(cfg *config.Config) error
+
+// Auditor records applied changes in the audit log
+type Auditor interface {
+	Record(actor, action, target string, oldValue, newValue interface{}) (*models.AuditEntry, error)
+}
+
+// Result lists what a reload changed
+type Result struct {
+	// Applied lists the changes now in effect
+	Applied []config.Change `json:"applied"`
+	// RestartRequired lists the changes that take effect on the next start
+	RestartRequired []config.Change `json:"restart_required"`
+	// Errors lists the components that failed to apply their changes; those
+	// changes are tried again on the next reload
+	Errors []string `json:"errors,omitempty"`
+}
+
+// handler applies a group of settings
+type handler struct {
+	settings []string
+	apply    ApplyFunc
+}
+
+// Reloader re-reads the configuration and hands each change to the
+// component registered for it
+type Reloader struct {
+	load    func() (*config.Config, error)
+	auditor Auditor
+
+	mu       sync.Mutex
+	started  *config.Config
+	current  *config.Config
+	handlers []handler
+}
+
+// New creates a reloader for the server started with current, re-reading
+// the configuration with load
+func New(current *config.Config, load func() (*config.Config, error)) *Reloader {
+	return &Reloader{load: load, started: current, current: current}
+}
+
+// SetAuditor sets the audit log applied changes are recorded in
+func (r *Reloader) SetAuditor(auditor Auditor) {
+	r.auditor = auditor
+}
+
+// Handle registers apply for settings, named by their environment
+// variables. apply is called with the new configuration when any of them
+// changes.
+func (r *Reloader) Handle(settings []string, apply ApplyFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers = append(r.handlers, handler{settings: settings, apply: apply})
+}
+
+// Reload re-reads the configuration and applies what changed. actor is
+// recorded in the audit log as whoever asked for the reload. An invalid
+// configuration is rejected as a whole, leaving the running one in place.
+func (r *Reloader) Reload(actor string) (*Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	handled := make(map[string]bool)
+	for _, h := range r.handlers {
+		for _, setting := range h.settings {
+			handled[setting] = true
+		}
+	}
+
+	result := &Result{Applied: []config.Change{}, RestartRequired: []config.Change{}}
+	changed := make(map[string]config.Change)
+	for _, change := range r.current.Changes(next) {
+		if handled[change.Setting] {
+			changed[change.Setting] = change
+		}
+	}
+	// Compare against the started configuration, so a change waiting for a
+	// restart is reported on every reload until then
+	for _, change := range r.started.Changes(next) {
+		if !handled[change.Setting] {
+			result.RestartRequired = append(result.RestartRequired, change)
+			slog.Warn("Configuration change needs a restart", "setting", change.Setting)
+		}
+	}
+
+	failed := false
+	for _, h := range r.handlers {
+		var changes []config.Change
+		for _, setting := range h.settings {
+			if change, ok := changed[setting]; ok {
+				changes = append(changes, change)
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if err := h.apply(next); err != nil {
+			failed = true
+			result.Errors = append(result.Errors, err.Error())
+			slog.Error("Failed to apply reloaded configuration", "settings", h.settings, "error", err)
+			continue
+		}
+		for _, change := range changes {
+			result.Applied = append(result.Applied, change)
+			r.audit(actor, change)
+		}
+	}
+
+	// After a failure the changes are compared against the old configuration
+	// again next time, so the failed ones are retried
+	if !failed {
+		r.current = next
+	}
+	slog.Info("Configuration reloaded", "by", actor, "applied", len(result.Applied), "restart_required", len(result.RestartRequired))
+	return result, nil
+}
+
+// audit records an applied change. Failures are logged rather than returned
+// since the change has already been made.
+func (r *Reloader) audit(actor string, change config.Change) {
+	if r.auditor == nil {
+		return
+	}
+	if _, err := r.auditor.Record(actor, models.AuditConfigReload, change.Setting, change.Old, change.New); err != nil {
+		slog.Error("Failed to record audit entry", "action", models.AuditConfigReload, "target", change.Setting, "error", err)
+	}
+}
@@ -0,0 +1,116 @@
+package reload
+
+import (
+	"errors"
+	"testing"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/services"
+	"watered/internal/storage"
+)
+
+// loadFrom returns a load func reading the configuration from env
+func loadFrom(env map[string]string) func() (*config.Config, error) {
+	return func() (*config.Config, error) {
+		return config.LoadFrom(func(key string) string { return env[key] })
+	}
+}
+
+func TestReloader_Reload(t *testing.T) {
+	env := map[string]string{"LOG_LEVEL": "info", "PORT": "8080"}
+	current, err := loadFrom(env)()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	auditService := services.NewAuditService(store)
+
+	reloader := New(current, loadFrom(env))
+	reloader.SetAuditor(auditService)
+	var applied []string
+	reloader.Handle([]string{"LOG_LEVEL"}, func(cfg *config.Config) error {
+		applied = append(applied, cfg.Server.LogLevel.String())
+		return nil
+	})
+
+	env["LOG_LEVEL"] = "debug"
+	env["PORT"] = "9090"
+	result, err := reloader.Reload("admin@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(applied) != 1 || applied[0] != "DEBUG" {
+		t.Errorf("Expected the new log level applied, got %v", applied)
+	}
+	if len(result.Applied) != 1 || result.Applied[0] != (config.Change{Setting: "LOG_LEVEL", Old: "info", New: "debug"}) {
+		t.Errorf("Expected LOG_LEVEL applied, got %v", result.Applied)
+	}
+	if len(result.RestartRequired) != 1 || result.RestartRequired[0].Setting != "PORT" {
+		t.Errorf("Expected PORT to need a restart, got %v", result.RestartRequired)
+	}
+
+	page, err := auditService.List(models.AuditFilter{Action: models.AuditConfigReload}, 10, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page.Total != 1 || page.Entries[0].Target != "LOG_LEVEL" || page.Entries[0].Actor != "admin@example.com" {
+		t.Errorf("Expected the applied change audited, got %+v", page.Entries)
+	}
+
+	// Nothing new is applied, but PORT still waits for a restart
+	result, err = reloader.Reload("SIGHUP")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(applied) != 1 || len(result.Applied) != 0 || len(result.RestartRequired) != 1 {
+		t.Errorf("Expected only PORT reported, got %+v", result)
+	}
+}
+
+func TestReloader_InvalidConfiguration(t *testing.T) {
+	env := map[string]string{"LOG_LEVEL": "info"}
+	current, _ := loadFrom(env)()
+	reloader := New(current, loadFrom(env))
+	reloader.Handle([]string{"LOG_LEVEL"}, func(cfg *config.Config) error {
+		t.Error("Expected an invalid configuration not to be applied")
+		return nil
+	})
+
+	env["LOG_LEVEL"] = "loud"
+	if _, err := reloader.Reload("SIGHUP"); err == nil {
+		t.Error("Expected an error for an invalid LOG_LEVEL")
+	}
+}
+
+func TestReloader_RetriesFailedChanges(t *testing.T) {
+	env := map[string]string{"SNOOZE_DURATION": "3h"}
+	current, _ := loadFrom(env)()
+	reloader := New(current, loadFrom(env))
+	calls := 0
+	reloader.Handle([]string{"SNOOZE_DURATION"}, func(cfg *config.Config) error {
+		calls++
+		if calls == 1 {
+			return errors.New("snoozes are busy")
+		}
+		return nil
+	})
+
+	env["SNOOZE_DURATION"] = "1h"
+	result, err := reloader.Reload("SIGHUP")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Applied) != 0 || len(result.Errors) != 1 {
+		t.Errorf("Expected the failure reported, got %+v", result)
+	}
+
+	result, err = reloader.Reload("SIGHUP")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls != 2 || len(result.Applied) != 1 {
+		t.Errorf("Expected the change retried and applied, got %+v", result)
+	}
+}
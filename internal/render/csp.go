@@ -0,0 +1,84 @@
+package render
+
+import (
+	"strings"
+
+	"watered/internal/config"
+)
+
+// CSPDirective is a single Content-Security-Policy directive. When Nonce is
+// set, the per-request nonce is added to the directive's sources.
+type CSPDirective struct {
+	Name    string
+	Sources []string
+	Nonce   bool
+}
+
+// CSPPolicy describes the Content-Security-Policy sent with rendered pages
+type CSPPolicy struct {
+	Directives []CSPDirective
+	ReportOnly bool
+	ReportURI  string
+}
+
+// DefaultCSPPolicy returns a strict policy for the bundled templates. Inline
+// scripts and style blocks need the request nonce; Alpine.js still needs
+// 'unsafe-eval' to evaluate its attribute expressions. Styles may also load
+// from jsDelivr for the Swagger UI page.
+func DefaultCSPPolicy() *CSPPolicy {
+	return &CSPPolicy{
+		Directives: []CSPDirective{
+			{Name: "default-src", Sources: []string{"'self'"}},
+			{Name: "script-src", Sources: []string{"'self'", "https://cdn.jsdelivr.net", "'unsafe-eval'"}, Nonce: true},
+			{Name: "style-src", Sources: []string{"'self'", "https://cdn.jsdelivr.net"}, Nonce: true},
+			{Name: "style-src-attr", Sources: []string{"'unsafe-inline'"}},
+			{Name: "img-src", Sources: []string{"'self'", "data:"}},
+			{Name: "connect-src", Sources: []string{"'self'"}},
+			{Name: "object-src", Sources: []string{"'none'"}},
+			{Name: "base-uri", Sources: []string{"'self'"}},
+			{Name: "form-action", Sources: []string{"'self'"}},
+			{Name: "frame-ancestors", Sources: []string{"'none'"}},
+		},
+	}
+}
+
+// NewCSPPolicy returns the default policy with the configured reporting
+// options, or nil when CSP is disabled
+func NewCSPPolicy(cfg config.CSPConfig) *CSPPolicy {
+	if cfg.Disabled {
+		return nil
+	}
+
+	policy := DefaultCSPPolicy()
+	policy.ReportOnly = cfg.ReportOnly
+	policy.ReportURI = cfg.ReportURI
+	return policy
+}
+
+// HeaderName returns the response header the policy is sent in
+func (p *CSPPolicy) HeaderName() string {
+	if p.ReportOnly {
+		return "Content-Security-Policy-Report-Only"
+	}
+	return "Content-Security-Policy"
+}
+
+// Header builds the header value for a request with the given nonce
+func (p *CSPPolicy) Header(nonce string) string {
+	parts := make([]string, 0, len(p.Directives)+1)
+	for _, directive := range p.Directives {
+		sources := directive.Sources
+		if directive.Nonce && nonce != "" {
+			sources = append(append([]string{}, sources...), "'nonce-"+nonce+"'")
+		}
+		if len(sources) == 0 {
+			parts = append(parts, directive.Name)
+			continue
+		}
+		parts = append(parts, directive.Name+" "+strings.Join(sources, " "))
+	}
+	if p.ReportURI != "" {
+		parts = append(parts, "report-uri "+p.ReportURI)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"watered/internal/i18n"
+)
+
+// NonceKey is the template data key holding the per-request CSP nonce.
+// Templates add it to inline tags: <script nonce="{{.CSPNonce}}">
+const NonceKey = "CSPNonce"
+
+// I18nKey is the template data key holding the request's *i18n.Printer.
+// Templates translate with it: <h1>{{.I18n.T "login.welcome"}}</h1>. Pages
+// rendered without one are in English.
+const I18nKey = "I18n"
+
+// Renderer executes page templates and sends the Content-Security-Policy
+// header with a fresh nonce for every request
+type Renderer struct {
+	templates *template.Template
+	policy    *CSPPolicy
+}
+
+// NewRenderer creates a renderer. A nil policy disables the CSP header.
+func NewRenderer(templates *template.Template, policy *CSPPolicy) *Renderer {
+	return &Renderer{
+		templates: templates,
+		policy:    policy,
+	}
+}
+
+// Render executes the named template with data, adding the request nonce
+// under NonceKey
+func (rd *Renderer) Render(w http.ResponseWriter, name string, data map[string]interface{}) error {
+	return rd.RenderStatus(w, http.StatusOK, name, data)
+}
+
+// RenderStatus is Render for pages answered with another status, such as a
+// friendly 404 page
+func (rd *Renderer) RenderStatus(w http.ResponseWriter, status int, name string, data map[string]interface{}) error {
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	nonce := ""
+	if rd.policy != nil {
+		var err error
+		nonce, err = generateNonce()
+		if err != nil {
+			return err
+		}
+		w.Header().Set(rd.policy.HeaderName(), rd.policy.Header(nonce))
+	}
+	data[NonceKey] = nonce
+	if _, ok := data[I18nKey].(*i18n.Printer); !ok {
+		data[I18nKey] = i18n.Get(i18n.English)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+	}
+	return rd.templates.ExecuteTemplate(w, name, data)
+}
+
+// generateNonce returns 128 random bits encoded for a CSP nonce source
+func generateNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate CSP nonce: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
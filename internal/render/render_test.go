@@ -0,0 +1,108 @@
+package render
+
+import (
+	"html/template"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"watered/internal/i18n"
+)
+
+func TestCSPPolicy_Header(t *testing.T) {
+	policy := &CSPPolicy{
+		Directives: []CSPDirective{
+			{Name: "default-src", Sources: []string{"'self'"}},
+			{Name: "script-src", Sources: []string{"'self'"}, Nonce: true},
+			{Name: "upgrade-insecure-requests"},
+		},
+		ReportURI: "/csp-report",
+	}
+
+	expected := "default-src 'self'; script-src 'self' 'nonce-abc'; upgrade-insecure-requests; report-uri /csp-report"
+	if got := policy.Header("abc"); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	// Building a header must not modify the configured sources
+	policy.Header("def")
+	if len(policy.Directives[1].Sources) != 1 {
+		t.Errorf("Expected configured sources to be unchanged, got %v", policy.Directives[1].Sources)
+	}
+
+	if policy.HeaderName() != "Content-Security-Policy" {
+		t.Errorf("Expected enforcing header, got %s", policy.HeaderName())
+	}
+	policy.ReportOnly = true
+	if policy.HeaderName() != "Content-Security-Policy-Report-Only" {
+		t.Errorf("Expected report-only header, got %s", policy.HeaderName())
+	}
+}
+
+func TestRenderer_Render(t *testing.T) {
+	templates := template.Must(template.New("page.html").Parse(`<script nonce="{{.CSPNonce}}">var name = "{{.Name}}";</script>`))
+	renderer := NewRenderer(templates, DefaultCSPPolicy())
+
+	nonces := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		if err := renderer.Render(w, "page.html", map[string]interface{}{"Name": "Fern"}); err != nil {
+			t.Fatalf("Failed to render: %v", err)
+		}
+
+		header := w.Header().Get("Content-Security-Policy")
+		start := strings.Index(header, "'nonce-")
+		if start < 0 {
+			t.Fatalf("Expected a nonce in the CSP header, got %q", header)
+		}
+		nonce := header[start+len("'nonce-"):]
+		nonce = nonce[:strings.Index(nonce, "'")]
+
+		if !strings.Contains(w.Body.String(), `nonce="`+nonce+`"`) {
+			t.Errorf("Expected the script tag to carry nonce %s, got %s", nonce, w.Body.String())
+		}
+		nonces[nonce] = true
+	}
+
+	if len(nonces) != 2 {
+		t.Error("Expected a fresh nonce for every request")
+	}
+}
+
+func TestRenderer_RenderWithoutPolicy(t *testing.T) {
+	templates := template.Must(template.New("page.html").Parse(`<p>{{.CSPNonce}}</p>`))
+	renderer := NewRenderer(templates, nil)
+
+	w := httptest.NewRecorder()
+	if err := renderer.Render(w, "page.html", nil); err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if w.Header().Get("Content-Security-Policy") != "" {
+		t.Error("Expected no CSP header without a policy")
+	}
+	if w.Body.String() != "<p></p>" {
+		t.Errorf("Expected empty nonce, got %s", w.Body.String())
+	}
+}
+
+func TestRenderer_RenderTranslated(t *testing.T) {
+	templates := template.Must(template.New("page.html").Parse(`<html lang="{{.I18n.Locale}}">{{.I18n.T "nav.home"}}</html>`))
+	renderer := NewRenderer(templates, nil)
+
+	w := httptest.NewRecorder()
+	if err := renderer.Render(w, "page.html", map[string]interface{}{I18nKey: i18n.Get("es")}); err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if w.Body.String() != `<html lang="es">Inicio</html>` {
+		t.Errorf("Expected the page in Spanish, got %s", w.Body.String())
+	}
+
+	// Without a printer the page is in English
+	w = httptest.NewRecorder()
+	if err := renderer.Render(w, "page.html", nil); err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if w.Body.String() != `<html lang="en">Home</html>` {
+		t.Errorf("Expected the page in English, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,71 @@
+// Package respond writes API error responses in one JSON shape, so clients
+// can tell failures apart by a stable code instead of parsing messages:
+//
+//	{"error": {"code": "not_found", "message": "Plant not found"}}
+package respond
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Error codes. Messages are for people and may change; codes are stable.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeInvalidJSON      = "invalid_json"
+	CodeValidation       = "validation_failed"
+	CodeTooLarge         = "body_too_large"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeConflict         = "conflict"
+	CodeGone             = "gone"
+	CodeRateLimited      = "rate_limited"
+	CodeNotConfigured    = "not_configured"
+	CodeUpstream         = "upstream_error"
+	CodeInternal         = "internal_error"
+)
+
+// ErrorBody describes a failed request
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Fields lists what was wrong with each field of a rejected request body
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError explains why one request body field was rejected
+type FieldError struct {
+	// Field is the field's JSON name
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ErrorResponse is the body of every error response
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// Error replies to the request with the HTTP status and a JSON error
+// envelope. Like http.Error it does not end the handler; callers return
+// after it.
+func Error(w http.ResponseWriter, status int, code, message string) {
+	write(w, status, ErrorBody{Code: code, Message: message})
+}
+
+// ValidationError replies 400 listing the request body fields that failed
+// validation
+func ValidationError(w http.ResponseWriter, fields []FieldError) {
+	write(w, http.StatusBadRequest, ErrorBody{Code: CodeValidation, Message: "Request validation failed", Fields: fields})
+}
+
+// write sends an error envelope
+func write(w http.ResponseWriter, status int, body ErrorBody) {
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: body})
+}
@@ -0,0 +1,33 @@
+package respond
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Length", "12")
+	Error(w, http.StatusNotFound, CodeNotFound, "Plant not found")
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", got)
+	}
+	if got := w.Header().Get("Content-Length"); got != "" {
+		t.Errorf("Expected Content-Length to be cleared, got %q", got)
+	}
+
+	var body map[string]map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode %q: %v", w.Body.String(), err)
+	}
+	want := map[string]string{"code": "not_found", "message": "Plant not found"}
+	if len(body) != 1 || body["error"]["code"] != want["code"] || body["error"]["message"] != want["message"] {
+		t.Errorf("Expected {\"error\": %v}, got %v", want, body)
+	}
+}
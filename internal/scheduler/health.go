@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"watered/internal/monitoring"
+)
+
+// Name returns the name of the scheduler's health check
+func (s *Scheduler) Name() string {
+	return "scheduler"
+}
+
+// Check reports every job's status. The scheduler is degraded while any
+// job's latest run failed.
+func (s *Scheduler) Check(ctx context.Context) monitoring.ComponentHealth {
+	start := time.Now()
+	statuses := s.Status()
+
+	health := monitoring.ComponentHealth{
+		Name:        s.Name(),
+		Status:      monitoring.HealthStatusHealthy,
+		Message:     fmt.Sprintf("%d jobs scheduled", len(statuses)),
+		LastChecked: start,
+		Details:     map[string]interface{}{"jobs": statuses},
+	}
+
+	var failing []string
+	for _, status := range statuses {
+		if status.LastError != "" {
+			failing = append(failing, status.Name)
+		}
+	}
+	if len(failing) > 0 {
+		health.Status = monitoring.HealthStatusDegraded
+		health.Message = fmt.Sprintf("Latest run failed for %d of %d jobs: %v", len(failing), len(statuses), failing)
+	}
+
+	health.Duration = time.Since(start)
+	return health
+}
+
+// Ready is the scheduler's readiness check. It fails before Start and after
+// Stop, since reminders would not be sent.
+func (s *Scheduler) Ready(ctx context.Context) error {
+	if !s.Running() {
+		return errors.New("scheduler is not running")
+	}
+	return nil
+}
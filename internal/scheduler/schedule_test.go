@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCron_Next(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	// Wednesday
+	from := time.Date(2024, 5, 15, 10, 7, 30, 0, berlin)
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, 5, 15, 10, 8, 0, 0, berlin)},
+		{"*/15 * * * *", time.Date(2024, 5, 15, 10, 15, 0, 0, berlin)},
+		{"0 8 * * *", time.Date(2024, 5, 16, 8, 0, 0, 0, berlin)},
+		{"30 9-17/4 * * *", time.Date(2024, 5, 15, 13, 30, 0, 0, berlin)},
+		{"0 9 * * 1,5", time.Date(2024, 5, 17, 9, 0, 0, 0, berlin)},
+		{"0 9 * * 7", time.Date(2024, 5, 19, 9, 0, 0, 0, berlin)},
+		{"0 0 1 * *", time.Date(2024, 6, 1, 0, 0, 0, 0, berlin)},
+		// Either day field matches when both are restricted
+		{"0 0 20 * 5", time.Date(2024, 5, 17, 0, 0, 0, 0, berlin)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, berlin)},
+		// 02:30 does not exist on the day clocks go forward
+		{"30 2 31 3 *", time.Date(2025, 3, 31, 2, 30, 0, 0, berlin)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := Cron(tt.expr, berlin)
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCron_NeverMatches(t *testing.T) {
+	schedule, err := Cron("0 0 31 2 *", time.UTC)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Expected no next run, got %v", next)
+	}
+}
+
+func TestCron_Invalid(t *testing.T) {
+	tests := []struct {
+		expr    string
+		message string
+	}{
+		{"0 8 * *", "must have 5 fields"},
+		{"60 * * * *", `minute "60" must be between 0 and 59`},
+		{"* 5-2 * * *", "must be between"},
+		{"* * 0 * *", "day of month"},
+		{"*/0 * * * *", "invalid step"},
+		{"a * * * *", "invalid minute"},
+	}
+
+	for _, tt := range tests {
+		if _, err := Cron(tt.expr, nil); err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("Cron(%q): expected error mentioning %q, got %v", tt.expr, tt.message, err)
+		}
+	}
+}
+
+func TestEvery(t *testing.T) {
+	from := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	schedule := Every(5 * time.Minute)
+	if next := schedule.Next(from); !next.Equal(from.Add(5 * time.Minute)) {
+		t.Errorf("Expected five minutes later, got %v", next)
+	}
+	if schedule.String() != "every 5m0s" {
+		t.Errorf("Unexpected description %q", schedule.String())
+	}
+}
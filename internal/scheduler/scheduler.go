@@ -0,0 +1,301 @@
+// Package scheduler runs periodic background jobs, such as reminder checks
+// and digests, on fixed intervals or cron schedules. Each job runs in its own
+// goroutine, never overlapping itself, and records when it last ran and how
+// it went so the status can be reported by /health/detailed.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Schedule decides when a job runs next
+type Schedule interface {
+	// Next returns the first run time after after, or the zero time if the
+	// job never runs again
+	Next(after time.Time) time.Time
+	String() string
+}
+
+// Job is a unit of periodic work
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Jitter delays each run by a random duration up to Jitter, so jobs on
+	// the same schedule do not all start at once
+	Jitter time.Duration
+	// RunAtStart also runs the job as soon as the scheduler starts
+	RunAtStart bool
+	// Run does the work. ctx is canceled when the scheduler stops.
+	Run func(ctx context.Context) error
+}
+
+// JobStatus reports a job's schedule and its runs since the server started
+type JobStatus struct {
+	Name         string        `json:"name"`
+	Schedule     string        `json:"schedule"`
+	Running      bool          `json:"running"`
+	Runs         int           `json:"runs"`
+	Failures     int           `json:"failures"`
+	LastRun      *time.Time    `json:"last_run,omitempty"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error,omitempty"`
+	NextRun      *time.Time    `json:"next_run,omitempty"`
+}
+
+// job is a registered job and its status
+type job struct {
+	Job
+	// rescheduled wakes the job's loop when its schedule changes
+	rescheduled chan struct{}
+
+	mu     sync.Mutex
+	status JobStatus
+}
+
+// Scheduler runs registered jobs until it is stopped
+type Scheduler struct {
+	now    func() time.Time
+	jitter func(max time.Duration) time.Duration
+
+	mu      sync.Mutex
+	jobs    []*job
+	started bool
+	stopped bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New creates a scheduler with no jobs
+func New() *Scheduler {
+	return &Scheduler{
+		now: time.Now,
+		jitter: func(max time.Duration) time.Duration {
+			return time.Duration(rand.Int63n(int64(max)))
+		},
+	}
+}
+
+// Register adds a job. Jobs must be registered before Start, and names must
+// be unique.
+func (s *Scheduler) Register(j Job) error {
+	if j.Name == "" || j.Schedule == nil || j.Run == nil {
+		return errors.New("job needs a name, a schedule and a run function")
+	}
+	now := s.now()
+	if next := j.Schedule.Next(now); !next.IsZero() && !next.After(now) {
+		return fmt.Errorf("job %s: schedule %s never advances", j.Name, j.Schedule)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("cannot register job %s after the scheduler started", j.Name)
+	}
+	for _, existing := range s.jobs {
+		if existing.Name == j.Name {
+			return fmt.Errorf("job %s is already registered", j.Name)
+		}
+	}
+
+	s.jobs = append(s.jobs, &job{
+		Job:         j,
+		rescheduled: make(chan struct{}, 1),
+		status:      JobStatus{Name: j.Name, Schedule: j.Schedule.String()},
+	})
+	return nil
+}
+
+// Reschedule replaces the schedule of a registered job, such as after the
+// configuration was reloaded. A job waiting for its next run is rescheduled
+// at once; a running job keeps running.
+func (s *Scheduler) Reschedule(name string, schedule Schedule) error {
+	if schedule == nil {
+		return fmt.Errorf("job %s needs a schedule", name)
+	}
+	now := s.now()
+	if next := schedule.Next(now); !next.IsZero() && !next.After(now) {
+		return fmt.Errorf("job %s: schedule %s never advances", name, schedule)
+	}
+
+	s.mu.Lock()
+	var found *job
+	for _, j := range s.jobs {
+		if j.Name == name {
+			found = j
+		}
+	}
+	s.mu.Unlock()
+	if found == nil {
+		return fmt.Errorf("job %s is not registered", name)
+	}
+
+	found.mu.Lock()
+	found.Schedule = schedule
+	found.status.Schedule = schedule.String()
+	found.mu.Unlock()
+	select {
+	case found.rescheduled <- struct{}{}:
+	default:
+	}
+	slog.Info("Job rescheduled", "job", name, "schedule", schedule.String())
+	return nil
+}
+
+// Start runs every registered job in the background until ctx is canceled or
+// Stop is called
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Stop stops scheduling new runs and waits for running jobs to return, or
+// for ctx to expire
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.stopped = true
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("jobs still running at shutdown: %w", ctx.Err())
+	}
+}
+
+// Running reports whether the scheduler has started and not been stopped
+func (s *Scheduler) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.started && !s.stopped
+}
+
+// loop runs one job on its schedule until ctx is canceled
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	slog.Info("Job scheduled", "job", j.Name, "schedule", j.schedule().String())
+	if j.RunAtStart {
+		s.run(ctx, j)
+	}
+
+	for {
+		next := j.schedule().Next(s.now())
+		if next.IsZero() {
+			slog.Info("Job has no further runs", "job", j.Name)
+			j.setNext(nil)
+			return
+		}
+		if j.Jitter > 0 {
+			next = next.Add(s.jitter(j.Jitter))
+		}
+		j.setNext(&next)
+
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-j.rescheduled:
+			timer.Stop()
+		case <-timer.C:
+			s.run(ctx, j)
+		}
+	}
+}
+
+// run runs a job once, recording the outcome. A panic counts as a failure.
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	start := s.now()
+	j.mu.Lock()
+	j.status.Running = true
+	j.mu.Unlock()
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return j.Run(ctx)
+	}()
+	duration := s.now().Sub(start)
+
+	j.mu.Lock()
+	j.status.Running = false
+	j.status.Runs++
+	j.status.LastRun = &start
+	j.status.LastDuration = duration
+	j.status.LastError = ""
+	if err != nil {
+		j.status.Failures++
+		j.status.LastError = err.Error()
+	}
+	j.mu.Unlock()
+
+	if err != nil {
+		slog.Error("Job failed", "job", j.Name, "duration", duration, "error", err)
+		return
+	}
+	slog.Debug("Job finished", "job", j.Name, "duration", duration)
+}
+
+// schedule returns the job's current schedule
+func (j *job) schedule() Schedule {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.Schedule
+}
+
+// setNext records when the job runs next
+func (j *job) setNext(next *time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status.NextRun = next
+}
+
+// Status returns the status of every job, sorted by name
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	jobs := append([]*job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(jobs))
+	for _, j := range jobs {
+		j.mu.Lock()
+		statuses = append(statuses, j.status)
+		j.mu.Unlock()
+	}
+	sort.Slice(statuses, func(i, k int) bool { return statuses[i].Name < statuses[k].Name })
+	return statuses
+}
@@ -0,0 +1,204 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"watered/internal/monitoring"
+)
+
+// waitFor polls cond until it holds or a second passes
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScheduler_Register(t *testing.T) {
+	s := New()
+	noop := func(context.Context) error { return nil }
+
+	if err := s.Register(Job{Name: "a", Schedule: Every(time.Minute), Run: noop}); err != nil {
+		t.Fatalf("Failed to register job: %v", err)
+	}
+
+	invalid := []Job{
+		{Name: "", Schedule: Every(time.Minute), Run: noop},
+		{Name: "b", Run: noop},
+		{Name: "c", Schedule: Every(time.Minute)},
+		{Name: "a", Schedule: Every(time.Minute), Run: noop},
+		{Name: "d", Schedule: Every(0), Run: noop},
+	}
+	for _, job := range invalid {
+		if err := s.Register(job); err == nil {
+			t.Errorf("Expected error registering %+v", job)
+		}
+	}
+
+	s.Start(context.Background())
+	defer s.Stop(context.Background())
+	if err := s.Register(Job{Name: "late", Schedule: Every(time.Minute), Run: noop}); err == nil {
+		t.Error("Expected error registering after start")
+	}
+}
+
+func TestScheduler_RunsAndRecordsStatus(t *testing.T) {
+	s := New()
+	var runs atomic.Int32
+	s.Register(Job{
+		Name:     "counter",
+		Schedule: Every(5 * time.Millisecond),
+		Run: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+	s.Register(Job{
+		Name:       "failing",
+		Schedule:   Every(time.Hour),
+		RunAtStart: true,
+		Run:        func(context.Context) error { return errors.New("storage unavailable") },
+	})
+	s.Register(Job{
+		Name:       "panicking",
+		Schedule:   Every(time.Hour),
+		RunAtStart: true,
+		Run:        func(context.Context) error { panic("boom") },
+	})
+
+	s.Start(context.Background())
+	defer s.Stop(context.Background())
+	waitFor(t, func() bool {
+		statuses := s.Status()
+		return runs.Load() >= 3 && statuses[1].Runs == 1 && statuses[2].Runs == 1
+	})
+
+	statuses := s.Status()
+	counter, failing, panicking := statuses[0], statuses[1], statuses[2]
+	if counter.Name != "counter" || counter.Failures != 0 || counter.LastRun == nil || counter.NextRun == nil {
+		t.Errorf("Unexpected counter status: %+v", counter)
+	}
+	if failing.Failures != 1 || failing.LastError != "storage unavailable" || failing.Schedule != "every 1h0m0s" {
+		t.Errorf("Unexpected failing status: %+v", failing)
+	}
+	if panicking.Failures != 1 || !strings.Contains(panicking.LastError, "panic: boom") {
+		t.Errorf("Unexpected panicking status: %+v", panicking)
+	}
+
+	health := s.Check(context.Background())
+	if health.Status != monitoring.HealthStatusDegraded || !strings.Contains(health.Message, "2 of 3 jobs") {
+		t.Errorf("Expected degraded health for failing jobs, got %s: %s", health.Status, health.Message)
+	}
+}
+
+func TestScheduler_Jitter(t *testing.T) {
+	s := New()
+	s.jitter = func(max time.Duration) time.Duration { return max / 2 }
+	s.Register(Job{Name: "jittered", Schedule: Every(time.Hour), Jitter: 10 * time.Minute, Run: func(context.Context) error { return nil }})
+
+	before := time.Now()
+	s.Start(context.Background())
+	defer s.Stop(context.Background())
+	waitFor(t, func() bool { return s.Status()[0].NextRun != nil })
+
+	next := *s.Status()[0].NextRun
+	if next.Before(before.Add(65*time.Minute)) || next.After(time.Now().Add(65*time.Minute)) {
+		t.Errorf("Expected the next run an hour and five minutes from now, got %v", next.Sub(before))
+	}
+}
+
+func TestScheduler_Reschedule(t *testing.T) {
+	s := New()
+	var runs atomic.Int32
+	s.Register(Job{Name: "reminders", Schedule: Every(time.Hour), Run: func(context.Context) error { runs.Add(1); return nil }})
+
+	if err := s.Reschedule("missing", Every(time.Minute)); err == nil {
+		t.Error("Expected an error rescheduling an unknown job")
+	}
+	if err := s.Reschedule("reminders", Every(0)); err == nil {
+		t.Error("Expected an error for a schedule that never advances")
+	}
+
+	s.Start(context.Background())
+	defer s.Stop(context.Background())
+	waitFor(t, func() bool { return s.Status()[0].NextRun != nil })
+
+	// The job waiting an hour picks up the new schedule at once
+	if err := s.Reschedule("reminders", Every(5*time.Millisecond)); err != nil {
+		t.Fatalf("Failed to reschedule job: %v", err)
+	}
+	waitFor(t, func() bool { return runs.Load() >= 2 })
+	if schedule := s.Status()[0].Schedule; schedule != "every 5ms" {
+		t.Errorf("Expected the new schedule in the status, got %q", schedule)
+	}
+}
+
+func TestScheduler_StopWaitsForRunningJobs(t *testing.T) {
+	s := New()
+	started := make(chan struct{})
+	var finished atomic.Bool
+	s.Register(Job{
+		Name:       "slow",
+		Schedule:   Every(time.Hour),
+		RunAtStart: true,
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			finished.Store(true)
+			return nil
+		},
+	})
+
+	if s.Ready(context.Background()) == nil {
+		t.Error("Expected the scheduler not to be ready before Start")
+	}
+	s.Start(context.Background())
+	<-started
+	if err := s.Ready(context.Background()); err != nil {
+		t.Errorf("Expected the running scheduler to be ready, got %v", err)
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Failed to stop: %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Expected Stop to wait for the running job")
+	}
+	if s.Ready(context.Background()) == nil {
+		t.Error("Expected the scheduler not to be ready after Stop")
+	}
+}
+
+func TestScheduler_StopTimesOut(t *testing.T) {
+	s := New()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	s.Register(Job{
+		Name:       "stuck",
+		Schedule:   Every(time.Hour),
+		RunAtStart: true,
+		Run: func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		},
+	})
+
+	s.Start(context.Background())
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); err == nil {
+		t.Error("Expected an error when jobs outlive the shutdown deadline")
+	}
+}
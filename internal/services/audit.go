@@ -0,0 +1,97 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+// AuditPage is one page of audit log entries, newest first
+type AuditPage struct {
+	Entries []*models.AuditEntry `json:"entries"`
+	// Total counts every entry matching the filter, across all pages
+	Total int `json:"total"`
+}
+
+// AuditService records admin changes in the audit log and answers queries
+// over it
+type AuditService struct {
+	storage storage.Storage
+	now     func() time.Time
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(storage storage.Storage) *AuditService {
+	return &AuditService{
+		storage: storage,
+		now:     time.Now,
+	}
+}
+
+// Record adds an entry to the audit log. oldValue and newValue are stored as
+// JSON; nil leaves them out.
+func (s *AuditService) Record(actor, action, target string, oldValue, newValue interface{}) (*models.AuditEntry, error) {
+	if action == "" {
+		return nil, fmt.Errorf("action is required")
+	}
+
+	entry := &models.AuditEntry{
+		At:     s.now(),
+		Actor:  actor,
+		Action: action,
+		Target: target,
+	}
+	var err error
+	if entry.Old, err = auditValue(oldValue); err != nil {
+		return nil, err
+	}
+	if entry.New, err = auditValue(newValue); err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.AddAuditEntry(entry); err != nil {
+		return nil, fmt.Errorf("failed to record audit entry: %w", err)
+	}
+
+	slog.Info("Admin change", "audit", true, "action", action, "target", target, "by", actor)
+	return entry, nil
+}
+
+// List returns the page of entries matching the filter starting at offset.
+// A limit of zero returns every entry from offset on.
+func (s *AuditService) List(filter models.AuditFilter, limit, offset int) (*AuditPage, error) {
+	entries, err := s.storage.ListAuditEntries(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	page := &AuditPage{Entries: []*models.AuditEntry{}, Total: len(entries)}
+	if offset < len(entries) {
+		end := len(entries)
+		if limit > 0 && offset+limit < end {
+			end = offset + limit
+		}
+		page.Entries = entries[offset:end]
+	}
+	return page, nil
+}
+
+// auditValue encodes a value for an audit entry, leaving nil values and nil
+// pointers out
+func auditValue(value interface{}) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit value: %w", err)
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	return data, nil
+}
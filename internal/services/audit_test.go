@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func TestAuditService_Record(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service := NewAuditService(store)
+
+	entry, err := service.Record("admin@example.com", models.AuditConfigTimeout, "", 24, 48)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if entry.ID != 1 || string(entry.Old) != "24" || string(entry.New) != "48" {
+		t.Errorf("Expected old and new values as JSON, got %+v", entry)
+	}
+
+	var device *models.Device
+	entry, err = service.Record("admin@example.com", models.AuditUserAdd, "new@example.com", device, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if entry.Old != nil || entry.New != nil {
+		t.Errorf("Expected nil values to be left out, got %+v", entry)
+	}
+
+	if _, err := service.Record("admin@example.com", "", "", nil, nil); err == nil {
+		t.Error("Expected an error without an action")
+	}
+}
+
+func TestAuditService_List(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service := NewAuditService(store)
+
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, actor := range []string{"a@example.com", "b@example.com", "a@example.com", "a@example.com"} {
+		service.now = func() time.Time { return start.Add(time.Duration(i) * time.Hour) }
+		service.Record(actor, models.AuditPlantReset, "1", nil, nil)
+	}
+
+	page, err := service.List(models.AuditFilter{Actor: "A@example.com"}, 2, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page.Total != 3 || len(page.Entries) != 2 || page.Entries[0].ID != 4 || page.Entries[1].ID != 3 {
+		t.Errorf("Expected the first page of a@example.com's entries newest first, got %+v", page)
+	}
+
+	page, _ = service.List(models.AuditFilter{Actor: "a@example.com"}, 2, 2)
+	if page.Total != 3 || len(page.Entries) != 1 || page.Entries[0].ID != 1 {
+		t.Errorf("Expected the last page to hold the oldest entry, got %+v", page)
+	}
+
+	page, _ = service.List(models.AuditFilter{Since: start.Add(time.Hour), Until: start.Add(3 * time.Hour)}, 0, 0)
+	if page.Total != 2 || page.Entries[0].ID != 3 || page.Entries[1].ID != 2 {
+		t.Errorf("Expected entries in [since, until), got %+v", page)
+	}
+
+	page, _ = service.List(models.AuditFilter{}, 10, 10)
+	if page.Total != 4 || len(page.Entries) != 0 {
+		t.Errorf("Expected an empty page past the end, got %+v", page)
+	}
+}
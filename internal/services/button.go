@@ -0,0 +1,64 @@
+package services
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+)
+
+// ButtonService records waterings from "I watered it" buttons registered as
+// devices. Cheap buttons bounce and people press twice to be sure, so a
+// press soon after the plant's last watering is ignored.
+type ButtonService struct {
+	deviceService *DeviceService
+	plantService  *PlantService
+	debounce      time.Duration
+	now           func() time.Time
+
+	// mu serialises presses so two arriving together record one watering
+	mu sync.Mutex
+}
+
+// NewButtonService creates a button service
+func NewButtonService(deviceService *DeviceService, plantService *PlantService, cfg config.ButtonConfig) *ButtonService {
+	return &ButtonService{
+		deviceService: deviceService,
+		plantService:  plantService,
+		debounce:      cfg.Debounce,
+		now:           time.Now,
+	}
+}
+
+// Press authenticates a button by its token and waters its plant. It reports
+// whether a watering was recorded; a press within the debounce window of the
+// plant's last watering, whoever recorded it, returns the plant unchanged.
+func (s *ButtonService) Press(token string) (*models.PlantState, bool, error) {
+	device, err := s.deviceService.AuthenticateToken(models.DeviceKindButton, token)
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plant, err := s.plantService.GetPlantByID(device.PlantID)
+	if err != nil {
+		return nil, false, err
+	}
+	now := s.now()
+	if plant.LastWatered != nil && now.Sub(*plant.LastWatered) < s.debounce {
+		slog.Debug("Ignoring button press soon after a watering", "device", device.ID, "plant_id", plant.ID)
+		return plant, false, nil
+	}
+
+	plant, err = s.plantService.WaterPlantByIDAt(plant.ID, models.ButtonWaterer, now)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to record button watering: %w", err)
+	}
+	slog.Info("Button recorded a watering", "device", device.ID, "plant_id", plant.ID)
+	return plant, true, nil
+}
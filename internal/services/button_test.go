@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func TestButtonService_Press(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := NewPlantService(store)
+	devices := NewDeviceService(store, plantService, config.SensorConfig{})
+	service := NewButtonService(devices, plantService, config.ButtonConfig{Debounce: 10 * time.Minute})
+	now := time.Now().Add(-time.Hour)
+	service.now = func() time.Time { return now }
+
+	_, token, err := devices.Register("hallway", "Hallway button", models.DeviceKindButton, models.DefaultPlantID, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Failed to register button: %v", err)
+	}
+
+	plant, recorded, err := service.Press(token)
+	if err != nil || !recorded || plant.WateredBy != models.ButtonWaterer {
+		t.Fatalf("Expected the press to water the plant, got %+v, %v (%v)", plant, recorded, err)
+	}
+
+	// A flaky contact fires several times in a row
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Minute)
+		if _, recorded, err := service.Press(token); err != nil || recorded {
+			t.Errorf("Expected a repeat press to be ignored, got %v (%v)", recorded, err)
+		}
+	}
+	if events, _ := store.ListWateringEvents(0); len(events) != 1 {
+		t.Errorf("Expected one watering, got %d", len(events))
+	}
+
+	now = now.Add(10 * time.Minute)
+	if _, recorded, _ := service.Press(token); !recorded {
+		t.Error("Expected a press after the debounce window to be recorded")
+	}
+
+	if _, _, err := service.Press("wd_wrong"); !errors.Is(err, ErrInvalidDeviceToken) {
+		t.Errorf("Expected ErrInvalidDeviceToken, got %v", err)
+	}
+
+	// Sensors cannot press
+	_, sensorToken, _ := devices.Register("kitchen", "Kitchen sensor", models.DeviceKindSensor, models.DefaultPlantID, "admin@example.com")
+	if _, _, err := service.Press(sensorToken); !errors.Is(err, ErrInvalidDeviceToken) {
+		t.Errorf("Expected a sensor's token to be refused, got %v", err)
+	}
+}
+
+func TestButtonService_PressAfterManualWatering(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := NewPlantService(store)
+	devices := NewDeviceService(store, plantService, config.SensorConfig{})
+	service := NewButtonService(devices, plantService, config.ButtonConfig{Debounce: 10 * time.Minute})
+
+	_, token, _ := devices.Register("hallway", "Hallway button", models.DeviceKindButton, models.DefaultPlantID, "admin@example.com")
+	if _, err := plantService.WaterPlantByID(models.DefaultPlantID, "alice@example.com"); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+
+	plant, recorded, err := service.Press(token)
+	if err != nil || recorded || plant.WateredBy != "alice@example.com" {
+		t.Errorf("Expected the press to be ignored right after alice watered, got %+v, %v (%v)", plant, recorded, err)
+	}
+}
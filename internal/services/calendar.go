@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"watered/internal/ical"
+)
+
+const (
+	// calendarHistory is how far back a plant's calendar lists waterings
+	calendarHistory = 365 * 24 * time.Hour
+	// calendarEventDuration is how long each event in a plant's calendar lasts
+	calendarEventDuration = 15 * time.Minute
+	// calendarRefreshInterval is how often subscribers are asked to refresh
+	calendarRefreshInterval = time.Hour
+)
+
+// CalendarByID returns a plant's watering calendar: its waterings over the
+// past year and a repeating event for when it is next due, every timeout
+// after that. Events do not say who watered, since calendar feeds are
+// shared outside the household.
+func (s *PlantService) CalendarByID(id int) (*ical.Calendar, error) {
+	plant, err := s.GetPlantByID(id)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.storage.ListWateringEvents(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list waterings: %w", err)
+	}
+
+	now := time.Now()
+	calendar := &ical.Calendar{Name: plant.Name, RefreshInterval: calendarRefreshInterval}
+	for _, event := range events {
+		if now.Sub(event.WateredAt) > calendarHistory {
+			continue
+		}
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:      "watering-" + strconv.Itoa(event.ID) + "@watered",
+			Start:    event.WateredAt,
+			Duration: calendarEventDuration,
+			Summary:  "💧 " + plant.Name + " watered",
+		})
+	}
+
+	// Repeat from when the plant is next due, or from now if it has never
+	// been watered. Calendar apps only repeat in whole hours or days.
+	due := now.Truncate(time.Minute)
+	if plant.LastWatered != nil {
+		due = plant.LastWatered.Add(plant.DueAfter())
+	}
+	interval := plant.DueAfter().Round(time.Hour)
+	if interval < time.Hour {
+		interval = time.Hour
+	}
+	rrule := "FREQ=HOURLY;INTERVAL=" + strconv.Itoa(int(interval/time.Hour))
+	if interval%(24*time.Hour) == 0 {
+		rrule = "FREQ=DAILY;INTERVAL=" + strconv.Itoa(int(interval/(24*time.Hour)))
+	}
+	calendar.Events = append(calendar.Events, ical.Event{
+		UID:         "schedule-" + strconv.Itoa(plant.ID) + "@watered",
+		Start:       due,
+		Duration:    calendarEventDuration,
+		Summary:     "🪴 Water " + plant.Name,
+		Description: "Repeats every " + strconv.Itoa(int(interval/time.Hour)) + " hours. The next time moves when the plant is watered.",
+		RRule:       rrule,
+	})
+
+	return calendar, nil
+}
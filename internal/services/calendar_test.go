@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func TestPlantService_CalendarByID(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service := NewPlantService(store)
+
+	// Never watered: the schedule starts now
+	calendar, err := service.CalendarByID(models.DefaultPlantID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(calendar.Events) != 1 || calendar.Events[0].RRule != "FREQ=DAILY;INTERVAL=1" || time.Since(calendar.Events[0].Start) > time.Minute {
+		t.Fatalf("Expected only a daily schedule from now, got %+v", calendar.Events)
+	}
+
+	longAgo := time.Now().Add(-400 * 24 * time.Hour)
+	lastWatered := time.Now().Add(-2 * time.Hour)
+	for _, at := range []time.Time{longAgo, lastWatered.Add(-24 * time.Hour), lastWatered} {
+		if _, err := service.WaterPlantByIDAt(models.DefaultPlantID, "alice@example.com", at); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	calendar, err = service.CalendarByID(models.DefaultPlantID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(calendar.Events) != 3 {
+		t.Fatalf("Expected the past year's two waterings and the schedule, got %+v", calendar.Events)
+	}
+	for _, event := range calendar.Events {
+		if strings.Contains(event.Summary+event.Description, "alice") {
+			t.Errorf("Expected events not to say who watered, got %+v", event)
+		}
+	}
+	schedule := calendar.Events[2]
+	if !schedule.Start.Equal(lastWatered.Add(24*time.Hour)) || schedule.UID != "schedule-1@watered" {
+		t.Errorf("Expected the schedule to start when the plant is next due, got %+v", schedule)
+	}
+
+	// Timeouts that are not whole days repeat hourly
+	fern, err := service.CreatePlant("Fern", 30)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	calendar, err = service.CalendarByID(fern.ID)
+	if err != nil || calendar.Events[0].RRule != "FREQ=HOURLY;INTERVAL=30" {
+		t.Errorf("Expected an hourly schedule, got %+v (%v)", calendar, err)
+	}
+
+	if _, err := service.CalendarByID(42); !errors.Is(err, ErrPlantNotFound) {
+		t.Errorf("Expected ErrPlantNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/tracing"
+)
+
+// Errors returned by care task operations
+var (
+	ErrCareTaskNotFound  = errors.New("care task not found")
+	ErrInvalidCareTask   = errors.New("invalid care task")
+	ErrDuplicateCareTask = errors.New("plant already has a task of this type")
+)
+
+// ListCareTasksByID returns a plant's care tasks, sorted by ID
+func (s *PlantService) ListCareTasksByID(id int) ([]*models.CareTask, error) {
+	if _, err := s.GetPlantByID(id); err != nil {
+		return nil, err
+	}
+	tasks, err := s.storage.ListCareTasks(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list care tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// GetCareTaskByID returns one of a plant's care tasks. A task belonging to
+// another plant is reported as not found.
+func (s *PlantService) GetCareTaskByID(plantID, taskID int) (*models.CareTask, error) {
+	if _, err := s.GetPlantByID(plantID); err != nil {
+		return nil, err
+	}
+	task, err := s.storage.GetCareTask(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get care task: %w", err)
+	}
+	if task == nil || task.PlantID != plantID {
+		return nil, ErrCareTaskNotFound
+	}
+	return task, nil
+}
+
+// CreateCareTaskByID adds a recurring care task to a plant. Its first
+// interval runs from now.
+func (s *PlantService) CreateCareTaskByID(id int, taskType models.CareTaskType, intervalHours int) (*models.CareTask, error) {
+	tasks, err := s.ListCareTasksByID(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range tasks {
+		if existing.Type == taskType {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateCareTask, taskType)
+		}
+	}
+
+	now := time.Now()
+	task := &models.CareTask{
+		PlantID:       id,
+		Type:          taskType,
+		IntervalHours: intervalHours,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := task.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCareTask, err)
+	}
+
+	if err := s.storage.SaveCareTask(task); err != nil {
+		return nil, fmt.Errorf("failed to save care task: %w", err)
+	}
+
+	slog.Info("Care task created", "plant_id", id, "task_id", task.ID, "type", task.Type, "interval_hours", intervalHours)
+	return task, nil
+}
+
+// UpdateCareTaskByID changes how often one of a plant's care tasks recurs
+func (s *PlantService) UpdateCareTaskByID(plantID, taskID int, intervalHours int) (*models.CareTask, error) {
+	task, err := s.GetCareTaskByID(plantID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	task.IntervalHours = intervalHours
+	task.UpdatedAt = time.Now()
+	if err := task.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCareTask, err)
+	}
+
+	if err := s.storage.SaveCareTask(task); err != nil {
+		return nil, fmt.Errorf("failed to save care task: %w", err)
+	}
+
+	slog.Info("Care task updated", "plant_id", plantID, "task_id", taskID, "interval_hours", intervalHours)
+	return task, nil
+}
+
+// DeleteCareTaskByID removes one of a plant's care tasks and its history
+func (s *PlantService) DeleteCareTaskByID(plantID, taskID int) (*models.CareTask, error) {
+	task, err := s.GetCareTaskByID(plantID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.DeleteCareTask(taskID); err != nil {
+		return nil, fmt.Errorf("failed to delete care task: %w", err)
+	}
+
+	slog.Info("Care task deleted", "plant_id", plantID, "task_id", taskID, "type", task.Type)
+	return task, nil
+}
+
+// CompleteCareTaskByID records that one of a plant's care tasks was done
+// now, restarting its interval
+func (s *PlantService) CompleteCareTaskByID(plantID, taskID int, doneBy string) (*models.CareTask, error) {
+	s, span := s.trace("PlantService.CompleteCareTaskByID", tracing.Int("plant.id", plantID))
+	defer span.End()
+
+	task, err := s.GetCareTaskByID(plantID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	event := &models.CareTaskEvent{TaskID: task.ID, PlantID: plantID, Type: task.Type, DoneAt: now, DoneBy: doneBy}
+	if err := s.storage.AddCareTaskEvent(event); err != nil {
+		return nil, fmt.Errorf("failed to record care task: %w", err)
+	}
+
+	task.LastDone = &now
+	task.LastDoneBy = doneBy
+	task.UpdatedAt = now
+	if err := s.storage.SaveCareTask(task); err != nil {
+		return nil, fmt.Errorf("failed to save care task: %w", err)
+	}
+
+	slog.Info("Care task completed", "plant_id", plantID, "task_id", taskID, "type", task.Type, "by", doneBy)
+	return task, nil
+}
+
+// CareTaskHistoryByID returns when one of a plant's care tasks was done,
+// oldest first
+func (s *PlantService) CareTaskHistoryByID(plantID, taskID int) ([]*models.CareTaskEvent, error) {
+	if _, err := s.GetCareTaskByID(plantID, taskID); err != nil {
+		return nil, err
+	}
+	events, err := s.storage.ListCareTaskEvents(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list care task history: %w", err)
+	}
+	return events, nil
+}
+
+// OverallStatus rolls a plant's current watering status and its care tasks
+// up into one status
+func (s *PlantService) OverallStatus(plant *models.PlantState) models.CareStatus {
+	return s.overallStatus(plant, plant.GetHealthStatus())
+}
+
+// overallStatus rolls a plant's watering status and its care tasks up into
+// one status. A plant whose tasks cannot be read is judged on watering alone.
+func (s *PlantService) overallStatus(plant *models.PlantState, watering models.PlantHealthStatus) models.CareStatus {
+	tasks, err := s.storage.ListCareTasks(plant.ID)
+	if err != nil {
+		slog.Warn("Failed to list care tasks", "plant_id", plant.ID, "error", err)
+	}
+	return models.OverallStatus(watering, tasks)
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func TestPlantService_CareTasks(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewPlantService(store)
+	service.WaterPlant("test@example.com")
+	plant, err := service.CreatePlant("Cactus", 336)
+	if err != nil {
+		t.Fatalf("Failed to create plant: %v", err)
+	}
+
+	mist, err := service.CreateCareTaskByID(models.DefaultPlantID, models.CareTaskMist, 48)
+	if err != nil {
+		t.Fatalf("CreateCareTaskByID() error = %v", err)
+	}
+	if _, err := service.CreateCareTaskByID(models.DefaultPlantID, models.CareTaskMist, 24); !errors.Is(err, ErrDuplicateCareTask) {
+		t.Errorf("Expected a second mist task to be rejected, got %v", err)
+	}
+	if _, err := service.CreateCareTaskByID(models.DefaultPlantID, models.CareTaskFertilize, 0); !errors.Is(err, ErrInvalidCareTask) {
+		t.Errorf("Expected a zero interval to be rejected, got %v", err)
+	}
+	if _, err := service.CreateCareTaskByID(42, models.CareTaskMist, 48); !errors.Is(err, ErrPlantNotFound) {
+		t.Errorf("Expected an unknown plant to be rejected, got %v", err)
+	}
+	if _, err := service.CompleteCareTaskByID(plant.ID, mist.ID, "test@example.com"); !errors.Is(err, ErrCareTaskNotFound) {
+		t.Errorf("Expected another plant's task to be hidden, got %v", err)
+	}
+
+	done, err := service.CompleteCareTaskByID(models.DefaultPlantID, mist.ID, "test@example.com")
+	if err != nil || done.LastDone == nil || done.LastDoneBy != "test@example.com" {
+		t.Fatalf("Expected the task to be completed, got %+v (%v)", done, err)
+	}
+	if events, _ := service.CareTaskHistoryByID(models.DefaultPlantID, mist.ID); len(events) != 1 || events[0].Type != models.CareTaskMist {
+		t.Errorf("Expected one completion in the history, got %+v", events)
+	}
+
+	status, _ := service.GetPlantStatus()
+	if status.OverallStatus != models.CareStatusHealthy {
+		t.Errorf("Expected a healthy overall status, got %v", status.OverallStatus)
+	}
+
+	// Deleting a plant deletes its tasks
+	repot, _ := service.CreateCareTaskByID(plant.ID, models.CareTaskRepot, 8760)
+	if err := service.DeletePlant(plant.ID); err != nil {
+		t.Fatalf("DeletePlant() error = %v", err)
+	}
+	if task, _ := store.GetCareTask(repot.ID); task != nil {
+		t.Errorf("Expected the deleted plant's task to be gone, got %+v", task)
+	}
+	if tasks, _ := store.ListCareTasks(0); len(tasks) != 1 || tasks[0].ID != mist.ID {
+		t.Errorf("Expected only the default plant's task to remain, got %+v", tasks)
+	}
+}
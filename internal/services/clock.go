@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ClientTimeHeader carries the client's clock on any request, as RFC3339 or
+// unix milliseconds, so the server can warn about misconfigured devices
+const ClientTimeHeader = "X-Client-Time"
+
+// DefaultClockSkewTolerance is how far a client clock may drift from the
+// server before responses warn about it and client timestamps in the future
+// are rejected
+const DefaultClockSkewTolerance = time.Minute
+
+// ClockSkew describes a client clock that differs from the server's by more
+// than the tolerance
+type ClockSkew struct {
+	ClientTime time.Time `json:"client_time"`
+	ServerTime time.Time `json:"server_time"`
+	// SkewSeconds is positive when the client clock is ahead of the server
+	SkewSeconds float64 `json:"skew_seconds"`
+	Warning     string  `json:"warning"`
+}
+
+// ParseClientTime parses an X-Client-Time header value
+func ParseClientTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(millis), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be RFC3339 or unix milliseconds, got %q", ClientTimeHeader, value)
+	}
+	return t, nil
+}
+
+// MeasureClockSkew compares a client's clock with the server's and returns
+// the skew, or nil when it is within tolerance
+func MeasureClockSkew(client, server time.Time, tolerance time.Duration) *ClockSkew {
+	skew := client.Sub(server)
+	if skew.Abs() <= tolerance {
+		return nil
+	}
+
+	direction := "ahead of"
+	if skew < 0 {
+		direction = "behind"
+	}
+	return &ClockSkew{
+		ClientTime:  client,
+		ServerTime:  server,
+		SkewSeconds: math.Round(skew.Seconds()),
+		Warning:     fmt.Sprintf("Your device clock is %s %s the server, check its date and time settings", skew.Abs().Round(time.Second), direction),
+	}
+}
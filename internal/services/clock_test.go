@@ -0,0 +1,46 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseClientTime(t *testing.T) {
+	want := time.Date(2024, 5, 10, 18, 0, 0, 0, time.UTC)
+
+	for _, value := range []string{"2024-05-10T18:00:00Z", "2024-05-10T20:00:00+02:00", "1715364000000", " 1715364000000 "} {
+		got, err := ParseClientTime(value)
+		if err != nil {
+			t.Errorf("ParseClientTime(%q) failed: %v", value, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseClientTime(%q) = %v, want %v", value, got, want)
+		}
+	}
+
+	for _, value := range []string{"", "yesterday", "2024-05-10 18:00"} {
+		if _, err := ParseClientTime(value); err == nil {
+			t.Errorf("Expected ParseClientTime(%q) to fail", value)
+		}
+	}
+}
+
+func TestMeasureClockSkew(t *testing.T) {
+	server := time.Date(2024, 5, 10, 18, 0, 0, 0, time.UTC)
+
+	if skew := MeasureClockSkew(server.Add(59*time.Second), server, time.Minute); skew != nil {
+		t.Errorf("Expected no skew within tolerance, got %+v", skew)
+	}
+
+	ahead := MeasureClockSkew(server.Add(2*time.Hour), server, time.Minute)
+	if ahead == nil || ahead.SkewSeconds != 7200 || !strings.Contains(ahead.Warning, "2h0m0s ahead of") {
+		t.Errorf("Unexpected skew for a clock ahead: %+v", ahead)
+	}
+
+	behind := MeasureClockSkew(server.Add(-90*time.Second), server, time.Minute)
+	if behind == nil || behind.SkewSeconds != -90 || !strings.Contains(behind.Warning, "behind") {
+		t.Errorf("Unexpected skew for a clock behind: %+v", behind)
+	}
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/randtoken"
+	"watered/internal/storage"
+)
+
+// DeviceTokenPrefix starts every issued device token so tokens are easy to
+// recognize in firmware and secret scanners
+const DeviceTokenPrefix = "wd_"
+
+// lastSeenResolution is how stale a device's last seen time may get before a
+// request from it is written back, so a sensor reporting every few seconds
+// does not rewrite the data file each time
+const lastSeenResolution = time.Minute
+
+// maxDeviceNameLength caps the label shown in the admin list and audit logs
+const maxDeviceNameLength = 64
+
+// deviceIDPattern matches device IDs, the same IDs SENSOR_DEVICES accepts.
+// They appear in ingestion URLs and MQTT topics.
+var deviceIDPattern = regexp.MustCompile(`^[-_A-Za-z0-9]{1,64}$`)
+
+var (
+	// ErrDeviceNotFound is returned for an ID no device is registered under
+	ErrDeviceNotFound = errors.New("device not found")
+	// ErrDeviceExists is returned when registering an ID that is taken
+	ErrDeviceExists = errors.New("device already exists")
+	// ErrInvalidDevice is returned for a malformed ID, name, kind or plant
+	ErrInvalidDevice = errors.New("invalid device")
+	// ErrInvalidDeviceToken is returned when a device's token does not match
+	ErrInvalidDeviceToken = errors.New("invalid device token")
+	// ErrDeviceInactive is returned when a device marked inactive authenticates
+	ErrDeviceInactive = errors.New("device is inactive")
+)
+
+// DeviceUpdate changes a registered device. Nil fields are left alone.
+type DeviceUpdate struct {
+	Name    *string `json:"name"`
+	PlantID *int    `json:"plant_id"`
+	Active  *bool   `json:"active"`
+}
+
+// DeviceService registers sensors and buttons, issues their tokens and
+// authenticates their requests. Devices listed in SENSOR_DEVICES are managed
+// in the environment instead and cannot be registered again.
+type DeviceService struct {
+	storage      storage.Storage
+	plantService *PlantService
+	configured   map[string]config.SensorDevice
+	now          func() time.Time
+}
+
+// NewDeviceService creates a device service alongside the configured sensors
+func NewDeviceService(storage storage.Storage, plantService *PlantService, cfg config.SensorConfig) *DeviceService {
+	return &DeviceService{
+		storage:      storage,
+		plantService: plantService,
+		configured:   cfg.Devices,
+		now:          time.Now,
+	}
+}
+
+// Register adds an active device placed with plantID and issues its token.
+// It returns the stored device and the plaintext token, which is not
+// retrievable afterwards.
+func (s *DeviceService) Register(id, name, kind string, plantID int, createdBy string) (*models.Device, string, error) {
+	if !deviceIDPattern.MatchString(id) {
+		return nil, "", fmt.Errorf("%w: ID must be 1 to 64 letters, digits, dashes or underscores", ErrInvalidDevice)
+	}
+	if kind != models.DeviceKindSensor && kind != models.DeviceKindButton {
+		return nil, "", fmt.Errorf("%w: kind must be %q or %q", ErrInvalidDevice, models.DeviceKindSensor, models.DeviceKindButton)
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		name = id
+	}
+	if err := s.validate(name, plantID); err != nil {
+		return nil, "", err
+	}
+
+	if _, ok := s.configured[id]; ok {
+		return nil, "", fmt.Errorf("%w: %s is configured in SENSOR_DEVICES", ErrDeviceExists, id)
+	}
+	existing, err := s.storage.GetDevice(id)
+	if err != nil {
+		return nil, "", err
+	}
+	if existing != nil {
+		return nil, "", ErrDeviceExists
+	}
+
+	token, err := newDeviceToken()
+	if err != nil {
+		return nil, "", err
+	}
+	now := s.now()
+	device := &models.Device{
+		ID:             id,
+		Name:           name,
+		Kind:           kind,
+		PlantID:        plantID,
+		Active:         true,
+		TokenHash:      randtoken.Hash(token),
+		TokenRotatedAt: now,
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+	}
+	if err := s.storage.SaveDevice(device); err != nil {
+		return nil, "", fmt.Errorf("failed to store device: %w", err)
+	}
+
+	slog.Info("Device registered", "audit", true, "device", id, "kind", kind, "plant_id", plantID, "by", createdBy)
+	return device, token, nil
+}
+
+// List returns all registered devices without their token hashes
+func (s *DeviceService) List() ([]*models.Device, error) {
+	devices, err := s.storage.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+	for _, device := range devices {
+		device.TokenHash = ""
+	}
+	return devices, nil
+}
+
+// Get returns a registered device without its token hash
+func (s *DeviceService) Get(id string) (*models.Device, error) {
+	device, err := s.storage.GetDevice(id)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, ErrDeviceNotFound
+	}
+	device.TokenHash = ""
+	return device, nil
+}
+
+// Update renames, moves, activates or deactivates a device
+func (s *DeviceService) Update(id string, update DeviceUpdate, updatedBy string) (*models.Device, error) {
+	device, err := s.storage.GetDevice(id)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, ErrDeviceNotFound
+	}
+
+	if update.Name != nil {
+		device.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.PlantID != nil {
+		device.PlantID = *update.PlantID
+	}
+	if update.Active != nil {
+		device.Active = *update.Active
+	}
+	if err := s.validate(device.Name, device.PlantID); err != nil {
+		return nil, err
+	}
+	if err := s.storage.SaveDevice(device); err != nil {
+		return nil, fmt.Errorf("failed to store device: %w", err)
+	}
+
+	slog.Info("Device updated", "audit", true, "device", id, "plant_id", device.PlantID, "active", device.Active, "by", updatedBy)
+	device.TokenHash = ""
+	return device, nil
+}
+
+// RotateToken issues a new token for a device; the old one stops working
+// at once. It returns the device and the plaintext token.
+func (s *DeviceService) RotateToken(id, rotatedBy string) (*models.Device, string, error) {
+	device, err := s.storage.GetDevice(id)
+	if err != nil {
+		return nil, "", err
+	}
+	if device == nil {
+		return nil, "", ErrDeviceNotFound
+	}
+
+	token, err := newDeviceToken()
+	if err != nil {
+		return nil, "", err
+	}
+	device.TokenHash = randtoken.Hash(token)
+	device.TokenRotatedAt = s.now()
+	if err := s.storage.SaveDevice(device); err != nil {
+		return nil, "", fmt.Errorf("failed to store device: %w", err)
+	}
+
+	slog.Info("Device token rotated", "audit", true, "device", id, "by", rotatedBy)
+	device.TokenHash = ""
+	return device, token, nil
+}
+
+// Delete removes a device. Readings it reported are kept. It returns false
+// when no device with that ID exists.
+func (s *DeviceService) Delete(id, deletedBy string) (bool, error) {
+	device, err := s.storage.GetDevice(id)
+	if err != nil {
+		return false, err
+	}
+	if device == nil {
+		return false, nil
+	}
+	if err := s.storage.DeleteDevice(id); err != nil {
+		return false, err
+	}
+
+	slog.Info("Device deleted", "audit", true, "device", id, "kind", device.Kind, "by", deletedBy)
+	return true, nil
+}
+
+// Authenticate checks a device's token and records that it was seen. Only
+// active devices of the given kind authenticate.
+func (s *DeviceService) Authenticate(id, kind, token string) (*models.Device, error) {
+	device, err := s.storage.GetDevice(id)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil || device.Kind != kind {
+		return nil, ErrDeviceNotFound
+	}
+	if subtle.ConstantTimeCompare([]byte(randtoken.Hash(token)), []byte(device.TokenHash)) != 1 {
+		return nil, ErrInvalidDeviceToken
+	}
+	return s.seen(device)
+}
+
+// AuthenticateToken finds the device of the given kind a token belongs to,
+// for devices such as buttons that can only be set up with a URL and a
+// token. It records that the device was seen.
+func (s *DeviceService) AuthenticateToken(kind, token string) (*models.Device, error) {
+	devices, err := s.storage.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+	hash := []byte(randtoken.Hash(token))
+	for _, device := range devices {
+		if device.Kind == kind && subtle.ConstantTimeCompare(hash, []byte(device.TokenHash)) == 1 {
+			return s.seen(device)
+		}
+	}
+	return nil, ErrInvalidDeviceToken
+}
+
+// seen refuses inactive devices and records that an active one was seen
+func (s *DeviceService) seen(device *models.Device) (*models.Device, error) {
+	if !device.Active {
+		return nil, ErrDeviceInactive
+	}
+
+	now := s.now()
+	if device.LastSeen == nil || now.Sub(*device.LastSeen) >= lastSeenResolution {
+		device.LastSeen = &now
+		if err := s.storage.SaveDevice(device); err != nil {
+			slog.Error("Failed to record device last seen", "device", device.ID, "error", err)
+		}
+	}
+	return device, nil
+}
+
+// Has reports whether any device of the given kind is registered, active or
+// not
+func (s *DeviceService) Has(kind string) bool {
+	devices, err := s.storage.ListDevices()
+	if err != nil {
+		return false
+	}
+	for _, device := range devices {
+		if device.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks a device's name and that its plant exists
+func (s *DeviceService) validate(name string, plantID int) error {
+	if name == "" || len(name) > maxDeviceNameLength {
+		return fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidDevice, maxDeviceNameLength)
+	}
+	if _, err := s.plantService.GetPlantByID(plantID); err != nil {
+		if errors.Is(err, ErrPlantNotFound) {
+			return fmt.Errorf("%w: plant %d does not exist", ErrInvalidDevice, plantID)
+		}
+		return err
+	}
+	return nil
+}
+
+// newDeviceToken generates a random device token
+func newDeviceToken() (string, error) {
+	secret, err := randtoken.String(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate device token: %w", err)
+	}
+	return DeviceTokenPrefix + secret, nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func newTestDeviceService(store storage.Storage) *DeviceService {
+	return NewDeviceService(store, NewPlantService(store), config.SensorConfig{
+		Devices: map[string]config.SensorDevice{"configured": {PlantID: 1, Token: "configured-token"}},
+	})
+}
+
+func TestDeviceService_Register(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service := newTestDeviceService(store)
+
+	device, token, err := service.Register("hallway", "", models.DeviceKindButton, models.DefaultPlantID, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(token, DeviceTokenPrefix) || !device.Active || device.Name != "hallway" || device.CreatedBy != "admin@example.com" {
+		t.Errorf("Expected an active device named after its ID with a token, got %+v (%q)", device, token)
+	}
+	stored, _ := store.GetDevice("hallway")
+	if stored == nil || stored.TokenHash == "" || strings.Contains(stored.TokenHash, token) {
+		t.Errorf("Expected only a hash of the token to be stored, got %+v", stored)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		kind    string
+		plantID int
+		want    error
+	}{
+		{"taken", "hallway", models.DeviceKindButton, 1, ErrDeviceExists},
+		{"configured", "configured", models.DeviceKindSensor, 1, ErrDeviceExists},
+		{"bad ID", "my hallway", models.DeviceKindButton, 1, ErrInvalidDevice},
+		{"bad kind", "doorbell", "doorbell", 1, ErrInvalidDevice},
+		{"unknown plant", "balcony", models.DeviceKindSensor, 42, ErrInvalidDevice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := service.Register(tt.id, "", tt.kind, tt.plantID, "admin@example.com"); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestDeviceService_Authenticate(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service := newTestDeviceService(store)
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	_, token, err := service.Register("kitchen", "Kitchen sensor", models.DeviceKindSensor, models.DefaultPlantID, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Failed to register device: %v", err)
+	}
+
+	if _, err := service.Authenticate("kitchen", models.DeviceKindButton, token); !errors.Is(err, ErrDeviceNotFound) {
+		t.Errorf("Expected a sensor not to authenticate as a button, got %v", err)
+	}
+	if _, err := service.Authenticate("kitchen", models.DeviceKindSensor, "wd_wrong"); !errors.Is(err, ErrInvalidDeviceToken) {
+		t.Errorf("Expected ErrInvalidDeviceToken, got %v", err)
+	}
+	device, err := service.Authenticate("kitchen", models.DeviceKindSensor, token)
+	if err != nil || device.PlantID != models.DefaultPlantID {
+		t.Fatalf("Expected the device to authenticate, got %+v (%v)", device, err)
+	}
+	if stored, _ := service.Get("kitchen"); stored.LastSeen == nil || !stored.LastSeen.Equal(now) {
+		t.Errorf("Expected last seen to be recorded, got %v", stored.LastSeen)
+	}
+
+	// Seen again moments later, the stored time is left alone
+	later := now
+	now = now.Add(10 * time.Second)
+	service.Authenticate("kitchen", models.DeviceKindSensor, token)
+	if stored, _ := service.Get("kitchen"); !stored.LastSeen.Equal(later) {
+		t.Errorf("Expected last seen to be written at most once a minute, got %v", stored.LastSeen)
+	}
+
+	inactive := false
+	if _, err := service.Update("kitchen", DeviceUpdate{Active: &inactive}, "admin@example.com"); err != nil {
+		t.Fatalf("Failed to deactivate device: %v", err)
+	}
+	if _, err := service.Authenticate("kitchen", models.DeviceKindSensor, token); !errors.Is(err, ErrDeviceInactive) {
+		t.Errorf("Expected ErrDeviceInactive, got %v", err)
+	}
+}
+
+func TestDeviceService_RotateToken(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service := newTestDeviceService(store)
+
+	_, oldToken, _ := service.Register("hallway", "Hallway button", models.DeviceKindButton, models.DefaultPlantID, "admin@example.com")
+	device, newToken, err := service.RotateToken("hallway", "admin@example.com")
+	if err != nil || newToken == oldToken || device.TokenHash != "" {
+		t.Fatalf("Expected a new token without the hash in the result, got %+v %q (%v)", device, newToken, err)
+	}
+	if _, err := service.Authenticate("hallway", models.DeviceKindButton, oldToken); !errors.Is(err, ErrInvalidDeviceToken) {
+		t.Errorf("Expected the old token to stop working, got %v", err)
+	}
+	if _, err := service.Authenticate("hallway", models.DeviceKindButton, newToken); err != nil {
+		t.Errorf("Expected the new token to work, got %v", err)
+	}
+	if _, _, err := service.RotateToken("missing", "admin@example.com"); !errors.Is(err, ErrDeviceNotFound) {
+		t.Errorf("Expected ErrDeviceNotFound, got %v", err)
+	}
+}
+
+func TestSensorService_RecordFromRegisteredDevice(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := NewPlantService(store)
+	devices := NewDeviceService(store, plantService, config.SensorConfig{})
+	service := NewSensorService(store, plantService, devices, config.SensorConfig{WateringCooldown: 6 * time.Hour})
+
+	if service.Enabled() {
+		t.Error("Expected sensors to be off with no devices")
+	}
+	_, token, _ := devices.Register("kitchen", "Kitchen sensor", models.DeviceKindSensor, models.DefaultPlantID, "admin@example.com")
+	if !service.Enabled() {
+		t.Error("Expected a registered sensor to turn sensors on")
+	}
+
+	moisture := 40.0
+	if err := service.Record("kitchen", token, &models.SensorReading{Moisture: &moisture}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := service.Record("kitchen", "wd_wrong", &models.SensorReading{Moisture: &moisture}); !errors.Is(err, ErrInvalidSensorToken) {
+		t.Errorf("Expected ErrInvalidSensorToken, got %v", err)
+	}
+	if err := service.Record("balcony", token, &models.SensorReading{Moisture: &moisture}); !errors.Is(err, ErrUnknownSensor) {
+		t.Errorf("Expected ErrUnknownSensor, got %v", err)
+	}
+	if readings, _ := service.Recent(models.DefaultPlantID, time.Hour); len(readings) != 1 || readings[0].DeviceID != "kitchen" {
+		t.Errorf("Expected one reading from the registered sensor, got %+v", readings)
+	}
+}
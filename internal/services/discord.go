@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/notify/discord"
+	"watered/internal/storage"
+)
+
+// ErrDiscordDisabled is returned when Discord is used without a webhook configured
+var ErrDiscordDisabled = errors.New("discord notifications are not configured")
+
+// Embed colors for each health status, matching the dashboard
+var discordStatusColors = map[models.PlantHealthStatus]int{
+	models.HealthStatusHealthy:    0x2ecc71,
+	models.HealthStatusNeedsWater: 0xf1c40f,
+	models.HealthStatusDue:        0xe67e22,
+	models.HealthStatusCritical:   0xe74c3c,
+	models.HealthStatusUnknown:    0x95a5a6,
+	models.HealthStatusPaused:     0x3498db,
+}
+
+// Embed labels for each health status
+var discordStatusLabels = map[models.PlantHealthStatus]string{
+	models.HealthStatusHealthy:    "Healthy",
+	models.HealthStatusNeedsWater: "Needs water",
+	models.HealthStatusDue:        "Due",
+	models.HealthStatusCritical:   "Critical",
+	models.HealthStatusUnknown:    "Unknown",
+	models.HealthStatusPaused:     "Paused",
+}
+
+// DiscordSender posts a single message to Discord
+type DiscordSender interface {
+	Send(ctx context.Context, msg discord.Message) error
+}
+
+// DiscordService posts rich embeds to a Discord channel when a plant becomes
+// overdue and when someone waters it
+type DiscordService struct {
+	storage storage.Storage
+	sender  DiscordSender
+}
+
+// NewDiscordService creates a Discord service. A nil sender disables Discord.
+func NewDiscordService(storage storage.Storage, sender DiscordSender) *DiscordService {
+	return &DiscordService{
+		storage: storage,
+		sender:  sender,
+	}
+}
+
+// Enabled reports whether a Discord webhook is configured
+func (s *DiscordService) Enabled() bool {
+	return s.sender != nil
+}
+
+// SendTest posts a test message so admins can verify the webhook
+func (s *DiscordService) SendTest(ctx context.Context) error {
+	if !s.Enabled() {
+		return ErrDiscordDisabled
+	}
+	return s.sender.Send(ctx, discord.Message{Content: "This is a test message from Watered. Your Discord webhook works!"})
+}
+
+// Trigger reports a Discord milestone once the plant is overdue or critical
+func (s *DiscordService) Trigger(plant *models.PlantState) models.NotificationTrigger {
+	return plant.GetNotificationTrigger()
+}
+
+// Notify posts that a plant is overdue and returns the number of deliveries
+func (s *DiscordService) Notify(ctx context.Context, plant *models.PlantState, trigger models.NotificationTrigger) int {
+	if !s.Enabled() {
+		return 0
+	}
+
+	title := fmt.Sprintf("%s is overdue for watering", plant.Name)
+	if trigger == models.NotificationTriggerCritical {
+		title = fmt.Sprintf("%s needs water now!", plant.Name)
+	}
+	since := "Never watered"
+	if plant.LastWatered != nil {
+		since = humanDuration(time.Since(*plant.LastWatered))
+	}
+
+	now := time.Now()
+	embed := s.embed(plant, title, "Since last watering", since, "Last watered by", &now)
+	if err := s.sender.Send(ctx, discord.Message{Embeds: []discord.Embed{embed}}); err != nil {
+		slog.Error("Failed to post Discord reminder", "plant_id", plant.ID, "trigger", trigger, "error", err)
+		return 0
+	}
+	slog.Info("Posted Discord reminder", "plant_id", plant.ID, "trigger", trigger)
+	return 1
+}
+
+// NotifyWatered posts who watered a plant and how long it had gone without
+// water
+func (s *DiscordService) NotifyWatered(ctx context.Context, plant *models.PlantState, previous *time.Time) {
+	if !s.Enabled() || plant.LastWatered == nil {
+		return
+	}
+
+	since := "First watering"
+	if previous != nil {
+		since = humanDuration(plant.LastWatered.Sub(*previous))
+	}
+
+	embed := s.embed(plant, fmt.Sprintf("%s was watered", plant.Name), "Went without water for", since, "Watered by", plant.LastWatered)
+	if err := s.sender.Send(ctx, discord.Message{Embeds: []discord.Embed{embed}}); err != nil {
+		slog.Error("Failed to post Discord watering message", "plant_id", plant.ID, "error", err)
+	}
+}
+
+// embed builds a card showing the plant's health status, colored to match,
+// how long it has gone without water and who watered it
+func (s *DiscordService) embed(plant *models.PlantState, title, sinceName, since, wateredByName string, timestamp *time.Time) discord.Embed {
+	status := plant.GetHealthStatus()
+	fields := []discord.Field{
+		{Name: "Status", Value: discordStatusLabels[status], Inline: true},
+		{Name: sinceName, Value: since, Inline: true},
+	}
+	if name := displayName(s.storage, plant.WateredBy); name != "" {
+		fields = append(fields, discord.Field{Name: wateredByName, Value: name, Inline: true})
+	}
+
+	return discord.Embed{
+		Title:     title,
+		Color:     discordStatusColors[status],
+		Fields:    fields,
+		Timestamp: timestamp,
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/notify/discord"
+	"watered/internal/storage"
+)
+
+// fakeDiscordSender records messages instead of posting to a webhook
+type fakeDiscordSender struct {
+	messages chan discord.Message
+	err      error
+}
+
+func newFakeDiscordSender() *fakeDiscordSender {
+	return &fakeDiscordSender{messages: make(chan discord.Message, 10)}
+}
+
+func (f *fakeDiscordSender) Send(ctx context.Context, msg discord.Message) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.messages <- msg
+	return nil
+}
+
+// next returns the single embed of the next message posted, failing if none
+// arrives
+func (f *fakeDiscordSender) next(t *testing.T) discord.Embed {
+	t.Helper()
+	select {
+	case msg := <-f.messages:
+		if len(msg.Embeds) != 1 {
+			t.Fatalf("Expected one embed, got %+v", msg)
+		}
+		return msg.Embeds[0]
+	case <-time.After(time.Second):
+		t.Fatal("Expected a Discord message")
+		return discord.Embed{}
+	}
+}
+
+// fieldValues maps embed field names to values
+func fieldValues(embed discord.Embed) map[string]string {
+	values := make(map[string]string, len(embed.Fields))
+	for _, field := range embed.Fields {
+		values[field.Name] = field.Value
+	}
+	return values
+}
+
+func TestDiscordService_Disabled(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewDiscordService(store, nil)
+	if service.Enabled() {
+		t.Error("Expected Discord to be disabled without a sender")
+	}
+	if err := service.SendTest(context.Background()); err != ErrDiscordDisabled {
+		t.Errorf("Expected ErrDiscordDisabled, got %v", err)
+	}
+	if delivered := service.Notify(context.Background(), &models.PlantState{Name: "Fern"}, models.NotificationTriggerDue); delivered != 0 {
+		t.Errorf("Expected no deliveries, got %d", delivered)
+	}
+}
+
+func TestDiscordService_Notify(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.CreateUser(&models.User{Email: "alice@example.com", Name: "Alice"})
+
+	sender := newFakeDiscordSender()
+	service := NewDiscordService(store, sender)
+
+	lastWatered := time.Now().Add(-30 * time.Hour)
+	plant := &models.PlantState{ID: 1, Name: "Fern", LastWatered: &lastWatered, WateredBy: "alice@example.com", TimeoutHours: 24, GracePeriodHours: 12}
+	if delivered := service.Notify(context.Background(), plant, models.NotificationTriggerDue); delivered != 1 {
+		t.Fatalf("Expected one delivery, got %d", delivered)
+	}
+	embed := sender.next(t)
+	fields := fieldValues(embed)
+	if embed.Title != "Fern is overdue for watering" || embed.Color != 0xe67e22 || embed.Timestamp == nil {
+		t.Errorf("Unexpected overdue embed: %+v", embed)
+	}
+	if fields["Status"] != "Due" || fields["Since last watering"] != "30 hours" || fields["Last watered by"] != "Alice" {
+		t.Errorf("Unexpected overdue fields: %v", fields)
+	}
+
+	service.Notify(context.Background(), &models.PlantState{ID: 2, Name: "Cactus"}, models.NotificationTriggerCritical)
+	embed = sender.next(t)
+	fields = fieldValues(embed)
+	if embed.Title != "Cactus needs water now!" || embed.Color != 0xe74c3c || fields["Since last watering"] != "Never watered" {
+		t.Errorf("Unexpected critical embed: %+v", embed)
+	}
+	if _, ok := fields["Last watered by"]; ok {
+		t.Errorf("Expected no waterer for a plant never watered, got %v", fields)
+	}
+
+	sender.err = errors.New("Unknown Webhook")
+	if delivered := service.Notify(context.Background(), plant, models.NotificationTriggerDue); delivered != 0 {
+		t.Errorf("Expected no deliveries when Discord fails, got %d", delivered)
+	}
+}
+
+func TestDiscordService_NotifyWatered(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	sender := newFakeDiscordSender()
+	discordService := NewDiscordService(store, sender)
+	plantService := NewPlantService(store)
+	plantService.AddWateringNotifier(discordService)
+
+	now := time.Now()
+	if _, err := plantService.WaterPlantByIDAt(1, "bob@example.com", now.Add(-5*time.Hour)); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+	embed := sender.next(t)
+	fields := fieldValues(embed)
+	if embed.Title != "Our Plant was watered" || fields["Went without water for"] != "First watering" || fields["Watered by"] != "bob@example.com" {
+		t.Errorf("Unexpected first watering embed: %+v", embed)
+	}
+
+	// In privacy mode the waterer is not named
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, PrivacyMode: true})
+	plantService.WaterPlantByIDAt(1, "bob@example.com", now)
+	embed = sender.next(t)
+	fields = fieldValues(embed)
+	if fields["Status"] != "Healthy" || embed.Color != 0x2ecc71 || fields["Went without water for"] != "5 hours" || fields["Watered by"] != "Someone" {
+		t.Errorf("Unexpected watering embed: %+v", embed)
+	}
+	if embed.Timestamp == nil || !embed.Timestamp.Equal(now) {
+		t.Errorf("Expected the watering time as the timestamp, got %v", embed.Timestamp)
+	}
+}
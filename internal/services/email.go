@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"watered/internal/models"
+	"watered/internal/notify/email"
+	"watered/internal/storage"
+)
+
+// EmailChannel is the notification channel name recorded for email deliveries
+const EmailChannel = "email"
+
+// ErrEmailDisabled is returned when email is used without SMTP configured
+var ErrEmailDisabled = errors.New("email notifications are not configured")
+
+// EmailSender delivers a single email message
+type EmailSender interface {
+	Send(msg email.Message) error
+}
+
+// EmailService emails watering reminders and digests to allowed users
+type EmailService struct {
+	storage             storage.Storage
+	sender              EmailSender
+	notificationService *NotificationService
+	snoozes             *SnoozeService
+}
+
+// NewEmailService creates a new email service. A nil sender disables email delivery.
+func NewEmailService(storage storage.Storage, sender EmailSender) *EmailService {
+	return &EmailService{
+		storage:             storage,
+		sender:              sender,
+		notificationService: NewNotificationService(storage),
+	}
+}
+
+// SetSnoozeService adds snooze links to reminders and skips users who
+// snoozed them
+func (s *EmailService) SetSnoozeService(snoozes *SnoozeService) {
+	s.snoozes = snoozes
+}
+
+// Enabled reports whether SMTP is configured
+func (s *EmailService) Enabled() bool {
+	return s.sender != nil
+}
+
+// Recipients returns the allowed users that receive reminders
+func (s *EmailService) Recipients() ([]string, error) {
+	config, err := s.storage.GetAdminConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin config: %w", err)
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(config.AllowedEmails))
+	recipients := make([]string, 0, len(config.AllowedEmails))
+	for _, address := range config.AllowedEmails {
+		address = strings.TrimSpace(strings.ToLower(address))
+		if address == "" || seen[address] {
+			continue
+		}
+		seen[address] = true
+		recipients = append(recipients, address)
+	}
+	return recipients, nil
+}
+
+// SendTest sends a test email so admins can verify the SMTP settings
+func (s *EmailService) SendTest(to string) error {
+	if !s.Enabled() {
+		return ErrEmailDisabled
+	}
+
+	to = strings.TrimSpace(strings.ToLower(to))
+	if to == "" {
+		return fmt.Errorf("recipient is required")
+	}
+
+	subject := "Watered test email"
+	err := s.sender.Send(email.Message{
+		To:      []string{to},
+		Subject: subject,
+		Body:    "This is a test email from Watered. Your SMTP settings work!",
+	})
+	s.record(to, models.NotificationTriggerTest, subject, err)
+	return err
+}
+
+// Trigger reports an email milestone once the plant is overdue or critical
+func (s *EmailService) Trigger(plant *models.PlantState) models.NotificationTrigger {
+	return plant.GetNotificationTrigger()
+}
+
+// Notify emails every allowed user that a plant is overdue
+func (s *EmailService) Notify(ctx context.Context, plant *models.PlantState, trigger models.NotificationTrigger) int {
+	return s.NotifyRecipient(ctx, plant, trigger, "")
+}
+
+// NotifyRecipient emails one user that a plant is overdue, or every allowed
+// user when recipient is empty
+func (s *EmailService) NotifyRecipient(ctx context.Context, plant *models.PlantState, trigger models.NotificationTrigger, recipient string) int {
+	subject := fmt.Sprintf("%s is overdue for watering", plant.Name)
+	body := fmt.Sprintf("%s is overdue for watering. Last watered: %s.\n\nPlease water it and tap the plant in Watered to reset the timer.",
+		plant.Name, lastWateredText(plant))
+	if trigger == models.NotificationTriggerCritical {
+		subject = fmt.Sprintf("%s needs water now!", plant.Name)
+		body = fmt.Sprintf("%s is past its grace period. Last watered: %s.\n\nPlease water it as soon as possible.",
+			plant.Name, lastWateredText(plant))
+	}
+
+	if !s.Enabled() {
+		return 0
+	}
+	recipients := []string{recipient}
+	if recipient == "" {
+		var err error
+		if recipients, err = s.Recipients(); err != nil {
+			slog.Error("Failed to get email recipients", "error", err)
+			return 0
+		}
+	}
+
+	// Each recipient gets their own snooze link, so reminders are sent one by one
+	delivered := 0
+	for _, to := range recipients {
+		if s.snoozes.Snoozed(plant.ID, to) {
+			slog.Debug("Skipping snoozed reminder", "plant_id", plant.ID, "to", to)
+			continue
+		}
+		message := body
+		if s.snoozes != nil {
+			message += fmt.Sprintf("\n\n%s: %s", s.snoozes.Label(), s.snoozes.Link(plant.ID, to, EmailChannel))
+		}
+		delivered += s.send([]string{to}, trigger, email.Message{Subject: subject, Body: message})
+	}
+	slog.Info("Sent reminder emails", "plant_id", plant.ID, "trigger", trigger, "delivered", delivered)
+	return delivered
+}
+
+// SendDigest emails every allowed user a summary of all plants
+func (s *EmailService) SendDigest(plants []*models.PlantState) int {
+	var body strings.Builder
+	body.WriteString("Here's how your plants are doing today:\n\n")
+	for _, plant := range plants {
+		fmt.Fprintf(&body, "- %s: %s (last watered: %s)\n",
+			plant.Name, strings.ReplaceAll(string(plant.GetHealthStatus()), "_", " "), lastWateredText(plant))
+	}
+
+	delivered := s.sendToRecipients(models.NotificationTriggerDigest, email.Message{Subject: "Your daily Watered digest", Body: body.String()})
+	slog.Info("Sent digest emails", "delivered", delivered)
+	return delivered
+}
+
+// sendToRecipients emails each allowed user separately so addresses are not
+// shared, recording every attempt in the notification history
+func (s *EmailService) sendToRecipients(trigger models.NotificationTrigger, msg email.Message) int {
+	if !s.Enabled() {
+		return 0
+	}
+
+	recipients, err := s.Recipients()
+	if err != nil {
+		slog.Error("Failed to get email recipients", "error", err)
+		return 0
+	}
+	return s.send(recipients, trigger, msg)
+}
+
+// send emails msg to each recipient separately, recording every attempt in
+// the notification history
+func (s *EmailService) send(recipients []string, trigger models.NotificationTrigger, msg email.Message) int {
+	if !s.Enabled() {
+		return 0
+	}
+
+	delivered := 0
+	for _, to := range recipients {
+		msg.To = []string{to}
+		err := s.sender.Send(msg)
+		if err != nil {
+			slog.Error("Failed to send email", "to", to, "error", err)
+		} else {
+			delivered++
+		}
+		s.record(to, trigger, msg.Subject, err)
+	}
+	return delivered
+}
+
+// record adds an email delivery attempt to the notification history
+func (s *EmailService) record(to string, trigger models.NotificationTrigger, subject string, deliveryErr error) {
+	if _, err := s.notificationService.Record(to, EmailChannel, trigger, subject, deliveryErr); err != nil {
+		slog.Warn("Failed to record email notification", "error", err)
+	}
+}
+
+// lastWateredText describes when a plant was last watered
+func lastWateredText(plant *models.PlantState) string {
+	if plant.LastWatered == nil {
+		return "never"
+	}
+	return plant.GetFormattedTimeSinceWatering()
+}
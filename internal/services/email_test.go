@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/notify/email"
+	"watered/internal/storage"
+)
+
+// fakeEmailSender records messages instead of contacting an SMTP server
+type fakeEmailSender struct {
+	messages []email.Message
+	fail     map[string]bool
+}
+
+func (f *fakeEmailSender) Send(msg email.Message) error {
+	if f.fail[msg.To[0]] {
+		return errors.New("mailbox unavailable")
+	}
+	f.messages = append(f.messages, msg)
+	return nil
+}
+
+func TestEmailService_Disabled(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewEmailService(store, nil)
+	if service.Enabled() {
+		t.Error("Expected email to be disabled without a sender")
+	}
+	if err := service.SendTest("test@example.com"); err != ErrEmailDisabled {
+		t.Errorf("Expected ErrEmailDisabled, got %v", err)
+	}
+	if delivered := service.SendDigest(nil); delivered != 0 {
+		t.Errorf("Expected no deliveries, got %d", delivered)
+	}
+}
+
+func TestEmailService_SendTest(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	sender := &fakeEmailSender{}
+	service := NewEmailService(store, sender)
+
+	if err := service.SendTest(""); err == nil {
+		t.Error("Expected error for missing recipient")
+	}
+	if err := service.SendTest("Admin@Example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(sender.messages) != 1 || sender.messages[0].To[0] != "admin@example.com" {
+		t.Fatalf("Expected one test email to admin@example.com, got %+v", sender.messages)
+	}
+
+	notifications, _ := store.ListNotifications(models.NotificationFilter{Channel: EmailChannel})
+	if len(notifications) != 1 || notifications[0].Trigger != models.NotificationTriggerTest {
+		t.Errorf("Expected test email in notification history, got %+v", notifications)
+	}
+}
+
+func TestEmailService_NotifyAllowedUsers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"a@example.com", "B@example.com", "b@example.com", "down@example.com"},
+	})
+
+	sender := &fakeEmailSender{fail: map[string]bool{"down@example.com": true}}
+	service := NewEmailService(store, sender)
+
+	wateredAt := time.Now().Add(-30 * time.Hour)
+	plant := &models.PlantState{ID: 1, Name: "Fern", TimeoutHours: 24, LastWatered: &wateredAt}
+
+	if trigger := service.Trigger(plant); trigger != models.NotificationTriggerCritical {
+		t.Fatalf("Expected critical trigger, got %s", trigger)
+	}
+
+	delivered := service.Notify(context.Background(), plant, models.NotificationTriggerCritical)
+	if delivered != 2 {
+		t.Errorf("Expected 2 deliveries, got %d", delivered)
+	}
+
+	// Every recipient gets a separate message
+	for _, msg := range sender.messages {
+		if len(msg.To) != 1 {
+			t.Errorf("Expected one recipient per message, got %v", msg.To)
+		}
+		if !strings.Contains(msg.Subject, "Fern") {
+			t.Errorf("Expected plant name in subject, got %q", msg.Subject)
+		}
+	}
+
+	failed, _ := store.ListNotifications(models.NotificationFilter{Status: models.NotificationStatusFailed})
+	if len(failed) != 1 || failed[0].UserEmail != "down@example.com" {
+		t.Errorf("Expected failed delivery to be recorded, got %+v", failed)
+	}
+}
+
+func TestEmailService_NotifyRecipient(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{AllowedEmails: []string{"a@example.com", "b@example.com"}})
+
+	sender := &fakeEmailSender{}
+	service := NewEmailService(store, sender)
+
+	wateredAt := time.Now().Add(-25 * time.Hour)
+	plant := &models.PlantState{ID: 1, Name: "Fern", TimeoutHours: 24, GracePeriodHours: 12, LastWatered: &wateredAt}
+
+	if delivered := service.NotifyRecipient(context.Background(), plant, models.NotificationTriggerDue, "backup@example.com"); delivered != 1 {
+		t.Errorf("Expected 1 delivery, got %d", delivered)
+	}
+	if len(sender.messages) != 1 || sender.messages[0].To[0] != "backup@example.com" {
+		t.Errorf("Expected only the recipient to be emailed, got %+v", sender.messages)
+	}
+}
+
+func TestEmailService_SendDigest(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, AllowedEmails: []string{"a@example.com"}})
+
+	sender := &fakeEmailSender{}
+	service := NewEmailService(store, sender)
+
+	wateredAt := time.Now().Add(-2 * time.Hour)
+	plants := []*models.PlantState{
+		{ID: 1, Name: "Fern", TimeoutHours: 24, LastWatered: &wateredAt},
+		{ID: 2, Name: "Cactus", TimeoutHours: 336},
+	}
+
+	if delivered := service.SendDigest(plants); delivered != 1 {
+		t.Fatalf("Expected 1 digest, got %d", delivered)
+	}
+
+	body := sender.messages[0].Body
+	if !strings.Contains(body, "Fern: healthy (last watered: 2 hours ago)") {
+		t.Errorf("Expected Fern in digest, got %q", body)
+	}
+	if !strings.Contains(body, "Cactus: critical (last watered: never)") {
+		t.Errorf("Expected Cactus in digest, got %q", body)
+	}
+}
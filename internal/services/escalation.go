@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+)
+
+// EscalationProvider delivers escalation steps on one notification channel
+type EscalationProvider interface {
+	// NotifyRecipient notifies one user, or everyone on the channel when
+	// recipient is empty, and returns the number of deliveries
+	NotifyRecipient(ctx context.Context, plant *models.PlantState, trigger models.NotificationTrigger, recipient string) int
+}
+
+// escalation tracks how far an overdue plant has moved along the chain
+type escalation struct {
+	dueAt time.Time
+	next  int
+}
+
+// EscalationScheduler walks overdue plants through an escalation chain,
+// for example the primary waterer, then a backup, then every user. Each step
+// is sent once its delay since the plant became overdue has passed, but only
+// within working hours when a calendar is configured. Watering the plant
+// ends its escalation.
+type EscalationScheduler struct {
+	plantService *PlantService
+	steps        []config.EscalationStep
+	workingHours *config.WorkingHours
+	providers    map[string]EscalationProvider
+	interval     time.Duration
+	now          func() time.Time
+
+	mu          sync.Mutex
+	initialized bool
+	escalations map[int]*escalation
+}
+
+// NewEscalationScheduler creates a scheduler for the configured chain.
+// providers maps channel names to the services that deliver them.
+func NewEscalationScheduler(plantService *PlantService, cfg config.EscalationConfig, interval time.Duration, providers map[string]EscalationProvider) *EscalationScheduler {
+	if interval <= 0 {
+		interval = DefaultNotificationCheckInterval
+	}
+
+	return &EscalationScheduler{
+		plantService: plantService,
+		steps:        cfg.Steps,
+		workingHours: cfg.WorkingHours,
+		providers:    providers,
+		interval:     interval,
+		now:          time.Now,
+		escalations:  make(map[int]*escalation),
+	}
+}
+
+// Interval returns how often CheckOnce should run
+func (s *EscalationScheduler) Interval() time.Duration {
+	return s.interval
+}
+
+// CheckOnce advances every overdue plant along the chain and returns the
+// number of deliveries. The first check skips steps whose delay has already
+// passed so a restart does not repeat them.
+func (s *EscalationScheduler) CheckOnce(ctx context.Context) int {
+	plants, err := s.plantService.ListPlants()
+	if err != nil {
+		slog.Error("Escalation scheduler failed to list plants", "error", err)
+		return 0
+	}
+
+	now := s.now()
+	working := s.workingHours == nil || s.workingHours.Contains(now.In(s.plantService.Location()))
+
+	type pendingStep struct {
+		plant *models.PlantState
+		step  config.EscalationStep
+	}
+	var pending []pendingStep
+
+	s.mu.Lock()
+	overdue := make(map[int]bool, len(plants))
+	for _, plant := range plants {
+		if plant.GetNotificationTrigger() == models.NotificationTriggerNone {
+			continue
+		}
+		overdue[plant.ID] = true
+
+		state, ok := s.escalations[plant.ID]
+		if !ok {
+			state = &escalation{dueAt: dueTime(plant, now)}
+			s.escalations[plant.ID] = state
+			if !s.initialized {
+				state.next = s.stepsReached(now.Sub(state.dueAt))
+			}
+		}
+
+		// Steps that come due outside working hours wait for the next working period
+		if !working {
+			continue
+		}
+		for ; state.next < s.stepsReached(now.Sub(state.dueAt)); state.next++ {
+			pending = append(pending, pendingStep{plant: plant, step: s.steps[state.next]})
+		}
+	}
+	for id := range s.escalations {
+		if !overdue[id] {
+			delete(s.escalations, id)
+		}
+	}
+	s.initialized = true
+	s.mu.Unlock()
+
+	delivered := 0
+	for _, p := range pending {
+		provider, ok := s.providers[p.step.Channel]
+		if !ok {
+			slog.Warn("Escalation step skipped, channel not configured", "step", p.step.String(), "channel", p.step.Channel)
+			continue
+		}
+		slog.Info("Plant still overdue, escalating", "plant_id", p.plant.ID, "step", p.step.String())
+		delivered += provider.NotifyRecipient(ctx, p.plant, p.plant.GetNotificationTrigger(), p.step.Recipient)
+	}
+	return delivered
+}
+
+// stepsReached returns how many steps are due once a plant has been overdue for elapsed
+func (s *EscalationScheduler) stepsReached(elapsed time.Duration) int {
+	count := 0
+	for _, step := range s.steps {
+		if elapsed < step.Delay {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// dueTime returns when the plant became overdue, or now for plants that
+// were never watered
+func dueTime(plant *models.PlantState, now time.Time) time.Time {
+	if plant.LastWatered == nil {
+		return now
+	}
+	return plant.LastWatered.Add(plant.DueAfter())
+}
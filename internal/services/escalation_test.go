@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+// fakeEscalationProvider records the recipients it was asked to notify
+type fakeEscalationProvider struct {
+	recipients []string
+}
+
+func (p *fakeEscalationProvider) NotifyRecipient(ctx context.Context, plant *models.PlantState, trigger models.NotificationTrigger, recipient string) int {
+	p.recipients = append(p.recipients, recipient)
+	return 1
+}
+
+func TestEscalationScheduler_CheckOnce(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	email := &fakeEscalationProvider{}
+	push := &fakeEscalationProvider{}
+	cfg := config.EscalationConfig{Steps: []config.EscalationStep{
+		{Channel: config.EscalationEmail, Recipient: "primary@example.com"},
+		{Delay: 2 * time.Hour, Channel: config.EscalationEmail, Recipient: "backup@example.com"},
+		{Delay: 4 * time.Hour, Channel: config.EscalationPush},
+	}}
+	scheduler := NewEscalationScheduler(NewPlantService(store), cfg, time.Minute, map[string]EscalationProvider{
+		config.EscalationEmail: email,
+		config.EscalationPush:  push,
+	})
+
+	// The plant became overdue an hour ago
+	wateredAt := time.Now().Add(-25 * time.Hour)
+	dueAt := wateredAt.Add(24 * time.Hour)
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Office Fern", TimeoutHours: 24, GracePeriodHours: 48, LastWatered: &wateredAt})
+
+	// The first check only skips steps that already passed before a restart
+	scheduler.now = func() time.Time { return dueAt.Add(time.Hour) }
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 0 {
+		t.Fatalf("Expected no escalation on first check, got %d", delivered)
+	}
+
+	// Backup is notified once two hours have passed
+	scheduler.now = func() time.Time { return dueAt.Add(2 * time.Hour) }
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 1 {
+		t.Errorf("Expected backup to be notified, got %d", delivered)
+	}
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 0 {
+		t.Errorf("Expected no repeat escalation, got %d", delivered)
+	}
+
+	// Then everyone on the push channel
+	scheduler.now = func() time.Time { return dueAt.Add(5 * time.Hour) }
+	scheduler.CheckOnce(context.Background())
+	if len(email.recipients) != 1 || email.recipients[0] != "backup@example.com" {
+		t.Errorf("Expected only the backup to be emailed, got %v", email.recipients)
+	}
+	if len(push.recipients) != 1 || push.recipients[0] != "" {
+		t.Errorf("Expected one push broadcast, got %v", push.recipients)
+	}
+
+	// Watering ends the escalation; the next overdue period starts at the primary
+	now := time.Now()
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Office Fern", TimeoutHours: 24, LastWatered: &now})
+	scheduler.CheckOnce(context.Background())
+
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Office Fern", TimeoutHours: 24, GracePeriodHours: 48, LastWatered: &wateredAt})
+	scheduler.now = func() time.Time { return dueAt.Add(time.Hour) }
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 1 {
+		t.Errorf("Expected primary to be notified when a new escalation starts, got %d", delivered)
+	}
+	if email.recipients[len(email.recipients)-1] != "primary@example.com" {
+		t.Errorf("Expected primary to be emailed, got %v", email.recipients)
+	}
+}
+
+func TestEscalationScheduler_WorkingHours(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{Timezone: "UTC"})
+
+	email := &fakeEscalationProvider{}
+	hours, err := config.ParseWorkingHours("Mon-Fri 09:00-17:00")
+	if err != nil {
+		t.Fatalf("Failed to parse working hours: %v", err)
+	}
+	cfg := config.EscalationConfig{
+		Steps:        []config.EscalationStep{{Channel: config.EscalationEmail, Recipient: "primary@example.com"}},
+		WorkingHours: hours,
+	}
+	scheduler := NewEscalationScheduler(NewPlantService(store), cfg, time.Minute, map[string]EscalationProvider{
+		config.EscalationEmail: email,
+	})
+
+	// Nothing overdue on the first check
+	now := time.Now()
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Office Fern", TimeoutHours: 24, LastWatered: &now})
+	scheduler.CheckOnce(context.Background())
+
+	// Overdue since Saturday morning: the step waits
+	saturday := time.Date(2024, 5, 11, 12, 0, 0, 0, time.UTC)
+	wateredAt := saturday.Add(-25 * time.Hour)
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Office Fern", TimeoutHours: 24, GracePeriodHours: 48, LastWatered: &wateredAt})
+
+	scheduler.now = func() time.Time { return saturday }
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 0 {
+		t.Errorf("Expected no escalation outside working hours, got %d", delivered)
+	}
+
+	// Monday morning: it is sent
+	scheduler.now = func() time.Time { return time.Date(2024, 5, 13, 9, 30, 0, 0, time.UTC) }
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 1 {
+		t.Errorf("Expected escalation once working hours resume, got %d", delivered)
+	}
+}
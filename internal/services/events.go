@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/privacy"
+)
+
+// PlantEventCheckInterval is how often plant status is checked for threshold
+// crossings to stream as live events
+const PlantEventCheckInterval = time.Minute
+
+// plantEventBuffer is how many events a slow subscriber may fall behind
+// before further events are dropped for it
+const plantEventBuffer = 16
+
+// PlantEventType names a live plant status change
+type PlantEventType string
+
+const (
+	PlantEventWatered    PlantEventType = "watered"
+	PlantEventNeedsWater PlantEventType = "needs_water"
+	PlantEventCritical   PlantEventType = "critical"
+)
+
+// Real-time message types published after plant and configuration changes
+const (
+	PlantUpdatedMessage  = "plant_updated"
+	PlantDeletedMessage  = "plant_deleted"
+	ConfigUpdatedMessage = "config_updated"
+)
+
+// Publisher receives state changes for connected real-time clients
+type Publisher interface {
+	Publish(messageType string, data interface{})
+}
+
+// PlantEvent is a plant status change streamed to connected clients
+type PlantEvent struct {
+	Type      PlantEventType       `json:"type"`
+	PlantID   int                  `json:"plant_id"`
+	Status    *PlantStatusResponse `json:"status"`
+	WateredBy string               `json:"watered_by,omitempty" mask:"member"`
+	Timestamp time.Time            `json:"timestamp"`
+}
+
+// PlantEvents fans plant events out to live subscribers
+type PlantEvents struct {
+	mu          sync.Mutex
+	subscribers map[chan PlantEvent]struct{}
+	closed      bool
+}
+
+// NewPlantEvents creates an event hub with no subscribers
+func NewPlantEvents() *PlantEvents {
+	return &PlantEvents{
+		subscribers: make(map[chan PlantEvent]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber. Call the returned function to
+// unsubscribe; the channel is closed afterwards, or as soon as the hub is
+// closed.
+func (e *PlantEvents) Subscribe() (<-chan PlantEvent, func()) {
+	ch := make(chan PlantEvent, plantEventBuffer)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	e.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if _, ok := e.subscribers[ch]; ok {
+			delete(e.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close closes every subscriber's channel and those of later subscribers,
+// so event streams end when the server shuts down instead of holding it
+// open until its deadline
+func (e *PlantEvents) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	for ch := range e.subscribers {
+		delete(e.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Publish sends an event to every subscriber without blocking and returns
+// the number of subscribers that received it
+func (e *PlantEvents) Publish(event PlantEvent) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delivered := 0
+	for ch := range e.subscribers {
+		select {
+		case ch <- event:
+			delivered++
+		default:
+		}
+	}
+	return delivered
+}
+
+// plantEventNotifier publishes threshold crossings found by a
+// NotificationScheduler as live events
+type plantEventNotifier struct {
+	plantService *PlantService
+}
+
+// EventNotifier returns a PlantNotifier that streams needs_water and critical
+// crossings to event subscribers
+func (s *PlantService) EventNotifier() PlantNotifier {
+	return plantEventNotifier{plantService: s}
+}
+
+// Trigger returns the milestone streamed for the plant's current state
+func (n plantEventNotifier) Trigger(plant *models.PlantState) models.NotificationTrigger {
+	switch plant.GetHealthStatus() {
+	case models.HealthStatusNeedsWater:
+		return models.NotificationTriggerNeedsWater
+	case models.HealthStatusCritical:
+		return models.NotificationTriggerCritical
+	default:
+		return models.NotificationTriggerNone
+	}
+}
+
+// Notify publishes the crossing to current subscribers
+func (n plantEventNotifier) Notify(ctx context.Context, plant *models.PlantState, trigger models.NotificationTrigger) int {
+	eventType := PlantEventNeedsWater
+	if trigger == models.NotificationTriggerCritical {
+		eventType = PlantEventCritical
+	}
+	return n.plantService.publish(eventType, plant)
+}
+
+// publish sends an event carrying the plant's current status
+func (s *PlantService) publish(eventType PlantEventType, plant *models.PlantState) int {
+	return s.events.Publish(PlantEvent{
+		Type:      eventType,
+		PlantID:   plant.ID,
+		Status:    s.statusResponse(plant),
+		WateredBy: plant.WateredBy,
+		Timestamp: time.Now(),
+	})
+}
+
+// WateringNotifier is told when a watering becomes a plant's latest one
+type WateringNotifier interface {
+	// NotifyWatered reports a watering. previous is when the plant was
+	// watered before, or nil if it never was.
+	NotifyWatered(ctx context.Context, plant *models.PlantState, previous *time.Time)
+}
+
+// AddWateringNotifier registers a notifier for new waterings. Notifiers run
+// in the background so a slow channel does not delay the response.
+func (s *PlantService) AddWateringNotifier(notifier WateringNotifier) {
+	s.wateringNotifiers = append(s.wateringNotifiers, notifier)
+}
+
+// SetNotificationsEnabled sets a check made before each watering is
+// notified, so notifications can be switched off without a restart
+func (s *PlantService) SetNotificationsEnabled(enabled func() bool) {
+	s.notificationsEnabled = enabled
+}
+
+// notifyWatered tells every watering notifier about a new watering
+func (s *PlantService) notifyWatered(plant *models.PlantState, previous *time.Time) {
+	if s.notificationsEnabled != nil && !s.notificationsEnabled() {
+		return
+	}
+	for _, notifier := range s.wateringNotifiers {
+		plantCopy := *plant
+		s.notifying.Add(1)
+		go func() {
+			defer s.notifying.Done()
+			notifier.NotifyWatered(context.Background(), &plantCopy, previous)
+		}()
+	}
+}
+
+// DrainNotifications waits until the watering notifications being sent in
+// the background are done, or ctx is. Stop recording waterings first.
+func (s *PlantService) DrainNotifications(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.notifying.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("watering notifications still sending: %w", ctx.Err())
+	}
+}
+
+// SetPublisher sets where plant changes are published after each successful
+// mutation
+func (s *PlantService) SetPublisher(publisher Publisher) {
+	s.publisher = publisher
+}
+
+// publishPlant sends a plant's new state to real-time clients. Clients are
+// not authenticated individually, so they only see what a viewer may see.
+func (s *PlantService) publishPlant(plant *models.PlantState) {
+	if s.publisher == nil {
+		return
+	}
+	s.publisher.Publish(PlantUpdatedMessage, privacy.Mask(plant, privacy.RoleViewer))
+}
+
+// publishPlantDeleted tells real-time clients a plant was removed
+func (s *PlantService) publishPlantDeleted(id int) {
+	if s.publisher == nil {
+		return
+	}
+	s.publisher.Publish(PlantDeletedMessage, map[string]interface{}{"id": id})
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func TestPlantEvents_PublishAndUnsubscribe(t *testing.T) {
+	events := NewPlantEvents()
+
+	first, unsubscribeFirst := events.Subscribe()
+	second, unsubscribeSecond := events.Subscribe()
+	defer unsubscribeSecond()
+
+	if delivered := events.Publish(PlantEvent{Type: PlantEventWatered, PlantID: 1}); delivered != 2 {
+		t.Errorf("Expected 2 deliveries, got %d", delivered)
+	}
+	if event := <-first; event.Type != PlantEventWatered {
+		t.Errorf("Expected watered event, got %s", event.Type)
+	}
+	<-second
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	if _, open := <-first; open {
+		t.Error("Expected channel to be closed after unsubscribing")
+	}
+	if delivered := events.Publish(PlantEvent{Type: PlantEventCritical, PlantID: 1}); delivered != 1 {
+		t.Errorf("Expected 1 delivery after unsubscribing, got %d", delivered)
+	}
+}
+
+func TestPlantEvents_SlowSubscriberDoesNotBlock(t *testing.T) {
+	events := NewPlantEvents()
+	_, unsubscribe := events.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < plantEventBuffer; i++ {
+		events.Publish(PlantEvent{Type: PlantEventWatered, PlantID: 1})
+	}
+	if delivered := events.Publish(PlantEvent{Type: PlantEventWatered, PlantID: 1}); delivered != 0 {
+		t.Errorf("Expected event to be dropped for a full subscriber, got %d deliveries", delivered)
+	}
+}
+
+func TestPlantEvents_Close(t *testing.T) {
+	events := NewPlantEvents()
+	subscribed, unsubscribe := events.Subscribe()
+
+	events.Close()
+	if _, open := <-subscribed; open {
+		t.Error("Expected subscribers' channels to be closed")
+	}
+	unsubscribe()
+
+	late, _ := events.Subscribe()
+	if _, open := <-late; open {
+		t.Error("Expected subscribing after close to return a closed channel")
+	}
+	if delivered := events.Publish(PlantEvent{Type: PlantEventWatered, PlantID: 1}); delivered != 0 {
+		t.Errorf("Expected no deliveries after close, got %d", delivered)
+	}
+}
+
+func TestPlantService_WaterPublishesEvent(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	plantService := NewPlantService(store)
+	events, unsubscribe := plantService.Events().Subscribe()
+	defer unsubscribe()
+
+	if _, err := plantService.WaterPlant("user@example.com"); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+
+	select {
+	case event := <-events:
+		if event.Type != PlantEventWatered || event.PlantID != models.DefaultPlantID {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+		if event.WateredBy != "user@example.com" {
+			t.Errorf("Expected watered_by to be set, got %q", event.WateredBy)
+		}
+		if event.Status == nil || event.Status.Status != models.HealthStatusHealthy {
+			t.Errorf("Expected healthy status, got %+v", event.Status)
+		}
+	default:
+		t.Fatal("Expected a watered event")
+	}
+}
+
+func TestPlantService_EventNotifierPublishesCrossings(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	plantService := NewPlantService(store)
+	scheduler := NewNotificationScheduler(plantService, time.Minute, plantService.EventNotifier())
+	events, unsubscribe := plantService.Events().Subscribe()
+	defer unsubscribe()
+
+	setLastWatered := func(hoursAgo float64) {
+		t.Helper()
+		wateredAt := time.Now().Add(-time.Duration(hoursAgo * float64(time.Hour)))
+		store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Fern", TimeoutHours: 24, LastWatered: &wateredAt})
+	}
+
+	setLastWatered(1)
+	scheduler.CheckOnce(context.Background())
+
+	setLastWatered(13)
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 1 {
+		t.Fatalf("Expected needs_water event, got %d deliveries", delivered)
+	}
+	if event := <-events; event.Type != PlantEventNeedsWater {
+		t.Errorf("Expected needs_water event, got %s", event.Type)
+	}
+
+	setLastWatered(30)
+	scheduler.CheckOnce(context.Background())
+	if event := <-events; event.Type != PlantEventCritical || event.Status.Status != models.HealthStatusCritical {
+		t.Errorf("Expected critical event, got %+v", event)
+	}
+}
+
+// fakePublisher records published real-time messages
+type fakePublisher struct {
+	messages []string
+	data     []interface{}
+}
+
+func (p *fakePublisher) Publish(messageType string, data interface{}) {
+	p.messages = append(p.messages, messageType)
+	p.data = append(p.data, data)
+}
+
+func TestPlantService_PublishesMutations(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	publisher := &fakePublisher{}
+	plantService := NewPlantService(store)
+	plantService.SetPublisher(publisher)
+
+	plant, err := plantService.CreatePlant("Cactus", 48)
+	if err != nil {
+		t.Fatalf("Failed to create plant: %v", err)
+	}
+	if _, err := plantService.WaterPlantByID(plant.ID, "user@example.com"); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+	if _, err := plantService.UpdatePlantSettingsByID(plant.ID, "Big Cactus", 0); err != nil {
+		t.Fatalf("Failed to update plant: %v", err)
+	}
+	if _, err := plantService.UpdateGracePeriodByID(plant.ID, 6); err != nil {
+		t.Fatalf("Failed to update grace period: %v", err)
+	}
+	if _, err := plantService.ResetPlantByID(plant.ID); err != nil {
+		t.Fatalf("Failed to reset plant: %v", err)
+	}
+	if err := plantService.DeletePlant(plant.ID); err != nil {
+		t.Fatalf("Failed to delete plant: %v", err)
+	}
+
+	// A failed mutation publishes nothing
+	if _, err := plantService.WaterPlantByID(plant.ID, "user@example.com"); err == nil {
+		t.Fatal("Expected watering a deleted plant to fail")
+	}
+
+	expected := []string{
+		PlantUpdatedMessage, PlantUpdatedMessage, PlantUpdatedMessage,
+		PlantUpdatedMessage, PlantUpdatedMessage, PlantDeletedMessage,
+	}
+	if len(publisher.messages) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, publisher.messages)
+	}
+	for i := range expected {
+		if publisher.messages[i] != expected[i] {
+			t.Errorf("Message %d: expected %s, got %s", i, expected[i], publisher.messages[i])
+		}
+	}
+
+	if watered := publisher.data[1].(*models.PlantState); watered.LastWatered == nil {
+		t.Error("Expected watering time in published state")
+	}
+}
+
+func TestPlantService_PublishMasksWaterer(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	publisher := &fakePublisher{}
+	plantService := NewPlantService(store)
+	plantService.SetPublisher(publisher)
+
+	plant, err := plantService.WaterPlant("user@example.com")
+	if err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+
+	published := publisher.data[0].(*models.PlantState)
+	if published.WateredBy != "" {
+		t.Errorf("Expected waterer hidden from real-time clients, got %q", published.WateredBy)
+	}
+	if plant.WateredBy != "user@example.com" {
+		t.Error("Masking must not modify the returned plant")
+	}
+}
+
+// blockingNotifier holds each watering notification until released
+type blockingNotifier struct {
+	release chan struct{}
+}
+
+func (n *blockingNotifier) NotifyWatered(ctx context.Context, plant *models.PlantState, previous *time.Time) {
+	<-n.release
+}
+
+func TestPlantService_DrainNotifications(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	notifier := &blockingNotifier{release: make(chan struct{})}
+	service := NewPlantService(store)
+	service.AddWateringNotifier(notifier)
+	if _, err := service.WaterPlantByIDAt(1, "alice@example.com", time.Now()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := service.DrainNotifications(ctx); err == nil {
+		t.Error("Expected an error while a notification is still sending")
+	}
+
+	close(notifier.release)
+	if err := service.DrainNotifications(context.Background()); err != nil {
+		t.Errorf("Expected the notification to finish, got %v", err)
+	}
+}
+
+func TestPlantService_NotificationsSwitchedOff(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	notifier := &blockingNotifier{release: make(chan struct{})}
+	defer close(notifier.release)
+	service := NewPlantService(store)
+	service.AddWateringNotifier(notifier)
+	service.SetNotificationsEnabled(func() bool { return false })
+	if _, err := service.WaterPlantByIDAt(1, "alice@example.com", time.Now()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := service.DrainNotifications(ctx); err != nil {
+		t.Errorf("Expected no notification to be sent, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/tracing"
+)
+
+// Kinds of activity in a plant's feed
+const (
+	FeedWatered  = "watered"
+	FeedReset    = "reset"
+	FeedSettings = "settings"
+)
+
+// MaxFeedEntries caps how many entries one feed request returns
+const MaxFeedEntries = 200
+
+// feedSettings lists the plant settings a settings entry describes, in the
+// order it describes them
+var feedSettings = []struct {
+	key   string
+	label string
+}{
+	{"name", "Name"},
+	{"timeout_hours", "Timeout (hours)"},
+	{"grace_period_hours", "Grace period (hours)"},
+	{"needs_water_percent", "Needs water at (%)"},
+	{"critical_percent", "Critical at (%)"},
+	{"outdoor", "Outdoor"},
+	{"metadata", "Custom fields"},
+}
+
+// FeedEntry is one thing that happened to a plant. Entries do not say who
+// did it, since feeds are read outside the household.
+type FeedEntry struct {
+	// ID is stable across requests, so feed readers can tell entries apart
+	ID        string
+	Kind      string
+	PlantID   int
+	PlantName string
+	At        time.Time
+	Title     string
+	Summary   string
+}
+
+// Feed returns what happened to a plant, or to all plants when plantID is 0,
+// newest first: waterings from the watering history, and resets and
+// settings changes from the audit log. Only entries after after are
+// returned, at most limit of them.
+func (s *PlantService) Feed(plantID int, after time.Time, limit int) ([]FeedEntry, error) {
+	s, span := s.trace("PlantService.Feed", tracing.Int("plant.id", plantID))
+	defer span.End()
+
+	if limit <= 0 || limit > MaxFeedEntries {
+		limit = MaxFeedEntries
+	}
+
+	plants, err := s.ListPlants()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(plants))
+	for _, plant := range plants {
+		names[plant.ID] = plant.Name
+	}
+	if _, ok := names[plantID]; plantID != 0 && !ok {
+		return nil, ErrPlantNotFound
+	}
+
+	var entries []FeedEntry
+	waterings, err := s.storage.ListWateringEvents(plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list waterings: %w", err)
+	}
+	for _, event := range waterings {
+		name, ok := names[event.PlantID]
+		if !ok || !event.WateredAt.After(after) {
+			continue
+		}
+		entries = append(entries, FeedEntry{
+			ID:        "watering-" + strconv.Itoa(event.ID),
+			Kind:      FeedWatered,
+			PlantID:   event.PlantID,
+			PlantName: name,
+			At:        event.WateredAt,
+			Title:     name + " was watered",
+		})
+	}
+
+	filter := models.AuditFilter{Since: after}
+	if plantID != 0 {
+		filter.Target = strconv.Itoa(plantID)
+	}
+	audits, err := s.storage.ListAuditEntries(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	for _, audit := range audits {
+		id, err := strconv.Atoi(audit.Target)
+		name, ok := names[id]
+		if err != nil || !ok || !audit.At.After(after) {
+			continue
+		}
+		entry := FeedEntry{ID: "audit-" + strconv.Itoa(audit.ID), PlantID: id, PlantName: name, At: audit.At}
+		switch audit.Action {
+		case models.AuditPlantReset:
+			entry.Kind = FeedReset
+			entry.Title = name + " was reset to unwatered"
+		case models.AuditPlantSettings:
+			entry.Kind = FeedSettings
+			entry.Title = name + "'s settings changed"
+			entry.Summary = describeSettingsChange(audit.Old, audit.New)
+		default:
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].At.After(entries[j].At) })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// describeSettingsChange lists the settings that differ between the old and
+// new settings recorded in an audit entry, such as "Timeout (hours): 24 → 48"
+func describeSettingsChange(oldJSON, newJSON json.RawMessage) string {
+	var before, after map[string]interface{}
+	json.Unmarshal(oldJSON, &before)
+	json.Unmarshal(newJSON, &after)
+
+	var changes []string
+	for _, setting := range feedSettings {
+		from, to := before[setting.key], after[setting.key]
+		fromJSON, _ := json.Marshal(from)
+		toJSON, _ := json.Marshal(to)
+		if string(fromJSON) == string(toJSON) {
+			continue
+		}
+		if setting.key == "metadata" {
+			changes = append(changes, setting.label+" changed")
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s: %v → %v", setting.label, from, to))
+	}
+	return strings.Join(changes, "; ")
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func TestPlantService_Feed(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service := NewPlantService(store)
+	audit := NewAuditService(store)
+
+	start := time.Now().Add(-time.Hour)
+	fern, err := service.CreatePlant("Fern", 48)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for i, id := range []int{models.DefaultPlantID, fern.ID, models.DefaultPlantID} {
+		if _, err := service.WaterPlantByIDAt(id, "alice@example.com", start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	audit.Record("admin@example.com", models.AuditPlantSettings, "2",
+		map[string]interface{}{"name": "Fern", "timeout_hours": 48, "outdoor": false},
+		map[string]interface{}{"name": "Fern", "timeout_hours": 72, "outdoor": true})
+	audit.Record("admin@example.com", models.AuditPlantReset, "1", nil, nil)
+	audit.Record("admin@example.com", models.AuditUserAdd, "bob@example.com", nil, nil)
+	audit.Record("admin@example.com", models.AuditPlantReset, "42", nil, nil)
+
+	entries, err := service.Feed(0, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	kinds := make([]string, len(entries))
+	for i, entry := range entries {
+		kinds[i] = entry.Kind
+		if strings.Contains(entry.Title+entry.Summary, "example.com") {
+			t.Errorf("Expected entries not to say who did something, got %+v", entry)
+		}
+	}
+	if strings.Join(kinds, ",") != "reset,settings,watered,watered,watered" {
+		t.Fatalf("Expected newest first with other audit actions and unknown plants left out, got %v", kinds)
+	}
+	if entries[1].Summary != "Timeout (hours): 48 → 72; Outdoor: false → true" || entries[1].PlantName != "Fern" {
+		t.Errorf("Unexpected settings entry: %+v", entries[1])
+	}
+
+	entries, err = service.Feed(fern.ID, time.Time{}, 0)
+	if err != nil || len(entries) != 2 || entries[0].Kind != FeedSettings || entries[1].ID != "watering-2" {
+		t.Errorf("Expected the fern's settings change and watering, got %+v (%v)", entries, err)
+	}
+
+	entries, err = service.Feed(0, start.Add(90*time.Second), 2)
+	if err != nil || len(entries) != 2 || entries[0].Kind != FeedReset {
+		t.Errorf("Expected the two newest entries after the cutoff, got %+v (%v)", entries, err)
+	}
+	entries, _ = service.Feed(models.DefaultPlantID, start.Add(90*time.Second), 10)
+	if len(entries) != 2 || !entries[1].At.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("Expected only plant 1's later watering and reset, got %+v", entries)
+	}
+
+	if _, err := service.Feed(42, time.Time{}, 10); !errors.Is(err, ErrPlantNotFound) {
+		t.Errorf("Expected ErrPlantNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/stats"
+	"watered/internal/tracing"
+)
+
+// MaxHistoryEntries caps how many waterings one history request returns
+const MaxHistoryEntries = 500
+
+// wateringsFor lists the waterings of a plant, or of all plants when
+// plantID is 0, oldest first
+func (s *PlantService) wateringsFor(plantID int) ([]*models.PlantWateringEvent, error) {
+	if plantID != 0 {
+		plant, err := s.storage.GetPlant(plantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get plant: %w", err)
+		}
+		if plant == nil {
+			return nil, ErrPlantNotFound
+		}
+	}
+	events, err := s.storage.ListWateringEvents(plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watering events: %w", err)
+	}
+	return events, nil
+}
+
+// WateringHistory returns the latest waterings of a plant, or of all plants
+// when plantID is 0, newest first and at most limit of them
+func (s *PlantService) WateringHistory(plantID, limit int) ([]*models.PlantWateringEvent, error) {
+	s, span := s.trace("PlantService.WateringHistory", tracing.Int("plant.id", plantID))
+	defer span.End()
+
+	if limit <= 0 || limit > MaxHistoryEntries {
+		limit = MaxHistoryEntries
+	}
+	events, err := s.wateringsFor(plantID)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]*models.PlantWateringEvent, 0, min(limit, len(events)))
+	for i := len(events) - 1; i >= 0 && len(history) < limit; i-- {
+		history = append(history, events[i])
+	}
+	return history, nil
+}
+
+// DailyWaterings counts a plant's waterings, or all plants' when plantID is
+// 0, on each of the last days days of the household timezone
+func (s *PlantService) DailyWaterings(plantID, days int) ([]stats.DailyCount, error) {
+	s, span := s.trace("PlantService.DailyWaterings", tracing.Int("plant.id", plantID))
+	defer span.End()
+
+	events, err := s.wateringsFor(plantID)
+	if err != nil {
+		return nil, err
+	}
+	return stats.Daily(events, days, s.Location(), time.Now()), nil
+}
+
+// WateringsByUser counts the waterings each user recorded for a plant, or
+// for all plants when plantID is 0, in the last days days of the household
+// timezone
+func (s *PlantService) WateringsByUser(plantID, days int) ([]stats.UserCount, error) {
+	s, span := s.trace("PlantService.WateringsByUser", tracing.Int("plant.id", plantID))
+	defer span.End()
+
+	events, err := s.wateringsFor(plantID)
+	if err != nil {
+		return nil, err
+	}
+	return stats.ByUser(events, days, s.Location(), time.Now()), nil
+}
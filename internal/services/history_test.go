@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func TestPlantService_WateringHistory(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service := NewPlantService(store)
+
+	now := time.Now()
+	fern, err := service.CreatePlant("Fern", 48)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	waterings := []struct {
+		plantID int
+		email   string
+		at      time.Time
+	}{
+		{models.DefaultPlantID, "alice@example.com", now.Add(-50 * time.Hour)},
+		{fern.ID, "bob@example.com", now.Add(-26 * time.Hour)},
+		{models.DefaultPlantID, "alice@example.com", now.Add(-time.Hour)},
+	}
+	for _, w := range waterings {
+		if _, err := service.WaterPlantByIDAt(w.plantID, w.email, w.at); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	history, err := service.WateringHistory(0, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(history) != 2 || history[0].PlantID != models.DefaultPlantID || history[1].PlantID != fern.ID {
+		t.Errorf("Expected the two latest waterings, newest first, got %+v", history)
+	}
+
+	daily, err := service.DailyWaterings(0, 7)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	total := 0
+	for _, day := range daily {
+		total += day.Waterings
+	}
+	if len(daily) != 7 || total != 3 {
+		t.Errorf("Expected 3 waterings over 7 days, got %+v", daily)
+	}
+	if daily[6].Date != now.In(service.Location()).Format("2006-01-02") {
+		t.Errorf("Expected the last day to be today, got %s", daily[6].Date)
+	}
+
+	users, err := service.WateringsByUser(models.DefaultPlantID, 30)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(users) != 1 || users[0].Email != "alice@example.com" || users[0].Waterings != 2 {
+		t.Errorf("Expected only alice's waterings of the default plant, got %+v", users)
+	}
+
+	if _, err := service.DailyWaterings(42, 7); !errors.Is(err, ErrPlantNotFound) {
+		t.Errorf("Expected ErrPlantNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+// IntegrityService checks stored data for consistency problems and can
+// repair the ones that have an unambiguous fix
+type IntegrityService struct {
+	storage storage.Storage
+	now     func() time.Time
+}
+
+// NewIntegrityService creates a new integrity service
+func NewIntegrityService(storage storage.Storage) *IntegrityService {
+	return &IntegrityService{
+		storage: storage,
+		now:     time.Now,
+	}
+}
+
+// Check runs all consistency checks. With repair set, repairable issues are
+// fixed and saved; the report marks which ones were repaired.
+func (s *IntegrityService) Check(repair bool) (*models.IntegrityReport, error) {
+	report := &models.IntegrityReport{
+		CheckedAt: s.now(),
+		Repair:    repair,
+		Issues:    []models.IntegrityIssue{},
+	}
+
+	config, err := s.storage.GetAdminConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin config: %w", err)
+	}
+
+	if err := s.checkConfig(report, config, repair); err != nil {
+		return nil, err
+	}
+	if err := s.checkPlants(report, repair); err != nil {
+		return nil, err
+	}
+	if err := s.checkWateringEvents(report, repair); err != nil {
+		return nil, err
+	}
+	if err := s.checkCareTasks(report, repair); err != nil {
+		return nil, err
+	}
+	if err := s.checkNotifications(report, config); err != nil {
+		return nil, err
+	}
+
+	for _, issue := range report.Issues {
+		status := "found"
+		if issue.Repaired {
+			status = "repaired"
+		}
+		slog.Warn("Integrity issue "+status, "severity", issue.Severity, "code", issue.Code, "message", issue.Message)
+	}
+	return report, nil
+}
+
+// addIssue records an issue, marking it repaired when a repair was attempted
+func addIssue(report *models.IntegrityReport, code string, severity models.IntegritySeverity, repairable bool, format string, args ...interface{}) {
+	report.Issues = append(report.Issues, models.IntegrityIssue{
+		Code:       code,
+		Severity:   severity,
+		Message:    fmt.Sprintf(format, args...),
+		Repairable: repairable,
+		Repaired:   repairable && report.Repair,
+	})
+}
+
+// checkConfig verifies admin configuration invariants: a positive timeout,
+// no duplicate emails, and every admin also being an allowed user
+func (s *IntegrityService) checkConfig(report *models.IntegrityReport, config *models.AdminConfig, repair bool) error {
+	if config == nil {
+		return nil
+	}
+
+	changed := false
+
+	if config.TimeoutHours <= 0 {
+		addIssue(report, "config_timeout_invalid", models.IntegritySeverityError, true,
+			"default timeout is %d hours; resetting to 24", config.TimeoutHours)
+		config.TimeoutHours = 24
+		changed = true
+	}
+
+	var duplicates []string
+	config.AllowedEmails, duplicates = dedupeEmails(config.AllowedEmails)
+	for _, email := range duplicates {
+		addIssue(report, "config_duplicate_allowed", models.IntegritySeverityWarning, true, "%s is listed more than once in allowed emails", email)
+		changed = true
+	}
+	config.AdminEmails, duplicates = dedupeEmails(config.AdminEmails)
+	for _, email := range duplicates {
+		addIssue(report, "config_duplicate_admin", models.IntegritySeverityWarning, true, "%s is listed more than once in admin emails", email)
+		changed = true
+	}
+	config.ViewerEmails, duplicates = dedupeEmails(config.ViewerEmails)
+	for _, email := range duplicates {
+		addIssue(report, "config_duplicate_viewer", models.IntegritySeverityWarning, true, "%s is listed more than once in viewer emails", email)
+		changed = true
+	}
+
+	allowed := make(map[string]bool, len(config.AllowedEmails))
+	for _, email := range config.AllowedEmails {
+		allowed[strings.ToLower(email)] = true
+	}
+	for _, email := range config.AdminEmails {
+		if !allowed[strings.ToLower(email)] {
+			addIssue(report, "config_admin_not_allowed", models.IntegritySeverityError, true, "admin %s is not in allowed emails", email)
+			config.AllowedEmails = append(config.AllowedEmails, email)
+			allowed[strings.ToLower(email)] = true
+			changed = true
+		}
+	}
+	for _, email := range config.ViewerEmails {
+		if !allowed[strings.ToLower(email)] {
+			addIssue(report, "config_viewer_not_allowed", models.IntegritySeverityError, true, "viewer %s is not in allowed emails", email)
+			config.AllowedEmails = append(config.AllowedEmails, email)
+			allowed[strings.ToLower(email)] = true
+			changed = true
+		}
+	}
+
+	if config.SetupCompleted && len(config.AdminEmails) == 0 {
+		addIssue(report, "config_no_admins", models.IntegritySeverityError, false,
+			"setup is complete but no admins are configured; start the server with -recovery")
+	}
+
+	if changed && repair {
+		if err := s.storage.UpdateAdminConfig(config); err != nil {
+			return fmt.Errorf("failed to save repaired config: %w", err)
+		}
+	}
+	return nil
+}
+
+// dedupeEmails removes case-insensitive duplicates, returning the duplicates found
+func dedupeEmails(emails []string) ([]string, []string) {
+	if emails == nil {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(emails))
+	unique := make([]string, 0, len(emails))
+	var duplicates []string
+	for _, email := range emails {
+		key := strings.ToLower(strings.TrimSpace(email))
+		if seen[key] {
+			duplicates = append(duplicates, email)
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, email)
+	}
+	return unique, duplicates
+}
+
+// checkPlants verifies each plant's settings and watering record.
+// Plants do not belong to households yet; a missing-household check goes here
+// once they do.
+func (s *IntegrityService) checkPlants(report *models.IntegrityReport, repair bool) error {
+	plants, err := s.storage.ListPlants()
+	if err != nil {
+		return fmt.Errorf("failed to list plants: %w", err)
+	}
+
+	now := s.now()
+	for _, plant := range plants {
+		changed := false
+
+		if err := plant.Validate(); err != nil {
+			addIssue(report, "plant_invalid", models.IntegritySeverityError, true, "plant %d: %v", plant.ID, err)
+			repairPlantSettings(plant)
+			changed = true
+		}
+
+		if plant.LastWatered != nil && plant.LastWatered.After(now) {
+			addIssue(report, "plant_watered_in_future", models.IntegritySeverityError, true,
+				"plant %d was last watered in the future (%s)", plant.ID, plant.LastWatered.Format(time.RFC3339))
+			plant.LastWatered = &now
+			changed = true
+		}
+
+		if plant.LastWatered == nil && plant.WateredBy != "" {
+			addIssue(report, "plant_waterer_without_watering", models.IntegritySeverityWarning, true,
+				"plant %d records a waterer but no watering time", plant.ID)
+			plant.WateredBy = ""
+			changed = true
+		}
+
+		if changed && repair {
+			plant.UpdatedAt = now
+			if err := s.storage.UpdatePlant(plant); err != nil {
+				return fmt.Errorf("failed to save repaired plant %d: %w", plant.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// repairPlantSettings resets out-of-range plant settings to safe defaults
+func repairPlantSettings(plant *models.PlantState) {
+	if plant.Name == "" {
+		plant.Name = fmt.Sprintf("Plant %d", plant.ID)
+	}
+	if plant.TimeoutHours <= 0 || plant.TimeoutHours > 8760 {
+		plant.TimeoutHours = 24
+	}
+	if plant.GracePeriodHours < 0 {
+		plant.GracePeriodHours = 0
+	}
+	if plant.GracePeriodHours > 8760 {
+		plant.GracePeriodHours = 8760
+	}
+	// With the fields above in range, only the health thresholds can still be invalid
+	if plant.Validate() != nil {
+		plant.NeedsWaterPercent, plant.CriticalPercent = 0, 0
+	}
+}
+
+// plantIDs returns the IDs of the stored plants
+func (s *IntegrityService) plantIDs() (map[int]bool, error) {
+	plants, err := s.storage.ListPlants()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plants: %w", err)
+	}
+	ids := make(map[int]bool, len(plants))
+	for _, plant := range plants {
+		ids[plant.ID] = true
+	}
+	return ids, nil
+}
+
+// checkWateringEvents finds waterings of plants that no longer exist, such
+// as those older versions left behind when a plant was deleted. Repair
+// deletes them.
+func (s *IntegrityService) checkWateringEvents(report *models.IntegrityReport, repair bool) error {
+	plants, err := s.plantIDs()
+	if err != nil {
+		return err
+	}
+	events, err := s.storage.ListWateringEvents(0)
+	if err != nil {
+		return fmt.Errorf("failed to list watering events: %w", err)
+	}
+
+	orphaned := make(map[int][]int)
+	for _, event := range events {
+		if !plants[event.PlantID] {
+			orphaned[event.PlantID] = append(orphaned[event.PlantID], event.ID)
+		}
+	}
+
+	plantIDs := make([]int, 0, len(orphaned))
+	for plantID := range orphaned {
+		plantIDs = append(plantIDs, plantID)
+	}
+	sort.Ints(plantIDs)
+	for _, plantID := range plantIDs {
+		eventIDs := orphaned[plantID]
+		addIssue(report, "watering_event_orphaned", models.IntegritySeverityWarning, true,
+			"%d watering events belong to deleted plant %d", len(eventIDs), plantID)
+		if !repair {
+			continue
+		}
+		for _, id := range eventIDs {
+			if err := s.storage.DeleteWateringEvent(id); err != nil {
+				return fmt.Errorf("failed to delete watering event %d: %w", id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkCareTasks finds care tasks of plants that no longer exist. Repair
+// deletes them along with their history.
+func (s *IntegrityService) checkCareTasks(report *models.IntegrityReport, repair bool) error {
+	plants, err := s.plantIDs()
+	if err != nil {
+		return err
+	}
+	tasks, err := s.storage.ListCareTasks(0)
+	if err != nil {
+		return fmt.Errorf("failed to list care tasks: %w", err)
+	}
+
+	for _, task := range tasks {
+		if plants[task.PlantID] {
+			continue
+		}
+		events, err := s.storage.ListCareTaskEvents(task.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list history of care task %d: %w", task.ID, err)
+		}
+		addIssue(report, "care_task_orphaned", models.IntegritySeverityWarning, true,
+			"care task %d (%s, %d completions) belongs to deleted plant %d", task.ID, task.Type, len(events), task.PlantID)
+		if repair {
+			if err := s.storage.DeleteCareTask(task.ID); err != nil {
+				return fmt.Errorf("failed to delete care task %d: %w", task.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkNotifications finds orphaned notification events whose recipient is
+// no longer known, and duplicate notification IDs
+func (s *IntegrityService) checkNotifications(report *models.IntegrityReport, config *models.AdminConfig) error {
+	notifications, err := s.storage.ListNotifications(models.NotificationFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	known := make(map[string]bool)
+	if config != nil {
+		for _, email := range config.AllowedEmails {
+			known[strings.ToLower(email)] = true
+		}
+		for _, email := range config.AdminEmails {
+			known[strings.ToLower(email)] = true
+		}
+	}
+
+	orphaned := make(map[string]int)
+	ids := make(map[int]bool, len(notifications))
+	for _, notification := range notifications {
+		if ids[notification.ID] {
+			addIssue(report, "notification_duplicate_id", models.IntegritySeverityError, false,
+				"notification ID %d is used more than once", notification.ID)
+		}
+		ids[notification.ID] = true
+
+		email := strings.ToLower(notification.UserEmail)
+		if known[email] {
+			continue
+		}
+		user, err := s.storage.GetUser(notification.UserEmail)
+		if err != nil {
+			return fmt.Errorf("failed to get user %s: %w", notification.UserEmail, err)
+		}
+		if user == nil {
+			orphaned[notification.UserEmail]++
+		} else {
+			known[email] = true
+		}
+	}
+
+	emails := make([]string, 0, len(orphaned))
+	for email := range orphaned {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+	for _, email := range emails {
+		count := orphaned[email]
+		addIssue(report, "notification_orphaned", models.IntegritySeverityWarning, false,
+			"%d notifications belong to unknown user %s; merge or re-add the user to keep them", count, email)
+	}
+	return nil
+}
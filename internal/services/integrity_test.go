@@ -0,0 +1,195 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func setupInconsistentStorage() *storage.MemoryStorage {
+	store := storage.NewMemoryStorage()
+	future := time.Now().Add(48 * time.Hour)
+
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  0,
+		AllowedEmails: []string{"user@example.com", "USER@example.com"},
+		AdminEmails:   []string{"admin@example.com"},
+		ViewerEmails:  []string{"sitter@example.com"},
+	})
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "", TimeoutHours: 24, LastWatered: &future, WateredBy: "user@example.com"})
+	store.CreatePlant(&models.PlantState{Name: "Cactus", TimeoutHours: 336, NeedsWaterPercent: 90, CriticalPercent: 80, WateredBy: "user@example.com"})
+	store.CreateNotification(&models.Notification{UserEmail: "gone@example.com", Channel: "email"})
+
+	return store
+}
+
+func issueCodes(report *models.IntegrityReport) map[string]bool {
+	codes := make(map[string]bool)
+	for _, issue := range report.Issues {
+		codes[issue.Code] = true
+	}
+	return codes
+}
+
+func TestIntegrityService_CheckFindsIssues(t *testing.T) {
+	store := setupInconsistentStorage()
+	service := NewIntegrityService(store)
+
+	report, err := service.Check(false)
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+
+	codes := issueCodes(report)
+	for _, code := range []string{
+		"config_timeout_invalid",
+		"config_duplicate_allowed",
+		"config_admin_not_allowed",
+		"config_viewer_not_allowed",
+		"plant_invalid",
+		"plant_watered_in_future",
+		"plant_waterer_without_watering",
+		"notification_orphaned",
+	} {
+		if !codes[code] {
+			t.Errorf("Expected issue %s, got %+v", code, report.Issues)
+		}
+	}
+
+	if report.OK() {
+		t.Error("Expected report to have unresolved issues")
+	}
+
+	// Check without repair must not modify anything
+	config, _ := store.GetAdminConfig()
+	if config.TimeoutHours != 0 || len(config.AllowedEmails) != 2 {
+		t.Errorf("Expected config untouched, got %+v", config)
+	}
+}
+
+func TestIntegrityService_Repair(t *testing.T) {
+	store := setupInconsistentStorage()
+	service := NewIntegrityService(store)
+
+	report, err := service.Check(true)
+	if err != nil {
+		t.Fatalf("Failed to repair: %v", err)
+	}
+
+	// Orphaned notifications are kept and reported, never deleted
+	unresolved := report.Unresolved()
+	if len(unresolved) != 1 || unresolved[0].Code != "notification_orphaned" {
+		t.Errorf("Expected only the orphaned notification to remain, got %+v", unresolved)
+	}
+
+	config, _ := store.GetAdminConfig()
+	if config.TimeoutHours != 24 {
+		t.Errorf("Expected timeout reset to 24, got %d", config.TimeoutHours)
+	}
+	if len(config.AllowedEmails) != 3 || config.AllowedEmails[1] != "admin@example.com" || config.AllowedEmails[2] != "sitter@example.com" {
+		t.Errorf("Expected deduped allowed list including admin and viewer, got %v", config.AllowedEmails)
+	}
+
+	plant, _ := store.GetPlantState()
+	if plant.Name != "Plant 1" || plant.LastWatered.After(time.Now()) {
+		t.Errorf("Expected repaired default plant, got %+v", plant)
+	}
+	cactus, _ := store.GetPlant(2)
+	if cactus.WateredBy != "" {
+		t.Errorf("Expected stray waterer cleared, got %q", cactus.WateredBy)
+	}
+	if cactus.NeedsWaterPercent != 0 || cactus.CriticalPercent != 0 {
+		t.Errorf("Expected inverted thresholds reset to defaults, got %d%% and %d%%", cactus.NeedsWaterPercent, cactus.CriticalPercent)
+	}
+
+	// A second pass finds only the unrepairable issue
+	report, _ = service.Check(false)
+	if len(report.Issues) != 1 {
+		t.Errorf("Expected 1 remaining issue after repair, got %+v", report.Issues)
+	}
+}
+
+func TestIntegrityService_OrphanedWateringEvents(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Our Plant", TimeoutHours: 24})
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 1, WateredAt: time.Now(), WateredBy: "user@example.com"})
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 7, WateredAt: time.Now(), WateredBy: "user@example.com"})
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 7, WateredAt: time.Now(), WateredBy: "user@example.com"})
+	service := NewIntegrityService(store)
+
+	report, err := service.Check(false)
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Code != "watering_event_orphaned" || !strings.Contains(report.Issues[0].Message, "2 watering events belong to deleted plant 7") {
+		t.Fatalf("Expected one orphaned watering issue for plant 7, got %+v", report.Issues)
+	}
+	if events, _ := store.ListWateringEvents(0); len(events) != 3 {
+		t.Errorf("Expected a check without repair to keep every watering, got %d", len(events))
+	}
+
+	if _, err := service.Check(true); err != nil {
+		t.Fatalf("Failed to repair: %v", err)
+	}
+	events, _ := store.ListWateringEvents(0)
+	if len(events) != 1 || events[0].PlantID != 1 {
+		t.Errorf("Expected only the existing plant's watering to remain, got %+v", events)
+	}
+	if report, _ := service.Check(false); len(report.Issues) != 0 {
+		t.Errorf("Expected no issues after repair, got %+v", report.Issues)
+	}
+}
+
+func TestIntegrityService_OrphanedCareTasks(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Our Plant", TimeoutHours: 24})
+	kept := &models.CareTask{PlantID: 1, Type: models.CareTaskMist, IntervalHours: 72}
+	store.SaveCareTask(kept)
+	orphan := &models.CareTask{PlantID: 7, Type: models.CareTaskFertilize, IntervalHours: 336}
+	store.SaveCareTask(orphan)
+	store.AddCareTaskEvent(&models.CareTaskEvent{TaskID: orphan.ID, PlantID: 7, Type: models.CareTaskFertilize, DoneAt: time.Now()})
+	service := NewIntegrityService(store)
+
+	report, err := service.Check(false)
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Code != "care_task_orphaned" || !strings.Contains(report.Issues[0].Message, "deleted plant 7") {
+		t.Fatalf("Expected one orphaned care task issue for plant 7, got %+v", report.Issues)
+	}
+	if task, _ := store.GetCareTask(orphan.ID); task == nil {
+		t.Error("Expected a check without repair to keep the care task")
+	}
+
+	if _, err := service.Check(true); err != nil {
+		t.Fatalf("Failed to repair: %v", err)
+	}
+	tasks, _ := store.ListCareTasks(0)
+	if len(tasks) != 1 || tasks[0].ID != kept.ID {
+		t.Errorf("Expected only the existing plant's care task to remain, got %+v", tasks)
+	}
+	if history, _ := store.ListCareTaskEvents(orphan.ID); len(history) != 0 {
+		t.Errorf("Expected the orphaned task's history to be deleted, got %+v", history)
+	}
+}
+
+func TestIntegrityService_CleanData(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"admin@example.com"},
+		AdminEmails:   []string{"admin@example.com"},
+	})
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Our Plant", TimeoutHours: 24})
+
+	report, err := NewIntegrityService(store).Check(false)
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if !report.OK() || len(report.Issues) != 0 {
+		t.Errorf("Expected no issues, got %+v", report.Issues)
+	}
+}
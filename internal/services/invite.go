@@ -0,0 +1,258 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/privacy"
+	"watered/internal/randtoken"
+	"watered/internal/storage"
+)
+
+const (
+	// DefaultInviteTTL is how long an invite link stays valid unless the
+	// admin asks for something else
+	DefaultInviteTTL = 7 * 24 * time.Hour
+	// MaxInviteTTL caps how long an invite link may stay valid
+	MaxInviteTTL = 30 * 24 * time.Hour
+	// maxInviteNameLength caps the label shown in the admin list and logs
+	maxInviteNameLength = 64
+)
+
+var (
+	// ErrInviteNotFound is returned for an ID no pending invite exists under
+	ErrInviteNotFound = errors.New("invite not found")
+	// ErrInvalidInvite is returned for a malformed name, role or lifetime
+	ErrInvalidInvite = errors.New("invalid invite")
+	// ErrInvalidInviteToken is returned for invite links that are malformed,
+	// tampered with, expired, revoked or already used
+	ErrInvalidInviteToken = errors.New("invalid or expired invite link")
+)
+
+// inviteClaims is the signed content of an invite token
+type inviteClaims struct {
+	ID        string `json:"i"`
+	ExpiresAt int64  `json:"x"`
+}
+
+// InviteService issues signed invite links and adds whoever accepts one to
+// the allowlist. The token only names the invite, so revoking or accepting
+// the stored invite stops its link working before it expires.
+type InviteService struct {
+	storage storage.Storage
+	key     []byte
+	baseURL string
+	now     func() time.Time
+
+	// acceptMu makes accepting an invite and using it up one step, so a
+	// link can't let two accounts in
+	acceptMu sync.Mutex
+}
+
+// NewInviteService creates an invite service. key signs the links and
+// baseURL is the server's public address.
+func NewInviteService(storage storage.Storage, key []byte, baseURL string) *InviteService {
+	return &InviteService{
+		storage: storage,
+		key:     key,
+		baseURL: baseURL,
+		now:     time.Now,
+	}
+}
+
+// Create issues an invite for someone to join with role, member or viewer,
+// valid for ttl. It returns the stored invite and the signed link, which is
+// not retrievable afterwards.
+func (s *InviteService) Create(name, role string, ttl time.Duration, createdBy string) (*models.Invite, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxInviteNameLength {
+		return nil, "", fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidInvite, maxInviteNameLength)
+	}
+	if role == "" {
+		role = privacy.RoleMember.String()
+	}
+	if role != privacy.RoleMember.String() && role != privacy.RoleViewer.String() {
+		return nil, "", fmt.Errorf("%w: role must be member or viewer", ErrInvalidInvite)
+	}
+	if ttl == 0 {
+		ttl = DefaultInviteTTL
+	}
+	if ttl < time.Hour || ttl > MaxInviteTTL {
+		return nil, "", fmt.Errorf("%w: invites must last between 1 and %d hours", ErrInvalidInvite, int(MaxInviteTTL.Hours()))
+	}
+
+	id, err := randtoken.String(8, hex.EncodeToString)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate invite ID: %w", err)
+	}
+	now := s.now()
+	invite := &models.Invite{
+		ID:        id,
+		Name:      name,
+		Role:      role,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := s.storage.SaveInvite(invite); err != nil {
+		return nil, "", fmt.Errorf("failed to store invite: %w", err)
+	}
+	s.pruneExpired()
+
+	slog.Info("Invite created", "audit", true, "invite_id", id, "name", name, "role", role, "expires_at", invite.ExpiresAt, "by", createdBy)
+	return invite, s.baseURL + "/invite/" + s.token(invite), nil
+}
+
+// List returns the invites that can still be accepted, oldest first
+func (s *InviteService) List() ([]*models.Invite, error) {
+	invites, err := s.storage.ListInvites()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	return slices.DeleteFunc(invites, func(invite *models.Invite) bool { return invite.Expired(now) }), nil
+}
+
+// Revoke deletes a pending invite so its link stops working, returning the
+// revoked invite
+func (s *InviteService) Revoke(id, revokedBy string) (*models.Invite, error) {
+	invite, err := s.storage.GetInvite(id)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil || invite.Expired(s.now()) {
+		return nil, ErrInviteNotFound
+	}
+	if err := s.storage.DeleteInvite(id); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Invite revoked", "audit", true, "invite_id", id, "name", invite.Name, "by", revokedBy)
+	return invite, nil
+}
+
+// Verify returns the pending invite a token names
+func (s *InviteService) Verify(token string) (*models.Invite, error) {
+	claims, err := s.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	invite, err := s.storage.GetInvite(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil || invite.Expired(s.now()) {
+		return nil, ErrInvalidInviteToken
+	}
+	return invite, nil
+}
+
+// Accept adds email to the allowlist with the role of the invite a token
+// names, and uses the invite up. Someone already on the allowlist keeps
+// their access unchanged.
+func (s *InviteService) Accept(token, email string) (*models.Invite, error) {
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == "" {
+		return nil, ErrInvalidInviteToken
+	}
+
+	s.acceptMu.Lock()
+	defer s.acceptMu.Unlock()
+
+	invite, err := s.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.storage.GetAdminConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin config: %w", err)
+	}
+	if config == nil {
+		config = &models.AdminConfig{TimeoutHours: 24}
+	}
+	if !slices.Contains(config.AllowedEmails, email) {
+		config.AllowedEmails = append(config.AllowedEmails, email)
+		if invite.Role == privacy.RoleViewer.String() {
+			config.ViewerEmails = append(config.ViewerEmails, email)
+		}
+		if err := s.storage.UpdateAdminConfig(config); err != nil {
+			return nil, fmt.Errorf("failed to update admin config: %w", err)
+		}
+	}
+	if err := s.storage.DeleteInvite(invite.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete invite: %w", err)
+	}
+
+	slog.Info("Invite accepted", "audit", true, "invite_id", invite.ID, "name", invite.Name, "role", invite.Role, "email", email)
+	return invite, nil
+}
+
+// pruneExpired deletes invites that can no longer be accepted. Failures are
+// logged since expired invites are ignored anyway.
+func (s *InviteService) pruneExpired() {
+	invites, err := s.storage.ListInvites()
+	if err != nil {
+		slog.Warn("Failed to list invites for pruning", "error", err)
+		return
+	}
+	now := s.now()
+	for _, invite := range invites {
+		if !invite.Expired(now) {
+			continue
+		}
+		if err := s.storage.DeleteInvite(invite.ID); err != nil {
+			slog.Warn("Failed to delete expired invite", "invite_id", invite.ID, "error", err)
+		}
+	}
+}
+
+// token returns the signed token naming an invite, valid until it expires
+func (s *InviteService) token(invite *models.Invite) string {
+	payload, _ := json.Marshal(inviteClaims{ID: invite.ID, ExpiresAt: invite.ExpiresAt.Unix()})
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded))
+}
+
+// verify checks a token's signature and expiry and returns its claims
+func (s *InviteService) verify(token string) (*inviteClaims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidInviteToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign(encoded)) {
+		return nil, ErrInvalidInviteToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidInviteToken
+	}
+	var claims inviteClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ID == "" {
+		return nil, ErrInvalidInviteToken
+	}
+	if s.now().Unix() >= claims.ExpiresAt {
+		return nil, ErrInvalidInviteToken
+	}
+	return &claims, nil
+}
+
+// sign returns the MAC of an encoded payload
+func (s *InviteService) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte("invite:" + encoded))
+	return mac.Sum(nil)
+}
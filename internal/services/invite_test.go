@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func newTestInviteService(t *testing.T) (*InviteService, storage.Storage, *time.Time) {
+	store := storage.NewMemoryStorage()
+	t.Cleanup(func() { store.Close() })
+	service := NewInviteService(store, []byte("test-key"), "https://plant.example.com")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	return service, store, &now
+}
+
+func TestInviteService_CreateAndAccept(t *testing.T) {
+	service, store, _ := newTestInviteService(t)
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, AllowedEmails: []string{"alice@example.com"}})
+
+	invite, link, err := service.Create("  Sitter  ", "viewer", 0, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if invite.Name != "Sitter" || invite.Role != "viewer" || !invite.ExpiresAt.Equal(invite.CreatedAt.Add(DefaultInviteTTL)) {
+		t.Errorf("Expected a week-long viewer invite for a trimmed name, got %+v", invite)
+	}
+	token, ok := strings.CutPrefix(link, "https://plant.example.com/invite/")
+	if !ok {
+		t.Fatalf("Expected an absolute invite link, got %q", link)
+	}
+	if invites, _ := service.List(); len(invites) != 1 || invites[0].ID != invite.ID {
+		t.Errorf("Expected the invite to be pending, got %+v", invites)
+	}
+
+	if _, err := service.Accept(token, " Bob@Example.com "); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	config, _ := store.GetAdminConfig()
+	if len(config.AllowedEmails) != 2 || config.AllowedEmails[1] != "bob@example.com" || len(config.ViewerEmails) != 1 || config.ViewerEmails[0] != "bob@example.com" {
+		t.Errorf("Expected bob to be allowed as a viewer, got %+v", config)
+	}
+
+	// The invite is used up by the first sign-in
+	if invites, _ := service.List(); len(invites) != 0 {
+		t.Errorf("Expected no pending invites, got %+v", invites)
+	}
+	if _, err := service.Accept(token, "carol@example.com"); !errors.Is(err, ErrInvalidInviteToken) {
+		t.Errorf("Expected ErrInvalidInviteToken for a used invite, got %v", err)
+	}
+}
+
+func TestInviteService_AcceptKeepsExistingAccess(t *testing.T) {
+	service, store, _ := newTestInviteService(t)
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, AllowedEmails: []string{"alice@example.com"}})
+
+	_, link, _ := service.Create("Alice", "viewer", 0, "admin@example.com")
+	if _, err := service.Accept(link[strings.LastIndex(link, "/")+1:], "alice@example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config, _ := store.GetAdminConfig(); len(config.AllowedEmails) != 1 || len(config.ViewerEmails) != 0 {
+		t.Errorf("Expected alice to stay a member, got %+v", config)
+	}
+}
+
+func TestInviteService_InvalidTokens(t *testing.T) {
+	service, _, now := newTestInviteService(t)
+
+	revoked, revokedLink, _ := service.Create("Neighbour", "", 0, "admin@example.com")
+	if revoked.Role != "member" {
+		t.Errorf("Expected invites to default to members, got %q", revoked.Role)
+	}
+	if _, err := service.Revoke(revoked.ID, "admin@example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.Revoke(revoked.ID, "admin@example.com"); !errors.Is(err, ErrInviteNotFound) {
+		t.Errorf("Expected ErrInviteNotFound revoking twice, got %v", err)
+	}
+
+	_, link, _ := service.Create("Alex", "member", 2*time.Hour, "admin@example.com")
+	token := link[strings.LastIndex(link, "/")+1:]
+	other := NewInviteService(storage.NewMemoryStorage(), []byte("other-key"), "")
+	_, forged, _ := other.Create("Alex", "member", 2*time.Hour, "admin@example.com")
+
+	for name, bad := range map[string]string{
+		"empty":       "",
+		"unsigned":    strings.Split(token, ".")[0],
+		"tampered":    token + "x",
+		"wrong key":   strings.TrimPrefix(forged, "/invite/"),
+		"revoked":     revokedLink[strings.LastIndex(revokedLink, "/")+1:],
+		"not encoded": "not.a-token",
+	} {
+		if _, err := service.Verify(bad); !errors.Is(err, ErrInvalidInviteToken) {
+			t.Errorf("%s: expected ErrInvalidInviteToken, got %v", name, err)
+		}
+	}
+
+	if _, err := service.Verify(token); err != nil {
+		t.Errorf("Expected the pending invite to verify, got %v", err)
+	}
+	*now = now.Add(2 * time.Hour)
+	if _, err := service.Verify(token); !errors.Is(err, ErrInvalidInviteToken) {
+		t.Errorf("Expected ErrInvalidInviteToken for an expired invite, got %v", err)
+	}
+	if invites, _ := service.List(); len(invites) != 0 {
+		t.Errorf("Expected expired invites to be hidden, got %+v", invites)
+	}
+
+	tests := []struct {
+		name     string
+		invitee  string
+		role     string
+		lifetime time.Duration
+	}{
+		{"blank name", "  ", "member", 0},
+		{"long name", strings.Repeat("x", maxInviteNameLength+1), "member", 0},
+		{"admin role", "Alex", "admin", 0},
+		{"too short", "Alex", "member", time.Minute},
+		{"too long", "Alex", "member", MaxInviteTTL + time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := service.Create(tt.invitee, tt.role, tt.lifetime, "admin@example.com"); !errors.Is(err, ErrInvalidInvite) {
+				t.Errorf("Expected ErrInvalidInvite, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+// NotificationService records delivered notifications and answers history queries
+type NotificationService struct {
+	storage storage.Storage
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(storage storage.Storage) *NotificationService {
+	return &NotificationService{
+		storage: storage,
+	}
+}
+
+// Record stores the outcome of a notification delivery attempt. A nil
+// deliveryErr marks the notification as delivered.
+func (s *NotificationService) Record(userEmail, channel string, trigger models.NotificationTrigger, summary string, deliveryErr error) (*models.Notification, error) {
+	if userEmail == "" {
+		return nil, fmt.Errorf("user email is required")
+	}
+	if channel == "" {
+		return nil, fmt.Errorf("channel is required")
+	}
+
+	notification := &models.Notification{
+		UserEmail: userEmail,
+		Channel:   channel,
+		Trigger:   trigger,
+		Status:    models.NotificationStatusDelivered,
+		Summary:   summary,
+		CreatedAt: time.Now(),
+	}
+	if deliveryErr != nil {
+		notification.Status = models.NotificationStatusFailed
+		notification.Error = deliveryErr.Error()
+	}
+
+	if err := s.storage.CreateNotification(notification); err != nil {
+		return nil, fmt.Errorf("failed to record notification: %w", err)
+	}
+
+	return notification, nil
+}
+
+// List returns notification history matching the filter, newest first
+func (s *NotificationService) List(filter models.NotificationFilter) ([]*models.Notification, error) {
+	notifications, err := s.storage.ListNotifications(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	return notifications, nil
+}
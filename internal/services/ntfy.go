@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/notify/ntfy"
+	"watered/internal/storage"
+)
+
+// ErrNtfyDisabled is returned when ntfy is used without a topic configured
+var ErrNtfyDisabled = errors.New("ntfy notifications are not configured")
+
+// NtfySender publishes a single message to an ntfy topic
+type NtfySender interface {
+	Publish(ctx context.Context, msg ntfy.Message) error
+}
+
+// NtfyService publishes phone notifications to an ntfy topic when a plant
+// becomes overdue and when someone waters it. Everyone subscribed to the
+// topic gets them, without Apple or Google push.
+type NtfyService struct {
+	storage  storage.Storage
+	sender   NtfySender
+	clickURL string
+}
+
+// NewNtfyService creates an ntfy service. Tapping a notification opens
+// clickURL, usually the dashboard. A nil sender disables ntfy.
+func NewNtfyService(storage storage.Storage, sender NtfySender, clickURL string) *NtfyService {
+	return &NtfyService{
+		storage:  storage,
+		sender:   sender,
+		clickURL: clickURL,
+	}
+}
+
+// Enabled reports whether an ntfy topic is configured
+func (s *NtfyService) Enabled() bool {
+	return s.sender != nil
+}
+
+// SendTest publishes a test message so admins can verify the topic
+func (s *NtfyService) SendTest(ctx context.Context) error {
+	if !s.Enabled() {
+		return ErrNtfyDisabled
+	}
+	return s.sender.Publish(ctx, ntfy.Message{
+		Title:   "Watered",
+		Message: "This is a test message from Watered. Your ntfy topic works!",
+		Tags:    []string{"potted_plant"},
+		Click:   s.clickURL,
+	})
+}
+
+// Trigger reports an ntfy milestone once the plant is overdue or critical
+func (s *NtfyService) Trigger(plant *models.PlantState) models.NotificationTrigger {
+	return plant.GetNotificationTrigger()
+}
+
+// Notify publishes that a plant is overdue and returns the number of
+// deliveries. Critical plants are sent at urgent priority, which ntfy apps
+// let through Do Not Disturb.
+func (s *NtfyService) Notify(ctx context.Context, plant *models.PlantState, trigger models.NotificationTrigger) int {
+	if !s.Enabled() {
+		return 0
+	}
+
+	msg := ntfy.Message{
+		Title:    fmt.Sprintf("%s is overdue for watering", plant.Name),
+		Priority: ntfy.PriorityHigh,
+		Tags:     []string{"droplet"},
+		Click:    s.clickURL,
+	}
+	if trigger == models.NotificationTriggerCritical {
+		msg.Title = fmt.Sprintf("%s needs water now!", plant.Name)
+		msg.Priority = ntfy.PriorityUrgent
+		msg.Tags = []string{"wilted_flower"}
+	}
+	if plant.LastWatered == nil {
+		msg.Message = "It has never been watered."
+	} else {
+		msg.Message = fmt.Sprintf("It was last watered %s ago", humanDuration(time.Since(*plant.LastWatered)))
+		if name := displayName(s.storage, plant.WateredBy); name != "" {
+			msg.Message += " by " + name
+		}
+		msg.Message += "."
+	}
+
+	if err := s.sender.Publish(ctx, msg); err != nil {
+		slog.Error("Failed to publish ntfy reminder", "plant_id", plant.ID, "trigger", trigger, "error", err)
+		return 0
+	}
+	slog.Info("Published ntfy reminder", "plant_id", plant.ID, "trigger", trigger)
+	return 1
+}
+
+// NotifyWatered quietly publishes who watered a plant and how long it had
+// gone without water
+func (s *NtfyService) NotifyWatered(ctx context.Context, plant *models.PlantState, previous *time.Time) {
+	if !s.Enabled() || plant.LastWatered == nil {
+		return
+	}
+
+	text := fmt.Sprintf("%s watered it", displayName(s.storage, plant.WateredBy))
+	if previous != nil {
+		text += " after " + humanDuration(plant.LastWatered.Sub(*previous))
+	}
+	msg := ntfy.Message{
+		Title:    fmt.Sprintf("%s was watered", plant.Name),
+		Message:  text + ".",
+		Priority: ntfy.PriorityLow,
+		Tags:     []string{"potted_plant"},
+		Click:    s.clickURL,
+	}
+	if err := s.sender.Publish(ctx, msg); err != nil {
+		slog.Error("Failed to publish ntfy watering message", "plant_id", plant.ID, "error", err)
+	}
+}
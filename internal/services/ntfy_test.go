@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/notify/ntfy"
+	"watered/internal/storage"
+)
+
+// fakeNtfySender records messages instead of publishing them
+type fakeNtfySender struct {
+	messages chan ntfy.Message
+	err      error
+}
+
+func newFakeNtfySender() *fakeNtfySender {
+	return &fakeNtfySender{messages: make(chan ntfy.Message, 10)}
+}
+
+func (f *fakeNtfySender) Publish(ctx context.Context, msg ntfy.Message) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.messages <- msg
+	return nil
+}
+
+// next returns the next message published, failing if none arrives
+func (f *fakeNtfySender) next(t *testing.T) ntfy.Message {
+	t.Helper()
+	select {
+	case msg := <-f.messages:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("Expected an ntfy message")
+		return ntfy.Message{}
+	}
+}
+
+func TestNtfyService_Disabled(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewNtfyService(store, nil, "")
+	if service.Enabled() {
+		t.Error("Expected ntfy to be disabled without a sender")
+	}
+	if err := service.SendTest(context.Background()); err != ErrNtfyDisabled {
+		t.Errorf("Expected ErrNtfyDisabled, got %v", err)
+	}
+	if delivered := service.Notify(context.Background(), &models.PlantState{Name: "Fern"}, models.NotificationTriggerDue); delivered != 0 {
+		t.Errorf("Expected no deliveries, got %d", delivered)
+	}
+}
+
+func TestNtfyService_Notify(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.CreateUser(&models.User{Email: "alice@example.com", Name: "Alice"})
+
+	sender := newFakeNtfySender()
+	service := NewNtfyService(store, sender, "https://plants.example.com")
+
+	lastWatered := time.Now().Add(-30 * time.Hour)
+	plant := &models.PlantState{ID: 1, Name: "Fern", LastWatered: &lastWatered, WateredBy: "alice@example.com", TimeoutHours: 24}
+	if delivered := service.Notify(context.Background(), plant, models.NotificationTriggerDue); delivered != 1 {
+		t.Fatalf("Expected one delivery, got %d", delivered)
+	}
+	msg := sender.next(t)
+	if msg.Title != "Fern is overdue for watering" || msg.Message != "It was last watered 30 hours ago by Alice." || msg.Priority != ntfy.PriorityHigh || msg.Click != "https://plants.example.com" {
+		t.Errorf("Unexpected overdue message: %+v", msg)
+	}
+
+	service.Notify(context.Background(), &models.PlantState{ID: 2, Name: "Cactus"}, models.NotificationTriggerCritical)
+	msg = sender.next(t)
+	if msg.Title != "Cactus needs water now!" || msg.Message != "It has never been watered." || msg.Priority != ntfy.PriorityUrgent || msg.Tags[0] != "wilted_flower" {
+		t.Errorf("Unexpected critical message: %+v", msg)
+	}
+
+	sender.err = errors.New("ntfy returned 429: limit reached")
+	if delivered := service.Notify(context.Background(), plant, models.NotificationTriggerDue); delivered != 0 {
+		t.Errorf("Expected no deliveries when ntfy fails, got %d", delivered)
+	}
+}
+
+func TestNtfyService_NotifyWatered(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	sender := newFakeNtfySender()
+	ntfyService := NewNtfyService(store, sender, "")
+	plantService := NewPlantService(store)
+	plantService.AddWateringNotifier(ntfyService)
+
+	now := time.Now()
+	if _, err := plantService.WaterPlantByIDAt(1, "bob@example.com", now.Add(-5*time.Hour)); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+	msg := sender.next(t)
+	if msg.Title != "Our Plant was watered" || msg.Message != "bob@example.com watered it." || msg.Priority != ntfy.PriorityLow {
+		t.Errorf("Unexpected first watering message: %+v", msg)
+	}
+
+	// In privacy mode the waterer is not named
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, PrivacyMode: true})
+	plantService.WaterPlantByIDAt(1, "bob@example.com", now)
+	if msg = sender.next(t); msg.Message != "Someone watered it after 5 hours." {
+		t.Errorf("Unexpected watering message: %+v", msg)
+	}
+}
@@ -1,119 +1,538 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"sync"
 	"time"
 
+	"watered/internal/i18n"
 	"watered/internal/models"
+	"watered/internal/stats"
 	"watered/internal/storage"
+	"watered/internal/tracing"
 )
 
+// DefaultStatusDwellTime is how long a worse plant status is held before a
+// better one is reported without an intervening watering
+const DefaultStatusDwellTime = 5 * time.Minute
+
+// DefaultUndoWateringWindow is how long after recording a watering it may be undone
+const DefaultUndoWateringWindow = 10 * time.Minute
+
+// ErrPlantNotFound is returned when a plant ID does not exist
+var ErrPlantNotFound = errors.New("plant not found")
+
+// ErrInvalidVacation is returned for a vacation that ends before it starts
+// or has already ended
+var ErrInvalidVacation = errors.New("invalid vacation")
+
+// Errors returned when a watering cannot be undone
+var (
+	ErrNothingToUndo    = errors.New("no watering to undo")
+	ErrUndoExpired      = errors.New("watering is too old to undo")
+	ErrUndoNotPermitted = errors.New("watering was recorded by someone else")
+)
+
+// plantStatusState is the hysteresis state tracked for a single plant
+type plantStatusState struct {
+	status      models.PlantHealthStatus
+	changedAt   time.Time
+	lastWatered *time.Time
+}
+
 // PlantService handles plant-related business logic
 type PlantService struct {
+	*plantServiceState
 	storage storage.Storage
+	// ctx carries the span that storage calls are recorded under; it is
+	// only set on the copies WithContext returns
+	ctx context.Context
+	// printer translates human-readable strings; nil means English
+	printer *i18n.Printer
+}
+
+// plantServiceState is the state shared by a plant service and the copies
+// WithContext returns
+type plantServiceState struct {
+	// Status hysteresis state, per plant ID
+	statusMu        sync.Mutex
+	statusDwellTime time.Duration
+	statuses        map[int]*plantStatusState
+
+	events            *PlantEvents
+	publisher         Publisher
+	wateringNotifiers []WateringNotifier
+	// notificationsEnabled is checked before watering notifications are
+	// sent, nil means always
+	notificationsEnabled func() bool
+	// notifying counts watering notifications still being sent
+	notifying sync.WaitGroup
+
+	clockSkewTolerance time.Duration
+	undoWateringWindow time.Duration
 }
 
 // NewPlantService creates a new plant service
 func NewPlantService(storage storage.Storage) *PlantService {
 	return &PlantService{
+		plantServiceState: &plantServiceState{
+			statusDwellTime: DefaultStatusDwellTime,
+			statuses:        make(map[int]*plantStatusState),
+			events:          NewPlantEvents(),
+
+			clockSkewTolerance: DefaultClockSkewTolerance,
+			undoWateringWindow: DefaultUndoWateringWindow,
+		},
 		storage: storage,
 	}
 }
 
-// GetPlant returns the current plant state, creating a default one if none exists
+// WithContext returns a service whose work is traced as part of the request
+// ctx belongs to, and whose human-readable strings are in the request's
+// language. For an untraced English request it returns s itself.
+func (s *PlantService) WithContext(ctx context.Context) *PlantService {
+	printer := i18n.FromContext(ctx)
+	traced := tracing.SpanFromContext(ctx) != nil
+	if !traced && printer.Locale() == s.printer.Locale() {
+		return s
+	}
+	service := &PlantService{
+		plantServiceState: s.plantServiceState,
+		storage:           s.storage,
+		printer:           printer,
+	}
+	if traced {
+		service.storage = storage.WithTracing(ctx, s.storage)
+		service.ctx = ctx
+	}
+	return service
+}
+
+// trace starts a span for a service method. Storage calls made through the
+// returned service are recorded as its children.
+func (s *PlantService) trace(name string, attrs ...tracing.Attribute) (*PlantService, *tracing.Span) {
+	if s.ctx == nil {
+		return s, nil
+	}
+	ctx, span := tracing.Start(s.ctx, name, attrs...)
+	return s.WithContext(ctx), span
+}
+
+// Events returns the hub that streams live plant status changes
+func (s *PlantService) Events() *PlantEvents {
+	return s.events
+}
+
+// SetStatusDwellTime sets the minimum time a status is held before it may improve
+func (s *PlantService) SetStatusDwellTime(d time.Duration) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	s.statusDwellTime = d
+}
+
+// SetClockSkewTolerance sets how far client clocks may drift before
+// responses warn about it and client timestamps in the future are rejected
+func (s *PlantService) SetClockSkewTolerance(d time.Duration) {
+	s.clockSkewTolerance = d
+}
+
+// ClockSkewTolerance returns how far client clocks may drift from the server
+func (s *PlantService) ClockSkewTolerance() time.Duration {
+	return s.clockSkewTolerance
+}
+
+// SetUndoWateringWindow sets how long after recording a watering it may be undone
+func (s *PlantService) SetUndoWateringWindow(d time.Duration) {
+	s.undoWateringWindow = d
+}
+
+// CheckClockSkew compares a client's clock with the server's, returning nil
+// when it is within tolerance
+func (s *PlantService) CheckClockSkew(clientTime time.Time) *ClockSkew {
+	return MeasureClockSkew(clientTime, time.Now(), s.clockSkewTolerance)
+}
+
+// TimeParser returns a parser for user-entered watering times in the
+// household timezone
+func (s *PlantService) TimeParser(locale string) *TimeParser {
+	parser := NewTimeParser(s.Location(), locale)
+	parser.SetSkewTolerance(s.clockSkewTolerance)
+	return parser
+}
+
+// statusSeverity orders plant health statuses from best to worst
+func statusSeverity(status models.PlantHealthStatus) int {
+	switch status {
+	case models.HealthStatusHealthy, models.HealthStatusPaused:
+		return 0
+	case models.HealthStatusNeedsWater:
+		return 1
+	case models.HealthStatusDue:
+		return 2
+	case models.HealthStatusCritical:
+		return 3
+	default:
+		return -1
+	}
+}
+
+// stableHealthStatus returns the plant's health status with hysteresis applied.
+// Status changes for the worse and changes caused by a watering or a vacation
+// starting or ending are reported immediately; other improvements (e.g. a
+// timeout edit near a boundary) only take effect after the dwell time so the
+// status does not oscillate.
+func (s *PlantService) stableHealthStatus(plant *models.PlantState) models.PlantHealthStatus {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	now := time.Now()
+	current := plant.GetHealthStatus()
+
+	state, exists := s.statuses[plant.ID]
+	if !exists {
+		s.statuses[plant.ID] = &plantStatusState{status: current, changedAt: now, lastWatered: plant.LastWatered}
+		return current
+	}
+
+	watered := !sameTime(plant.LastWatered, state.lastWatered)
+	paused := (current == models.HealthStatusPaused) != (state.status == models.HealthStatusPaused)
+	if !watered && !paused &&
+		statusSeverity(current) < statusSeverity(state.status) &&
+		now.Sub(state.changedAt) < s.statusDwellTime {
+		return state.status
+	}
+
+	if current != state.status || watered {
+		state.status = current
+		state.changedAt = now
+		state.lastWatered = plant.LastWatered
+	}
+	return current
+}
+
+// sameTime reports whether two optional timestamps are equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// GetPlant returns the default plant state, creating it if none exists
 func (s *PlantService) GetPlant() (*models.PlantState, error) {
-	plant, err := s.storage.GetPlantState()
+	return s.GetPlantByID(models.DefaultPlantID)
+}
+
+// GetPlantByID returns a plant by ID. The default plant is created on first
+// access; other missing plants return ErrPlantNotFound.
+func (s *PlantService) GetPlantByID(id int) (*models.PlantState, error) {
+	plant, err := s.storage.GetPlant(id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get plant state: %w", err)
 	}
 
+	if plant != nil {
+		slog.Debug("Found existing plant", "plant_id", plant.ID, "timeout_hours", plant.TimeoutHours)
+		return plant, nil
+	}
+
+	if id != models.DefaultPlantID {
+		return nil, ErrPlantNotFound
+	}
+
 	// Create default plant if none exists
-	if plant == nil {
-		log.Printf("DEBUG GetPlant: No plant found, creating default plant with 24h timeout")
-		plant = s.createDefaultPlant()
-		if err := s.storage.UpdatePlantState(plant); err != nil {
-			log.Printf("Warning: failed to save default plant: %v", err)
-		} else {
-			log.Printf("DEBUG GetPlant: Default plant created and saved with %d hour timeout", plant.TimeoutHours)
-		}
+	slog.Debug("No plant found, creating default plant")
+	plant = s.createDefaultPlant()
+	if err := s.storage.UpdatePlantState(plant); err != nil {
+		slog.Warn("Failed to save default plant", "error", err)
 	} else {
-		log.Printf("DEBUG GetPlant: Found existing plant with %d hour timeout", plant.TimeoutHours)
+		slog.Debug("Default plant created", "plant_id", plant.ID, "timeout_hours", plant.TimeoutHours)
 	}
 
 	return plant, nil
 }
 
-// WaterPlant records a watering event for the plant
+// ListPlants returns all plants, making sure the default plant exists
+func (s *PlantService) ListPlants() ([]*models.PlantState, error) {
+	s, span := s.trace("PlantService.ListPlants")
+	defer span.End()
+
+	if _, err := s.GetPlant(); err != nil {
+		return nil, err
+	}
+
+	plants, err := s.storage.ListPlants()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plants: %w", err)
+	}
+	return plants, nil
+}
+
+// CreatePlant adds a new plant
+func (s *PlantService) CreatePlant(name string, timeoutHours int) (*models.PlantState, error) {
+	s, span := s.trace("PlantService.CreatePlant")
+	defer span.End()
+
+	if timeoutHours == 0 {
+		timeoutHours = 24
+	}
+
+	// Make sure the default plant keeps ID 1
+	if _, err := s.GetPlant(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	plant := &models.PlantState{
+		Name:         name,
+		TimeoutHours: timeoutHours,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := plant.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid plant: %w", err)
+	}
+
+	if err := s.storage.CreatePlant(plant); err != nil {
+		return nil, fmt.Errorf("failed to create plant: %w", err)
+	}
+
+	slog.Info("Plant created", "plant_id", plant.ID, "name", plant.Name, "timeout_hours", plant.TimeoutHours)
+	s.publishPlant(plant)
+	return plant, nil
+}
+
+// DeletePlant removes a plant along with its history, care tasks, share
+// links and devices. The default plant cannot be deleted.
+func (s *PlantService) DeletePlant(id int) error {
+	s, span := s.trace("PlantService.DeletePlant", tracing.Int("plant.id", id))
+	defer span.End()
+
+	if id == models.DefaultPlantID {
+		return fmt.Errorf("the default plant cannot be deleted")
+	}
+
+	if _, err := s.GetPlantByID(id); err != nil {
+		return err
+	}
+
+	if err := s.storage.DeletePlant(id); err != nil {
+		return fmt.Errorf("failed to delete plant: %w", err)
+	}
+
+	s.statusMu.Lock()
+	delete(s.statuses, id)
+	s.statusMu.Unlock()
+
+	slog.Info("Plant deleted", "plant_id", id)
+	s.publishPlantDeleted(id)
+	return nil
+}
+
+// savePlant persists changes to an existing plant
+func (s *PlantService) savePlant(plant *models.PlantState) error {
+	if plant.ID == models.DefaultPlantID {
+		return s.storage.UpdatePlantState(plant)
+	}
+	return s.storage.UpdatePlant(plant)
+}
+
+// WaterPlant records a watering event for the default plant
 func (s *PlantService) WaterPlant(wateredBy string) (*models.PlantState, error) {
+	return s.WaterPlantByID(models.DefaultPlantID, wateredBy)
+}
+
+// WaterPlantByID records a watering event for a plant
+func (s *PlantService) WaterPlantByID(id int, wateredBy string) (*models.PlantState, error) {
+	return s.WaterPlantByIDAt(id, wateredBy, time.Now())
+}
+
+// WaterPlantByIDAt records a watering that happened at wateredAt, such as
+// one entered after the fact or synced from an offline device. Waterings
+// older than the plant's last watering are only added to the history.
+func (s *PlantService) WaterPlantByIDAt(id int, wateredBy string, wateredAt time.Time) (*models.PlantState, error) {
+	s, span := s.trace("PlantService.WaterPlantByIDAt", tracing.Int("plant.id", id))
+	defer span.End()
+
 	if wateredBy == "" {
 		return nil, fmt.Errorf("watered_by field is required")
 	}
+	if wateredAt.After(time.Now().Add(s.clockSkewTolerance)) {
+		return nil, fmt.Errorf("watering time %s is in the future", wateredAt.Format(time.RFC3339))
+	}
 
-	plant, err := s.GetPlant()
+	plant, err := s.GetPlantByID(id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get plant for watering: %w", err)
 	}
 
 	// Update watering information
-	now := time.Now()
-	plant.LastWatered = &now
-	plant.WateredBy = wateredBy
-	plant.UpdatedAt = now
+	if previous := plant.LastWatered; previous == nil || wateredAt.After(*previous) {
+		plant.LastWatered = &wateredAt
+		plant.WateredBy = wateredBy
+		plant.UpdatedAt = time.Now()
 
-	// Save the updated plant state
-	if err := s.storage.UpdatePlantState(plant); err != nil {
-		return nil, fmt.Errorf("failed to save watered plant: %w", err)
+		// Save the updated plant state
+		if err := s.savePlant(plant); err != nil {
+			return nil, fmt.Errorf("failed to save watered plant: %w", err)
+		}
+		s.notifyWatered(plant, previous)
+	}
+
+	// A failure to record history must not undo the watering itself
+	recordedAt := time.Now()
+	event := &models.PlantWateringEvent{PlantID: plant.ID, WateredAt: wateredAt, WateredBy: wateredBy, RecordedAt: &recordedAt}
+	if err := s.storage.AddWateringEvent(event); err != nil {
+		slog.Error("Failed to record watering event", "plant_id", plant.ID, "error", err)
 	}
 
-	log.Printf("Plant watered by %s at %s", wateredBy, now.Format(time.RFC3339))
+	slog.Info("Plant watered", "plant_id", plant.ID, "by", wateredBy, "at", wateredAt.Format(time.RFC3339))
+	s.publish(PlantEventWatered, plant)
+	s.publishPlant(plant)
 	return plant, nil
 }
 
-// GetPlantStatus returns just the health status information
+// UndoWateringByID removes a plant's latest watering from its history and
+// restores the one before it, for waterings recorded by mistake. Only the
+// user who recorded the watering or an admin may undo it, and only within
+// the undo window. It returns the plant and the watering that was removed.
+func (s *PlantService) UndoWateringByID(id int, undoneBy string, admin bool) (*models.PlantState, *models.PlantWateringEvent, error) {
+	s, span := s.trace("PlantService.UndoWateringByID", tracing.Int("plant.id", id))
+	defer span.End()
+
+	plant, err := s.GetPlantByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	events, err := s.storage.ListWateringEvents(plant.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list watering events: %w", err)
+	}
+
+	// Only the watering the plant currently shows can be undone
+	if len(events) == 0 || !sameTime(&events[len(events)-1].WateredAt, plant.LastWatered) {
+		return nil, nil, ErrNothingToUndo
+	}
+	last := events[len(events)-1]
+	if !admin && last.WateredBy != undoneBy {
+		return nil, nil, ErrUndoNotPermitted
+	}
+	recordedAt := last.WateredAt
+	if last.RecordedAt != nil {
+		recordedAt = *last.RecordedAt
+	}
+	if time.Since(recordedAt) > s.undoWateringWindow {
+		return nil, nil, ErrUndoExpired
+	}
+
+	if err := s.storage.DeleteWateringEvent(last.ID); err != nil {
+		return nil, nil, fmt.Errorf("failed to delete watering event: %w", err)
+	}
+
+	plant.LastWatered = nil
+	plant.WateredBy = ""
+	if len(events) > 1 {
+		previous := events[len(events)-2]
+		plant.LastWatered = &previous.WateredAt
+		plant.WateredBy = previous.WateredBy
+	}
+	plant.UpdatedAt = time.Now()
+	if err := s.savePlant(plant); err != nil {
+		return nil, nil, fmt.Errorf("failed to save plant: %w", err)
+	}
+
+	slog.Info("Plant watering undone", "plant_id", plant.ID, "by", undoneBy, "watered_by", last.WateredBy, "watered_at", last.WateredAt.Format(time.RFC3339))
+	s.publishPlant(plant)
+	return plant, last, nil
+}
+
+// GetPlantStatus returns just the health status information for the default plant
 func (s *PlantService) GetPlantStatus() (*PlantStatusResponse, error) {
-	plant, err := s.GetPlant()
+	return s.GetPlantStatusByID(models.DefaultPlantID)
+}
+
+// GetPlantStatusByID returns just the health status information for a plant
+func (s *PlantService) GetPlantStatusByID(id int) (*PlantStatusResponse, error) {
+	s, span := s.trace("PlantService.GetPlantStatusByID", tracing.Int("plant.id", id))
+	defer span.End()
+
+	plant, err := s.GetPlantByID(id)
 	if err != nil {
 		return nil, err
 	}
+	return s.statusResponse(plant), nil
+}
 
+// statusResponse builds the health status information for a plant
+func (s *PlantService) statusResponse(plant *models.PlantState) *PlantStatusResponse {
+	status := s.stableHealthStatus(plant)
 	return &PlantStatusResponse{
-		Status:                     plant.GetHealthStatus(),
-		TimeSinceWateringFormatted: plant.GetFormattedTimeSinceWatering(),
+		Status:                     status,
+		OverallStatus:              s.overallStatus(plant, status),
+		TimeSinceWateringFormatted: plant.FormatTimeSinceWatering(s.printer),
 		HoursSinceWatering:         plant.GetHoursSinceWatering(),
 		IsOverdue:                  plant.IsOverdue(),
+		IsCritical:                 plant.IsCritical(),
 		TimeUntilDue:               plant.GetTimeUntilDue(),
-	}, nil
+	}
 }
 
-// GetPlantTimer returns timer-specific information
+// GetPlantTimer returns timer-specific information for the default plant
 func (s *PlantService) GetPlantTimer() (*PlantTimerResponse, error) {
-	plant, err := s.GetPlant()
+	return s.GetPlantTimerByID(models.DefaultPlantID)
+}
+
+// GetPlantTimerByID returns timer-specific information for a plant
+func (s *PlantService) GetPlantTimerByID(id int) (*PlantTimerResponse, error) {
+	s, span := s.trace("PlantService.GetPlantTimerByID", tracing.Int("plant.id", id))
+	defer span.End()
+
+	plant, err := s.GetPlantByID(id)
 	if err != nil {
 		return nil, err
 	}
 
+	needsWater, critical := plant.HealthThresholds()
 	var nextWateringTime *time.Time
 	if plant.LastWatered != nil {
-		next := plant.LastWatered.Add(time.Duration(plant.TimeoutHours) * time.Hour)
+		next := plant.LastWatered.Add(plant.DueAfter())
 		nextWateringTime = &next
 	}
 
 	return &PlantTimerResponse{
 		LastWatered:                plant.LastWatered,
 		TimeSinceWatering:          plant.GetTimeSinceWatering(),
-		TimeSinceWateringFormatted: plant.GetFormattedTimeSinceWatering(),
+		TimeSinceWateringFormatted: plant.FormatTimeSinceWatering(s.printer),
 		HoursSinceWatering:         plant.GetHoursSinceWatering(),
 		TimeoutHours:               plant.TimeoutHours,
+		WeatherFactor:              plant.WeatherFactor(),
+		EffectiveTimeoutHours:      plant.EffectiveTimeout().Hours(),
+		Weather:                    plant.CurrentWeather(),
+		GracePeriodHours:           plant.GracePeriodHours,
+		NeedsWaterPercent:          needsWater,
+		CriticalPercent:            critical,
 		NextWateringTime:           nextWateringTime,
 		TimeUntilDue:               plant.GetTimeUntilDue(),
 		IsOverdue:                  plant.IsOverdue(),
+		IsCritical:                 plant.IsCritical(),
 	}, nil
 }
 
-// UpdatePlantSettings updates plant configuration (timeout, name, etc.)
+// UpdatePlantSettings updates the default plant's configuration (timeout, name, etc.)
 func (s *PlantService) UpdatePlantSettings(name string, timeoutHours int) (*models.PlantState, error) {
-	plant, err := s.GetPlant()
+	return s.UpdatePlantSettingsByID(models.DefaultPlantID, name, timeoutHours)
+}
+
+// UpdatePlantSettingsByID updates a plant's configuration (timeout, name, etc.)
+func (s *PlantService) UpdatePlantSettingsByID(id int, name string, timeoutHours int) (*models.PlantState, error) {
+	s, span := s.trace("PlantService.UpdatePlantSettingsByID", tracing.Int("plant.id", id))
+	defer span.End()
+
+	plant, err := s.GetPlantByID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -138,17 +557,203 @@ func (s *PlantService) UpdatePlantSettings(name string, timeoutHours int) (*mode
 	}
 
 	// Save the updated plant
-	if err := s.storage.UpdatePlantState(plant); err != nil {
+	if err := s.savePlant(plant); err != nil {
 		return nil, fmt.Errorf("failed to save plant settings: %w", err)
 	}
 
-	log.Printf("Plant settings updated: name=%s, timeout=%d hours", plant.Name, plant.TimeoutHours)
+	slog.Info("Plant settings updated", "plant_id", plant.ID, "name", plant.Name, "timeout_hours", plant.TimeoutHours)
+	s.publishPlant(plant)
 	return plant, nil
 }
 
-// ResetPlant resets the plant to unwatered state (admin function)
+// UpdateGracePeriod sets how long the default plant stays "due" past its timeout before becoming critical
+func (s *PlantService) UpdateGracePeriod(gracePeriodHours int) (*models.PlantState, error) {
+	return s.UpdateGracePeriodByID(models.DefaultPlantID, gracePeriodHours)
+}
+
+// UpdateGracePeriodByID sets how long a plant stays "due" past its timeout before becoming critical
+func (s *PlantService) UpdateGracePeriodByID(id int, gracePeriodHours int) (*models.PlantState, error) {
+	plant, err := s.GetPlantByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	plant.GracePeriodHours = gracePeriodHours
+	plant.UpdatedAt = time.Now()
+
+	if err := plant.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid grace period: %w", err)
+	}
+
+	if err := s.savePlant(plant); err != nil {
+		return nil, fmt.Errorf("failed to save grace period: %w", err)
+	}
+
+	slog.Info("Plant grace period updated", "plant_id", plant.ID, "grace_period_hours", plant.GracePeriodHours)
+	s.publishPlant(plant)
+	return plant, nil
+}
+
+// UpdateHealthThresholdsByID sets the shares of a plant's timeout, in
+// percent, after which it needs water and after which it is overdue. 0
+// restores a default.
+func (s *PlantService) UpdateHealthThresholdsByID(id int, needsWaterPercent, criticalPercent int) (*models.PlantState, error) {
+	plant, err := s.GetPlantByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	plant.NeedsWaterPercent = needsWaterPercent
+	plant.CriticalPercent = criticalPercent
+	plant.UpdatedAt = time.Now()
+
+	if err := plant.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid health thresholds: %w", err)
+	}
+
+	if err := s.savePlant(plant); err != nil {
+		return nil, fmt.Errorf("failed to save health thresholds: %w", err)
+	}
+
+	needsWater, critical := plant.HealthThresholds()
+	slog.Info("Plant health thresholds updated", "plant_id", plant.ID, "needs_water_percent", needsWater, "critical_percent", critical)
+	s.publishPlant(plant)
+	return plant, nil
+}
+
+// UpdateMetadataByID replaces a plant's custom fields. Fields are validated
+// against their declared types; an empty map removes them all.
+func (s *PlantService) UpdateMetadataByID(id int, metadata map[string]models.MetadataValue) (*models.PlantState, error) {
+	plant, err := s.GetPlantByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, err := models.NormalizeMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+
+	plant.Metadata = normalized
+	plant.UpdatedAt = time.Now()
+
+	if err := s.savePlant(plant); err != nil {
+		return nil, fmt.Errorf("failed to save metadata: %w", err)
+	}
+
+	slog.Info("Plant metadata updated", "plant_id", plant.ID, "fields", len(plant.Metadata))
+	s.publishPlant(plant)
+	return plant, nil
+}
+
+// SetVacationByID pauses a plant's reminders and overdue status from start
+// until end. They resume by themselves when the vacation ends.
+func (s *PlantService) SetVacationByID(id int, start, end time.Time) (*models.PlantState, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("%w: end must be after start", ErrInvalidVacation)
+	}
+	if !end.After(time.Now()) {
+		return nil, fmt.Errorf("%w: end must be in the future", ErrInvalidVacation)
+	}
+
+	plant, err := s.GetPlantByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	plant.Vacation = &models.Vacation{Start: start, End: end}
+	plant.UpdatedAt = time.Now()
+
+	if err := s.savePlant(plant); err != nil {
+		return nil, fmt.Errorf("failed to save vacation: %w", err)
+	}
+
+	slog.Info("Plant vacation set", "plant_id", plant.ID, "start", start.Format(time.RFC3339), "end", end.Format(time.RFC3339))
+	s.publishPlant(plant)
+	return plant, nil
+}
+
+// ClearVacationByID ends a plant's vacation early, or cancels one that has
+// not started
+func (s *PlantService) ClearVacationByID(id int) (*models.PlantState, error) {
+	plant, err := s.GetPlantByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if plant.Vacation == nil {
+		return plant, nil
+	}
+
+	plant.Vacation = nil
+	plant.UpdatedAt = time.Now()
+
+	if err := s.savePlant(plant); err != nil {
+		return nil, fmt.Errorf("failed to clear vacation: %w", err)
+	}
+
+	slog.Info("Plant vacation cleared", "plant_id", plant.ID)
+	s.publishPlant(plant)
+	return plant, nil
+}
+
+// UpdateOutdoorByID marks whether a plant lives outdoors, where its timeout
+// follows the weather. Moving a plant indoors drops its weather adjustment.
+func (s *PlantService) UpdateOutdoorByID(id int, outdoor bool) (*models.PlantState, error) {
+	plant, err := s.GetPlantByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	plant.Outdoor = outdoor
+	if !outdoor {
+		plant.Weather = nil
+	}
+	plant.UpdatedAt = time.Now()
+
+	if err := s.savePlant(plant); err != nil {
+		return nil, fmt.Errorf("failed to save outdoor setting: %w", err)
+	}
+
+	slog.Info("Plant outdoor setting updated", "plant_id", plant.ID, "outdoor", plant.Outdoor)
+	s.publishPlant(plant)
+	return plant, nil
+}
+
+// setWeatherAdjustment records the latest weather adjustment of a plant if
+// it is still outdoors. Refreshing it does not count as a settings change, so
+// UpdatedAt is left alone.
+func (s *PlantService) setWeatherAdjustment(id int, adjustment *models.WeatherAdjustment) error {
+	plant, err := s.GetPlantByID(id)
+	if err != nil {
+		return err
+	}
+	if !plant.Outdoor {
+		return nil
+	}
+
+	changed := plant.WeatherFactor() != adjustment.Factor
+	plant.Weather = adjustment
+	if err := s.savePlant(plant); err != nil {
+		return fmt.Errorf("failed to save weather adjustment: %w", err)
+	}
+	if changed {
+		slog.Info("Plant timeout adjusted for weather", "plant_id", plant.ID, "factor", adjustment.Factor, "reason", adjustment.Reason)
+		s.publishPlant(plant)
+	}
+	return nil
+}
+
+// ResetPlant resets the default plant to unwatered state (admin function)
 func (s *PlantService) ResetPlant() (*models.PlantState, error) {
-	plant, err := s.GetPlant()
+	return s.ResetPlantByID(models.DefaultPlantID)
+}
+
+// ResetPlantByID resets a plant to unwatered state (admin function)
+func (s *PlantService) ResetPlantByID(id int) (*models.PlantState, error) {
+	s, span := s.trace("PlantService.ResetPlantByID", tracing.Int("plant.id", id))
+	defer span.End()
+
+	plant, err := s.GetPlantByID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -158,19 +763,73 @@ func (s *PlantService) ResetPlant() (*models.PlantState, error) {
 	plant.WateredBy = ""
 	plant.UpdatedAt = time.Now()
 
-	if err := s.storage.UpdatePlantState(plant); err != nil {
+	if err := s.savePlant(plant); err != nil {
 		return nil, fmt.Errorf("failed to reset plant: %w", err)
 	}
 
-	log.Printf("Plant reset to unwatered state")
+	slog.Info("Plant reset to unwatered state", "plant_id", plant.ID)
+	s.publishPlant(plant)
 	return plant, nil
 }
 
+// WateringStats computes streaks and punctuality from the watering history
+// of all plants, counting days in the household timezone
+func (s *PlantService) WateringStats() (*stats.Report, error) {
+	s, span := s.trace("PlantService.WateringStats")
+	defer span.End()
+
+	events, err := s.storage.ListWateringEvents(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watering events: %w", err)
+	}
+	plants, err := s.storage.ListPlants()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plants: %w", err)
+	}
+	return stats.Compute(events, plants, s.Location(), time.Now()), nil
+}
+
+// Leaderboard ranks users by waterings in the current week or month of the
+// household timezone
+func (s *PlantService) Leaderboard(period stats.Period) (*stats.Leaderboard, error) {
+	s, span := s.trace("PlantService.Leaderboard")
+	defer span.End()
+
+	events, err := s.storage.ListWateringEvents(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watering events: %w", err)
+	}
+	return stats.BuildLeaderboard(events, period, s.Location(), time.Now()), nil
+}
+
+// IsPrivacyModeEnabled reports whether waterer identities should be hidden from non-admins
+func (s *PlantService) IsPrivacyModeEnabled() bool {
+	config, err := s.storage.GetAdminConfig()
+	if err != nil || config == nil {
+		return false
+	}
+	return config.PrivacyMode
+}
+
+// Location returns the household timezone set during setup, or the server's
+// local timezone when none is configured
+func (s *PlantService) Location() *time.Location {
+	config, err := s.storage.GetAdminConfig()
+	if err != nil || config == nil || config.Timezone == "" {
+		return time.Local
+	}
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
 // createDefaultPlant creates a default plant configuration
 func (s *PlantService) createDefaultPlant() *models.PlantState {
 	now := time.Now()
 	return &models.PlantState{
-		ID:           1,
+		ID:           models.DefaultPlantID,
 		Name:         "Our Plant",
 		LastWatered:  nil, // Never watered initially
 		TimeoutHours: 24,  // Default to 24 hours
@@ -186,7 +845,12 @@ type PlantStatusResponse struct {
 	TimeSinceWateringFormatted string                   `json:"time_since_watering_formatted"`
 	HoursSinceWatering         *float64                 `json:"hours_since_watering"`
 	IsOverdue                  bool                     `json:"is_overdue"`
+	IsCritical                 bool                     `json:"is_critical"`
 	TimeUntilDue               *time.Duration           `json:"time_until_due"`
+	// OverallStatus rolls the watering status and the plant's care tasks up
+	OverallStatus models.CareStatus `json:"overall_status"`
+	// ClockSkew warns when the requesting device's clock is off
+	ClockSkew *ClockSkew `json:"clock_skew,omitempty"`
 }
 
 // PlantTimerResponse represents the response for plant timer endpoint
@@ -196,7 +860,16 @@ type PlantTimerResponse struct {
 	TimeSinceWateringFormatted string         `json:"time_since_watering_formatted"`
 	HoursSinceWatering         *float64       `json:"hours_since_watering"`
 	TimeoutHours               int            `json:"timeout_hours"`
-	NextWateringTime           *time.Time     `json:"next_watering_time"`
-	TimeUntilDue               *time.Duration `json:"time_until_due"`
-	IsOverdue                  bool           `json:"is_overdue"`
+	// WeatherFactor is what TimeoutHours is multiplied by for the weather,
+	// 1 for indoor plants
+	WeatherFactor         float64                   `json:"weather_factor"`
+	EffectiveTimeoutHours float64                   `json:"effective_timeout_hours"`
+	Weather               *models.WeatherAdjustment `json:"weather,omitempty"`
+	GracePeriodHours      int                       `json:"grace_period_hours"`
+	NeedsWaterPercent     int                       `json:"needs_water_percent"`
+	CriticalPercent       int                       `json:"critical_percent"`
+	NextWateringTime      *time.Time                `json:"next_watering_time"`
+	TimeUntilDue          *time.Duration            `json:"time_until_due"`
+	IsOverdue             bool                      `json:"is_overdue"`
+	IsCritical            bool                      `json:"is_critical"`
 }
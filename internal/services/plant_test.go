@@ -1,6 +1,9 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -79,6 +82,129 @@ func TestPlantService_WaterPlant(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error when watering without user email")
 	}
+
+	// Only the successful watering is kept in the history
+	report, err := service.WateringStats()
+	if err != nil {
+		t.Fatalf("Failed to get watering stats: %v", err)
+	}
+	if report.Household.Waterings != 1 || report.User(userEmail) == nil {
+		t.Errorf("Expected one recorded watering by %s, got %+v", userEmail, report)
+	}
+}
+
+func TestPlantService_WaterPlantByIDAt(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewPlantService(store)
+	plant, err := service.GetPlant()
+	if err != nil {
+		t.Fatalf("Failed to get plant: %v", err)
+	}
+
+	// A backdated watering becomes the last watering
+	yesterday := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	plant, err = service.WaterPlantByIDAt(plant.ID, "alice@example.com", yesterday)
+	if err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+	if !plant.LastWatered.Equal(yesterday) || plant.WateredBy != "alice@example.com" {
+		t.Errorf("Expected last watering by alice yesterday, got %v by %s", plant.LastWatered, plant.WateredBy)
+	}
+
+	// An older one synced later is only added to the history
+	older := yesterday.Add(-24 * time.Hour)
+	plant, err = service.WaterPlantByIDAt(plant.ID, "bob@example.com", older)
+	if err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+	if !plant.LastWatered.Equal(yesterday) || plant.WateredBy != "alice@example.com" {
+		t.Errorf("Expected older watering not to replace the last one, got %v by %s", plant.LastWatered, plant.WateredBy)
+	}
+
+	report, err := service.WateringStats()
+	if err != nil {
+		t.Fatalf("Failed to get watering stats: %v", err)
+	}
+	if report.Household.Waterings != 2 || !report.Household.LastWatered.Equal(yesterday) {
+		t.Errorf("Expected both waterings in the history, got %+v", report.Household)
+	}
+
+	// Waterings beyond the clock skew tolerance are in the future
+	service.SetClockSkewTolerance(time.Minute)
+	if _, err := service.WaterPlantByIDAt(plant.ID, "alice@example.com", time.Now().Add(time.Hour)); err == nil {
+		t.Error("Expected error for a watering in the future")
+	}
+	if _, err := service.WaterPlantByIDAt(plant.ID, "alice@example.com", time.Now().Add(30*time.Second)); err != nil {
+		t.Errorf("Expected a watering within the tolerance to be accepted, got %v", err)
+	}
+}
+
+func TestPlantService_UndoWateringByID(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewPlantService(store)
+	plant, err := service.GetPlant()
+	if err != nil {
+		t.Fatalf("Failed to get plant: %v", err)
+	}
+
+	if _, _, err := service.UndoWateringByID(plant.ID, "alice@example.com", false); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo for an unwatered plant, got %v", err)
+	}
+
+	yesterday := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	if _, err := service.WaterPlantByIDAt(plant.ID, "bob@example.com", yesterday); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+	if _, err := service.WaterPlantByID(plant.ID, "alice@example.com"); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+
+	// Only the waterer or an admin may undo it
+	if _, _, err := service.UndoWateringByID(plant.ID, "bob@example.com", false); !errors.Is(err, ErrUndoNotPermitted) {
+		t.Errorf("Expected ErrUndoNotPermitted for another user, got %v", err)
+	}
+
+	plant, undone, err := service.UndoWateringByID(plant.ID, "alice@example.com", false)
+	if err != nil {
+		t.Fatalf("Failed to undo watering: %v", err)
+	}
+	if undone.WateredBy != "alice@example.com" {
+		t.Errorf("Expected alice's watering to be undone, got %+v", undone)
+	}
+	if plant.LastWatered == nil || !plant.LastWatered.Equal(yesterday) || plant.WateredBy != "bob@example.com" {
+		t.Errorf("Expected the previous watering to be restored, got %v by %s", plant.LastWatered, plant.WateredBy)
+	}
+	if events, _ := store.ListWateringEvents(plant.ID); len(events) != 1 {
+		t.Errorf("Expected one watering left in the history, got %d", len(events))
+	}
+
+	// Bob's backdated watering was recorded just now, so an admin can still
+	// undo it, leaving the plant unwatered
+	plant, _, err = service.UndoWateringByID(plant.ID, "admin@example.com", true)
+	if err != nil {
+		t.Fatalf("Failed to undo watering as admin: %v", err)
+	}
+	if plant.LastWatered != nil || plant.WateredBy != "" {
+		t.Errorf("Expected no watering left, got %v by %s", plant.LastWatered, plant.WateredBy)
+	}
+
+	// Waterings recorded before the window are kept
+	service.SetUndoWateringWindow(time.Millisecond)
+	if _, err := service.WaterPlantByID(plant.ID, "alice@example.com"); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, _, err := service.UndoWateringByID(plant.ID, "alice@example.com", false); !errors.Is(err, ErrUndoExpired) {
+		t.Errorf("Expected ErrUndoExpired after the window, got %v", err)
+	}
+
+	if _, _, err := service.UndoWateringByID(99, "alice@example.com", false); !errors.Is(err, ErrPlantNotFound) {
+		t.Errorf("Expected ErrPlantNotFound, got %v", err)
+	}
 }
 
 func TestPlantService_GetPlantStatus(t *testing.T) {
@@ -224,6 +350,122 @@ func TestPlantService_UpdatePlantSettings(t *testing.T) {
 	}
 }
 
+func TestPlantService_UpdateGracePeriod(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewPlantService(store)
+
+	plant, err := service.UpdateGracePeriod(12)
+	if err != nil {
+		t.Fatalf("Failed to update grace period: %v", err)
+	}
+
+	if plant.GracePeriodHours != 12 {
+		t.Errorf("Expected grace period 12 hours, got %d", plant.GracePeriodHours)
+	}
+
+	// Watered 30 hours ago with a 24h timeout is due, not critical
+	wateredAt := time.Now().Add(-30 * time.Hour)
+	plant.LastWatered = &wateredAt
+	store.UpdatePlantState(plant)
+
+	status, err := service.GetPlantStatus()
+	if err != nil {
+		t.Fatalf("Failed to get plant status: %v", err)
+	}
+
+	if status.Status != models.HealthStatusDue {
+		t.Errorf("Expected due status, got %s", status.Status)
+	}
+	if !status.IsOverdue || status.IsCritical {
+		t.Errorf("Expected overdue but not critical, got overdue=%v critical=%v", status.IsOverdue, status.IsCritical)
+	}
+
+	// Test invalid grace period
+	if _, err := service.UpdateGracePeriod(-1); err == nil {
+		t.Error("Expected error for negative grace period")
+	}
+}
+
+func TestPlantService_StatusHysteresis(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewPlantService(store)
+
+	// Watered 13 hours ago with a 24h timeout needs water
+	wateredAt := time.Now().Add(-13 * time.Hour)
+	plant, _ := service.GetPlant()
+	plant.LastWatered = &wateredAt
+	store.UpdatePlantState(plant)
+
+	status, _ := service.GetPlantStatus()
+	if status.Status != models.HealthStatusNeedsWater {
+		t.Fatalf("Expected needs_water, got %s", status.Status)
+	}
+
+	// Nudging the timeout across the boundary does not flip the status back
+	service.UpdatePlantSettings("", 27)
+	status, _ = service.GetPlantStatus()
+	if status.Status != models.HealthStatusNeedsWater {
+		t.Errorf("Expected status to hold at needs_water, got %s", status.Status)
+	}
+
+	// Watering the plant is reported immediately
+	service.WaterPlant("test@example.com")
+	status, _ = service.GetPlantStatus()
+	if status.Status != models.HealthStatusHealthy {
+		t.Errorf("Expected healthy after watering, got %s", status.Status)
+	}
+}
+
+func TestPlantService_Vacation(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewPlantService(store)
+
+	// A critical plant is paused as soon as the vacation starts
+	status, _ := service.GetPlantStatus()
+	if status.Status != models.HealthStatusCritical {
+		t.Fatalf("Expected critical, got %s", status.Status)
+	}
+	now := time.Now()
+	plant, err := service.SetVacationByID(models.DefaultPlantID, now.Add(-time.Minute), now.Add(7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to set vacation: %v", err)
+	}
+	if plant.Vacation == nil || !plant.IsPaused() {
+		t.Errorf("Expected an active vacation, got %+v", plant.Vacation)
+	}
+	status, _ = service.GetPlantStatus()
+	if status.Status != models.HealthStatusPaused || status.IsOverdue || status.IsCritical {
+		t.Errorf("Expected paused and not overdue, got %+v", status)
+	}
+
+	// Ending it early resumes the status immediately
+	if _, err := service.ClearVacationByID(models.DefaultPlantID); err != nil {
+		t.Fatalf("Failed to clear vacation: %v", err)
+	}
+	status, _ = service.GetPlantStatus()
+	if status.Status != models.HealthStatusCritical {
+		t.Errorf("Expected critical after the vacation, got %s", status.Status)
+	}
+
+	for name, window := range map[string][2]time.Time{
+		"ends before it starts": {now.Add(time.Hour), now},
+		"already ended":         {now.Add(-2 * time.Hour), now.Add(-time.Hour)},
+	} {
+		if _, err := service.SetVacationByID(models.DefaultPlantID, window[0], window[1]); !errors.Is(err, ErrInvalidVacation) {
+			t.Errorf("Expected ErrInvalidVacation for a vacation that %s, got %v", name, err)
+		}
+	}
+	if _, err := service.SetVacationByID(99, now, now.Add(time.Hour)); !errors.Is(err, ErrPlantNotFound) {
+		t.Errorf("Expected ErrPlantNotFound, got %v", err)
+	}
+}
+
 func TestPlantService_ResetPlant(t *testing.T) {
 	store := storage.NewMemoryStorage()
 	defer store.Close()
@@ -269,3 +511,94 @@ func TestPlantService_ResetPlant(t *testing.T) {
 		t.Errorf("Expected critical status after reset, got %s", resetPlant.GetHealthStatus())
 	}
 }
+
+func TestPlantService_ConcurrentWaterAndGet(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewPlantService(store)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := service.WaterPlant(fmt.Sprintf("user%d@example.com", i)); err != nil {
+				t.Errorf("Failed to water plant: %v", err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := service.GetPlant(); err != nil {
+				t.Errorf("Failed to get plant: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := service.GetPlantStatus(); err != nil {
+				t.Errorf("Failed to get plant status: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	plant, _ := service.GetPlant()
+	if plant.LastWatered == nil {
+		t.Error("Expected plant to be watered")
+	}
+}
+
+func TestPlantService_MultiplePlants(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewPlantService(store)
+
+	fern, err := service.CreatePlant("Fern", 48)
+	if err != nil {
+		t.Fatalf("Failed to create plant: %v", err)
+	}
+	if fern.ID == models.DefaultPlantID {
+		t.Errorf("Expected new plant to get an ID other than the default, got %d", fern.ID)
+	}
+
+	plants, err := service.ListPlants()
+	if err != nil {
+		t.Fatalf("Failed to list plants: %v", err)
+	}
+	if len(plants) != 2 {
+		t.Fatalf("Expected default plant plus new plant, got %d", len(plants))
+	}
+
+	// Watering one plant leaves the other untouched
+	if _, err := service.WaterPlantByID(fern.ID, "test@example.com"); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+
+	status, err := service.GetPlantStatusByID(fern.ID)
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	if status.Status != models.HealthStatusHealthy {
+		t.Errorf("Expected watered plant to be healthy, got %s", status.Status)
+	}
+
+	defaultPlant, _ := service.GetPlant()
+	if defaultPlant.LastWatered != nil {
+		t.Error("Expected default plant to remain unwatered")
+	}
+
+	// Deleting
+	if err := service.DeletePlant(models.DefaultPlantID); err == nil {
+		t.Error("Expected error deleting the default plant")
+	}
+	if err := service.DeletePlant(fern.ID); err != nil {
+		t.Fatalf("Failed to delete plant: %v", err)
+	}
+	if _, err := service.GetPlantByID(fern.ID); !errors.Is(err, ErrPlantNotFound) {
+		t.Errorf("Expected ErrPlantNotFound after delete, got %v", err)
+	}
+	if _, err := service.WaterPlantByID(fern.ID, "test@example.com"); !errors.Is(err, ErrPlantNotFound) {
+		t.Errorf("Expected ErrPlantNotFound watering a deleted plant, got %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/push"
+	"watered/internal/storage"
+)
+
+// PushChannel is the notification channel name recorded for Web Push deliveries
+const PushChannel = "push"
+
+// ErrPushDisabled is returned when Web Push is used without VAPID keys configured
+var ErrPushDisabled = errors.New("push notifications are not configured")
+
+// pushPayload is the JSON message delivered to the service worker
+type pushPayload struct {
+	Title   string                   `json:"title"`
+	Body    string                   `json:"body"`
+	PlantID int                      `json:"plant_id"`
+	Status  models.PlantHealthStatus `json:"status"`
+	URL     string                   `json:"url"`
+	// SnoozeURL and SnoozeTitle offer a "remind me later" action
+	SnoozeURL   string `json:"snooze_url,omitempty"`
+	SnoozeTitle string `json:"snooze_title,omitempty"`
+}
+
+// PushSender delivers a payload to a single push subscription
+type PushSender interface {
+	Send(ctx context.Context, sub push.Subscription, payload []byte) error
+	PublicKey() string
+}
+
+// PushService manages Web Push subscriptions and delivers push messages
+type PushService struct {
+	storage             storage.Storage
+	sender              PushSender
+	notificationService *NotificationService
+	snoozes             *SnoozeService
+}
+
+// NewPushService creates a new push service. A nil sender disables push delivery.
+func NewPushService(storage storage.Storage, sender PushSender) *PushService {
+	return &PushService{
+		storage:             storage,
+		sender:              sender,
+		notificationService: NewNotificationService(storage),
+	}
+}
+
+// SetSnoozeService adds a snooze action to reminders and skips users who
+// snoozed them
+func (s *PushService) SetSnoozeService(snoozes *SnoozeService) {
+	s.snoozes = snoozes
+}
+
+// Enabled reports whether VAPID keys are configured
+func (s *PushService) Enabled() bool {
+	return s.sender != nil
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with
+func (s *PushService) PublicKey() string {
+	if s.sender == nil {
+		return ""
+	}
+	return s.sender.PublicKey()
+}
+
+// Subscribe stores a browser push subscription for a user. Re-subscribing
+// with the same endpoint replaces the previous subscription.
+func (s *PushService) Subscribe(userEmail, endpoint, p256dh, auth, userAgent string) (*models.PushSubscription, error) {
+	if !s.Enabled() {
+		return nil, ErrPushDisabled
+	}
+	if userEmail == "" {
+		return nil, fmt.Errorf("user email is required")
+	}
+
+	if err := push.ValidateSubscription(push.Subscription{Endpoint: endpoint, P256dh: p256dh, Auth: auth}); err != nil {
+		return nil, fmt.Errorf("invalid subscription: %w", err)
+	}
+
+	subscription := &models.PushSubscription{
+		UserEmail: strings.ToLower(userEmail),
+		Endpoint:  endpoint,
+		P256dh:    p256dh,
+		Auth:      auth,
+		UserAgent: userAgent,
+		CreatedAt: time.Now(),
+	}
+	if err := s.storage.SavePushSubscription(subscription); err != nil {
+		return nil, fmt.Errorf("failed to save push subscription: %w", err)
+	}
+
+	slog.Info("Push subscription registered", "email", subscription.UserEmail)
+	return subscription, nil
+}
+
+// Unsubscribe removes one of a user's push subscriptions
+func (s *PushService) Unsubscribe(userEmail, endpoint string) error {
+	subscriptions, err := s.storage.ListPushSubscriptions(strings.ToLower(userEmail))
+	if err != nil {
+		return fmt.Errorf("failed to list push subscriptions: %w", err)
+	}
+
+	for _, subscription := range subscriptions {
+		if subscription.Endpoint == endpoint {
+			if err := s.storage.DeletePushSubscription(endpoint); err != nil {
+				return fmt.Errorf("failed to delete push subscription: %w", err)
+			}
+			slog.Info("Push subscription removed", "email", subscription.UserEmail)
+			return nil
+		}
+	}
+	return fmt.Errorf("subscription not found")
+}
+
+// Subscriptions returns a user's push subscriptions
+func (s *PushService) Subscriptions(userEmail string) ([]*models.PushSubscription, error) {
+	return s.storage.ListPushSubscriptions(strings.ToLower(userEmail))
+}
+
+// Broadcast sends a payload to every subscription and records each delivery
+// in the notification history. Subscriptions the push service reports as
+// gone are deleted. It returns the number of successful deliveries.
+func (s *PushService) Broadcast(ctx context.Context, trigger models.NotificationTrigger, summary string, payload []byte) (int, error) {
+	return s.SendToUser(ctx, "", trigger, summary, payload)
+}
+
+// SendToUser sends a payload to every subscription of one user, or of all
+// users when userEmail is empty, like Broadcast
+func (s *PushService) SendToUser(ctx context.Context, userEmail string, trigger models.NotificationTrigger, summary string, payload []byte) (int, error) {
+	return s.sendEach(ctx, userEmail, trigger, summary, func(string) []byte { return payload })
+}
+
+// sendEach is SendToUser with a payload built for each subscription's user.
+// Subscriptions whose payload is nil are skipped.
+func (s *PushService) sendEach(ctx context.Context, userEmail string, trigger models.NotificationTrigger, summary string, payloadFor func(userEmail string) []byte) (int, error) {
+	if !s.Enabled() {
+		return 0, ErrPushDisabled
+	}
+
+	subscriptions, err := s.storage.ListPushSubscriptions(userEmail)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list push subscriptions: %w", err)
+	}
+
+	delivered := 0
+	for _, subscription := range subscriptions {
+		payload := payloadFor(subscription.UserEmail)
+		if payload == nil {
+			continue
+		}
+		err := s.sender.Send(ctx, push.Subscription{
+			Endpoint: subscription.Endpoint,
+			P256dh:   subscription.P256dh,
+			Auth:     subscription.Auth,
+		}, payload)
+
+		if errors.Is(err, push.ErrSubscriptionGone) {
+			slog.Info("Push subscription expired, removing it", "email", subscription.UserEmail)
+			if deleteErr := s.storage.DeletePushSubscription(subscription.Endpoint); deleteErr != nil {
+				slog.Warn("Failed to delete expired push subscription", "error", deleteErr)
+			}
+		} else if err != nil {
+			slog.Error("Failed to send push notification", "email", subscription.UserEmail, "error", err)
+		} else {
+			delivered++
+		}
+
+		if _, recordErr := s.notificationService.Record(subscription.UserEmail, PushChannel, trigger, summary, err); recordErr != nil {
+			slog.Warn("Failed to record push notification", "error", recordErr)
+		}
+	}
+	return delivered, nil
+}
+
+// Trigger reports a push milestone when the plant needs water or is critical
+func (s *PushService) Trigger(plant *models.PlantState) models.NotificationTrigger {
+	switch plant.GetHealthStatus() {
+	case models.HealthStatusNeedsWater:
+		return models.NotificationTriggerNeedsWater
+	case models.HealthStatusCritical:
+		return models.NotificationTriggerCritical
+	default:
+		return models.NotificationTriggerNone
+	}
+}
+
+// Notify broadcasts a push notification about a plant's new status
+func (s *PushService) Notify(ctx context.Context, plant *models.PlantState, trigger models.NotificationTrigger) int {
+	return s.NotifyRecipient(ctx, plant, trigger, "")
+}
+
+// NotifyRecipient pushes a notification about a plant's new status to one
+// user's devices, or to every subscription when recipient is empty
+func (s *PushService) NotifyRecipient(ctx context.Context, plant *models.PlantState, trigger models.NotificationTrigger, recipient string) int {
+	message := pushPayload{
+		Title:   fmt.Sprintf("%s is getting thirsty 🌱", plant.Name),
+		Body:    "Water it soon to keep it healthy.",
+		PlantID: plant.ID,
+		Status:  plant.GetHealthStatus(),
+		URL:     "/",
+	}
+	if trigger == models.NotificationTriggerCritical {
+		message.Title = fmt.Sprintf("%s needs water now! 🥀", plant.Name)
+		message.Body = "It's overdue for watering."
+	}
+
+	// Each user gets their own snooze link; users who snoozed the plant are skipped
+	payloadFor := func(userEmail string) []byte {
+		if s.snoozes.Snoozed(plant.ID, userEmail) {
+			return nil
+		}
+		userMessage := message
+		if s.snoozes != nil {
+			userMessage.SnoozeURL = s.snoozes.Link(plant.ID, userEmail, PushChannel)
+			userMessage.SnoozeTitle = s.snoozes.Label()
+		}
+		payload, err := json.Marshal(userMessage)
+		if err != nil {
+			slog.Error("Failed to encode push payload", "error", err)
+			return nil
+		}
+		return payload
+	}
+
+	delivered, err := s.sendEach(ctx, recipient, trigger, message.Title, payloadFor)
+	if err != nil {
+		slog.Error("Failed to send push notifications", "plant_id", plant.ID, "error", err)
+		return 0
+	}
+	slog.Info("Sent push notifications", "plant_id", plant.ID, "trigger", trigger, "delivered", delivered)
+	return delivered
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"watered/internal/models"
+	"watered/internal/push"
+	"watered/internal/storage"
+)
+
+// fakePushSender records deliveries instead of contacting a push service
+type fakePushSender struct {
+	mu       sync.Mutex
+	sent     map[string][][]byte
+	failures map[string]error
+}
+
+func newFakePushSender() *fakePushSender {
+	return &fakePushSender{
+		sent:     make(map[string][][]byte),
+		failures: make(map[string]error),
+	}
+}
+
+func (f *fakePushSender) Send(ctx context.Context, sub push.Subscription, payload []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failures[sub.Endpoint]; err != nil {
+		return err
+	}
+	f.sent[sub.Endpoint] = append(f.sent[sub.Endpoint], payload)
+	return nil
+}
+
+func (f *fakePushSender) PublicKey() string {
+	return "test-public-key"
+}
+
+func (f *fakePushSender) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	total := 0
+	for _, payloads := range f.sent {
+		total += len(payloads)
+	}
+	return total
+}
+
+// testSubscriptionKeys returns valid p256dh and auth values for a browser subscription
+func testSubscriptionKeys(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	auth := make([]byte, 16)
+	rand.Read(auth)
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()), base64.RawURLEncoding.EncodeToString(auth)
+}
+
+func TestPushService_Disabled(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewPushService(store, nil)
+	if service.Enabled() || service.PublicKey() != "" {
+		t.Error("Expected push to be disabled without a sender")
+	}
+
+	p256dh, auth := testSubscriptionKeys(t)
+	if _, err := service.Subscribe("test@example.com", "https://push.example.com/1", p256dh, auth, ""); err != ErrPushDisabled {
+		t.Errorf("Expected ErrPushDisabled, got %v", err)
+	}
+	if _, err := service.Broadcast(context.Background(), models.NotificationTriggerDue, "Plant is due", nil); err != ErrPushDisabled {
+		t.Errorf("Expected ErrPushDisabled, got %v", err)
+	}
+}
+
+func TestPushService_Subscribe(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewPushService(store, newFakePushSender())
+	p256dh, auth := testSubscriptionKeys(t)
+
+	tests := []struct {
+		name     string
+		email    string
+		endpoint string
+		p256dh   string
+		auth     string
+		wantErr  bool
+	}{
+		{"valid subscription", "Test@Example.com", "https://push.example.com/1", p256dh, auth, false},
+		{"missing email", "", "https://push.example.com/2", p256dh, auth, true},
+		{"http endpoint", "test@example.com", "http://push.example.com/3", p256dh, auth, true},
+		{"short key", "test@example.com", "https://push.example.com/4", "AAAA", auth, true},
+		{"short auth", "test@example.com", "https://push.example.com/5", p256dh, "AAAA", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Subscribe(tt.email, tt.endpoint, tt.p256dh, tt.auth, "test-agent")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Subscribe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	subscriptions, _ := service.Subscriptions("test@example.com")
+	if len(subscriptions) != 1 || subscriptions[0].UserEmail != "test@example.com" {
+		t.Fatalf("Expected one normalized subscription, got %+v", subscriptions)
+	}
+
+	if err := service.Unsubscribe("other@example.com", "https://push.example.com/1"); err == nil {
+		t.Error("Expected error removing another user's subscription")
+	}
+	if err := service.Unsubscribe("test@example.com", "https://push.example.com/1"); err != nil {
+		t.Errorf("Expected no error unsubscribing, got %v", err)
+	}
+	if subscriptions, _ := service.Subscriptions("test@example.com"); len(subscriptions) != 0 {
+		t.Errorf("Expected no subscriptions after unsubscribe, got %d", len(subscriptions))
+	}
+}
+
+func TestPushService_Broadcast(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	sender := newFakePushSender()
+	service := NewPushService(store, sender)
+
+	for i, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		p256dh, auth := testSubscriptionKeys(t)
+		if _, err := service.Subscribe(email, fmt.Sprintf("https://push.example.com/%d", i), p256dh, auth, ""); err != nil {
+			t.Fatalf("Failed to subscribe: %v", err)
+		}
+	}
+	sender.failures["https://push.example.com/1"] = push.ErrSubscriptionGone
+	sender.failures["https://push.example.com/2"] = fmt.Errorf("push service unavailable")
+
+	delivered, err := service.Broadcast(context.Background(), models.NotificationTriggerCritical, "Plant needs water", []byte(`{}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if delivered != 1 {
+		t.Errorf("Expected 1 delivery, got %d", delivered)
+	}
+
+	// Gone subscriptions are removed, transient failures are kept
+	subscriptions, _ := store.ListPushSubscriptions("")
+	if len(subscriptions) != 2 {
+		t.Errorf("Expected expired subscription to be removed, got %d subscriptions", len(subscriptions))
+	}
+
+	notifications, _ := store.ListNotifications(models.NotificationFilter{})
+	if len(notifications) != 3 {
+		t.Fatalf("Expected every delivery attempt to be recorded, got %d", len(notifications))
+	}
+	failed := 0
+	for _, notification := range notifications {
+		if notification.Channel != PushChannel {
+			t.Errorf("Expected push channel, got %s", notification.Channel)
+		}
+		if notification.Status == models.NotificationStatusFailed {
+			failed++
+		}
+	}
+	if failed != 2 {
+		t.Errorf("Expected 2 failed notifications, got %d", failed)
+	}
+}
+
+func TestPushService_NotifySnooze(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	sender := newFakePushSender()
+	service := NewPushService(store, sender)
+	snoozes, _ := newTestSnoozeService(store)
+	service.SetSnoozeService(snoozes)
+
+	for i, email := range []string{"a@example.com", "b@example.com"} {
+		p256dh, auth := testSubscriptionKeys(t)
+		if _, err := service.Subscribe(email, fmt.Sprintf("https://push.example.com/%d", i), p256dh, auth, ""); err != nil {
+			t.Fatalf("Failed to subscribe: %v", err)
+		}
+	}
+	if _, err := snoozes.Snooze(snoozes.Token(models.DefaultPlantID, "b@example.com", PushChannel)); err != nil {
+		t.Fatalf("Failed to snooze: %v", err)
+	}
+
+	plant := &models.PlantState{ID: models.DefaultPlantID, Name: "Fern"}
+	if delivered := service.Notify(context.Background(), plant, models.NotificationTriggerCritical); delivered != 1 {
+		t.Fatalf("Expected the snoozed user to be skipped, got %d deliveries", delivered)
+	}
+
+	var payload pushPayload
+	if err := json.Unmarshal(sender.sent["https://push.example.com/0"][0], &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.SnoozeTitle != "Remind me again in 3 hours" || !strings.HasPrefix(payload.SnoozeURL, "https://plants.example.com/api/v1/snooze?token=") {
+		t.Errorf("Expected a snooze action, got %+v", payload)
+	}
+	if snooze, err := snoozes.Snooze(snoozeToken(t, payload.SnoozeURL)); err != nil || snooze.Email != "a@example.com" {
+		t.Errorf("Expected the link to snooze a@example.com, got %+v, %v", snooze, err)
+	}
+}
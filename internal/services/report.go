@@ -0,0 +1,133 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/notify/email"
+	"watered/internal/stats"
+)
+
+// WeeklyReport summarises the waterings of the last seven days: how many
+// there were, the longest any plant went without water and who watered most
+func (s *PlantService) WeeklyReport() (*stats.WeeklyReport, error) {
+	s, span := s.trace("PlantService.WeeklyReport")
+	defer span.End()
+
+	events, err := s.storage.ListWateringEvents(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watering events: %w", err)
+	}
+	plants, err := s.storage.ListPlants()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plants: %w", err)
+	}
+
+	report := stats.Weekly(events, plants, s.Location(), time.Now())
+	if report.MostActive != nil {
+		if user, err := s.storage.GetUser(report.MostActive.Email); err == nil && user != nil {
+			report.MostActive.Name = user.Name
+		}
+	}
+	return report, nil
+}
+
+// ReportSummary is the one-line summary of a weekly report, such as "This
+// week: 5 waterings, longest gap 31h (Fern), most active: Sam"
+func ReportSummary(report *stats.WeeklyReport) string {
+	parts := []string{fmt.Sprintf("This week: %d %s", report.Waterings, plural(report.Waterings, "watering", "waterings"))}
+	if report.LongestGapHours != nil {
+		gap := "longest gap " + formatHours(*report.LongestGapHours)
+		if len(report.Plants) > 1 {
+			gap += " (" + report.LongestGapPlant + ")"
+		}
+		parts = append(parts, gap)
+	}
+	if report.MostActive != nil {
+		parts = append(parts, "most active: "+activeName(report.MostActive))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// RenderWeeklyReport renders a weekly report as the plain text and HTML
+// parts of an email
+func RenderWeeklyReport(report *stats.WeeklyReport) (text, html string, err error) {
+	var body strings.Builder
+	body.WriteString(ReportSummary(report) + ".\n\n")
+	for _, plant := range report.Plants {
+		fmt.Fprintf(&body, "- %s: %d %s", plant.Name, plant.Waterings, plural(plant.Waterings, "watering", "waterings"))
+		if plant.LongestGapHours != nil {
+			fmt.Fprintf(&body, ", longest gap %s", formatHours(*plant.LongestGapHours))
+		}
+		body.WriteString("\n")
+	}
+	fmt.Fprintf(&body, "\n%s to %s (%s)\n", report.Start.Format("Mon 2 Jan 15:04"), report.End.Format("Mon 2 Jan 15:04"), report.Timezone)
+
+	var buf bytes.Buffer
+	if err := weeklyReportTemplate.Execute(&buf, report); err != nil {
+		return "", "", fmt.Errorf("failed to render weekly report: %w", err)
+	}
+	return body.String(), buf.String(), nil
+}
+
+var weeklyReportTemplate = template.Must(template.New("weekly").Funcs(template.FuncMap{
+	"summary": ReportSummary,
+	"hours":   func(hours *float64) string { return formatHours(*hours) },
+	"date":    func(t time.Time) string { return t.Format("Mon 2 Jan 15:04") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #2c232d; background-color: #f8efe7; padding: 1rem;">
+<h2 style="color: #cd4770;">Your week with Watered</h2>
+<p>{{summary .}}.</p>
+<table style="border-collapse: collapse;">
+<tr><th style="text-align: left; padding: 0.25rem 1rem 0.25rem 0;">Plant</th><th style="text-align: right; padding: 0.25rem 1rem;">Waterings</th><th style="text-align: right; padding: 0.25rem 0;">Longest gap</th></tr>
+{{range .Plants}}<tr><td style="padding: 0.25rem 1rem 0.25rem 0;">{{.Name}}</td><td style="text-align: right; padding: 0.25rem 1rem;">{{.Waterings}}</td><td style="text-align: right; padding: 0.25rem 0;">{{if .LongestGapHours}}{{hours .LongestGapHours}}{{else}}-{{end}}</td></tr>
+{{end}}</table>
+<p style="color: #91898a; font-size: 0.9em;">{{date .Start}} to {{date .End}} ({{.Timezone}})</p>
+</body>
+</html>
+`))
+
+// SendWeeklyReport emails every allowed user a weekly report
+func (s *EmailService) SendWeeklyReport(report *stats.WeeklyReport) int {
+	text, html, err := RenderWeeklyReport(report)
+	if err != nil {
+		slog.Error("Failed to render weekly report", "error", err)
+		return 0
+	}
+
+	delivered := s.sendToRecipients(models.NotificationTriggerWeeklyReport, email.Message{Subject: "Your weekly Watered report", Body: text, HTML: html})
+	slog.Info("Sent weekly report emails", "delivered", delivered)
+	return delivered
+}
+
+// activeName is how a report names its most active user
+func activeName(user *stats.ActiveUser) string {
+	if user.Name != "" {
+		return user.Name
+	}
+	return user.Email
+}
+
+// formatHours formats a gap such as 31h, or 2d 7h from two days on
+func formatHours(hours float64) string {
+	whole := int(hours + 0.5)
+	if whole < 48 {
+		return strconv.Itoa(whole) + "h"
+	}
+	return fmt.Sprintf("%dd %dh", whole/24, whole%24)
+}
+
+// plural picks the singular or plural form for n
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/stats"
+	"watered/internal/storage"
+)
+
+func TestPlantService_WeeklyReport(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service := NewPlantService(store)
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, Timezone: "UTC", AllowedEmails: []string{"sam@example.com"}})
+	store.CreateUser(&models.User{Email: "sam@example.com", Name: "Sam"})
+
+	now := time.Now()
+	for _, hours := range []int{100, 69, 40, 9, 5} {
+		if _, err := service.WaterPlantByIDAt(models.DefaultPlantID, "sam@example.com", now.Add(-time.Duration(hours)*time.Hour)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	report, err := service.WeeklyReport()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if summary := ReportSummary(report); summary != "This week: 5 waterings, longest gap 31h, most active: Sam" {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+}
+
+func TestEmailService_SendWeeklyReport(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, AllowedEmails: []string{"a@example.com", "b@example.com"}})
+
+	sender := &fakeEmailSender{}
+	service := NewEmailService(store, sender)
+
+	gap := 52.0
+	end := time.Date(2024, 6, 12, 20, 0, 0, 0, time.UTC)
+	report := &stats.WeeklyReport{
+		Start:           end.Add(-stats.ReportWindow),
+		End:             end,
+		Timezone:        "UTC",
+		Waterings:       1,
+		LongestGapHours: &gap,
+		LongestGapPlant: "<Fern>",
+		MostActive:      &stats.ActiveUser{Email: "a@example.com", Waterings: 1},
+		Plants: []*stats.PlantWeek{
+			{PlantID: 1, Name: "<Fern>", Waterings: 1, LongestGapHours: &gap},
+			{PlantID: 2, Name: "Cactus"},
+		},
+	}
+
+	if delivered := service.SendWeeklyReport(report); delivered != 2 {
+		t.Fatalf("Expected 2 reports, got %d", delivered)
+	}
+
+	msg := sender.messages[0]
+	if msg.Subject != "Your weekly Watered report" {
+		t.Errorf("Unexpected subject %q", msg.Subject)
+	}
+	for _, expected := range []string{
+		"This week: 1 watering, longest gap 2d 4h (<Fern>), most active: a@example.com.",
+		"- <Fern>: 1 watering, longest gap 2d 4h\n- Cactus: 0 waterings\n",
+		"Wed 5 Jun 20:00 to Wed 12 Jun 20:00 (UTC)",
+	} {
+		if !strings.Contains(msg.Body, expected) {
+			t.Errorf("Expected text body to contain %q, got %q", expected, msg.Body)
+		}
+	}
+	if !strings.Contains(msg.HTML, "<td style=\"padding: 0.25rem 1rem 0.25rem 0;\">&lt;Fern&gt;</td>") || strings.Contains(msg.HTML, "<Fern>") {
+		t.Errorf("Expected plant names to be escaped in the HTML body, got %q", msg.HTML)
+	}
+
+	notifications, err := store.ListNotifications(models.NotificationFilter{Channel: EmailChannel})
+	if err != nil || len(notifications) != 2 || notifications[0].Trigger != models.NotificationTriggerWeeklyReport {
+		t.Errorf("Expected both deliveries in the notification history, got %+v (%v)", notifications, err)
+	}
+}
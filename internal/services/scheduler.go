@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"watered/internal/models"
+)
+
+// DefaultNotificationCheckInterval is how often the scheduler checks plant status
+const DefaultNotificationCheckInterval = 5 * time.Minute
+
+// PlantNotifier sends reminders on one channel when a plant reaches a milestone
+type PlantNotifier interface {
+	// Trigger returns the milestone the notifier reports for the plant's current state
+	Trigger(plant *models.PlantState) models.NotificationTrigger
+	// Notify sends a reminder about a plant and returns the number of deliveries
+	Notify(ctx context.Context, plant *models.PlantState, trigger models.NotificationTrigger) int
+}
+
+// NotificationScheduler periodically checks every plant and asks each
+// notifier to send a reminder when the plant reaches a new milestone.
+// Each milestone is notified once; watering the plant re-arms it.
+type NotificationScheduler struct {
+	plantService *PlantService
+	notifiers    []PlantNotifier
+	interval     time.Duration
+
+	mu          sync.Mutex
+	lastTrigger []map[int]models.NotificationTrigger
+}
+
+// NewNotificationScheduler creates a new notification scheduler
+func NewNotificationScheduler(plantService *PlantService, interval time.Duration, notifiers ...PlantNotifier) *NotificationScheduler {
+	if interval <= 0 {
+		interval = DefaultNotificationCheckInterval
+	}
+
+	lastTrigger := make([]map[int]models.NotificationTrigger, len(notifiers))
+	for i := range lastTrigger {
+		lastTrigger[i] = make(map[int]models.NotificationTrigger)
+	}
+
+	return &NotificationScheduler{
+		plantService: plantService,
+		notifiers:    notifiers,
+		interval:     interval,
+		lastTrigger:  lastTrigger,
+	}
+}
+
+// Interval returns how often CheckOnce should run
+func (s *NotificationScheduler) Interval() time.Duration {
+	return s.interval
+}
+
+// pendingNotification is a milestone a notifier has not reported yet
+type pendingNotification struct {
+	notifier PlantNotifier
+	plant    *models.PlantState
+	trigger  models.NotificationTrigger
+}
+
+// CheckOnce checks all plants and notifies about newly reached milestones.
+// The first check only records each plant's state so a restart does not
+// repeat notifications. It returns the number of deliveries.
+func (s *NotificationScheduler) CheckOnce(ctx context.Context) int {
+	plants, err := s.plantService.ListPlants()
+	if err != nil {
+		slog.Error("Notification scheduler failed to list plants", "error", err)
+		return 0
+	}
+
+	s.mu.Lock()
+	var pending []pendingNotification
+	for i, notifier := range s.notifiers {
+		seen := make(map[int]bool, len(plants))
+		for _, plant := range plants {
+			seen[plant.ID] = true
+			trigger := notifier.Trigger(plant)
+			previous, known := s.lastTrigger[i][plant.ID]
+			s.lastTrigger[i][plant.ID] = trigger
+
+			if known && trigger != previous && trigger != models.NotificationTriggerNone {
+				pending = append(pending, pendingNotification{notifier: notifier, plant: plant, trigger: trigger})
+			}
+		}
+		for id := range s.lastTrigger[i] {
+			if !seen[id] {
+				delete(s.lastTrigger[i], id)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	delivered := 0
+	for _, notification := range pending {
+		delivered += notification.notifier.Notify(ctx, notification.plant, notification.trigger)
+	}
+	return delivered
+}
+
+// DigestScheduler emails a daily summary of all plants at a fixed local hour
+type DigestScheduler struct {
+	plantService *PlantService
+	emailService *EmailService
+	hour         int
+}
+
+// NewDigestScheduler creates a scheduler that sends the digest every day at hour (0-23)
+func NewDigestScheduler(plantService *PlantService, emailService *EmailService, hour int) *DigestScheduler {
+	return &DigestScheduler{
+		plantService: plantService,
+		emailService: emailService,
+		hour:         hour,
+	}
+}
+
+// Next returns when the digest is sent next, so the scheduler can be used as
+// a job schedule
+func (s *DigestScheduler) Next(after time.Time) time.Time {
+	return nextDigestTime(after, s.hour)
+}
+
+func (s *DigestScheduler) String() string {
+	return fmt.Sprintf("daily at %02d:00", s.hour)
+}
+
+// SendOnce emails the digest immediately and returns the number of deliveries
+func (s *DigestScheduler) SendOnce() int {
+	plants, err := s.plantService.ListPlants()
+	if err != nil {
+		slog.Error("Digest scheduler failed to list plants", "error", err)
+		return 0
+	}
+	return s.emailService.SendDigest(plants)
+}
+
+// nextDigestTime returns the next occurrence of hour:00 strictly after now
+func nextDigestTime(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// WeeklyReportScheduler emails a summary of the week's waterings on a fixed
+// day and local hour
+type WeeklyReportScheduler struct {
+	plantService *PlantService
+	emailService *EmailService
+	day          time.Weekday
+	hour         int
+}
+
+// NewWeeklyReportScheduler creates a scheduler that sends the weekly report
+// every week on day at hour (0-23)
+func NewWeeklyReportScheduler(plantService *PlantService, emailService *EmailService, day time.Weekday, hour int) *WeeklyReportScheduler {
+	return &WeeklyReportScheduler{
+		plantService: plantService,
+		emailService: emailService,
+		day:          day,
+		hour:         hour,
+	}
+}
+
+// Next returns when the report is sent next, so the scheduler can be used as
+// a job schedule
+func (s *WeeklyReportScheduler) Next(after time.Time) time.Time {
+	next := nextDigestTime(after, s.hour)
+	for next.Weekday() != s.day {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func (s *WeeklyReportScheduler) String() string {
+	return fmt.Sprintf("%ss at %02d:00", s.day, s.hour)
+}
+
+// SendOnce emails the report immediately and returns the number of
+// deliveries. With privacy mode on, the report does not say who watered most.
+func (s *WeeklyReportScheduler) SendOnce() int {
+	report, err := s.plantService.WeeklyReport()
+	if err != nil {
+		slog.Error("Weekly report scheduler failed to build the report", "error", err)
+		return 0
+	}
+	if s.plantService.IsPrivacyModeEnabled() {
+		report.MostActive = nil
+	}
+	return s.emailService.SendWeeklyReport(report)
+}
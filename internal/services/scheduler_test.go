@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func TestNotificationScheduler_CheckOnce(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	sender := newFakePushSender()
+	plantService := NewPlantService(store)
+	pushService := NewPushService(store, sender)
+	scheduler := NewNotificationScheduler(plantService, time.Minute, pushService)
+
+	p256dh, auth := testSubscriptionKeys(t)
+	if _, err := pushService.Subscribe("test@example.com", "https://push.example.com/1", p256dh, auth, ""); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	setLastWatered := func(hoursAgo float64) {
+		t.Helper()
+		wateredAt := time.Now().Add(-time.Duration(hoursAgo * float64(time.Hour)))
+		store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Fern", TimeoutHours: 24, LastWatered: &wateredAt})
+	}
+
+	// The first check only records the current status
+	setLastWatered(1)
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 0 {
+		t.Errorf("Expected no notification on first check, got %d", delivered)
+	}
+
+	// Crossing into needs_water notifies once
+	setLastWatered(13)
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 1 {
+		t.Errorf("Expected needs_water notification, got %d", delivered)
+	}
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 0 {
+		t.Errorf("Expected no repeat notification, got %d", delivered)
+	}
+
+	// Crossing into critical notifies again
+	setLastWatered(30)
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 1 {
+		t.Errorf("Expected critical notification, got %d", delivered)
+	}
+
+	var payload pushPayload
+	if err := json.Unmarshal(sender.sent["https://push.example.com/1"][1], &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.Status != models.HealthStatusCritical || payload.PlantID != 1 || payload.URL == "" {
+		t.Errorf("Unexpected critical payload: %+v", payload)
+	}
+
+	// Watering re-arms the notification
+	setLastWatered(0)
+	scheduler.CheckOnce(context.Background())
+	setLastWatered(13)
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 1 {
+		t.Errorf("Expected notification after re-arming, got %d", delivered)
+	}
+
+	if sender.count() != 3 {
+		t.Errorf("Expected 3 deliveries in total, got %d", sender.count())
+	}
+}
+
+func TestNotificationScheduler_Vacation(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	sender := newFakePushSender()
+	plantService := NewPlantService(store)
+	pushService := NewPushService(store, sender)
+	scheduler := NewNotificationScheduler(plantService, time.Minute, pushService)
+
+	p256dh, auth := testSubscriptionKeys(t)
+	if _, err := pushService.Subscribe("test@example.com", "https://push.example.com/1", p256dh, auth, ""); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	wateredAt := time.Now().Add(-time.Hour)
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Fern", TimeoutHours: 24, LastWatered: &wateredAt})
+	scheduler.CheckOnce(context.Background())
+
+	// No reminders while the household is away
+	wateredAt = time.Now().Add(-30 * time.Hour)
+	vacation := &models.Vacation{Start: time.Now().Add(-time.Hour), End: time.Now().Add(time.Hour)}
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Fern", TimeoutHours: 24, LastWatered: &wateredAt, Vacation: vacation})
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 0 {
+		t.Errorf("Expected no notification during the vacation, got %d", delivered)
+	}
+
+	// Reminders resume once it is over
+	vacation.End = time.Now().Add(-time.Minute)
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Fern", TimeoutHours: 24, LastWatered: &wateredAt, Vacation: vacation})
+	if delivered := scheduler.CheckOnce(context.Background()); delivered != 1 {
+		t.Errorf("Expected a critical notification after the vacation, got %d", delivered)
+	}
+}
+
+func TestNotificationScheduler_MultipleNotifiers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, AllowedEmails: []string{"a@example.com", "b@example.com"}})
+
+	pushSender := newFakePushSender()
+	emailSender := &fakeEmailSender{}
+	plantService := NewPlantService(store)
+	pushService := NewPushService(store, pushSender)
+	emailService := NewEmailService(store, emailSender)
+	scheduler := NewNotificationScheduler(plantService, time.Minute, pushService, emailService)
+
+	p256dh, auth := testSubscriptionKeys(t)
+	if _, err := pushService.Subscribe("a@example.com", "https://push.example.com/1", p256dh, auth, ""); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	setLastWatered := func(hoursAgo float64) {
+		t.Helper()
+		wateredAt := time.Now().Add(-time.Duration(hoursAgo * float64(time.Hour)))
+		store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Fern", TimeoutHours: 24, GracePeriodHours: 12, LastWatered: &wateredAt})
+	}
+
+	setLastWatered(1)
+	scheduler.CheckOnce(context.Background())
+
+	// Needs water only reaches push subscribers
+	setLastWatered(13)
+	scheduler.CheckOnce(context.Background())
+	if pushSender.count() != 1 || len(emailSender.messages) != 0 {
+		t.Errorf("Expected 1 push and no emails, got %d pushes and %d emails", pushSender.count(), len(emailSender.messages))
+	}
+
+	// Overdue emails every allowed user
+	setLastWatered(25)
+	scheduler.CheckOnce(context.Background())
+	if pushSender.count() != 1 || len(emailSender.messages) != 2 {
+		t.Errorf("Expected 1 push and 2 emails, got %d pushes and %d emails", pushSender.count(), len(emailSender.messages))
+	}
+
+	// Critical reaches both channels
+	setLastWatered(40)
+	scheduler.CheckOnce(context.Background())
+	if pushSender.count() != 2 || len(emailSender.messages) != 4 {
+		t.Errorf("Expected 2 pushes and 4 emails, got %d pushes and %d emails", pushSender.count(), len(emailSender.messages))
+	}
+}
+
+func TestNextDigestTime(t *testing.T) {
+	loc := time.UTC
+	tests := []struct {
+		name     string
+		now      time.Time
+		hour     int
+		expected time.Time
+	}{
+		{"later today", time.Date(2024, 3, 1, 6, 30, 0, 0, loc), 8, time.Date(2024, 3, 1, 8, 0, 0, 0, loc)},
+		{"already passed", time.Date(2024, 3, 1, 9, 0, 0, 0, loc), 8, time.Date(2024, 3, 2, 8, 0, 0, 0, loc)},
+		{"exactly now", time.Date(2024, 3, 1, 8, 0, 0, 0, loc), 8, time.Date(2024, 3, 2, 8, 0, 0, 0, loc)},
+		{"end of month", time.Date(2024, 2, 29, 23, 0, 0, 0, loc), 0, time.Date(2024, 3, 1, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextDigestTime(tt.now, tt.hour); !got.Equal(tt.expected) {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWeeklyReportScheduler_Next(t *testing.T) {
+	loc := time.UTC
+	schedule := NewWeeklyReportScheduler(nil, nil, time.Monday, 8)
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected time.Time
+	}{
+		// March 4 2024 is a Monday
+		{"later on the day", time.Date(2024, 3, 4, 6, 0, 0, 0, loc), time.Date(2024, 3, 4, 8, 0, 0, 0, loc)},
+		{"already passed", time.Date(2024, 3, 4, 8, 0, 0, 0, loc), time.Date(2024, 3, 11, 8, 0, 0, 0, loc)},
+		{"mid week", time.Date(2024, 3, 7, 12, 0, 0, 0, loc), time.Date(2024, 3, 11, 8, 0, 0, 0, loc)},
+		{"day before", time.Date(2024, 3, 10, 23, 0, 0, 0, loc), time.Date(2024, 3, 11, 8, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schedule.Next(tt.now); !got.Equal(tt.expected) {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+	if schedule.String() != "Mondays at 08:00" {
+		t.Errorf("Unexpected schedule description %q", schedule.String())
+	}
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+// MaxSearchQueryLength is the longest search query accepted
+const MaxSearchQueryLength = 100
+
+// ErrInvalidSearchQuery is returned for empty or overlong search queries
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// SearchResultKind names what a search result points at
+type SearchResultKind string
+
+const (
+	SearchResultPlant        SearchResultKind = "plant"
+	SearchResultNotification SearchResultKind = "notification"
+)
+
+// Field weights, so a match on a plant's name ranks above the same match in
+// a custom field or the notification history
+const (
+	searchWeightName     = 3
+	searchWeightMetadata = 2
+	searchWeightWaterer  = 2
+	searchWeightEvent    = 1
+)
+
+// SearchQuery describes a search and which fields the caller may match on.
+// Fields the caller may not see are never matched, so results cannot reveal
+// them.
+type SearchQuery struct {
+	Text   string
+	Limit  int
+	Offset int
+
+	// IncludeMetadata matches plants' custom fields
+	IncludeMetadata bool
+	// IncludeWaterers matches who last watered each plant
+	IncludeWaterers bool
+	// NotificationEmail limits notification matches to one user's history
+	NotificationEmail string
+	// AllNotifications matches every user's notification history
+	AllNotifications bool
+}
+
+// SearchResult is one matching plant or notification
+type SearchResult struct {
+	Kind  SearchResultKind `json:"kind"`
+	ID    int              `json:"id"`
+	Title string           `json:"title"`
+	// Field names the best matching field, e.g. "name" or "metadata.location"
+	Field string `json:"field"`
+	// Match is the value of the matching field
+	Match     string    `json:"match"`
+	Score     int       `json:"score"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SearchResults is one page of results and the total number of matches
+type SearchResults struct {
+	Results []SearchResult
+	Total   int
+}
+
+// SearchService searches plants and notification history
+type SearchService struct {
+	storage storage.Storage
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(storage storage.Storage) *SearchService {
+	return &SearchService{
+		storage: storage,
+	}
+}
+
+// matchScore rates how well value matches the lowercased term: 3 for the
+// whole value, 2 for a prefix, 1 anywhere else, 0 for no match
+func matchScore(value, term string) int {
+	value = strings.ToLower(value)
+	switch {
+	case value == term:
+		return 3
+	case strings.HasPrefix(value, term):
+		return 2
+	case strings.Contains(value, term):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// consider keeps the field as the result's match if it matches better than
+// the best field so far
+func (r *SearchResult) consider(term, field, value string, weight int) {
+	if score := matchScore(value, term) * weight; score > r.Score {
+		r.Score = score
+		r.Field = field
+		r.Match = value
+	}
+}
+
+// Search matches the query case-insensitively against plant names, custom
+// fields, waterers and notification history as allowed by the query. Results
+// are ordered by relevance, then newest first.
+func (s *SearchService) Search(query SearchQuery) (*SearchResults, error) {
+	term := strings.ToLower(strings.TrimSpace(query.Text))
+	if term == "" {
+		return nil, fmt.Errorf("%w: query cannot be empty", ErrInvalidSearchQuery)
+	}
+	if len(term) > MaxSearchQueryLength {
+		return nil, fmt.Errorf("%w: query cannot exceed %d characters", ErrInvalidSearchQuery, MaxSearchQueryLength)
+	}
+
+	var matches []SearchResult
+
+	plants, err := s.storage.ListPlants()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plants: %w", err)
+	}
+	for _, plant := range plants {
+		result := SearchResult{
+			Kind:      SearchResultPlant,
+			ID:        plant.ID,
+			Title:     plant.Name,
+			Timestamp: plant.UpdatedAt,
+		}
+		result.consider(term, "name", plant.Name, searchWeightName)
+		if query.IncludeMetadata {
+			keys := make([]string, 0, len(plant.Metadata))
+			for key := range plant.Metadata {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				result.consider(term, "metadata."+key, plant.Metadata[key].Value, searchWeightMetadata)
+			}
+		}
+		if query.IncludeWaterers && plant.WateredBy != "" {
+			result.consider(term, "watered_by", plant.WateredBy, searchWeightWaterer)
+		}
+		if result.Score > 0 {
+			matches = append(matches, result)
+		}
+	}
+
+	if query.AllNotifications || query.NotificationEmail != "" {
+		filter := models.NotificationFilter{}
+		if !query.AllNotifications {
+			filter.UserEmail = query.NotificationEmail
+		}
+		notifications, err := s.storage.ListNotifications(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list notifications: %w", err)
+		}
+		for _, notification := range notifications {
+			result := SearchResult{
+				Kind:      SearchResultNotification,
+				ID:        notification.ID,
+				Title:     notification.Summary,
+				Timestamp: notification.CreatedAt,
+			}
+			result.consider(term, "summary", notification.Summary, searchWeightEvent)
+			result.consider(term, "user_email", notification.UserEmail, searchWeightEvent)
+			result.consider(term, "channel", notification.Channel, searchWeightEvent)
+			result.consider(term, "trigger", string(notification.Trigger), searchWeightEvent)
+			result.consider(term, "status", string(notification.Status), searchWeightEvent)
+			if result.Score > 0 {
+				matches = append(matches, result)
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.After(b.Timestamp)
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.ID < b.ID
+	})
+
+	results := &SearchResults{Results: []SearchResult{}, Total: len(matches)}
+	if query.Offset < len(matches) {
+		end := len(matches)
+		if query.Limit > 0 && query.Offset+query.Limit < end {
+			end = query.Offset + query.Limit
+		}
+		results.Results = matches[query.Offset:end]
+	}
+	return results, nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+// newSearchFixture stores three plants, one of them only matching "fern" by
+// its custom field and waterer, and notifications for two users
+func newSearchFixture(t *testing.T) (*SearchService, storage.Storage) {
+	t.Helper()
+	store := storage.NewMemoryStorage()
+	t.Cleanup(func() { store.Close() })
+
+	plantService := NewPlantService(store)
+	var ficus *models.PlantState
+	for _, name := range []string{"Fern", "Boston Fern", "Ficus"} {
+		plant, err := plantService.CreatePlant(name, 24)
+		if err != nil {
+			t.Fatalf("Failed to create plant: %v", err)
+		}
+		ficus = plant
+	}
+	if _, err := plantService.UpdateMetadataByID(ficus.ID, map[string]models.MetadataValue{
+		"location": {Value: "Fernando's desk"},
+	}); err != nil {
+		t.Fatalf("Failed to set metadata: %v", err)
+	}
+	if _, err := plantService.WaterPlantByID(ficus.ID, "fern.lover@example.com"); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+
+	notifications := NewNotificationService(store)
+	notifications.Record("fern.lover@example.com", "email", models.NotificationTriggerDue, "Fern is due", nil)
+	notifications.Record("other@example.com", "push", models.NotificationTriggerCritical, "Fern is critical", nil)
+
+	return NewSearchService(store), store
+}
+
+func TestSearchService_Search(t *testing.T) {
+	service, _ := newSearchFixture(t)
+
+	results, err := service.Search(SearchQuery{Text: "  FERN "})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	// Without extra scopes only plant names match: exact before prefix
+	// before substring
+	if results.Total != 2 {
+		t.Fatalf("Expected 2 matches, got %d: %+v", results.Total, results.Results)
+	}
+	if results.Results[0].Title != "Fern" || results.Results[0].Field != "name" {
+		t.Errorf("Expected the exact name match first, got %+v", results.Results[0])
+	}
+	if results.Results[1].Title != "Boston Fern" {
+		t.Errorf("Expected the substring match second, got %+v", results.Results[1])
+	}
+}
+
+func TestSearchService_Scopes(t *testing.T) {
+	service, _ := newSearchFixture(t)
+
+	tests := []struct {
+		name     string
+		query    SearchQuery
+		expected int
+		field    string
+	}{
+		{"names only", SearchQuery{}, 2, ""},
+		{"custom fields", SearchQuery{IncludeMetadata: true}, 3, "metadata.location"},
+		{"waterers", SearchQuery{IncludeWaterers: true}, 3, "watered_by"},
+		{"own notifications", SearchQuery{NotificationEmail: "fern.lover@example.com"}, 3, "summary"},
+		{"all notifications", SearchQuery{AllNotifications: true}, 4, "summary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Text = "fern"
+			results, err := service.Search(tt.query)
+			if err != nil {
+				t.Fatalf("Failed to search: %v", err)
+			}
+			if results.Total != tt.expected {
+				t.Errorf("Expected %d matches, got %d: %+v", tt.expected, results.Total, results.Results)
+			}
+			if tt.field == "" {
+				return
+			}
+			found := false
+			for _, result := range results.Results {
+				found = found || result.Field == tt.field
+			}
+			if !found {
+				t.Errorf("Expected a match on %s, got %+v", tt.field, results.Results)
+			}
+		})
+	}
+}
+
+func TestSearchService_Pagination(t *testing.T) {
+	service, _ := newSearchFixture(t)
+	query := SearchQuery{Text: "fern", AllNotifications: true, IncludeMetadata: true, IncludeWaterers: true, Limit: 2}
+
+	first, err := service.Search(query)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	query.Offset = 2
+	second, err := service.Search(query)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	if first.Total != 5 || second.Total != 5 {
+		t.Errorf("Expected 5 matches in total, got %d and %d", first.Total, second.Total)
+	}
+	if len(first.Results) != 2 || len(second.Results) != 2 {
+		t.Fatalf("Expected pages of 2, got %d and %d", len(first.Results), len(second.Results))
+	}
+	if first.Results[1].Score < second.Results[0].Score {
+		t.Errorf("Expected pages in relevance order, got %+v then %+v", first.Results, second.Results)
+	}
+
+	query.Offset = 10
+	past, err := service.Search(query)
+	if err != nil || len(past.Results) != 0 {
+		t.Errorf("Expected an empty page past the end, got %+v (%v)", past, err)
+	}
+}
+
+func TestSearchService_InvalidQuery(t *testing.T) {
+	service, _ := newSearchFixture(t)
+
+	for _, text := range []string{"", "   ", strings.Repeat("a", MaxSearchQueryLength+1)} {
+		if _, err := service.Search(SearchQuery{Text: text}); !errors.Is(err, ErrInvalidSearchQuery) {
+			t.Errorf("Expected ErrInvalidSearchQuery for %q, got %v", text, err)
+		}
+	}
+}
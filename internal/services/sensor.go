@@ -0,0 +1,211 @@
+package services
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+var (
+	// ErrUnknownSensor is returned for readings from a device that is neither
+	// configured nor registered
+	ErrUnknownSensor = errors.New("unknown sensor device")
+	// ErrInvalidSensorToken is returned when a device's token does not match
+	ErrInvalidSensorToken = errors.New("invalid sensor token")
+	// ErrInvalidSensorReading is returned for readings with no or out of
+	// range values
+	ErrInvalidSensorReading = errors.New("invalid sensor reading")
+)
+
+// Plausible soil temperatures in degrees Celsius, the range cheap sensors report
+const (
+	minSoilTemperature = -40
+	maxSoilTemperature = 85
+)
+
+// wateringLookback is how far back a moisture rise is measured from
+const wateringLookback = time.Hour
+
+// SensorService records readings from soil sensors placed in plant pots.
+// Each device is configured in SENSOR_DEVICES or registered by an admin with
+// the plant it sits in and a token it authenticates with. When moisture
+// jumps, the service records a watering.
+type SensorService struct {
+	storage          storage.Storage
+	plantService     *PlantService
+	deviceService    *DeviceService
+	devices          map[string]config.SensorDevice
+	wateringRise     int
+	wateringCooldown time.Duration
+	now              func() time.Time
+}
+
+// NewSensorService creates a sensor service for the configured and
+// registered devices
+func NewSensorService(storage storage.Storage, plantService *PlantService, deviceService *DeviceService, cfg config.SensorConfig) *SensorService {
+	return &SensorService{
+		storage:          storage,
+		plantService:     plantService,
+		deviceService:    deviceService,
+		devices:          cfg.Devices,
+		wateringRise:     cfg.WateringRise,
+		wateringCooldown: cfg.WateringCooldown,
+		now:              time.Now,
+	}
+}
+
+// Enabled reports whether any sensor is configured or registered
+func (s *SensorService) Enabled() bool {
+	return len(s.devices) > 0 || s.deviceService.Has(models.DeviceKindSensor)
+}
+
+// authenticate checks a device's token and returns the plant it sits in.
+// Devices in SENSOR_DEVICES take precedence over registered ones.
+func (s *SensorService) authenticate(deviceID, token string) (int, error) {
+	if device, ok := s.devices[deviceID]; ok {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(device.Token)) != 1 {
+			return 0, ErrInvalidSensorToken
+		}
+		return device.PlantID, nil
+	}
+
+	device, err := s.deviceService.Authenticate(deviceID, models.DeviceKindSensor, token)
+	switch {
+	case errors.Is(err, ErrDeviceNotFound):
+		return 0, ErrUnknownSensor
+	case errors.Is(err, ErrInvalidDeviceToken):
+		return 0, ErrInvalidSensorToken
+	case err != nil:
+		return 0, err
+	}
+	return device.PlantID, nil
+}
+
+// Record checks the device's token, validates the reading and stores it
+// against the device's plant. A reading without a time is recorded now.
+func (s *SensorService) Record(deviceID, token string, reading *models.SensorReading) error {
+	plantID, err := s.authenticate(deviceID, token)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	if reading.RecordedAt.IsZero() {
+		reading.RecordedAt = now
+	}
+	if err := validateSensorReading(reading, now); err != nil {
+		return err
+	}
+
+	reading.DeviceID = deviceID
+	reading.PlantID = plantID
+	if err := s.storage.AddSensorReading(reading); err != nil {
+		return fmt.Errorf("failed to store sensor reading: %w", err)
+	}
+	slog.Debug("Recorded sensor reading", "device", deviceID, "plant_id", plantID)
+
+	s.detectWatering(reading)
+	return nil
+}
+
+// detectWatering records a watering by the sensor when moisture rose by at
+// least the configured number of points within the last hour. Nothing is
+// recorded within the cooldown of another watering, whoever recorded it, so
+// a slow soak or a noisy sensor does not add the same watering twice.
+func (s *SensorService) detectWatering(reading *models.SensorReading) {
+	if s.wateringRise <= 0 || reading.Moisture == nil {
+		return
+	}
+
+	earlier, err := s.storage.ListSensorReadings(models.SensorReadingFilter{
+		DeviceID: reading.DeviceID,
+		Since:    reading.RecordedAt.Add(-wateringLookback),
+	})
+	if err != nil {
+		slog.Error("Failed to list sensor readings", "device", reading.DeviceID, "error", err)
+		return
+	}
+	lowest := *reading.Moisture
+	for _, previous := range earlier {
+		if previous.Moisture != nil && previous.RecordedAt.Before(reading.RecordedAt) && *previous.Moisture < lowest {
+			lowest = *previous.Moisture
+		}
+	}
+	if *reading.Moisture-lowest < float64(s.wateringRise) {
+		return
+	}
+
+	plant, err := s.plantService.GetPlantByID(reading.PlantID)
+	if err != nil {
+		slog.Error("Failed to get plant for sensor watering", "plant_id", reading.PlantID, "error", err)
+		return
+	}
+	if plant.LastWatered != nil {
+		since := reading.RecordedAt.Sub(*plant.LastWatered)
+		if since < s.wateringCooldown && since > -s.wateringCooldown {
+			slog.Debug("Ignoring moisture rise close to another watering", "plant_id", plant.ID, "device", reading.DeviceID)
+			return
+		}
+	}
+
+	if _, err := s.plantService.WaterPlantByIDAt(plant.ID, models.SensorWaterer, reading.RecordedAt); err != nil {
+		slog.Error("Failed to record sensor watering", "plant_id", plant.ID, "device", reading.DeviceID, "error", err)
+		return
+	}
+	slog.Info("Sensor detected a watering", "plant_id", plant.ID, "device", reading.DeviceID, "from", lowest, "to", *reading.Moisture)
+}
+
+// sensorMessage is the JSON payload a sensor publishes over MQTT. Brokers
+// are often shared, so it carries the device's token like the HTTP path.
+type sensorMessage struct {
+	Token       string    `json:"token"`
+	Moisture    *float64  `json:"moisture"`
+	Temperature *float64  `json:"temperature"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+// RecordMessage records a reading published by a sensor over MQTT
+func (s *SensorService) RecordMessage(deviceID string, payload []byte) error {
+	var msg sensorMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return fmt.Errorf("%w: payload is not a JSON object", ErrInvalidSensorReading)
+	}
+	return s.Record(deviceID, msg.Token, &models.SensorReading{
+		Moisture:    msg.Moisture,
+		Temperature: msg.Temperature,
+		RecordedAt:  msg.RecordedAt,
+	})
+}
+
+// validateSensorReading rejects readings without values, with values a soil
+// sensor cannot report, or from the future
+func validateSensorReading(reading *models.SensorReading, now time.Time) error {
+	if reading.Moisture == nil && reading.Temperature == nil {
+		return fmt.Errorf("%w: moisture or temperature is required", ErrInvalidSensorReading)
+	}
+	if reading.Moisture != nil && (*reading.Moisture < 0 || *reading.Moisture > 100) {
+		return fmt.Errorf("%w: moisture must be between 0 and 100", ErrInvalidSensorReading)
+	}
+	if reading.Temperature != nil && (*reading.Temperature < minSoilTemperature || *reading.Temperature > maxSoilTemperature) {
+		return fmt.Errorf("%w: temperature must be between %d and %d", ErrInvalidSensorReading, minSoilTemperature, maxSoilTemperature)
+	}
+	if reading.RecordedAt.After(now.Add(time.Minute)) {
+		return fmt.Errorf("%w: recorded_at is in the future", ErrInvalidSensorReading)
+	}
+	return nil
+}
+
+// Recent returns a plant's readings recorded in the last window, newest first
+func (s *SensorService) Recent(plantID int, window time.Duration) ([]*models.SensorReading, error) {
+	return s.storage.ListSensorReadings(models.SensorReadingFilter{
+		PlantID: plantID,
+		Since:   s.now().Add(-window),
+	})
+}
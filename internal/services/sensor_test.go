@@ -0,0 +1,165 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func newTestSensorService(store storage.Storage) (*SensorService, time.Time) {
+	now := time.Now()
+	service := NewSensorService(store, NewPlantService(store), NewDeviceService(store, NewPlantService(store), config.SensorConfig{}), config.SensorConfig{
+		Devices:          map[string]config.SensorDevice{"kitchen": {PlantID: 2, Token: "kitchen-token"}},
+		WateringCooldown: 6 * time.Hour,
+	})
+	service.now = func() time.Time { return now }
+	return service, now
+}
+
+func TestSensorService_Record(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service, now := newTestSensorService(store)
+
+	moisture := 31.5
+	reading := &models.SensorReading{DeviceID: "spoofed", PlantID: 9, Moisture: &moisture}
+	if err := service.Record("kitchen", "kitchen-token", reading); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reading.ID != 1 || reading.DeviceID != "kitchen" || reading.PlantID != 2 || !reading.RecordedAt.Equal(now) {
+		t.Errorf("Expected the reading to be recorded now against the device's plant, got %+v", reading)
+	}
+
+	recent, err := service.Recent(2, time.Hour)
+	if err != nil || len(recent) != 1 || *recent[0].Moisture != 31.5 {
+		t.Errorf("Expected the recent reading, got %+v (%v)", recent, err)
+	}
+	if other, _ := service.Recent(1, time.Hour); len(other) != 0 {
+		t.Errorf("Expected no readings for another plant, got %+v", other)
+	}
+}
+
+func TestSensorService_RecordRejects(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service, now := newTestSensorService(store)
+
+	value := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name    string
+		device  string
+		token   string
+		reading models.SensorReading
+		want    error
+	}{
+		{"unknown device", "balcony", "kitchen-token", models.SensorReading{Moisture: value(40)}, ErrUnknownSensor},
+		{"wrong token", "kitchen", "balcony-token", models.SensorReading{Moisture: value(40)}, ErrInvalidSensorToken},
+		{"no values", "kitchen", "kitchen-token", models.SensorReading{}, ErrInvalidSensorReading},
+		{"moisture range", "kitchen", "kitchen-token", models.SensorReading{Moisture: value(101)}, ErrInvalidSensorReading},
+		{"temperature range", "kitchen", "kitchen-token", models.SensorReading{Temperature: value(-60)}, ErrInvalidSensorReading},
+		{"future", "kitchen", "kitchen-token", models.SensorReading{Moisture: value(40), RecordedAt: now.Add(time.Hour)}, ErrInvalidSensorReading},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.Record(tt.device, tt.token, &tt.reading); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if readings, _ := store.ListSensorReadings(models.SensorReadingFilter{}); len(readings) != 0 {
+		t.Errorf("Expected nothing to be stored, got %+v", readings)
+	}
+}
+
+func TestSensorService_RecordMessage(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service, _ := newTestSensorService(store)
+
+	if err := service.RecordMessage("kitchen", []byte(`{"token": "kitchen-token", "temperature": 18.5}`)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := service.RecordMessage("kitchen", []byte(`{"temperature": 18.5}`)); !errors.Is(err, ErrInvalidSensorToken) {
+		t.Errorf("Expected ErrInvalidSensorToken without a token, got %v", err)
+	}
+	if err := service.RecordMessage("kitchen", []byte(`18.5`)); !errors.Is(err, ErrInvalidSensorReading) {
+		t.Errorf("Expected ErrInvalidSensorReading for a bare value, got %v", err)
+	}
+
+	readings, _ := store.ListSensorReadings(models.SensorReadingFilter{DeviceID: "kitchen"})
+	if len(readings) != 1 || *readings[0].Temperature != 18.5 || readings[0].PlantID != 2 {
+		t.Errorf("Expected one reading for the kitchen plant, got %+v", readings)
+	}
+}
+
+func TestSensorService_DetectsWatering(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := NewPlantService(store)
+	service := NewSensorService(store, plantService, NewDeviceService(store, plantService, config.SensorConfig{}), config.SensorConfig{
+		Devices:          map[string]config.SensorDevice{"kitchen": {PlantID: models.DefaultPlantID, Token: "kitchen-token"}},
+		WateringRise:     20,
+		WateringCooldown: 6 * time.Hour,
+	})
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	record := func(moisture float64, ago time.Duration) {
+		t.Helper()
+		if err := service.Record("kitchen", "kitchen-token", &models.SensorReading{Moisture: &moisture, RecordedAt: now.Add(-ago)}); err != nil {
+			t.Fatalf("Failed to record reading: %v", err)
+		}
+	}
+
+	// A rise below the threshold is noise
+	record(20, 3*time.Hour)
+	record(35, 150*time.Minute)
+	if events, _ := store.ListWateringEvents(0); len(events) != 0 {
+		t.Fatalf("Expected no watering for a small rise, got %+v", events)
+	}
+
+	// Dry an hour ago, soaked now
+	record(22, 50*time.Minute)
+	record(45, 10*time.Minute)
+	events, _ := store.ListWateringEvents(0)
+	if len(events) != 1 || events[0].WateredBy != models.SensorWaterer || !events[0].WateredAt.Equal(now.Add(-10*time.Minute)) {
+		t.Fatalf("Expected one watering by the sensor, got %+v", events)
+	}
+	plant, _ := plantService.GetPlantByID(models.DefaultPlantID)
+	if plant.WateredBy != models.SensorWaterer || plant.LastWatered == nil {
+		t.Errorf("Expected the plant to be marked watered by the sensor, got %+v", plant)
+	}
+
+	// The soil keeps soaking up water, which is the same watering
+	record(70, 0)
+	if events, _ := store.ListWateringEvents(0); len(events) != 1 {
+		t.Errorf("Expected the cooldown to prevent a second watering, got %+v", events)
+	}
+}
+
+func TestSensorService_IgnoresRiseAfterManualWatering(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := NewPlantService(store)
+	service := NewSensorService(store, plantService, NewDeviceService(store, plantService, config.SensorConfig{}), config.SensorConfig{
+		Devices:          map[string]config.SensorDevice{"kitchen": {PlantID: models.DefaultPlantID, Token: "kitchen-token"}},
+		WateringRise:     20,
+		WateringCooldown: 6 * time.Hour,
+	})
+
+	if _, err := plantService.WaterPlantByID(models.DefaultPlantID, "alice@example.com"); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+	for _, moisture := range []float64{15, 60} {
+		service.Record("kitchen", "kitchen-token", &models.SensorReading{Moisture: &moisture})
+	}
+
+	if events, _ := store.ListWateringEvents(0); len(events) != 1 || events[0].WateredBy != "alice@example.com" {
+		t.Errorf("Expected only the manual watering, got %+v", events)
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+// SetupRequest holds the values collected by the first-run setup wizard
+type SetupRequest struct {
+	AdminEmail         string `json:"adminEmail"`
+	Timezone           string `json:"timezone"`
+	PlantName          string `json:"plantName"`
+	GoogleClientID     string `json:"googleClientId"`
+	GoogleClientSecret string `json:"googleClientSecret"`
+}
+
+// SetupService runs the one-time setup flow for a fresh instance
+type SetupService struct {
+	storage     storage.Storage
+	adminEmails []string
+	token       string
+	mu          sync.Mutex
+}
+
+// NewSetupService creates a new setup service, generating a bootstrap token
+// when the instance has not been configured yet
+func NewSetupService(storage storage.Storage, cfg config.AuthConfig) *SetupService {
+	s := &SetupService{
+		storage:     storage,
+		adminEmails: cfg.AdminEmails,
+	}
+
+	if s.needsSetup() {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			slog.Warn("Failed to generate setup token, setup wizard disabled", "error", err)
+			return s
+		}
+		s.token = hex.EncodeToString(b)
+	}
+
+	return s
+}
+
+// needsSetup reports whether neither stored configuration nor ADMIN_EMAILS exists
+func (s *SetupService) needsSetup() bool {
+	if len(s.adminEmails) > 0 {
+		return false
+	}
+
+	config, err := s.storage.GetAdminConfig()
+	if err != nil {
+		return false
+	}
+	return config == nil || !config.SetupCompleted && len(config.AdminEmails) == 0
+}
+
+// IsSetupRequired reports whether the setup wizard is still available
+func (s *SetupService) IsSetupRequired() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token != ""
+}
+
+// BootstrapToken returns the one-time token that guards the setup endpoints
+func (s *SetupService) BootstrapToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// ValidateToken checks a candidate bootstrap token in constant time
+func (s *SetupService) ValidateToken(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(s.token), []byte(token)) == 1
+}
+
+// CompleteSetup stores the initial configuration and invalidates the bootstrap token
+func (s *SetupService) CompleteSetup(req SetupRequest) (*models.AdminConfig, error) {
+	adminEmail := strings.TrimSpace(strings.ToLower(req.AdminEmail))
+	if adminEmail == "" || !strings.Contains(adminEmail, "@") {
+		return nil, fmt.Errorf("a valid admin email is required")
+	}
+
+	timezone := strings.TrimSpace(req.Timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", timezone)
+	}
+
+	if (req.GoogleClientID == "") != (req.GoogleClientSecret == "") {
+		return nil, fmt.Errorf("both Google client ID and secret must be provided")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == "" {
+		return nil, fmt.Errorf("setup has already been completed")
+	}
+
+	config := &models.AdminConfig{
+		TimeoutHours:       24,
+		AllowedEmails:      []string{adminEmail},
+		AdminEmails:        []string{adminEmail},
+		Timezone:           timezone,
+		GoogleClientID:     req.GoogleClientID,
+		GoogleClientSecret: req.GoogleClientSecret,
+		SetupCompleted:     true,
+		LastModified:       time.Now(),
+		ModifiedBy:         adminEmail,
+	}
+
+	if name := strings.TrimSpace(req.PlantName); name != "" {
+		plant, err := NewPlantService(s.storage).UpdatePlantSettings(name, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set plant name: %w", err)
+		}
+		config.TimeoutHours = plant.TimeoutHours
+	}
+
+	if err := s.storage.UpdateAdminConfig(config); err != nil {
+		return nil, fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	s.token = ""
+	slog.Info("Setup completed", "audit", true, "admin", adminEmail)
+	return config, nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func TestSetupService_FreshInstance(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	service := NewSetupService(store, config.AuthConfig{})
+
+	if !service.IsSetupRequired() {
+		t.Fatal("Expected setup to be required on a fresh instance")
+	}
+
+	token := service.BootstrapToken()
+	if token == "" {
+		t.Fatal("Expected a bootstrap token")
+	}
+	if service.ValidateToken("wrong") || !service.ValidateToken(token) {
+		t.Error("Expected only the bootstrap token to validate")
+	}
+
+	config, err := service.CompleteSetup(SetupRequest{
+		AdminEmail: "Owner@Example.com",
+		Timezone:   "Europe/Berlin",
+		PlantName:  "Fern",
+	})
+	if err != nil {
+		t.Fatalf("Failed to complete setup: %v", err)
+	}
+
+	if config.AdminEmails[0] != "owner@example.com" || !config.SetupCompleted {
+		t.Errorf("Unexpected config after setup: %+v", config)
+	}
+	if plant, _ := store.GetPlantState(); plant == nil || plant.Name != "Fern" {
+		t.Errorf("Expected plant to be named Fern, got %v", plant)
+	}
+
+	// Setup is one-time only
+	if service.IsSetupRequired() || service.ValidateToken(token) {
+		t.Error("Expected bootstrap token to be invalidated after setup")
+	}
+	if _, err := service.CompleteSetup(SetupRequest{AdminEmail: "other@example.com"}); err == nil {
+		t.Error("Expected second setup attempt to fail")
+	}
+}
+
+func TestSetupService_ConfiguredInstance(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, AdminEmails: []string{"admin@example.com"}})
+
+	if NewSetupService(store, config.AuthConfig{}).IsSetupRequired() {
+		t.Error("Expected setup not to be required when an admin is configured")
+	}
+
+	if NewSetupService(storage.NewMemoryStorage(), config.AuthConfig{AdminEmails: []string{"admin@example.com"}}).IsSetupRequired() {
+		t.Error("Expected setup not to be required when ADMIN_EMAILS is set")
+	}
+}
+
+func TestSetupService_Validation(t *testing.T) {
+
+	tests := []struct {
+		name string
+		req  SetupRequest
+	}{
+		{"missing admin email", SetupRequest{}},
+		{"invalid timezone", SetupRequest{AdminEmail: "a@example.com", Timezone: "Mars/Olympus"}},
+		{"partial OAuth credentials", SetupRequest{AdminEmail: "a@example.com", GoogleClientID: "id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewSetupService(storage.NewMemoryStorage(), config.AuthConfig{})
+			if _, err := service.CompleteSetup(tt.req); err == nil {
+				t.Error("Expected validation error")
+			}
+			if !service.IsSetupRequired() {
+				t.Error("Expected setup to remain available after a failed attempt")
+			}
+		})
+	}
+}
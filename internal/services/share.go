@@ -0,0 +1,214 @@
+package services
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"watered/internal/ical"
+	"watered/internal/models"
+	"watered/internal/randtoken"
+	"watered/internal/storage"
+	"watered/internal/tracing"
+)
+
+// ShareTokenPrefix starts every share link token so tokens are easy to
+// recognize in URLs and secret scanners
+const ShareTokenPrefix = "ws_"
+
+// maxShareNameLength caps the label shown in the admin list and audit logs
+const maxShareNameLength = 64
+
+var (
+	// ErrShareLinkNotFound is returned for an ID no share link exists under
+	ErrShareLinkNotFound = errors.New("share link not found")
+	// ErrInvalidShareLink is returned for a malformed name or unknown plant
+	ErrInvalidShareLink = errors.New("invalid share link")
+	// ErrInvalidShareToken is returned for a token that is malformed, revoked
+	// or never existed
+	ErrInvalidShareToken = errors.New("invalid share token")
+)
+
+// SharedPlantStatus is what a share link shows: the plant's name and
+// status, without who watered it or any household details
+type SharedPlantStatus struct {
+	Name                       string                   `json:"name"`
+	Status                     models.PlantHealthStatus `json:"status"`
+	OverallStatus              models.CareStatus        `json:"overall_status"`
+	LastWatered                *time.Time               `json:"last_watered"`
+	TimeSinceWateringFormatted string                   `json:"time_since_watering_formatted"`
+	NextWateringTime           *time.Time               `json:"next_watering_time"`
+	IsOverdue                  bool                     `json:"is_overdue"`
+}
+
+// ShareService issues and resolves read-only share links to a plant's status
+type ShareService struct {
+	storage      storage.Storage
+	plantService *PlantService
+}
+
+// NewShareService creates a new share service
+func NewShareService(storage storage.Storage, plantService *PlantService) *ShareService {
+	return &ShareService{
+		storage:      storage,
+		plantService: plantService,
+	}
+}
+
+// Create issues a share link to plantID's status. It returns the stored link
+// and the plaintext token, which is not retrievable afterwards.
+func (s *ShareService) Create(name string, plantID int, createdBy string) (*models.ShareLink, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxShareNameLength {
+		return nil, "", fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidShareLink, maxShareNameLength)
+	}
+	if _, err := s.plantService.GetPlantByID(plantID); err != nil {
+		if errors.Is(err, ErrPlantNotFound) {
+			return nil, "", fmt.Errorf("%w: plant %d does not exist", ErrInvalidShareLink, plantID)
+		}
+		return nil, "", err
+	}
+
+	id, err := randtoken.String(8, hex.EncodeToString)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	secret, err := randtoken.String(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	token := ShareTokenPrefix + id + "_" + secret
+
+	link := &models.ShareLink{
+		ID:        id,
+		Name:      name,
+		PlantID:   plantID,
+		TokenHash: randtoken.Hash(token),
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	if err := s.storage.SaveShareLink(link); err != nil {
+		return nil, "", fmt.Errorf("failed to store share link: %w", err)
+	}
+
+	slog.Info("Share link created", "audit", true, "share_id", id, "name", name, "plant_id", plantID, "by", createdBy)
+	return link, token, nil
+}
+
+// List returns all share links without their token hashes
+func (s *ShareService) List() ([]*models.ShareLink, error) {
+	links, err := s.storage.ListShareLinks()
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		link.TokenHash = ""
+	}
+	return links, nil
+}
+
+// Revoke deletes a share link so its token stops working, returning the
+// revoked link
+func (s *ShareService) Revoke(id, revokedBy string) (*models.ShareLink, error) {
+	link, err := s.storage.GetShareLink(id)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, ErrShareLinkNotFound
+	}
+	if err := s.storage.DeleteShareLink(id); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Share link revoked", "audit", true, "share_id", id, "name", link.Name, "by", revokedBy)
+	link.TokenHash = ""
+	return link, nil
+}
+
+// Status returns the status of the plant a token shares. Tokens of revoked
+// links return ErrInvalidShareToken, as do tokens of a deleted plant's
+// links, which DeletePlant removes along with the plant.
+func (s *ShareService) Status(token string) (*SharedPlantStatus, error) {
+	link := s.authenticate(token)
+	if link == nil {
+		return nil, ErrInvalidShareToken
+	}
+
+	status, err := s.plantService.SharedStatusByID(link.PlantID)
+	if errors.Is(err, ErrPlantNotFound) {
+		return nil, ErrInvalidShareToken
+	}
+	return status, err
+}
+
+// Calendar returns the watering calendar of the plant plantID for a token
+// that shares it. Tokens of revoked links, links to other plants and
+// links of a deleted plant return ErrInvalidShareToken.
+func (s *ShareService) Calendar(token string, plantID int) (*ical.Calendar, error) {
+	link := s.authenticate(token)
+	if link == nil || link.PlantID != plantID {
+		return nil, ErrInvalidShareToken
+	}
+
+	calendar, err := s.plantService.CalendarByID(plantID)
+	if errors.Is(err, ErrPlantNotFound) {
+		return nil, ErrInvalidShareToken
+	}
+	return calendar, err
+}
+
+// SharedStatusByID returns the parts of a plant's status that may be shown
+// outside the household, such as on share links and badges
+func (s *PlantService) SharedStatusByID(id int) (*SharedPlantStatus, error) {
+	s, span := s.trace("PlantService.SharedStatusByID", tracing.Int("plant.id", id))
+	defer span.End()
+
+	plant, err := s.GetPlantByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	status := s.statusResponse(plant)
+	var next *time.Time
+	if plant.LastWatered != nil {
+		due := plant.LastWatered.Add(plant.DueAfter())
+		next = &due
+	}
+	return &SharedPlantStatus{
+		Name:                       plant.Name,
+		Status:                     status.Status,
+		OverallStatus:              status.OverallStatus,
+		LastWatered:                plant.LastWatered,
+		TimeSinceWateringFormatted: status.TimeSinceWateringFormatted,
+		NextWateringTime:           next,
+		IsOverdue:                  status.IsOverdue,
+	}, nil
+}
+
+// authenticate returns the link a plaintext token belongs to, or nil when
+// the token is malformed, unknown or revoked
+func (s *ShareService) authenticate(token string) *models.ShareLink {
+	rest, ok := strings.CutPrefix(token, ShareTokenPrefix)
+	if !ok {
+		return nil
+	}
+	id, _, ok := strings.Cut(rest, "_")
+	if !ok || id == "" {
+		return nil
+	}
+
+	link, err := s.storage.GetShareLink(id)
+	if err != nil || link == nil {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(randtoken.Hash(token)), []byte(link.TokenHash)) != 1 {
+		return nil
+	}
+	return link
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func TestShareService_CreateAndStatus(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := NewPlantService(store)
+	service := NewShareService(store, plantService)
+
+	if _, err := plantService.WaterPlant("alice@example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	link, token, err := service.Create("  grandma  ", models.DefaultPlantID, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(token, ShareTokenPrefix+link.ID+"_") || link.Name != "grandma" || link.CreatedBy != "admin@example.com" {
+		t.Errorf("Expected a prefixed token for a trimmed name, got %+v (%q)", link, token)
+	}
+	stored, _ := store.GetShareLink(link.ID)
+	if stored == nil || stored.TokenHash == "" || strings.Contains(stored.TokenHash, token) {
+		t.Errorf("Expected only a hash of the token to be stored, got %+v", stored)
+	}
+
+	status, err := service.Status(token)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if status.Status != models.HealthStatusHealthy || status.LastWatered == nil || status.NextWateringTime == nil || status.IsOverdue {
+		t.Errorf("Expected the freshly watered plant to be healthy, got %+v", status)
+	}
+
+	for _, bad := range []string{"", token + "x", "ws_" + link.ID, "ak_" + strings.TrimPrefix(token, ShareTokenPrefix), "ws_missing_secret"} {
+		if _, err := service.Status(bad); !errors.Is(err, ErrInvalidShareToken) {
+			t.Errorf("Expected ErrInvalidShareToken for %q, got %v", bad, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		share   string
+		plantID int
+	}{
+		{"blank name", "  ", models.DefaultPlantID},
+		{"long name", strings.Repeat("x", maxShareNameLength+1), models.DefaultPlantID},
+		{"unknown plant", "neighbour", 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := service.Create(tt.share, tt.plantID, "admin@example.com"); !errors.Is(err, ErrInvalidShareLink) {
+				t.Errorf("Expected ErrInvalidShareLink, got %v", err)
+			}
+		})
+	}
+}
+
+func TestShareService_Revoke(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := NewPlantService(store)
+	service := NewShareService(store, plantService)
+
+	link, token, err := service.Create("neighbour", models.DefaultPlantID, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	links, err := service.List()
+	if err != nil || len(links) != 1 || links[0].TokenHash != "" {
+		t.Fatalf("Expected one link without its hash, got %+v (%v)", links, err)
+	}
+
+	revoked, err := service.Revoke(link.ID, "admin@example.com")
+	if err != nil || revoked.Name != "neighbour" {
+		t.Fatalf("Expected the revoked link, got %+v (%v)", revoked, err)
+	}
+	if _, err := service.Status(token); !errors.Is(err, ErrInvalidShareToken) {
+		t.Errorf("Expected a revoked token to stop working, got %v", err)
+	}
+	if _, err := service.Revoke(link.ID, "admin@example.com"); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("Expected ErrShareLinkNotFound, got %v", err)
+	}
+}
+
+func TestShareService_DeletedPlant(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := NewPlantService(store)
+	service := NewShareService(store, plantService)
+
+	plant, err := plantService.CreatePlant("Fern", 48)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_, token, err := service.Create("fern", plant.ID, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	status, err := service.Status(token)
+	if err != nil || status.Name != "Fern" || status.LastWatered != nil || status.NextWateringTime != nil {
+		t.Fatalf("Expected the unwatered fern, got %+v (%v)", status, err)
+	}
+
+	if err := plantService.DeletePlant(plant.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.Status(token); !errors.Is(err, ErrInvalidShareToken) {
+		t.Errorf("Expected a link to a deleted plant to stop working, got %v", err)
+	}
+	if links, _ := service.List(); len(links) != 0 {
+		t.Errorf("Expected the deleted plant's links to be removed, got %+v", links)
+	}
+
+	// A plant created afterwards must not be reachable through the old link
+	cactus, err := plantService.CreatePlant("Private cactus", 336)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if status, err := service.Status(token); !errors.Is(err, ErrInvalidShareToken) {
+		t.Errorf("Expected the old link to stay invalid, got %+v (%v)", status, err)
+	}
+	if _, err := service.Calendar(token, cactus.ID); !errors.Is(err, ErrInvalidShareToken) {
+		t.Errorf("Expected the old link to stay invalid for the calendar, got %v", err)
+	}
+}
+
+func TestShareService_Calendar(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	plantService := NewPlantService(store)
+	service := NewShareService(store, plantService)
+
+	fern, err := plantService.CreatePlant("Fern", 48)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_, token, err := service.Create("calendar", fern.ID, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	calendar, err := service.Calendar(token, fern.ID)
+	if err != nil || calendar.Name != "Fern" {
+		t.Fatalf("Expected the fern's calendar, got %+v (%v)", calendar, err)
+	}
+	if _, err := service.Calendar(token, models.DefaultPlantID); !errors.Is(err, ErrInvalidShareToken) {
+		t.Errorf("Expected a link to only open its own plant's calendar, got %v", err)
+	}
+	if _, err := service.Calendar("", fern.ID); !errors.Is(err, ErrInvalidShareToken) {
+		t.Errorf("Expected ErrInvalidShareToken without a token, got %v", err)
+	}
+}
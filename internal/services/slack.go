@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/notify/slack"
+	"watered/internal/storage"
+)
+
+// ErrSlackDisabled is returned when Slack is used without a webhook configured
+var ErrSlackDisabled = errors.New("slack notifications are not configured")
+
+// Built-in Slack message templates, used when SLACK_OVERDUE_TEMPLATE or
+// SLACK_WATERED_TEMPLATE is not set
+const (
+	DefaultSlackOverdueTemplate = `{{if .Critical}}:rotating_light: *{{.Plant}}* needs water now!{{else}}:droplet: *{{.Plant}}* is overdue for watering.{{end}}` +
+		` {{if .Since}}It was last watered {{.Since}} ago{{if .WateredBy}} by {{.WateredBy}}{{end}}.{{else}}It has never been watered.{{end}}`
+	DefaultSlackWateredTemplate = `:potted_plant: {{.WateredBy}} watered *{{.Plant}}*{{if .Since}} after {{.Since}}{{end}}.`
+)
+
+// SlackSender posts a single message to Slack
+type SlackSender interface {
+	Send(ctx context.Context, msg slack.Message) error
+}
+
+// SlackMessage is the data available to Slack message templates
+type SlackMessage struct {
+	// Plant is the plant's name
+	Plant string
+	// WateredBy is the name of whoever watered the plant, for overdue
+	// messages the last person to water it. It is "Someone" in privacy mode.
+	WateredBy string
+	// Since is how long the plant had gone without water, such as "2 days",
+	// or empty if it had never been watered
+	Since string
+	// Critical is set when an overdue plant is past its grace period
+	Critical bool
+}
+
+// SlackService posts to a Slack channel when a plant becomes overdue and
+// when someone waters it
+type SlackService struct {
+	storage storage.Storage
+	sender  SlackSender
+	overdue *template.Template
+	watered *template.Template
+}
+
+// NewSlackService creates a Slack service using the built-in templates. A
+// nil sender disables Slack.
+func NewSlackService(storage storage.Storage, sender SlackSender) *SlackService {
+	return &SlackService{
+		storage: storage,
+		sender:  sender,
+		overdue: template.Must(template.New("overdue").Parse(DefaultSlackOverdueTemplate)),
+		watered: template.Must(template.New("watered").Parse(DefaultSlackWateredTemplate)),
+	}
+}
+
+// SetTemplates replaces the message templates. Empty templates keep the
+// current ones.
+func (s *SlackService) SetTemplates(overdue, watered string) error {
+	if overdue != "" {
+		parsed, err := template.New("overdue").Parse(overdue)
+		if err != nil {
+			return fmt.Errorf("invalid overdue template: %w", err)
+		}
+		s.overdue = parsed
+	}
+	if watered != "" {
+		parsed, err := template.New("watered").Parse(watered)
+		if err != nil {
+			return fmt.Errorf("invalid watered template: %w", err)
+		}
+		s.watered = parsed
+	}
+	return nil
+}
+
+// Enabled reports whether a Slack webhook is configured
+func (s *SlackService) Enabled() bool {
+	return s.sender != nil
+}
+
+// SendTest posts a test message so admins can verify the webhook
+func (s *SlackService) SendTest(ctx context.Context) error {
+	if !s.Enabled() {
+		return ErrSlackDisabled
+	}
+	return s.sender.Send(ctx, slack.Message{Text: "This is a test message from Watered. Your Slack webhook works!"})
+}
+
+// Trigger reports a Slack milestone once the plant is overdue or critical
+func (s *SlackService) Trigger(plant *models.PlantState) models.NotificationTrigger {
+	return plant.GetNotificationTrigger()
+}
+
+// Notify posts that a plant is overdue and returns the number of deliveries
+func (s *SlackService) Notify(ctx context.Context, plant *models.PlantState, trigger models.NotificationTrigger) int {
+	if !s.Enabled() {
+		return 0
+	}
+
+	message := SlackMessage{Plant: plant.Name, Critical: trigger == models.NotificationTriggerCritical}
+	if plant.LastWatered != nil {
+		message.Since = humanDuration(time.Since(*plant.LastWatered))
+		message.WateredBy = displayName(s.storage, plant.WateredBy)
+	}
+	if err := s.post(ctx, s.overdue, message); err != nil {
+		slog.Error("Failed to post Slack reminder", "plant_id", plant.ID, "trigger", trigger, "error", err)
+		return 0
+	}
+	slog.Info("Posted Slack reminder", "plant_id", plant.ID, "trigger", trigger)
+	return 1
+}
+
+// NotifyWatered posts who watered a plant and how long it had gone without
+// water
+func (s *SlackService) NotifyWatered(ctx context.Context, plant *models.PlantState, previous *time.Time) {
+	if !s.Enabled() || plant.LastWatered == nil {
+		return
+	}
+
+	message := SlackMessage{Plant: plant.Name, WateredBy: displayName(s.storage, plant.WateredBy)}
+	if previous != nil {
+		message.Since = humanDuration(plant.LastWatered.Sub(*previous))
+	}
+	if err := s.post(ctx, s.watered, message); err != nil {
+		slog.Error("Failed to post Slack watering message", "plant_id", plant.ID, "error", err)
+	}
+}
+
+// post renders a template and sends the result
+func (s *SlackService) post(ctx context.Context, tmpl *template.Template, message SlackMessage) error {
+	var text strings.Builder
+	if err := tmpl.Execute(&text, message); err != nil {
+		return fmt.Errorf("failed to render %s template: %w", tmpl.Name(), err)
+	}
+	return s.sender.Send(ctx, slack.Message{Text: text.String()})
+}
+
+// displayName names a user in a chat channel outside the app: their name if
+// known, else their email, or "Someone" in privacy mode
+func displayName(store storage.Storage, email string) string {
+	if email == "" {
+		return ""
+	}
+	if config, err := store.GetAdminConfig(); err == nil && config != nil && config.PrivacyMode {
+		return "Someone"
+	}
+	if user, err := store.GetUser(email); err == nil && user != nil && user.Name != "" {
+		return user.Name
+	}
+	return email
+}
+
+// humanDuration describes a duration in its largest whole unit, such as
+// "45 minutes", "5 hours" or "2 days"
+func humanDuration(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return "1 " + unit
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+
+	switch {
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute")
+	case d < 48*time.Hour:
+		return plural(int(d.Hours()), "hour")
+	default:
+		return plural(int(d.Hours()/24), "day")
+	}
+}
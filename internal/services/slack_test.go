@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/notify/slack"
+	"watered/internal/storage"
+)
+
+// fakeSlackSender records messages instead of posting to a webhook
+type fakeSlackSender struct {
+	messages chan slack.Message
+	err      error
+}
+
+func newFakeSlackSender() *fakeSlackSender {
+	return &fakeSlackSender{messages: make(chan slack.Message, 10)}
+}
+
+func (f *fakeSlackSender) Send(ctx context.Context, msg slack.Message) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.messages <- msg
+	return nil
+}
+
+// next returns the next message posted, failing if none arrives
+func (f *fakeSlackSender) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case msg := <-f.messages:
+		return msg.Text
+	case <-time.After(time.Second):
+		t.Fatal("Expected a Slack message")
+		return ""
+	}
+}
+
+func TestSlackService_Disabled(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := NewSlackService(store, nil)
+	if service.Enabled() {
+		t.Error("Expected Slack to be disabled without a sender")
+	}
+	if err := service.SendTest(context.Background()); err != ErrSlackDisabled {
+		t.Errorf("Expected ErrSlackDisabled, got %v", err)
+	}
+	if delivered := service.Notify(context.Background(), &models.PlantState{Name: "Fern"}, models.NotificationTriggerDue); delivered != 0 {
+		t.Errorf("Expected no deliveries, got %d", delivered)
+	}
+}
+
+func TestSlackService_Notify(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.CreateUser(&models.User{Email: "alice@example.com", Name: "Alice"})
+
+	sender := newFakeSlackSender()
+	service := NewSlackService(store, sender)
+
+	lastWatered := time.Now().Add(-50 * time.Hour)
+	plant := &models.PlantState{ID: 1, Name: "Fern", LastWatered: &lastWatered, WateredBy: "alice@example.com"}
+	if delivered := service.Notify(context.Background(), plant, models.NotificationTriggerDue); delivered != 1 {
+		t.Fatalf("Expected one delivery, got %d", delivered)
+	}
+	if text := sender.next(t); text != ":droplet: *Fern* is overdue for watering. It was last watered 2 days ago by Alice." {
+		t.Errorf("Unexpected overdue message: %q", text)
+	}
+
+	service.Notify(context.Background(), &models.PlantState{ID: 2, Name: "Cactus"}, models.NotificationTriggerCritical)
+	if text := sender.next(t); text != ":rotating_light: *Cactus* needs water now! It has never been watered." {
+		t.Errorf("Unexpected critical message: %q", text)
+	}
+
+	sender.err = errors.New("invalid_token")
+	if delivered := service.Notify(context.Background(), plant, models.NotificationTriggerDue); delivered != 0 {
+		t.Errorf("Expected no deliveries when Slack fails, got %d", delivered)
+	}
+}
+
+func TestSlackService_NotifyWatered(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	sender := newFakeSlackSender()
+	slackService := NewSlackService(store, sender)
+	plantService := NewPlantService(store)
+	plantService.AddWateringNotifier(slackService)
+
+	now := time.Now()
+	if _, err := plantService.WaterPlantByIDAt(1, "bob@example.com", now.Add(-5*time.Hour)); err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+	if text := sender.next(t); text != ":potted_plant: bob@example.com watered *Our Plant*." {
+		t.Errorf("Unexpected first watering message: %q", text)
+	}
+
+	// In privacy mode the waterer is not named
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, PrivacyMode: true})
+	plantService.WaterPlantByIDAt(1, "bob@example.com", now)
+	if text := sender.next(t); text != ":potted_plant: Someone watered *Our Plant* after 5 hours." {
+		t.Errorf("Unexpected watering message: %q", text)
+	}
+
+	// Backfilled waterings do not change the plant and are not announced
+	plantService.WaterPlantByIDAt(1, "bob@example.com", now.Add(-time.Hour))
+	select {
+	case msg := <-sender.messages:
+		t.Errorf("Expected no message for a backfilled watering, got %q", msg.Text)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSlackService_SetTemplates(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	sender := newFakeSlackSender()
+	service := NewSlackService(store, sender)
+	if err := service.SetTemplates("{{.Plant", ""); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+	if err := service.SetTemplates("", "{{.WateredBy}} saved {{.Plant}} ({{.Since}})"); err != nil {
+		t.Fatalf("Failed to set templates: %v", err)
+	}
+
+	lastWatered := time.Now()
+	previous := lastWatered.Add(-90 * time.Minute)
+	service.NotifyWatered(context.Background(), &models.PlantState{Name: "Fern", LastWatered: &lastWatered, WateredBy: "alice@example.com"}, &previous)
+	if text := sender.next(t); text != "alice@example.com saved Fern (1 hour)" {
+		t.Errorf("Unexpected custom message: %q", text)
+	}
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+// SnoozeLinkTTL is how long a snooze link in a reminder stays valid
+const SnoozeLinkTTL = 48 * time.Hour
+
+// ErrInvalidSnoozeToken is returned for snooze links that are malformed,
+// tampered with or expired
+var ErrInvalidSnoozeToken = errors.New("invalid or expired snooze link")
+
+// snoozeClaims is the signed content of a snooze token
+type snoozeClaims struct {
+	PlantID   int    `json:"p"`
+	Email     string `json:"e"`
+	Channel   string `json:"c"`
+	ExpiresAt int64  `json:"x"`
+}
+
+// snoozeKey identifies one user's reminders about one plant
+type snoozeKey struct {
+	plantID int
+	email   string
+}
+
+// snooze is an active snooze and the channel to remind the user on when it ends
+type snooze struct {
+	until   time.Time
+	channel string
+}
+
+// Snooze describes a snooze started from a reminder link
+type Snooze struct {
+	Plant *models.PlantState
+	Email string
+	Until time.Time
+}
+
+// SnoozeService signs the snooze links carried by reminders and tracks who
+// snoozed which plant. While a snooze lasts the user gets no reminders or
+// escalation steps about the plant; other users are unaffected. When it ends
+// and the plant is still overdue, the user is reminded again on the channel
+// the link came from. Snoozes are kept in memory, so a restart ends them.
+type SnoozeService struct {
+	plantService        *PlantService
+	notificationService *NotificationService
+	key                 []byte
+	baseURL             string
+	now                 func() time.Time
+
+	mu        sync.Mutex
+	duration  time.Duration
+	snoozes   map[snoozeKey]snooze
+	providers map[string]EscalationProvider
+}
+
+// NewSnoozeService creates a snooze service. key signs the links, duration
+// is how long a snooze lasts and baseURL is the server's public address.
+func NewSnoozeService(storage storage.Storage, plantService *PlantService, key []byte, duration time.Duration, baseURL string) *SnoozeService {
+	return &SnoozeService{
+		plantService:        plantService,
+		notificationService: NewNotificationService(storage),
+		key:                 key,
+		duration:            duration,
+		baseURL:             strings.TrimSuffix(baseURL, "/"),
+		now:                 time.Now,
+		snoozes:             make(map[snoozeKey]snooze),
+	}
+}
+
+// SetProviders sets the channels used to remind users again once their
+// snooze ends, keyed by channel name
+func (s *SnoozeService) SetProviders(providers map[string]EscalationProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.providers = providers
+}
+
+// SetDuration changes how long new snoozes last; snoozes already made keep
+// their end
+func (s *SnoozeService) SetDuration(duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.duration = duration
+}
+
+// snoozeDuration returns how long new snoozes last
+func (s *SnoozeService) snoozeDuration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.duration
+}
+
+// Label is the text shown on snooze links, such as "Remind me again in 3 hours"
+func (s *SnoozeService) Label() string {
+	return "Remind me again in " + humanDuration(s.snoozeDuration())
+}
+
+// Link returns the snooze URL for a reminder about plantID sent to email on
+// channel
+func (s *SnoozeService) Link(plantID int, email, channel string) string {
+	return s.baseURL + "/api/v1/snooze?token=" + url.QueryEscape(s.Token(plantID, email, channel))
+}
+
+// Token returns a signed snooze token valid for SnoozeLinkTTL
+func (s *SnoozeService) Token(plantID int, email, channel string) string {
+	payload, _ := json.Marshal(snoozeClaims{
+		PlantID:   plantID,
+		Email:     email,
+		Channel:   channel,
+		ExpiresAt: s.now().Add(SnoozeLinkTTL).Unix(),
+	})
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded))
+}
+
+// Snooze verifies a token and silences reminders about the plant for the
+// token's user, recording the snooze in their notification history
+func (s *SnoozeService) Snooze(token string) (*Snooze, error) {
+	claims, err := s.verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	plant, err := s.plantService.GetPlantByID(claims.PlantID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	duration := s.duration
+	until := s.now().Add(duration)
+	s.snoozes[snoozeKey{plantID: plant.ID, email: claims.Email}] = snooze{until: until, channel: claims.Channel}
+	s.mu.Unlock()
+
+	summary := fmt.Sprintf("Snoozed reminders about %s for %s", plant.Name, humanDuration(duration))
+	if _, err := s.notificationService.Record(claims.Email, claims.Channel, models.NotificationTriggerSnoozed, summary, nil); err != nil {
+		slog.Warn("Failed to record snooze", "error", err)
+	}
+	slog.Info("Reminders snoozed", "plant_id", plant.ID, "email", claims.Email, "until", until)
+
+	return &Snooze{Plant: plant, Email: claims.Email, Until: until}, nil
+}
+
+// Snoozed reports whether email has snoozed reminders about plantID
+func (s *SnoozeService) Snoozed(plantID int, email string) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	active, ok := s.snoozes[snoozeKey{plantID: plantID, email: email}]
+	return ok && s.now().Before(active.until)
+}
+
+// CheckOnce ends snoozes that are over, reminding each user again if the
+// plant is still overdue, and returns the number of deliveries
+func (s *SnoozeService) CheckOnce(ctx context.Context) int {
+	now := s.now()
+
+	type ended struct {
+		key     snoozeKey
+		channel string
+	}
+	var over []ended
+	s.mu.Lock()
+	providers := s.providers
+	for key, active := range s.snoozes {
+		if !now.Before(active.until) {
+			over = append(over, ended{key: key, channel: active.channel})
+			delete(s.snoozes, key)
+		}
+	}
+	s.mu.Unlock()
+
+	delivered := 0
+	for _, e := range over {
+		plant, err := s.plantService.GetPlantByID(e.key.plantID)
+		if err != nil {
+			continue
+		}
+		trigger := plant.GetNotificationTrigger()
+		if trigger == models.NotificationTriggerNone {
+			continue
+		}
+		provider, ok := providers[e.channel]
+		if !ok {
+			continue
+		}
+		slog.Info("Snooze ended, reminding again", "plant_id", plant.ID, "email", e.key.email, "channel", e.channel)
+		delivered += provider.NotifyRecipient(ctx, plant, trigger, e.key.email)
+	}
+	return delivered
+}
+
+// verify checks a token's signature and expiry and returns its claims
+func (s *SnoozeService) verify(token string) (*snoozeClaims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidSnoozeToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign(encoded)) {
+		return nil, ErrInvalidSnoozeToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidSnoozeToken
+	}
+	var claims snoozeClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Email == "" {
+		return nil, ErrInvalidSnoozeToken
+	}
+	if s.now().Unix() > claims.ExpiresAt {
+		return nil, ErrInvalidSnoozeToken
+	}
+	return &claims, nil
+}
+
+// sign returns the MAC of an encoded payload
+func (s *SnoozeService) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte("snooze:" + encoded))
+	return mac.Sum(nil)
+}
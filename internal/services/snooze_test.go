@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+var snoozeLinkPattern = regexp.MustCompile(`https://plants\.example\.com/api/v1/snooze\?token=(\S+)`)
+
+// snoozeToken extracts the token from the snooze link in a reminder
+func snoozeToken(t *testing.T, body string) string {
+	t.Helper()
+	match := snoozeLinkPattern.FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf("Expected a snooze link in %q", body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("Failed to unescape token: %v", err)
+	}
+	return token
+}
+
+func newTestSnoozeService(store storage.Storage) (*SnoozeService, *time.Time) {
+	now := time.Now()
+	service := NewSnoozeService(store, NewPlantService(store), []byte("test-key"), 3*time.Hour, "https://plants.example.com/")
+	service.now = func() time.Time { return now }
+	return service, &now
+}
+
+func TestSnoozeService_Token(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	service, now := newTestSnoozeService(store)
+
+	token := service.Token(models.DefaultPlantID, "a@example.com", EmailChannel)
+	encoded, signature, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"no signature", encoded},
+		{"bad signature", encoded + ".AAAA"},
+		{"other payload", service.Token(2, "a@example.com", EmailChannel)[:len(encoded)] + "." + signature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.Snooze(tt.token); !errors.Is(err, ErrInvalidSnoozeToken) {
+				t.Errorf("Expected ErrInvalidSnoozeToken, got %v", err)
+			}
+		})
+	}
+
+	// A key other than the signing key rejects the token
+	other := NewSnoozeService(store, NewPlantService(store), []byte("other-key"), time.Hour, "")
+	if _, err := other.Snooze(token); !errors.Is(err, ErrInvalidSnoozeToken) {
+		t.Errorf("Expected a token signed with another key to be rejected, got %v", err)
+	}
+
+	*now = now.Add(SnoozeLinkTTL + time.Minute)
+	if _, err := service.Snooze(token); !errors.Is(err, ErrInvalidSnoozeToken) {
+		t.Errorf("Expected an expired token to be rejected, got %v", err)
+	}
+}
+
+func TestSnoozeService_EmailReminders(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 24, AllowedEmails: []string{"a@example.com", "b@example.com"}})
+
+	plantService := NewPlantService(store)
+	plant, err := plantService.WaterPlantByIDAt(models.DefaultPlantID, "a@example.com", time.Now().Add(-30*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to water plant: %v", err)
+	}
+
+	snoozes, now := newTestSnoozeService(store)
+	sender := &fakeEmailSender{}
+	emailService := NewEmailService(store, sender)
+	emailService.SetSnoozeService(snoozes)
+	snoozes.SetProviders(map[string]EscalationProvider{EmailChannel: emailService})
+
+	if delivered := emailService.Notify(context.Background(), plant, models.NotificationTriggerDue); delivered != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d", delivered)
+	}
+	if !strings.Contains(sender.messages[0].Body, "Remind me again in 3 hours: https://plants.example.com/api/v1/snooze?token=") {
+		t.Errorf("Expected a snooze link in the reminder, got %q", sender.messages[0].Body)
+	}
+
+	snooze, err := snoozes.Snooze(snoozeToken(t, sender.messages[0].Body))
+	if err != nil {
+		t.Fatalf("Failed to snooze: %v", err)
+	}
+	if snooze.Email != "a@example.com" || snooze.Plant.ID != models.DefaultPlantID || !snooze.Until.Equal(now.Add(3*time.Hour)) {
+		t.Errorf("Unexpected snooze: %+v", snooze)
+	}
+	snoozed, _ := store.ListNotifications(models.NotificationFilter{UserEmail: "a@example.com"})
+	if len(snoozed) == 0 || snoozed[0].Trigger != models.NotificationTriggerSnoozed {
+		t.Errorf("Expected the snooze in the notification history, got %+v", snoozed)
+	}
+
+	// Only the user who snoozed stops getting reminders, including escalation steps
+	sender.messages = nil
+	emailService.Notify(context.Background(), plant, models.NotificationTriggerCritical)
+	emailService.NotifyRecipient(context.Background(), plant, models.NotificationTriggerCritical, "a@example.com")
+	if len(sender.messages) != 1 || sender.messages[0].To[0] != "b@example.com" {
+		t.Errorf("Expected only b@example.com to be reminded, got %+v", sender.messages)
+	}
+
+	// Nothing happens before the snooze ends
+	sender.messages = nil
+	if delivered := snoozes.CheckOnce(context.Background()); delivered != 0 {
+		t.Errorf("Expected no reminders during the snooze, got %d", delivered)
+	}
+
+	// Once it ends the user is reminded again while the plant is still overdue
+	*now = now.Add(3 * time.Hour)
+	if delivered := snoozes.CheckOnce(context.Background()); delivered != 1 {
+		t.Fatalf("Expected a reminder when the snooze ends, got %d", delivered)
+	}
+	if sender.messages[0].To[0] != "a@example.com" || snoozes.Snoozed(models.DefaultPlantID, "a@example.com") {
+		t.Errorf("Expected a@example.com to be reminded and no longer snoozed, got %+v", sender.messages)
+	}
+	if delivered := snoozes.CheckOnce(context.Background()); delivered != 0 {
+		t.Errorf("Expected one reminder per snooze, got %d", delivered)
+	}
+}
+
+func TestSnoozeService_WateredDuringSnooze(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	plantService := NewPlantService(store)
+	plantService.WaterPlantByIDAt(models.DefaultPlantID, "a@example.com", time.Now().Add(-100*time.Hour))
+
+	snoozes, now := newTestSnoozeService(store)
+	sender := &fakeEmailSender{}
+	snoozes.SetProviders(map[string]EscalationProvider{EmailChannel: NewEmailService(store, sender)})
+	if _, err := snoozes.Snooze(snoozes.Token(models.DefaultPlantID, "a@example.com", EmailChannel)); err != nil {
+		t.Fatalf("Failed to snooze: %v", err)
+	}
+
+	plantService.WaterPlantByID(models.DefaultPlantID, "b@example.com")
+	*now = now.Add(4 * time.Hour)
+	if delivered := snoozes.CheckOnce(context.Background()); delivered != 0 || len(sender.messages) != 0 {
+		t.Errorf("Expected no reminder once the plant was watered, got %+v", sender.messages)
+	}
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/notify/telegram"
+	"watered/internal/storage"
+)
+
+// telegramHelp lists the bot's commands
+const telegramHelp = "Commands:\n" +
+	"/watered - record that you watered the plant\n" +
+	"/watered <plant ID> - record watering another plant\n" +
+	"/status - show how the plants are doing"
+
+// TelegramSender posts a message to a Telegram chat
+type TelegramSender interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// TelegramService posts reminders to a Telegram chat and runs the commands
+// users send the bot, such as /watered
+type TelegramService struct {
+	storage      storage.Storage
+	plantService *PlantService
+	sender       TelegramSender
+	chatID       int64
+	users        map[int64]string
+}
+
+// NewTelegramService creates a Telegram service for the configured chat and
+// users. A nil sender disables reminders.
+func NewTelegramService(storage storage.Storage, plantService *PlantService, sender TelegramSender, cfg config.TelegramConfig) *TelegramService {
+	return &TelegramService{
+		storage:      storage,
+		plantService: plantService,
+		sender:       sender,
+		chatID:       cfg.ChatID,
+		users:        cfg.Users,
+	}
+}
+
+// Enabled reports whether a Telegram bot is configured
+func (s *TelegramService) Enabled() bool {
+	return s.sender != nil
+}
+
+// Trigger reports a Telegram milestone once the plant is overdue or critical
+func (s *TelegramService) Trigger(plant *models.PlantState) models.NotificationTrigger {
+	return plant.GetNotificationTrigger()
+}
+
+// Notify posts that a plant is overdue to the chat and returns the number of
+// deliveries
+func (s *TelegramService) Notify(ctx context.Context, plant *models.PlantState, trigger models.NotificationTrigger) int {
+	if !s.Enabled() {
+		return 0
+	}
+
+	var text strings.Builder
+	if trigger == models.NotificationTriggerCritical {
+		fmt.Fprintf(&text, "🥀 %s needs water now!", plant.Name)
+	} else {
+		fmt.Fprintf(&text, "💧 %s is overdue for watering.", plant.Name)
+	}
+	if plant.LastWatered == nil {
+		text.WriteString(" It has never been watered.")
+	} else {
+		fmt.Fprintf(&text, " It was last watered %s ago", humanDuration(time.Since(*plant.LastWatered)))
+		if name := displayName(s.storage, plant.WateredBy); name != "" {
+			fmt.Fprintf(&text, " by %s", name)
+		}
+		text.WriteString(".")
+	}
+	fmt.Fprintf(&text, "\nReply %s once it's done.", wateredCommand(plant.ID))
+
+	if err := s.sender.SendMessage(ctx, s.chatID, text.String()); err != nil {
+		slog.Error("Failed to post Telegram reminder", "plant_id", plant.ID, "trigger", trigger, "error", err)
+		return 0
+	}
+	slog.Info("Posted Telegram reminder", "plant_id", plant.ID, "trigger", trigger)
+	return 1
+}
+
+// HandleMessage runs a command sent to the bot and returns the reply, or ""
+// when the message is not a command. allowed reports whether an email may
+// still water plants, so removing a user from the allowlist or making them a
+// viewer also locks out their Telegram account.
+func (s *TelegramService) HandleMessage(msg *telegram.Message, allowed func(email string) bool) string {
+	fields := strings.Fields(msg.Text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return ""
+	}
+	// In groups commands may be addressed as /watered@YourBot
+	command, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	args := fields[1:]
+
+	switch command {
+	case "/start", "/help":
+		return "Hi! I remind this chat when the plants need water.\n\n" + telegramHelp
+	case "/status":
+		return s.status()
+	case "/watered":
+		return s.watered(msg.From, args, allowed)
+	default:
+		return "Sorry, I don't know that command.\n\n" + telegramHelp
+	}
+}
+
+// watered records a watering for the Watered user linked to the sender
+func (s *TelegramService) watered(from *telegram.User, args []string, allowed func(email string) bool) string {
+	if from == nil {
+		return ""
+	}
+	email, linked := s.users[from.ID]
+	if !linked {
+		return fmt.Sprintf("Your Telegram account (ID %d) is not linked to a Watered user. Ask an admin to add it to TELEGRAM_USERS.", from.ID)
+	}
+	if !allowed(email) {
+		slog.Warn("Rejected Telegram command from a user who is no longer allowed", "audit", true, "telegram_id", from.ID, "email", email)
+		return "Your Watered account is not allowed to water plants."
+	}
+
+	plantID := models.DefaultPlantID
+	if len(args) > 0 {
+		id, err := strconv.Atoi(args[0])
+		if err != nil || id <= 0 || len(args) > 1 {
+			return "Usage: /watered or /watered <plant ID>"
+		}
+		plantID = id
+	}
+
+	plant, err := s.plantService.WaterPlantByID(plantID, email)
+	if errors.Is(err, ErrPlantNotFound) {
+		return fmt.Sprintf("There is no plant with ID %d.", plantID)
+	}
+	if err != nil {
+		slog.Error("Failed to water plant from Telegram", "plant_id", plantID, "email", email, "error", err)
+		return "Sorry, that didn't work. Please try again in the app."
+	}
+
+	slog.Info("Plant watered from Telegram", "audit", true, "plant_id", plant.ID, "email", email, "telegram_id", from.ID)
+	return fmt.Sprintf("💧 %s is watered. Thanks!", plant.Name)
+}
+
+// status describes every plant's health and last watering
+func (s *TelegramService) status() string {
+	plants, err := s.plantService.ListPlants()
+	if err != nil {
+		slog.Error("Failed to list plants for Telegram", "error", err)
+		return "Sorry, I couldn't load the plants. Please try again later."
+	}
+
+	var text strings.Builder
+	for i, plant := range plants {
+		if i > 0 {
+			text.WriteString("\n")
+		}
+		fmt.Fprintf(&text, "🌱 %s (ID %d): %s, last watered %s",
+			plant.Name, plant.ID, strings.ReplaceAll(string(plant.GetHealthStatus()), "_", " "), lastWateredText(plant))
+	}
+	return text.String()
+}
+
+// wateredCommand returns the command that records watering a plant
+func wateredCommand(plantID int) string {
+	if plantID == models.DefaultPlantID {
+		return "/watered"
+	}
+	return fmt.Sprintf("/watered %d", plantID)
+}
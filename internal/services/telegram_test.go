@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/notify/telegram"
+	"watered/internal/storage"
+)
+
+// fakeTelegramSender records messages instead of calling the Bot API
+type fakeTelegramSender struct {
+	chats    []int64
+	messages []string
+}
+
+func (f *fakeTelegramSender) SendMessage(ctx context.Context, chatID int64, text string) error {
+	f.chats = append(f.chats, chatID)
+	f.messages = append(f.messages, text)
+	return nil
+}
+
+func newTestTelegramService(store storage.Storage, sender TelegramSender) *TelegramService {
+	return NewTelegramService(store, NewPlantService(store), sender, config.TelegramConfig{
+		ChatID: -100123,
+		Users:  map[int64]string{111: "alice@example.com", 222: "removed@example.com"},
+	})
+}
+
+func TestTelegramService_Notify(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+	store.CreateUser(&models.User{Email: "alice@example.com", Name: "Alice"})
+
+	if delivered := newTestTelegramService(store, nil).Notify(context.Background(), &models.PlantState{Name: "Fern"}, models.NotificationTriggerDue); delivered != 0 {
+		t.Errorf("Expected no deliveries without a bot, got %d", delivered)
+	}
+
+	sender := &fakeTelegramSender{}
+	service := newTestTelegramService(store, sender)
+
+	lastWatered := time.Now().Add(-50 * time.Hour)
+	plant := &models.PlantState{ID: 1, Name: "Fern", LastWatered: &lastWatered, WateredBy: "alice@example.com"}
+	if delivered := service.Notify(context.Background(), plant, models.NotificationTriggerDue); delivered != 1 {
+		t.Fatalf("Expected one delivery, got %d", delivered)
+	}
+	if sender.chats[0] != -100123 || sender.messages[0] != "💧 Fern is overdue for watering. It was last watered 2 days ago by Alice.\nReply /watered once it's done." {
+		t.Errorf("Unexpected reminder to chat %d: %q", sender.chats[0], sender.messages[0])
+	}
+
+	service.Notify(context.Background(), &models.PlantState{ID: 3, Name: "Cactus"}, models.NotificationTriggerCritical)
+	if sender.messages[1] != "🥀 Cactus needs water now! It has never been watered.\nReply /watered 3 once it's done." {
+		t.Errorf("Unexpected critical reminder: %q", sender.messages[1])
+	}
+}
+
+func TestTelegramService_HandleMessage(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	service := newTestTelegramService(store, &fakeTelegramSender{})
+	allowed := func(email string) bool { return email == "alice@example.com" }
+	message := func(from int64, text string) *telegram.Message {
+		return &telegram.Message{From: &telegram.User{ID: from}, Chat: telegram.Chat{ID: -100123}, Text: text}
+	}
+
+	tests := []struct {
+		name  string
+		from  int64
+		text  string
+		reply string
+	}{
+		{"plain text", 111, "thanks everyone", ""},
+		{"help", 999, "/help", "Commands:"},
+		{"unknown command", 111, "/dance", "Sorry, I don't know that command."},
+		{"unlinked user", 999, "/watered", "Your Telegram account (ID 999) is not linked"},
+		{"no longer allowed", 222, "/watered", "not allowed to water plants"},
+		{"bad plant ID", 111, "/watered fern", "Usage: /watered"},
+		{"missing plant", 111, "/watered 42", "There is no plant with ID 42."},
+		{"status", 999, "/status", "🌱 Our Plant (ID 1): critical, last watered never"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := service.HandleMessage(message(tt.from, tt.text), allowed)
+			if tt.reply == "" && reply != "" || !strings.Contains(reply, tt.reply) {
+				t.Errorf("Expected a reply containing %q, got %q", tt.reply, reply)
+			}
+		})
+	}
+
+	// Group chats address commands to the bot by name
+	if reply := service.HandleMessage(message(111, "/watered@WateredBot"), allowed); reply != "💧 Our Plant is watered. Thanks!" {
+		t.Errorf("Unexpected reply: %q", reply)
+	}
+	plant, _ := store.GetPlant(models.DefaultPlantID)
+	if plant.LastWatered == nil || plant.WateredBy != "alice@example.com" {
+		t.Errorf("Expected the plant to be watered by the linked user, got %+v", plant)
+	}
+}
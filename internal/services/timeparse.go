@@ -0,0 +1,270 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeParseError describes why a user-entered timestamp could not be used
+type TimeParseError struct {
+	Input  string
+	Reason string
+}
+
+func (e *TimeParseError) Error() string {
+	return fmt.Sprintf("cannot use %q: %s", e.Input, e.Reason)
+}
+
+// AcceptedTimeFormats lists example inputs the parser understands, for error messages and docs
+var AcceptedTimeFormats = []string{
+	"2024-05-01T18:00:00Z (RFC3339)",
+	"2024-05-01 18:00",
+	"1714586400 (unix seconds)",
+	"yesterday 18:00 / gestern 18:00 / ayer 18:00 / hier 18h",
+	"today 6pm",
+	"3 hours ago / vor 3 Stunden / hace 3 horas / il y a 3 heures",
+}
+
+// relativeDays maps words for today/yesterday in supported languages to a day offset
+var relativeDays = map[string]int{
+	// English
+	"now": 0, "today": 0, "yesterday": -1,
+	// German
+	"jetzt": 0, "heute": 0, "gestern": -1, "vorgestern": -2,
+	// Spanish
+	"ahora": 0, "hoy": 0, "ayer": -1, "anteayer": -2,
+	// French
+	"maintenant": 0, "aujourd'hui": 0, "hier": -1, "avant-hier": -2,
+	// Italian
+	"adesso": 0, "oggi": 0, "ieri": -1,
+	// Dutch
+	"nu": 0, "vandaag": 0, "gisteren": -1, "eergisteren": -2,
+	// Portuguese
+	"agora": 0, "hoje": 0, "ontem": -1, "anteontem": -2,
+}
+
+// nowWords are relative words that mean the current instant when used alone
+var nowWords = map[string]bool{
+	"now": true, "jetzt": true, "ahora": true, "maintenant": true,
+	"adesso": true, "nu": true, "agora": true,
+}
+
+// connectorWords are filler words between a day and a time ("yesterday at 6pm")
+var connectorWords = map[string]bool{
+	"at": true, "um": true, "à": true, "a": true, "las": true, "la": true,
+	"alle": true, "om": true, "às": true, "uhr": true,
+}
+
+var (
+	agoPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^(\d+)\s*(minutes?|mins?|hours?|hrs?|days?)\s+ago$`),
+		regexp.MustCompile(`^vor\s+(\d+)\s*(minuten?|stunden?|tagen?|tag)$`),
+		regexp.MustCompile(`^hace\s+(\d+)\s*(minutos?|horas?|d[ií]as?)$`),
+		regexp.MustCompile(`^il y a\s+(\d+)\s*(minutes?|heures?|jours?)$`),
+		regexp.MustCompile(`^(\d+)\s*(minuti|minuto|ore|ora|giorni|giorno)\s+fa$`),
+		regexp.MustCompile(`^(\d+)\s*(minuten?|uur|uren|dagen?)\s+geleden$`),
+		regexp.MustCompile(`^h[aá]\s+(\d+)\s*(minutos?|horas?|dias?)$`),
+	}
+	clockPattern = regexp.MustCompile(`^(\d{1,2})(?:[:h.](\d{2}))?\s*(am|pm)?$`)
+	unixPattern  = regexp.MustCompile(`^\d{9,13}$`)
+)
+
+// TimeParser turns the many ways people type dates into timestamps, interpreting
+// local times in the user's timezone and ambiguous numeric dates by locale
+type TimeParser struct {
+	location  *time.Location
+	locale    string
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+// NewTimeParser creates a parser for the given timezone and locale (e.g. "en-US", "de")
+func NewTimeParser(location *time.Location, locale string) *TimeParser {
+	if location == nil {
+		location = time.UTC
+	}
+	return &TimeParser{
+		location:  location,
+		locale:    strings.ToLower(strings.TrimSpace(locale)),
+		tolerance: DefaultClockSkewTolerance,
+		now:       time.Now,
+	}
+}
+
+// SetSkewTolerance sets how far in the future a timestamp may be, to allow
+// for client clocks running slightly ahead
+func (p *TimeParser) SetSkewTolerance(d time.Duration) {
+	p.tolerance = d
+}
+
+// Parse interprets input as a past point in time
+func (p *TimeParser) Parse(input string) (time.Time, error) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(input), " "))
+	if normalized == "" {
+		return time.Time{}, &TimeParseError{Input: input, Reason: "timestamp is empty"}
+	}
+
+	t, ok := p.parseAbsolute(normalized)
+	if !ok {
+		t, ok = p.parseAgo(normalized)
+	}
+	if !ok {
+		var err error
+		t, ok, err = p.parseRelativeDay(normalized)
+		if err != nil {
+			return time.Time{}, &TimeParseError{Input: input, Reason: err.Error()}
+		}
+	}
+	if !ok {
+		return time.Time{}, &TimeParseError{
+			Input:  input,
+			Reason: "unrecognized format, try one of: " + strings.Join(AcceptedTimeFormats, "; "),
+		}
+	}
+
+	// Allow a little clock skew but no future waterings
+	if t.After(p.now().Add(p.tolerance)) {
+		return time.Time{}, &TimeParseError{Input: input, Reason: "timestamp is in the future"}
+	}
+
+	return t, nil
+}
+
+// usesMonthFirst reports whether numeric dates are month-first for this locale
+func (p *TimeParser) usesMonthFirst() bool {
+	return p.locale == "en-us" || p.locale == "en_us" || p.locale == "us"
+}
+
+// parseAbsolute handles RFC3339, unix timestamps and numeric calendar dates
+func (p *TimeParser) parseAbsolute(input string) (time.Time, bool) {
+	if unixPattern.MatchString(input) {
+		value, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if len(input) == 13 {
+			return time.UnixMilli(value).In(p.location), true
+		}
+		return time.Unix(value, 0).In(p.location), true
+	}
+
+	// Formats that carry their own offset
+	for _, layout := range []string{time.RFC3339, time.RFC3339Nano} {
+		if t, err := time.Parse(layout, strings.ToUpper(input)); err == nil {
+			return t, true
+		}
+	}
+
+	layouts := []string{
+		"2006-01-02t15:04:05", "2006-01-02t15:04", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02",
+		"02.01.2006 15:04", "02.01.2006",
+	}
+	if p.usesMonthFirst() {
+		layouts = append(layouts, "01/02/2006 15:04", "01/02/2006 3:04pm", "01/02/2006")
+	} else {
+		layouts = append(layouts, "02/01/2006 15:04", "02/01/2006")
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, input, p.location); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseAgo handles "3 hours ago" and its translations
+func (p *TimeParser) parseAgo(input string) (time.Time, bool) {
+	for _, pattern := range agoPatterns {
+		match := pattern.FindStringSubmatch(input)
+		if match == nil {
+			continue
+		}
+
+		amount, err := strconv.Atoi(match[1])
+		if err != nil {
+			return time.Time{}, false
+		}
+
+		unit := time.Hour
+		switch {
+		case strings.HasPrefix(match[2], "m"):
+			unit = time.Minute
+		case strings.HasPrefix(match[2], "d"), strings.HasPrefix(match[2], "t"),
+			strings.HasPrefix(match[2], "j"), strings.HasPrefix(match[2], "g"):
+			unit = 24 * time.Hour
+		}
+		return p.now().Add(-time.Duration(amount) * unit), true
+	}
+	return time.Time{}, false
+}
+
+// parseRelativeDay handles "yesterday 18:00", "gestern um 18 Uhr", "hier à 18h30"
+func (p *TimeParser) parseRelativeDay(input string) (time.Time, bool, error) {
+	words := strings.Fields(input)
+	offset, ok := relativeDays[words[0]]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	rest := []string{}
+	for _, word := range words[1:] {
+		if !connectorWords[word] {
+			rest = append(rest, word)
+		}
+	}
+
+	now := p.now().In(p.location)
+	if len(rest) == 0 {
+		if nowWords[words[0]] {
+			return now, true, nil
+		}
+		if offset == 0 {
+			return time.Time{}, false, fmt.Errorf("please include a time, e.g. %q", words[0]+" 08:30")
+		}
+		// A day without a time means noon, which is the least surprising guess
+		rest = []string{"12:00"}
+	}
+
+	hour, minute, err := parseClock(strings.Join(rest, ""))
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	day := now.AddDate(0, 0, offset)
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, p.location), true, nil
+}
+
+// parseClock parses "18:00", "6pm", "6:30pm", "18h30" and "18.00"
+func parseClock(value string) (int, int, error) {
+	match := clockPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, 0, fmt.Errorf("unrecognized time of day %q", value)
+	}
+
+	hour, _ := strconv.Atoi(match[1])
+	minute := 0
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+
+	if match[3] != "" {
+		// 12-hour clock
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("time of day %q is out of range", value)
+		}
+		if match[3] == "am" && hour == 12 {
+			hour = 0
+		} else if match[3] == "pm" && hour < 12 {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("time of day %q is out of range", value)
+	}
+	return hour, minute, nil
+}
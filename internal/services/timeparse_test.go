@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestTimeParser(locale string) *TimeParser {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	parser := NewTimeParser(berlin, locale)
+	parser.now = func() time.Time {
+		return time.Date(2024, 5, 10, 20, 0, 0, 0, berlin)
+	}
+	return parser
+}
+
+func TestTimeParser_Parse(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	tests := []struct {
+		name     string
+		locale   string
+		input    string
+		expected time.Time
+	}{
+		{"RFC3339", "en", "2024-05-09T16:00:00Z", time.Date(2024, 5, 9, 16, 0, 0, 0, time.UTC)},
+		{"ISO local", "en", "2024-05-09 18:30", time.Date(2024, 5, 9, 18, 30, 0, 0, berlin)},
+		{"unix seconds", "en", "1715270400", time.Unix(1715270400, 0)},
+		{"unix milliseconds", "en", "1715270400000", time.Unix(1715270400, 0)},
+		{"US date", "en-US", "05/09/2024 18:00", time.Date(2024, 5, 9, 18, 0, 0, 0, berlin)},
+		{"European date", "en-GB", "09/05/2024 18:00", time.Date(2024, 5, 9, 18, 0, 0, 0, berlin)},
+		{"German date", "de", "09.05.2024 18:00", time.Date(2024, 5, 9, 18, 0, 0, 0, berlin)},
+		{"yesterday", "en", "yesterday 18:00", time.Date(2024, 5, 9, 18, 0, 0, 0, berlin)},
+		{"yesterday at 6pm", "en", "Yesterday at 6pm", time.Date(2024, 5, 9, 18, 0, 0, 0, berlin)},
+		{"today 8:30am", "en", "today 8:30am", time.Date(2024, 5, 10, 8, 30, 0, 0, berlin)},
+		{"German yesterday", "de", "gestern um 18 Uhr", time.Date(2024, 5, 9, 18, 0, 0, 0, berlin)},
+		{"German day before", "de", "vorgestern 07:15", time.Date(2024, 5, 8, 7, 15, 0, 0, berlin)},
+		{"Spanish yesterday", "es", "ayer a las 18:00", time.Date(2024, 5, 9, 18, 0, 0, 0, berlin)},
+		{"French yesterday", "fr", "hier à 18h30", time.Date(2024, 5, 9, 18, 30, 0, 0, berlin)},
+		{"yesterday without time", "en", "yesterday", time.Date(2024, 5, 9, 12, 0, 0, 0, berlin)},
+		{"now", "en", "now", time.Date(2024, 5, 10, 20, 0, 0, 0, berlin)},
+		{"hours ago", "en", "3 hours ago", time.Date(2024, 5, 10, 17, 0, 0, 0, berlin)},
+		{"German ago", "de", "vor 2 Tagen", time.Date(2024, 5, 8, 20, 0, 0, 0, berlin)},
+		{"Spanish ago", "es", "hace 30 minutos", time.Date(2024, 5, 10, 19, 30, 0, 0, berlin)},
+		{"French ago", "fr", "il y a 1 jour", time.Date(2024, 5, 9, 20, 0, 0, 0, berlin)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := newTestTimeParser(tt.locale).Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse %q: %v", tt.input, err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTimeParser_ParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		reason string
+	}{
+		{"empty", "  ", "timestamp is empty"},
+		{"garbage", "last blue moon", "unrecognized format"},
+		{"future", "2030-01-01 10:00", "timestamp is in the future"},
+		{"bad clock", "yesterday 25:00", "out of range"},
+		{"bad 12h clock", "yesterday 13pm", "out of range"},
+		{"today without time", "today", "please include a time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestTimeParser("en").Parse(tt.input)
+			var parseErr *TimeParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected TimeParseError, got %v", err)
+			}
+			if !strings.Contains(parseErr.Reason, tt.reason) {
+				t.Errorf("Expected reason containing %q, got %q", tt.reason, parseErr.Reason)
+			}
+		})
+	}
+}
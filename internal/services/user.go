@@ -0,0 +1,500 @@
+package services
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"watered/internal/i18n"
+	"watered/internal/models"
+	"watered/internal/randtoken"
+	"watered/internal/storage"
+)
+
+// UserService handles user account management
+type UserService struct {
+	storage storage.Storage
+}
+
+// NewUserService creates a new user service
+func NewUserService(storage storage.Storage) *UserService {
+	return &UserService{
+		storage: storage,
+	}
+}
+
+// MergeUsers moves everything owned by fromEmail to toEmail. With dryRun set
+// it only reports what would change.
+func (s *UserService) MergeUsers(fromEmail, toEmail string, dryRun bool) (*models.UserMergeResult, error) {
+	fromEmail = strings.TrimSpace(strings.ToLower(fromEmail))
+	toEmail = strings.TrimSpace(strings.ToLower(toEmail))
+
+	if fromEmail == "" || toEmail == "" {
+		return nil, fmt.Errorf("both from and to emails are required")
+	}
+	if fromEmail == toEmail {
+		return nil, fmt.Errorf("cannot merge a user into itself")
+	}
+
+	result := &models.UserMergeResult{
+		FromEmail:  fromEmail,
+		ToEmail:    toEmail,
+		DryRun:     dryRun,
+		Reassigned: make(map[string]int),
+		Changes:    []string{},
+	}
+
+	if err := s.mergeUserRecord(result); err != nil {
+		return nil, err
+	}
+	if err := s.mergeAccessLists(result); err != nil {
+		return nil, err
+	}
+	if err := s.mergePlant(result); err != nil {
+		return nil, err
+	}
+	if err := s.mergeNotifications(result); err != nil {
+		return nil, err
+	}
+	if err := s.mergeWaterings(result); err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		slog.Info("Merged users", "audit", true, "from", fromEmail, "to", toEmail, "reassigned", result.Reassigned)
+	}
+	return result, nil
+}
+
+// mergeUserRecord keeps the earliest join date and removes the old user record
+func (s *UserService) mergeUserRecord(result *models.UserMergeResult) error {
+	from, err := s.storage.GetUser(result.FromEmail)
+	if err != nil {
+		return fmt.Errorf("failed to get user %s: %w", result.FromEmail, err)
+	}
+	if from == nil {
+		return nil
+	}
+
+	to, err := s.storage.GetUser(result.ToEmail)
+	if err != nil {
+		return fmt.Errorf("failed to get user %s: %w", result.ToEmail, err)
+	}
+
+	result.Reassigned["users"] = 1
+	result.Changes = append(result.Changes, fmt.Sprintf("remove user record %s", result.FromEmail))
+	if result.DryRun {
+		return nil
+	}
+
+	if to == nil {
+		merged := *from
+		merged.Email = result.ToEmail
+		to = &merged
+	} else {
+		if from.JoinedAt.Before(to.JoinedAt) {
+			to.JoinedAt = from.JoinedAt
+		}
+		to.IsAdmin = to.IsAdmin || from.IsAdmin
+		if to.Locale == "" {
+			to.Locale = from.Locale
+		}
+	}
+
+	if err := s.storage.CreateUser(to); err != nil {
+		return fmt.Errorf("failed to save user %s: %w", result.ToEmail, err)
+	}
+	if err := s.storage.DeleteUser(result.FromEmail); err != nil {
+		return fmt.Errorf("failed to delete user %s: %w", result.FromEmail, err)
+	}
+	return nil
+}
+
+// mergeAccessLists replaces the old email in the allow and admin lists
+func (s *UserService) mergeAccessLists(result *models.UserMergeResult) error {
+	config, err := s.storage.GetAdminConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get admin config: %w", err)
+	}
+	if config == nil {
+		return nil
+	}
+
+	allowed, allowedChanged := replaceEmail(config.AllowedEmails, result.FromEmail, result.ToEmail)
+	admins, adminsChanged := replaceEmail(config.AdminEmails, result.FromEmail, result.ToEmail)
+	viewers, viewersChanged := replaceEmail(config.ViewerEmails, result.FromEmail, result.ToEmail)
+	if !allowedChanged && !adminsChanged && !viewersChanged {
+		return nil
+	}
+
+	if allowedChanged {
+		result.Reassigned["allowed_emails"] = 1
+		result.Changes = append(result.Changes, "replace email in allowed users")
+	}
+	if adminsChanged {
+		result.Reassigned["admin_emails"] = 1
+		result.Changes = append(result.Changes, "replace email in admin users")
+	}
+	if viewersChanged {
+		result.Reassigned["viewer_emails"] = 1
+		result.Changes = append(result.Changes, "replace email in viewer users")
+	}
+	if result.DryRun {
+		return nil
+	}
+
+	config.AllowedEmails = allowed
+	config.AdminEmails = admins
+	config.ViewerEmails = viewers
+	if err := s.storage.UpdateAdminConfig(config); err != nil {
+		return fmt.Errorf("failed to update admin config: %w", err)
+	}
+	return nil
+}
+
+// mergePlant reassigns the last waterer of every plant
+func (s *UserService) mergePlant(result *models.UserMergeResult) error {
+	plants, err := s.storage.ListPlants()
+	if err != nil {
+		return fmt.Errorf("failed to list plants: %w", err)
+	}
+
+	for _, plant := range plants {
+		if plant.WateredBy != result.FromEmail {
+			continue
+		}
+
+		result.Reassigned["plants"]++
+		result.Changes = append(result.Changes, fmt.Sprintf("reassign last watering of %s", plant.Name))
+		if result.DryRun {
+			continue
+		}
+
+		plant.WateredBy = result.ToEmail
+		if err := s.storage.UpdatePlant(plant); err != nil {
+			return fmt.Errorf("failed to update plant %d: %w", plant.ID, err)
+		}
+	}
+	return nil
+}
+
+// mergeNotifications reassigns notification history
+func (s *UserService) mergeNotifications(result *models.UserMergeResult) error {
+	if result.DryRun {
+		notifications, err := s.storage.ListNotifications(models.NotificationFilter{UserEmail: result.FromEmail})
+		if err != nil {
+			return fmt.Errorf("failed to list notifications: %w", err)
+		}
+		if len(notifications) > 0 {
+			result.Reassigned["notifications"] = len(notifications)
+			result.Changes = append(result.Changes, fmt.Sprintf("reassign %d notifications", len(notifications)))
+		}
+		return nil
+	}
+
+	count, err := s.storage.ReassignNotifications(result.FromEmail, result.ToEmail)
+	if err != nil {
+		return fmt.Errorf("failed to reassign notifications: %w", err)
+	}
+	if count > 0 {
+		result.Reassigned["notifications"] = count
+		result.Changes = append(result.Changes, fmt.Sprintf("reassign %d notifications", count))
+	}
+	return nil
+}
+
+// mergeWaterings reassigns watering history
+func (s *UserService) mergeWaterings(result *models.UserMergeResult) error {
+	if result.DryRun {
+		events, err := s.storage.ListWateringEvents(0)
+		if err != nil {
+			return fmt.Errorf("failed to list watering events: %w", err)
+		}
+		count := 0
+		for _, event := range events {
+			if event.WateredBy == result.FromEmail {
+				count++
+			}
+		}
+		if count > 0 {
+			result.Reassigned["waterings"] = count
+			result.Changes = append(result.Changes, fmt.Sprintf("reassign %d waterings", count))
+		}
+		return nil
+	}
+
+	count, err := s.storage.ReassignWateringEvents(result.FromEmail, result.ToEmail)
+	if err != nil {
+		return fmt.Errorf("failed to reassign watering events: %w", err)
+	}
+	if count > 0 {
+		result.Reassigned["waterings"] = count
+		result.Changes = append(result.Changes, fmt.Sprintf("reassign %d waterings", count))
+	}
+	return nil
+}
+
+// replaceEmail swaps from for to in a list without introducing duplicates
+func replaceEmail(emails []string, from, to string) ([]string, bool) {
+	found := false
+	hasTo := false
+	for _, email := range emails {
+		if email == from {
+			found = true
+		}
+		if email == to {
+			hasTo = true
+		}
+	}
+	if !found {
+		return emails, false
+	}
+
+	result := make([]string, 0, len(emails))
+	for _, email := range emails {
+		if email != from {
+			result = append(result, email)
+		} else if !hasTo {
+			result = append(result, to)
+		}
+	}
+	return result, true
+}
+
+// ExportAccount collects everything stored about email: the user record,
+// activity, waterings, care task completions, notifications and push
+// subscriptions. The caller fills in the role.
+func (s *UserService) ExportAccount(email string) (*models.AccountExport, error) {
+	email = strings.TrimSpace(strings.ToLower(email))
+	export := &models.AccountExport{
+		ExportedAt:        time.Now(),
+		Email:             email,
+		Waterings:         []*models.PlantWateringEvent{},
+		CareTaskEvents:    []*models.CareTaskEvent{},
+		PushSubscriptions: []*models.PushSubscription{},
+	}
+
+	user, err := s.storage.GetUser(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %s: %w", email, err)
+	}
+	export.User = user
+
+	activity, err := s.storage.ListUserActivity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user activity: %w", err)
+	}
+	for _, entry := range activity {
+		if entry.Email == email {
+			export.Activity = entry
+		}
+	}
+
+	waterings, err := s.storage.ListWateringEvents(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watering events: %w", err)
+	}
+	for _, event := range waterings {
+		if event.WateredBy == email {
+			export.Waterings = append(export.Waterings, event)
+		}
+	}
+
+	tasks, err := s.storage.ListCareTasks(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list care tasks: %w", err)
+	}
+	for _, task := range tasks {
+		events, err := s.storage.ListCareTaskEvents(task.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list care task events: %w", err)
+		}
+		for _, event := range events {
+			if event.DoneBy == email {
+				export.CareTaskEvents = append(export.CareTaskEvents, event)
+			}
+		}
+	}
+
+	if export.Notifications, err = s.storage.ListNotifications(models.NotificationFilter{UserEmail: email}); err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	if export.PushSubscriptions, err = s.storage.ListPushSubscriptions(email); err != nil {
+		return nil, fmt.Errorf("failed to list push subscriptions: %w", err)
+	}
+	return export, nil
+}
+
+// DeleteAccount removes email's user record, push subscriptions and place on
+// the allowlist. Their waterings, care task completions and notifications
+// stay in the history under a random placeholder, so nothing left links
+// back to them. Activity and sessions are kept outside storage, so the
+// caller clears those.
+func (s *UserService) DeleteAccount(email string) (*models.AccountDeletion, error) {
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == "" {
+		return nil, fmt.Errorf("email is required")
+	}
+	suffix, err := randtoken.String(8, hex.EncodeToString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate replacement ID: %w", err)
+	}
+	result := &models.AccountDeletion{
+		Email:      email,
+		ReplacedBy: models.DeletedUserPrefix + suffix,
+		Anonymized: make(map[string]int),
+		Deleted:    make(map[string]int),
+	}
+
+	if err := s.anonymizeHistory(result); err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := s.storage.ListPushSubscriptions(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list push subscriptions: %w", err)
+	}
+	for _, subscription := range subscriptions {
+		if err := s.storage.DeletePushSubscription(subscription.Endpoint); err != nil {
+			return nil, fmt.Errorf("failed to delete push subscription: %w", err)
+		}
+		result.Deleted["push_subscriptions"]++
+	}
+
+	user, err := s.storage.GetUser(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %s: %w", email, err)
+	}
+	if user != nil {
+		if err := s.storage.DeleteUser(email); err != nil {
+			return nil, fmt.Errorf("failed to delete user %s: %w", email, err)
+		}
+		result.Deleted["users"] = 1
+	}
+
+	if err := s.removeFromAccessLists(result); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Deleted account", "audit", true, "email", email, "replaced_by", result.ReplacedBy, "anonymized", result.Anonymized, "deleted", result.Deleted)
+	return result, nil
+}
+
+// anonymizeHistory moves a deleted user's history to their placeholder
+func (s *UserService) anonymizeHistory(result *models.AccountDeletion) error {
+	count, err := s.storage.ReassignWateringEvents(result.Email, result.ReplacedBy)
+	if err != nil {
+		return fmt.Errorf("failed to anonymize watering events: %w", err)
+	}
+	result.Anonymized["waterings"] = count
+
+	if count, err = s.storage.ReassignCareTaskEvents(result.Email, result.ReplacedBy); err != nil {
+		return fmt.Errorf("failed to anonymize care task events: %w", err)
+	}
+	result.Anonymized["care_task_events"] = count
+
+	if count, err = s.storage.ReassignNotifications(result.Email, result.ReplacedBy); err != nil {
+		return fmt.Errorf("failed to anonymize notifications: %w", err)
+	}
+	result.Anonymized["notifications"] = count
+
+	plants, err := s.storage.ListPlants()
+	if err != nil {
+		return fmt.Errorf("failed to list plants: %w", err)
+	}
+	for _, plant := range plants {
+		if plant.WateredBy != result.Email {
+			continue
+		}
+		plant.WateredBy = result.ReplacedBy
+		if err := s.storage.UpdatePlant(plant); err != nil {
+			return fmt.Errorf("failed to update plant %d: %w", plant.ID, err)
+		}
+		result.Anonymized["plants"]++
+	}
+
+	tasks, err := s.storage.ListCareTasks(0)
+	if err != nil {
+		return fmt.Errorf("failed to list care tasks: %w", err)
+	}
+	for _, task := range tasks {
+		if task.LastDoneBy != result.Email {
+			continue
+		}
+		task.LastDoneBy = result.ReplacedBy
+		if err := s.storage.SaveCareTask(task); err != nil {
+			return fmt.Errorf("failed to update care task %d: %w", task.ID, err)
+		}
+		result.Anonymized["care_tasks"]++
+	}
+	return nil
+}
+
+// removeFromAccessLists takes a deleted user off the allow, admin and viewer
+// lists. Emails allowed through the environment stay allowed.
+func (s *UserService) removeFromAccessLists(result *models.AccountDeletion) error {
+	config, err := s.storage.GetAdminConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get admin config: %w", err)
+	}
+	if config == nil {
+		return nil
+	}
+
+	isEmail := func(email string) bool { return email == result.Email }
+	before := len(config.AllowedEmails) + len(config.AdminEmails) + len(config.ViewerEmails)
+	config.AllowedEmails = slices.DeleteFunc(config.AllowedEmails, isEmail)
+	config.AdminEmails = slices.DeleteFunc(config.AdminEmails, isEmail)
+	config.ViewerEmails = slices.DeleteFunc(config.ViewerEmails, isEmail)
+	if removed := before - len(config.AllowedEmails) - len(config.AdminEmails) - len(config.ViewerEmails); removed > 0 {
+		if err := s.storage.UpdateAdminConfig(config); err != nil {
+			return fmt.Errorf("failed to update admin config: %w", err)
+		}
+		result.Deleted["access_list_entries"] = removed
+	}
+	return nil
+}
+
+// ErrUnsupportedLocale is returned when a user picks a language without a
+// catalog
+var ErrUnsupportedLocale = errors.New("unsupported locale")
+
+// Locale returns the language email chose, or "" when they did not choose
+// one or cannot be looked up
+func (s *UserService) Locale(email string) string {
+	user, err := s.storage.GetUser(email)
+	if err != nil || user == nil {
+		return ""
+	}
+	return user.Locale
+}
+
+// SetLocale stores the language email chose, such as "es" or "es-MX"; ""
+// goes back to following the browser. It returns the stored language.
+func (s *UserService) SetLocale(email, locale string) (string, error) {
+	if locale != "" {
+		normalized, ok := i18n.Normalize(locale)
+		if !ok {
+			return "", fmt.Errorf("%w %q, choose one of %s", ErrUnsupportedLocale, locale, strings.Join(i18n.Supported(), ", "))
+		}
+		locale = normalized
+	}
+
+	user, err := s.storage.GetUser(email)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user %s: %w", email, err)
+	}
+	if user == nil {
+		// Users signed in before their record was stored
+		user = &models.User{Email: email, JoinedAt: time.Now()}
+	}
+	user.Locale = locale
+	if err := s.storage.CreateUser(user); err != nil {
+		return "", fmt.Errorf("failed to save user %s: %w", email, err)
+	}
+	return locale, nil
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+)
+
+func setupMergeStorage() *storage.MemoryStorage {
+	store := storage.NewMemoryStorage()
+	now := time.Now()
+
+	store.CreateUser(&models.User{Email: "personal@example.com", Name: "Sam", JoinedAt: now.Add(-48 * time.Hour)})
+	store.CreateUser(&models.User{Email: "work@example.com", Name: "Sam", JoinedAt: now})
+	store.UpdateAdminConfig(&models.AdminConfig{
+		TimeoutHours:  24,
+		AllowedEmails: []string{"personal@example.com", "work@example.com"},
+		AdminEmails:   []string{"personal@example.com"},
+	})
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Our Plant", TimeoutHours: 24, LastWatered: &now, WateredBy: "personal@example.com"})
+	store.CreateNotification(&models.Notification{UserEmail: "personal@example.com", Channel: "email"})
+	store.CreateNotification(&models.Notification{UserEmail: "personal@example.com", Channel: "push"})
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 1, WateredAt: now, WateredBy: "personal@example.com"})
+
+	return store
+}
+
+func TestUserService_MergeUsers_DryRun(t *testing.T) {
+	store := setupMergeStorage()
+	service := NewUserService(store)
+
+	result, err := service.MergeUsers("personal@example.com", "work@example.com", true)
+	if err != nil {
+		t.Fatalf("Failed to preview merge: %v", err)
+	}
+
+	if result.Reassigned["notifications"] != 2 {
+		t.Errorf("Expected 2 notifications in preview, got %d", result.Reassigned["notifications"])
+	}
+	if result.Reassigned["plants"] != 1 {
+		t.Errorf("Expected plant in preview, got %d", result.Reassigned["plants"])
+	}
+	if result.Reassigned["waterings"] != 1 {
+		t.Errorf("Expected watering in preview, got %d", result.Reassigned["waterings"])
+	}
+
+	// Nothing should have changed
+	if user, _ := store.GetUser("personal@example.com"); user == nil {
+		t.Error("Expected dry run to keep the old user")
+	}
+	if plant, _ := store.GetPlantState(); plant.WateredBy != "personal@example.com" {
+		t.Errorf("Expected dry run to keep waterer, got %s", plant.WateredBy)
+	}
+}
+
+func TestUserService_MergeUsers(t *testing.T) {
+	store := setupMergeStorage()
+	service := NewUserService(store)
+
+	if _, err := service.MergeUsers("Personal@Example.com", "work@example.com", false); err != nil {
+		t.Fatalf("Failed to merge users: %v", err)
+	}
+
+	if user, _ := store.GetUser("personal@example.com"); user != nil {
+		t.Error("Expected old user record to be removed")
+	}
+	user, _ := store.GetUser("work@example.com")
+	if user == nil || user.JoinedAt.After(time.Now().Add(-47*time.Hour)) {
+		t.Errorf("Expected merged user to keep earliest join date, got %v", user)
+	}
+
+	config, _ := store.GetAdminConfig()
+	if len(config.AllowedEmails) != 1 || config.AllowedEmails[0] != "work@example.com" {
+		t.Errorf("Expected allowlist to contain only work email, got %v", config.AllowedEmails)
+	}
+	if len(config.AdminEmails) != 1 || config.AdminEmails[0] != "work@example.com" {
+		t.Errorf("Expected admin list to carry over, got %v", config.AdminEmails)
+	}
+
+	if plant, _ := store.GetPlantState(); plant.WateredBy != "work@example.com" {
+		t.Errorf("Expected waterer to be reassigned, got %s", plant.WateredBy)
+	}
+
+	notifications, _ := store.ListNotifications(models.NotificationFilter{UserEmail: "work@example.com"})
+	if len(notifications) != 2 {
+		t.Errorf("Expected 2 reassigned notifications, got %d", len(notifications))
+	}
+
+	if events, _ := store.ListWateringEvents(0); len(events) != 1 || events[0].WateredBy != "work@example.com" {
+		t.Errorf("Expected watering history to be reassigned, got %+v", events)
+	}
+}
+
+func TestUserService_MergeUsers_Validation(t *testing.T) {
+	service := NewUserService(storage.NewMemoryStorage())
+
+	if _, err := service.MergeUsers("", "work@example.com", false); err == nil {
+		t.Error("Expected error for missing from email")
+	}
+	if _, err := service.MergeUsers("a@example.com", "A@example.com", false); err == nil {
+		t.Error("Expected error when merging a user into itself")
+	}
+}
+
+func TestUserService_SetLocale(t *testing.T) {
+	store := setupMergeStorage()
+	service := NewUserService(store)
+
+	locale, err := service.SetLocale("work@example.com", "es-MX")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if locale != "es" || service.Locale("work@example.com") != "es" {
+		t.Errorf("Expected es stored, got %q", service.Locale("work@example.com"))
+	}
+	if user, _ := store.GetUser("work@example.com"); user.Name != "Sam" {
+		t.Error("Expected the rest of the user record kept")
+	}
+
+	if _, err := service.SetLocale("work@example.com", "tlh"); !errors.Is(err, ErrUnsupportedLocale) {
+		t.Errorf("Expected ErrUnsupportedLocale, got %v", err)
+	}
+
+	// Users without a record get one
+	if _, err := service.SetLocale("new@example.com", "en"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if service.Locale("new@example.com") != "en" {
+		t.Error("Expected the locale stored for a new user")
+	}
+
+	if _, err := service.SetLocale("work@example.com", ""); err != nil || service.Locale("work@example.com") != "" {
+		t.Errorf("Expected the preference cleared, got %q (%v)", service.Locale("work@example.com"), err)
+	}
+}
+
+func TestUserService_ExportAccount(t *testing.T) {
+	store := setupMergeStorage()
+	service := NewUserService(store)
+	store.SaveCareTask(&models.CareTask{ID: 1, PlantID: 1, Type: models.CareTaskMist, IntervalHours: 72})
+	store.AddCareTaskEvent(&models.CareTaskEvent{TaskID: 1, PlantID: 1, Type: models.CareTaskMist, DoneBy: "personal@example.com"})
+	store.AddCareTaskEvent(&models.CareTaskEvent{TaskID: 1, PlantID: 1, Type: models.CareTaskMist, DoneBy: "work@example.com"})
+	store.SavePushSubscription(&models.PushSubscription{UserEmail: "personal@example.com", Endpoint: "https://push.example.com/1"})
+	store.SaveUserActivity([]*models.UserActivity{{Email: "personal@example.com", UserAgent: "Firefox"}})
+
+	export, err := service.ExportAccount(" Personal@example.com ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if export.Email != "personal@example.com" || export.User == nil || export.User.Name != "Sam" {
+		t.Errorf("Expected the user record, got %+v", export.User)
+	}
+	if export.Activity == nil || export.Activity.UserAgent != "Firefox" {
+		t.Errorf("Expected the activity record, got %+v", export.Activity)
+	}
+	if len(export.Waterings) != 1 || len(export.CareTaskEvents) != 1 || len(export.Notifications) != 2 || len(export.PushSubscriptions) != 1 {
+		t.Errorf("Expected only personal@example.com's history, got %+v", export)
+	}
+
+	// Someone who hasn't done anything yet still gets a complete export
+	export, err = service.ExportAccount("new@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if export.User != nil || export.Waterings == nil || len(export.Waterings) != 0 {
+		t.Errorf("Expected an empty export, got %+v", export)
+	}
+}
+
+func TestUserService_DeleteAccount(t *testing.T) {
+	store := setupMergeStorage()
+	service := NewUserService(store)
+	store.SaveCareTask(&models.CareTask{ID: 1, PlantID: 1, Type: models.CareTaskMist, IntervalHours: 72, LastDoneBy: "work@example.com"})
+	store.AddCareTaskEvent(&models.CareTaskEvent{TaskID: 1, PlantID: 1, Type: models.CareTaskMist, DoneBy: "work@example.com"})
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 1, WateredAt: time.Now(), WateredBy: "work@example.com"})
+	store.SavePushSubscription(&models.PushSubscription{UserEmail: "work@example.com", Endpoint: "https://push.example.com/1"})
+	plant, _ := store.GetPlantState()
+	plant.WateredBy = "work@example.com"
+	store.UpdatePlantState(plant)
+
+	result, err := service.DeleteAccount("work@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(result.ReplacedBy, models.DeletedUserPrefix) {
+		t.Errorf("Expected a deleted-user placeholder, got %q", result.ReplacedBy)
+	}
+	if result.Anonymized["waterings"] != 1 || result.Anonymized["care_task_events"] != 1 || result.Anonymized["plants"] != 1 || result.Anonymized["care_tasks"] != 1 {
+		t.Errorf("Expected the history anonymized, got %+v", result.Anonymized)
+	}
+	if result.Deleted["users"] != 1 || result.Deleted["push_subscriptions"] != 1 || result.Deleted["access_list_entries"] != 1 {
+		t.Errorf("Expected the account removed, got %+v", result.Deleted)
+	}
+
+	if user, _ := store.GetUser("work@example.com"); user != nil {
+		t.Error("Expected the user record deleted")
+	}
+	if config, _ := store.GetAdminConfig(); len(config.AllowedEmails) != 1 || config.AllowedEmails[0] != "personal@example.com" {
+		t.Errorf("Expected work@example.com off the allowlist, got %v", config.AllowedEmails)
+	}
+	if plant, _ := store.GetPlantState(); plant.WateredBy != result.ReplacedBy {
+		t.Errorf("Expected the last waterer anonymized, got %q", plant.WateredBy)
+	}
+	if task, _ := store.GetCareTask(1); task.LastDoneBy != result.ReplacedBy {
+		t.Errorf("Expected the care task anonymized, got %q", task.LastDoneBy)
+	}
+
+	// Other users' history is untouched
+	events, _ := store.ListWateringEvents(0)
+	for _, event := range events {
+		if event.WateredBy != "personal@example.com" && event.WateredBy != result.ReplacedBy {
+			t.Errorf("Unexpected waterer %q", event.WateredBy)
+		}
+	}
+	if export, _ := service.ExportAccount("work@example.com"); len(export.Waterings)+len(export.CareTaskEvents)+len(export.PushSubscriptions) != 0 {
+		t.Errorf("Expected nothing left under the deleted email, got %+v", export)
+	}
+	if export, _ := service.ExportAccount("personal@example.com"); len(export.Waterings) != 1 {
+		t.Errorf("Expected personal@example.com's watering kept, got %d", len(export.Waterings))
+	}
+}
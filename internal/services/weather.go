@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/weather"
+)
+
+// WeatherSource provides the recent weather and today's forecast
+type WeatherSource interface {
+	Forecast(ctx context.Context) (*weather.Forecast, error)
+}
+
+// WeatherService adjusts the timeouts of outdoor plants for the weather:
+// longer after rainy or humid days, shorter in a heatwave. Indoor plants
+// are left alone.
+type WeatherService struct {
+	plantService *PlantService
+	source       WeatherSource
+	now          func() time.Time
+}
+
+// NewWeatherService creates a weather service fed by source
+func NewWeatherService(plantService *PlantService, source WeatherSource) *WeatherService {
+	return &WeatherService{
+		plantService: plantService,
+		source:       source,
+		now:          time.Now,
+	}
+}
+
+// Refresh fetches the forecast and updates every outdoor plant's weather
+// adjustment. If the forecast cannot be fetched the previous adjustments
+// stay until they expire after models.WeatherAdjustmentMaxAge.
+func (s *WeatherService) Refresh(ctx context.Context) error {
+	plants, err := s.plantService.ListPlants()
+	if err != nil {
+		return fmt.Errorf("failed to list plants: %w", err)
+	}
+
+	var outdoor []*models.PlantState
+	for _, plant := range plants {
+		if plant.Outdoor {
+			outdoor = append(outdoor, plant)
+		}
+	}
+	if len(outdoor) == 0 {
+		return nil
+	}
+
+	forecast, err := s.source.Forecast(ctx)
+	if err != nil {
+		return err
+	}
+	adjustment := weather.Adjust(forecast)
+
+	var errs []error
+	for _, plant := range outdoor {
+		err := s.plantService.setWeatherAdjustment(plant.ID, &models.WeatherAdjustment{
+			Factor:    adjustment.Factor,
+			Reason:    adjustment.Reason,
+			UpdatedAt: s.now(),
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("plant %d: %w", plant.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage"
+	"watered/internal/weather"
+)
+
+// fakeWeatherSource returns a fixed forecast and counts fetches
+type fakeWeatherSource struct {
+	forecast *weather.Forecast
+	err      error
+	fetches  int
+}
+
+func (s *fakeWeatherSource) Forecast(ctx context.Context) (*weather.Forecast, error) {
+	s.fetches++
+	return s.forecast, s.err
+}
+
+func TestWeatherService_Refresh(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	defer store.Close()
+
+	plantService := NewPlantService(store)
+	wateredAt := time.Now().Add(-30 * time.Hour)
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Balcony Tomato", TimeoutHours: 24, LastWatered: &wateredAt})
+	store.UpdatePlantState(&models.PlantState{ID: 2, Name: "Office Fern", TimeoutHours: 24, LastWatered: &wateredAt})
+
+	rain := 12.0
+	source := &fakeWeatherSource{forecast: &weather.Forecast{Past: []weather.Day{{PrecipitationMM: &rain}, {}}}}
+	service := NewWeatherService(plantService, source)
+
+	// Nothing is fetched while every plant is indoors
+	if err := service.Refresh(context.Background()); err != nil || source.fetches != 0 {
+		t.Fatalf("Expected no fetch without outdoor plants, got %d fetches and error %v", source.fetches, err)
+	}
+
+	if _, err := plantService.UpdateOutdoorByID(1, true); err != nil {
+		t.Fatalf("Failed to move plant outdoors: %v", err)
+	}
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	tomato, _ := plantService.GetPlantByID(1)
+	if tomato.WeatherFactor() != 1.5 || tomato.Weather.Reason != "12.0 mm of rain in 2 days" {
+		t.Errorf("Expected the rain to stretch the timeout, got %+v", tomato.Weather)
+	}
+	if tomato.IsOverdue() {
+		t.Error("Expected the watered outdoor plant not to be overdue after rain")
+	}
+	timer, _ := plantService.GetPlantTimerByID(1)
+	if timer.WeatherFactor != 1.5 || timer.EffectiveTimeoutHours != 36 || !timer.NextWateringTime.Equal(wateredAt.Add(36*time.Hour)) {
+		t.Errorf("Expected the timer to show the adjustment, got %+v", timer)
+	}
+
+	fern, _ := plantService.GetPlantByID(2)
+	if fern.Weather != nil || !fern.IsOverdue() {
+		t.Errorf("Expected the indoor plant to be left alone, got %+v", fern.Weather)
+	}
+
+	// A failed fetch keeps the previous adjustment
+	source.err = errors.New("connection refused")
+	if err := service.Refresh(context.Background()); err == nil {
+		t.Error("Expected the fetch error to be returned")
+	}
+	if tomato, _ := plantService.GetPlantByID(1); tomato.WeatherFactor() != 1.5 {
+		t.Errorf("Expected the adjustment to be kept, got factor %v", tomato.WeatherFactor())
+	}
+
+	// Moving the plant indoors drops the adjustment
+	tomato, _ = plantService.UpdateOutdoorByID(1, false)
+	if tomato.Weather != nil || tomato.WeatherFactor() != 1 {
+		t.Errorf("Expected the adjustment to be dropped, got %+v", tomato.Weather)
+	}
+}
@@ -0,0 +1,83 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"watered/internal/models"
+)
+
+// MaxHistoryDays is how far back the history charts reach
+const MaxHistoryDays = 366
+
+// DailyCount is the number of waterings on one day of the household timezone
+type DailyCount struct {
+	Date      string `json:"date"`
+	Waterings int    `json:"waterings"`
+}
+
+// UserCount is the number of waterings one user recorded
+type UserCount struct {
+	Email       string    `json:"email" mask:"member"`
+	Waterings   int       `json:"waterings"`
+	LastWatered time.Time `json:"last_watered"`
+}
+
+// HistoryStart returns the start of the first of the last days days in loc,
+// counting today
+func HistoryStart(days int, loc *time.Location, now time.Time) time.Time {
+	year, month, day := now.In(loc).Date()
+	return time.Date(year, month, day-days+1, 0, 0, 0, 0, loc)
+}
+
+// Daily counts the waterings on each of the last days days in loc, oldest
+// first and ending today. Days without a watering are included with a count
+// of zero, so the result can be charted as is.
+func Daily(events []*models.PlantWateringEvent, days int, loc *time.Location, now time.Time) []DailyCount {
+	first := date(HistoryStart(days, loc, now), loc)
+	counts := make([]DailyCount, days)
+	for i := range counts {
+		counts[i].Date = first.AddDate(0, 0, i).Format("2006-01-02")
+	}
+	for _, event := range events {
+		i := int(date(event.WateredAt, loc).Sub(first) / (24 * time.Hour))
+		if i >= 0 && i < days {
+			counts[i].Waterings++
+		}
+	}
+	return counts
+}
+
+// ByUser counts the waterings each user recorded in the last days days in
+// loc, most first. Sensors and buttons are counted under their own names;
+// waterings recorded by nobody are left out.
+func ByUser(events []*models.PlantWateringEvent, days int, loc *time.Location, now time.Time) []UserCount {
+	start := HistoryStart(days, loc, now)
+	users := make(map[string]*UserCount)
+	for _, event := range events {
+		if event.WateredBy == "" || event.WateredAt.Before(start) {
+			continue
+		}
+		count, ok := users[event.WateredBy]
+		if !ok {
+			count = &UserCount{Email: event.WateredBy}
+			users[event.WateredBy] = count
+		}
+		count.Waterings++
+		if event.WateredAt.After(count.LastWatered) {
+			count.LastWatered = event.WateredAt
+		}
+	}
+
+	result := make([]UserCount, 0, len(users))
+	for _, count := range users {
+		result = append(result, *count)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Waterings != result[j].Waterings {
+			return result[i].Waterings > result[j].Waterings
+		}
+		return result[i].Email < result[j].Email
+	})
+	return result
+}
@@ -0,0 +1,73 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"watered/internal/models"
+)
+
+func TestDaily(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+	now := time.Date(2024, 3, 12, 8, 0, 0, 0, loc)
+	events := []*models.PlantWateringEvent{
+		// Before the first day
+		{WateredAt: time.Date(2024, 3, 5, 23, 0, 0, 0, loc)},
+		{WateredAt: time.Date(2024, 3, 6, 0, 30, 0, 0, loc)},
+		// 01:30 UTC on the 11th is still the 10th in New York, the day
+		// daylight saving time started
+		{WateredAt: time.Date(2024, 3, 11, 1, 30, 0, 0, time.UTC)},
+		{WateredAt: time.Date(2024, 3, 12, 7, 0, 0, 0, loc)},
+		{WateredAt: time.Date(2024, 3, 12, 7, 30, 0, 0, loc)},
+	}
+
+	daily := Daily(events, 7, loc, now)
+	expected := []DailyCount{
+		{"2024-03-06", 1}, {"2024-03-07", 0}, {"2024-03-08", 0}, {"2024-03-09", 0},
+		{"2024-03-10", 1}, {"2024-03-11", 0}, {"2024-03-12", 2},
+	}
+	if len(daily) != len(expected) {
+		t.Fatalf("Expected %d days, got %+v", len(expected), daily)
+	}
+	for i, want := range expected {
+		if daily[i] != want {
+			t.Errorf("Day %d: expected %+v, got %+v", i, want, daily[i])
+		}
+	}
+}
+
+func TestByUser(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2024, 6, 12, 20, 0, 0, 0, loc)
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 9, 0, 0, 0, loc) }
+	water := func(d int, email string) *models.PlantWateringEvent {
+		return &models.PlantWateringEvent{PlantID: 1, WateredAt: day(d), WateredBy: email}
+	}
+
+	events := []*models.PlantWateringEvent{
+		water(10, "bob@example.com"), water(11, "alice@example.com"),
+		water(12, "bob@example.com"), water(12, models.SensorWaterer),
+		water(12, "alice@example.com"), water(11, ""),
+		// Too old to count
+		water(5, "carol@example.com"),
+	}
+
+	users := ByUser(events, 3, loc, now)
+	expected := []UserCount{
+		{"alice@example.com", 2, day(12)},
+		{"bob@example.com", 2, day(12)},
+		{models.SensorWaterer, 1, day(12)},
+	}
+	if len(users) != len(expected) {
+		t.Fatalf("Expected %d users, got %+v", len(expected), users)
+	}
+	for i, want := range expected {
+		got := users[i]
+		if got.Email != want.Email || got.Waterings != want.Waterings || !got.LastWatered.Equal(want.LastWatered) {
+			t.Errorf("User %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
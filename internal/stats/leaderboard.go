@@ -0,0 +1,147 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"watered/internal/models"
+)
+
+// Period is the span a leaderboard covers
+type Period string
+
+// Periods accepted by BuildLeaderboard
+const (
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+)
+
+// ParsePeriod parses a leaderboard period, defaulting to the current week
+func ParsePeriod(value string) (Period, error) {
+	switch Period(value) {
+	case "", PeriodWeek:
+		return PeriodWeek, nil
+	case PeriodMonth:
+		return PeriodMonth, nil
+	default:
+		return "", fmt.Errorf("period must be %s or %s, got %q", PeriodWeek, PeriodMonth, value)
+	}
+}
+
+// bounds returns the start of the calendar period in loc containing now, the
+// start of the next one and the start of the previous one. Weeks start on
+// Monday.
+func (p Period) bounds(now time.Time, loc *time.Location) (start, end, previous time.Time) {
+	year, month, day := now.In(loc).Date()
+	if p == PeriodMonth {
+		start = time.Date(year, month, 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 1, 0), start.AddDate(0, -1, 0)
+	}
+
+	sinceMonday := (int(now.In(loc).Weekday()) + 6) % 7
+	start = time.Date(year, month, day-sinceMonday, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 0, 7), start.AddDate(0, 0, -7)
+}
+
+// LeaderboardEntry is one user's standing in the current period compared
+// with the previous one
+type LeaderboardEntry struct {
+	Rank              int    `json:"rank"`
+	Email             string `json:"email,omitempty" mask:"member"`
+	Waterings         int    `json:"waterings"`
+	PreviousWaterings int    `json:"previous_waterings"`
+	// Delta is the change in waterings since the previous period
+	Delta int `json:"delta"`
+	// PreviousRank is nil for users who did not water in the previous period
+	PreviousRank *int `json:"previous_rank"`
+	// RankChange is how many places the user moved up, nil without a
+	// previous rank
+	RankChange *int `json:"rank_change"`
+}
+
+// Leaderboard ranks users by waterings in the current calendar period
+type Leaderboard struct {
+	Period        Period             `json:"period"`
+	Start         time.Time          `json:"start"`
+	End           time.Time          `json:"end"`
+	PreviousStart time.Time          `json:"previous_start"`
+	Entries       []LeaderboardEntry `json:"entries"`
+}
+
+// BuildLeaderboard ranks everyone who watered in the current or previous
+// period by their waterings in the current one. Users with equal counts share
+// a rank, so two users tied for first are followed by third place.
+func BuildLeaderboard(events []*models.PlantWateringEvent, period Period, loc *time.Location, now time.Time) *Leaderboard {
+	start, end, previousStart := period.bounds(now, loc)
+
+	current := make(map[string]int)
+	previous := make(map[string]int)
+	for _, event := range events {
+		// Waterings a sensor or button recorded belong to nobody in particular
+		if event.WateredBy == "" || models.IsDeviceWaterer(event.WateredBy) {
+			continue
+		}
+		switch {
+		case !event.WateredAt.Before(start) && event.WateredAt.Before(end):
+			current[event.WateredBy]++
+		case !event.WateredAt.Before(previousStart) && event.WateredAt.Before(start):
+			previous[event.WateredBy]++
+		}
+	}
+
+	previousRanks := rank(previous)
+	board := &Leaderboard{
+		Period:        period,
+		Start:         start,
+		End:           end,
+		PreviousStart: previousStart,
+		Entries:       []LeaderboardEntry{},
+	}
+
+	everyone := make(map[string]int, len(current)+len(previous))
+	for email := range previous {
+		everyone[email] = 0
+	}
+	for email, count := range current {
+		everyone[email] = count
+	}
+	ranks := rank(everyone)
+
+	for email, count := range everyone {
+		entry := LeaderboardEntry{
+			Rank:              ranks[email],
+			Email:             email,
+			Waterings:         count,
+			PreviousWaterings: previous[email],
+			Delta:             count - previous[email],
+		}
+		if previousRank, ok := previousRanks[email]; ok {
+			change := previousRank - entry.Rank
+			entry.PreviousRank = &previousRank
+			entry.RankChange = &change
+		}
+		board.Entries = append(board.Entries, entry)
+	}
+	sort.Slice(board.Entries, func(i, j int) bool {
+		if board.Entries[i].Rank != board.Entries[j].Rank {
+			return board.Entries[i].Rank < board.Entries[j].Rank
+		}
+		return board.Entries[i].Email < board.Entries[j].Email
+	})
+	return board
+}
+
+// rank assigns competition ranks by count, highest first
+func rank(counts map[string]int) map[string]int {
+	ranks := make(map[string]int, len(counts))
+	for email, count := range counts {
+		ranks[email] = 1
+		for _, other := range counts {
+			if other > count {
+				ranks[email]++
+			}
+		}
+	}
+	return ranks
+}
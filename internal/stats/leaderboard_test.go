@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"watered/internal/models"
+)
+
+func TestBuildLeaderboard(t *testing.T) {
+	loc := time.UTC
+	// Wednesday; the week started on Monday June 10
+	now := time.Date(2024, 6, 12, 20, 0, 0, 0, loc)
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 9, 0, 0, 0, loc) }
+	water := func(d int, email string) *models.PlantWateringEvent {
+		return &models.PlantWateringEvent{PlantID: 1, WateredAt: day(d), WateredBy: email}
+	}
+
+	events := []*models.PlantWateringEvent{
+		// Previous week: bob ahead of alice, carol only then
+		water(3, "bob@example.com"), water(4, "bob@example.com"), water(5, "bob@example.com"),
+		water(6, "alice@example.com"), water(9, "carol@example.com"),
+		// This week: alice and dave tied, bob behind
+		water(10, "alice@example.com"), water(11, "alice@example.com"),
+		water(10, "dave@example.com"), water(12, "dave@example.com"),
+		water(11, "bob@example.com"),
+		// Recorded by a sensor or a button, so nobody's
+		water(12, models.SensorWaterer),
+		water(13, models.ButtonWaterer),
+		// Too old to count
+		water(1, "alice@example.com"),
+	}
+
+	board := BuildLeaderboard(events, PeriodWeek, loc, now)
+	if !board.Start.Equal(time.Date(2024, 6, 10, 0, 0, 0, 0, loc)) || !board.PreviousStart.Equal(time.Date(2024, 6, 3, 0, 0, 0, 0, loc)) {
+		t.Errorf("Unexpected week bounds: %v, previous %v", board.Start, board.PreviousStart)
+	}
+
+	expected := []struct {
+		email      string
+		rank       int
+		waterings  int
+		delta      int
+		rankChange *int
+	}{
+		{"alice@example.com", 1, 2, 1, intPtr(1)},
+		{"dave@example.com", 1, 2, 2, nil},
+		{"bob@example.com", 3, 1, -2, intPtr(-2)},
+		{"carol@example.com", 4, 0, -1, intPtr(-2)},
+	}
+	if len(board.Entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %+v", len(expected), board.Entries)
+	}
+	for i, want := range expected {
+		got := board.Entries[i]
+		if got.Email != want.email || got.Rank != want.rank || got.Waterings != want.waterings || got.Delta != want.delta {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, want, got)
+		}
+		if (got.RankChange == nil) != (want.rankChange == nil) || (got.RankChange != nil && *got.RankChange != *want.rankChange) {
+			t.Errorf("Entry %d: expected rank change %v, got %v", i, want.rankChange, got.RankChange)
+		}
+	}
+
+	month := BuildLeaderboard(events, PeriodMonth, loc, now)
+	if len(month.Entries) != 4 || month.Entries[0].Email != "alice@example.com" || month.Entries[0].Waterings != 4 {
+		t.Errorf("Expected alice to lead the month with 4 waterings, got %+v", month.Entries)
+	}
+	if month.Entries[0].PreviousRank != nil {
+		t.Errorf("Expected no previous rank without waterings in May, got %d", *month.Entries[0].PreviousRank)
+	}
+}
+
+func TestBuildLeaderboard_WeekStartsMondayInLocalTime(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	// Sunday 23:00 UTC is already Monday in Tokyo
+	now := time.Date(2024, 6, 9, 23, 0, 0, 0, time.UTC)
+
+	board := BuildLeaderboard(nil, PeriodWeek, tokyo, now)
+	if want := time.Date(2024, 6, 10, 0, 0, 0, 0, tokyo); !board.Start.Equal(want) {
+		t.Errorf("Expected the week to start %v, got %v", want, board.Start)
+	}
+	if board.Entries == nil || len(board.Entries) != 0 {
+		t.Errorf("Expected an empty leaderboard, got %+v", board.Entries)
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	for value, want := range map[string]Period{"": PeriodWeek, "week": PeriodWeek, "month": PeriodMonth} {
+		if got, err := ParsePeriod(value); err != nil || got != want {
+			t.Errorf("ParsePeriod(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParsePeriod("year"); err == nil {
+		t.Error("Expected an error for an unknown period")
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
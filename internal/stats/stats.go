@@ -0,0 +1,180 @@
+// Package stats derives watering statistics, such as streaks and how often
+// plants are watered on time, from the watering history.
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"watered/internal/models"
+)
+
+// Summary describes the waterings of one user or of the whole household
+type Summary struct {
+	Waterings int `json:"waterings"`
+	// CurrentStreak counts consecutive days with a watering up to today. A
+	// streak is not broken until a whole day passes without one, so it
+	// still counts yesterday's run before the first watering of today.
+	CurrentStreak int `json:"current_streak_days"`
+	LongestStreak int `json:"longest_streak_days"`
+	// AverageIntervalHours is the mean time between consecutive waterings,
+	// nil with fewer than two
+	AverageIntervalHours *float64 `json:"average_interval_hours"`
+	// OnTimePercentage is the share of waterings that came within the
+	// plant's timeout of its previous watering, nil when none can be judged
+	OnTimePercentage *float64   `json:"on_time_percentage"`
+	LastWatered      *time.Time `json:"last_watered"`
+}
+
+// UserStats is the summary of one user's waterings
+type UserStats struct {
+	Email string `json:"email"`
+	Summary
+}
+
+// Report is the household summary and a summary per user, most active first
+type Report struct {
+	Household Summary     `json:"household"`
+	Users     []UserStats `json:"users" mask:"member"`
+}
+
+// User returns the summary of one user, or nil if they never watered
+func (r *Report) User(email string) *UserStats {
+	for i := range r.Users {
+		if r.Users[i].Email == email {
+			return &r.Users[i]
+		}
+	}
+	return nil
+}
+
+// judged is a watering and whether it was on time, if that can be told
+type judged struct {
+	event  *models.PlantWateringEvent
+	onTime *bool
+}
+
+// Compute builds a report from the watering history. Streaks count calendar
+// days in loc up to now. A watering is on time when it came within the
+// plant's current timeout of the plant's previous watering by anyone; the
+// first watering of a plant and waterings of deleted plants are not judged.
+func Compute(events []*models.PlantWateringEvent, plants []*models.PlantState, loc *time.Location, now time.Time) *Report {
+	timeouts := make(map[int]time.Duration, len(plants))
+	for _, plant := range plants {
+		timeouts[plant.ID] = time.Duration(plant.TimeoutHours) * time.Hour
+	}
+
+	sorted := make([]*models.PlantWateringEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].WateredAt.Before(sorted[j].WateredAt) })
+
+	all := make([]judged, 0, len(sorted))
+	byUser := make(map[string][]judged)
+	previous := make(map[int]time.Time)
+	for _, event := range sorted {
+		j := judged{event: event}
+		if last, ok := previous[event.PlantID]; ok {
+			if timeout, ok := timeouts[event.PlantID]; ok {
+				onTime := event.WateredAt.Sub(last) <= timeout
+				j.onTime = &onTime
+			}
+		}
+		previous[event.PlantID] = event.WateredAt
+
+		all = append(all, j)
+		if event.WateredBy != "" && !models.IsDeviceWaterer(event.WateredBy) {
+			byUser[event.WateredBy] = append(byUser[event.WateredBy], j)
+		}
+	}
+
+	report := &Report{
+		Household: summarize(all, loc, now),
+		Users:     make([]UserStats, 0, len(byUser)),
+	}
+	for email, waterings := range byUser {
+		report.Users = append(report.Users, UserStats{Email: email, Summary: summarize(waterings, loc, now)})
+	}
+	sort.Slice(report.Users, func(i, j int) bool {
+		if report.Users[i].Waterings != report.Users[j].Waterings {
+			return report.Users[i].Waterings > report.Users[j].Waterings
+		}
+		return report.Users[i].Email < report.Users[j].Email
+	})
+	return report
+}
+
+// summarize computes a summary of waterings sorted oldest first
+func summarize(waterings []judged, loc *time.Location, now time.Time) Summary {
+	summary := Summary{Waterings: len(waterings)}
+	if len(waterings) == 0 {
+		return summary
+	}
+
+	last := waterings[len(waterings)-1].event.WateredAt
+	summary.LastWatered = &last
+
+	if len(waterings) > 1 {
+		first := waterings[0].event.WateredAt
+		average := round(last.Sub(first).Hours() / float64(len(waterings)-1))
+		summary.AverageIntervalHours = &average
+	}
+
+	judgedCount, onTimeCount := 0, 0
+	for _, w := range waterings {
+		if w.onTime == nil {
+			continue
+		}
+		judgedCount++
+		if *w.onTime {
+			onTimeCount++
+		}
+	}
+	if judgedCount > 0 {
+		percentage := round(float64(onTimeCount) * 100 / float64(judgedCount))
+		summary.OnTimePercentage = &percentage
+	}
+
+	summary.CurrentStreak, summary.LongestStreak = streaks(waterings, loc, now)
+	return summary
+}
+
+// streaks returns the current and longest runs of consecutive days in loc
+// with at least one watering
+func streaks(waterings []judged, loc *time.Location, now time.Time) (current, longest int) {
+	run := 0
+	var previous time.Time
+	for _, w := range waterings {
+		day := date(w.event.WateredAt, loc)
+		switch {
+		case run > 0 && day.Equal(previous):
+			continue
+		case run > 0 && day.Equal(previous.AddDate(0, 0, 1)):
+			run++
+		default:
+			run = 1
+		}
+		previous = day
+		if run > longest {
+			longest = run
+		}
+	}
+
+	today := date(now, loc)
+	if previous.Equal(today) || previous.Equal(today.AddDate(0, 0, -1)) {
+		current = run
+	}
+	return current, longest
+}
+
+// date returns the calendar day of t in loc as midnight UTC, so days can be
+// compared and stepped without daylight saving shifts
+func date(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// round rounds to one decimal place
+func round(x float64) float64 {
+	return math.Round(x*10) / 10
+}
@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"watered/internal/models"
+)
+
+func TestCompute(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2024, 6, 10, 20, 0, 0, 0, loc)
+	day := func(d, hour int) time.Time { return time.Date(2024, 6, d, hour, 0, 0, 0, loc) }
+
+	plants := []*models.PlantState{
+		{ID: 1, TimeoutHours: 24},
+		{ID: 2, TimeoutHours: 72},
+	}
+	events := []*models.PlantWateringEvent{
+		// Out of order on purpose: history is sorted before it is judged
+		{PlantID: 1, WateredAt: day(9, 8), WateredBy: "alice@example.com"},
+		{PlantID: 1, WateredAt: day(1, 8), WateredBy: "alice@example.com"},
+		{PlantID: 1, WateredAt: day(2, 8), WateredBy: "alice@example.com"},
+		{PlantID: 1, WateredAt: day(3, 8), WateredBy: "alice@example.com"},
+		{PlantID: 2, WateredAt: day(3, 9), WateredBy: "bob@example.com"},
+		// 5 days after the previous watering of plant 1: late
+		{PlantID: 1, WateredAt: day(8, 8), WateredBy: "bob@example.com"},
+		// Plant 2 within its 72 hour timeout: on time
+		{PlantID: 2, WateredAt: day(5, 9), WateredBy: "bob@example.com"},
+		// Deleted plant: counted but not judged
+		{PlantID: 9, WateredAt: day(5, 10), WateredBy: "bob@example.com"},
+	}
+
+	report := Compute(events, plants, loc, now)
+
+	household := report.Household
+	if household.Waterings != 8 {
+		t.Errorf("Expected 8 waterings, got %d", household.Waterings)
+	}
+	if household.LongestStreak != 3 || household.CurrentStreak != 2 {
+		t.Errorf("Expected streaks of 2 current and 3 longest, got %d and %d", household.CurrentStreak, household.LongestStreak)
+	}
+	// 4 of 5 judged waterings were on time
+	if household.OnTimePercentage == nil || *household.OnTimePercentage != 80 {
+		t.Errorf("Expected 80%% on time, got %v", household.OnTimePercentage)
+	}
+	// 8 days between the first and last watering over 7 intervals
+	if household.AverageIntervalHours == nil || *household.AverageIntervalHours != 27.4 {
+		t.Errorf("Expected an average interval of 27.4 hours, got %v", household.AverageIntervalHours)
+	}
+
+	if len(report.Users) != 2 || report.Users[0].Email != "alice@example.com" {
+		t.Fatalf("Expected alice first by watering count, got %+v", report.Users)
+	}
+
+	alice := report.User("alice@example.com")
+	if alice.Waterings != 4 || alice.LongestStreak != 3 || alice.CurrentStreak != 1 {
+		t.Errorf("Unexpected stats for alice: %+v", alice.Summary)
+	}
+	if alice.OnTimePercentage == nil || *alice.OnTimePercentage != 100 {
+		t.Errorf("Expected alice to be always on time, got %v", alice.OnTimePercentage)
+	}
+
+	bob := report.User("bob@example.com")
+	if bob.CurrentStreak != 0 || bob.LongestStreak != 1 {
+		t.Errorf("Expected bob's streak to be broken, got %+v", bob.Summary)
+	}
+	if bob.OnTimePercentage == nil || *bob.OnTimePercentage != 50 {
+		t.Errorf("Expected bob to be on time half the time, got %v", bob.OnTimePercentage)
+	}
+
+	if report.User("nobody@example.com") != nil {
+		t.Error("Expected no stats for a user who never watered")
+	}
+}
+
+func TestCompute_Empty(t *testing.T) {
+	report := Compute(nil, nil, time.UTC, time.Now())
+
+	if report.Household.Waterings != 0 || report.Household.LastWatered != nil {
+		t.Errorf("Expected an empty summary, got %+v", report.Household)
+	}
+	if report.Household.AverageIntervalHours != nil || report.Household.OnTimePercentage != nil {
+		t.Errorf("Expected no averages without history, got %+v", report.Household)
+	}
+	if report.Users == nil || len(report.Users) != 0 {
+		t.Errorf("Expected an empty user list, got %v", report.Users)
+	}
+}
+
+func TestStreaksUseLocalDays(t *testing.T) {
+	loc := time.FixedZone("UTC+10", 10*60*60)
+	// 20:00 UTC on consecutive days is early morning of the next day in loc,
+	// and 02:00 UTC the same local day as the previous watering
+	events := []*models.PlantWateringEvent{
+		{PlantID: 1, WateredAt: time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC), WateredBy: "a@example.com"},
+		{PlantID: 1, WateredAt: time.Date(2024, 6, 2, 2, 0, 0, 0, time.UTC), WateredBy: "a@example.com"},
+		{PlantID: 1, WateredAt: time.Date(2024, 6, 2, 20, 0, 0, 0, time.UTC), WateredBy: "a@example.com"},
+	}
+
+	report := Compute(events, nil, loc, time.Date(2024, 6, 3, 1, 0, 0, 0, time.UTC))
+	if report.Household.LongestStreak != 2 || report.Household.CurrentStreak != 2 {
+		t.Errorf("Expected a 2 day streak in local time, got %+v", report.Household)
+	}
+}
@@ -0,0 +1,127 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"watered/internal/models"
+)
+
+// ReportWindow is the span a weekly report covers
+const ReportWindow = 7 * 24 * time.Hour
+
+// PlantWeek is one plant's part of a weekly report
+type PlantWeek struct {
+	PlantID   int    `json:"plant_id"`
+	Name      string `json:"name"`
+	Waterings int    `json:"waterings"`
+	// LongestGapHours is the longest the plant went without water among
+	// the gaps ending this week, counting one still open at the end of the
+	// week. It is nil for plants never watered.
+	LongestGapHours *float64 `json:"longest_gap_hours"`
+}
+
+// ActiveUser is the user who watered most in a weekly report
+type ActiveUser struct {
+	Email     string `json:"email" mask:"member"`
+	Name      string `json:"name,omitempty" mask:"member"`
+	Waterings int    `json:"waterings"`
+}
+
+// WeeklyReport summarises the waterings of the week before End
+type WeeklyReport struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Timezone  string    `json:"timezone"`
+	Waterings int       `json:"waterings"`
+	// LongestGapHours and LongestGapPlant name the plant that went longest
+	// without water
+	LongestGapHours *float64 `json:"longest_gap_hours"`
+	LongestGapPlant string   `json:"longest_gap_plant,omitempty"`
+	// MostActive is nil when no person watered this week. Ties go to the
+	// user who watered last, then by email.
+	MostActive *ActiveUser  `json:"most_active"`
+	Plants     []*PlantWeek `json:"plants"`
+}
+
+// Weekly builds the report for the week ending at now. Waterings by sensors
+// and buttons count towards the totals and gaps but nobody is most active
+// for them.
+func Weekly(events []*models.PlantWateringEvent, plants []*models.PlantState, loc *time.Location, now time.Time) *WeeklyReport {
+	report := &WeeklyReport{
+		Start:    now.Add(-ReportWindow).In(loc),
+		End:      now.In(loc),
+		Timezone: loc.String(),
+		Plants:   make([]*PlantWeek, 0, len(plants)),
+	}
+
+	weeks := make(map[int]*PlantWeek, len(plants))
+	for _, plant := range plants {
+		week := &PlantWeek{PlantID: plant.ID, Name: plant.Name}
+		weeks[plant.ID] = week
+		report.Plants = append(report.Plants, week)
+	}
+	sort.Slice(report.Plants, func(i, j int) bool { return report.Plants[i].PlantID < report.Plants[j].PlantID })
+
+	// Events are oldest first, so each plant's previous watering is known
+	// when the next one is reached
+	sorted := append([]*models.PlantWateringEvent(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].WateredAt.Before(sorted[j].WateredAt) })
+
+	previous := make(map[int]time.Time)
+	users := make(map[string]int)
+	lastActive := make(map[string]time.Time)
+	for _, event := range sorted {
+		week, ok := weeks[event.PlantID]
+		if !ok || event.WateredAt.After(now) {
+			continue
+		}
+		if !event.WateredAt.Before(report.Start) {
+			week.Waterings++
+			report.Waterings++
+			if event.WateredBy != "" && !models.IsDeviceWaterer(event.WateredBy) {
+				users[event.WateredBy]++
+				lastActive[event.WateredBy] = event.WateredAt
+			}
+			if last, ok := previous[event.PlantID]; ok {
+				week.gap(event.WateredAt.Sub(last))
+			}
+		}
+		previous[event.PlantID] = event.WateredAt
+	}
+
+	for _, week := range report.Plants {
+		if last, ok := previous[week.PlantID]; ok {
+			week.gap(now.Sub(last))
+		}
+		if week.LongestGapHours != nil && (report.LongestGapHours == nil || *week.LongestGapHours > *report.LongestGapHours) {
+			report.LongestGapHours = week.LongestGapHours
+			report.LongestGapPlant = week.Name
+		}
+	}
+
+	for email, count := range users {
+		best := report.MostActive
+		if best == nil || count > best.Waterings || (count == best.Waterings && later(email, best.Email, lastActive)) {
+			report.MostActive = &ActiveUser{Email: email, Waterings: count}
+		}
+	}
+	return report
+}
+
+// later reports whether a last watered after b, comparing emails when they
+// watered at the same time
+func later(a, b string, lastActive map[string]time.Time) bool {
+	if !lastActive[a].Equal(lastActive[b]) {
+		return lastActive[a].After(lastActive[b])
+	}
+	return a < b
+}
+
+// gap records a span without water if it is the plant's longest yet
+func (w *PlantWeek) gap(d time.Duration) {
+	hours := round(d.Hours())
+	if w.LongestGapHours == nil || hours > *w.LongestGapHours {
+		w.LongestGapHours = &hours
+	}
+}
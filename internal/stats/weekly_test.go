@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"watered/internal/models"
+)
+
+func TestWeekly(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2024, 6, 12, 20, 0, 0, 0, loc)
+	hoursAgo := func(h int) time.Time { return now.Add(-time.Duration(h) * time.Hour) }
+	plants := []*models.PlantState{
+		{ID: 2, Name: "Fern"},
+		{ID: 1, Name: "Monstera"},
+		{ID: 3, Name: "Cactus"},
+	}
+	events := []*models.PlantWateringEvent{
+		// Monstera: a 31h gap into the week, and 80h since its last watering
+		{PlantID: 1, WateredAt: hoursAgo(7*24 + 10), WateredBy: "sam@example.com"},
+		{PlantID: 1, WateredAt: hoursAgo(7*24 - 21), WateredBy: "sam@example.com"},
+		{PlantID: 1, WateredAt: hoursAgo(140), WateredBy: "alex@example.com"},
+		{PlantID: 1, WateredAt: hoursAgo(120), WateredBy: models.SensorWaterer},
+		{PlantID: 1, WateredAt: hoursAgo(100), WateredBy: "sam@example.com"},
+		{PlantID: 1, WateredAt: hoursAgo(80), WateredBy: "alex@example.com"},
+		// Fern: watered once, 50h ago
+		{PlantID: 2, WateredAt: hoursAgo(50), WateredBy: "sam@example.com"},
+		// Unknown plant and a watering in the future
+		{PlantID: 9, WateredAt: hoursAgo(1), WateredBy: "alex@example.com"},
+		{PlantID: 2, WateredAt: now.Add(time.Hour), WateredBy: "alex@example.com"},
+	}
+
+	report := Weekly(events, plants, loc, now)
+	if !report.Start.Equal(now.Add(-ReportWindow)) || !report.End.Equal(now) || report.Timezone != "UTC" {
+		t.Errorf("Unexpected report window: %s to %s (%s)", report.Start, report.End, report.Timezone)
+	}
+	if report.Waterings != 6 {
+		t.Errorf("Expected 6 waterings, got %d", report.Waterings)
+	}
+	if report.LongestGapHours == nil || *report.LongestGapHours != 80 || report.LongestGapPlant != "Monstera" {
+		t.Errorf("Expected Monstera's open 80h gap to be the longest, got %v (%s)", report.LongestGapHours, report.LongestGapPlant)
+	}
+	if report.MostActive == nil || report.MostActive.Email != "sam@example.com" || report.MostActive.Waterings != 3 {
+		t.Errorf("Expected sam to be most active, got %+v", report.MostActive)
+	}
+
+	expected := []struct {
+		name      string
+		waterings int
+		gap       *float64
+	}{
+		{"Monstera", 5, floatPtr(80)},
+		{"Fern", 1, floatPtr(50)},
+		{"Cactus", 0, nil},
+	}
+	if len(report.Plants) != len(expected) {
+		t.Fatalf("Expected %d plants, got %+v", len(expected), report.Plants)
+	}
+	for i, want := range expected {
+		got := report.Plants[i]
+		if got.Name != want.name || got.Waterings != want.waterings || (got.LongestGapHours == nil) != (want.gap == nil) ||
+			(got.LongestGapHours != nil && *got.LongestGapHours != *want.gap) {
+			t.Errorf("Plant %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestWeekly_MostActiveTies(t *testing.T) {
+	now := time.Date(2024, 6, 12, 20, 0, 0, 0, time.UTC)
+	events := []*models.PlantWateringEvent{
+		{PlantID: 1, WateredAt: now.Add(-3 * time.Hour), WateredBy: "alex@example.com"},
+		{PlantID: 1, WateredAt: now.Add(-2 * time.Hour), WateredBy: "sam@example.com"},
+		{PlantID: 1, WateredAt: now.Add(-time.Hour), WateredBy: models.ButtonWaterer},
+	}
+
+	report := Weekly(events, []*models.PlantState{{ID: 1, Name: "Fern"}}, time.UTC, now)
+	if report.MostActive == nil || report.MostActive.Email != "sam@example.com" {
+		t.Errorf("Expected the tie to go to whoever watered last, got %+v", report.MostActive)
+	}
+
+	report = Weekly(nil, []*models.PlantState{{ID: 1, Name: "Fern"}}, time.UTC, now)
+	if report.MostActive != nil || report.LongestGapHours != nil || report.Waterings != 0 {
+		t.Errorf("Expected an empty report, got %+v", report)
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
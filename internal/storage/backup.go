@@ -0,0 +1,344 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage/migrations"
+)
+
+// BackupFormat marks a document as a backup archive
+const BackupFormat = "watered-backup"
+
+// ErrInvalidBackup is returned when a backup archive cannot be restored
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// Archive is everything a store holds except sessions, which belong to the
+// host that issued them and are never backed up
+type Archive struct {
+	Plants []*models.PlantState `json:"plants"`
+	// LastPlantID is the highest plant ID ever assigned, so IDs of deleted
+	// plants are not reused. Older files without it start from the
+	// highest stored plant.
+	LastPlantID   int                          `json:"last_plant_id,omitempty"`
+	Users         []*models.User               `json:"users"`
+	Config        *models.AdminConfig          `json:"config"`
+	Notifications []*models.Notification       `json:"notifications"`
+	Waterings     []*models.PlantWateringEvent `json:"watering_events"`
+	Subscriptions []*models.PushSubscription   `json:"push_subscriptions"`
+	APIKeys       []*models.APIKey             `json:"api_keys"`
+	Devices       []*models.Device             `json:"devices"`
+	ShareLinks    []*models.ShareLink          `json:"share_links"`
+	Invites       []*models.Invite             `json:"invites"`
+	UserActivity  []*models.UserActivity       `json:"user_activity"`
+	Readings      []*models.SensorReading      `json:"sensor_readings"`
+	CareTasks     []*models.CareTask           `json:"care_tasks"`
+	CareEvents    []*models.CareTaskEvent      `json:"care_task_events"`
+	Audit         []*models.AuditEntry         `json:"audit_log"`
+	HealthHistory []*models.HealthSample       `json:"health_history"`
+}
+
+// Backup is a portable archive of a store. It uses the same layout as the
+// data file, so it carries the schema migrations it was written with and
+// older backups are migrated when they are restored.
+type Backup struct {
+	Format     string                        `json:"format"`
+	CreatedAt  time.Time                     `json:"created_at"`
+	Migrations []migrations.AppliedMigration `json:"schema_migrations"`
+	Archive
+}
+
+// ParseBackup decodes a backup archive, migrates it to the current schema
+// and checks that it can be loaded. Errors wrap ErrInvalidBackup.
+func ParseBackup(data []byte) (*Backup, error) {
+	var doc migrations.Document
+	if err := json.Unmarshal(data, &doc); err != nil || doc == nil {
+		return nil, fmt.Errorf("%w: not a JSON object", ErrInvalidBackup)
+	}
+	if doc["format"] != BackupFormat {
+		return nil, fmt.Errorf("%w: format must be %q", ErrInvalidBackup, BackupFormat)
+	}
+
+	applied, err := migrations.Applied(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	known := make(map[int]bool)
+	for _, m := range migrations.All() {
+		known[m.Version] = true
+	}
+	for _, a := range applied {
+		if !known[a.Version] {
+			return nil, fmt.Errorf("%w: written by a newer version (schema migration %d)", ErrInvalidBackup, a.Version)
+		}
+	}
+	if _, err := migrations.Run(doc, false); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+
+	// Round-trip the migrated document into the typed backup
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode migrated backup: %w", err)
+	}
+	var backup Backup
+	if err := json.Unmarshal(migrated, &backup); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if err := backup.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	return &backup, nil
+}
+
+// validate checks the records a store is keyed by, so loading the archive
+// cannot silently drop or merge any of them
+func (b *Backup) validate() error {
+	plants := make(map[int]bool, len(b.Plants))
+	for _, plant := range b.Plants {
+		if plant == nil || plant.ID <= 0 {
+			return fmt.Errorf("plants must have a positive ID")
+		}
+		if plants[plant.ID] {
+			return fmt.Errorf("duplicate plant %d", plant.ID)
+		}
+		if err := plant.Validate(); err != nil {
+			return fmt.Errorf("plant %d: %v", plant.ID, err)
+		}
+		plants[plant.ID] = true
+	}
+
+	users := make(map[string]bool, len(b.Users))
+	for _, user := range b.Users {
+		if user == nil || user.Email == "" {
+			return fmt.Errorf("users must have an email")
+		}
+		if users[user.Email] {
+			return fmt.Errorf("duplicate user %s", user.Email)
+		}
+		users[user.Email] = true
+	}
+
+	tasks := make(map[int]bool, len(b.CareTasks))
+	for _, task := range b.CareTasks {
+		if task == nil || task.ID <= 0 {
+			return fmt.Errorf("care tasks must have a positive ID")
+		}
+		if tasks[task.ID] {
+			return fmt.Errorf("duplicate care task %d", task.ID)
+		}
+		if !plants[task.PlantID] {
+			return fmt.Errorf("care task %d belongs to unknown plant %d", task.ID, task.PlantID)
+		}
+		tasks[task.ID] = true
+	}
+
+	for _, key := range b.APIKeys {
+		if key == nil || key.ID == "" {
+			return fmt.Errorf("API keys must have an ID")
+		}
+	}
+	for _, device := range b.Devices {
+		if device == nil || device.ID == "" {
+			return fmt.Errorf("devices must have an ID")
+		}
+	}
+	for _, link := range b.ShareLinks {
+		if link == nil || link.ID == "" {
+			return fmt.Errorf("share links must have an ID")
+		}
+	}
+	for _, invite := range b.Invites {
+		if invite == nil || invite.ID == "" {
+			return fmt.Errorf("invites must have an ID")
+		}
+	}
+	for _, subscription := range b.Subscriptions {
+		if subscription == nil || subscription.Endpoint == "" {
+			return fmt.Errorf("push subscriptions must have an endpoint")
+		}
+	}
+	for _, record := range b.UserActivity {
+		if record == nil || record.Email == "" {
+			return fmt.Errorf("user activity must have an email")
+		}
+	}
+	for _, notification := range b.Notifications {
+		if notification == nil {
+			return fmt.Errorf("notifications must not be null")
+		}
+	}
+	for _, event := range b.Waterings {
+		if event == nil {
+			return fmt.Errorf("watering events must not be null")
+		}
+	}
+	for _, reading := range b.Readings {
+		if reading == nil {
+			return fmt.Errorf("sensor readings must not be null")
+		}
+	}
+	for _, event := range b.CareEvents {
+		if event == nil {
+			return fmt.Errorf("care task events must not be null")
+		}
+	}
+	for _, entry := range b.Audit {
+		if entry == nil {
+			return fmt.Errorf("audit entries must not be null")
+		}
+	}
+	for _, sample := range b.HealthHistory {
+		if sample == nil {
+			return fmt.Errorf("health samples must not be null")
+		}
+	}
+	return nil
+}
+
+// Backup returns a copy of everything in the store except sessions
+func (m *MemoryStorage) Backup() (*Backup, error) {
+	// Encode under the read lock so the archive is consistent, then decode
+	// so the caller never shares records with the store
+	m.mu.RLock()
+	data, err := json.Marshal(m.archive())
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode backup: %w", err)
+	}
+
+	backup := &Backup{Format: BackupFormat, CreatedAt: time.Now(), Migrations: migrations.Baseline()}
+	if err := json.Unmarshal(data, &backup.Archive); err != nil {
+		return nil, fmt.Errorf("failed to decode backup: %w", err)
+	}
+	return backup, nil
+}
+
+// Restore replaces everything in the store except sessions with a backup
+// from ParseBackup. A journaled store rewrites its journal to match before
+// the new state becomes visible, so a crash leaves either the old or the
+// new state behind, never a mix.
+func (m *MemoryStorage) Restore(backup *Backup) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.journal != nil {
+		restored := NewMemoryStorage()
+		restored.load(&backup.Archive)
+		restored.sessions = m.sessions
+		if err := m.journal.replace(restored); err != nil {
+			return err
+		}
+	}
+	m.load(&backup.Archive)
+	return nil
+}
+
+// archive captures the in-memory state; the caller must hold the read lock
+func (m *MemoryStorage) archive() *Archive {
+	archive := &Archive{
+		Plants:        make([]*models.PlantState, 0, len(m.plants)),
+		LastPlantID:   m.lastPlantID,
+		Config:        m.config,
+		Users:         make([]*models.User, 0, len(m.users)),
+		Notifications: m.notifications,
+		Waterings:     m.waterings,
+		Readings:      m.readings,
+		CareEvents:    m.careEvents,
+		Audit:         m.audit,
+		HealthHistory: m.health,
+	}
+	for _, plant := range m.plants {
+		archive.Plants = append(archive.Plants, plant)
+	}
+	sort.Slice(archive.Plants, func(i, j int) bool { return archive.Plants[i].ID < archive.Plants[j].ID })
+	for _, user := range m.users {
+		archive.Users = append(archive.Users, user)
+	}
+	for _, subscription := range m.subscriptions {
+		archive.Subscriptions = append(archive.Subscriptions, subscription)
+	}
+	sort.Slice(archive.Subscriptions, func(i, j int) bool {
+		return archive.Subscriptions[i].Endpoint < archive.Subscriptions[j].Endpoint
+	})
+	for _, key := range m.apiKeys {
+		archive.APIKeys = append(archive.APIKeys, key)
+	}
+	sort.Slice(archive.APIKeys, func(i, j int) bool { return archive.APIKeys[i].ID < archive.APIKeys[j].ID })
+	for _, device := range m.devices {
+		archive.Devices = append(archive.Devices, device)
+	}
+	sort.Slice(archive.Devices, func(i, j int) bool { return archive.Devices[i].ID < archive.Devices[j].ID })
+	for _, link := range m.shares {
+		archive.ShareLinks = append(archive.ShareLinks, link)
+	}
+	sort.Slice(archive.ShareLinks, func(i, j int) bool { return archive.ShareLinks[i].ID < archive.ShareLinks[j].ID })
+	for _, invite := range m.invites {
+		archive.Invites = append(archive.Invites, invite)
+	}
+	sort.Slice(archive.Invites, func(i, j int) bool { return archive.Invites[i].ID < archive.Invites[j].ID })
+	for _, record := range m.activity {
+		archive.UserActivity = append(archive.UserActivity, record)
+	}
+	sort.Slice(archive.UserActivity, func(i, j int) bool {
+		return archive.UserActivity[i].Email < archive.UserActivity[j].Email
+	})
+	for _, task := range m.careTasks {
+		archive.CareTasks = append(archive.CareTasks, task)
+	}
+	sort.Slice(archive.CareTasks, func(i, j int) bool { return archive.CareTasks[i].ID < archive.CareTasks[j].ID })
+	return archive
+}
+
+// load replaces the in-memory state, except sessions, with an archive; the
+// caller must hold the write lock
+func (m *MemoryStorage) load(archive *Archive) {
+	m.plants = make(map[int]*models.PlantState, len(archive.Plants))
+	m.lastPlantID = archive.LastPlantID
+	for _, plant := range archive.Plants {
+		m.putPlant(plant)
+	}
+	m.config = archive.Config
+	m.users = make(map[string]*models.User, len(archive.Users))
+	for _, user := range archive.Users {
+		m.users[user.Email] = user
+	}
+	m.notifications = archive.Notifications
+	m.waterings = archive.Waterings
+	m.subscriptions = make(map[string]*models.PushSubscription, len(archive.Subscriptions))
+	for _, subscription := range archive.Subscriptions {
+		m.subscriptions[subscription.Endpoint] = subscription
+	}
+	m.apiKeys = make(map[string]*models.APIKey, len(archive.APIKeys))
+	for _, key := range archive.APIKeys {
+		m.apiKeys[key.ID] = key
+	}
+	m.devices = make(map[string]*models.Device, len(archive.Devices))
+	for _, device := range archive.Devices {
+		m.devices[device.ID] = device
+	}
+	m.shares = make(map[string]*models.ShareLink, len(archive.ShareLinks))
+	for _, link := range archive.ShareLinks {
+		m.shares[link.ID] = link
+	}
+	m.invites = make(map[string]*models.Invite, len(archive.Invites))
+	for _, invite := range archive.Invites {
+		m.invites[invite.ID] = invite
+	}
+	m.activity = make(map[string]*models.UserActivity, len(archive.UserActivity))
+	for _, record := range archive.UserActivity {
+		m.activity[record.Email] = record
+	}
+	m.readings = archive.Readings
+	m.careTasks = make(map[int]*models.CareTask, len(archive.CareTasks))
+	for _, task := range archive.CareTasks {
+		m.careTasks[task.ID] = task
+	}
+	m.careEvents = archive.CareEvents
+	m.audit = archive.Audit
+	m.health = archive.HealthHistory
+}
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+)
+
+// seedBackupStore fills a store with one record of most kinds
+func seedBackupStore(t *testing.T, store Storage) {
+	t.Helper()
+	now := time.Now()
+	writes := []error{
+		store.UpdatePlantState(&models.PlantState{Name: "Fern", LastWatered: &now, TimeoutHours: 48, WateredBy: "test@example.com"}),
+		store.CreateUser(&models.User{Email: "test@example.com", Name: "Test User"}),
+		store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 48, AdminEmails: []string{"test@example.com"}}),
+		store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 1, WateredAt: now, WateredBy: "test@example.com"}),
+		store.SaveAPIKey(&models.APIKey{ID: "key", Name: "Home Assistant", Hash: "hash"}),
+		store.SaveShareLink(&models.ShareLink{ID: "grandma", Name: "Grandma", PlantID: 1, TokenHash: "hash"}),
+		store.SaveSession(&models.Session{ID: "session", Email: "test@example.com", Authenticated: true}),
+		store.SaveCareTask(&models.CareTask{PlantID: 1, Type: models.CareTaskMist, IntervalHours: 48}),
+		store.AddAuditEntry(&models.AuditEntry{Actor: "test@example.com", Action: models.AuditConfigTimeout}),
+	}
+	for _, err := range writes {
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
+
+// encodeBackup takes a backup of store and encodes it as a client would download it
+func encodeBackup(t *testing.T, store Storage) []byte {
+	t.Helper()
+	backup, err := store.Backup()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := json.Marshal(backup)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return data
+}
+
+// encodeArchive encodes what a backup of store holds, without the time it was taken
+func encodeArchive(t *testing.T, store Storage) string {
+	t.Helper()
+	backup, err := store.Backup()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := json.Marshal(backup.Archive)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return string(data)
+}
+
+func TestBackup_ExcludesSessions(t *testing.T) {
+	store := NewMemoryStorage()
+	seedBackupStore(t, store)
+
+	data := encodeBackup(t, store)
+	if strings.Contains(string(data), `"sessions"`) {
+		t.Errorf("Expected sessions to be left out of the backup, got %s", data)
+	}
+	if !strings.Contains(string(data), `"format":"watered-backup"`) || !strings.Contains(string(data), `"schema_migrations"`) {
+		t.Errorf("Expected the format marker and schema migrations, got %s", data)
+	}
+}
+
+func TestRestore_RoundTripAcrossBackends(t *testing.T) {
+	source := NewMemoryStorage()
+	seedBackupStore(t, source)
+	data := encodeBackup(t, source)
+
+	dir := t.TempDir()
+	journaled, err := NewJournaledMemoryStorage(filepath.Join(dir, "watered.journal"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	file, err := NewFileStorage(filepath.Join(dir, "watered.json"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for name, target := range map[string]Storage{"journal": journaled, "file": file} {
+		t.Run(name, func(t *testing.T) {
+			// The target's own data is replaced, but its sessions survive
+			target.CreatePlant(&models.PlantState{Name: "Cactus", TimeoutHours: 336})
+			target.CreatePlant(&models.PlantState{Name: "Palm", TimeoutHours: 72})
+			target.SaveSession(&models.Session{ID: "mine", Email: "admin@example.com", Authenticated: true})
+
+			backup, err := ParseBackup(data)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := target.Restore(backup); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			plants, _ := target.ListPlants()
+			if len(plants) != 1 || plants[0].Name != "Fern" {
+				t.Errorf("Expected only the restored fern, got %+v", plants)
+			}
+			if session, _ := target.GetSession("mine"); session == nil {
+				t.Error("Expected the target's own session to survive a restore")
+			}
+			if session, _ := target.GetSession("session"); session != nil {
+				t.Error("Expected no sessions to be restored")
+			}
+			if link, _ := target.GetShareLink("grandma"); link == nil || link.TokenHash != "hash" {
+				t.Errorf("Expected the share link to be restored, got %+v", link)
+			}
+			if tasks, _ := target.ListCareTasks(1); len(tasks) != 1 {
+				t.Errorf("Expected the care task to be restored, got %+v", tasks)
+			}
+			if want, got := encodeArchive(t, source), encodeArchive(t, target); got != want {
+				t.Errorf("Expected a backup of the restored store to match the original\nwant %s\ngot  %s", want, got)
+			}
+		})
+	}
+	journaled.Close()
+	file.Close()
+
+	// Both backends load the restored state after a restart
+	reopened, err := NewJournaledMemoryStorage(filepath.Join(dir, "watered.journal"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer reopened.Close()
+	if plants, _ := reopened.ListPlants(); len(plants) != 1 || plants[0].Name != "Fern" {
+		t.Errorf("Expected the restored journal to replay, got %+v", plants)
+	}
+	if session, _ := reopened.GetSession("mine"); session == nil {
+		t.Error("Expected the journal to keep the target's sessions")
+	}
+	reloaded, err := NewFileStorage(filepath.Join(dir, "watered.json"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if plants, _ := reloaded.ListPlants(); len(plants) != 1 || plants[0].Name != "Fern" {
+		t.Errorf("Expected the restored data file to load, got %+v", plants)
+	}
+}
+
+func TestParseBackup_MigratesOlderBackups(t *testing.T) {
+	// A backup from before multi-plant support, with no schema migrations
+	data := `{"format":"watered-backup","plant":{"name":"Fern","timeout_hours":48}}`
+
+	backup, err := ParseBackup([]byte(data))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(backup.Plants) != 1 || backup.Plants[0].Name != "Fern" || backup.Plants[0].ID != models.DefaultPlantID {
+		t.Errorf("Expected the legacy plant to be migrated, got %+v", backup.Plants)
+	}
+	if len(backup.Migrations) == 0 {
+		t.Error("Expected the applied migrations to be recorded")
+	}
+}
+
+func TestParseBackup_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not json", `not json`},
+		{"not an object", `[]`},
+		{"missing format", `{"plants":[]}`},
+		{"data file", `{"schema_migrations":[],"plants":[]}`},
+		{"newer schema", `{"format":"watered-backup","schema_migrations":[{"version":9999,"name":"future"}]}`},
+		{"duplicate plant", `{"format":"watered-backup","plants":[{"id":1,"name":"Fern","timeout_hours":48},{"id":1,"name":"Palm","timeout_hours":48}]}`},
+		{"invalid plant", `{"format":"watered-backup","plants":[{"id":1,"name":"","timeout_hours":48}]}`},
+		{"plant without ID", `{"format":"watered-backup","plants":[{"name":"Fern","timeout_hours":48}]}`},
+		{"null plant", `{"format":"watered-backup","plants":[null]}`},
+		{"duplicate user", `{"format":"watered-backup","users":[{"email":"a@example.com"},{"email":"a@example.com"}]}`},
+		{"orphaned care task", `{"format":"watered-backup","care_tasks":[{"id":1,"plant_id":2,"type":"mist","interval_hours":48}]}`},
+		{"wrong types", `{"format":"watered-backup","plants":"Fern"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseBackup([]byte(tt.data)); !errors.Is(err, ErrInvalidBackup) {
+				t.Errorf("Expected ErrInvalidBackup, got %v", err)
+			}
+		})
+	}
+}
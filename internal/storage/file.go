@@ -0,0 +1,516 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"watered/internal/models"
+	"watered/internal/storage/migrations"
+)
+
+// fileSnapshot is the on-disk representation of all stored state. It has
+// the same layout as a Backup, plus the sessions backups leave out.
+type fileSnapshot struct {
+	Migrations []migrations.AppliedMigration `json:"schema_migrations"`
+	Archive
+	Sessions []*models.Session `json:"sessions"`
+}
+
+// FileStorage keeps state in memory and persists it to a single JSON file
+// after every write. Writes go to a temporary file that is fsynced and then
+// atomically renamed over the previous file, so a crash never leaves a
+// partially written file behind.
+type FileStorage struct {
+	*MemoryStorage
+	path    string
+	writeMu sync.Mutex
+
+	// applied is the applied-versions table, written back on every save
+	applied []migrations.AppliedMigration
+}
+
+// NewFileStorage creates a file-backed storage, loading existing state from
+// path if present. Pending schema migrations are applied automatically.
+func NewFileStorage(path string) (*FileStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	f := &FileStorage{
+		MemoryStorage: NewMemoryStorage(),
+		path:          path,
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		slog.Info("No data file, starting with empty storage", "path", path)
+		f.applied = migrations.Baseline()
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+
+	doc, _, err := migrateData(path, data, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Round-trip the migrated document into the typed snapshot
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode migrated data: %w", err)
+	}
+	var snapshot fileSnapshot
+	if err := json.Unmarshal(migrated, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse data file %s: %w", path, err)
+	}
+	f.restore(&snapshot)
+
+	slog.Info("Loaded data file", "path", path)
+	return f, nil
+}
+
+// MigrateFile applies pending schema migrations to the data file at path.
+// With dryRun set the file is not modified and the pending migrations are
+// only reported.
+func MigrateFile(path string, dryRun bool) (*migrations.Result, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &migrations.Result{DryRun: dryRun, Applied: []migrations.AppliedMigration{}, Pending: []string{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+
+	_, result, err := migrateData(path, data, dryRun)
+	return result, err
+}
+
+// migrateData decodes a data file and runs pending migrations against it,
+// writing the migrated document back to path unless dryRun is set
+func migrateData(path string, data []byte, dryRun bool) (migrations.Document, *migrations.Result, error) {
+	var doc migrations.Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse data file %s: %w", path, err)
+	}
+	if doc == nil {
+		doc = migrations.Document{}
+	}
+
+	result, err := migrations.Run(doc, dryRun)
+	if err != nil {
+		return nil, result, err
+	}
+
+	for _, name := range result.Pending {
+		slog.Info("Pending migration (dry run)", "migration", name)
+	}
+	if len(result.Applied) == 0 {
+		return doc, result, nil
+	}
+
+	for _, applied := range result.Applied {
+		slog.Info("Applied migration", "version", applied.Version, "migration", applied.Name, "path", path)
+	}
+	migrated, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to encode migrated data: %w", err)
+	}
+	if err := writeFileAtomic(path, migrated); err != nil {
+		return nil, result, fmt.Errorf("failed to write migrated data: %w", err)
+	}
+	return doc, result, nil
+}
+
+// PendingMigrations returns the migrations not yet applied to the data
+// file, which is empty once it has been opened successfully
+func (f *FileStorage) PendingMigrations() []string {
+	f.MemoryStorage.mu.RLock()
+	defer f.MemoryStorage.mu.RUnlock()
+	return migrations.Pending(f.applied)
+}
+
+// restore replaces the in-memory state with a snapshot
+func (f *FileStorage) restore(snapshot *fileSnapshot) {
+	m := f.MemoryStorage
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f.applied = snapshot.Migrations
+	m.load(&snapshot.Archive)
+	m.sessions = make(map[string]*models.Session, len(snapshot.Sessions))
+	for _, session := range snapshot.Sessions {
+		m.sessions[session.ID] = session
+	}
+}
+
+// save writes the current state to disk atomically
+func (f *FileStorage) save() error {
+	f.writeMu.Lock()
+	defer f.writeMu.Unlock()
+
+	// Marshal while holding the read lock so the snapshot is consistent
+	f.MemoryStorage.mu.RLock()
+	data, err := json.MarshalIndent(f.snapshot(), "", "  ")
+	f.MemoryStorage.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode data: %w", err)
+	}
+
+	return writeFileAtomic(f.path, data)
+}
+
+// snapshot captures the in-memory state; the caller must hold the read lock
+func (f *FileStorage) snapshot() *fileSnapshot {
+	m := f.MemoryStorage
+	snapshot := &fileSnapshot{Migrations: f.applied, Archive: *m.archive()}
+	for _, session := range m.sessions {
+		snapshot.Sessions = append(snapshot.Sessions, session)
+	}
+	sort.Slice(snapshot.Sessions, func(i, j int) bool { return snapshot.Sessions[i].ID < snapshot.Sessions[j].ID })
+	return snapshot
+}
+
+// writeFileAtomic writes data to a temp file, fsyncs it and renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // No-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace data file: %w", err)
+	}
+
+	// Sync the directory so the rename itself is durable
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// UpdatePlantState updates the plant state and persists it
+func (f *FileStorage) UpdatePlantState(state *models.PlantState) error {
+	if err := f.MemoryStorage.UpdatePlantState(state); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// CreatePlant stores a new plant and persists it
+func (f *FileStorage) CreatePlant(plant *models.PlantState) error {
+	if err := f.MemoryStorage.CreatePlant(plant); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// UpdatePlant updates an existing plant and persists it
+func (f *FileStorage) UpdatePlant(plant *models.PlantState) error {
+	if err := f.MemoryStorage.UpdatePlant(plant); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// DeletePlant removes a plant and persists the change
+func (f *FileStorage) DeletePlant(id int) error {
+	if err := f.MemoryStorage.DeletePlant(id); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// CreateUser creates or updates a user and persists it
+func (f *FileStorage) CreateUser(user *models.User) error {
+	if err := f.MemoryStorage.CreateUser(user); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// DeleteUser removes a user and persists the change
+func (f *FileStorage) DeleteUser(email string) error {
+	if err := f.MemoryStorage.DeleteUser(email); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// UpdateAdminConfig updates the admin configuration and persists it
+func (f *FileStorage) UpdateAdminConfig(config *models.AdminConfig) error {
+	if err := f.MemoryStorage.UpdateAdminConfig(config); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// CreateNotification records a notification and persists it
+func (f *FileStorage) CreateNotification(notification *models.Notification) error {
+	if err := f.MemoryStorage.CreateNotification(notification); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// ReassignNotifications moves notifications between users and persists the change
+func (f *FileStorage) ReassignNotifications(fromEmail, toEmail string) (int, error) {
+	count, err := f.MemoryStorage.ReassignNotifications(fromEmail, toEmail)
+	if err != nil || count == 0 {
+		return count, err
+	}
+	return count, f.save()
+}
+
+// AddWateringEvent records a watering and persists it
+func (f *FileStorage) AddWateringEvent(event *models.PlantWateringEvent) error {
+	if err := f.MemoryStorage.AddWateringEvent(event); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// DeleteWateringEvent removes a watering and persists the change
+func (f *FileStorage) DeleteWateringEvent(id int) error {
+	if err := f.MemoryStorage.DeleteWateringEvent(id); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// ReassignWateringEvents moves waterings between users and persists the change
+func (f *FileStorage) ReassignWateringEvents(fromEmail, toEmail string) (int, error) {
+	count, err := f.MemoryStorage.ReassignWateringEvents(fromEmail, toEmail)
+	if err != nil || count == 0 {
+		return count, err
+	}
+	return count, f.save()
+}
+
+// SavePushSubscription stores a push subscription and persists it
+func (f *FileStorage) SavePushSubscription(subscription *models.PushSubscription) error {
+	if err := f.MemoryStorage.SavePushSubscription(subscription); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// DeletePushSubscription removes a push subscription and persists the change
+func (f *FileStorage) DeletePushSubscription(endpoint string) error {
+	if err := f.MemoryStorage.DeletePushSubscription(endpoint); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// SaveAPIKey stores an API key and persists it
+func (f *FileStorage) SaveAPIKey(key *models.APIKey) error {
+	if err := f.MemoryStorage.SaveAPIKey(key); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// DeleteAPIKey removes an API key and persists the change
+func (f *FileStorage) DeleteAPIKey(id string) error {
+	if err := f.MemoryStorage.DeleteAPIKey(id); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// SaveDevice stores a device and persists it
+func (f *FileStorage) SaveDevice(device *models.Device) error {
+	if err := f.MemoryStorage.SaveDevice(device); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// DeleteDevice removes a device and persists the change
+func (f *FileStorage) DeleteDevice(id string) error {
+	if err := f.MemoryStorage.DeleteDevice(id); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// SaveShareLink stores a share link and persists it
+func (f *FileStorage) SaveShareLink(link *models.ShareLink) error {
+	if err := f.MemoryStorage.SaveShareLink(link); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// DeleteShareLink removes a share link and persists the change
+func (f *FileStorage) DeleteShareLink(id string) error {
+	if err := f.MemoryStorage.DeleteShareLink(id); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// SaveInvite stores an invite and persists it
+func (f *FileStorage) SaveInvite(invite *models.Invite) error {
+	if err := f.MemoryStorage.SaveInvite(invite); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// DeleteInvite removes an invite and persists the change
+func (f *FileStorage) DeleteInvite(id string) error {
+	if err := f.MemoryStorage.DeleteInvite(id); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// SaveSession stores a session and persists it
+func (f *FileStorage) SaveSession(session *models.Session) error {
+	if err := f.MemoryStorage.SaveSession(session); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// DeleteSession removes a session and persists the change
+func (f *FileStorage) DeleteSession(id string) error {
+	return f.DeleteSessions([]string{id})
+}
+
+// DeleteSessions removes sessions and persists the change in one write
+func (f *FileStorage) DeleteSessions(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := f.MemoryStorage.DeleteSessions(ids); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// SaveUserActivity stores a batch of activity records and persists them in
+// one write
+func (f *FileStorage) SaveUserActivity(activity []*models.UserActivity) error {
+	if len(activity) == 0 {
+		return nil
+	}
+	if err := f.MemoryStorage.SaveUserActivity(activity); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// DeleteUserActivity removes a user's activity record and persists the
+// change
+func (f *FileStorage) DeleteUserActivity(email string) error {
+	if err := f.MemoryStorage.DeleteUserActivity(email); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// AddSensorReading records a sensor reading and persists it
+func (f *FileStorage) AddSensorReading(reading *models.SensorReading) error {
+	if err := f.MemoryStorage.AddSensorReading(reading); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// SaveCareTask stores a care task and persists it
+func (f *FileStorage) SaveCareTask(task *models.CareTask) error {
+	if err := f.MemoryStorage.SaveCareTask(task); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// DeleteCareTask removes a care task and its history and persists the change
+func (f *FileStorage) DeleteCareTask(id int) error {
+	if err := f.MemoryStorage.DeleteCareTask(id); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// AddCareTaskEvent records a care task completion and persists it
+func (f *FileStorage) AddCareTaskEvent(event *models.CareTaskEvent) error {
+	if err := f.MemoryStorage.AddCareTaskEvent(event); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// ReassignCareTaskEvents moves care task completions between users and
+// persists the change
+func (f *FileStorage) ReassignCareTaskEvents(fromEmail, toEmail string) (int, error) {
+	count, err := f.MemoryStorage.ReassignCareTaskEvents(fromEmail, toEmail)
+	if err != nil || count == 0 {
+		return count, err
+	}
+	return count, f.save()
+}
+
+// AddAuditEntry records an audit log entry and persists it
+func (f *FileStorage) AddAuditEntry(entry *models.AuditEntry) error {
+	if err := f.MemoryStorage.AddAuditEntry(entry); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// AddHealthSample records a health check result and persists it
+func (f *FileStorage) AddHealthSample(sample *models.HealthSample) error {
+	if err := f.MemoryStorage.AddHealthSample(sample); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// PruneHealthSamples removes old health samples and persists the change
+// when any were removed
+func (f *FileStorage) PruneHealthSamples(before time.Time) (int, error) {
+	removed, err := f.MemoryStorage.PruneHealthSamples(before)
+	if err != nil || removed == 0 {
+		return removed, err
+	}
+	return removed, f.save()
+}
+
+// Restore replaces everything except sessions with a backup and persists it
+func (f *FileStorage) Restore(backup *Backup) error {
+	if err := f.MemoryStorage.Restore(backup); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// Close flushes state to disk
+func (f *FileStorage) Close() error {
+	return f.save()
+}
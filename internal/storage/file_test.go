@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+)
+
+func TestFileStorage_PersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "watered.json")
+
+	store, err := NewFileStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create file storage: %v", err)
+	}
+
+	now := time.Now()
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Fern", LastWatered: &now, TimeoutHours: 48, WateredBy: "test@example.com"})
+	store.CreateUser(&models.User{Email: "test@example.com", Name: "Test User"})
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 48, AllowedEmails: []string{"test@example.com"}})
+	store.CreateNotification(&models.Notification{UserEmail: "test@example.com", Channel: "email"})
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 1, WateredAt: now, WateredBy: "test@example.com"})
+	store.SavePushSubscription(&models.PushSubscription{UserEmail: "test@example.com", Endpoint: "https://push.example.com/1"})
+	store.SaveAPIKey(&models.APIKey{ID: "abc", Name: "Home Assistant", Hash: "hash", CreatedBy: "test@example.com"})
+	store.SaveDevice(&models.Device{ID: "kitchen", Kind: models.DeviceKindSensor, PlantID: 1, TokenHash: "device-hash", LastSeen: &now})
+	store.AddAuditEntry(&models.AuditEntry{At: now, Actor: "admin@example.com", Action: models.AuditPlantReset, Target: "1"})
+	store.SaveSession(&models.Session{ID: "session-hash", Email: "test@example.com", Authenticated: true, CSRFToken: "csrf", ExpiresAt: now.Add(time.Hour)})
+	store.SaveUserActivity([]*models.UserActivity{{Email: "test@example.com", FirstSeen: now, LastSeen: now, UserAgent: "Firefox"}})
+	temperature := 21.5
+	store.AddSensorReading(&models.SensorReading{DeviceID: "kitchen", PlantID: 1, Temperature: &temperature, RecordedAt: now})
+	store.Close()
+
+	reopened, err := NewFileStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen file storage: %v", err)
+	}
+
+	plant, _ := reopened.GetPlantState()
+	if plant == nil || plant.Name != "Fern" || plant.TimeoutHours != 48 || plant.LastWatered == nil {
+		t.Errorf("Expected plant to survive restart, got %+v", plant)
+	}
+	if user, _ := reopened.GetUser("test@example.com"); user == nil || user.Name != "Test User" {
+		t.Errorf("Expected user to survive restart, got %+v", user)
+	}
+	if config, _ := reopened.GetAdminConfig(); config == nil || len(config.AllowedEmails) != 1 {
+		t.Errorf("Expected config to survive restart, got %+v", config)
+	}
+	if notifications, _ := reopened.ListNotifications(models.NotificationFilter{}); len(notifications) != 1 {
+		t.Errorf("Expected 1 notification after restart, got %d", len(notifications))
+	}
+	if events, _ := reopened.ListWateringEvents(1); len(events) != 1 || events[0].WateredBy != "test@example.com" {
+		t.Errorf("Expected watering event to survive restart, got %+v", events)
+	}
+	if subscriptions, _ := reopened.ListPushSubscriptions("test@example.com"); len(subscriptions) != 1 {
+		t.Errorf("Expected 1 push subscription after restart, got %d", len(subscriptions))
+	}
+	if key, _ := reopened.GetAPIKey("abc"); key == nil || key.Hash != "hash" {
+		t.Errorf("Expected API key to survive restart, got %+v", key)
+	}
+	if device, _ := reopened.GetDevice("kitchen"); device == nil || device.TokenHash != "device-hash" || device.LastSeen == nil {
+		t.Errorf("Expected device to survive restart, got %+v", device)
+	}
+	if session, _ := reopened.GetSession("session-hash"); session == nil || session.CSRFToken != "csrf" || !session.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected session to survive restart, got %+v", session)
+	}
+	if entries, _ := reopened.ListAuditEntries(models.AuditFilter{}); len(entries) != 1 || entries[0].Target != "1" {
+		t.Errorf("Expected audit log to survive restart, got %+v", entries)
+	}
+	if activity, _ := reopened.ListUserActivity(); len(activity) != 1 || activity[0].UserAgent != "Firefox" {
+		t.Errorf("Expected user activity to survive restart, got %+v", activity)
+	}
+	if readings, _ := reopened.ListSensorReadings(models.SensorReadingFilter{PlantID: 1}); len(readings) != 1 || *readings[0].Temperature != 21.5 {
+		t.Errorf("Expected sensor reading to survive restart, got %+v", readings)
+	}
+}
+
+func TestFileStorage_PersistsMultiplePlants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watered.json")
+
+	store, err := NewFileStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create file storage: %v", err)
+	}
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Default", TimeoutHours: 24})
+	store.CreatePlant(&models.PlantState{Name: "Cactus", TimeoutHours: 336})
+	store.Close()
+
+	reopened, err := NewFileStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen file storage: %v", err)
+	}
+
+	plants, _ := reopened.ListPlants()
+	if len(plants) != 2 || plants[1].Name != "Cactus" {
+		t.Errorf("Expected both plants to survive restart, got %+v", plants)
+	}
+}
+
+func TestFileStorage_DoesNotReusePlantIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watered.json")
+
+	store, err := NewFileStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create file storage: %v", err)
+	}
+	store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Default", TimeoutHours: 24})
+	store.CreatePlant(&models.PlantState{Name: "Cactus", TimeoutHours: 336})
+	store.DeletePlant(2)
+	store.Close()
+
+	reopened, err := NewFileStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen file storage: %v", err)
+	}
+
+	fern := &models.PlantState{Name: "Fern", TimeoutHours: 48}
+	reopened.CreatePlant(fern)
+	if fern.ID != 3 {
+		t.Errorf("Expected plant ID 3 after deleting plant 2, got %d", fern.ID)
+	}
+}
+
+func TestFileStorage_LoadsLegacySinglePlant(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watered.json")
+	legacy := `{"plant":{"id":1,"name":"Old Plant","timeout_hours":12}}`
+	if err := os.WriteFile(path, []byte(legacy), 0600); err != nil {
+		t.Fatalf("Failed to write legacy file: %v", err)
+	}
+
+	store, err := NewFileStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to load legacy file: %v", err)
+	}
+
+	plant, _ := store.GetPlantState()
+	if plant == nil || plant.Name != "Old Plant" || plant.TimeoutHours != 12 {
+		t.Errorf("Expected legacy plant to load, got %+v", plant)
+	}
+	if pending := store.PendingMigrations(); len(pending) != 0 {
+		t.Errorf("Expected the storage to report no pending migrations, got %v", pending)
+	}
+
+	// The migration was recorded, so a dry run finds nothing pending
+	result, err := MigrateFile(path, true)
+	if err != nil {
+		t.Fatalf("Failed to dry-run migrations: %v", err)
+	}
+	if len(result.Pending) != 0 {
+		t.Errorf("Expected no pending migrations after startup, got %v", result.Pending)
+	}
+}
+
+func TestMigrateFile_DryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watered.json")
+	legacy := `{"plant":{"id":1,"name":"Old Plant","timeout_hours":12}}`
+	if err := os.WriteFile(path, []byte(legacy), 0600); err != nil {
+		t.Fatalf("Failed to write legacy file: %v", err)
+	}
+
+	result, err := MigrateFile(path, true)
+	if err != nil {
+		t.Fatalf("Failed to dry-run migrations: %v", err)
+	}
+	if len(result.Pending) != 1 {
+		t.Errorf("Expected 1 pending migration, got %v", result.Pending)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != legacy {
+		t.Errorf("Expected dry run to leave the file untouched, got %s", data)
+	}
+}
+
+func TestFileStorage_AtomicWriteLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "watered.json")
+
+	store, err := NewFileStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create file storage: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		store.UpdatePlantState(&models.PlantState{ID: 1, Name: "Plant", TimeoutHours: 24 + i})
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "watered.json" {
+		names := []string{}
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("Expected only the data file, found %v", names)
+	}
+}
+
+func TestFileStorage_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watered.json")
+	os.WriteFile(path, []byte("{not json"), 0o600)
+
+	if _, err := NewFileStorage(path); err == nil {
+		t.Error("Expected error loading a corrupt data file")
+	}
+}
+
+func TestOpen_SelectsBackend(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		cfg      config.StorageConfig
+		expected string
+	}{
+		{"memory", config.StorageConfig{}, "*storage.MemoryStorage"},
+		{"data file", config.StorageConfig{DataFile: filepath.Join(dir, "watered.json"), JournalFile: filepath.Join(dir, "ignored.journal")}, "*storage.FileStorage"},
+		{"journal", config.StorageConfig{JournalFile: filepath.Join(dir, "watered.journal")}, "*storage.MemoryStorage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := Open(tt.cfg)
+			if err != nil {
+				t.Fatalf("Failed to open storage: %v", err)
+			}
+			defer store.Close()
+
+			if got := fmt.Sprintf("%T", store); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "ignored.journal")); !os.IsNotExist(err) {
+		t.Error("Expected JOURNAL_FILE to be ignored when DATA_FILE is set")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "watered.journal")); err != nil {
+		t.Errorf("Expected journal file to be created, got %v", err)
+	}
+}
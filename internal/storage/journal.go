@@ -0,0 +1,571 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"watered/internal/models"
+)
+
+// Journal operations. Each entry fully describes one write so the journal
+// can be replayed without access to the previous in-memory state.
+const (
+	opPutPlant               = "put_plant"
+	opDeletePlant            = "delete_plant"
+	opSetLastPlantID         = "set_last_plant_id"
+	opPutUser                = "put_user"
+	opDeleteUser             = "delete_user"
+	opPutConfig              = "put_config"
+	opAddNotification        = "add_notification"
+	opReassignNotifications  = "reassign_notifications"
+	opAddWateringEvent       = "add_watering_event"
+	opDeleteWateringEvent    = "delete_watering_event"
+	opReassignWateringEvents = "reassign_watering_events"
+	opPutPushSubscription    = "put_push_subscription"
+	opDeletePushSubscription = "delete_push_subscription"
+	opPutAPIKey              = "put_api_key"
+	opDeleteAPIKey           = "delete_api_key"
+	opPutDevice              = "put_device"
+	opDeleteDevice           = "delete_device"
+	opPutShareLink           = "put_share_link"
+	opDeleteShareLink        = "delete_share_link"
+	opPutInvite              = "put_invite"
+	opDeleteInvite           = "delete_invite"
+	opPutSession             = "put_session"
+	opDeleteSessions         = "delete_sessions"
+	opPutUserActivity        = "put_user_activity"
+	opDeleteUserActivity     = "delete_user_activity"
+	opAddSensorReading       = "add_sensor_reading"
+	opPutCareTask            = "put_care_task"
+	opDeleteCareTask         = "delete_care_task"
+	opAddCareTaskEvent       = "add_care_task_event"
+	opReassignCareTaskEvents = "reassign_care_task_events"
+	opAddAuditEntry          = "add_audit_entry"
+	opAddHealthSample        = "add_health_sample"
+	opPruneHealthSamples     = "prune_health_samples"
+)
+
+// journalEntry is a single line in the append-only journal file
+type journalEntry struct {
+	Op   string          `json:"op"`
+	At   time.Time       `json:"at"`
+	Data json.RawMessage `json:"data"`
+}
+
+// reassignment is the payload of reassign_notifications,
+// reassign_watering_events and reassign_care_task_events entries
+type reassignment struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// journal appends write entries to a JSON-lines file, fsyncing each one
+type journal struct {
+	path string
+	file *os.File
+}
+
+// NewJournaledMemoryStorage creates an in-memory storage that records every
+// write in an append-only journal at path. Existing entries are replayed on
+// startup and the journal is then compacted to one entry per stored record.
+func NewJournaledMemoryStorage(path string) (*MemoryStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	m := NewMemoryStorage()
+	replayed, err := m.replayJournal(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.compactJournal(path); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	m.journal = &journal{path: path, file: file}
+
+	slog.Info("Journal opened", "path", path, "replayed", replayed)
+	return m, nil
+}
+
+// replayJournal applies every entry in the journal file, returning how many were read
+func (m *MemoryStorage) replayJournal(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A torn final line is expected after a crash mid-append
+			if !bytes.HasSuffix(data, []byte("\n")) && isLastLine(data, line) {
+				slog.Warn("Discarding incomplete journal entry", "line", line)
+				break
+			}
+			return count, fmt.Errorf("corrupt journal entry on line %d: %w", line, err)
+		}
+
+		if err := m.applyEntry(&entry); err != nil {
+			return count, fmt.Errorf("failed to replay journal line %d: %w", line, err)
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return count, fmt.Errorf("failed to scan journal: %w", err)
+	}
+	return count, nil
+}
+
+// isLastLine reports whether the 1-based line number is the final line of data
+func isLastLine(data []byte, line int) bool {
+	return bytes.Count(data, []byte("\n")) == line-1
+}
+
+// applyEntry replays a journal entry; the caller must hold the write lock
+func (m *MemoryStorage) applyEntry(entry *journalEntry) error {
+	switch entry.Op {
+	case opPutPlant:
+		var plant models.PlantState
+		if err := json.Unmarshal(entry.Data, &plant); err != nil {
+			return err
+		}
+		m.putPlant(&plant)
+	case opDeletePlant:
+		var id int
+		if err := json.Unmarshal(entry.Data, &id); err != nil {
+			return err
+		}
+		m.deletePlant(id)
+	case opSetLastPlantID:
+		var id int
+		if err := json.Unmarshal(entry.Data, &id); err != nil {
+			return err
+		}
+		m.lastPlantID = max(m.lastPlantID, id)
+	case opPutUser:
+		var user models.User
+		if err := json.Unmarshal(entry.Data, &user); err != nil {
+			return err
+		}
+		m.users[user.Email] = &user
+	case opDeleteUser:
+		var email string
+		if err := json.Unmarshal(entry.Data, &email); err != nil {
+			return err
+		}
+		delete(m.users, email)
+	case opPutConfig:
+		var config models.AdminConfig
+		if err := json.Unmarshal(entry.Data, &config); err != nil {
+			return err
+		}
+		m.config = &config
+	case opAddNotification:
+		var notification models.Notification
+		if err := json.Unmarshal(entry.Data, &notification); err != nil {
+			return err
+		}
+		m.notifications = append(m.notifications, &notification)
+	case opReassignNotifications:
+		var r reassignment
+		if err := json.Unmarshal(entry.Data, &r); err != nil {
+			return err
+		}
+		for _, notification := range m.notifications {
+			if notification.UserEmail == r.From {
+				notification.UserEmail = r.To
+			}
+		}
+	case opAddWateringEvent:
+		var event models.PlantWateringEvent
+		if err := json.Unmarshal(entry.Data, &event); err != nil {
+			return err
+		}
+		m.waterings = append(m.waterings, &event)
+	case opDeleteWateringEvent:
+		var id int
+		if err := json.Unmarshal(entry.Data, &id); err != nil {
+			return err
+		}
+		m.deleteWateringEvent(id)
+	case opReassignWateringEvents:
+		var r reassignment
+		if err := json.Unmarshal(entry.Data, &r); err != nil {
+			return err
+		}
+		for _, event := range m.waterings {
+			if event.WateredBy == r.From {
+				event.WateredBy = r.To
+			}
+		}
+	case opPutPushSubscription:
+		var subscription models.PushSubscription
+		if err := json.Unmarshal(entry.Data, &subscription); err != nil {
+			return err
+		}
+		m.subscriptions[subscription.Endpoint] = &subscription
+	case opDeletePushSubscription:
+		var endpoint string
+		if err := json.Unmarshal(entry.Data, &endpoint); err != nil {
+			return err
+		}
+		delete(m.subscriptions, endpoint)
+	case opPutAPIKey:
+		var key models.APIKey
+		if err := json.Unmarshal(entry.Data, &key); err != nil {
+			return err
+		}
+		m.apiKeys[key.ID] = &key
+	case opDeleteAPIKey:
+		var id string
+		if err := json.Unmarshal(entry.Data, &id); err != nil {
+			return err
+		}
+		delete(m.apiKeys, id)
+	case opPutDevice:
+		var device models.Device
+		if err := json.Unmarshal(entry.Data, &device); err != nil {
+			return err
+		}
+		m.devices[device.ID] = &device
+	case opDeleteDevice:
+		var id string
+		if err := json.Unmarshal(entry.Data, &id); err != nil {
+			return err
+		}
+		delete(m.devices, id)
+	case opPutShareLink:
+		var link models.ShareLink
+		if err := json.Unmarshal(entry.Data, &link); err != nil {
+			return err
+		}
+		m.shares[link.ID] = &link
+	case opDeleteShareLink:
+		var id string
+		if err := json.Unmarshal(entry.Data, &id); err != nil {
+			return err
+		}
+		delete(m.shares, id)
+	case opPutInvite:
+		var invite models.Invite
+		if err := json.Unmarshal(entry.Data, &invite); err != nil {
+			return err
+		}
+		m.invites[invite.ID] = &invite
+	case opDeleteInvite:
+		var id string
+		if err := json.Unmarshal(entry.Data, &id); err != nil {
+			return err
+		}
+		delete(m.invites, id)
+	case opPutSession:
+		var session models.Session
+		if err := json.Unmarshal(entry.Data, &session); err != nil {
+			return err
+		}
+		m.sessions[session.ID] = &session
+	case opDeleteSessions:
+		var ids []string
+		if err := json.Unmarshal(entry.Data, &ids); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			delete(m.sessions, id)
+		}
+	case opPutUserActivity:
+		var activity []*models.UserActivity
+		if err := json.Unmarshal(entry.Data, &activity); err != nil {
+			return err
+		}
+		for _, record := range activity {
+			m.activity[record.Email] = record
+		}
+	case opDeleteUserActivity:
+		var email string
+		if err := json.Unmarshal(entry.Data, &email); err != nil {
+			return err
+		}
+		delete(m.activity, email)
+	case opAddSensorReading:
+		var reading models.SensorReading
+		if err := json.Unmarshal(entry.Data, &reading); err != nil {
+			return err
+		}
+		m.appendSensorReading(&reading)
+	case opPutCareTask:
+		var task models.CareTask
+		if err := json.Unmarshal(entry.Data, &task); err != nil {
+			return err
+		}
+		m.careTasks[task.ID] = &task
+	case opDeleteCareTask:
+		var id int
+		if err := json.Unmarshal(entry.Data, &id); err != nil {
+			return err
+		}
+		m.deleteCareTask(id)
+	case opAddCareTaskEvent:
+		var event models.CareTaskEvent
+		if err := json.Unmarshal(entry.Data, &event); err != nil {
+			return err
+		}
+		m.careEvents = append(m.careEvents, &event)
+	case opReassignCareTaskEvents:
+		var r reassignment
+		if err := json.Unmarshal(entry.Data, &r); err != nil {
+			return err
+		}
+		for _, event := range m.careEvents {
+			if event.DoneBy == r.From {
+				event.DoneBy = r.To
+			}
+		}
+	case opAddAuditEntry:
+		var auditEntry models.AuditEntry
+		if err := json.Unmarshal(entry.Data, &auditEntry); err != nil {
+			return err
+		}
+		m.audit = append(m.audit, &auditEntry)
+	case opAddHealthSample:
+		var sample models.HealthSample
+		if err := json.Unmarshal(entry.Data, &sample); err != nil {
+			return err
+		}
+		m.health = append(m.health, &sample)
+	case opPruneHealthSamples:
+		var before time.Time
+		if err := json.Unmarshal(entry.Data, &before); err != nil {
+			return err
+		}
+		m.pruneHealthSamples(before)
+	default:
+		return fmt.Errorf("unknown journal operation %q", entry.Op)
+	}
+	return nil
+}
+
+// compactJournal rewrites the journal as one entry per stored record so it
+// does not grow without bound across restarts
+func (m *MemoryStorage) compactJournal(path string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, err := m.journalContents()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// journalContents encodes the in-memory state as one journal entry per
+// stored record; the caller must hold the read lock
+func (m *MemoryStorage) journalContents() ([]byte, error) {
+	var buf bytes.Buffer
+	now := time.Now()
+	write := func(op string, data interface{}) error {
+		line, err := encodeJournalEntry(op, data, now)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		return nil
+	}
+
+	ids := make([]int, 0, len(m.plants))
+	for id := range m.plants {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		if err := write(opPutPlant, m.plants[id]); err != nil {
+			return nil, err
+		}
+	}
+	// Deleted plants are not written, but their IDs must stay used
+	if m.lastPlantID > 0 && (len(ids) == 0 || m.lastPlantID > ids[len(ids)-1]) {
+		if err := write(opSetLastPlantID, m.lastPlantID); err != nil {
+			return nil, err
+		}
+	}
+	for _, user := range m.users {
+		if err := write(opPutUser, user); err != nil {
+			return nil, err
+		}
+	}
+	if m.config != nil {
+		if err := write(opPutConfig, m.config); err != nil {
+			return nil, err
+		}
+	}
+	for _, notification := range m.notifications {
+		if err := write(opAddNotification, notification); err != nil {
+			return nil, err
+		}
+	}
+	for _, event := range m.waterings {
+		if err := write(opAddWateringEvent, event); err != nil {
+			return nil, err
+		}
+	}
+	for _, subscription := range m.subscriptions {
+		if err := write(opPutPushSubscription, subscription); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range m.apiKeys {
+		if err := write(opPutAPIKey, key); err != nil {
+			return nil, err
+		}
+	}
+	for _, device := range m.devices {
+		if err := write(opPutDevice, device); err != nil {
+			return nil, err
+		}
+	}
+	for _, link := range m.shares {
+		if err := write(opPutShareLink, link); err != nil {
+			return nil, err
+		}
+	}
+	for _, invite := range m.invites {
+		if err := write(opPutInvite, invite); err != nil {
+			return nil, err
+		}
+	}
+	for _, session := range m.sessions {
+		if err := write(opPutSession, session); err != nil {
+			return nil, err
+		}
+	}
+	if len(m.activity) > 0 {
+		activity := make([]*models.UserActivity, 0, len(m.activity))
+		for _, record := range m.activity {
+			activity = append(activity, record)
+		}
+		sort.Slice(activity, func(i, j int) bool { return activity[i].Email < activity[j].Email })
+		if err := write(opPutUserActivity, activity); err != nil {
+			return nil, err
+		}
+	}
+	for _, reading := range m.readings {
+		if err := write(opAddSensorReading, reading); err != nil {
+			return nil, err
+		}
+	}
+	taskIDs := make([]int, 0, len(m.careTasks))
+	for id := range m.careTasks {
+		taskIDs = append(taskIDs, id)
+	}
+	sort.Ints(taskIDs)
+	for _, id := range taskIDs {
+		if err := write(opPutCareTask, m.careTasks[id]); err != nil {
+			return nil, err
+		}
+	}
+	for _, event := range m.careEvents {
+		if err := write(opAddCareTaskEvent, event); err != nil {
+			return nil, err
+		}
+	}
+	for _, auditEntry := range m.audit {
+		if err := write(opAddAuditEntry, auditEntry); err != nil {
+			return nil, err
+		}
+	}
+	for _, sample := range m.health {
+		if err := write(opAddHealthSample, sample); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeJournalEntry marshals a journal entry as a single newline-terminated line
+func encodeJournalEntry(op string, data interface{}, at time.Time) ([]byte, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode journal data: %w", err)
+	}
+	line, err := json.Marshal(journalEntry{Op: op, At: at, Data: payload})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	return append(line, '\n'), nil
+}
+
+// logWrite appends a write to the journal before it is applied in memory.
+// It is a no-op when journaling is disabled; the caller must hold the write lock.
+func (m *MemoryStorage) logWrite(op string, data interface{}) error {
+	if m.journal == nil {
+		return nil
+	}
+	return m.journal.append(op, data)
+}
+
+// append writes one entry and fsyncs it so it survives a power loss
+func (j *journal) append(op string, data interface{}) error {
+	line, err := encodeJournalEntry(op, data, time.Now())
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(line); err != nil {
+		return fmt.Errorf("failed to append to journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	return nil
+}
+
+// replace atomically rewrites the journal with the contents of another
+// store and switches appends over to the new file
+func (j *journal) replace(contents *MemoryStorage) error {
+	data, err := contents.journalContents()
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(j.path, data); err != nil {
+		return fmt.Errorf("failed to rewrite journal: %w", err)
+	}
+
+	// The old file descriptor still points at the replaced file
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to reopen journal: %w", err)
+	}
+	j.file.Close()
+	j.file = file
+	return nil
+}
+
+// close closes the journal file
+func (j *journal) close() error {
+	return j.file.Close()
+}
@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"watered/internal/models"
+)
+
+func TestJournaledMemoryStorage_ReplaysOnStartup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watered.journal")
+
+	store, err := NewJournaledMemoryStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+
+	now := time.Now()
+	store.UpdatePlantState(&models.PlantState{Name: "Fern", LastWatered: &now, TimeoutHours: 48, WateredBy: "test@example.com"})
+	store.CreatePlant(&models.PlantState{Name: "Cactus", TimeoutHours: 336})
+	store.DeletePlant(2)
+	store.CreateUser(&models.User{Email: "old@example.com", Name: "Old"})
+	store.CreateUser(&models.User{Email: "test@example.com", Name: "Test User"})
+	store.DeleteUser("old@example.com")
+	store.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 48, AdminEmails: []string{"test@example.com"}})
+	store.CreateNotification(&models.Notification{UserEmail: "old@example.com", Channel: "email"})
+	store.ReassignNotifications("old@example.com", "test@example.com")
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 1, WateredAt: now, WateredBy: "old@example.com"})
+	store.ReassignWateringEvents("old@example.com", "test@example.com")
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 1, WateredAt: now, WateredBy: "test@example.com"})
+	store.DeleteWateringEvent(2)
+	store.SavePushSubscription(&models.PushSubscription{UserEmail: "test@example.com", Endpoint: "https://push.example.com/1"})
+	store.SavePushSubscription(&models.PushSubscription{UserEmail: "test@example.com", Endpoint: "https://push.example.com/2"})
+	store.DeletePushSubscription("https://push.example.com/1")
+	store.SaveAPIKey(&models.APIKey{ID: "revoked", Name: "Old key"})
+	store.SaveAPIKey(&models.APIKey{ID: "active", Name: "Home Assistant"})
+	store.DeleteAPIKey("revoked")
+	store.SaveDevice(&models.Device{ID: "hallway", Kind: models.DeviceKindButton})
+	store.SaveDevice(&models.Device{ID: "kitchen", Kind: models.DeviceKindSensor, Active: true})
+	store.DeleteDevice("hallway")
+	store.SaveShareLink(&models.ShareLink{ID: "revoked", Name: "Neighbour", PlantID: 1})
+	store.SaveShareLink(&models.ShareLink{ID: "active", Name: "Grandma", PlantID: 1, TokenHash: "hash-g"})
+	store.DeleteShareLink("revoked")
+	store.SaveInvite(&models.Invite{ID: "accepted", Name: "Alex", Role: "member"})
+	store.SaveInvite(&models.Invite{ID: "pending", Name: "Sitter", Role: "viewer"})
+	store.DeleteInvite("accepted")
+	store.SaveSession(&models.Session{ID: "revoked", Email: "test@example.com"})
+	store.SaveSession(&models.Session{ID: "active", Email: "test@example.com", Authenticated: true})
+	store.DeleteSessions([]string{"revoked"})
+	store.AddAuditEntry(&models.AuditEntry{Actor: "admin@example.com", Action: models.AuditConfigTimeout, Old: []byte("24"), New: []byte("48")})
+	store.SaveUserActivity([]*models.UserActivity{{Email: "test@example.com", UserAgent: "Firefox"}})
+	store.SaveUserActivity([]*models.UserActivity{{Email: "test@example.com", UserAgent: "Safari"}})
+	store.SaveUserActivity([]*models.UserActivity{{Email: "gone@example.com", UserAgent: "Chrome"}})
+	store.DeleteUserActivity("gone@example.com")
+	moisture := 37.0
+	store.AddSensorReading(&models.SensorReading{DeviceID: "kitchen", PlantID: 1, Moisture: &moisture, RecordedAt: now})
+	store.AddHealthSample(&models.HealthSample{CheckedAt: now.Add(-48 * time.Hour), Status: "healthy"})
+	store.AddHealthSample(&models.HealthSample{CheckedAt: now, Status: "degraded", Components: map[string]string{"storage": "degraded"}})
+	store.PruneHealthSamples(now.Add(-24 * time.Hour))
+	store.SaveCareTask(&models.CareTask{PlantID: 1, Type: models.CareTaskMist, IntervalHours: 48})
+	store.SaveCareTask(&models.CareTask{PlantID: 1, Type: models.CareTaskFertilize, IntervalHours: 672})
+	store.AddCareTaskEvent(&models.CareTaskEvent{TaskID: 1, PlantID: 1, DoneAt: now})
+	store.AddCareTaskEvent(&models.CareTaskEvent{TaskID: 2, PlantID: 1, DoneAt: now, DoneBy: "old@example.com"})
+	store.ReassignCareTaskEvents("old@example.com", "test@example.com")
+	store.DeleteCareTask(1)
+	store.Close()
+
+	reopened, err := NewJournaledMemoryStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to replay journal: %v", err)
+	}
+	defer reopened.Close()
+
+	plants, _ := reopened.ListPlants()
+	if len(plants) != 1 || plants[0].Name != "Fern" || plants[0].LastWatered == nil {
+		t.Errorf("Expected only the default plant after replay, got %+v", plants)
+	}
+	if user, _ := reopened.GetUser("old@example.com"); user != nil {
+		t.Errorf("Expected deleted user to stay deleted, got %+v", user)
+	}
+	if user, _ := reopened.GetUser("test@example.com"); user == nil || user.Name != "Test User" {
+		t.Errorf("Expected user to survive restart, got %+v", user)
+	}
+	if config, _ := reopened.GetAdminConfig(); config == nil || config.TimeoutHours != 48 {
+		t.Errorf("Expected config to survive restart, got %+v", config)
+	}
+	notifications, _ := reopened.ListNotifications(models.NotificationFilter{UserEmail: "test@example.com"})
+	if len(notifications) != 1 {
+		t.Errorf("Expected reassigned notification after replay, got %d", len(notifications))
+	}
+	if events, _ := reopened.ListWateringEvents(0); len(events) != 1 || events[0].WateredBy != "test@example.com" {
+		t.Errorf("Expected reassigned watering event after replay, got %+v", events)
+	}
+	if subscriptions, _ := reopened.ListPushSubscriptions(""); len(subscriptions) != 1 || subscriptions[0].Endpoint != "https://push.example.com/2" {
+		t.Errorf("Expected one push subscription after replay, got %+v", subscriptions)
+	}
+	if keys, _ := reopened.ListAPIKeys(); len(keys) != 1 || keys[0].ID != "active" {
+		t.Errorf("Expected one API key after replay, got %+v", keys)
+	}
+	if devices, _ := reopened.ListDevices(); len(devices) != 1 || devices[0].ID != "kitchen" || !devices[0].Active {
+		t.Errorf("Expected one device after replay, got %+v", devices)
+	}
+	if links, _ := reopened.ListShareLinks(); len(links) != 1 || links[0].ID != "active" || links[0].TokenHash != "hash-g" {
+		t.Errorf("Expected one share link after replay, got %+v", links)
+	}
+	if invites, _ := reopened.ListInvites(); len(invites) != 1 || invites[0].ID != "pending" || invites[0].Role != "viewer" {
+		t.Errorf("Expected one invite after replay, got %+v", invites)
+	}
+	if sessions, _ := reopened.ListSessions(); len(sessions) != 1 || sessions[0].ID != "active" || !sessions[0].Authenticated {
+		t.Errorf("Expected one session after replay, got %+v", sessions)
+	}
+	if entries, _ := reopened.ListAuditEntries(models.AuditFilter{}); len(entries) != 1 || entries[0].ID != 1 || string(entries[0].New) != "48" {
+		t.Errorf("Expected one audit entry after replay, got %+v", entries)
+	}
+	if activity, _ := reopened.ListUserActivity(); len(activity) != 1 || activity[0].UserAgent != "Safari" {
+		t.Errorf("Expected the latest user activity after replay, got %+v", activity)
+	}
+	if readings, _ := reopened.ListSensorReadings(models.SensorReadingFilter{}); len(readings) != 1 || *readings[0].Moisture != 37 {
+		t.Errorf("Expected the sensor reading after replay, got %+v", readings)
+	}
+	if samples, _ := reopened.ListHealthSamples(time.Time{}); len(samples) != 1 || samples[0].Components["storage"] != "degraded" {
+		t.Errorf("Expected only the unpruned health sample after replay, got %+v", samples)
+	}
+	if tasks, _ := reopened.ListCareTasks(1); len(tasks) != 1 || tasks[0].Type != models.CareTaskFertilize {
+		t.Errorf("Expected the fertilize task after replay, got %+v", tasks)
+	}
+	if events, _ := reopened.ListCareTaskEvents(1); len(events) != 0 {
+		t.Errorf("Expected the deleted task's history to stay deleted, got %+v", events)
+	}
+	if events, _ := reopened.ListCareTaskEvents(2); len(events) != 1 || events[0].DoneBy != "test@example.com" {
+		t.Errorf("Expected the fertilize task's history after replay, got %+v", events)
+	}
+}
+
+func TestJournaledMemoryStorage_CompactsOnStartup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watered.journal")
+
+	store, err := NewJournaledMemoryStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		store.UpdatePlantState(&models.PlantState{Name: "Fern", TimeoutHours: 24 + i})
+	}
+	store.Close()
+
+	reopened, err := NewJournaledMemoryStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to replay journal: %v", err)
+	}
+	reopened.Close()
+
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Errorf("Expected compacted journal with 1 entry, got %d", lines)
+	}
+	if plant, _ := reopened.GetPlantState(); plant == nil || plant.TimeoutHours != 33 {
+		t.Errorf("Expected latest plant state, got %+v", plant)
+	}
+}
+
+func TestJournaledMemoryStorage_DoesNotReusePlantIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watered.journal")
+
+	store, err := NewJournaledMemoryStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	store.UpdatePlantState(&models.PlantState{Name: "Default", TimeoutHours: 24})
+	store.CreatePlant(&models.PlantState{Name: "Cactus", TimeoutHours: 336})
+	store.AddWateringEvent(&models.PlantWateringEvent{PlantID: 2, WateredBy: "test@example.com"})
+	store.DeletePlant(2)
+	store.Close()
+
+	// The second reopen replays the compacted journal
+	compacted, err := NewJournaledMemoryStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to replay journal: %v", err)
+	}
+	compacted.Close()
+	store, err = NewJournaledMemoryStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to replay compacted journal: %v", err)
+	}
+	defer store.Close()
+
+	fern := &models.PlantState{Name: "Fern", TimeoutHours: 48}
+	store.CreatePlant(fern)
+	if fern.ID != 3 {
+		t.Errorf("Expected plant ID 3 after deleting plant 2, got %d", fern.ID)
+	}
+	if events, _ := store.ListWateringEvents(0); len(events) != 0 {
+		t.Errorf("Expected the deleted plant's waterings to be gone, got %v", events)
+	}
+}
+
+func TestJournaledMemoryStorage_TornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watered.journal")
+	journal := `{"op":"put_plant","at":"2024-01-01T00:00:00Z","data":{"id":1,"name":"Fern","timeout_hours":24}}
+{"op":"put_plant","at":"2024-01-01T00:01:00Z","data":{"id":1,"na`
+	if err := os.WriteFile(path, []byte(journal), 0600); err != nil {
+		t.Fatalf("Failed to write journal: %v", err)
+	}
+
+	store, err := NewJournaledMemoryStorage(path)
+	if err != nil {
+		t.Fatalf("Expected torn final entry to be discarded, got %v", err)
+	}
+	defer store.Close()
+
+	if plant, _ := store.GetPlantState(); plant == nil || plant.Name != "Fern" {
+		t.Errorf("Expected plant from the complete entry, got %+v", plant)
+	}
+}
+
+func TestJournaledMemoryStorage_CorruptEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watered.journal")
+	journal := "not json\n" + `{"op":"put_plant","at":"2024-01-01T00:00:00Z","data":{"id":1,"name":"Fern"}}` + "\n"
+	if err := os.WriteFile(path, []byte(journal), 0600); err != nil {
+		t.Fatalf("Failed to write journal: %v", err)
+	}
+
+	if _, err := NewJournaledMemoryStorage(path); err == nil {
+		t.Error("Expected error for corrupt journal entry")
+	}
+}
+
+func TestJournaledMemoryStorage_RefusesReset(t *testing.T) {
+	store, err := NewJournaledMemoryStorage(filepath.Join(t.TempDir(), "watered.journal"))
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	defer store.Close()
+
+	store.CreateUser(&models.User{Email: "a@example.com"})
+	if err := store.Reset(); err == nil {
+		t.Error("Expected resetting a journaled store to fail")
+	}
+	if user, _ := store.GetUser("a@example.com"); user == nil {
+		t.Error("Expected the data to survive a refused reset")
+	}
+}
@@ -0,0 +1,25 @@
+package migrations
+
+func init() {
+	register(Migration{Version: 1, Name: "multi_plant", Up: migrateMultiPlant})
+}
+
+// migrateMultiPlant moves the single "plant" object written before multi-plant
+// support into the "plants" list, giving it the default plant ID
+func migrateMultiPlant(doc Document) error {
+	legacy, ok := doc["plant"].(map[string]interface{})
+	delete(doc, "plant")
+	if !ok {
+		return nil
+	}
+
+	if plants, _ := doc["plants"].([]interface{}); len(plants) > 0 {
+		return nil
+	}
+
+	if id, _ := legacy["id"].(float64); id == 0 {
+		legacy["id"] = 1
+	}
+	doc["plants"] = []interface{}{legacy}
+	return nil
+}
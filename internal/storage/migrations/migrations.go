@@ -0,0 +1,143 @@
+// Package migrations applies versioned schema changes to the persisted data
+// document. Each migration lives in its own file named after its version
+// (e.g. 0001_multi_plant.go) and registers itself from init. Applied versions
+// are recorded in the document under VersionsKey so every migration runs once.
+package migrations
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// VersionsKey is the document key holding the applied-versions table
+const VersionsKey = "schema_migrations"
+
+// Document is the decoded data file that migrations operate on
+type Document map[string]interface{}
+
+// Migration is a single ordered schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(doc Document) error
+}
+
+// AppliedMigration is a row in the applied-versions table
+type AppliedMigration struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// Result describes the outcome of a migration run
+type Result struct {
+	DryRun  bool               `json:"dryRun"`
+	Applied []AppliedMigration `json:"applied"`
+	Pending []string           `json:"pending"`
+}
+
+var registry []Migration
+
+// register adds a migration to the registry; called from each migration file's init
+func register(m Migration) {
+	for _, existing := range registry {
+		if existing.Version == m.Version {
+			panic(fmt.Sprintf("migrations: duplicate version %d (%s and %s)", m.Version, existing.Name, m.Name))
+		}
+	}
+	registry = append(registry, m)
+	sort.Slice(registry, func(i, j int) bool { return registry[i].Version < registry[j].Version })
+}
+
+// All returns every registered migration in version order
+func All() []Migration {
+	all := make([]Migration, len(registry))
+	copy(all, registry)
+	return all
+}
+
+// Baseline returns applied-version rows for every known migration. New data
+// files are written in the latest format and start out fully migrated.
+func Baseline() []AppliedMigration {
+	now := time.Now()
+	applied := make([]AppliedMigration, 0, len(registry))
+	for _, m := range registry {
+		applied = append(applied, AppliedMigration{Version: m.Version, Name: m.Name, AppliedAt: now})
+	}
+	return applied
+}
+
+// Applied reads the applied-versions table from a document
+func Applied(doc Document) ([]AppliedMigration, error) {
+	raw, ok := doc[VersionsKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", VersionsKey, err)
+	}
+	var applied []AppliedMigration
+	if err := json.Unmarshal(data, &applied); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", VersionsKey, err)
+	}
+	return applied, nil
+}
+
+// Pending returns the labels of the registered migrations missing from
+// applied, in version order
+func Pending(applied []AppliedMigration) []string {
+	done := make(map[int]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+	pending := []string{}
+	for _, m := range registry {
+		if !done[m.Version] {
+			pending = append(pending, fmt.Sprintf("%04d_%s", m.Version, m.Name))
+		}
+	}
+	return pending
+}
+
+// Run applies all pending migrations to doc in version order. In dry-run
+// mode the document is left untouched and the pending migrations are only
+// reported.
+func Run(doc Document, dryRun bool) (*Result, error) {
+	applied, err := Applied(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(map[int]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+
+	result := &Result{DryRun: dryRun, Applied: []AppliedMigration{}, Pending: []string{}}
+	for _, m := range registry {
+		if done[m.Version] {
+			continue
+		}
+
+		label := fmt.Sprintf("%04d_%s", m.Version, m.Name)
+		if dryRun {
+			result.Pending = append(result.Pending, label)
+			continue
+		}
+
+		if err := m.Up(doc); err != nil {
+			return result, fmt.Errorf("migration %s failed: %w", label, err)
+		}
+
+		row := AppliedMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}
+		applied = append(applied, row)
+		doc[VersionsKey] = applied
+		result.Applied = append(result.Applied, row)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package migrations
+
+import (
+	"testing"
+)
+
+func TestRegistryIsOrdered(t *testing.T) {
+	all := All()
+	if len(all) == 0 {
+		t.Fatal("Expected at least one registered migration")
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].Version <= all[i-1].Version {
+			t.Errorf("Expected migrations in ascending order, got %d after %d", all[i].Version, all[i-1].Version)
+		}
+	}
+}
+
+func TestRun_AppliesPendingOnce(t *testing.T) {
+	doc := Document{"plant": map[string]interface{}{"name": "Old Plant", "timeout_hours": 12.0}}
+
+	result, err := Run(doc, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Applied) != len(All()) {
+		t.Errorf("Expected %d applied migrations, got %d", len(All()), len(result.Applied))
+	}
+
+	plants, ok := doc["plants"].([]interface{})
+	if !ok || len(plants) != 1 {
+		t.Fatalf("Expected legacy plant moved into plants, got %v", doc["plants"])
+	}
+	if plant := plants[0].(map[string]interface{}); plant["id"] != 1 || plant["name"] != "Old Plant" {
+		t.Errorf("Expected migrated plant with default ID, got %v", plant)
+	}
+	if _, exists := doc["plant"]; exists {
+		t.Error("Expected legacy plant key to be removed")
+	}
+
+	// Running again is a no-op
+	result, err = Run(doc, false)
+	if err != nil {
+		t.Fatalf("Expected no error on second run, got %v", err)
+	}
+	if len(result.Applied) != 0 {
+		t.Errorf("Expected no migrations on second run, got %d", len(result.Applied))
+	}
+}
+
+func TestRun_DryRunLeavesDocumentUntouched(t *testing.T) {
+	doc := Document{"plant": map[string]interface{}{"name": "Old Plant"}}
+
+	result, err := Run(doc, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Pending) == 0 || result.Pending[0] != "0001_multi_plant" {
+		t.Errorf("Expected 0001_multi_plant pending, got %v", result.Pending)
+	}
+	if len(result.Applied) != 0 {
+		t.Errorf("Expected nothing applied in dry run, got %v", result.Applied)
+	}
+	if _, exists := doc["plant"]; !exists {
+		t.Error("Expected dry run to leave the document unchanged")
+	}
+	if _, exists := doc[VersionsKey]; exists {
+		t.Error("Expected dry run not to record applied versions")
+	}
+}
+
+func TestRun_BaselineSkipsAll(t *testing.T) {
+	doc := Document{VersionsKey: Baseline()}
+
+	result, err := Run(doc, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Pending) != 0 {
+		t.Errorf("Expected no pending migrations after baseline, got %v", result.Pending)
+	}
+}
+
+func TestPending(t *testing.T) {
+	if pending := Pending(nil); len(pending) != len(All()) || pending[0] != "0001_multi_plant" {
+		t.Errorf("Expected every migration pending on an unversioned document, got %v", pending)
+	}
+	if pending := Pending(Baseline()); len(pending) != 0 {
+		t.Errorf("Expected no pending migrations after baseline, got %v", pending)
+	}
+}
@@ -1,79 +1,1275 @@
 package storage
 
 import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"watered/internal/config"
 	"watered/internal/models"
 )
 
 // Storage defines the interface for data persistence
 type Storage interface {
-	// Plant operations
+	// Default plant operations
 	GetPlantState() (*models.PlantState, error)
 	UpdatePlantState(state *models.PlantState) error
 
+	// Plant operations
+	ListPlants() ([]*models.PlantState, error)
+	GetPlant(id int) (*models.PlantState, error)
+	CreatePlant(plant *models.PlantState) error
+	UpdatePlant(plant *models.PlantState) error
+	// DeletePlant removes a plant together with its waterings, care tasks,
+	// share links, devices and sensor readings. Plant IDs are never reused,
+	// so nothing left behind can attach to a later plant.
+	DeletePlant(id int) error
+
 	// User operations
 	GetUser(email string) (*models.User, error)
 	CreateUser(user *models.User) error
+	DeleteUser(email string) error
 
 	// Admin operations
 	GetAdminConfig() (*models.AdminConfig, error)
 	UpdateAdminConfig(config *models.AdminConfig) error
 
+	// Notification operations
+	CreateNotification(notification *models.Notification) error
+	ListNotifications(filter models.NotificationFilter) ([]*models.Notification, error)
+	ReassignNotifications(fromEmail, toEmail string) (int, error)
+
+	// Watering history operations
+	AddWateringEvent(event *models.PlantWateringEvent) error
+	ListWateringEvents(plantID int) ([]*models.PlantWateringEvent, error)
+	DeleteWateringEvent(id int) error
+	ReassignWateringEvents(fromEmail, toEmail string) (int, error)
+
+	// Push subscription operations
+	SavePushSubscription(subscription *models.PushSubscription) error
+	ListPushSubscriptions(userEmail string) ([]*models.PushSubscription, error)
+	DeletePushSubscription(endpoint string) error
+
+	// API key operations
+	SaveAPIKey(key *models.APIKey) error
+	GetAPIKey(id string) (*models.APIKey, error)
+	ListAPIKeys() ([]*models.APIKey, error)
+	DeleteAPIKey(id string) error
+
+	// Device operations
+	SaveDevice(device *models.Device) error
+	GetDevice(id string) (*models.Device, error)
+	ListDevices() ([]*models.Device, error)
+	DeleteDevice(id string) error
+
+	// Share link operations
+	SaveShareLink(link *models.ShareLink) error
+	GetShareLink(id string) (*models.ShareLink, error)
+	ListShareLinks() ([]*models.ShareLink, error)
+	DeleteShareLink(id string) error
+
+	// Invite operations
+	SaveInvite(invite *models.Invite) error
+	GetInvite(id string) (*models.Invite, error)
+	ListInvites() ([]*models.Invite, error)
+	DeleteInvite(id string) error
+
+	// Session operations. Sessions are keyed by the hash of their cookie ID.
+	SaveSession(session *models.Session) error
+	GetSession(id string) (*models.Session, error)
+	ListSessions() ([]*models.Session, error)
+	DeleteSession(id string) error
+	// DeleteSessions removes several sessions in one write, for signing a
+	// user out everywhere or pruning expired sessions
+	DeleteSessions(ids []string) error
+
+	// User activity operations. Activity is saved in batches, one call per
+	// flush rather than per request.
+	SaveUserActivity(activity []*models.UserActivity) error
+	ListUserActivity() ([]*models.UserActivity, error)
+	DeleteUserActivity(email string) error
+
+	// Sensor reading operations. Only the latest MaxSensorReadingsPerDevice
+	// readings of each device are kept.
+	AddSensorReading(reading *models.SensorReading) error
+	ListSensorReadings(filter models.SensorReadingFilter) ([]*models.SensorReading, error)
+
+	// Care task operations. Deleting a task also deletes its history.
+	SaveCareTask(task *models.CareTask) error
+	GetCareTask(id int) (*models.CareTask, error)
+	ListCareTasks(plantID int) ([]*models.CareTask, error)
+	DeleteCareTask(id int) error
+	AddCareTaskEvent(event *models.CareTaskEvent) error
+	ListCareTaskEvents(taskID int) ([]*models.CareTaskEvent, error)
+	ReassignCareTaskEvents(fromEmail, toEmail string) (int, error)
+
+	// Audit log operations. Entries are never changed or removed.
+	AddAuditEntry(entry *models.AuditEntry) error
+	ListAuditEntries(filter models.AuditFilter) ([]*models.AuditEntry, error)
+
+	// Health history operations. Samples older than the retention window
+	// are removed with PruneHealthSamples.
+	AddHealthSample(sample *models.HealthSample) error
+	ListHealthSamples(since time.Time) ([]*models.HealthSample, error)
+	PruneHealthSamples(before time.Time) (int, error)
+
+	// Backup operations. Backups hold everything except sessions, and
+	// restoring one replaces everything except sessions.
+	Backup() (*Backup, error)
+	Restore(backup *Backup) error
+
 	// Close the storage connection
 	Close() error
 }
 
-// MemoryStorage provides in-memory storage for development
+// MemoryStorage provides in-memory storage for development. It is safe for
+// concurrent use; values are copied on the way in and out so callers never
+// share memory with the store. Writes are optionally recorded in an
+// append-only journal (see NewJournaledMemoryStorage).
 type MemoryStorage struct {
-	plant  *models.PlantState
-	users  map[string]*models.User
-	config *models.AdminConfig
+	mu     sync.RWMutex
+	plants map[int]*models.PlantState
+	// lastPlantID is the highest plant ID ever assigned, including deleted
+	// plants, so CreatePlant never hands out an ID twice
+	lastPlantID   int
+	users         map[string]*models.User
+	config        *models.AdminConfig
+	notifications []*models.Notification
+	waterings     []*models.PlantWateringEvent
+	subscriptions map[string]*models.PushSubscription
+	apiKeys       map[string]*models.APIKey
+	devices       map[string]*models.Device
+	shares        map[string]*models.ShareLink
+	invites       map[string]*models.Invite
+	sessions      map[string]*models.Session
+	activity      map[string]*models.UserActivity
+	readings      []*models.SensorReading
+	careTasks     map[int]*models.CareTask
+	careEvents    []*models.CareTaskEvent
+	audit         []*models.AuditEntry
+	health        []*models.HealthSample
+	journal       *journal
 }
 
+// MaxSensorReadingsPerDevice is how many readings are kept for each sensor,
+// a week of readings every five minutes
+const MaxSensorReadingsPerDevice = 7 * 24 * 12
+
 // NewMemoryStorage creates a new in-memory storage instance
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
-		users: make(map[string]*models.User),
+		plants:        make(map[int]*models.PlantState),
+		users:         make(map[string]*models.User),
+		subscriptions: make(map[string]*models.PushSubscription),
+		apiKeys:       make(map[string]*models.APIKey),
+		devices:       make(map[string]*models.Device),
+		shares:        make(map[string]*models.ShareLink),
+		invites:       make(map[string]*models.Invite),
+		sessions:      make(map[string]*models.Session),
+		activity:      make(map[string]*models.UserActivity),
+		careTasks:     make(map[int]*models.CareTask),
 	}
 }
 
-// GetPlantState returns the current plant state
+// Open creates the configured storage backend: a JSON data file when DataFile
+// is set, an append-only journal when JournalFile is set, or memory otherwise.
+// Pending schema migrations are applied when a data file is opened.
+func Open(cfg config.StorageConfig) (Storage, error) {
+	if cfg.DataFile != "" {
+		store, err := NewFileStorage(cfg.DataFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open data file: %w", err)
+		}
+		return store, nil
+	}
+
+	if cfg.JournalFile != "" {
+		store, err := NewJournaledMemoryStorage(cfg.JournalFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open journal: %w", err)
+		}
+		return store, nil
+	}
+
+	return NewMemoryStorage(), nil
+}
+
+// GetPlantState returns the default plant state
 func (m *MemoryStorage) GetPlantState() (*models.PlantState, error) {
-	return m.plant, nil
+	return m.GetPlant(models.DefaultPlantID)
 }
 
-// UpdatePlantState updates the plant state
+// UpdatePlantState creates or updates a plant, defaulting to the default plant when no ID is set
 func (m *MemoryStorage) UpdatePlantState(state *models.PlantState) error {
-	m.plant = state
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stateCopy := copyPlantState(state)
+	if stateCopy.ID == 0 {
+		stateCopy.ID = models.DefaultPlantID
+	}
+	if err := m.logWrite(opPutPlant, stateCopy); err != nil {
+		return err
+	}
+	m.putPlant(stateCopy)
+	return nil
+}
+
+// ListPlants returns all plants ordered by ID
+func (m *MemoryStorage) ListPlants() ([]*models.PlantState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	plants := make([]*models.PlantState, 0, len(m.plants))
+	for _, plant := range m.plants {
+		plants = append(plants, copyPlantState(plant))
+	}
+	sort.Slice(plants, func(i, j int) bool { return plants[i].ID < plants[j].ID })
+	return plants, nil
+}
+
+// GetPlant retrieves a plant by ID, returning nil if it does not exist
+func (m *MemoryStorage) GetPlant(id int) (*models.PlantState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return copyPlantState(m.plants[id]), nil
+}
+
+// CreatePlant stores a new plant, assigning it an ID no plant has had before
+func (m *MemoryStorage) CreatePlant(plant *models.PlantState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	nextID := m.lastPlantID + 1
+	plantCopy := copyPlantState(plant)
+	plantCopy.ID = nextID
+	if err := m.logWrite(opPutPlant, plantCopy); err != nil {
+		return err
+	}
+	plant.ID = nextID
+	m.putPlant(plantCopy)
+	return nil
+}
+
+// putPlant stores a plant and advances the plant ID counter past it; the
+// caller must hold the write lock
+func (m *MemoryStorage) putPlant(plant *models.PlantState) {
+	m.plants[plant.ID] = plant
+	m.lastPlantID = max(m.lastPlantID, plant.ID)
+}
+
+// UpdatePlant updates an existing plant
+func (m *MemoryStorage) UpdatePlant(plant *models.PlantState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.plants[plant.ID]; !exists {
+		return fmt.Errorf("plant %d not found", plant.ID)
+	}
+	plantCopy := copyPlantState(plant)
+	if err := m.logWrite(opPutPlant, plantCopy); err != nil {
+		return err
+	}
+	m.plants[plant.ID] = plantCopy
 	return nil
 }
 
+// DeletePlant removes a plant by ID along with every record keyed by it
+func (m *MemoryStorage) DeletePlant(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opDeletePlant, id); err != nil {
+		return err
+	}
+	m.deletePlant(id)
+	return nil
+}
+
+// deletePlant removes a plant and its waterings, care tasks and their
+// history, share links, devices and sensor readings; the caller must hold
+// the write lock
+func (m *MemoryStorage) deletePlant(id int) {
+	delete(m.plants, id)
+
+	m.waterings = slices.DeleteFunc(m.waterings, func(event *models.PlantWateringEvent) bool {
+		return event.PlantID == id
+	})
+	m.readings = slices.DeleteFunc(m.readings, func(reading *models.SensorReading) bool {
+		return reading.PlantID == id
+	})
+	for taskID, task := range m.careTasks {
+		if task.PlantID == id {
+			m.deleteCareTask(taskID)
+		}
+	}
+	for linkID, link := range m.shares {
+		if link.PlantID == id {
+			delete(m.shares, linkID)
+		}
+	}
+	for deviceID, device := range m.devices {
+		if device.PlantID == id {
+			delete(m.devices, deviceID)
+		}
+	}
+}
+
 // GetUser retrieves a user by email
 func (m *MemoryStorage) GetUser(email string) (*models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	user, exists := m.users[email]
 	if !exists {
 		return nil, nil
 	}
-	return user, nil
+	userCopy := *user
+	return &userCopy, nil
 }
 
 // CreateUser creates a new user
 func (m *MemoryStorage) CreateUser(user *models.User) error {
-	m.users[user.Email] = user
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	userCopy := *user
+	if err := m.logWrite(opPutUser, &userCopy); err != nil {
+		return err
+	}
+	m.users[user.Email] = &userCopy
+	return nil
+}
+
+// DeleteUser removes a user by email
+func (m *MemoryStorage) DeleteUser(email string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opDeleteUser, email); err != nil {
+		return err
+	}
+	delete(m.users, email)
 	return nil
 }
 
 // GetAdminConfig returns the admin configuration
 func (m *MemoryStorage) GetAdminConfig() (*models.AdminConfig, error) {
-	return m.config, nil
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return copyAdminConfig(m.config), nil
 }
 
 // UpdateAdminConfig updates the admin configuration
 func (m *MemoryStorage) UpdateAdminConfig(config *models.AdminConfig) error {
-	m.config = config
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	configCopy := copyAdminConfig(config)
+	if err := m.logWrite(opPutConfig, configCopy); err != nil {
+		return err
+	}
+	m.config = configCopy
+	return nil
+}
+
+// CreateNotification records a notification, assigning it the next ID
+func (m *MemoryStorage) CreateNotification(notification *models.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	notificationCopy := *notification
+	notificationCopy.ID = len(m.notifications) + 1
+	if err := m.logWrite(opAddNotification, &notificationCopy); err != nil {
+		return err
+	}
+	notification.ID = notificationCopy.ID
+	m.notifications = append(m.notifications, &notificationCopy)
 	return nil
 }
 
-// Close closes the storage connection (no-op for memory storage)
+// ListNotifications returns notifications matching the filter, newest first
+func (m *MemoryStorage) ListNotifications(filter models.NotificationFilter) ([]*models.Notification, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := []*models.Notification{}
+	for i := len(m.notifications) - 1; i >= 0; i-- {
+		if !filter.Matches(m.notifications[i]) {
+			continue
+		}
+		notificationCopy := *m.notifications[i]
+		result = append(result, &notificationCopy)
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// ReassignNotifications moves all notifications from one user to another,
+// returning how many were moved
+func (m *MemoryStorage) ReassignNotifications(fromEmail, toEmail string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opReassignNotifications, reassignment{From: fromEmail, To: toEmail}); err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, notification := range m.notifications {
+		if notification.UserEmail == fromEmail {
+			notification.UserEmail = toEmail
+			count++
+		}
+	}
+	return count, nil
+}
+
+// AddWateringEvent records a watering, assigning it the next ID
+func (m *MemoryStorage) AddWateringEvent(event *models.PlantWateringEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	eventCopy := *event
+	eventCopy.ID = 1
+	if len(m.waterings) > 0 {
+		eventCopy.ID = m.waterings[len(m.waterings)-1].ID + 1
+	}
+	if err := m.logWrite(opAddWateringEvent, &eventCopy); err != nil {
+		return err
+	}
+	event.ID = eventCopy.ID
+	m.waterings = append(m.waterings, &eventCopy)
+	return nil
+}
+
+// ListWateringEvents returns the waterings of a plant, or of all plants when
+// plantID is 0, oldest first
+func (m *MemoryStorage) ListWateringEvents(plantID int) ([]*models.PlantWateringEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := []*models.PlantWateringEvent{}
+	for _, event := range m.waterings {
+		if plantID != 0 && event.PlantID != plantID {
+			continue
+		}
+		eventCopy := *event
+		result = append(result, &eventCopy)
+	}
+	// Waterings entered after the fact are stored out of order
+	sort.SliceStable(result, func(i, j int) bool { return result[i].WateredAt.Before(result[j].WateredAt) })
+	return result, nil
+}
+
+// DeleteWateringEvent removes a watering by ID, such as one recorded by
+// mistake
+func (m *MemoryStorage) DeleteWateringEvent(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opDeleteWateringEvent, id); err != nil {
+		return err
+	}
+	m.deleteWateringEvent(id)
+	return nil
+}
+
+// deleteWateringEvent removes a watering by ID; the caller must hold the
+// write lock
+func (m *MemoryStorage) deleteWateringEvent(id int) {
+	for i, event := range m.waterings {
+		if event.ID == id {
+			m.waterings = append(m.waterings[:i], m.waterings[i+1:]...)
+			return
+		}
+	}
+}
+
+// ReassignWateringEvents moves all waterings from one user to another,
+// returning how many were moved
+func (m *MemoryStorage) ReassignWateringEvents(fromEmail, toEmail string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opReassignWateringEvents, reassignment{From: fromEmail, To: toEmail}); err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, event := range m.waterings {
+		if event.WateredBy == fromEmail {
+			event.WateredBy = toEmail
+			count++
+		}
+	}
+	return count, nil
+}
+
+// SavePushSubscription creates or replaces a push subscription, keyed by endpoint
+func (m *MemoryStorage) SavePushSubscription(subscription *models.PushSubscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subscriptionCopy := *subscription
+	if err := m.logWrite(opPutPushSubscription, &subscriptionCopy); err != nil {
+		return err
+	}
+	m.subscriptions[subscription.Endpoint] = &subscriptionCopy
+	return nil
+}
+
+// ListPushSubscriptions returns push subscriptions for a user, or all
+// subscriptions when userEmail is empty, oldest first
+func (m *MemoryStorage) ListPushSubscriptions(userEmail string) ([]*models.PushSubscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := []*models.PushSubscription{}
+	for _, subscription := range m.subscriptions {
+		if userEmail != "" && subscription.UserEmail != userEmail {
+			continue
+		}
+		subscriptionCopy := *subscription
+		result = append(result, &subscriptionCopy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].Endpoint < result[j].Endpoint
+	})
+	return result, nil
+}
+
+// DeletePushSubscription removes a push subscription by endpoint
+func (m *MemoryStorage) DeletePushSubscription(endpoint string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opDeletePushSubscription, endpoint); err != nil {
+		return err
+	}
+	delete(m.subscriptions, endpoint)
+	return nil
+}
+
+// SaveAPIKey creates or replaces an API key, keyed by ID
+func (m *MemoryStorage) SaveAPIKey(key *models.APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keyCopy := *key
+	if err := m.logWrite(opPutAPIKey, &keyCopy); err != nil {
+		return err
+	}
+	m.apiKeys[key.ID] = &keyCopy
+	return nil
+}
+
+// GetAPIKey retrieves an API key by ID, returning nil if it does not exist
+func (m *MemoryStorage) GetAPIKey(id string) (*models.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	key, exists := m.apiKeys[id]
+	if !exists {
+		return nil, nil
+	}
+	keyCopy := *key
+	return &keyCopy, nil
+}
+
+// ListAPIKeys returns all API keys, oldest first
+func (m *MemoryStorage) ListAPIKeys() ([]*models.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*models.APIKey, 0, len(m.apiKeys))
+	for _, key := range m.apiKeys {
+		keyCopy := *key
+		result = append(result, &keyCopy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// DeleteAPIKey removes an API key by ID
+func (m *MemoryStorage) DeleteAPIKey(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opDeleteAPIKey, id); err != nil {
+		return err
+	}
+	delete(m.apiKeys, id)
+	return nil
+}
+
+// SaveDevice creates or replaces a device, keyed by ID
+func (m *MemoryStorage) SaveDevice(device *models.Device) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deviceCopy := copyDevice(device)
+	if err := m.logWrite(opPutDevice, deviceCopy); err != nil {
+		return err
+	}
+	m.devices[device.ID] = deviceCopy
+	return nil
+}
+
+// GetDevice retrieves a device by ID, returning nil if it does not exist
+func (m *MemoryStorage) GetDevice(id string) (*models.Device, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	device, exists := m.devices[id]
+	if !exists {
+		return nil, nil
+	}
+	return copyDevice(device), nil
+}
+
+// ListDevices returns all devices, sorted by ID
+func (m *MemoryStorage) ListDevices() ([]*models.Device, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*models.Device, 0, len(m.devices))
+	for _, device := range m.devices {
+		result = append(result, copyDevice(device))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// DeleteDevice removes a device by ID
+func (m *MemoryStorage) DeleteDevice(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opDeleteDevice, id); err != nil {
+		return err
+	}
+	delete(m.devices, id)
+	return nil
+}
+
+// SaveShareLink creates or replaces a share link, keyed by ID
+func (m *MemoryStorage) SaveShareLink(link *models.ShareLink) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	linkCopy := *link
+	if err := m.logWrite(opPutShareLink, &linkCopy); err != nil {
+		return err
+	}
+	m.shares[link.ID] = &linkCopy
+	return nil
+}
+
+// GetShareLink retrieves a share link by ID, returning nil if it does not exist
+func (m *MemoryStorage) GetShareLink(id string) (*models.ShareLink, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	link, exists := m.shares[id]
+	if !exists {
+		return nil, nil
+	}
+	linkCopy := *link
+	return &linkCopy, nil
+}
+
+// ListShareLinks returns all share links, oldest first
+func (m *MemoryStorage) ListShareLinks() ([]*models.ShareLink, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*models.ShareLink, 0, len(m.shares))
+	for _, link := range m.shares {
+		linkCopy := *link
+		result = append(result, &linkCopy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// DeleteShareLink removes a share link by ID
+func (m *MemoryStorage) DeleteShareLink(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opDeleteShareLink, id); err != nil {
+		return err
+	}
+	delete(m.shares, id)
+	return nil
+}
+
+// SaveInvite creates or replaces an invite, keyed by ID
+func (m *MemoryStorage) SaveInvite(invite *models.Invite) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inviteCopy := *invite
+	if err := m.logWrite(opPutInvite, &inviteCopy); err != nil {
+		return err
+	}
+	m.invites[invite.ID] = &inviteCopy
+	return nil
+}
+
+// GetInvite retrieves an invite by ID, returning nil if it does not exist
+func (m *MemoryStorage) GetInvite(id string) (*models.Invite, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	invite, exists := m.invites[id]
+	if !exists {
+		return nil, nil
+	}
+	inviteCopy := *invite
+	return &inviteCopy, nil
+}
+
+// ListInvites returns all invites, oldest first
+func (m *MemoryStorage) ListInvites() ([]*models.Invite, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*models.Invite, 0, len(m.invites))
+	for _, invite := range m.invites {
+		inviteCopy := *invite
+		result = append(result, &inviteCopy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// DeleteInvite removes an invite by ID
+func (m *MemoryStorage) DeleteInvite(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opDeleteInvite, id); err != nil {
+		return err
+	}
+	delete(m.invites, id)
+	return nil
+}
+
+// SaveSession creates or replaces a session, keyed by ID
+func (m *MemoryStorage) SaveSession(session *models.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sessionCopy := *session
+	if err := m.logWrite(opPutSession, &sessionCopy); err != nil {
+		return err
+	}
+	m.sessions[session.ID] = &sessionCopy
+	return nil
+}
+
+// GetSession retrieves a session by ID, returning nil if it does not exist
+func (m *MemoryStorage) GetSession(id string) (*models.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	session, exists := m.sessions[id]
+	if !exists {
+		return nil, nil
+	}
+	sessionCopy := *session
+	return &sessionCopy, nil
+}
+
+// ListSessions returns all sessions, oldest first
+func (m *MemoryStorage) ListSessions() ([]*models.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*models.Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessionCopy := *session
+		result = append(result, &sessionCopy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// DeleteSession removes a session by ID
+func (m *MemoryStorage) DeleteSession(id string) error {
+	return m.DeleteSessions([]string{id})
+}
+
+// DeleteSessions removes sessions by ID, ignoring IDs that do not exist
+func (m *MemoryStorage) DeleteSessions(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opDeleteSessions, ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		delete(m.sessions, id)
+	}
+	return nil
+}
+
+// SaveUserActivity creates or replaces activity records, keyed by email
+func (m *MemoryStorage) SaveUserActivity(activity []*models.UserActivity) error {
+	if len(activity) == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	activityCopy := make([]*models.UserActivity, len(activity))
+	for i, record := range activity {
+		recordCopy := *record
+		activityCopy[i] = &recordCopy
+	}
+	if err := m.logWrite(opPutUserActivity, activityCopy); err != nil {
+		return err
+	}
+	for _, record := range activityCopy {
+		m.activity[record.Email] = record
+	}
+	return nil
+}
+
+// ListUserActivity returns every user's activity, ordered by email
+func (m *MemoryStorage) ListUserActivity() ([]*models.UserActivity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*models.UserActivity, 0, len(m.activity))
+	for _, record := range m.activity {
+		recordCopy := *record
+		result = append(result, &recordCopy)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Email < result[j].Email })
+	return result, nil
+}
+
+// DeleteUserActivity removes a user's activity record
+func (m *MemoryStorage) DeleteUserActivity(email string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opDeleteUserActivity, email); err != nil {
+		return err
+	}
+	delete(m.activity, email)
+	return nil
+}
+
+// AddSensorReading records a sensor reading, assigning it the next ID and
+// dropping the device's oldest reading once it has too many
+func (m *MemoryStorage) AddSensorReading(reading *models.SensorReading) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	readingCopy := copySensorReading(reading)
+	readingCopy.ID = 1
+	if len(m.readings) > 0 {
+		readingCopy.ID = m.readings[len(m.readings)-1].ID + 1
+	}
+	if err := m.logWrite(opAddSensorReading, readingCopy); err != nil {
+		return err
+	}
+	reading.ID = readingCopy.ID
+	m.appendSensorReading(readingCopy)
+	return nil
+}
+
+// appendSensorReading stores a reading and enforces the per-device limit.
+// The caller must hold the write lock.
+func (m *MemoryStorage) appendSensorReading(reading *models.SensorReading) {
+	m.readings = append(m.readings, reading)
+
+	count, oldest := 0, -1
+	for i, stored := range m.readings {
+		if stored.DeviceID == reading.DeviceID {
+			if oldest < 0 {
+				oldest = i
+			}
+			count++
+		}
+	}
+	if count > MaxSensorReadingsPerDevice {
+		m.readings = append(m.readings[:oldest], m.readings[oldest+1:]...)
+	}
+}
+
+// ListSensorReadings returns the readings matching the filter, newest first
+func (m *MemoryStorage) ListSensorReadings(filter models.SensorReadingFilter) ([]*models.SensorReading, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := []*models.SensorReading{}
+	for _, reading := range m.readings {
+		if filter.Matches(reading) {
+			result = append(result, copySensorReading(reading))
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].RecordedAt.Equal(result[j].RecordedAt) {
+			return result[i].RecordedAt.After(result[j].RecordedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+// SaveCareTask creates or replaces a care task. A task without an ID is
+// assigned the next one.
+func (m *MemoryStorage) SaveCareTask(task *models.CareTask) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	taskCopy := copyCareTask(task)
+	if taskCopy.ID == 0 {
+		for id := range m.careTasks {
+			taskCopy.ID = max(taskCopy.ID, id)
+		}
+		taskCopy.ID++
+	}
+	if err := m.logWrite(opPutCareTask, taskCopy); err != nil {
+		return err
+	}
+	task.ID = taskCopy.ID
+	m.careTasks[taskCopy.ID] = taskCopy
+	return nil
+}
+
+// GetCareTask retrieves a care task by ID, returning nil if it does not exist
+func (m *MemoryStorage) GetCareTask(id int) (*models.CareTask, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	task, exists := m.careTasks[id]
+	if !exists {
+		return nil, nil
+	}
+	return copyCareTask(task), nil
+}
+
+// ListCareTasks returns a plant's care tasks, or every plant's when plantID
+// is 0, sorted by ID
+func (m *MemoryStorage) ListCareTasks(plantID int) ([]*models.CareTask, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := []*models.CareTask{}
+	for _, task := range m.careTasks {
+		if plantID == 0 || task.PlantID == plantID {
+			result = append(result, copyCareTask(task))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// DeleteCareTask removes a care task and its history
+func (m *MemoryStorage) DeleteCareTask(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opDeleteCareTask, id); err != nil {
+		return err
+	}
+	m.deleteCareTask(id)
+	return nil
+}
+
+// deleteCareTask removes a care task and its history; the caller must hold
+// the write lock
+func (m *MemoryStorage) deleteCareTask(id int) {
+	delete(m.careTasks, id)
+	kept := m.careEvents[:0]
+	for _, event := range m.careEvents {
+		if event.TaskID != id {
+			kept = append(kept, event)
+		}
+	}
+	m.careEvents = kept
+}
+
+// AddCareTaskEvent records a care task completion, assigning it the next ID
+func (m *MemoryStorage) AddCareTaskEvent(event *models.CareTaskEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	eventCopy := *event
+	eventCopy.ID = 1
+	if len(m.careEvents) > 0 {
+		eventCopy.ID = m.careEvents[len(m.careEvents)-1].ID + 1
+	}
+	if err := m.logWrite(opAddCareTaskEvent, &eventCopy); err != nil {
+		return err
+	}
+	event.ID = eventCopy.ID
+	m.careEvents = append(m.careEvents, &eventCopy)
+	return nil
+}
+
+// ListCareTaskEvents returns a care task's completions, oldest first
+func (m *MemoryStorage) ListCareTaskEvents(taskID int) ([]*models.CareTaskEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := []*models.CareTaskEvent{}
+	for _, event := range m.careEvents {
+		if event.TaskID == taskID {
+			eventCopy := *event
+			result = append(result, &eventCopy)
+		}
+	}
+	return result, nil
+}
+
+// ReassignCareTaskEvents moves care task completions from one user to
+// another, returning how many were moved. Tasks' LastDoneBy is left to the
+// caller.
+func (m *MemoryStorage) ReassignCareTaskEvents(fromEmail, toEmail string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.logWrite(opReassignCareTaskEvents, reassignment{From: fromEmail, To: toEmail}); err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, event := range m.careEvents {
+		if event.DoneBy == fromEmail {
+			event.DoneBy = toEmail
+			count++
+		}
+	}
+	return count, nil
+}
+
+// AddAuditEntry records an audit log entry, assigning it the next ID
+func (m *MemoryStorage) AddAuditEntry(entry *models.AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entryCopy := copyAuditEntry(entry)
+	entryCopy.ID = len(m.audit) + 1
+	if err := m.logWrite(opAddAuditEntry, entryCopy); err != nil {
+		return err
+	}
+	entry.ID = entryCopy.ID
+	m.audit = append(m.audit, entryCopy)
+	return nil
+}
+
+// ListAuditEntries returns audit log entries matching the filter, newest
+// first
+func (m *MemoryStorage) ListAuditEntries(filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := []*models.AuditEntry{}
+	for i := len(m.audit) - 1; i >= 0; i-- {
+		if filter.Matches(m.audit[i]) {
+			result = append(result, copyAuditEntry(m.audit[i]))
+		}
+	}
+	return result, nil
+}
+
+// AddHealthSample records a health check result, assigning it the next ID
+func (m *MemoryStorage) AddHealthSample(sample *models.HealthSample) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sampleCopy := copyHealthSample(sample)
+	sampleCopy.ID = 1
+	if len(m.health) > 0 {
+		sampleCopy.ID = m.health[len(m.health)-1].ID + 1
+	}
+	if err := m.logWrite(opAddHealthSample, sampleCopy); err != nil {
+		return err
+	}
+	sample.ID = sampleCopy.ID
+	m.health = append(m.health, sampleCopy)
+	return nil
+}
+
+// ListHealthSamples returns the health samples checked at or after since,
+// oldest first
+func (m *MemoryStorage) ListHealthSamples(since time.Time) ([]*models.HealthSample, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := []*models.HealthSample{}
+	for _, sample := range m.health {
+		if !sample.CheckedAt.Before(since) {
+			result = append(result, copyHealthSample(sample))
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CheckedAt.Before(result[j].CheckedAt) })
+	return result, nil
+}
+
+// PruneHealthSamples removes the health samples checked before the cutoff
+// and returns how many were removed
+func (m *MemoryStorage) PruneHealthSamples(before time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stale := 0
+	for _, sample := range m.health {
+		if sample.CheckedAt.Before(before) {
+			stale++
+		}
+	}
+	if stale == 0 {
+		return 0, nil
+	}
+	if err := m.logWrite(opPruneHealthSamples, before); err != nil {
+		return 0, err
+	}
+	m.pruneHealthSamples(before)
+	return stale, nil
+}
+
+// pruneHealthSamples drops samples checked before the cutoff. The caller
+// must hold the write lock.
+func (m *MemoryStorage) pruneHealthSamples(before time.Time) {
+	kept := m.health[:0]
+	for _, sample := range m.health {
+		if !sample.CheckedAt.Before(before) {
+			kept = append(kept, sample)
+		}
+	}
+	m.health = kept
+}
+
+// Close closes the journal file, if any
 func (m *MemoryStorage) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.journal == nil {
+		return nil
+	}
+	err := m.journal.close()
+	m.journal = nil
+	return err
+}
+
+// Reset empties the store. It is meant for the demo sandbox and refuses to
+// wipe a journaled store, whose journal would keep the old data.
+func (m *MemoryStorage) Reset() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.journal != nil {
+		return fmt.Errorf("cannot reset a journaled store")
+	}
+	m.plants = make(map[int]*models.PlantState)
+	m.lastPlantID = 0
+	m.users = make(map[string]*models.User)
+	m.config = nil
+	m.notifications = nil
+	m.waterings = nil
+	m.subscriptions = make(map[string]*models.PushSubscription)
+	m.apiKeys = make(map[string]*models.APIKey)
+	m.devices = make(map[string]*models.Device)
+	m.shares = make(map[string]*models.ShareLink)
+	m.invites = make(map[string]*models.Invite)
+	m.sessions = make(map[string]*models.Session)
+	m.activity = make(map[string]*models.UserActivity)
+	m.readings = nil
+	m.careTasks = make(map[int]*models.CareTask)
+	m.careEvents = nil
+	m.audit = nil
+	m.health = nil
 	return nil
 }
+
+// copyDevice returns a deep copy of a device
+func copyDevice(device *models.Device) *models.Device {
+	deviceCopy := *device
+	if device.LastSeen != nil {
+		lastSeen := *device.LastSeen
+		deviceCopy.LastSeen = &lastSeen
+	}
+	return &deviceCopy
+}
+
+// copyCareTask returns a deep copy of a care task
+func copyCareTask(task *models.CareTask) *models.CareTask {
+	taskCopy := *task
+	if task.LastDone != nil {
+		lastDone := *task.LastDone
+		taskCopy.LastDone = &lastDone
+	}
+	return &taskCopy
+}
+
+// copyAuditEntry returns a deep copy of an audit log entry
+func copyAuditEntry(entry *models.AuditEntry) *models.AuditEntry {
+	entryCopy := *entry
+	entryCopy.Old = append(json.RawMessage(nil), entry.Old...)
+	entryCopy.New = append(json.RawMessage(nil), entry.New...)
+	return &entryCopy
+}
+
+// copyHealthSample returns a deep copy of a health sample
+func copyHealthSample(sample *models.HealthSample) *models.HealthSample {
+	sampleCopy := *sample
+	sampleCopy.Components = make(map[string]string, len(sample.Components))
+	for name, status := range sample.Components {
+		sampleCopy.Components[name] = status
+	}
+	return &sampleCopy
+}
+
+// copySensorReading returns a deep copy of a sensor reading
+func copySensorReading(reading *models.SensorReading) *models.SensorReading {
+	readingCopy := *reading
+	if reading.Moisture != nil {
+		moisture := *reading.Moisture
+		readingCopy.Moisture = &moisture
+	}
+	if reading.Temperature != nil {
+		temperature := *reading.Temperature
+		readingCopy.Temperature = &temperature
+	}
+	return &readingCopy
+}
+
+// copyPlantState returns a deep copy of a plant state
+func copyPlantState(state *models.PlantState) *models.PlantState {
+	if state == nil {
+		return nil
+	}
+	stateCopy := *state
+	if state.LastWatered != nil {
+		lastWatered := *state.LastWatered
+		stateCopy.LastWatered = &lastWatered
+	}
+	if state.Metadata != nil {
+		stateCopy.Metadata = make(map[string]models.MetadataValue, len(state.Metadata))
+		for key, value := range state.Metadata {
+			stateCopy.Metadata[key] = value
+		}
+	}
+	if state.Vacation != nil {
+		vacation := *state.Vacation
+		stateCopy.Vacation = &vacation
+	}
+	if state.Weather != nil {
+		weather := *state.Weather
+		stateCopy.Weather = &weather
+	}
+	return &stateCopy
+}
+
+// copyAdminConfig returns a deep copy of an admin configuration
+func copyAdminConfig(config *models.AdminConfig) *models.AdminConfig {
+	if config == nil {
+		return nil
+	}
+	configCopy := *config
+	configCopy.AllowedEmails = copyStrings(config.AllowedEmails)
+	configCopy.AdminEmails = copyStrings(config.AdminEmails)
+	configCopy.ViewerEmails = copyStrings(config.ViewerEmails)
+	if config.DemoUsers != nil {
+		configCopy.DemoUsers = append([]models.DemoUser{}, config.DemoUsers...)
+	}
+	if config.Features != nil {
+		configCopy.Features = make(map[string]bool, len(config.Features))
+		for name, enabled := range config.Features {
+			configCopy.Features[name] = enabled
+		}
+	}
+	return &configCopy
+}
+
+// copyStrings copies a string slice, preserving nil
+func copyStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	result := make([]string, len(values))
+	copy(result, values)
+	return result
+}
@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -51,6 +53,94 @@ func TestMemoryStorage_PlantOperations(t *testing.T) {
 	}
 }
 
+func TestMemoryStorage_MultiplePlants(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	storage.UpdatePlantState(&models.PlantState{ID: 1, Name: "Default", TimeoutHours: 24})
+
+	fern := &models.PlantState{Name: "Fern", TimeoutHours: 48}
+	if err := storage.CreatePlant(fern); err != nil {
+		t.Fatalf("Expected no error creating plant, got %v", err)
+	}
+	if fern.ID != 2 {
+		t.Errorf("Expected new plant ID 2, got %d", fern.ID)
+	}
+
+	plants, err := storage.ListPlants()
+	if err != nil {
+		t.Fatalf("Expected no error listing plants, got %v", err)
+	}
+	if len(plants) != 2 || plants[0].ID != 1 || plants[1].ID != 2 {
+		t.Errorf("Expected plants 1 and 2 in order, got %+v", plants)
+	}
+
+	fern.Name = "Boston Fern"
+	if err := storage.UpdatePlant(fern); err != nil {
+		t.Errorf("Expected no error updating plant, got %v", err)
+	}
+	if got, _ := storage.GetPlant(2); got == nil || got.Name != "Boston Fern" {
+		t.Errorf("Expected updated plant, got %+v", got)
+	}
+
+	if err := storage.UpdatePlant(&models.PlantState{ID: 99, Name: "Ghost"}); err == nil {
+		t.Error("Expected error updating missing plant")
+	}
+
+	if err := storage.DeletePlant(2); err != nil {
+		t.Errorf("Expected no error deleting plant, got %v", err)
+	}
+	if got, _ := storage.GetPlant(2); got != nil {
+		t.Errorf("Expected plant to be deleted, got %+v", got)
+	}
+}
+
+func TestMemoryStorage_DeletePlantRemovesItsRecords(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	storage.UpdatePlantState(&models.PlantState{ID: 1, Name: "Default", TimeoutHours: 24})
+	fern := &models.PlantState{Name: "Fern", TimeoutHours: 48}
+	storage.CreatePlant(fern)
+	for _, plantID := range []int{1, fern.ID} {
+		storage.AddWateringEvent(&models.PlantWateringEvent{PlantID: plantID, WateredBy: "a@example.com"})
+		storage.AddSensorReading(&models.SensorReading{DeviceID: fmt.Sprintf("sensor-%d", plantID), PlantID: plantID})
+		storage.SaveDevice(&models.Device{ID: fmt.Sprintf("sensor-%d", plantID), PlantID: plantID})
+		storage.SaveShareLink(&models.ShareLink{ID: fmt.Sprintf("share-%d", plantID), PlantID: plantID})
+		task := &models.CareTask{PlantID: plantID, Type: models.CareTaskFertilize}
+		storage.SaveCareTask(task)
+		storage.AddCareTaskEvent(&models.CareTaskEvent{TaskID: task.ID, PlantID: plantID})
+	}
+
+	if err := storage.DeletePlant(fern.ID); err != nil {
+		t.Fatalf("Failed to delete plant: %v", err)
+	}
+
+	events, _ := storage.ListWateringEvents(0)
+	readings, _ := storage.ListSensorReadings(models.SensorReadingFilter{})
+	devices, _ := storage.ListDevices()
+	links, _ := storage.ListShareLinks()
+	tasks, _ := storage.ListCareTasks(0)
+	if len(events) != 1 || len(readings) != 1 || len(devices) != 1 || len(links) != 1 || len(tasks) != 1 {
+		t.Fatalf("Expected only the default plant's records, got events %v, readings %v, devices %v, links %v, tasks %v", events, readings, devices, links, tasks)
+	}
+	for _, plantID := range []int{events[0].PlantID, readings[0].PlantID, devices[0].PlantID, links[0].PlantID, tasks[0].PlantID} {
+		if plantID != 1 {
+			t.Errorf("Expected records of plant 1 to remain, got one of plant %d", plantID)
+		}
+	}
+	if history, _ := storage.ListCareTaskEvents(tasks[0].ID); len(history) != 1 {
+		t.Errorf("Expected the default plant's care history to remain, got %v", history)
+	}
+
+	// The deleted plant's ID is not handed out again
+	cactus := &models.PlantState{Name: "Cactus", TimeoutHours: 336}
+	storage.CreatePlant(cactus)
+	if cactus.ID == fern.ID {
+		t.Errorf("Expected a new plant ID, got the deleted plant's ID %d again", cactus.ID)
+	}
+}
+
 func TestMemoryStorage_UserOperations(t *testing.T) {
 	storage := NewMemoryStorage()
 	defer storage.Close()
@@ -139,3 +229,535 @@ func TestMemoryStorage_AdminConfig(t *testing.T) {
 		t.Errorf("Expected 2 allowed emails, got %d", len(retrievedConfig.AllowedEmails))
 	}
 }
+
+func TestMemoryStorage_NotificationOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	for _, email := range []string{"a@example.com", "b@example.com", "a@example.com"} {
+		err := storage.CreateNotification(&models.Notification{
+			UserEmail: email,
+			Channel:   "email",
+			Status:    models.NotificationStatusDelivered,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			t.Errorf("Expected no error creating notification, got %v", err)
+		}
+	}
+
+	all, err := storage.ListNotifications(models.NotificationFilter{})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 notifications, got %d", len(all))
+	}
+	if all[0].ID != 3 {
+		t.Errorf("Expected newest notification first, got ID %d", all[0].ID)
+	}
+
+	filtered, _ := storage.ListNotifications(models.NotificationFilter{UserEmail: "a@example.com", Limit: 1})
+	if len(filtered) != 1 || filtered[0].UserEmail != "a@example.com" {
+		t.Errorf("Expected 1 notification for a@example.com, got %v", filtered)
+	}
+}
+
+func TestMemoryStorage_WateringEventOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	start := time.Now()
+	for i, plantID := range []int{1, 2, 1} {
+		err := storage.AddWateringEvent(&models.PlantWateringEvent{
+			PlantID:   plantID,
+			WateredAt: start.Add(time.Duration(i) * time.Hour),
+			WateredBy: "a@example.com",
+		})
+		if err != nil {
+			t.Errorf("Expected no error adding watering event, got %v", err)
+		}
+	}
+
+	all, _ := storage.ListWateringEvents(0)
+	if len(all) != 3 || all[0].ID != 1 || all[2].ID != 3 {
+		t.Fatalf("Expected 3 events oldest first, got %+v", all)
+	}
+	if plant, _ := storage.ListWateringEvents(1); len(plant) != 2 {
+		t.Errorf("Expected 2 events for plant 1, got %d", len(plant))
+	}
+
+	count, err := storage.ReassignWateringEvents("a@example.com", "b@example.com")
+	if err != nil || count != 3 {
+		t.Errorf("Expected 3 reassigned events, got %d (%v)", count, err)
+	}
+	if all, _ := storage.ListWateringEvents(0); all[0].WateredBy != "b@example.com" {
+		t.Errorf("Expected reassigned waterer, got %s", all[0].WateredBy)
+	}
+
+	if err := storage.DeleteWateringEvent(2); err != nil {
+		t.Errorf("Expected no error deleting watering event, got %v", err)
+	}
+	if all, _ := storage.ListWateringEvents(0); len(all) != 2 || all[0].ID != 1 || all[1].ID != 3 {
+		t.Errorf("Expected events 1 and 3 after delete, got %+v", all)
+	}
+	event := &models.PlantWateringEvent{PlantID: 1, WateredAt: start, WateredBy: "a@example.com"}
+	if storage.AddWateringEvent(event); event.ID != 4 {
+		t.Errorf("Expected IDs not to be reused after delete, got %d", event.ID)
+	}
+}
+
+func TestMemoryStorage_PushSubscriptionOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	start := time.Now()
+	subscriptions := []*models.PushSubscription{
+		{UserEmail: "a@example.com", Endpoint: "https://push.example.com/1", CreatedAt: start},
+		{UserEmail: "b@example.com", Endpoint: "https://push.example.com/2", CreatedAt: start.Add(time.Minute)},
+		{UserEmail: "a@example.com", Endpoint: "https://push.example.com/3", CreatedAt: start.Add(2 * time.Minute)},
+	}
+	for _, subscription := range subscriptions {
+		if err := storage.SavePushSubscription(subscription); err != nil {
+			t.Errorf("Expected no error saving subscription, got %v", err)
+		}
+	}
+
+	all, _ := storage.ListPushSubscriptions("")
+	if len(all) != 3 || all[0].Endpoint != "https://push.example.com/1" {
+		t.Fatalf("Expected 3 subscriptions oldest first, got %+v", all)
+	}
+
+	mine, _ := storage.ListPushSubscriptions("a@example.com")
+	if len(mine) != 2 {
+		t.Errorf("Expected 2 subscriptions for a@example.com, got %d", len(mine))
+	}
+
+	if err := storage.DeletePushSubscription("https://push.example.com/1"); err != nil {
+		t.Errorf("Expected no error deleting subscription, got %v", err)
+	}
+	if remaining, _ := storage.ListPushSubscriptions(""); len(remaining) != 2 {
+		t.Errorf("Expected 2 subscriptions after delete, got %d", len(remaining))
+	}
+}
+
+func TestMemoryStorage_APIKeyOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	start := time.Now()
+	storage.SaveAPIKey(&models.APIKey{ID: "b", Name: "Second", Hash: "hash-b", CreatedAt: start.Add(time.Minute)})
+	storage.SaveAPIKey(&models.APIKey{ID: "a", Name: "First", Hash: "hash-a", CreatedAt: start})
+
+	key, err := storage.GetAPIKey("a")
+	if err != nil || key == nil || key.Hash != "hash-a" {
+		t.Fatalf("Expected to get key a, got %+v (%v)", key, err)
+	}
+	if missing, _ := storage.GetAPIKey("missing"); missing != nil {
+		t.Errorf("Expected nil for unknown key, got %+v", missing)
+	}
+
+	keys, _ := storage.ListAPIKeys()
+	if len(keys) != 2 || keys[0].ID != "a" {
+		t.Fatalf("Expected 2 keys oldest first, got %+v", keys)
+	}
+
+	if err := storage.DeleteAPIKey("a"); err != nil {
+		t.Errorf("Expected no error deleting key, got %v", err)
+	}
+	if remaining, _ := storage.ListAPIKeys(); len(remaining) != 1 {
+		t.Errorf("Expected 1 key after delete, got %d", len(remaining))
+	}
+}
+
+func TestMemoryStorage_DeviceOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	now := time.Now()
+	storage.SaveDevice(&models.Device{ID: "kitchen", Kind: models.DeviceKindSensor, PlantID: 1, Active: true, TokenHash: "hash-k"})
+	storage.SaveDevice(&models.Device{ID: "balcony", Kind: models.DeviceKindButton, PlantID: 2, LastSeen: &now})
+
+	device, err := storage.GetDevice("balcony")
+	if err != nil || device == nil || device.Kind != models.DeviceKindButton || device.LastSeen == nil {
+		t.Fatalf("Expected to get the balcony device, got %+v (%v)", device, err)
+	}
+	*device.LastSeen = now.Add(time.Hour)
+	if stored, _ := storage.GetDevice("balcony"); !stored.LastSeen.Equal(now) {
+		t.Errorf("Expected the stored last seen time to be unaffected by changes to a copy, got %v", stored.LastSeen)
+	}
+	if missing, _ := storage.GetDevice("missing"); missing != nil {
+		t.Errorf("Expected nil for unknown device, got %+v", missing)
+	}
+
+	devices, _ := storage.ListDevices()
+	if len(devices) != 2 || devices[0].ID != "balcony" {
+		t.Fatalf("Expected 2 devices sorted by ID, got %+v", devices)
+	}
+
+	if err := storage.DeleteDevice("balcony"); err != nil {
+		t.Errorf("Expected no error deleting device, got %v", err)
+	}
+	if remaining, _ := storage.ListDevices(); len(remaining) != 1 || remaining[0].ID != "kitchen" {
+		t.Errorf("Expected only the kitchen device after delete, got %+v", remaining)
+	}
+}
+
+func TestMemoryStorage_ShareLinkOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	now := time.Now()
+	storage.SaveShareLink(&models.ShareLink{ID: "b", Name: "Grandma", PlantID: 1, CreatedAt: now})
+	storage.SaveShareLink(&models.ShareLink{ID: "a", Name: "Neighbour", PlantID: 2, CreatedAt: now.Add(time.Hour)})
+
+	if link, _ := storage.GetShareLink("b"); link == nil || link.Name != "Grandma" {
+		t.Errorf("Expected the Grandma link, got %+v", link)
+	}
+	if missing, _ := storage.GetShareLink("missing"); missing != nil {
+		t.Errorf("Expected nil for unknown link, got %+v", missing)
+	}
+	if links, _ := storage.ListShareLinks(); len(links) != 2 || links[0].ID != "b" {
+		t.Errorf("Expected 2 links oldest first, got %+v", links)
+	}
+
+	storage.DeleteShareLink("b")
+	if links, _ := storage.ListShareLinks(); len(links) != 1 || links[0].ID != "a" {
+		t.Errorf("Expected only the neighbour's link after delete, got %+v", links)
+	}
+}
+
+func TestMemoryStorage_InviteOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	now := time.Now()
+	storage.SaveInvite(&models.Invite{ID: "b", Name: "Alex", Role: "member", CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+	storage.SaveInvite(&models.Invite{ID: "a", Name: "Sitter", Role: "viewer", CreatedAt: now.Add(time.Hour)})
+
+	if invite, _ := storage.GetInvite("b"); invite == nil || invite.Name != "Alex" {
+		t.Errorf("Expected Alex's invite, got %+v", invite)
+	}
+	if missing, _ := storage.GetInvite("missing"); missing != nil {
+		t.Errorf("Expected nil for unknown invite, got %+v", missing)
+	}
+	if invites, _ := storage.ListInvites(); len(invites) != 2 || invites[0].ID != "b" {
+		t.Errorf("Expected 2 invites oldest first, got %+v", invites)
+	}
+
+	storage.DeleteInvite("b")
+	if invites, _ := storage.ListInvites(); len(invites) != 1 || invites[0].ID != "a" {
+		t.Errorf("Expected only the sitter's invite after delete, got %+v", invites)
+	}
+}
+
+func TestMemoryStorage_CareTaskOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	now := time.Now()
+	mist := &models.CareTask{PlantID: 1, Type: models.CareTaskMist, IntervalHours: 48}
+	storage.SaveCareTask(mist)
+	storage.SaveCareTask(&models.CareTask{PlantID: 2, Type: models.CareTaskRepot, IntervalHours: 8760})
+	if mist.ID != 1 {
+		t.Errorf("Expected the first task to get ID 1, got %d", mist.ID)
+	}
+
+	mist.LastDone = &now
+	storage.SaveCareTask(mist)
+	task, err := storage.GetCareTask(1)
+	if err != nil || task == nil || task.LastDone == nil || !task.LastDone.Equal(now) {
+		t.Fatalf("Expected the updated mist task, got %+v (%v)", task, err)
+	}
+	if missing, _ := storage.GetCareTask(99); missing != nil {
+		t.Errorf("Expected nil for unknown task, got %+v", missing)
+	}
+	if tasks, _ := storage.ListCareTasks(1); len(tasks) != 1 || tasks[0].Type != models.CareTaskMist {
+		t.Errorf("Expected only plant 1's task, got %+v", tasks)
+	}
+	if tasks, _ := storage.ListCareTasks(0); len(tasks) != 2 || tasks[1].Type != models.CareTaskRepot {
+		t.Errorf("Expected every plant's tasks sorted by ID, got %+v", tasks)
+	}
+
+	storage.AddCareTaskEvent(&models.CareTaskEvent{TaskID: 1, PlantID: 1, DoneAt: now.Add(-time.Hour)})
+	storage.AddCareTaskEvent(&models.CareTaskEvent{TaskID: 2, PlantID: 2, DoneAt: now})
+	storage.AddCareTaskEvent(&models.CareTaskEvent{TaskID: 1, PlantID: 1, DoneAt: now})
+	events, _ := storage.ListCareTaskEvents(1)
+	if len(events) != 2 || events[0].ID != 1 || events[1].ID != 3 {
+		t.Errorf("Expected the mist task's events oldest first, got %+v", events)
+	}
+
+	if err := storage.DeleteCareTask(1); err != nil {
+		t.Errorf("Expected no error deleting task, got %v", err)
+	}
+	if events, _ := storage.ListCareTaskEvents(1); len(events) != 0 {
+		t.Errorf("Expected the deleted task's history to be gone, got %+v", events)
+	}
+	if events, _ := storage.ListCareTaskEvents(2); len(events) != 1 {
+		t.Errorf("Expected other tasks' history to be kept, got %+v", events)
+	}
+
+	storage.AddCareTaskEvent(&models.CareTaskEvent{TaskID: 2, PlantID: 2, DoneAt: now, DoneBy: "a@example.com"})
+	if count, err := storage.ReassignCareTaskEvents("a@example.com", "b@example.com"); err != nil || count != 1 {
+		t.Errorf("Expected 1 reassigned event, got %d (%v)", count, err)
+	}
+	if events, _ := storage.ListCareTaskEvents(2); len(events) != 2 || events[0].DoneBy != "" || events[1].DoneBy != "b@example.com" {
+		t.Errorf("Expected only a@example.com's completion to be reassigned, got %+v", events)
+	}
+}
+
+func TestMemoryStorage_SessionOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	now := time.Now()
+	storage.SaveSession(&models.Session{ID: "newer", Email: "a@example.com", CreatedAt: now})
+	storage.SaveSession(&models.Session{ID: "older", Email: "b@example.com", CreatedAt: now.Add(-time.Hour)})
+	storage.SaveSession(&models.Session{ID: "oldest", Email: "a@example.com", CreatedAt: now.Add(-2 * time.Hour)})
+
+	session, err := storage.GetSession("newer")
+	if err != nil || session == nil || session.Email != "a@example.com" {
+		t.Fatalf("Expected to get the newer session, got %+v (%v)", session, err)
+	}
+	if missing, _ := storage.GetSession("missing"); missing != nil {
+		t.Errorf("Expected nil for unknown session, got %+v", missing)
+	}
+
+	sessions, _ := storage.ListSessions()
+	if len(sessions) != 3 || sessions[0].ID != "oldest" || sessions[2].ID != "newer" {
+		t.Fatalf("Expected 3 sessions oldest first, got %+v", sessions)
+	}
+
+	if err := storage.DeleteSessions([]string{"newer", "oldest", "missing"}); err != nil {
+		t.Errorf("Expected no error deleting sessions, got %v", err)
+	}
+	if remaining, _ := storage.ListSessions(); len(remaining) != 1 || remaining[0].ID != "older" {
+		t.Errorf("Expected only the older session after delete, got %+v", remaining)
+	}
+}
+
+func TestMemoryStorage_AuditOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	first := &models.AuditEntry{Actor: "admin@example.com", Action: models.AuditUserAdd, Target: "a@example.com", New: []byte(`"a@example.com"`)}
+	if err := storage.AddAuditEntry(first); err != nil || first.ID != 1 {
+		t.Fatalf("Expected the first entry to get ID 1, got %d (%v)", first.ID, err)
+	}
+	storage.AddAuditEntry(&models.AuditEntry{Actor: "admin@example.com", Action: models.AuditUserRemove, Target: "a@example.com"})
+
+	entries, _ := storage.ListAuditEntries(models.AuditFilter{})
+	if len(entries) != 2 || entries[0].ID != 2 {
+		t.Fatalf("Expected 2 entries newest first, got %+v", entries)
+	}
+	entries[1].New[0] = 'x'
+	if stored, _ := storage.ListAuditEntries(models.AuditFilter{Action: models.AuditUserAdd}); len(stored) != 1 || string(stored[0].New) != `"a@example.com"` {
+		t.Errorf("Expected the stored entry to be unaffected by changes to a copy, got %+v", stored)
+	}
+}
+
+func TestMemoryStorage_UserActivityOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	now := time.Now()
+	storage.SaveUserActivity([]*models.UserActivity{
+		{Email: "b@example.com", FirstSeen: now, LastSeen: now},
+		{Email: "a@example.com", FirstSeen: now, LastSeen: now, UserAgent: "Firefox"},
+	})
+	storage.SaveUserActivity([]*models.UserActivity{
+		{Email: "a@example.com", FirstSeen: now, LastSeen: now.Add(time.Hour), UserAgent: "Safari"},
+	})
+
+	activity, err := storage.ListUserActivity()
+	if err != nil || len(activity) != 2 {
+		t.Fatalf("Expected 2 activity records, got %+v (%v)", activity, err)
+	}
+	if activity[0].Email != "a@example.com" || activity[0].UserAgent != "Safari" || !activity[0].LastSeen.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected the latest activity first by email, got %+v", activity[0])
+	}
+
+	storage.DeleteUserActivity("a@example.com")
+	if activity, _ := storage.ListUserActivity(); len(activity) != 1 || activity[0].Email != "b@example.com" {
+		t.Errorf("Expected only b@example.com's activity after delete, got %+v", activity)
+	}
+}
+
+func TestMemoryStorage_SensorReadingOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	start := time.Now().Add(-time.Hour)
+	moisture := 42.5
+	for i, device := range []string{"kitchen", "balcony", "kitchen"} {
+		reading := &models.SensorReading{DeviceID: device, PlantID: 1, Moisture: &moisture, RecordedAt: start.Add(time.Duration(i) * time.Minute)}
+		if err := storage.AddSensorReading(reading); err != nil {
+			t.Errorf("Expected no error adding sensor reading, got %v", err)
+		}
+		if reading.ID != i+1 {
+			t.Errorf("Expected reading ID %d, got %d", i+1, reading.ID)
+		}
+	}
+
+	all, _ := storage.ListSensorReadings(models.SensorReadingFilter{})
+	if len(all) != 3 || all[0].ID != 3 || all[2].ID != 1 {
+		t.Fatalf("Expected 3 readings newest first, got %+v", all)
+	}
+	if *all[0].Moisture != 42.5 || all[0].Temperature != nil {
+		t.Errorf("Expected moisture only, got %+v", all[0])
+	}
+	if kitchen, _ := storage.ListSensorReadings(models.SensorReadingFilter{DeviceID: "kitchen", Limit: 1}); len(kitchen) != 1 || kitchen[0].ID != 3 {
+		t.Errorf("Expected the latest kitchen reading, got %+v", kitchen)
+	}
+	if recent, _ := storage.ListSensorReadings(models.SensorReadingFilter{Since: start.Add(30 * time.Second)}); len(recent) != 2 {
+		t.Errorf("Expected 2 recent readings, got %d", len(recent))
+	}
+}
+
+func TestMemoryStorage_SensorReadingLimit(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	start := time.Now().Add(-30 * 24 * time.Hour)
+	moisture := 50.0
+	storage.AddSensorReading(&models.SensorReading{DeviceID: "balcony", Moisture: &moisture, RecordedAt: start})
+	for i := 0; i < MaxSensorReadingsPerDevice+10; i++ {
+		storage.AddSensorReading(&models.SensorReading{DeviceID: "kitchen", Moisture: &moisture, RecordedAt: start.Add(time.Duration(i) * time.Minute)})
+	}
+
+	kitchen, _ := storage.ListSensorReadings(models.SensorReadingFilter{DeviceID: "kitchen"})
+	if len(kitchen) != MaxSensorReadingsPerDevice {
+		t.Errorf("Expected %d kitchen readings, got %d", MaxSensorReadingsPerDevice, len(kitchen))
+	}
+	if oldest := kitchen[len(kitchen)-1]; !oldest.RecordedAt.Equal(start.Add(10 * time.Minute)) {
+		t.Errorf("Expected the oldest readings to be dropped, oldest is %v", oldest.RecordedAt)
+	}
+	if balcony, _ := storage.ListSensorReadings(models.SensorReadingFilter{DeviceID: "balcony"}); len(balcony) != 1 {
+		t.Errorf("Expected other devices to keep their readings, got %d", len(balcony))
+	}
+}
+
+func TestMemoryStorage_HealthSampleOperations(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	start := time.Now().Add(-3 * time.Hour)
+	for i, status := range []string{"healthy", "degraded", "healthy"} {
+		sample := &models.HealthSample{CheckedAt: start.Add(time.Duration(i) * time.Hour), Status: status, Components: map[string]string{"storage": status}}
+		if err := storage.AddHealthSample(sample); err != nil {
+			t.Fatalf("Expected no error adding health sample, got %v", err)
+		}
+		if sample.ID != i+1 {
+			t.Errorf("Expected sample ID %d, got %d", i+1, sample.ID)
+		}
+	}
+
+	recent, _ := storage.ListHealthSamples(start.Add(30 * time.Minute))
+	if len(recent) != 2 || recent[0].Status != "degraded" || recent[1].ID != 3 {
+		t.Fatalf("Expected the 2 recent samples oldest first, got %+v", recent)
+	}
+	recent[0].Components["storage"] = "unhealthy"
+	if again, _ := storage.ListHealthSamples(start.Add(30 * time.Minute)); again[0].Components["storage"] != "degraded" {
+		t.Error("Expected listed samples to be copies")
+	}
+
+	removed, err := storage.PruneHealthSamples(start.Add(90 * time.Minute))
+	if err != nil || removed != 2 {
+		t.Errorf("Expected 2 samples pruned, got %d (%v)", removed, err)
+	}
+	if all, _ := storage.ListHealthSamples(time.Time{}); len(all) != 1 || all[0].ID != 3 {
+		t.Errorf("Expected only the newest sample to remain, got %+v", all)
+	}
+	if removed, _ := storage.PruneHealthSamples(start); removed != 0 {
+		t.Errorf("Expected nothing left to prune, got %d", removed)
+	}
+}
+
+func TestMemoryStorage_Reset(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	storage.CreatePlant(&models.PlantState{Name: "Fern"})
+	storage.CreateUser(&models.User{Email: "a@example.com"})
+	storage.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: 48})
+	storage.AddWateringEvent(&models.PlantWateringEvent{PlantID: 1, WateredBy: "a@example.com"})
+	storage.AddSensorReading(&models.SensorReading{DeviceID: "kitchen", PlantID: 1})
+	storage.SaveDevice(&models.Device{ID: "kitchen", PlantID: 1})
+
+	if err := storage.Reset(); err != nil {
+		t.Fatalf("Failed to reset: %v", err)
+	}
+	plants, _ := storage.ListPlants()
+	user, _ := storage.GetUser("a@example.com")
+	config, _ := storage.GetAdminConfig()
+	events, _ := storage.ListWateringEvents(0)
+	readings, _ := storage.ListSensorReadings(models.SensorReadingFilter{})
+	devices, _ := storage.ListDevices()
+	if len(plants) != 0 || user != nil || config != nil || len(events) != 0 || len(readings) != 0 || len(devices) != 0 {
+		t.Errorf("Expected an empty store, got plants %v, user %v, config %v, events %v, readings %v, devices %v", plants, user, config, events, readings, devices)
+	}
+
+	// IDs start over
+	plant := &models.PlantState{Name: "Cactus"}
+	storage.CreatePlant(plant)
+	if plant.ID != models.DefaultPlantID {
+		t.Errorf("Expected the first plant after a reset to get ID %d, got %d", models.DefaultPlantID, plant.ID)
+	}
+}
+
+func TestMemoryStorage_ConcurrentAccess(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			now := time.Now()
+			storage.UpdatePlantState(&models.PlantState{ID: 1, Name: "Plant", LastWatered: &now, TimeoutHours: 24})
+			storage.CreateUser(&models.User{Email: fmt.Sprintf("user%d@example.com", i)})
+			storage.UpdateAdminConfig(&models.AdminConfig{TimeoutHours: i + 1, AllowedEmails: []string{"a@example.com"}})
+			storage.CreateNotification(&models.Notification{UserEmail: "a@example.com"})
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			storage.GetPlantState()
+			storage.GetUser(fmt.Sprintf("user%d@example.com", i))
+			storage.GetAdminConfig()
+			storage.ListNotifications(models.NotificationFilter{})
+		}(i)
+	}
+	wg.Wait()
+
+	notifications, _ := storage.ListNotifications(models.NotificationFilter{})
+	if len(notifications) != 50 {
+		t.Errorf("Expected 50 notifications, got %d", len(notifications))
+	}
+}
+
+func TestMemoryStorage_ReturnsCopies(t *testing.T) {
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	start := time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC)
+	storage.UpdatePlantState(&models.PlantState{ID: 1, Name: "Original", TimeoutHours: 24, Metadata: map[string]models.MetadataValue{
+		"location": {Type: models.MetadataTypeString, Value: "balcony"},
+	}, Vacation: &models.Vacation{Start: start, End: start.AddDate(0, 0, 14)}})
+
+	plant, _ := storage.GetPlantState()
+	plant.Name = "Modified"
+	plant.Metadata["location"] = models.MetadataValue{Type: models.MetadataTypeString, Value: "kitchen"}
+	plant.Vacation.End = start
+
+	stored, _ := storage.GetPlantState()
+	if stored.Name != "Original" {
+		t.Errorf("Expected stored plant to be unaffected by caller changes, got %s", stored.Name)
+	}
+	if stored.Metadata["location"].Value != "balcony" {
+		t.Errorf("Expected stored metadata to be unaffected by caller changes, got %v", stored.Metadata)
+	}
+	if !stored.Vacation.End.Equal(start.AddDate(0, 0, 14)) {
+		t.Errorf("Expected stored vacation to be unaffected by caller changes, got %+v", stored.Vacation)
+	}
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+
+	"watered/internal/models"
+	"watered/internal/tracing"
+)
+
+// tracedStorage records a span for each plant, watering, user and
+// notification call on the storage it wraps, as a child of the span in ctx.
+// Other calls pass straight through.
+type tracedStorage struct {
+	Storage
+	ctx context.Context
+}
+
+// WithTracing returns storage whose calls are recorded as children of the
+// span in ctx. It returns inner unchanged when ctx has no span, so untraced
+// requests pay nothing.
+func WithTracing(ctx context.Context, inner Storage) Storage {
+	if tracing.SpanFromContext(ctx) == nil {
+		return inner
+	}
+	if traced, ok := inner.(*tracedStorage); ok {
+		inner = traced.Storage
+	}
+	return &tracedStorage{Storage: inner, ctx: ctx}
+}
+
+// traced runs call inside a span named after the storage method
+func traced[T any](s *tracedStorage, method string, call func() (T, error)) (T, error) {
+	_, span := tracing.Start(s.ctx, "storage."+method)
+	defer span.End()
+	result, err := call()
+	span.RecordError(err)
+	return result, err
+}
+
+// tracedWrite runs a call that returns only an error inside a span
+func tracedWrite(s *tracedStorage, method string, call func() error) error {
+	_, err := traced(s, method, func() (struct{}, error) { return struct{}{}, call() })
+	return err
+}
+
+func (s *tracedStorage) GetPlantState() (*models.PlantState, error) {
+	return traced(s, "GetPlantState", s.Storage.GetPlantState)
+}
+
+func (s *tracedStorage) UpdatePlantState(state *models.PlantState) error {
+	return tracedWrite(s, "UpdatePlantState", func() error { return s.Storage.UpdatePlantState(state) })
+}
+
+func (s *tracedStorage) ListPlants() ([]*models.PlantState, error) {
+	return traced(s, "ListPlants", s.Storage.ListPlants)
+}
+
+func (s *tracedStorage) GetPlant(id int) (*models.PlantState, error) {
+	return traced(s, "GetPlant", func() (*models.PlantState, error) { return s.Storage.GetPlant(id) })
+}
+
+func (s *tracedStorage) CreatePlant(plant *models.PlantState) error {
+	return tracedWrite(s, "CreatePlant", func() error { return s.Storage.CreatePlant(plant) })
+}
+
+func (s *tracedStorage) UpdatePlant(plant *models.PlantState) error {
+	return tracedWrite(s, "UpdatePlant", func() error { return s.Storage.UpdatePlant(plant) })
+}
+
+func (s *tracedStorage) DeletePlant(id int) error {
+	return tracedWrite(s, "DeletePlant", func() error { return s.Storage.DeletePlant(id) })
+}
+
+func (s *tracedStorage) GetUser(email string) (*models.User, error) {
+	return traced(s, "GetUser", func() (*models.User, error) { return s.Storage.GetUser(email) })
+}
+
+func (s *tracedStorage) GetAdminConfig() (*models.AdminConfig, error) {
+	return traced(s, "GetAdminConfig", s.Storage.GetAdminConfig)
+}
+
+func (s *tracedStorage) UpdateAdminConfig(config *models.AdminConfig) error {
+	return tracedWrite(s, "UpdateAdminConfig", func() error { return s.Storage.UpdateAdminConfig(config) })
+}
+
+func (s *tracedStorage) CreateNotification(notification *models.Notification) error {
+	return tracedWrite(s, "CreateNotification", func() error { return s.Storage.CreateNotification(notification) })
+}
+
+func (s *tracedStorage) ListNotifications(filter models.NotificationFilter) ([]*models.Notification, error) {
+	return traced(s, "ListNotifications", func() ([]*models.Notification, error) { return s.Storage.ListNotifications(filter) })
+}
+
+func (s *tracedStorage) AddWateringEvent(event *models.PlantWateringEvent) error {
+	return tracedWrite(s, "AddWateringEvent", func() error { return s.Storage.AddWateringEvent(event) })
+}
+
+func (s *tracedStorage) ListWateringEvents(plantID int) ([]*models.PlantWateringEvent, error) {
+	return traced(s, "ListWateringEvents", func() ([]*models.PlantWateringEvent, error) { return s.Storage.ListWateringEvents(plantID) })
+}
+
+func (s *tracedStorage) DeleteWateringEvent(id int) error {
+	return tracedWrite(s, "DeleteWateringEvent", func() error { return s.Storage.DeleteWateringEvent(id) })
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+	"watered/internal/models"
+	"watered/internal/tracing"
+)
+
+func TestWithTracing(t *testing.T) {
+	var mu sync.Mutex
+	var names []string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []struct {
+						Name string `json:"name"`
+					} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, resource := range req.ResourceSpans {
+			for _, scope := range resource.ScopeSpans {
+				for _, span := range scope.Spans {
+					names = append(names, span.Name)
+				}
+			}
+		}
+	}))
+	defer collector.Close()
+
+	store := NewMemoryStorage()
+	defer store.Close()
+	if WithTracing(context.Background(), store) != Storage(store) {
+		t.Fatal("Expected the storage itself outside a traced request")
+	}
+
+	exporter := tracing.NewExporter(config.TracingConfig{Endpoint: collector.URL, ServiceName: "watered"}, "dev")
+	exporter.Start()
+	tracing.SetTracer(tracing.NewTracer(exporter, 1))
+	defer tracing.SetTracer(nil)
+
+	ctx, span := tracing.Start(context.Background(), "request")
+	traced := WithTracing(ctx, WithTracing(ctx, store))
+	if err := traced.CreatePlant(&models.PlantState{Name: "Fern", TimeoutHours: 24}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	plants, err := traced.ListPlants()
+	if err != nil || len(plants) != 1 {
+		t.Fatalf("Expected the created plant, got %v (%v)", plants, err)
+	}
+	// Calls without a span of their own pass through
+	if _, err := traced.ListAPIKeys(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	span.End()
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := exporter.Shutdown(shutdown); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"storage.CreatePlant", "storage.ListPlants", "request"}
+	if len(names) != len(want) {
+		t.Fatalf("Expected spans %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Expected spans %v, got %v", want, names)
+			break
+		}
+	}
+}
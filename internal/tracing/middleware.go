@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"watered/internal/logger"
+)
+
+// Middleware starts a server span for each request, continuing the trace
+// named by its traceparent header, and names the span after the chi route
+// once the request has been served. It must run after logger.Middleware so
+// the request's log lines carry the trace ID.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		if remote, ok := Extract(r.Header); ok {
+			ctx = ContextWithRemoteSpanContext(ctx, remote)
+		}
+		ctx, span := start(ctx, r.Method, KindServer, []Attribute{
+			String("http.request.method", r.Method),
+			String("url.path", r.URL.Path),
+			String("user_agent.original", r.UserAgent()),
+		})
+		if span == nil {
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		defer span.End()
+		logger.AddAttrs(ctx, "trace_id", span.Context().TraceID.String(), "span_id", span.Context().SpanID.String())
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		r = r.WithContext(ctx)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		// chi fills in the route pattern while routing, so it is only known
+		// afterwards
+		if route := chi.RouteContext(r.Context()).RoutePattern(); route != "" {
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(String("http.route", route))
+		}
+		span.SetAttributes(Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.Fail(http.StatusText(status))
+		}
+	})
+}
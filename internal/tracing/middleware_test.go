@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestMiddleware(t *testing.T) {
+	rec := install(t, 1)
+
+	var inner *Span
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/api/v1/plants/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, inner = Start(r.Context(), "handler")
+		inner.End()
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/plants/2", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(rec.spans) != 2 {
+		t.Fatalf("Expected the handler and server spans, got %d", len(rec.spans))
+	}
+	server := rec.spans[1]
+	if server.name != "GET /api/v1/plants/{id}" || server.kind != KindServer || !server.failed {
+		t.Errorf("Unexpected server span: %s (kind %d, failed %v)", server.name, server.kind, server.failed)
+	}
+	if server.context.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || server.parent.String() != "00f067aa0ba902b7" {
+		t.Errorf("Expected the server span to continue the caller's trace, got %+v", server.context)
+	}
+	if inner.parent != server.context.SpanID {
+		t.Error("Expected the handler span to be a child of the server span")
+	}
+
+	attrs := map[string]any{}
+	for _, attr := range server.attrs {
+		attrs[attr.Key] = attr.Value
+	}
+	if attrs["http.route"] != "/api/v1/plants/{id}" || attrs["http.response.status_code"] != int64(http.StatusBadGateway) || attrs["url.path"] != "/api/v1/plants/2" {
+		t.Errorf("Unexpected server span attributes: %v", attrs)
+	}
+}
+
+func TestMiddleware_Unsampled(t *testing.T) {
+	rec := install(t, 1)
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, span := Start(r.Context(), "handler"); span != nil {
+			t.Error("Expected no span when the caller did not sample the trace")
+		}
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(rec.spans) != 0 {
+		t.Errorf("Expected no spans, got %d", len(rec.spans))
+	}
+}
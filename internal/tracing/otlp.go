@@ -0,0 +1,262 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"watered/internal/config"
+)
+
+const (
+	// queueSize is how many finished spans wait for export before new ones
+	// are dropped
+	queueSize = 2048
+	// batchSize is the most spans sent in one request
+	batchSize = 512
+	// flushInterval is the longest a finished span waits to be sent
+	flushInterval = 5 * time.Second
+)
+
+// Exporter sends finished spans to an OTLP/HTTP endpoint as JSON, in the
+// background and in batches. Spans are dropped rather than slowing requests
+// when the endpoint cannot keep up.
+type Exporter struct {
+	url      string
+	headers  map[string]string
+	resource otlpResource
+	client   *http.Client
+
+	queue chan *Span
+	done  chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewExporter creates an exporter for the configured endpoint. It returns
+// nil when tracing is not configured.
+func NewExporter(cfg config.TracingConfig, version string) *Exporter {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return &Exporter{
+		url:     cfg.Endpoint + "/v1/traces",
+		headers: cfg.Headers,
+		resource: otlpResource{Attributes: []otlpAttribute{
+			otlpAttr(String("service.name", cfg.ServiceName)),
+			otlpAttr(String("service.version", version)),
+		}},
+		client: &http.Client{Timeout: 10 * time.Second},
+		queue:  make(chan *Span, queueSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start sends spans in the background until Shutdown is called
+func (e *Exporter) Start() {
+	go e.run()
+}
+
+// Shutdown stops accepting spans and sends those still queued, giving up
+// when ctx is done
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// export queues a finished span
+func (e *Exporter) export(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	select {
+	case e.queue <- span:
+	default:
+		slog.Debug("Dropped span, export queue full", "span", span.name)
+	}
+}
+
+func (e *Exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil {
+			slog.Warn("Failed to export spans", "spans", len(batch), "error", err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case span, ok := <-e.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) == batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// send posts one batch of spans
+func (e *Exporter) send(spans []*Span) error {
+	otlpSpans := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		otlpSpans[i] = encodeSpan(span)
+	}
+	body, err := json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   e.resource,
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "watered/internal/tracing"}, Spans: otlpSpans}},
+	}}})
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range e.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send spans: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+// The OTLP/HTTP JSON encoding of ExportTraceServiceRequest. IDs are hex and
+// 64-bit integers are strings, as the protobuf JSON mapping requires.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              Kind            `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+// otlpStatus codes: 0 is unset, 2 is error
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+func encodeSpan(span *Span) otlpSpan {
+	span.mu.Lock()
+	defer span.mu.Unlock()
+
+	encoded := otlpSpan{
+		TraceID:           span.context.TraceID.String(),
+		SpanID:            span.context.SpanID.String(),
+		Name:              span.name,
+		Kind:              span.kind,
+		StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+	}
+	if span.parent.IsValid() {
+		encoded.ParentSpanID = span.parent.String()
+	}
+	for _, attr := range span.attrs {
+		encoded.Attributes = append(encoded.Attributes, otlpAttr(attr))
+	}
+	if span.failed {
+		encoded.Status = otlpStatus{Code: 2, Message: span.status}
+	}
+	return encoded
+}
+
+func otlpAttr(attr Attribute) otlpAttribute {
+	encoded := otlpAttribute{Key: attr.Key}
+	switch v := attr.Value.(type) {
+	case string:
+		encoded.Value.StringValue = &v
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		encoded.Value.IntValue = &s
+	case float64:
+		encoded.Value.DoubleValue = &v
+	case bool:
+		encoded.Value.BoolValue = &v
+	default:
+		s := fmt.Sprint(v)
+		encoded.Value.StringValue = &s
+	}
+	return encoded
+}
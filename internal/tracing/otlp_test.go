@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"watered/internal/config"
+)
+
+// collector is an OTLP/HTTP endpoint keeping the requests it receives
+type collector struct {
+	mu       sync.Mutex
+	requests []otlpRequest
+	headers  []http.Header
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var req otlpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+	c.headers = append(c.headers, r.Header.Clone())
+}
+
+// spans returns every span received
+func (c *collector) spans() []otlpSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var spans []otlpSpan
+	for _, req := range c.requests {
+		for _, resource := range req.ResourceSpans {
+			for _, scope := range resource.ScopeSpans {
+				spans = append(spans, scope.Spans...)
+			}
+		}
+	}
+	return spans
+}
+
+func TestExporter(t *testing.T) {
+	col := &collector{}
+	server := httptest.NewServer(col)
+	defer server.Close()
+
+	exporter := NewExporter(config.TracingConfig{
+		Endpoint:    server.URL,
+		Headers:     map[string]string{"X-Api-Key": "secret"},
+		ServiceName: "watered-test",
+		SampleRatio: 1,
+	}, "1.2.3")
+	exporter.Start()
+	SetTracer(NewTracer(exporter, 1))
+	t.Cleanup(func() { SetTracer(nil) })
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child", String("storage", "memory"), Bool("cached", false))
+	child.Fail("not found")
+	child.End()
+	parent.End()
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := exporter.Shutdown(shutdown); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Spans ended after shutdown are dropped
+	_, late := Start(context.Background(), "late")
+	late.End()
+
+	spans := col.spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %+v", spans)
+	}
+	if col.headers[0].Get("X-Api-Key") != "secret" {
+		t.Errorf("Expected the configured headers, got %v", col.headers[0])
+	}
+	resource := col.requests[0].ResourceSpans[0].Resource.Attributes
+	if len(resource) != 2 || *resource[0].Value.StringValue != "watered-test" || *resource[1].Value.StringValue != "1.2.3" {
+		t.Errorf("Unexpected resource attributes: %+v", resource)
+	}
+
+	got, want := spans[0], child.Context()
+	if got.Name != "child" || got.TraceID != want.TraceID.String() || got.SpanID != want.SpanID.String() || got.ParentSpanID != parent.Context().SpanID.String() {
+		t.Errorf("Unexpected child span: %+v", got)
+	}
+	if got.Kind != KindInternal || got.Status.Code != 2 || got.Status.Message != "not found" || got.StartTimeUnixNano == "" {
+		t.Errorf("Unexpected child span: %+v", got)
+	}
+	if len(got.Attributes) != 2 || *got.Attributes[0].Value.StringValue != "memory" || *got.Attributes[1].Value.BoolValue {
+		t.Errorf("Unexpected child attributes: %+v", got.Attributes)
+	}
+	if spans[1].ParentSpanID != "" || spans[1].Status.Code != 0 {
+		t.Errorf("Expected a successful root span, got %+v", spans[1])
+	}
+}
+
+func TestNewExporter_Disabled(t *testing.T) {
+	if NewExporter(config.TracingConfig{}, "dev") != nil {
+		t.Error("Expected no exporter without an endpoint")
+	}
+}
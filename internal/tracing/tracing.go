@@ -0,0 +1,257 @@
+// Package tracing records request traces and sends them to an OpenTelemetry
+// collector, Jaeger or any other OTLP/HTTP endpoint, so slow requests can be
+// broken down into the handler, service and storage calls they made. Spans
+// follow the W3C Trace Context, so traces started by a load balancer or a
+// client continue through the server.
+//
+// Tracing is off until SetTracer installs a tracer. Until then, and for
+// requests that are not sampled, Start returns a nil span whose methods do
+// nothing.
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// String returns the ID as lowercase hex
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// String returns the ID as lowercase hex
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid reports whether the ID is not all zeroes
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+// IsValid reports whether the ID is not all zeroes
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+// SpanContext is the part of a span that crosses process boundaries
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether the span context names a trace and a span
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Kind is the role of a span, as OTLP numbers it
+type Kind int
+
+// Span kinds
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+)
+
+// Attribute is a key and a string, int64, float64 or bool value
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// String returns a string attribute
+func String(key, value string) Attribute { return Attribute{key, value} }
+
+// Int returns an integer attribute
+func Int(key string, value int) Attribute { return Attribute{key, int64(value)} }
+
+// Bool returns a boolean attribute
+func Bool(key string, value bool) Attribute { return Attribute{key, value} }
+
+// Span is one timed operation in a trace. A nil span is valid and records
+// nothing.
+type Span struct {
+	tracer  *Tracer
+	context SpanContext
+	parent  SpanID
+	kind    Kind
+	start   time.Time
+
+	mu     sync.Mutex
+	name   string
+	attrs  []Attribute
+	failed bool
+	status string
+	end    time.Time
+}
+
+// Context returns the span's trace and span IDs
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+// SetName renames the span, such as once a request's route is known
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// Fail marks the span as failed with a message
+func (s *Span) Fail(message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = true
+	s.status = message
+}
+
+// RecordError marks the span as failed when err is not nil
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.Fail(err.Error())
+	}
+}
+
+// End finishes the span and hands it to the exporter. Only the first call
+// has any effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.end.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.end = time.Now()
+	s.mu.Unlock()
+	s.tracer.exporter.export(s)
+}
+
+// exporter receives finished spans
+type exporter interface {
+	export(span *Span)
+}
+
+// Tracer starts spans and samples new traces
+type Tracer struct {
+	exporter exporter
+	// ratio is the fraction of new traces that are recorded
+	ratio float64
+}
+
+// NewTracer creates a tracer that records ratio (0 to 1) of new traces and
+// sends them to exporter. Traces continued from a caller follow the
+// caller's sampling decision.
+func NewTracer(exporter *Exporter, ratio float64) *Tracer {
+	return &Tracer{exporter: exporter, ratio: ratio}
+}
+
+var global atomic.Pointer[Tracer]
+
+// SetTracer installs the tracer Start uses. A nil tracer turns tracing off.
+func SetTracer(t *Tracer) {
+	global.Store(t)
+}
+
+// Enabled reports whether a tracer is installed
+func Enabled() bool {
+	return global.Load() != nil
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// SpanFromContext returns the span started in ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext returns a context whose spans continue the
+// trace sc started in another process
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// Start starts a span named name as a child of the span in ctx, or as the
+// root of a new trace. The returned context carries the new span. When
+// tracing is off or the trace is not sampled, the span is nil and the
+// context carries the decision, so descendants are not sampled either.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return start(ctx, name, KindInternal, attrs)
+}
+
+func start(ctx context.Context, name string, kind Kind, attrs []Attribute) (context.Context, *Span) {
+	tracer := global.Load()
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	parent := SpanFromContext(ctx).Context()
+	if !parent.IsValid() {
+		parent, _ = ctx.Value(remoteKey{}).(SpanContext)
+	}
+
+	sc := SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled}
+	if !parent.IsValid() {
+		sc.TraceID = newTraceID()
+		sc.Sampled = rand.Float64() < tracer.ratio
+	}
+	sc.SpanID = newSpanID()
+	if !sc.Sampled {
+		return ContextWithRemoteSpanContext(ctx, sc), nil
+	}
+
+	span := &Span{
+		tracer:  tracer,
+		context: sc,
+		parent:  parent.SpanID,
+		kind:    kind,
+		start:   time.Now(),
+		name:    name,
+		attrs:   attrs,
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		binary.LittleEndian.PutUint64(id[:8], rand.Uint64())
+		binary.LittleEndian.PutUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		binary.LittleEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
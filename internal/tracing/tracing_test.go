@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// recorder keeps finished spans in memory
+type recorder struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (r *recorder) export(span *Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+// install sets a tracer recording into a new recorder for the test
+func install(t *testing.T, ratio float64) *recorder {
+	t.Helper()
+	rec := &recorder{}
+	SetTracer(&Tracer{exporter: rec, ratio: ratio})
+	t.Cleanup(func() { SetTracer(nil) })
+	return rec
+}
+
+func TestStart_Disabled(t *testing.T) {
+	ctx, span := Start(context.Background(), "work")
+	if span != nil || SpanFromContext(ctx) != nil {
+		t.Fatal("Expected no span without a tracer")
+	}
+	// A nil span is safe to use
+	span.SetAttributes(String("key", "value"))
+	span.RecordError(context.Canceled)
+	span.End()
+}
+
+func TestStart_Nesting(t *testing.T) {
+	rec := install(t, 1)
+
+	ctx, parent := Start(context.Background(), "parent", Int("plant.id", 2))
+	_, child := Start(ctx, "child")
+	child.RecordError(context.DeadlineExceeded)
+	child.End()
+	child.End()
+	parent.End()
+
+	if len(rec.spans) != 2 {
+		t.Fatalf("Expected each span to be exported once, got %d spans", len(rec.spans))
+	}
+	if child.context.TraceID != parent.context.TraceID || child.parent != parent.context.SpanID || parent.parent.IsValid() {
+		t.Errorf("Expected the child to belong to the parent's trace, got %+v and %+v", child.context, parent.context)
+	}
+	if !child.failed || child.status != "context deadline exceeded" || parent.failed {
+		t.Errorf("Expected only the child to fail, got %v and %v", child.failed, parent.failed)
+	}
+	if len(parent.attrs) != 1 || parent.attrs[0] != (Attribute{"plant.id", int64(2)}) {
+		t.Errorf("Unexpected attributes: %+v", parent.attrs)
+	}
+}
+
+func TestStart_Sampling(t *testing.T) {
+	rec := install(t, 0)
+
+	ctx, span := Start(context.Background(), "unsampled")
+	if span != nil {
+		t.Fatal("Expected no span for an unsampled trace")
+	}
+	// Descendants follow the root's decision
+	if _, child := Start(ctx, "child"); child != nil {
+		t.Error("Expected no span under an unsampled root")
+	}
+
+	// A sampled caller's decision wins over the ratio
+	remote := SpanContext{TraceID: TraceID{1}, SpanID: SpanID{2}, Sampled: true}
+	_, span = Start(ContextWithRemoteSpanContext(context.Background(), remote), "continued")
+	if span == nil {
+		t.Fatal("Expected a span continuing a sampled trace")
+	}
+	span.End()
+	if span.context.TraceID != remote.TraceID || span.parent != remote.SpanID || len(rec.spans) != 1 {
+		t.Errorf("Expected the span to continue the remote trace, got %+v", span.context)
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.Sampled {
+		t.Errorf("Unexpected span context: %+v", sc)
+	}
+	if got := sc.Traceparent(); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("Expected the header to round trip, got %s", got)
+	}
+
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473g-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceparent(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+
+	// Later versions may append fields
+	if _, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); err != nil {
+		t.Errorf("Expected a later version to parse, got %v", err)
+	}
+}
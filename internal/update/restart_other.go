@@ -0,0 +1,10 @@
+//go:build !unix
+
+package update
+
+import "errors"
+
+// Restart is not supported on this platform; restart the server manually
+func Restart() error {
+	return errors.New("in-place restart is not supported on this platform")
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package update
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Restart replaces the current process with the installed binary, keeping
+// the process ID, arguments and environment so service managers such as
+// systemd keep tracking it. It only returns on failure.
+func Restart() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate binary: %w", err)
+	}
+	return syscall.Exec(exe, os.Args, os.Environ())
+}
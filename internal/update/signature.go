@@ -0,0 +1,70 @@
+package update
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// GenerateKeys creates a release signing key pair. Both keys are base64url
+// encoded without padding; the private key is the 32-byte Ed25519 seed.
+func GenerateKeys() (publicKey, privateKey string, err error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(public),
+		base64.RawURLEncoding.EncodeToString(private.Seed()), nil
+}
+
+// ParsePublicKey decodes an encoded release verification key
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid update public key encoding: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid update public key: expected %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// signedMessage is what a release signature covers: the version and asset
+// name as well as the binary, so a validly signed older release cannot be
+// served as a newer one, or one platform's binary as another's
+func signedMessage(version, assetName string, binary []byte) []byte {
+	sum := sha256.Sum256(binary)
+	return []byte(version + "\n" + assetName + "\n" + hex.EncodeToString(sum[:]))
+}
+
+// Sign signs a release binary for version and assetName with an encoded
+// private key and returns the signature in the format published as the .sig
+// asset
+func Sign(privateKey, version, assetName string, binary []byte) (string, error) {
+	seed, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(privateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid signing key encoding: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return "", fmt.Errorf("invalid signing key: expected %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	signature := ed25519.Sign(ed25519.NewKeyFromSeed(seed), signedMessage(version, assetName, binary))
+	return base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verify checks that an encoded signature covers binary as assetName of
+// version. A signature made for any other version or asset does not match.
+func Verify(publicKey ed25519.PublicKey, version, assetName string, binary []byte, signature string) error {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	if !ed25519.Verify(publicKey, signedMessage(version, assetName, binary), raw) {
+		return fmt.Errorf("signature does not match %s %s", assetName, version)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+// Package update installs new server releases published on GitHub. Release
+// binaries must carry an Ed25519 signature from the key in UPDATE_PUBLIC_KEY
+// over their version, asset name and contents; unsigned, tampered or
+// mislabelled downloads are never installed.
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"watered/internal/config"
+)
+
+// Version is the running server version. Release builds set it with
+// -ldflags "-X watered/internal/update.Version=v1.2.3".
+var Version = "dev"
+
+const (
+	// maxBinarySize caps release downloads so a bad release cannot fill the disk
+	maxBinarySize = 200 << 20
+	// maxSignatureSize caps signature downloads
+	maxSignatureSize = 1 << 10
+)
+
+// ErrUpdateInProgress is returned when another install is already running
+var ErrUpdateInProgress = errors.New("an update is already in progress")
+
+// Release is a published server release for this platform
+type Release struct {
+	Version      string    `json:"version"`
+	Notes        string    `json:"notes"`
+	PublishedAt  time.Time `json:"published_at"`
+	AssetURL     string    `json:"asset_url"`
+	SignatureURL string    `json:"signature_url"`
+}
+
+// Status describes the running version and the latest release
+type Status struct {
+	CurrentVersion  string    `json:"current_version"`
+	LatestVersion   string    `json:"latest_version"`
+	UpdateAvailable bool      `json:"update_available"`
+	Release         *Release  `json:"release,omitempty"`
+	Installing      bool      `json:"installing"`
+	LastError       string    `json:"last_error,omitempty"`
+	CheckedAt       time.Time `json:"checked_at"`
+}
+
+// Updater checks GitHub releases and replaces the running binary
+type Updater struct {
+	repository string
+	publicKey  ed25519.PublicKey
+	current    string
+	assetName  string
+
+	apiURL     string
+	client     *http.Client
+	executable func() (string, error)
+
+	mu         sync.Mutex
+	installing bool
+	lastError  error
+}
+
+// NewUpdater builds an updater from the update settings. It returns nil
+// without error when self-update is not configured.
+func NewUpdater(cfg config.UpdateConfig, current string) (*Updater, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	publicKey, err := ParsePublicKey(cfg.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Updater{
+		repository: cfg.Repository,
+		publicKey:  publicKey,
+		current:    current,
+		assetName:  AssetName(runtime.GOOS, runtime.GOARCH),
+		apiURL:     "https://api.github.com",
+		client:     &http.Client{Timeout: 10 * time.Minute},
+		executable: os.Executable,
+	}, nil
+}
+
+// AssetName is the release asset holding the server binary for a platform.
+// Its signature is published alongside as AssetName + ".sig".
+func AssetName(goos, goarch string) string {
+	return fmt.Sprintf("watered-%s-%s", goos, goarch)
+}
+
+// Check looks up the latest release and reports whether it is newer than the
+// running version
+func (u *Updater) Check(ctx context.Context) (*Status, error) {
+	release, err := u.latestRelease(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	status := &Status{
+		CurrentVersion:  u.current,
+		LatestVersion:   release.Version,
+		UpdateAvailable: newerVersion(release.Version, u.current),
+		Installing:      u.installing,
+		CheckedAt:       time.Now(),
+	}
+	if status.UpdateAvailable {
+		status.Release = release
+	}
+	if u.lastError != nil {
+		status.LastError = u.lastError.Error()
+	}
+	return status, nil
+}
+
+// Update installs the latest release when it is newer than the running
+// version. It returns the installed release, or nil when already up to date.
+// The new binary takes effect after Restart.
+func (u *Updater) Update(ctx context.Context) (*Release, error) {
+	u.mu.Lock()
+	if u.installing {
+		u.mu.Unlock()
+		return nil, ErrUpdateInProgress
+	}
+	u.installing = true
+	u.mu.Unlock()
+
+	release, err := u.update(ctx)
+
+	u.mu.Lock()
+	u.installing = false
+	u.lastError = err
+	u.mu.Unlock()
+
+	return release, err
+}
+
+func (u *Updater) update(ctx context.Context) (*Release, error) {
+	release, err := u.latestRelease(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !newerVersion(release.Version, u.current) {
+		return nil, nil
+	}
+
+	signature, err := u.download(ctx, release.SignatureURL, maxSignatureSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download signature for %s: %w", release.Version, err)
+	}
+	binary, err := u.download(ctx, release.AssetURL, maxBinarySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", release.Version, err)
+	}
+
+	// The signature covers the version, so a signed older release published
+	// under a newer tag is refused rather than installed as a downgrade
+	if err := Verify(u.publicKey, release.Version, u.assetName, binary, string(signature)); err != nil {
+		return nil, fmt.Errorf("refusing to install %s: %w", release.Version, err)
+	}
+
+	if err := u.install(binary); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// githubRelease is the subset of the GitHub releases API response we use
+type githubRelease struct {
+	TagName     string    `json:"tag_name"`
+	Body        string    `json:"body"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// latestRelease fetches the latest published release and finds the binary
+// and signature for this platform
+func (u *Updater) latestRelease(ctx context.Context) (*Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", u.apiURL, u.repository)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for releases: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to check for releases: GitHub returned %s", resp.Status)
+	}
+
+	var latest githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+
+	release := &Release{
+		Version:     latest.TagName,
+		Notes:       latest.Body,
+		PublishedAt: latest.PublishedAt,
+	}
+	for _, asset := range latest.Assets {
+		switch asset.Name {
+		case u.assetName:
+			release.AssetURL = asset.URL
+		case u.assetName + ".sig":
+			release.SignatureURL = asset.URL
+		}
+	}
+
+	if release.AssetURL == "" || release.SignatureURL == "" {
+		return nil, fmt.Errorf("release %s has no signed %s binary", release.Version, u.assetName)
+	}
+	return release, nil
+}
+
+// download fetches url, failing when the body is larger than limit
+func (u *Updater) download(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("download exceeds %d bytes", limit)
+	}
+	return data, nil
+}
+
+// install atomically replaces the running executable with binary. The old
+// binary is kept next to it with a .previous suffix for manual rollback.
+func (u *Updater) install(binary []byte) error {
+	exe, err := u.executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate running binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".watered-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to stage update: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to stage update: %w", err)
+	}
+	if err := tmp.Chmod(0755); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to stage update: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to stage update: %w", err)
+	}
+
+	previous := exe + ".previous"
+	if err := os.Rename(exe, previous); err != nil {
+		return fmt.Errorf("failed to keep previous binary: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		os.Rename(previous, exe)
+		return fmt.Errorf("failed to install update: %w", err)
+	}
+	return nil
+}
+
+// newerVersion reports whether latest is a higher version than current.
+// Development builds with an unparsable version are never updated.
+func newerVersion(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parseVersion parses "v1.2.3" or "1.2" into major, minor and patch numbers.
+// Pre-release versions such as "v1.3.0-rc1" are rejected.
+func parseVersion(version string) ([3]int, bool) {
+	var parts [3]int
+
+	fields := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
@@ -0,0 +1,213 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"watered/internal/config"
+)
+
+// fakeGitHub serves a latest release with a signed binary for linux/arm64
+type fakeGitHub struct {
+	tag       string
+	binary    []byte
+	signature string
+}
+
+func (f *fakeGitHub) handler() http.Handler {
+	mux := http.NewServeMux()
+	var server string
+	mux.HandleFunc("/repos/owner/watered/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		server = "http://" + r.Host
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tag_name": f.tag,
+			"body":     "Bug fixes",
+			"assets": []map[string]string{
+				{"name": "watered-linux-arm64", "browser_download_url": server + "/download/binary"},
+				{"name": "watered-linux-arm64.sig", "browser_download_url": server + "/download/signature"},
+				{"name": "watered-darwin-arm64", "browser_download_url": server + "/download/other"},
+			},
+		})
+	})
+	mux.HandleFunc("/download/binary", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(f.binary)
+	})
+	mux.HandleFunc("/download/signature", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, f.signature)
+	})
+	return mux
+}
+
+// newTestUpdater returns an updater for a fake release server and the path
+// of the executable it replaces
+func newTestUpdater(t *testing.T, release *fakeGitHub, publicKey, current string) (*Updater, string) {
+	server := httptest.NewServer(release.handler())
+	t.Cleanup(server.Close)
+
+	exe := filepath.Join(t.TempDir(), "watered")
+	require.NoError(t, os.WriteFile(exe, []byte("old binary"), 0755))
+
+	updater, err := NewUpdater(config.UpdateConfig{PublicKey: publicKey, Repository: "owner/watered"}, current)
+	require.NoError(t, err)
+	updater.apiURL = server.URL
+	updater.assetName = AssetName("linux", "arm64")
+	updater.executable = func() (string, error) { return exe, nil }
+	return updater, exe
+}
+
+func signedRelease(t *testing.T, tag string) (*fakeGitHub, string) {
+	publicKey, privateKey, err := GenerateKeys()
+	require.NoError(t, err)
+
+	binary := []byte("new binary " + tag)
+	signature, err := Sign(privateKey, tag, AssetName("linux", "arm64"), binary)
+	require.NoError(t, err)
+
+	return &fakeGitHub{tag: tag, binary: binary, signature: signature}, publicKey
+}
+
+func TestNewUpdater_Disabled(t *testing.T) {
+	updater, err := NewUpdater(config.UpdateConfig{}, "v1.0.0")
+	assert.NoError(t, err)
+	assert.Nil(t, updater)
+
+	_, err = NewUpdater(config.UpdateConfig{PublicKey: "not-a-key"}, "v1.0.0")
+	assert.Error(t, err)
+}
+
+func TestUpdater_Check(t *testing.T) {
+	release, publicKey := signedRelease(t, "v1.2.0")
+	updater, _ := newTestUpdater(t, release, publicKey, "v1.1.3")
+
+	status, err := updater.Check(context.Background())
+	require.NoError(t, err)
+	assert.True(t, status.UpdateAvailable)
+	assert.Equal(t, "v1.1.3", status.CurrentVersion)
+	assert.Equal(t, "v1.2.0", status.LatestVersion)
+	require.NotNil(t, status.Release)
+	assert.True(t, strings.HasSuffix(status.Release.AssetURL, "/download/binary"))
+	assert.Equal(t, "Bug fixes", status.Release.Notes)
+}
+
+func TestUpdater_UpdateInstallsSignedRelease(t *testing.T) {
+	release, publicKey := signedRelease(t, "v1.2.0")
+	updater, exe := newTestUpdater(t, release, publicKey, "v1.1.3")
+
+	installed, err := updater.Update(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, installed)
+	assert.Equal(t, "v1.2.0", installed.Version)
+
+	data, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, release.binary, data)
+
+	info, err := os.Stat(exe)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	previous, err := os.ReadFile(exe + ".previous")
+	require.NoError(t, err)
+	assert.Equal(t, "old binary", string(previous))
+}
+
+func TestUpdater_UpdateRejectsBadSignature(t *testing.T) {
+	release, publicKey := signedRelease(t, "v1.2.0")
+	release.binary = []byte("tampered binary")
+	updater, exe := newTestUpdater(t, release, publicKey, "v1.1.3")
+
+	installed, err := updater.Update(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "signature does not match")
+	assert.Nil(t, installed)
+
+	data, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, "old binary", string(data))
+
+	status, err := updater.Check(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, status.LastError, "signature does not match")
+}
+
+func TestUpdater_UpdateRejectsRetaggedRelease(t *testing.T) {
+	// A validly signed v1.1.0 published as v1.2.0 must not be installed
+	release, publicKey := signedRelease(t, "v1.1.0")
+	release.tag = "v1.2.0"
+	updater, exe := newTestUpdater(t, release, publicKey, "v1.1.3")
+
+	installed, err := updater.Update(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "signature does not match")
+	assert.Nil(t, installed)
+
+	data, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, "old binary", string(data))
+}
+
+func TestUpdater_UpdateUpToDate(t *testing.T) {
+	release, publicKey := signedRelease(t, "v1.2.0")
+	updater, exe := newTestUpdater(t, release, publicKey, "v1.2.0")
+
+	installed, err := updater.Update(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, installed)
+
+	data, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, "old binary", string(data))
+}
+
+func TestNewerVersion(t *testing.T) {
+	tests := []struct {
+		latest  string
+		current string
+		want    bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"v2", "v1.9.9", true},
+		{"1.2.1", "v1.2.0", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.1.0", "v1.2.0", false},
+		{"v1.3.0-rc1", "v1.2.0", false},
+		{"v1.3.0", "dev", false},
+		{"latest", "v1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.latest+"_vs_"+tt.current, func(t *testing.T) {
+			assert.Equal(t, tt.want, newerVersion(tt.latest, tt.current))
+		})
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	publicKey, privateKey, err := GenerateKeys()
+	require.NoError(t, err)
+
+	key, err := ParsePublicKey(publicKey)
+	require.NoError(t, err)
+
+	signature, err := Sign(privateKey, "v1.2.0", "watered-linux-arm64", []byte("binary"))
+	require.NoError(t, err)
+	assert.NoError(t, Verify(key, "v1.2.0", "watered-linux-arm64", []byte("binary"), signature+"\n"))
+	assert.Error(t, Verify(key, "v1.2.0", "watered-linux-arm64", []byte("other"), signature))
+	assert.Error(t, Verify(key, "v1.3.0", "watered-linux-arm64", []byte("binary"), signature))
+	assert.Error(t, Verify(key, "v1.2.0", "watered-linux-amd64", []byte("binary"), signature))
+	assert.Error(t, Verify(key, "v1.2.0", "watered-linux-arm64", []byte("binary"), "!!"))
+
+	_, err = Sign(publicKey+"AA", "v1.2.0", "watered-linux-arm64", []byte("binary"))
+	assert.Error(t, err)
+}
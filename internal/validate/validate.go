@@ -0,0 +1,233 @@
+// Package validate decodes JSON request bodies and checks them against
+// `validate` struct tags, so handlers can reject a bad payload with a
+// message for each field instead of a generic 400:
+//
+//	var req struct {
+//		TimeoutHours int    `json:"timeoutHours" validate:"min=1,max=168"`
+//		Email        string `json:"email" validate:"required,email"`
+//	}
+//	if !validate.DecodeJSON(w, r, &req) {
+//		return
+//	}
+//
+// Rules are comma separated:
+//
+//   - required: strings must not be blank and pointers must not be nil
+//   - min=N, max=N: bounds integers, or the length of strings
+//   - email: a single plain address such as alice@example.com
+//
+// Empty strings and nil pointers skip every rule but required.
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"watered/internal/respond"
+)
+
+// MaxBodyBytes is the largest request body DecodeJSON reads
+const MaxBodyBytes = 1 << 20
+
+// Errors lists the fields of a value that failed validation
+type Errors []respond.FieldError
+
+// Error joins the field messages
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, field := range e {
+		messages[i] = field.Field + " " + field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// DecodeJSON reads a JSON body of at most MaxBodyBytes into dst, a pointer
+// to a struct, and validates it. It writes a 413, or a 400 naming the bad
+// fields, and returns false if the body is unusable.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &tooLarge):
+			respond.Error(w, http.StatusRequestEntityTooLarge, respond.CodeTooLarge, fmt.Sprintf("Request body must not exceed %d bytes", MaxBodyBytes))
+		case errors.As(err, &typeErr) && typeErr.Field != "":
+			respond.ValidationError(w, Errors{{Field: typeErr.Field, Message: "must be " + typeName(typeErr.Type)}})
+		case errors.Is(err, io.EOF):
+			respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Request body is required")
+		default:
+			respond.Error(w, http.StatusBadRequest, respond.CodeInvalidJSON, "Invalid JSON")
+		}
+		return false
+	}
+
+	if errs := Struct(dst); len(errs) > 0 {
+		respond.ValidationError(w, errs)
+		return false
+	}
+	return true
+}
+
+// Struct checks the fields of a struct, or pointer to one, against their
+// validate tags. It returns nil if every field is valid and panics on an
+// unknown rule.
+func Struct(v interface{}) Errors {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: %T is not a struct", v))
+	}
+
+	var errs Errors
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		rules, ok := field.Tag.Lookup("validate")
+		if !ok || rules == "" {
+			continue
+		}
+		if message := check(value.Field(i), rules); message != "" {
+			errs = append(errs, respond.FieldError{Field: jsonName(field), Message: message})
+		}
+	}
+	return errs
+}
+
+// check applies comma-separated rules to one field and returns the first
+// failure
+func check(value reflect.Value, rules string) string {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			if hasRule(rules, "required") {
+				return "is required"
+			}
+			return ""
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.String && strings.TrimSpace(value.String()) == "" {
+		if hasRule(rules, "required") {
+			return "is required"
+		}
+		return ""
+	}
+
+	min, hasMin := bound(rules, "min")
+	max, hasMax := bound(rules, "max")
+	if hasMin || hasMax {
+		if message := checkBounds(value, min, max, hasMin, hasMax); message != "" {
+			return message
+		}
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, _, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required", "min", "max":
+		case "email":
+			if !isEmail(value.String()) {
+				return "must be a valid email address"
+			}
+		default:
+			panic(fmt.Sprintf("validate: unknown rule %q", rule))
+		}
+	}
+	return ""
+}
+
+// checkBounds applies min and max to a number, or to a string's length
+func checkBounds(value reflect.Value, min, max int64, hasMin, hasMax bool) string {
+	var n int64
+	unit := ""
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = value.Int()
+	case reflect.String:
+		n = int64(utf8.RuneCountInString(value.String()))
+		unit = " characters"
+	default:
+		panic(fmt.Sprintf("validate: min and max do not apply to %s", value.Kind()))
+	}
+
+	if (hasMin && n < min) || (hasMax && n > max) {
+		switch {
+		case hasMin && hasMax:
+			return fmt.Sprintf("must be between %d and %d%s", min, max, unit)
+		case hasMin:
+			return fmt.Sprintf("must be at least %d%s", min, unit)
+		default:
+			return fmt.Sprintf("must be at most %d%s", max, unit)
+		}
+	}
+	return ""
+}
+
+// hasRule reports whether rules contains the named rule
+func hasRule(rules, name string) bool {
+	for _, rule := range strings.Split(rules, ",") {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// bound returns the value of a min=N or max=N rule
+func bound(rules, name string) (int64, bool) {
+	for _, rule := range strings.Split(rules, ",") {
+		if key, arg, ok := strings.Cut(rule, "="); ok && key == name {
+			n, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				panic(fmt.Sprintf("validate: bad %s rule %q", name, rule))
+			}
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// isEmail reports whether s is a single plain address with a dotted domain
+func isEmail(s string) bool {
+	s = strings.TrimSpace(s)
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || addr.Name != "" {
+		return false
+	}
+	_, domain, _ := strings.Cut(s, "@")
+	return strings.Contains(strings.Trim(domain, "."), ".")
+}
+
+// jsonName returns the name a field has in JSON
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// typeName describes the JSON type expected for a Go type
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,109 @@
+package validate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"watered/internal/respond"
+)
+
+type settings struct {
+	Name    string `json:"name" validate:"required,max=5"`
+	Hours   int    `json:"hours" validate:"min=1,max=168"`
+	Grace   *int   `json:"grace" validate:"min=0"`
+	Email   string `json:"email" validate:"email"`
+	Comment string `json:"comment"`
+}
+
+func TestStruct(t *testing.T) {
+	negative := -1
+	tests := []struct {
+		name  string
+		value settings
+		want  Errors
+	}{
+		{"valid", settings{Name: "Fern", Hours: 24, Email: "alice@example.com"}, nil},
+		{"optional fields empty", settings{Name: "Fern", Hours: 1}, nil},
+		{"required blank", settings{Name: "  ", Hours: 24}, Errors{{Field: "name", Message: "is required"}}},
+		{"string too long", settings{Name: "Monstera", Hours: 24}, Errors{{Field: "name", Message: "must be at most 5 characters"}}},
+		{"out of range", settings{Name: "Fern", Hours: 200}, Errors{{Field: "hours", Message: "must be between 1 and 168"}}},
+		{"pointer checked when set", settings{Name: "Fern", Hours: 24, Grace: &negative}, Errors{{Field: "grace", Message: "must be at least 0"}}},
+		{"bad email", settings{Name: "Fern", Hours: 24, Email: "alice"}, Errors{{Field: "email", Message: "must be a valid email address"}}},
+		{"several fields", settings{Hours: 0, Email: "Alice <alice@example.com>"}, Errors{
+			{Field: "name", Message: "is required"},
+			{Field: "hours", Message: "must be between 1 and 168"},
+			{Field: "email", Message: "must be a valid email address"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Struct(&tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Struct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsEmail(t *testing.T) {
+	for _, email := range []string{"alice@example.com", " bob@mail.example.org "} {
+		if !isEmail(email) {
+			t.Errorf("Expected %q to be valid", email)
+		}
+	}
+	for _, email := range []string{"alice", "alice@localhost", "@example.com", "a@b@example.com", "Alice <alice@example.com>"} {
+		if isEmail(email) {
+			t.Errorf("Expected %q to be invalid", email)
+		}
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   string
+		fields []respond.FieldError
+	}{
+		{"valid", `{"name":"Fern","hours":24}`, http.StatusOK, "", nil},
+		{"empty body", ``, http.StatusBadRequest, respond.CodeInvalidJSON, nil},
+		{"malformed", `{"name":`, http.StatusBadRequest, respond.CodeInvalidJSON, nil},
+		{"wrong type", `{"name":"Fern","hours":"lots"}`, http.StatusBadRequest, respond.CodeValidation, []respond.FieldError{{Field: "hours", Message: "must be a whole number"}}},
+		{"invalid field", `{"name":"Fern","hours":0}`, http.StatusBadRequest, respond.CodeValidation, []respond.FieldError{{Field: "hours", Message: "must be between 1 and 168"}}},
+		{"too large", `{"comment":"` + strings.Repeat("x", MaxBodyBytes) + `"}`, http.StatusRequestEntityTooLarge, respond.CodeTooLarge, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			var dst settings
+			ok := DecodeJSON(rr, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.body)), &dst)
+
+			if ok != (tt.status == http.StatusOK) {
+				t.Fatalf("DecodeJSON() = %v for status %d", ok, tt.status)
+			}
+			if ok {
+				return
+			}
+			if rr.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rr.Code)
+			}
+
+			var response respond.ErrorResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode %q: %v", rr.Body.String(), err)
+			}
+			if response.Error.Code != tt.code {
+				t.Errorf("Expected code %q, got %q", tt.code, response.Error.Code)
+			}
+			if !reflect.DeepEqual(response.Error.Fields, tt.fields) {
+				t.Errorf("Expected fields %v, got %v", tt.fields, response.Error.Fields)
+			}
+		})
+	}
+}
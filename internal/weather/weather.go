@@ -0,0 +1,215 @@
+// Package weather fetches the recent weather at the household's location from
+// Open-Meteo and turns it into a factor for outdoor plants' watering timeouts:
+// rain and humidity stretch the timeout, a heatwave shrinks it.
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"watered/internal/config"
+)
+
+// pastDays is how many days before today the adjustment looks back on
+const pastDays = 2
+
+// Adjustment thresholds and the factors they apply. Factors multiply, and
+// the result is kept between MinFactor and MaxFactor.
+const (
+	rainMM      = 2.0  // millimetres over the past days that count as rainy
+	heavyRainMM = 10.0 // millimetres over the past days that count as very wet
+	humidPct    = 80.0 // mean relative humidity over the past days that counts as humid
+	hotC        = 30.0 // daily high in degrees Celsius that counts as a heatwave
+	veryHotC    = 35.0 // daily high in degrees Celsius that counts as extreme heat
+
+	rainFactor      = 1.25
+	heavyRainFactor = 1.5
+	humidFactor     = 1.15
+	hotFactor       = 0.75
+	veryHotFactor   = 0.5
+
+	MinFactor = 0.5
+	MaxFactor = 2.0
+)
+
+// Day is one day of weather. Missing values are nil.
+type Day struct {
+	Date            string   `json:"date"`
+	PrecipitationMM *float64 `json:"precipitation_mm"`
+	MaxTemperatureC *float64 `json:"max_temperature_c"`
+	MeanHumidityPct *float64 `json:"mean_humidity_pct"`
+}
+
+// Forecast is the weather of the past few days and today's forecast
+type Forecast struct {
+	Past  []Day `json:"past"`
+	Today Day   `json:"today"`
+}
+
+// Adjustment is the factor a forecast scales outdoor timeouts by and why
+type Adjustment struct {
+	Factor float64 `json:"factor"`
+	Reason string  `json:"reason,omitempty"`
+}
+
+// Client fetches forecasts for one location
+type Client struct {
+	apiURL    string
+	latitude  float64
+	longitude float64
+	client    *http.Client
+}
+
+// NewClient creates a client for the configured location. It returns nil
+// when no location is configured.
+func NewClient(cfg config.WeatherConfig) *Client {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return &Client{
+		apiURL:    strings.TrimRight(cfg.APIURL, "/"),
+		latitude:  cfg.Latitude,
+		longitude: cfg.Longitude,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// dailyResponse is the part of an Open-Meteo forecast response that is used
+type dailyResponse struct {
+	Daily struct {
+		Time          []string   `json:"time"`
+		Precipitation []*float64 `json:"precipitation_sum"`
+		MaxTemp       []*float64 `json:"temperature_2m_max"`
+		MeanHumidity  []*float64 `json:"relative_humidity_2m_mean"`
+	} `json:"daily"`
+	Reason string `json:"reason"`
+}
+
+// Forecast fetches the past days' weather and today's forecast, in the
+// location's own timezone
+func (c *Client) Forecast(ctx context.Context) (*Forecast, error) {
+	query := url.Values{
+		"latitude":      {strconv.FormatFloat(c.latitude, 'f', -1, 64)},
+		"longitude":     {strconv.FormatFloat(c.longitude, 'f', -1, 64)},
+		"daily":         {"precipitation_sum,temperature_2m_max,relative_humidity_2m_mean"},
+		"past_days":     {strconv.Itoa(pastDays)},
+		"forecast_days": {"1"},
+		"timezone":      {"auto"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"/v1/forecast?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create weather request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch weather: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Open-Meteo explains rejected requests in a reason field
+	var body dailyResponse
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body)
+	if resp.StatusCode != http.StatusOK {
+		if body.Reason != "" {
+			return nil, fmt.Errorf("weather API returned status %d: %s", resp.StatusCode, body.Reason)
+		}
+		return nil, fmt.Errorf("weather API returned status %d", resp.StatusCode)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode weather: %w", decodeErr)
+	}
+
+	daily := body.Daily
+	if len(daily.Time) == 0 {
+		return nil, fmt.Errorf("weather API returned no days")
+	}
+	days := make([]Day, len(daily.Time))
+	for i, date := range daily.Time {
+		days[i] = Day{
+			Date:            date,
+			PrecipitationMM: at(daily.Precipitation, i),
+			MaxTemperatureC: at(daily.MaxTemp, i),
+			MeanHumidityPct: at(daily.MeanHumidity, i),
+		}
+	}
+	return &Forecast{Past: days[:len(days)-1], Today: days[len(days)-1]}, nil
+}
+
+// at returns values[i], or nil if the series is short
+func at(values []*float64, i int) *float64 {
+	if i < len(values) {
+		return values[i]
+	}
+	return nil
+}
+
+// Adjust works out how much a forecast stretches or shrinks an outdoor
+// plant's timeout. Rain and humidity over the past days stretch it, since the
+// soil is still wet; a hot day in the past days or today's forecast shrinks it.
+func Adjust(f *Forecast) Adjustment {
+	factor := 1.0
+	var reasons []string
+
+	var rain, humidity float64
+	humidDays := 0
+	for _, day := range f.Past {
+		if day.PrecipitationMM != nil {
+			rain += *day.PrecipitationMM
+		}
+		if day.MeanHumidityPct != nil {
+			humidity += *day.MeanHumidityPct
+			humidDays++
+		}
+	}
+	switch {
+	case rain >= heavyRainMM:
+		factor *= heavyRainFactor
+		reasons = append(reasons, fmt.Sprintf("%.1f mm of rain in %d days", rain, len(f.Past)))
+	case rain >= rainMM:
+		factor *= rainFactor
+		reasons = append(reasons, fmt.Sprintf("%.1f mm of rain in %d days", rain, len(f.Past)))
+	}
+	if humidDays > 0 && humidity/float64(humidDays) >= humidPct {
+		factor *= humidFactor
+		reasons = append(reasons, fmt.Sprintf("%.0f%% humidity", humidity/float64(humidDays)))
+	}
+
+	hottest := math.Inf(-1)
+	for _, high := range highs(f) {
+		hottest = math.Max(hottest, high)
+	}
+	switch {
+	case hottest >= veryHotC:
+		factor *= veryHotFactor
+		reasons = append(reasons, fmt.Sprintf("highs of %.0f°C", hottest))
+	case hottest >= hotC:
+		factor *= hotFactor
+		reasons = append(reasons, fmt.Sprintf("highs of %.0f°C", hottest))
+	}
+
+	factor = math.Max(MinFactor, math.Min(MaxFactor, factor))
+	return Adjustment{Factor: math.Round(factor*100) / 100, Reason: strings.Join(reasons, ", ")}
+}
+
+// highs returns the known daily highs of the past days and today
+func highs(f *Forecast) []float64 {
+	var temperatures []float64
+	for _, day := range f.Past {
+		if day.MaxTemperatureC != nil {
+			temperatures = append(temperatures, *day.MaxTemperatureC)
+		}
+	}
+	if f.Today.MaxTemperatureC != nil {
+		temperatures = append(temperatures, *f.Today.MaxTemperatureC)
+	}
+	return temperatures
+}
@@ -0,0 +1,100 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"watered/internal/config"
+)
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestNewClient(t *testing.T) {
+	if client := NewClient(config.WeatherConfig{APIURL: "https://api.open-meteo.com"}); client != nil {
+		t.Error("Expected no client without a location")
+	}
+}
+
+func TestClient_Forecast(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		if r.URL.Path != "/v1/forecast" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"daily": {
+			"time": ["2026-10-13", "2026-10-14", "2026-10-15"],
+			"precipitation_sum": [4.5, 0.0, null],
+			"temperature_2m_max": [18.2, 21.0, 24.5],
+			"relative_humidity_2m_mean": [88, 75, 70]
+		}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(config.WeatherConfig{Latitude: 52.52, Longitude: 13.41, APIURL: server.URL + "/"})
+	forecast, err := client.Forecast(context.Background())
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+
+	for _, param := range []string{"latitude=52.52", "longitude=13.41", "past_days=2", "forecast_days=1"} {
+		if !strings.Contains(query, param) {
+			t.Errorf("Expected %s in query %q", param, query)
+		}
+	}
+	if len(forecast.Past) != 2 || forecast.Past[0].Date != "2026-10-13" || *forecast.Past[0].PrecipitationMM != 4.5 {
+		t.Errorf("Unexpected past days: %+v", forecast.Past)
+	}
+	if forecast.Today.Date != "2026-10-15" || forecast.Today.PrecipitationMM != nil || *forecast.Today.MaxTemperatureC != 24.5 {
+		t.Errorf("Unexpected today: %+v", forecast.Today)
+	}
+}
+
+func TestClient_ForecastError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": true, "reason": "Latitude must be in range of -90 to 90°."}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(config.WeatherConfig{Latitude: 52.52, Longitude: 13.41, APIURL: server.URL})
+	_, err := client.Forecast(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Latitude must be in range") {
+		t.Errorf("Expected the API's reason in the error, got %v", err)
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	day := func(rain, high, humidity float64) Day {
+		return Day{PrecipitationMM: ptr(rain), MaxTemperatureC: ptr(high), MeanHumidityPct: ptr(humidity)}
+	}
+	tests := []struct {
+		name   string
+		past   []Day
+		today  Day
+		factor float64
+		reason string
+	}{
+		{"mild", []Day{day(0, 20, 60), day(1, 22, 60)}, day(0, 21, 60), 1, ""},
+		{"rainy", []Day{day(1.5, 18, 60), day(1.5, 18, 60)}, day(0, 18, 60), 1.25, "3.0 mm of rain in 2 days"},
+		{"wet and humid", []Day{day(8, 16, 90), day(6, 15, 85)}, day(0, 17, 80), 1.73, "14.0 mm of rain in 2 days, 88% humidity"},
+		{"heatwave forecast", []Day{day(0, 28, 40), day(0, 29, 40)}, day(0, 31, 40), 0.75, "highs of 31°C"},
+		{"extreme heat", []Day{day(0, 36, 30), day(0, 33, 30)}, day(0, 30, 30), 0.5, "highs of 36°C"},
+		{"storm after heat", []Day{day(20, 36, 60), day(0, 25, 70)}, day(0, 22, 60), 0.75, "20.0 mm of rain in 2 days, highs of 36°C"},
+		{"missing values", []Day{{}, {}}, Day{}, 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Adjust(&Forecast{Past: tt.past, Today: tt.today})
+			if got.Factor != tt.factor || got.Reason != tt.reason {
+				t.Errorf("Adjust() = %+v, want factor %v and reason %q", got, tt.factor, tt.reason)
+			}
+		})
+	}
+}
@@ -3,13 +3,18 @@ package e2e
 import (
 	"encoding/json"
 	"fmt"
+	"html/template"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"watered/internal/auth"
+	"watered/internal/config"
 	"watered/internal/handlers"
+	"watered/internal/render"
 	"watered/internal/services"
 	"watered/internal/storage"
 
@@ -29,15 +34,19 @@ type TestApp struct {
 func NewTestApp(t *testing.T) *TestApp {
 	// Initialize storage
 	store := storage.NewMemoryStorage()
+	cfg := config.Default()
+	cfg.Auth.DemoMode = true
 
 	// Initialize services
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, cfg.Auth)
 	plantService := services.NewPlantService(store)
 
 	// Initialize handlers
 	authHandlers := handlers.NewAuthHandlers(authService)
+	templates := template.Must(template.ParseFiles(filepath.Join("..", "..", "web", "templates", "demo-login.html")))
+	authHandlers.SetRenderer(render.NewRenderer(templates, nil))
 	plantHandlers := handlers.NewPlantHandlers(plantService, authService)
-	adminHandlers := handlers.NewAdminHandler(store)
+	adminHandlers := handlers.NewAdminHandler(store, cfg)
 
 	// Create router with full application setup
 	r := chi.NewRouter()
@@ -308,9 +317,13 @@ func TestDemoModeWorkflow(t *testing.T) {
 	require.NoError(t, err)
 	defer resp.Body.Close()
 
-	// Demo login should be available (app is in demo mode by default)
-	// Exact behavior depends on implementation - could be redirect or form
-	assert.True(t, resp.StatusCode < 500, "Demo login endpoint should not error")
+	// Demo login should be available (the test app turns on DEMO_MODE) and
+	// offer the example users
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `<option value="demo@example.com">`)
+	assert.Contains(t, string(body), "admin@example.com (Admin)")
 }
 
 func TestConcurrentAccess(t *testing.T) {
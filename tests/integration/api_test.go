@@ -8,10 +8,14 @@ import (
 	"testing"
 	"time"
 
+	"watered/internal/apiversion"
 	"watered/internal/auth"
+	"watered/internal/config"
 	"watered/internal/handlers"
+	"watered/internal/ratelimit"
 	"watered/internal/services"
 	"watered/internal/storage"
+	"watered/internal/update"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -20,17 +24,38 @@ import (
 
 // CreateTestServer creates a test server instance
 func CreateTestServer(t *testing.T) *httptest.Server {
+	cfg := config.Default()
+	cfg.Auth.DemoMode = true
+	return CreateTestServerWithConfig(t, cfg)
+}
+
+// CreateTestServerWithConfig creates a test server instance with the given
+// configuration, rate limited the way the server is
+func CreateTestServerWithConfig(t *testing.T, cfg *config.Config) *httptest.Server {
 	// Initialize storage
 	store := storage.NewMemoryStorage()
 
 	// Initialize services
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, cfg.Auth)
 	plantService := services.NewPlantService(store)
 
 	// Initialize handlers
 	authHandlers := handlers.NewAuthHandlers(authService)
 	plantHandlers := handlers.NewPlantHandlers(plantService, authService)
-	adminHandlers := handlers.NewAdminHandler(store)
+	adminHandlers := handlers.NewAdminHandler(store, cfg)
+
+	// Rate limiting
+	authLimit := func(next http.Handler) http.Handler { return next }
+	if limiter := ratelimit.NewBucketLimiter("auth", cfg.RateLimit.Auth); limiter != nil {
+		authLimit = limiter.Middleware(authService.ClientIPKey, authService.ClientKey)
+	}
+	writeLimit := func(next http.Handler) http.Handler { return next }
+	if limiter := ratelimit.NewBucketLimiter("write", cfg.RateLimit.Write); limiter != nil {
+		writeLimit = ratelimit.WritesOnly(limiter.Middleware(authService.ClientIPKey, authService.ClientKey))
+	}
+	if lockout := ratelimit.NewLockout(cfg.RateLimit.Lockout); lockout != nil {
+		authHandlers.SetLockout(lockout)
+	}
 
 	// Create router
 	r := chi.NewRouter()
@@ -45,12 +70,16 @@ func CreateTestServer(t *testing.T) *httptest.Server {
 	// Authentication routes
 	r.Route("/auth", func(r chi.Router) {
 		r.Get("/status", authHandlers.StatusHandler)
-		r.HandleFunc("/demo-login", authHandlers.DemoLoginHandler)
-		r.Post("/logout", authHandlers.LogoutHandler)
+		r.Group(func(r chi.Router) {
+			r.Use(authLimit)
+			r.HandleFunc("/demo-login", authHandlers.DemoLoginHandler)
+			r.Post("/logout", authHandlers.LogoutHandler)
+		})
 	})
 
-	// API routes
-	r.Route("/api", func(r chi.Router) {
+	// API routes, versioned with the unversioned paths kept as deprecated
+	// aliases of v1
+	apiV1 := func(r chi.Router) {
 		r.Get("/status", handlers.GetStatus)
 
 		// Plant API routes
@@ -73,10 +102,16 @@ func CreateTestServer(t *testing.T) *httptest.Server {
 				r.Post("/reset", plantHandlers.ResetPlantHandler)
 			})
 		})
+	}
+	r.Route("/api", func(r chi.Router) {
+		r.Use(writeLimit)
+		apiversion.Mount(r, apiversion.Version{Name: "v1", Routes: apiV1})
+		apiversion.MountLegacy(r, "/api", apiversion.Version{Name: "v1", Routes: apiV1})
 	})
 
 	// Admin API routes
 	r.Route("/admin", func(r chi.Router) {
+		r.Use(writeLimit)
 		r.Use(authService.AdminRequired)
 
 		// Configuration endpoints
@@ -132,7 +167,7 @@ func TestAPIStatusEndpoint(t *testing.T) {
 
 	assert.Equal(t, "ok", response["status"])
 	assert.Equal(t, "watered-api", response["service"])
-	assert.Equal(t, "1.0.0", response["version"])
+	assert.Equal(t, update.Version, response["version"])
 	assert.Contains(t, response, "timestamp")
 }
 
@@ -177,6 +212,28 @@ func TestPlantStatusEndpoint(t *testing.T) {
 	assert.Contains(t, statusResponse, "is_overdue")
 }
 
+func TestAPIVersioning(t *testing.T) {
+	server := CreateTestServer(t)
+	defer server.Close()
+
+	// v1 is current
+	resp, err := http.Get(server.URL + "/api/v1/plant/status")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Deprecation"))
+
+	// The unversioned path still works but points at v1
+	resp, err = http.Get(server.URL + "/api/plant/status")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Deprecation"))
+	assert.Equal(t, `</api/v1/plant/status>; rel="successor-version"`, resp.Header.Get("Link"))
+}
+
 func TestAuthStatusEndpoint(t *testing.T) {
 	server := CreateTestServer(t)
 	defer server.Close()
@@ -257,21 +314,51 @@ func TestCORSHeaders(t *testing.T) {
 }
 
 func TestRateLimiting(t *testing.T) {
-	server := CreateTestServer(t)
+	cfg := config.Default()
+	cfg.RateLimit.Auth = config.BucketConfig{Burst: 3, Refill: time.Hour}
+	cfg.RateLimit.Write = config.BucketConfig{Burst: 2, Refill: time.Hour}
+	server := CreateTestServerWithConfig(t, cfg)
 	defer server.Close()
 
-	// Basic test - make multiple requests quickly
-	// Real rate limiting would require rate limiting middleware
-	for i := 0; i < 10; i++ {
-		resp, err := http.Get(server.URL + "/health")
+	// Sign-out redirects; the limit headers are on the redirect itself
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	send := func(method, path string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
 		require.NoError(t, err)
 		resp.Body.Close()
+		return resp
+	}
 
-		// Should not error out with basic load
-		assert.Equal(t, http.StatusOK, resp.StatusCode)
-
-		// Small delay to avoid overwhelming the test server
-		time.Sleep(10 * time.Millisecond)
+	// Sign-in requests get a burst, then 429 until the bucket refills
+	for i, remaining := range []string{"2", "1", "0"} {
+		resp := send(http.MethodPost, "/auth/logout")
+		assert.NotEqual(t, http.StatusTooManyRequests, resp.StatusCode, "request %d", i+1)
+		assert.Equal(t, "3", resp.Header.Get("X-RateLimit-Limit"), "request %d", i+1)
+		assert.Equal(t, remaining, resp.Header.Get("X-RateLimit-Remaining"), "request %d", i+1)
+	}
+	resp := send(http.MethodPost, "/auth/logout")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "3600", resp.Header.Get("Retry-After"))
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, resp.Header.Get("X-RateLimit-Reset"))
+
+	// Reading the sign-in state is not limited
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/auth/status").StatusCode)
+
+	// Writes have their own bucket, reads are not limited
+	for i := 0; i < 2; i++ {
+		assert.NotEqual(t, http.StatusTooManyRequests, send(http.MethodPost, "/api/plant/water").StatusCode)
+	}
+	resp = send(http.MethodPost, "/admin/users")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, send(http.MethodGet, "/health").StatusCode)
+		assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/status").StatusCode)
 	}
 }
 
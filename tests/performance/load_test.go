@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"watered/internal/auth"
+	"watered/internal/config"
 	"watered/internal/handlers"
 	"watered/internal/services"
 	"watered/internal/storage"
@@ -40,9 +41,10 @@ type LoadTestResults struct {
 func CreateLoadTestServer() *httptest.Server {
 	// Initialize storage
 	store := storage.NewMemoryStorage()
+	cfg := config.Default()
 
 	// Initialize services
-	authService := auth.NewAuthService(store)
+	authService := auth.NewAuthService(store, cfg.Auth)
 	plantService := services.NewPlantService(store)
 
 	// Initialize handlers
@@ -0,0 +1,166 @@
+// Service worker: precaches static assets listed in /api/v1/cache-manifest,
+// keeps the app usable offline and shows watering reminders delivered via
+// Web Push
+const CACHE_PREFIX = 'watered-assets-';
+const PAGES_CACHE = 'watered-pages';
+const DATA_CACHE = 'watered-data';
+const OFFLINE_URL = '/offline';
+// Responses kept for showing the last known plant status offline
+const CACHED_PATHS = new Set(['/fragments/plant-card']);
+let currentCache = null;
+
+// precache stores every asset of the current manifest version and removes
+// caches left over from older versions
+async function precache() {
+    const response = await fetch('/api/v1/cache-manifest', { cache: 'no-store' });
+    if (!response.ok) {
+        throw new Error(`cache manifest unavailable: ${response.status}`);
+    }
+    const manifest = await response.json();
+    const cacheName = CACHE_PREFIX + manifest.version;
+
+    if (!(await caches.has(cacheName))) {
+        const cache = await caches.open(cacheName);
+        await cache.addAll(manifest.assets.map((asset) => new Request(asset.url, { integrity: asset.hash })));
+    }
+
+    for (const name of await caches.keys()) {
+        if (name.startsWith(CACHE_PREFIX) && name !== cacheName) {
+            await caches.delete(name);
+        }
+    }
+    currentCache = cacheName;
+}
+
+// cacheOfflinePage stores the page shown when navigation fails, refreshed on
+// every page load so it follows the user's language
+async function cacheOfflinePage() {
+    const cache = await caches.open(PAGES_CACHE);
+    await cache.add(new Request(OFFLINE_URL, { cache: 'no-store' }));
+}
+
+// networkFirst answers from the network, saving a copy stamped with the time
+// it was saved, and falls back to that copy when the server can't be reached
+async function networkFirst(request) {
+    try {
+        const response = await fetch(request);
+        if (response.ok) {
+            const headers = new Headers(response.headers);
+            headers.set('X-Cached-At', new Date().toISOString());
+            // The body is saved decoded
+            headers.delete('Content-Encoding');
+            headers.delete('Content-Length');
+            const copy = new Response(await response.clone().blob(), {
+                status: response.status,
+                statusText: response.statusText,
+                headers,
+            });
+            const cache = await caches.open(DATA_CACHE);
+            await cache.put(request, copy);
+        }
+        return response;
+    } catch (error) {
+        const cached = await caches.match(request, { cacheName: DATA_CACHE });
+        if (cached) {
+            return cached;
+        }
+        throw error;
+    }
+}
+
+// offlineFallback answers a failed page load with the offline page
+async function offlineFallback(request) {
+    try {
+        return await fetch(request);
+    } catch (error) {
+        const cached = await caches.match(OFFLINE_URL, { cacheName: PAGES_CACHE });
+        if (cached) {
+            return cached;
+        }
+        throw error;
+    }
+}
+
+self.addEventListener('install', (event) => {
+    event.waitUntil(Promise.all([precache(), cacheOfflinePage()]).then(() => self.skipWaiting()));
+});
+
+self.addEventListener('activate', (event) => {
+    event.waitUntil(self.clients.claim());
+});
+
+self.addEventListener('fetch', (event) => {
+    const url = new URL(event.request.url);
+    if (url.origin !== self.location.origin) {
+        return;
+    }
+
+    // The cached plant status belongs to the signed-in user
+    if (event.request.method === 'POST' && url.pathname === '/auth/logout') {
+        event.waitUntil(caches.delete(DATA_CACHE));
+        return;
+    }
+    if (event.request.method !== 'GET') {
+        return;
+    }
+
+    // Page loads check for a new server version in the background, and show
+    // the offline page when the server can't be reached
+    if (event.request.mode === 'navigate') {
+        event.waitUntil(Promise.all([precache(), cacheOfflinePage()])
+            .catch((error) => console.warn('Precache failed:', error)));
+        event.respondWith(offlineFallback(event.request));
+        return;
+    }
+
+    if (CACHED_PATHS.has(url.pathname)) {
+        event.respondWith(networkFirst(event.request));
+        return;
+    }
+
+    if (url.pathname.startsWith('/static/')) {
+        event.respondWith(caches.match(event.request, currentCache ? { cacheName: currentCache } : {})
+            .then((cached) => cached || fetch(event.request)));
+    }
+});
+
+self.addEventListener('push', (event) => {
+    let data = {};
+    try {
+        data = event.data ? event.data.json() : {};
+    } catch (e) {
+        data = { body: event.data ? event.data.text() : '' };
+    }
+
+    const title = data.title || 'Watered';
+    event.waitUntil(self.registration.showNotification(title, {
+        body: data.body || 'Your plant needs attention',
+        icon: '/static/icons/icon-192.png',
+        tag: data.plant_id ? `plant-${data.plant_id}` : 'watered',
+        data: { url: data.url || '/', snoozeUrl: data.snooze_url },
+        actions: data.snooze_url ? [{ action: 'snooze', title: data.snooze_title || 'Remind me later' }] : [],
+    }));
+});
+
+self.addEventListener('notificationclick', (event) => {
+    event.notification.close();
+
+    // Snoozing happens in the background without opening the app
+    if (event.action === 'snooze' && event.notification.data && event.notification.data.snoozeUrl) {
+        event.waitUntil(fetch(event.notification.data.snoozeUrl, { credentials: 'omit' })
+            .catch((error) => console.warn('Snooze failed:', error)));
+        return;
+    }
+
+    const url = (event.notification.data && event.notification.data.url) || '/';
+
+    event.waitUntil(clients.matchAll({ type: 'window', includeUncontrolled: true }).then((windows) => {
+        for (const client of windows) {
+            if ('focus' in client) {
+                client.navigate(url);
+                return client.focus();
+            }
+        }
+        return clients.openWindow(url);
+    }));
+});